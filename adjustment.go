@@ -0,0 +1,161 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// adjustmentsSchema creates the calorie_adjustments table in databases
+// made before it existed.
+const adjustmentsSchema = `
+	CREATE TABLE IF NOT EXISTS calorie_adjustments (
+		id INTEGER PRIMARY KEY,
+		phase_id INTEGER NOT NULL,
+		date DATE NOT NULL,
+		weeks INTEGER NOT NULL,
+		weight_change REAL NOT NULL,
+		weekly_change REAL NOT NULL,
+		old_calories REAL NOT NULL,
+		new_calories REAL NOT NULL,
+		protein REAL NOT NULL,
+		carbs REAL NOT NULL,
+		fats REAL NOT NULL
+	)
+`
+
+// Adjustment is a change to the calorie goal made by the progress
+// checks, and why it was made.
+type Adjustment struct {
+	ID      int       `db:"id"`
+	PhaseID int       `db:"phase_id"`
+	Date    time.Time `db:"date"` // Last day of the weeks that led to it.
+	Weeks   int       `db:"weeks"`
+	// WeightChange is the average weekly weight change (lbs) over the
+	// weeks, and WeeklyChange the goal it missed.
+	WeightChange float64 `db:"weight_change"`
+	WeeklyChange float64 `db:"weekly_change"`
+	OldCalories  float64 `db:"old_calories"`
+	NewCalories  float64 `db:"new_calories"`
+	// Protein, Carbs, and Fats are the changes (g) to the macros.
+	Protein float64 `db:"protein"`
+	Carbs   float64 `db:"carbs"`
+	Fats    float64 `db:"fats"`
+}
+
+// String explains the adjustment, such as "2 weeks at -0.1 lbs vs
+// target -0.9 lbs ⇒ -175 calories a day, taken from fats 10g, carbs
+// 15g".
+func (a Adjustment) String() string {
+	s := fmt.Sprintf("%d weeks at %s lbs vs target %s lbs ⇒ %s calories a day",
+		a.Weeks, signedWeight(a.WeightChange), signedWeight(a.WeeklyChange),
+		localizeNumber(fmt.Sprintf("%+.0f", a.NewCalories-a.OldCalories)))
+
+	var taken, added []string
+	for _, m := range []struct {
+		name   string
+		change float64
+	}{{"fats", a.Fats}, {"carbs", a.Carbs}, {"protein", a.Protein}} {
+		switch g := math.Round(m.change); {
+		case g < 0:
+			taken = append(taken, fmt.Sprintf("%s %sg", m.name, formatNumber(-g, 0)))
+		case g > 0:
+			added = append(added, fmt.Sprintf("%s %sg", m.name, formatNumber(g, 0)))
+		}
+	}
+	if len(taken) > 0 {
+		s += ", taken from " + strings.Join(taken, ", ")
+	}
+	if len(added) > 0 {
+		s += ", added to " + strings.Join(added, ", ")
+	}
+	return s
+}
+
+// signedWeight formats a weight change with its sign.
+func signedWeight(w float64) string {
+	return localizeNumber(fmt.Sprintf("%+.*f", WeightDecimals, w))
+}
+
+// addAdjustmentsTable creates the calorie_adjustments table if it
+// doesn't exist, along with its audit triggers.
+func addAdjustmentsTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(adjustmentsSchema); err != nil {
+		return fmt.Errorf("couldn't create calorie adjustments table: %v", err)
+	}
+	return auditTable(tx, "calorie_adjustments")
+}
+
+// recordAdjustment saves the adjustment.
+func recordAdjustment(tx *sqlx.Tx, a *Adjustment) error {
+	if err := addAdjustmentsTable(tx); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO calorie_adjustments (phase_id, date, weeks, weight_change, weekly_change,
+			old_calories, new_calories, protein, carbs, fats)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	res, err := tx.Exec(query, a.PhaseID, a.Date.Format(dateFormat), a.Weeks, a.WeightChange,
+		a.WeeklyChange, a.OldCalories, a.NewCalories, a.Protein, a.Carbs, a.Fats)
+	if err != nil {
+		return fmt.Errorf("couldn't record calorie adjustment: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("couldn't get calorie adjustment id: %v", err)
+	}
+	a.ID = int(id)
+	return nil
+}
+
+// deleteAdjustments deletes the adjustments of the phase with the
+// given id.
+func deleteAdjustments(tx *sqlx.Tx, phaseID int) error {
+	if err := addAdjustmentsTable(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM calorie_adjustments WHERE phase_id = $1`, phaseID); err != nil {
+		return fmt.Errorf("couldn't delete calorie adjustments: %v", err)
+	}
+	return nil
+}
+
+// Adjustments returns the adjustments of the phase with the given id,
+// ordered by date.
+func Adjustments(tx *sqlx.Tx, phaseID int) ([]Adjustment, error) {
+	if err := addAdjustmentsTable(tx); err != nil {
+		return nil, err
+	}
+	var as []Adjustment
+	const query = `SELECT * FROM calorie_adjustments WHERE phase_id = $1 ORDER BY date, id`
+	if err := tx.Select(&as, query, phaseID); err != nil {
+		return nil, fmt.Errorf("couldn't get calorie adjustments: %v", err)
+	}
+	return as, nil
+}
+
+// AdjustmentSummary prints the calorie adjustments of the user's diet
+// phase and why each was made.
+func AdjustmentSummary(db *sqlx.DB, u *UserInfo) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	as, err := Adjustments(tx, u.Phase.PhaseID)
+	if err != nil {
+		return err
+	}
+	if len(as) == 0 {
+		fmt.Println("No calorie adjustments for this diet phase.")
+		return tx.Commit()
+	}
+	for _, a := range as {
+		fmt.Printf("%s  %.0f -> %.0f  %s\n", a.Date.Format(dateFormat), a.OldCalories, a.NewCalories, a)
+	}
+	return tx.Commit()
+}
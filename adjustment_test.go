@@ -0,0 +1,14 @@
+package bite
+
+import "fmt"
+
+func ExampleAdjustment_String() {
+	cut := Adjustment{Weeks: 2, WeightChange: -0.1, WeeklyChange: -0.9, OldCalories: 2200, NewCalories: 2025, Fats: -10, Carbs: -15}
+	fmt.Println(cut)
+	bulk := Adjustment{Weeks: 2, WeightChange: 0.1, WeeklyChange: 0.5, OldCalories: 2800, NewCalories: 3000, Carbs: 40, Fats: 4.2}
+	fmt.Println(bulk)
+
+	// Output:
+	// 2 weeks at -0.1 lbs vs target -0.9 lbs ⇒ -175 calories a day, taken from fats 10g, carbs 15g
+	// 2 weeks at +0.1 lbs vs target +0.5 lbs ⇒ +200 calories a day, added to fats 4g, carbs 40g
+}
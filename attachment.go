@@ -0,0 +1,150 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// attachmentsTable creates the attachments table in databases made
+// before it existed.
+const attachmentsTable = `
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY,
+		entry_table TEXT NOT NULL CHECK (entry_table IN ('daily_foods', 'daily_weights')),
+		entry_id INTEGER NOT NULL,
+		path TEXT NOT NULL,
+		created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')) NOT NULL
+	)
+`
+
+// Attachment is a local file, such as a progress or meal photo, that a
+// weight or food entry refers to.
+type Attachment struct {
+	ID         int    `db:"id"`
+	EntryTable string `db:"entry_table"` // "daily_foods" or "daily_weights".
+	EntryID    int    `db:"entry_id"`
+	Path       string `db:"path"`
+	CreatedAt  string `db:"created_at"`
+
+	// Date and Entry describe the entry: its date and the food name or
+	// weight.
+	Date  string `db:"date"`
+	Entry string `db:"entry"`
+}
+
+// addAttachment makes the entry refer to the file at path, which must
+// exist. The path is stored as an absolute path.
+func addAttachment(tx *sqlx.Tx, table string, entryID int, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("couldn't get absolute path of %s: %v", path, err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return fmt.Errorf("couldn't attach file: %v", err)
+	}
+
+	const query = `
+		INSERT INTO attachments (entry_table, entry_id, path)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(query, table, entryID, abs); err != nil {
+		return fmt.Errorf("couldn't save attachment: %v", err)
+	}
+	return nil
+}
+
+// deleteAttachments removes the attachments of an entry. The files
+// themselves are left alone.
+func deleteAttachments(tx *sqlx.Tx, table string, entryID int) error {
+	const query = `DELETE FROM attachments WHERE entry_table = $1 AND entry_id = $2`
+	if _, err := tx.Exec(query, table, entryID); err != nil {
+		return fmt.Errorf("couldn't delete attachments: %v", err)
+	}
+	return nil
+}
+
+// AttachWeight makes the weight entry of the given date refer to the
+// file at path.
+func AttachWeight(db *sqlx.DB, date time.Time, path string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int
+	const query = `SELECT id FROM daily_weights WHERE date = $1 LIMIT 1`
+	if err := tx.Get(&id, query, date.Format(dateFormat)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no weight logged on %s", date.Format(dateFormat))
+		}
+		return fmt.Errorf("couldn't find weight entry: %v", err)
+	}
+	if err := addAttachment(tx, "daily_weights", id, path); err != nil {
+		return err
+	}
+	fmt.Println("Attached file to weight entry.")
+	return tx.Commit()
+}
+
+// AttachFood prompts the user to select a food entry and makes it refer
+// to the file at path.
+func AttachFood(db *sqlx.DB, path string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	e, err := selectFoodEntry(tx)
+	if err != nil {
+		return err
+	}
+	if err := addAttachment(tx, "daily_foods", e.ID, path); err != nil {
+		return err
+	}
+	fmt.Println("Attached file to food entry.")
+	return tx.Commit()
+}
+
+// Attachments returns every attachment with the date and description of
+// its entry, ordered by date.
+func Attachments(q sqlx.Queryer) ([]Attachment, error) {
+	const query = `
+		SELECT a.id, a.entry_table, a.entry_id, a.path, a.created_at,
+			COALESCE(CAST(COALESCE(df.date, dw.date) AS TEXT), '') AS date,
+			COALESCE(f.food_name, printf('%.1f', dw.weight), '') AS entry
+		FROM attachments a
+		LEFT JOIN daily_foods df ON a.entry_table = 'daily_foods' AND df.id = a.entry_id
+		LEFT JOIN foods f ON f.food_id = df.food_id
+		LEFT JOIN daily_weights dw ON a.entry_table = 'daily_weights' AND dw.id = a.entry_id
+		ORDER BY date, a.id
+	`
+	var as []Attachment
+	if err := sqlx.Select(q, &as, query); err != nil {
+		return nil, fmt.Errorf("couldn't get attachments: %v", err)
+	}
+	return as, nil
+}
+
+// PrintAttachments prints the attachments and the entries they belong
+// to.
+func PrintAttachments(as []Attachment) {
+	if len(as) == 0 {
+		fmt.Println("No attachments.")
+		return
+	}
+	for _, a := range as {
+		kind := "Food"
+		if a.EntryTable == "daily_weights" {
+			kind = "Weight"
+		}
+		fmt.Printf("%s %-6s %-30s %s\n", a.Date, kind, a.Entry, a.Path)
+	}
+}
@@ -0,0 +1,71 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func ExampleAttachments() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	photo := filepath.Join(dir, "photo.jpg")
+	if err := os.WriteFile(photo, nil, 0o600); err != nil {
+		log.Println(err)
+		return
+	}
+
+	laptop := syncTestDB()
+	defer laptop.Close()
+	home := syncTestDB()
+	defer home.Close()
+
+	laptop.MustExec(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (1, '2024-01-02', '08:00:00', 100, 52, 0.3, 0.2, 14);
+		INSERT INTO daily_weights (date, time, weight) VALUES ('2024-01-01', '07:00:00', 180);
+	`)
+
+	tx := laptop.MustBegin()
+	if err := addAttachment(tx, "daily_weights", 1, photo); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := addAttachment(tx, "daily_foods", 1, photo); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(addAttachment(tx, "daily_foods", 1, filepath.Join(dir, "missing.jpg")) != nil)
+	tx.Commit()
+
+	// The references are exported along with the entries.
+	_, r, err := transfer(laptop, home, time.Time{})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	as, err := Attachments(home)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for i := range as {
+		as[i].Path = strings.TrimPrefix(as[i].Path, dir+string(filepath.Separator))
+	}
+	PrintAttachments(as)
+
+	// Output:
+	// true
+	// 4 inserted, 0 updated, 0 deleted, 0 skipped, 0 duplicates, 0 conflicts
+	// 2024-01-01 Weight 180.0                          photo.jpg
+	// 2024-01-02 Food   Apple                          photo.jpg
+}
@@ -0,0 +1,394 @@
+package bite
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// auditSchema creates the audit_log table, which records every insert,
+// update, and delete of the audited tables. Triggers made by
+// EnableAudit write the entries; the command that made the change and
+// the user who ran it are filled in through AttributeChanges.
+const auditSchema = `
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id INTEGER PRIMARY KEY,
+		time TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')) NOT NULL,
+		command TEXT DEFAULT '' NOT NULL,
+		user TEXT DEFAULT '' NOT NULL,
+		table_name TEXT NOT NULL,
+		action TEXT NOT NULL CHECK(action IN ('insert', 'update', 'delete')),
+		row_id INTEGER NOT NULL,
+		before TEXT,
+		after TEXT,
+		run INTEGER DEFAULT 0 NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log(time);
+`
+
+// auditRunSchema creates the temporary table holding the command run
+// through a connection, and the trigger that copies it into each audit
+// log entry written through the connection. Temporary objects belong to
+// the connection alone, so changes made by other commands at the same
+// time, or by other programs, aren't attributed to it.
+const auditRunSchema = `
+	CREATE TEMP TABLE IF NOT EXISTS audit_run (
+		command TEXT NOT NULL,
+		user TEXT NOT NULL,
+		run INTEGER DEFAULT 0 NOT NULL
+	);
+
+	CREATE TEMP TRIGGER IF NOT EXISTS audit_attribute AFTER INSERT ON main.audit_log
+	WHEN EXISTS (SELECT 1 FROM audit_run)
+	BEGIN
+		UPDATE audit_run SET run = new.id WHERE run = 0;
+		UPDATE audit_log SET
+			command = (SELECT command FROM audit_run),
+			user = (SELECT user FROM audit_run),
+			run = (SELECT run FROM audit_run)
+		WHERE id = new.id;
+	END;
+`
+
+// auditSkip are the tables that aren't audited: the audit log itself
+// and the tables that are kept up to date from others.
+var auditSkip = map[string]bool{
+	"audit_log":      true,
+	"daily_rollups":  true,
+	"sync_deletions": true,
+}
+
+// AuditEntry is a change of a row recorded in the audit log. Before
+// and After hold the row as a JSON object, and are empty for inserts
+// and deletes respectively.
+type AuditEntry struct {
+	ID      int64   `db:"id"`
+	Time    string  `db:"time"` // In SyncTimeFormat.
+	Command string  `db:"command"`
+	User    string  `db:"user"`
+	Table   string  `db:"table_name"`
+	Action  string  `db:"action"`
+	RowID   int64   `db:"row_id"`
+	Before  *string `db:"before"`
+	After   *string `db:"after"`
+	// Run is the id of the first change of the command run that made
+	// the change, or zero if it isn't known.
+	Run int64 `db:"run"`
+}
+
+// EnableAudit creates the audit log and the triggers that write to it.
+// Triggers are made for tables added since it was last called and
+// remade for tables whose columns changed, so it is cheap to call each
+// time the database is opened. Since it changes the schema when it
+// makes triggers, it should only then be called with the database held
+// exclusively; AuditCurrent reports whether it would.
+func EnableAudit(db *sqlx.DB) error {
+	want, ok, err := staleAuditTriggers(db)
+	if err != nil {
+		return err
+	}
+	if len(want) == 0 && ok {
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(auditSchema); err != nil {
+		return fmt.Errorf("couldn't create audit log: %v", err)
+	}
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := tx.Exec(fmt.Sprintf(`DROP TRIGGER IF EXISTS "%s"`, name)); err != nil {
+			return fmt.Errorf("couldn't drop trigger %s: %v", name, err)
+		}
+		if _, err := tx.Exec(want[name]); err != nil {
+			return fmt.Errorf("couldn't create trigger %s: %v", name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// AuditCurrent reports whether the audit log and its triggers are up to
+// date, so that EnableAudit has nothing to change.
+func AuditCurrent(db *sqlx.DB) (bool, error) {
+	want, ok, err := staleAuditTriggers(db)
+	return len(want) == 0 && ok, err
+}
+
+// staleAuditTriggers returns the statements creating the audit triggers
+// that are missing or out of date by trigger name, and whether the
+// audit log exists. The triggers are worked out without taking the
+// write lock, so that checking an up to date database doesn't write to
+// it.
+func staleAuditTriggers(db *sqlx.DB) (map[string]string, bool, error) {
+	var tables []string
+	const tablesSQL = `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+			AND COALESCE(sql, '') NOT LIKE 'CREATE VIRTUAL TABLE%'
+	`
+	if err := db.Select(&tables, tablesSQL); err != nil {
+		return nil, false, fmt.Errorf("couldn't get tables: %v", err)
+	}
+	var triggers []struct {
+		Name string `db:"name"`
+		SQL  string `db:"sql"`
+	}
+	const triggersSQL = `
+		SELECT name, sql FROM sqlite_master
+		WHERE type = 'trigger' AND name LIKE 'audit\_%' ESCAPE '\'
+	`
+	if err := db.Select(&triggers, triggersSQL); err != nil {
+		return nil, false, fmt.Errorf("couldn't get audit triggers: %v", err)
+	}
+	existing := make(map[string]string)
+	for _, t := range triggers {
+		existing[t.Name] = t.SQL
+	}
+
+	want := make(map[string]string)
+	for _, t := range auditTables(tables) {
+		var cols []string
+		if err := db.Select(&cols, `SELECT name FROM pragma_table_info($1)`, t); err != nil {
+			return nil, false, fmt.Errorf("couldn't get %s columns: %v", t, err)
+		}
+		for name, sql := range auditTriggers(t, cols) {
+			if existing[name] != sql {
+				want[name] = sql
+			}
+		}
+	}
+	return want, contains(tables, "audit_log"), nil
+}
+
+// auditTables returns the tables that are audited, leaving out those
+// in auditSkip and those storing the virtual tables, such as
+// "foods_fts_data".
+func auditTables(tables []string) []string {
+	var audited []string
+outer:
+	for _, t := range tables {
+		if auditSkip[t] {
+			continue
+		}
+		for _, fts := range []string{"foods_fts_", "meals_fts_"} {
+			if strings.HasPrefix(t, fts) {
+				continue outer
+			}
+		}
+		audited = append(audited, t)
+	}
+	return audited
+}
+
+// auditTriggers returns the statements creating the insert, update, and
+// delete triggers of the table by trigger name.
+func auditTriggers(table string, cols []string) map[string]string {
+	row := func(ref string) string {
+		pairs := make([]string, len(cols))
+		for i, c := range cols {
+			pairs[i] = fmt.Sprintf(`'%s', %s."%s"`, c, ref, c)
+		}
+		return "json_object(" + strings.Join(pairs, ", ") + ")"
+	}
+	const trigger = `CREATE TRIGGER "audit_%[1]s_%[2]s" AFTER %[3]s ON "%[1]s"
+BEGIN
+  INSERT INTO audit_log (table_name, action, row_id, before, after)
+  VALUES ('%[1]s', '%[2]s', %[4]s.rowid, %[5]s, %[6]s);
+END`
+	return map[string]string{
+		"audit_" + table + "_insert": fmt.Sprintf(trigger, table, "insert", "INSERT", "new", "NULL", row("new")),
+		"audit_" + table + "_update": fmt.Sprintf(trigger, table, "update", "UPDATE", "new", row("old"), row("new")),
+		"audit_" + table + "_delete": fmt.Sprintf(trigger, table, "delete", "DELETE", "old", row("old"), "NULL"),
+	}
+}
+
+// auditTable creates the audit triggers of a table made after
+// EnableAudit was called, if the database has an audit log.
+func auditTable(tx *sqlx.Tx, table string) error {
+	var n int
+	const query = `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE (type = 'table' AND name = 'audit_log')
+			OR (type = 'trigger' AND name = $1)
+	`
+	if err := tx.Get(&n, query, "audit_"+table+"_insert"); err != nil {
+		return fmt.Errorf("couldn't get audit triggers: %v", err)
+	}
+	if n != 1 { // No audit log, or the triggers already exist.
+		return nil
+	}
+
+	var cols []string
+	if err := tx.Select(&cols, `SELECT name FROM pragma_table_info($1)`, table); err != nil {
+		return fmt.Errorf("couldn't get %s columns: %v", table, err)
+	}
+	triggers := auditTriggers(table, cols)
+	names := make([]string, 0, len(triggers))
+	for name := range triggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := tx.Exec(triggers[name]); err != nil {
+			return fmt.Errorf("couldn't create trigger %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// AttributeChanges records the command and the user that ran it with
+// each change made through db from then on, and groups the changes into
+// a run of the command. The changes are attributed by a trigger as they
+// are logged, in the transaction that makes them, so the audit log must
+// exist. An empty command stops attributing changes, and calling it
+// again starts a new run.
+//
+// The command is kept with a connection of db, so db is limited to that
+// one connection.
+func AttributeChanges(db *sqlx.DB, command, user string) error {
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := addColumns(tx, "audit_log", "run INTEGER DEFAULT 0 NOT NULL"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(auditRunSchema); err != nil {
+		return fmt.Errorf("couldn't create audit trigger: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM temp.audit_run`); err != nil {
+		return fmt.Errorf("couldn't update audit command: %v", err)
+	}
+	if command != "" {
+		const query = `INSERT INTO temp.audit_run (command, user) VALUES ($1, $2)`
+		if _, err := tx.Exec(query, command, user); err != nil {
+			return fmt.Errorf("couldn't update audit command: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// AuditLog returns the audit log entries made since the given time,
+// oldest first.
+func AuditLog(db *sqlx.DB, since time.Time) ([]AuditEntry, error) {
+	const query = `
+		SELECT * FROM audit_log
+		WHERE time >= $1
+		ORDER BY id
+	`
+	var entries []AuditEntry
+	if err := db.Select(&entries, query, since.UTC().Format(SyncTimeFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't read audit log: %v", err)
+	}
+	return entries, nil
+}
+
+// WriteAuditLog writes the entries, grouped by the command that made
+// them. Inserts and deletes are written with the whole row and updates
+// with the columns that changed. Times are written in Location.
+func WriteAuditLog(w io.Writer, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+	for i, e := range entries {
+		if i == 0 || e.Command != entries[i-1].Command || e.User != entries[i-1].User {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			command := e.Command
+			if command == "" {
+				command = "(unknown command)"
+			}
+			at := e.Time
+			if t, err := time.Parse(SyncTimeFormat, e.Time); err == nil {
+				at = inLocation(t).Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s  %s", at, command)
+			if e.User != "" {
+				fmt.Fprintf(w, " (%s)", e.User)
+			}
+			fmt.Fprintln(w)
+		}
+
+		before, err := auditRow(e.Before)
+		if err != nil {
+			return err
+		}
+		after, err := auditRow(e.After)
+		if err != nil {
+			return err
+		}
+		var fields []string
+		switch e.Action {
+		case "insert":
+			fields = rowFields(after, nil)
+		case "delete":
+			fields = rowFields(before, nil)
+		default:
+			fields = rowFields(after, before)
+		}
+		if _, err := fmt.Fprintf(w, "  %s %s %d: %s\n", e.Action, e.Table, e.RowID, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditRow decodes a row of the audit log.
+func auditRow(s *string) (map[string]interface{}, error) {
+	if s == nil {
+		return nil, nil
+	}
+	var row map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(*s))
+	d.UseNumber()
+	if err := d.Decode(&row); err != nil {
+		return nil, fmt.Errorf("couldn't decode audit log row: %v", err)
+	}
+	return row, nil
+}
+
+// rowFields formats the columns of the row as "col=value", sorted by
+// name. If before is given, only the columns that changed are written,
+// as "col=old→new".
+func rowFields(row, before map[string]interface{}) []string {
+	names := make([]string, 0, len(row))
+	for c := range row {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+
+	var fields []string
+	for _, c := range names {
+		v := formatValue(row[c])
+		if before == nil {
+			fields = append(fields, c+"="+v)
+			continue
+		}
+		if old := formatValue(before[c]); old != v {
+			fields = append(fields, c+"="+old+"→"+v)
+		}
+	}
+	return fields
+}
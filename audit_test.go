@@ -0,0 +1,133 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExampleEnableAudit() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-18', '07:00:00', 180);
+	`)
+	defer db.Close()
+
+	if err := EnableAudit(db); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := AttributeChanges(db, "bite log weight 179", "sam"); err != nil {
+		log.Println(err)
+		return
+	}
+	db.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-19', '07:00:00', 179.5)`)
+	db.MustExec(`UPDATE daily_weights SET weight = 179 WHERE date = '2024-03-19'`)
+	if err := AttributeChanges(db, "", ""); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// A column added later is audited once the triggers are remade.
+	db.MustExec(`ALTER TABLE daily_weights ADD COLUMN note TEXT DEFAULT '' NOT NULL`)
+	if err := EnableAudit(db); err != nil {
+		log.Println(err)
+		return
+	}
+	db.MustExec(`DELETE FROM daily_weights WHERE date = '2024-03-18'`)
+
+	entries, err := AuditLog(db, time.Now().Add(-time.Hour))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer func(l *time.Location) { Location = l }(Location)
+	Location = time.FixedZone("CET", 60*60)
+	for i := range entries {
+		entries[i].Time = "2024-03-19T08:00:00.000Z"
+	}
+	if err := WriteAuditLog(os.Stdout, entries); err != nil {
+		log.Println(err)
+	}
+
+	// Output:
+	// 2024-03-19 09:00:00  bite log weight 179 (sam)
+	//   insert daily_weights 2: date=2024-03-19 estimated=0 id=2 logged_at="" time=07:00:00 utc_offset=0 weight=179.5
+	//   update daily_weights 2: weight=179.5→179.0
+	//
+	// 2024-03-19 09:00:00  (unknown command)
+	//   delete daily_weights 1: date=2024-03-18 estimated=0 id=1 logged_at="" note="" time=07:00:00 utc_offset=0 weight=180.0
+}
+
+func ExampleAttributeChanges() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bite.db")
+	if err := dbtest.Create(path); err != nil {
+		log.Fatal(err)
+	}
+
+	// Two commands write to the database at once, each through its own
+	// connection.
+	open := func(command string) *sqlx.DB {
+		db, err := Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := EnableAudit(db); err != nil {
+			log.Fatal(err)
+		}
+		if err := AttributeChanges(db, command, "sam"); err != nil {
+			log.Fatal(err)
+		}
+		return db
+	}
+	a := open("bite log weight 180")
+	defer a.Close()
+	b := open("bite import weights.csv")
+	defer b.Close()
+	a.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-19', '07:00:00', 180)`)
+	b.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-19', '07:00:00', 181)`)
+	a.MustExec(`UPDATE daily_weights SET weight = 179.8 WHERE weight = 180`)
+	b.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-19', '07:00:00', 182)`)
+
+	var entries []AuditEntry
+	if err := a.Select(&entries, `SELECT * FROM audit_log ORDER BY id`); err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		fmt.Println(e.ID, e.Run, e.Action, e.Command)
+	}
+	// Output:
+	// 1 1 insert bite log weight 180
+	// 2 2 insert bite import weights.csv
+	// 3 1 update bite log weight 180
+	// 4 2 insert bite import weights.csv
+}
+
+func ExampleParseSince() {
+	now := time.Date(2024, 3, 19, 8, 30, 0, 0, time.UTC)
+	for _, s := range []string{"7d", "12h", "2w", "2024-03-01", "soon"} {
+		t, err := ParseSince(s, now)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(t.Format(time.RFC3339))
+	}
+
+	// Output:
+	// 2024-03-12T08:30:00Z
+	// 2024-03-18T20:30:00Z
+	// 2024-03-05T08:30:00Z
+	// 2024-03-01T00:00:00Z
+	// since must be a date or a number of minutes, hours, days, or weeks such as 7d, got "soon"
+}
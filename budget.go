@@ -0,0 +1,113 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// WeeklyBudget is the most to spend on food a week, which starts on
+// WeekStart. Zero means no budget.
+var WeeklyBudget float64
+
+const (
+	// cheapWeight is how much a food's cost per calorie lowers it in
+	// search results while the budget is tight, relative to how well its
+	// name matches.
+	cheapWeight = 3.0
+	// cheapCost is the cost per 100 calories at which a food gets half
+	// of the cost penalty.
+	cheapCost = 0.5
+)
+
+// Budget is the spend of a week on food against WeeklyBudget.
+type Budget struct {
+	Limit float64
+	Spent float64
+	// Days is the number of days of the week so far, including today.
+	Days int
+}
+
+// Left returns the budget left to spend this week, negative when over.
+func (b Budget) Left() float64 {
+	return b.Limit - b.Spent
+}
+
+// Over reports whether the week's spend went over the budget.
+func (b Budget) Over() bool {
+	return b.Spent > b.Limit
+}
+
+// Tight reports whether the week's spend is at or ahead of the pace
+// that spends the budget by the end of the week.
+func (b Budget) Tight() bool {
+	return b.Spent >= b.Limit*float64(b.Days)/7
+}
+
+// weekBudget returns the spend of the week of now, up to and including
+// now's day, or nil if there is no weekly budget.
+func weekBudget(q sqlx.Queryer, now time.Time) (*Budget, error) {
+	if WeeklyBudget <= 0 {
+		return nil, nil
+	}
+	start := startOfWeek(now)
+	b := &Budget{
+		Limit: WeeklyBudget,
+		Days:  int(dateOf(now).Sub(start).Hours()/24) + 1,
+	}
+	const query = `
+		SELECT COALESCE(SUM(price), 0) FROM daily_foods
+		WHERE date BETWEEN $1 AND $2
+	`
+	if err := sqlx.Get(q, &b.Spent, query, start.Format(dateFormat), dateOf(now).Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get week's food spend: %v", err)
+	}
+	return b, nil
+}
+
+// budgetTight reports whether the budget of this week is tight.
+func budgetTight(q sqlx.Queryer) (bool, error) {
+	b, err := weekBudget(q, LogNow())
+	if err != nil || b == nil {
+		return false, err
+	}
+	return b.Tight(), nil
+}
+
+// cheapClauses returns the join of the calories of each of the foods
+// table's rows and the expression of the rows' penalty in rank for
+// their cost per calorie, between 0 and cheapWeight. Foods without a
+// price or calories get no penalty.
+func cheapClauses(idCol string) (string, string) {
+	join := fmt.Sprintf(`
+			LEFT JOIN (
+				SELECT food_id, amount AS calories FROM food_nutrients
+				WHERE nutrient_id = 1008
+			) c ON c.food_id = r.%s`, idCol)
+	// Cost is per PortionSize serving units, as are nutrient amounts.
+	const cost = `(r.cost * 100 / NULLIF(c.calories, 0))`
+	penalty := fmt.Sprintf(`(
+				%g * COALESCE(%s / (%s + %g), 0)
+			)`, cheapWeight, cost, cost, cheapCost)
+	return join, penalty
+}
+
+// printWeekBudget prints the week's spend on food against the weekly
+// budget.
+func printWeekBudget(days []Entry) {
+	if WeeklyBudget <= 0 {
+		return
+	}
+	var spent float64
+	for _, e := range days {
+		spent += e.Price
+	}
+	b := Budget{Limit: WeeklyBudget, Spent: spent}
+	if b.Over() {
+		fmt.Printf(tr("Spent $%s on food, $%s over the $%s weekly budget.\n"),
+			formatNumber(b.Spent, 2), formatNumber(-b.Left(), 2), formatNumber(b.Limit, 2))
+		return
+	}
+	fmt.Printf(tr("Spent $%s of the $%s weekly food budget.\n"), formatNumber(b.Spent, 2), formatNumber(b.Limit, 2))
+}
@@ -0,0 +1,55 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleBudget_Tight() {
+	db := dbtest.MustNew(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving, cost) VALUES
+			(4, 'Greek yogurt', 170, 'g', '', 5),
+			(5, 'Plain yogurt', 170, 'g', '', 0.1);
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(4, 1008, 100, 71), (5, 1008, 100, 71);
+		INSERT INTO foods_fts (food_id, food_name, brand_name)
+			SELECT food_id, food_name, brand_name FROM foods WHERE food_id > 3;
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price) VALUES
+			(4, '2024-01-18', '08:00:00', 170, 1, 170, 17, 3, 7, 8.5),
+			(2, '2024-03-18', '12:00:00', 100, 2, 330, 62, 7.2, 0, 45);
+	`)
+	defer db.Close()
+
+	defer SetClock(FixedClock(time.Date(2024, 3, 20, 9, 0, 0, 0, time.UTC)))()
+	defer func(b float64) { WeeklyBudget = b }(WeeklyBudget)
+
+	search := func() {
+		foods, err := SearchFoods(db, "yogurt")
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range foods {
+			fmt.Println(f.Name)
+		}
+	}
+	search()
+
+	// $45 is spent by Wednesday, ahead of the $21.43 pace of a $50 week.
+	WeeklyBudget = 50
+	b, err := weekBudget(db, Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(b.Spent, b.Left(), b.Days, b.Tight())
+	search()
+
+	// Output:
+	// Greek yogurt
+	// Plain yogurt
+	// 45 5 3 true
+	// Plain yogurt
+	// Greek yogurt
+}
@@ -0,0 +1,108 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CaffeineCutoff is the time of day ("15:04") after which caffeine is
+// flagged in the day summary, since late caffeine disturbs sleep, and
+// poor sleep raises water retention and hunger.
+var CaffeineCutoff = "14:00"
+
+// DayCaffeine is the caffeine (mg) of a day's food log.
+type DayCaffeine struct {
+	Total  float64
+	Late   float64 // Caffeine logged at or after Cutoff.
+	Cutoff string
+}
+
+// addCaffeineColumn adds the caffeine column, the caffeine (mg) of a
+// serving of a food, to food tables created before it existed.
+func addCaffeineColumn(tx *sqlx.Tx) error {
+	return addColumns(tx, "foods", "caffeine REAL DEFAULT 0 NOT NULL")
+}
+
+// SetFoodCaffeine flags a food as a caffeine source with the given
+// caffeine (mg) per serving. Zero removes the flag.
+func SetFoodCaffeine(tx *sqlx.Tx, foodID int, mg float64) error {
+	if mg < 0 {
+		return fmt.Errorf("caffeine can't be negative, got %v", mg)
+	}
+	if err := addCaffeineColumn(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE foods SET caffeine = $1 WHERE food_id = $2`, mg, foodID); err != nil {
+		return fmt.Errorf("couldn't set caffeine: %v", err)
+	}
+	return nil
+}
+
+// FlagCaffeine lets the user select a food and sets its caffeine (mg)
+// per serving.
+func FlagCaffeine(db *sqlx.DB, mg float64) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := SetFoodCaffeine(tx, food.ID, mg); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if mg == 0 {
+		fmt.Printf("%s is no longer a caffeine source.\n", food.Name)
+		return nil
+	}
+	fmt.Printf("A serving of %s (%g %s) has %s mg of caffeine.\n", food.Name, food.ServingSize, food.ServingUnit, formatNumber(mg, 0))
+	return nil
+}
+
+// caffeineOn returns the caffeine of the food entries of the day, or
+// nil if none of them had caffeine. Each entry has the caffeine of its
+// food's serving scaled to the amount eaten.
+func caffeineOn(tx *sqlx.Tx, date time.Time) (*DayCaffeine, error) {
+	if err := addCaffeineColumn(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT df.time, f.caffeine * df.serving_size * df.number_of_servings / f.serving_size
+		FROM daily_foods df
+		INNER JOIN foods f ON df.food_id = f.food_id
+		WHERE df.date = $1 AND f.caffeine > 0 AND f.serving_size > 0
+	`
+	rows, err := tx.Query(query, date.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get caffeine: %v", err)
+	}
+	defer rows.Close()
+
+	c := &DayCaffeine{Cutoff: CaffeineCutoff}
+	for rows.Next() {
+		var at string
+		var mg float64
+		if err := rows.Scan(&at, &mg); err != nil {
+			return nil, fmt.Errorf("couldn't get caffeine: %v", err)
+		}
+		c.Total += mg
+		if at >= CaffeineCutoff {
+			c.Late += mg
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't get caffeine: %v", err)
+	}
+	if c.Total == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
@@ -0,0 +1,62 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSetFoodCaffeine() {
+	db := dbtest.MustNew(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (4, 'Coffee', 240, 'g', '1 cup');
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(4, '2024-03-18', '08:30:00', 240, 1, 2, 0, 0, 0),
+			(3, '2024-03-18', '10:00:00', 118, 1, 105, 1.3, 0.4, 27),
+			(4, '2024-03-18', '15:45:00', 120, 1, 1, 0, 0, 0);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	if err := SetFoodCaffeine(tx, 4, 95); err != nil {
+		log.Fatal(err)
+	}
+
+	// Half a cup of coffee in the afternoon is after the cutoff.
+	day := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	r := &DayReport{
+		Date:      day,
+		Nutrition: Nutrition{Calories: 108, Protein: 1.3, Fat: 0.4, Carbs: 27},
+		Goal:      Nutrition{Calories: 2000, Protein: 150, Fat: 70, Carbs: 200},
+	}
+	var err error
+	if r.Caffeine, err = caffeineOn(tx, day); err != nil {
+		log.Fatal(err)
+	}
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		fmt.Println(err)
+	}
+
+	// Days without caffeine leave it out.
+	c, err := caffeineOn(tx, day.AddDate(0, 0, 1))
+	fmt.Println(c, err)
+
+	fmt.Println(SetFoodCaffeine(tx, 4, -1))
+	// Output:
+	// Protein:  [▒▒▒▒▒▒▒▒▒▒]   1% (1g / 150g)
+	// Fat:      [▒▒▒▒▒▒▒▒▒▒]   1% (0g / 70g)
+	// Carbs:    [█▒▒▒▒▒▒▒▒▒]  14% (27g / 200g)
+	// Calories: [▒▒▒▒▒▒▒▒▒▒]   5% (108 / 2000)
+	// Macros:   P 4% / C 92% / F 3% of calories
+	//
+	// 1892.00 calories remaining.
+	// Eaten $0.00 worth of food today.
+	// Caffeine: 142 mg.
+	// Warning: 48 mg of caffeine after 14:00 can disturb sleep and hide weight trends.
+	// <nil> <nil>
+	// caffeine can't be negative, got -1
+}
@@ -0,0 +1,198 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// lowCheckInRating is the highest rating that counts as low.
+	lowCheckInRating = 2
+	// lowCheckInWeeks is the number of consecutive weeks of low hunger
+	// or energy ratings after which a diet break is recommended.
+	lowCheckInWeeks = 2
+)
+
+// CheckIn holds the user's subjective ratings for a week. Ratings range
+// from 1 (worst) to 5 (best).
+type CheckIn struct {
+	Date        time.Time `db:"date"`
+	Hunger      int       `db:"hunger"` // 1 is very hungry, 5 is not hungry.
+	Energy      int       `db:"energy"`
+	Sleep       int       `db:"sleep"`
+	Performance int       `db:"performance"` // Gym performance.
+	Notes       string    `db:"notes"`       // How the week went, in the user's words.
+}
+
+// low reports whether hunger or energy was rated low.
+func (c CheckIn) low() bool {
+	return c.Hunger <= lowCheckInRating || c.Energy <= lowCheckInRating
+}
+
+// LogCheckIn prompts the user for this week's check-in and stores it.
+func LogCheckIn(db *sqlx.DB, u *UserInfo) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fmt.Println(tr("Rate the past week from 1 (worst) to 5 (best)."))
+	c := CheckIn{
+		Date:        LogNow(),
+		Hunger:      getRating(tr("Hunger (1 = very hungry, 5 = not hungry)")),
+		Energy:      getRating(tr("Energy")),
+		Sleep:       getRating(tr("Sleep quality")),
+		Performance: getRating(tr("Gym performance")),
+		Notes:       promptNotes(),
+	}
+	if err := addCheckIn(tx, c); err != nil {
+		return err
+	}
+
+	if u.Phase.Status == "active" {
+		if err := checkCheckIns(tx, u); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// addCheckInColumns adds the notes column to check-in tables created
+// before it existed.
+func addCheckInColumns(tx *sqlx.Tx) error {
+	return addColumns(tx, "checkins", "notes TEXT DEFAULT '' NOT NULL")
+}
+
+// addCheckIn stores a check-in. It replaces any check-in from the same
+// day.
+func addCheckIn(tx *sqlx.Tx, c CheckIn) error {
+	if err := addCheckInColumns(tx); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO checkins (date, hunger, energy, sleep, performance, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT(date) DO UPDATE SET
+			hunger = $2, energy = $3, sleep = $4, performance = $5, notes = $6
+	`
+	_, err := tx.Exec(query, c.Date.Format(dateFormat), c.Hunger, c.Energy, c.Sleep, c.Performance, c.Notes)
+	if err != nil {
+		return fmt.Errorf("couldn't save check-in: %v", err)
+	}
+	return nil
+}
+
+// phaseCheckIns returns the check-ins since the start of the user's
+// diet phase, ordered by date.
+func phaseCheckIns(tx *sqlx.Tx, u *UserInfo) ([]CheckIn, error) {
+	const query = `
+		SELECT date, hunger, energy, sleep, performance
+		FROM checkins
+		WHERE date >= $1
+		ORDER BY date
+	`
+	var checkins []CheckIn
+	if err := tx.Select(&checkins, query, u.Phase.StartDate.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get check-ins: %v", err)
+	}
+	return checkins, nil
+}
+
+// lowWeeks returns the number of consecutive phase weeks, ending with
+// the most recent check-in, whose last check-in rated hunger or energy
+// low. Weeks are counted in 7 day steps from the phase start date, and
+// the check-ins are expected in date order.
+func lowWeeks(u *UserInfo, checkins []CheckIn) int {
+	// Keep the last check-in of each week.
+	weeks := make(map[int]CheckIn)
+	last := -1
+	for _, c := range checkins {
+		w := int(c.Date.Sub(u.Phase.StartDate).Hours() / 24 / 7)
+		weeks[w] = c
+		if w > last {
+			last = w
+		}
+	}
+
+	n := 0
+	for w := last; w >= 0; w-- {
+		c, ok := weeks[w]
+		if !ok || !c.low() {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// checkCheckIns recommends a diet break when hunger or energy have been
+// rated low for lowCheckInWeeks weeks in a row during a cut.
+func checkCheckIns(tx *sqlx.Tx, u *UserInfo) error {
+	if u.Phase.Name != "cut" {
+		return nil
+	}
+
+	checkins, err := phaseCheckIns(tx, u)
+	if err != nil {
+		return err
+	}
+	n := lowWeeks(u, checkins)
+	if n < lowCheckInWeeks {
+		return nil
+	}
+
+	c := checkins[len(checkins)-1]
+	fmt.Printf(tr("Your hunger or energy has been low for %d weeks in a row (hunger %d/5, energy %d/5, sleep %d/5, gym performance %d/5 this week).\n"),
+		n, c.Hunger, c.Energy, c.Sleep, c.Performance)
+	fmt.Println(tr("Taking a diet break, 1-2 weeks of eating at maintenance, is recommended before continuing the cut."))
+	return nil
+}
+
+// getRating prompts the user for a rating, validates their response,
+// and returns the valid rating.
+func getRating(name string) (r int) {
+	var err error
+	for {
+		r, err = validateRating(promptRating(name))
+		if err != nil {
+			fmt.Println(tr("Rating must be a number from 1 to 5. Please try again."))
+			continue
+		}
+
+		break
+	}
+	return r
+}
+
+// promptRating prompts the user for a rating and returns it as a
+// string.
+func promptRating(name string) (s string) {
+	fmt.Printf(tr("%s (1-5): "), name)
+	fmt.Fscanln(Input, &s)
+	return s
+}
+
+// promptNotes prompts the user for optional notes on the week and
+// returns them.
+func promptNotes() string {
+	fmt.Print(tr("Notes (optional): "))
+	s, _ := Input.ReadString('\n')
+	return strings.TrimSpace(s)
+}
+
+// validateRating validates a rating and returns it as an int if valid.
+func validateRating(s string) (int, error) {
+	r, err := strconv.Atoi(s)
+	if err != nil || r < 1 || r > 5 {
+		return 0, errors.New("Invalid rating.")
+	}
+
+	return r, nil
+}
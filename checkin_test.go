@@ -0,0 +1,73 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleLowWeeks() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.StartDate = start
+
+	checkins := []CheckIn{
+		{Date: start.AddDate(0, 0, 6), Hunger: 2, Energy: 4},
+		{Date: start.AddDate(0, 0, 13), Hunger: 4, Energy: 4},
+		{Date: start.AddDate(0, 0, 20), Hunger: 3, Energy: 2},
+	}
+	fmt.Println(lowWeeks(&u, checkins))
+
+	checkins = append(checkins, CheckIn{Date: start.AddDate(0, 0, 27), Hunger: 1, Energy: 2})
+	fmt.Println(lowWeeks(&u, checkins))
+
+	// The last check-in of a week counts.
+	checkins = append(checkins, CheckIn{Date: start.AddDate(0, 0, 28), Hunger: 2, Energy: 2})
+	checkins = append(checkins, CheckIn{Date: start.AddDate(0, 0, 30), Hunger: 4, Energy: 4})
+	fmt.Println(lowWeeks(&u, checkins))
+
+	// Output:
+	// 1
+	// 2
+	// 0
+}
+
+func ExampleCheckCheckIns() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.StartDate = start
+
+	checkins := []CheckIn{
+		{Date: start.AddDate(0, 0, 6), Hunger: 4, Energy: 4, Sleep: 4, Performance: 4},
+		{Date: start.AddDate(0, 0, 13), Hunger: 2, Energy: 3, Sleep: 3, Performance: 3},
+		{Date: start.AddDate(0, 0, 20), Hunger: 1, Energy: 2, Sleep: 3, Performance: 2},
+	}
+	for _, c := range checkins {
+		if err := addCheckIn(tx, c); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+
+	if err := checkCheckIns(tx, &u); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Output:
+	// Your hunger or energy has been low for 2 weeks in a row (hunger 1/5, energy 2/5, sleep 3/5, gym performance 2/5 this week).
+	// Taking a diet break, 1-2 weeks of eating at maintenance, is recommended before continuing the cut.
+}
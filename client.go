@@ -0,0 +1,174 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNoUserInfo is returned by Client methods that need the user's
+// info before it has been set up with the bite command.
+var ErrNoUserInfo = errors.New("user info hasn't been set up")
+
+// Client is the API for Go programs that use bite as a library. Its
+// methods don't prompt or print, and their signatures are kept stable
+// between versions. The package's other exported identifiers serve the
+// bite command and may change as it does.
+type Client struct {
+	db *sqlx.DB
+}
+
+// NewClient opens the plaintext database at path. Changes made through
+// the client are recorded in the audit log.
+func NewClient(path string) (*Client, error) {
+	db, err := Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open database: %v", err)
+	}
+	if err := AddTables(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := EnableAudit(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Client{db: db}, nil
+}
+
+// NewClientDB returns a client for an already open database, such as
+// one returned by OpenEncrypted.
+func NewClientDB(db *sqlx.DB) *Client {
+	return &Client{db: db}
+}
+
+// Close closes the database.
+func (c *Client) Close() error {
+	return c.db.Close()
+}
+
+// LogFood logs a number of servings of the food at the given time and
+// returns the nutrition of the entry. The serving size is in the food's
+// serving unit. If servingSize or servings isn't positive, the user's
+// preferred serving of the food is used.
+func (c *Client) LogFood(foodID int, servingSize, servings float64, at time.Time) (Nutrition, error) {
+	if servingSize <= 0 || servings <= 0 {
+		f, err := FoodWithPref(c.db, foodID)
+		if err != nil {
+			return Nutrition{}, err
+		}
+		servingSize, servings = f.ServingSize, f.NumberOfServings
+	}
+	per, err := foodNutrition(c.db, foodID)
+	if err != nil {
+		return Nutrition{}, err
+	}
+	n := per.times(servingSize / PortionSize * servings)
+
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return Nutrition{}, err
+	}
+	defer tx.Rollback()
+
+	f := &Food{
+		ID:               foodID,
+		ServingSize:      servingSize,
+		NumberOfServings: servings,
+		Calories:         n.Calories,
+		FoodMacros:       &FoodMacros{Protein: n.Protein, Fat: n.Fat, Carbs: n.Carbs},
+		Price:            n.Price,
+	}
+	if err := AddFoodEntry(tx, f, at); err != nil {
+		return Nutrition{}, err
+	}
+	return n, tx.Commit()
+}
+
+// LogWeight logs the user's weight, in pounds, for the date and makes
+// it their current weight. A weight already logged for the date is an
+// error.
+func (c *Client) LogWeight(weight float64, date time.Time) error {
+	if weight <= 0 {
+		return errors.New("weight must be positive")
+	}
+
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertWeightEntry(tx, date, weight); err != nil {
+		return err
+	}
+	u, err := c.userInfo(tx)
+	if err != nil && !errors.Is(err, ErrNoUserInfo) {
+		return err
+	}
+	if u != nil {
+		u.Weight = weight
+		if err := insertOrUpdateUserInfo(tx, u); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DaySummary returns the nutrition eaten on the date and the date's
+// calorie goal.
+func (c *Client) DaySummary(date time.Time) (*DayTotals, error) {
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	u, err := c.userInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := DietRange(tx, u, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Days) == 1 {
+		return &s.Days[0], nil
+	}
+
+	d := &DayTotals{Date: dateOf(date)}
+	if d.Goal, err = dayGoal(tx, u, d.Date); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// PhaseStatus returns the user's diet phase. Unlike the bite command, it
+// only reads the phase and doesn't check progress or start and end
+// phases, so the status is as of the last time the command ran.
+func (c *Client) PhaseStatus() (*PhaseInfo, error) {
+	tx, err := c.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	u, err := c.userInfo(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &u.Phase, nil
+}
+
+// userInfo reads the user's info, returning ErrNoUserInfo if it hasn't
+// been set up.
+func (c *Client) userInfo(tx *sqlx.Tx) (*UserInfo, error) {
+	u, err := loadUserInfo(tx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoUserInfo
+	}
+	return u, err
+}
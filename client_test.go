@@ -0,0 +1,58 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleClient() {
+	// The client is run against the schema the bite command creates.
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+
+	c := NewClientDB(db)
+	day := time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC)
+
+	// Log the food's preferred serving, then a custom one.
+	if _, err := c.LogFood(1, 0, 0, day); err != nil {
+		log.Fatal(err)
+	}
+	n, err := c.LogFood(1, 80, 1, day.Add(4*time.Hour))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Logged %.0f calories, $%.2f\n", n.Calories, n.Price)
+
+	if err := c.LogWeight(183.6, day); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Weighing in twice:", c.LogWeight(183.2, day))
+
+	d, err := c.DaySummary(day)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s: %.0f of %.0f calories, %sg protein\n", d.Date.Format(dateFormat), d.Calories, d.Goal, FormatMacro(d.Protein))
+
+	p, err := c.PhaseStatus()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Phase: %s (%s)\n", p.Name, p.Status)
+
+	u, err := Config(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Weight:", FormatWeight(u.Weight))
+
+	// Output:
+	// Logged 311 calories, $0.40
+	// Weighing in twice: Weight for this date has already been logged.
+	// 2024-01-08: 467 of 2200 calories, 15.6g protein
+	// Phase: cut (active)
+	// Weight: 183.6
+}
@@ -0,0 +1,105 @@
+package bite
+
+import "time"
+
+// Clock tells the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock of the system time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// FixedClock is a Clock stopped at a time, such as to test phase logic
+// and summaries on fixed dates.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+// clock is the Clock the current time is read from.
+var clock Clock = systemClock{}
+
+var (
+	// Location is the time zone days are kept in, such as the home time
+	// zone of a traveler. Nil means the system's time zone.
+	Location *time.Location
+
+	// DayBoundaryHour is the hour of the day a day of logs starts at. Zero
+	// means midnight; 4 makes food eaten at 2 a.m. on a night shift count
+	// toward the day before.
+	DayBoundaryHour = 0
+
+	// LateNightCutoff is the time of day ("15:04") until which food
+	// logged after midnight counts toward the day before, the way a
+	// snack at 1 a.m. is part of yesterday's dinner. Empty means
+	// midnight.
+	LateNightCutoff = ""
+)
+
+// SetClock makes the current time be read from c, and returns a
+// function that restores the previous clock.
+func SetClock(c Clock) (restore func()) {
+	prev := clock
+	clock = c
+	return func() { clock = prev }
+}
+
+// Now returns the current time of the clock set by SetClock, which is
+// the system time by default, in Location.
+func Now() time.Time {
+	t := clock.Now()
+	if Location != nil {
+		t = t.In(Location)
+	}
+	return t
+}
+
+// inLocation returns t in Location, or in the system's time zone if
+// Location is nil.
+func inLocation(t time.Time) time.Time {
+	if Location != nil {
+		return t.In(Location)
+	}
+	return t.Local()
+}
+
+// LogNow returns the current time the way logs record it: the time of
+// day in Location on the day of logs it falls in, which is the day
+// before the calendar day until DayBoundaryHour. Like the dates and times
+// read from the database, it is in UTC, so it compares with them the
+// same wherever the user is.
+func LogNow() time.Time {
+	t := Now()
+	d := t.Add(-time.Duration(DayBoundaryHour) * time.Hour)
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// FoodLogNow returns LogNow for logging food. Until LateNightCutoff,
+// it is on the day before, so food eaten late at night adds up with the
+// rest of that day's food in entries and summaries.
+func FoodLogNow() time.Time {
+	t := LogNow()
+	// Before DayBoundaryHour, LogNow is already on the day before.
+	if LateNightCutoff != "" && t.Hour() >= DayBoundaryHour && t.Format("15:04") < LateNightCutoff {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// Today returns the day of logs it is now, at midnight UTC the way
+// dates are read from the database.
+func Today() time.Time {
+	return dateOf(LogNow())
+}
+
+// logStamp returns the current time in UTC, in SyncTimeFormat, and the
+// offset in seconds of Location from UTC, which entries are stored with
+// so the moment they were logged is known whatever day they count
+// toward.
+func logStamp() (string, int) {
+	t := Now()
+	_, offset := t.Zone()
+	return t.UTC().Format(SyncTimeFormat), offset
+}
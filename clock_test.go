@@ -0,0 +1,73 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSetClock() {
+	restore := SetClock(FixedClock(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)))
+	defer restore()
+
+	fmt.Println(Now().Format(dateFormat))
+	fmt.Println(validateDateIsNotPast(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)))
+	fmt.Println(validateDateIsNotPast(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)))
+
+	// The phase of dbtest.User runs from 2024-01-01 to 2024-03-25.
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	u, err := Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	status, err := CheckPhaseStatus(db, u)
+	fmt.Println(status, err)
+	fmt.Printf("%.0f days left\n", calculateDuration(Now(), u.Phase.EndDate).Hours()/24)
+
+	// Output:
+	// 2024-01-10
+	// true
+	// false
+	// active <nil>
+	// 74 days left
+}
+
+func ExampleLogNow() {
+	// 1:30 a.m. in Chicago on the 10th is 7:30 a.m. UTC.
+	defer SetClock(FixedClock(time.Date(2024, 1, 10, 7, 30, 0, 0, time.UTC)))()
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func(loc *time.Location, h int) { Location, DayBoundaryHour = loc, h }(Location, DayBoundaryHour)
+
+	Location = chicago
+	fmt.Println(LogNow().Format(dateFormat+" "+dateFormatTime), Today().Format(dateFormat))
+
+	// With days starting at 4 a.m., a night shift's 1:30 a.m. snack
+	// counts toward the day before.
+	DayBoundaryHour = 4
+	fmt.Println(LogNow().Format(dateFormat+" "+dateFormatTime), Today().Format(dateFormat))
+	// Output:
+	// 2024-01-10 01:30:00 2024-01-10
+	// 2024-01-09 01:30:00 2024-01-09
+}
+
+func ExampleFoodLogNow() {
+	defer SetClock(FixedClock(time.Date(2024, 1, 10, 1, 30, 0, 0, time.UTC)))()
+	defer func(c string) { LateNightCutoff = c }(LateNightCutoff)
+
+	// A snack at 1:30 a.m. counts toward the day before until the
+	// cutoff, while the day of other logs stays the same.
+	LateNightCutoff = "03:00"
+	fmt.Println(FoodLogNow().Format(dateFormat+" "+dateFormatTime), Today().Format(dateFormat))
+	LateNightCutoff = "01:00"
+	fmt.Println(FoodLogNow().Format(dateFormat + " " + dateFormatTime))
+	// Output:
+	// 2024-01-09 01:30:00 2024-01-10
+	// 2024-01-10 01:30:00
+}
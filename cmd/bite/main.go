@@ -3,137 +3,71 @@ Bite is a command-line utility for managing diet phases and food logging.
 
 USAGE
 
-	bite [command]
+	bite [--db path] <command> [flags]
 
-COMMAND
+COMMANDS
 
-	log     - Manages food, meal, and weight log.
-	create  - Creates food or meal.
-	delete  - Deletes food or meal.
-	update  - Updates food, meal, or user information.
-	summary - Provides phase, diet, and user summary.
-	stop    - Stops a current phase.
+	log       - Manages food, meal, and weight log.
+	q         - Quickly logs foods written out, such as "2 eggs and 40g oats".
+	create    - Creates food or meal.
+	food      - Manages food groups.
+	tag       - Tags foods and meals, e.g. "vegan".
+	delete    - Deletes food or meal.
+	update    - Updates food, meal, or user information.
+	summary   - Provides phase, diet, and user summary.
+	stop      - Stops a current phase.
+	checkin   - Rates hunger, energy, sleep, and gym performance for the week.
+	note      - Annotates the timeline with life events.
+	leftovers - Lists leftovers and logs a serving of one.
+	pantry    - Tracks the foods on hand and the staples running low.
+	prep      - Plans meals cooked ahead for the week.
+	supp      - Manages the supplements taken every day.
+	condition - Flags conditions and medications that change safe targets.
+	notify    - Prints reminders of what is due now.
+	photo     - Manages progress photos.
+	break     - Starts or stops a diet break.
+	phase     - Manages the diet phase.
+	recipe    - Works out the nutrition of recipes cooked in batches.
+	keys      - Lists or changes search UI keybindings.
+	db        - Encrypts, decrypts, or maintains the database.
+	sync      - Syncs the log between devices through a file.
+	share     - Shares the diet with a coach.
+	export    - Exports the diet phase to other apps.
+	import    - Imports data from other sources.
+	serve     - Serves diet metrics for monitoring.
+	audit     - Reviews the changes made to the database.
+	history   - Lists the recent commands that changed the database.
+	redo      - Repeats the last food logged.
+
+Every command accepts --help, or a trailing "help" argument, to print
+its usage.
 */
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/ericstrs/bite/internal/ui"
 )
 
-const usage = `USAGE
-
-	bite [command]
-
-COMMANDS
-
-	log     - Manages food, meal, and weight log.
-	create  - Creates food or meal.
-	delete  - Deletes food or meal.
-	update  - Updates food, meal, or user information.
-	summary - Provides phase, diet, and user summary.
-	stop    - Stops a current phase.
-
-DESCRIPTION
-
-	Bite is a command-line utility for managing diet phases and food logging.
-
-	Appending "help" after any command will print more command information.
-`
-
 func main() {
 	if err := Run(); err != nil {
+		var uerr *ui.UsageError
+		if errors.As(err, &uerr) {
+			fmt.Fprintln(os.Stderr, "ERROR: "+uerr.Msg)
+			uerr.Cmd.PrintUsage(os.Stderr)
+			os.Exit(1)
+		}
 		log.Println(err)
 	}
 }
 
 func Run() error {
-	args := os.Args
-	if len(args) < 2 {
-		fmt.Fprintln(os.Stderr, `ERROR: Not enough arguments`)
-		fmt.Fprintf(os.Stderr, usage)
-		os.Exit(1)
-	}
-
-	/*
-		dbPath := os.Getenv("BITE_DB_PATH")
-		if dbPath == "" {
-			log.Fatal("Environment variable BITE_DB_PATH must be set")
-		}
-
-		// Connect to SQLite database
-		db, err := sqlx.Connect("sqlite", dbPath)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-
-		// Read user's config file.
-		u, err := b.Config(db)
-		if err != nil {
-			return err
-		}
-
-		status, err := b.CheckPhaseStatus(db, u)
-		if err != nil {
-			return err
-		}
-
-		// Read user entries.
-		entries, err := b.GetAllEntries(db)
-		if err != nil {
-			return err
-		}
-
-		var activeLog *[]b.Entry
-		// If there is an active diet,
-		if status == "active" {
-			// Subset the log for the active diet phase.
-			activeLog = b.GetValidLog(u, entries)
-
-			// Get user progress.
-			err = b.CheckProgress(db, u, activeLog)
-			if err != nil {
-				return err
-			}
-		}
-	*/
-
-	switch strings.ToLower(args[1]) {
-	case `log`:
-		if err := ui.LogCmd(args); err != nil {
-			return err
-		}
-	case `create`:
-		if err := ui.CreateCmd(args); err != nil {
-			return err
-		}
-	case `delete`:
-		if err := ui.DeleteCmd(args); err != nil {
-			return err
-		}
-	case `update`:
-		if err := ui.UpdateCmd(args); err != nil {
-			return err
-		}
-	case `summary`:
-		if err := ui.SummaryCmd(args); err != nil {
-			return err
-		}
-	case `stop`:
-		if err := ui.StopCmd(args); err != nil {
-			return err
-		}
-	case `help`:
-		fmt.Printf(usage)
-	default:
-		fmt.Fprintln(os.Stderr, `ERROR: Incorrect argument.`)
-		fmt.Fprintf(os.Stderr, usage)
-		os.Exit(1)
+	if err := ui.LoadConfig(); err != nil {
+		return err
 	}
-	return nil
+	return ui.Root().Execute(os.Args[1:])
 }
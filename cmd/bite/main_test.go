@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/ericstrs/bite/internal/ui"
+)
+
+// TestMain runs bite itself when the test binary is started by
+// runBite, so that the tests drive the same command users run.
+func TestMain(m *testing.M) {
+	if os.Getenv("BITE_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// maxOutput is the most output runBite reads. Prompts ask again when
+// they read the end of the input, so a script missing answers would
+// otherwise print until the timeout.
+const maxOutput = 1 << 20
+
+// runBite runs bite with the arguments against the database at db,
+// reading the answers to its prompts from input, and returns what it
+// printed to standard output.
+func runBite(t *testing.T, db, input string, args ...string) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, os.Args[0], append([]string{"--db", db}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"BITE_TEST_MAIN=1",
+		"BITE_CONFIG="+filepath.Join(t.TempDir(), "config.toml"),
+		"LC_ALL=C",
+		"NO_COLOR=1",
+		"TERM=dumb",
+	)
+	cmd.Stdin = strings.NewReader(input)
+	stdout := &limitedBuffer{max: maxOutput}
+	var stderr bytes.Buffer
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	switch {
+	case stdout.Len() >= maxOutput:
+		t.Fatalf("bite %s: prompted past the end of the input:\n%s", strings.Join(args, " "), tail(stdout.String()))
+	case err != nil:
+		t.Fatalf("bite %s: %v\n%s%s", strings.Join(args, " "), err, stdout.String(), stderr.String())
+	}
+	return stdout.String()
+}
+
+// limitedBuffer is a buffer that fails writes past max bytes.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		n, _ := b.Buffer.Write(p[:b.max-b.Len()])
+		return n, errors.New("too much output")
+	}
+	return b.Buffer.Write(p)
+}
+
+// tail returns the last lines of the output.
+func tail(s string) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > 10 {
+		lines = lines[len(lines)-10:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wantOutput fails the test if the output is missing any of the lines.
+func wantOutput(t *testing.T, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("output is missing %q:\n%s", w, out)
+		}
+	}
+}
+
+func TestInitLogSummary(t *testing.T) {
+	db := filepath.Join(t.TempDir(), "bite.db")
+	if err := dbtest.Create(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first command asks for the user's details and diet phase
+	// before logging the weight.
+	setup := strings.Join([]string{
+		"2",           // Imperial units.
+		"male",        // Sex.
+		"180",         // Weight (lbs).
+		"5",           // Height (feet).
+		"10",          // Height (inches).
+		"30",          // Age.
+		"moderate",    // Activity level.
+		"1",           // Mifflin-St Jeor.
+		"maintain",    // Phase.
+		"recommended", // Diet goal.
+		"",            // Start today.
+		"179.5",       // Weight to log.
+		"",            // Log it today.
+	}, "\n") + "\n"
+	out := runBite(t, db, setup, "log", "weight")
+	wantOutput(t, out,
+		"Step 1: Your details.",
+		"Diet Duration: 5.0 weeks",
+		"Successfully added weight entry.",
+	)
+
+	out = runBite(t, db, "2 bananas\n80 g oats\n\n", "log", "paste", "--yes")
+	wantOutput(t, out,
+		"2 x 118 g Banana",
+		"80 g Oats",
+		"Logged 2 foods.",
+	)
+
+	out = runBite(t, db, "", "summary", "diet", "day")
+	wantOutput(t, out,
+		"(521 / 2763)",
+		"2241.97 calories remaining.",
+	)
+}
+
+func TestDocCommands(t *testing.T) {
+	src, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usage bytes.Buffer
+	ui.Root().PrintUsage(&usage)
+
+	// The commands listed in the package doc are those of the usage,
+	// indented with a tab.
+	section := func(s, indent string) []string {
+		_, s, _ = strings.Cut(s, "COMMANDS\n\n")
+		s, _, _ = strings.Cut(s, "\n\n")
+		var lines []string
+		for _, l := range strings.Split(s, "\n") {
+			lines = append(lines, strings.TrimPrefix(l, indent))
+		}
+		return lines
+	}
+	doc, want := section(string(src), "\t"), section(usage.String(), "  ")
+	if strings.Join(doc, "\n") != strings.Join(want, "\n") {
+		t.Errorf("package doc lists commands:\n%s\nwant:\n%s", strings.Join(doc, "\n"), strings.Join(want, "\n"))
+	}
+}
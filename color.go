@@ -0,0 +1,84 @@
+package bite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ANSI escape sequences of the text styles.
+const (
+	colorReset     = "\033[0m"
+	colorBold      = "\033[1m"
+	colorItalic    = "\033[3m"
+	colorUnderline = "\033[4m"
+	colorRed       = "\033[31m"
+	colorGreen     = "\033[32m"
+)
+
+// NoColor turns off colored and styled output.
+var NoColor = false
+
+var (
+	// colorMet is the color of days that met the calorie goal.
+	colorMet = colorGreen
+	// colorMissed is the color of days that missed the calorie goal.
+	colorMissed = colorRed
+)
+
+// colorNames are the colors that can be chosen by name. "none" leaves
+// text plain.
+var colorNames = map[string]string{
+	"none":    "",
+	"bold":    colorBold,
+	"black":   "\033[30m",
+	"red":     colorRed,
+	"green":   colorGreen,
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"white":   "\033[37m",
+}
+
+// SetAdherenceColors sets the colors of days that met and missed the
+// calorie goal by name, such as "green" or "none". An empty name keeps
+// the current color.
+func SetAdherenceColors(met, missed string) error {
+	m, err := parseColor(met, colorMet)
+	if err != nil {
+		return err
+	}
+	x, err := parseColor(missed, colorMissed)
+	if err != nil {
+		return err
+	}
+	colorMet, colorMissed = m, x
+	return nil
+}
+
+// parseColor returns the escape sequence of the named color, or def if
+// the name is empty.
+func parseColor(name, def string) (string, error) {
+	if name == "" {
+		return def, nil
+	}
+	c, ok := colorNames[strings.ToLower(name)]
+	if !ok {
+		names := make([]string, 0, len(colorNames))
+		for n := range colorNames {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown color %q, want one of %s", name, strings.Join(names, ", "))
+	}
+	return c, nil
+}
+
+// paint returns s in the style, or s as is when color is off.
+func paint(style, s string) string {
+	if NoColor || style == "" {
+		return s
+	}
+	return style + s + colorReset
+}
@@ -0,0 +1,26 @@
+package bite
+
+import "fmt"
+
+func ExampleSetAdherenceColors() {
+	defer func() { colorMet, colorMissed = colorGreen, colorRed }()
+
+	fmt.Printf("%q %q\n", getAdherenceColor("Mon", true), getAdherenceColor("Tue", false))
+
+	if err := SetAdherenceColors("blue", "none"); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%q %q\n", getAdherenceColor("Mon", true), getAdherenceColor("Tue", false))
+
+	NoColor = true
+	fmt.Printf("%q\n", getAdherenceColor("Mon", true))
+	NoColor = false
+
+	fmt.Println(SetAdherenceColors("", "pink"))
+
+	// Output:
+	// "\x1b[32mMon\x1b[0m" "\x1b[31mTue\x1b[0m"
+	// "\x1b[34mMon\x1b[0m" "Tue"
+	// "Mon"
+	// unknown color "pink", want one of black, blue, bold, cyan, green, magenta, none, red, white, yellow
+}
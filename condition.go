@@ -0,0 +1,184 @@
+package bite
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Condition is a medical condition or medication that changes the safe
+// rate of weight loss and the macro minimums bite recommends. The
+// changes are conservative defaults, not medical advice.
+type Condition struct {
+	Name        string
+	Description string
+	// CutRate is the recommended weekly weight change of a cut as a
+	// fraction of body weight. It replaces the default when slower, and
+	// zero leaves the default.
+	CutRate float64
+	// MaxCutRate is the fastest safe weekly weight loss as a fraction of
+	// body weight. It replaces maxCutRate when lower, and zero leaves
+	// it.
+	MaxCutRate float64
+	// MinProtein and MinCarbs are the least protein and carbs (g) per
+	// pound of body weight. They replace the defaults when higher.
+	MinProtein float64
+	MinCarbs   float64
+	Disclaimer string
+}
+
+// conditions are the conditions the user can flag.
+var conditions = []Condition{
+	{
+		Name:        "glp1",
+		Description: "GLP-1 medication, such as semaglutide or tirzepatide",
+		MaxCutRate:  0.01,
+		MinProtein:  0.7,
+		Disclaimer: "GLP-1 medications blunt appetite, which makes it easy to eat too little protein " +
+			"and lose muscle along with fat. Reach the protein minimum every day and keep lifting.",
+	},
+	{
+		Name:        "thyroid",
+		Description: "hypothyroidism or thyroid medication",
+		CutRate:     -0.004,
+		MaxCutRate:  0.01,
+		Disclaimer: "Thyroid conditions and dose changes shift how many calories you burn, so " +
+			"calorie estimates are less reliable. Have your levels checked before a long cut.",
+	},
+	{
+		Name:        "diabetes",
+		Description: "diabetes treated with insulin or sulfonylureas",
+		MaxCutRate:  0.01,
+		MinCarbs:    0.5,
+		Disclaimer: "Cutting carbs or calories on insulin or sulfonylureas can cause low blood " +
+			"sugar, and doses often need to change as you lose weight.",
+	},
+	{
+		Name:        "breastfeeding",
+		Description: "breastfeeding",
+		CutRate:     -0.0025,
+		MaxCutRate:  0.005,
+		MinCarbs:    0.5,
+		Disclaimer: "Losing weight quickly while breastfeeding can lower milk supply. Eat at " +
+			"maintenance for the first weeks after birth.",
+	},
+}
+
+// Conditions returns the conditions the user can flag.
+func Conditions() []Condition {
+	return append([]Condition(nil), conditions...)
+}
+
+// ParseCondition returns the condition with the given name.
+func ParseCondition(name string) (Condition, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var names []string
+	for _, c := range conditions {
+		if c.Name == name {
+			return c, nil
+		}
+		names = append(names, c.Name)
+	}
+	return Condition{}, fmt.Errorf("unknown condition %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// UserConditions returns the conditions the user flagged.
+func (u *UserInfo) UserConditions() []Condition {
+	var cs []Condition
+	for _, name := range strings.Split(u.Conditions, ",") {
+		if c, err := ParseCondition(name); err == nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// SetConditions replaces the conditions the user flagged and updates
+// the macro minimums they change.
+func SetConditions(tx *sqlx.Tx, u *UserInfo, names []string) error {
+	seen := make(map[string]bool)
+	var flagged []string
+	for _, name := range names {
+		c, err := ParseCondition(name)
+		if err != nil {
+			return err
+		}
+		if !seen[c.Name] {
+			seen[c.Name] = true
+			flagged = append(flagged, c.Name)
+		}
+	}
+	sort.Strings(flagged)
+
+	if err := addColumns(tx, "config", "conditions TEXT DEFAULT '' NOT NULL"); err != nil {
+		return err
+	}
+	u.Conditions = strings.Join(flagged, ",")
+	if _, err := tx.Exec(`UPDATE config SET conditions = $1 WHERE user_id = 1`, u.Conditions); err != nil {
+		return fmt.Errorf("couldn't update conditions: %v", err)
+	}
+	setMinMaxMacros(u)
+	return insertOrUpdateMacros(tx, u)
+}
+
+// cutRate returns the recommended weekly weight change of a cut as a
+// fraction of body weight: the slowest of the default and the rates of
+// the user's conditions.
+func cutRate(u *UserInfo) float64 {
+	rate := defaultCutWeeklyChangePct
+	for _, c := range u.UserConditions() {
+		if c.CutRate != 0 && c.CutRate > rate {
+			rate = c.CutRate
+		}
+	}
+	return rate
+}
+
+// safeCutRate returns the fastest safe weekly weight loss as a fraction
+// of body weight: the lowest of maxCutRate and the rates of the user's
+// conditions.
+func safeCutRate(u *UserInfo) float64 {
+	rate := maxCutRate
+	for _, c := range u.UserConditions() {
+		if c.MaxCutRate != 0 && c.MaxCutRate < rate {
+			rate = c.MaxCutRate
+		}
+	}
+	return rate
+}
+
+// applyConditionMinimums raises the macro minimums to those of the
+// user's conditions.
+func applyConditionMinimums(u *UserInfo) {
+	for _, c := range u.UserConditions() {
+		if min := c.MinProtein * u.Weight; min > u.Macros.MinProtein {
+			u.Macros.MinProtein = min
+		}
+		if min := c.MinCarbs * u.Weight; min > u.Macros.MinCarbs {
+			u.Macros.MinCarbs = min
+		}
+	}
+}
+
+// PrintConditionDisclaimers prints how the user's conditions changed
+// the recommendations, and that they are no replacement for a doctor.
+func PrintConditionDisclaimers(u *UserInfo) {
+	cs := u.UserConditions()
+	if len(cs) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(paint(colorUnderline, "Conditions"))
+	for _, c := range cs {
+		fmt.Printf("- %s: %s\n", strings.ToUpper(c.Description[:1])+c.Description[1:], c.Disclaimer)
+	}
+	fmt.Printf("Cuts are capped at %s%% of body weight a week", localizeNumber(strconv.FormatFloat(safeCutRate(u)*100, 'f', -1, 64)))
+	if p, c := u.Macros.MinProtein, u.Macros.MinCarbs; p > 0 || c > 0 {
+		fmt.Printf(", with at least %sg of protein and %sg of carbs a day", FormatMacro(p), FormatMacro(c))
+	}
+	fmt.Println(".")
+	fmt.Println("These are conservative defaults, not medical advice. Follow the advice of your doctor.")
+}
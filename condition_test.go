@@ -0,0 +1,46 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSetConditions() {
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	u, err := loadUserInfo(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	setMinMaxMacros(u)
+	fmt.Printf("%.1f%% %.2f%% %.1f %.1f\n", safeCutRate(u)*100, cutRate(u)*100, u.Macros.MinProtein, u.Macros.MinCarbs)
+
+	if err := SetConditions(tx, u, []string{"thyroid", "GLP1", "glp1"}); err != nil {
+		log.Fatal(err)
+	}
+	u, err = loadUserInfo(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(u.Conditions)
+	fmt.Printf("%.1f%% %.2f%% %.1f %.1f\n", safeCutRate(u)*100, cutRate(u)*100, u.Macros.MinProtein, u.Macros.MinCarbs)
+
+	// A cut of 2 lbs a week is now too fast for 185 lbs.
+	for _, o := range safetyChecks(u, -2, 4000) {
+		fmt.Println(o.Detail)
+	}
+
+	fmt.Println(SetConditions(tx, u, []string{"insomnia"}))
+
+	// Output:
+	// 1.5% -0.50% 55.5 55.5
+	// glp1,thyroid
+	// 1.0% -0.40% 129.5 55.5
+	// losing 2.0 lbs a week is faster than the safe rate of 1.9 lbs (1.0% of body weight)
+	// unknown condition "insomnia", expected one of glp1, thyroid, diabetes, breastfeeding
+}
@@ -0,0 +1,418 @@
+package bite
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
+)
+
+const (
+	// encMagic starts every encrypted database file.
+	encMagic = "BITEENC1"
+
+	// sqliteMagic starts every plaintext SQLite database file.
+	sqliteMagic = "SQLite format 3\x00"
+
+	saltSize  = 16
+	keySize   = 32
+	nonceSize = 12
+
+	// kdfIterations is the number of PBKDF2-SHA256 iterations used to
+	// derive a key from a passphrase.
+	kdfIterations = 600000
+
+	// memDBName is the name of the decrypted database in memFS.
+	memDBName = "bite.db"
+)
+
+// ErrWrongPassphrase is returned when an encrypted database can't be
+// decrypted with the given key.
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// Key is an encryption key derived from a passphrase.
+type Key struct {
+	// Salt is the random salt the key was derived with. It is stored in
+	// the header of the encrypted database.
+	Salt []byte
+
+	// Secret is the derived AES-256 key.
+	Secret []byte
+}
+
+// NewKey derives a new key with a random salt from the passphrase.
+func NewKey(passphrase []byte) (*Key, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("couldn't generate salt: %v", err)
+	}
+	return DeriveKey(passphrase, salt), nil
+}
+
+// DeriveKey derives the key for the passphrase and salt.
+func DeriveKey(passphrase, salt []byte) *Key {
+	return &Key{
+		Salt:   salt,
+		Secret: pbkdf2(passphrase, salt, kdfIterations, keySize),
+	}
+}
+
+// pbkdf2 implements PBKDF2 with HMAC-SHA256 as described in RFC 8018.
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	numBlocks := (keyLen + hashLen - 1) / hashLen
+
+	var buf [4]byte
+	dk := make([]byte, 0, numBlocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= numBlocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf[:], uint32(block))
+		prf.Write(buf[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+
+		for n := 2; n <= iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range u {
+				t[i] ^= u[i]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
+
+// Seal encrypts the data with AES-256-GCM. The result holds a header
+// with the key's salt and a random nonce followed by the ciphertext.
+func (k *Key) Seal(data []byte) ([]byte, error) {
+	gcm, err := k.gcm()
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, len(encMagic)+saltSize+nonceSize)
+	header = append(header, encMagic...)
+	header = append(header, k.Salt...)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("couldn't generate nonce: %v", err)
+	}
+	header = append(header, nonce...)
+
+	// The header is authenticated along with the data.
+	return gcm.Seal(header, nonce, data, header), nil
+}
+
+// Open decrypts data produced by Seal.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	s, err := salt(data)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(s, k.Salt) {
+		return nil, ErrWrongPassphrase
+	}
+	gcm, err := k.gcm()
+	if err != nil {
+		return nil, err
+	}
+	n := len(encMagic) + saltSize + nonceSize
+	plain, err := gcm.Open(nil, data[n-nonceSize:n], data[n:], data[:n])
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
+
+func (k *Key) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create cipher: %v", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create cipher: %v", err)
+	}
+	return gcm, nil
+}
+
+// salt returns the salt stored in the header of encrypted data.
+func salt(data []byte) ([]byte, error) {
+	n := len(encMagic) + saltSize + nonceSize
+	if len(data) < n || string(data[:len(encMagic)]) != encMagic {
+		return nil, errors.New("data is not encrypted")
+	}
+	return data[len(encMagic) : len(encMagic)+saltSize], nil
+}
+
+// FileSalt returns the salt stored in the header of the encrypted
+// database at path.
+func FileSalt(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(encMagic)+saltSize+nonceSize)
+	if _, err := io.ReadFull(f, header); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return salt(header)
+}
+
+// IsEncrypted reports whether the file at path is an encrypted
+// database.
+func IsEncrypted(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(encMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return string(magic) == encMagic, nil
+}
+
+// EncryptFile encrypts the plaintext database at path in place. The
+// database is taken out of WAL mode first, so that the encrypted file
+// holds all of it.
+func EncryptFile(path string, k *Key) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(sqliteMagic)) {
+		return fmt.Errorf("%s is not a plaintext SQLite database", path)
+	}
+	if err := checkpoint(path); err != nil {
+		return err
+	}
+	if data, err = os.ReadFile(path); err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	sealed, err := k.Seal(data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, sealed)
+}
+
+// DecryptFile decrypts the encrypted database at path in place.
+func DecryptFile(path string, k *Key) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	plain, err := k.Open(data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, plain)
+}
+
+// OpenEncrypted decrypts the database at path into memory and returns
+// a connection to it. Changes are only written back to disk by
+// SaveEncrypted.
+func OpenEncrypted(path string, k *Key) (*sqlx.DB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read database: %v", err)
+	}
+	plain, err := k.Open(data)
+	if err != nil {
+		return nil, err
+	}
+	return openMemory(plain)
+}
+
+// openMemory returns a connection to an in-memory database loaded from
+// the database image.
+func openMemory(image []byte) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to ":memory:" is a separate database, so the
+	// pool must hold exactly one.
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	if err := restore(db, image); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("couldn't load database: %v", err)
+	}
+	// Foreign keys are enforced when they hold, as they are by Open.
+	ok, err := foreignKeysHold(db)
+	if err == nil && ok {
+		_, err = db.Exec(`PRAGMA foreign_keys = ON`)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// restore copies the database image into the in-memory database. The
+// image is exposed to SQLite as a read-only file and copied with the
+// backup API.
+func restore(db *sqlx.DB, image []byte) error {
+	name, vfsFS, err := vfs.New(memFS{name: memDBName, data: image})
+	if err != nil {
+		return err
+	}
+	defer vfsFS.Close()
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(dc any) error {
+		r, ok := dc.(interface {
+			NewRestore(string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return errors.New("sqlite driver can't restore databases")
+		}
+		b, err := r.NewRestore("file:" + memDBName + "?vfs=" + name)
+		if err != nil {
+			return err
+		}
+		if _, err := b.Step(-1); err != nil {
+			b.Finish()
+			return err
+		}
+		return b.Finish()
+	})
+}
+
+// SaveEncrypted encrypts the in-memory database and writes it to path.
+func SaveEncrypted(db *sqlx.DB, path string, k *Key) error {
+	data, err := serialize(db)
+	if err != nil {
+		return err
+	}
+	sealed, err := k.Seal(data)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, sealed)
+}
+
+// serialize returns an image of the database, as it would be written to
+// a file.
+func serialize(db *sqlx.DB) ([]byte, error) {
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var data []byte
+	err = conn.Raw(func(dc any) error {
+		s, ok := dc.(interface{ Serialize() ([]byte, error) })
+		if !ok {
+			return errors.New("sqlite driver can't serialize databases")
+		}
+		data, err = s.Serialize()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("couldn't serialize database: %v", err)
+	}
+	// The image has no write-ahead log of its own, so a database in WAL
+	// mode is marked as being in rollback journal mode, the way leaving
+	// WAL mode marks it, for the image to be opened.
+	if len(data) >= 20 && data[18] == 2 && data[19] == 2 {
+		data[18], data[19] = 1, 1
+	}
+	return data, nil
+}
+
+// writeFileAtomic replaces the file at path with data, so that the
+// file is never left half written.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("couldn't create temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("couldn't write database: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("couldn't write database: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("couldn't write database: %v", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("couldn't replace database: %v", err)
+	}
+	return nil
+}
+
+// memFS is a read-only file system holding a single file in memory.
+type memFS struct {
+	name string
+	data []byte
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if name != m.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: bytes.NewReader(m.data), fs: m}, nil
+}
+
+// memFile is an open memFS file.
+type memFile struct {
+	*bytes.Reader
+	fs memFS
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memFileInfo(f.fs), nil }
+func (f *memFile) Close() error               { return nil }
+
+// memFileInfo describes a memFS file.
+type memFileInfo memFS
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return int64(len(fi.data)) }
+func (fi memFileInfo) Mode() fs.FileMode  { return 0400 }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return false }
+func (fi memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,118 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExampleKey_Open() {
+	k, err := NewKey([]byte("hunter2"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	sealed, err := k.Seal([]byte("weight: 180"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	plain, err := k.Open(sealed)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(string(plain))
+
+	wrong := DeriveKey([]byte("hunter3"), k.Salt)
+	_, err = wrong.Open(sealed)
+	fmt.Println(errors.Is(err, ErrWrongPassphrase))
+
+	// Output:
+	// weight: 180
+	// true
+}
+
+func Example_pbkdf2() {
+	// The PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11.
+	fmt.Printf("%x\n", pbkdf2([]byte("passwd"), []byte("salt"), 1, 64))
+	fmt.Printf("%x\n", pbkdf2([]byte("Password"), []byte("NaCl"), 80000, 64))
+
+	// Output:
+	// 55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783
+	// 4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d
+}
+
+func ExampleOpenEncrypted() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bite.db")
+
+	// Create a plaintext database.
+	if err := dbtest.Create(path, `INSERT INTO settings (key, value) VALUES ('key.down', 'n')`); err != nil {
+		log.Println(err)
+		return
+	}
+
+	k, err := NewKey([]byte("hunter2"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := EncryptFile(path, k); err != nil {
+		log.Println(err)
+		return
+	}
+	enc, err := IsEncrypted(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println("Encrypted:", enc)
+
+	// Modify the decrypted database and write it back.
+	db, err := OpenEncrypted(path, k)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	db.MustExec(`INSERT INTO settings (key, value) VALUES ('key.up', 'e')`)
+	if err := SaveEncrypted(db, path, k); err != nil {
+		log.Println(err)
+		return
+	}
+	db.Close()
+
+	if err := DecryptFile(path, k); err != nil {
+		log.Println(err)
+		return
+	}
+	db, err = sqlx.Connect("sqlite", path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer db.Close()
+
+	settings, err := SettingsWithPrefix(db, "key.")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(settings["key.down"], settings["key.up"])
+
+	// Output:
+	// Encrypted: true
+	// n e
+}
@@ -0,0 +1,68 @@
+package bite
+
+import "time"
+
+const (
+	// defaultCycleLength is the length in days of a menstrual cycle
+	// when none is set.
+	defaultCycleLength = 28
+	// defaultCycleDaysBefore is the number of days before a period
+	// starts with water retention when none is set.
+	defaultCycleDaysBefore = 5
+	// defaultCycleDaysAfter is the number of days from the start of a
+	// period with water retention when none is set.
+	defaultCycleDaysAfter = 2
+)
+
+// Cycle is the user's menstrual cycle, or nil when cycle tracking is
+// off. Weeks with days in its water retention windows are left out of
+// progress checks, like weeks with diet break days.
+var Cycle *MenstrualCycle
+
+// MenstrualCycle describes when water retention is expected to raise
+// the scale weight: from DaysBefore days before each period starts
+// through the first DaysAfter days of it.
+type MenstrualCycle struct {
+	Start      time.Time // First day of a period.
+	Length     int       // Days from the start of a period to the next.
+	DaysBefore int
+	DaysAfter  int
+}
+
+// NewMenstrualCycle returns the cycle of the period that started on the
+// date, with the default length and windows for the zero values.
+func NewMenstrualCycle(start time.Time, length, before, after int) *MenstrualCycle {
+	c := &MenstrualCycle{Start: dateOf(start), Length: length, DaysBefore: before, DaysAfter: after}
+	if c.Length == 0 {
+		c.Length = defaultCycleLength
+	}
+	if c.DaysBefore == 0 {
+		c.DaysBefore = defaultCycleDaysBefore
+	}
+	if c.DaysAfter == 0 {
+		c.DaysAfter = defaultCycleDaysAfter
+	}
+	return c
+}
+
+// InWindow reports whether water retention is expected on the date. It
+// is always false for a nil cycle.
+func (c *MenstrualCycle) InWindow(date time.Time) bool {
+	if c == nil || c.Length <= 0 {
+		return false
+	}
+	days := int(dateOf(date).Sub(c.Start).Hours() / 24)
+	day := (days%c.Length + c.Length) % c.Length // Day of the cycle, 0 on the first day of a period.
+	return day < c.DaysAfter || day >= c.Length-c.DaysBefore
+}
+
+// overlaps reports whether any of the days from start to end,
+// inclusive, fall in a water retention window.
+func (c *MenstrualCycle) overlaps(start, end time.Time) bool {
+	for d := dateOf(start); !d.After(end); d = d.AddDate(0, 0, 1) {
+		if c.InWindow(d) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleMenstrualCycle_InWindow() {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMenstrualCycle(start, 28, 0, 0)
+	for _, d := range []time.Time{
+		start.AddDate(0, 0, -6),
+		start.AddDate(0, 0, -5),
+		start,
+		start.AddDate(0, 0, 1),
+		start.AddDate(0, 0, 2),
+		start.AddDate(0, 0, 23), // 5 days before the next period.
+		start.AddDate(0, 0, 56),
+	} {
+		fmt.Println(d.Format(dateFormat), c.InWindow(d))
+	}
+
+	// Weeks with days in a window are left out of progress checks.
+	fmt.Println(c.overlaps(start.AddDate(0, 0, 2), start.AddDate(0, 0, 8)))
+	fmt.Println(c.overlaps(start.AddDate(0, 0, 17), start.AddDate(0, 0, 23)))
+
+	var off *MenstrualCycle
+	fmt.Println(off.InWindow(start))
+
+	// Output:
+	// 2024-02-24 false
+	// 2024-02-25 true
+	// 2024-03-01 true
+	// 2024-03-02 true
+	// 2024-03-03 false
+	// 2024-03-24 true
+	// 2024-04-26 true
+	// false
+	// true
+	// false
+}
@@ -0,0 +1,9 @@
+// Package database holds the SQL scripts that create the bite database.
+package database
+
+import _ "embed"
+
+// Setup is the script that creates the tables of the bite database.
+//
+//go:embed sql/setup.sql
+var Setup string
@@ -6,7 +6,16 @@ CREATE TABLE IF NOT EXISTS foods (
   serving_unit TEXT NOT NULL,
   household_serving TEXT NOT NULL,
   brand_name TEXT DEFAULT '',
-  cost REAL DEFAULT 0
+  cost REAL DEFAULT 0,
+  -- source is where the food's data came from.
+  source TEXT DEFAULT 'usda' NOT NULL CHECK (source IN ('usda', 'openfoodfacts', 'user')),
+  -- caffeine is the caffeine (mg) of a serving.
+  caffeine REAL DEFAULT 0 NOT NULL,
+  -- cooked_yield is the weight of a gram of the food once cooked, or 0
+  -- for the standard yield of its kind.
+  cooked_yield REAL DEFAULT 0 NOT NULL,
+  -- glycemic_index is the glycemic index of the food, or 0 if unknown.
+  glycemic_index REAL DEFAULT 0 NOT NULL
 );
 
 -- create virtual table for full-text searching 
@@ -22,12 +31,19 @@ CREATE TABLE IF NOT EXISTS meals (
     meal_name TEXT NOT NULL
 );
 
+-- create virtual table for full-text searching meals by name and by
+-- the names of the foods (ingredients) that make up the meal.
+CREATE VIRTUAL TABLE IF NOT EXISTS meals_fts
+USING fts5 (
+    meal_id UNINDEXED, meal_name, ingredients
+);
+
 -- user_foods contains the user's food consumption
 -- logs.
 CREATE TABLE IF NOT EXISTS daily_foods (
   id INTEGER PRIMARY KEY,
-  food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_id INTEGER REFERENCES meals(meal_id),
+  food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE NOT NULL,
+  meal_id INTEGER REFERENCES meals(meal_id) ON DELETE SET NULL,
   date DATE NOT NULL,
   time TIME NOT NULL,
   serving_size REAL NOT NULL,
@@ -36,29 +52,245 @@ CREATE TABLE IF NOT EXISTS daily_foods (
   protein REAL NOT NULL,
   fat REAL NOT NULL,
   carbs REAL NOT NULL,
-  price REAL DEFAULT 0
+  price REAL DEFAULT 0,
+  -- logged_at is when the entry was logged, in UTC, and utc_offset is
+  -- the offset (seconds) from UTC of the time zone it was logged in.
+  -- date is the day of logs the entry counts toward.
+  logged_at TEXT DEFAULT '' NOT NULL,
+  utc_offset INTEGER DEFAULT 0 NOT NULL
 );
 
+-- daily_rollups holds the daily totals of the food log, kept up to date
+-- by triggers on daily_foods, so summaries don't add up every entry.
+CREATE TABLE IF NOT EXISTS daily_rollups (
+  date DATE PRIMARY KEY,
+  calories REAL NOT NULL,
+  protein REAL NOT NULL,
+  fat REAL NOT NULL,
+  carbs REAL NOT NULL,
+  price REAL DEFAULT 0 NOT NULL,
+  entries INTEGER NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS daily_rollups_insert AFTER INSERT ON daily_foods
+BEGIN
+  DELETE FROM daily_rollups WHERE date = new.date;
+  INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+  SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+  FROM daily_foods WHERE date = new.date GROUP BY date;
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_rollups_update
+AFTER UPDATE OF date, calories, protein, fat, carbs, price ON daily_foods
+BEGIN
+  DELETE FROM daily_rollups WHERE date IN (old.date, new.date);
+  INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+  SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+  FROM daily_foods WHERE date IN (old.date, new.date) GROUP BY date;
+END;
+
+CREATE TRIGGER IF NOT EXISTS daily_rollups_delete AFTER DELETE ON daily_foods
+BEGIN
+  DELETE FROM daily_rollups WHERE date = old.date;
+  INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+  SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+  FROM daily_foods WHERE date = old.date GROUP BY date;
+END;
+
+CREATE INDEX IF NOT EXISTS idx_daily_foods_date ON daily_foods(date);
+CREATE INDEX IF NOT EXISTS idx_daily_foods_food_date ON daily_foods(food_id, date);
+
 -- user_meals contains the user's meal consumption logs.
 CREATE TABLE IF NOT EXISTS daily_meals (
   id INTEGER PRIMARY KEY,
-  meal_id INTEGER REFERENCES meals(meal_id),
+  meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE,
   date DATE NOT NULL,
   time TIME NOT NULL
 );
 
 -- daily_weights contains the users daily weight and date of the entry.
+-- Estimated weights are filled in between logged weights.
 CREATE TABLE IF NOT EXISTS daily_weights (
   id INTEGER PRIMARY KEY,
   date DATE NOT NULL,
   time TIME NOT NULL,
-  weight REAL NOT NULL
+  weight REAL NOT NULL,
+  estimated INTEGER DEFAULT 0 NOT NULL,
+  logged_at TEXT DEFAULT '' NOT NULL,
+  utc_offset INTEGER DEFAULT 0 NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_weights_date ON daily_weights(date);
+
+-- daily_training contains the user's training sessions.
+CREATE TABLE IF NOT EXISTS daily_training (
+  id INTEGER PRIMARY KEY,
+  date DATE NOT NULL,
+  time TIME NOT NULL,
+  type TEXT NOT NULL,
+  duration REAL NOT NULL,
+  calories REAL DEFAULT 0 NOT NULL
+);
+
+-- daily_sleep contains the hours the user slept the night before each
+-- day.
+CREATE TABLE IF NOT EXISTS daily_sleep (
+  date DATE PRIMARY KEY,
+  hours REAL NOT NULL
+);
+
+-- strength_log contains the user's lifting sets, from which the
+-- estimated one-rep max of each lift is tracked.
+CREATE TABLE IF NOT EXISTS strength_log (
+  id INTEGER PRIMARY KEY,
+  date DATE NOT NULL,
+  lift TEXT NOT NULL COLLATE NOCASE,
+  weight REAL NOT NULL,
+  reps INTEGER NOT NULL
+);
+
+-- daily_steps contains the user's step count for each day.
+CREATE TABLE IF NOT EXISTS daily_steps (
+  date DATE PRIMARY KEY,
+  steps INTEGER NOT NULL
+);
+
+-- checkins contains the user's weekly subjective ratings, from 1
+-- (worst) to 5 (best), and notes on the week.
+CREATE TABLE IF NOT EXISTS checkins (
+  date DATE PRIMARY KEY,
+  hunger INTEGER NOT NULL CHECK(hunger BETWEEN 1 AND 5),
+  energy INTEGER NOT NULL CHECK(energy BETWEEN 1 AND 5),
+  sleep INTEGER NOT NULL CHECK(sleep BETWEEN 1 AND 5),
+  performance INTEGER NOT NULL CHECK(performance BETWEEN 1 AND 5),
+  notes TEXT DEFAULT '' NOT NULL
+);
+
+-- supplements contains the supplements the user takes every day, such
+-- as creatine or vitamin D. time is the time of day ("15:04") a
+-- supplement is due, or empty for any time.
+CREATE TABLE IF NOT EXISTS supplements (
+  supp_id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+  dose TEXT DEFAULT '' NOT NULL,
+  time TEXT DEFAULT '' NOT NULL,
+  start_date DATE NOT NULL,
+  active INTEGER DEFAULT 1 NOT NULL
+);
+
+-- daily_supps contains the days each supplement was taken.
+CREATE TABLE IF NOT EXISTS daily_supps (
+  date DATE NOT NULL,
+  supp_id INTEGER REFERENCES supplements(supp_id) NOT NULL,
+  time TIME NOT NULL,
+  PRIMARY KEY (date, supp_id)
+);
+
+-- food_groups puts foods in a group, such as a restaurant or "Home
+-- cooking". A food belongs to at most one group.
+CREATE TABLE IF NOT EXISTS food_groups (
+  food_id INTEGER PRIMARY KEY REFERENCES foods(food_id) ON DELETE CASCADE,
+  group_name TEXT NOT NULL
+);
+
+-- tags contains the user's tags for foods and meals, e.g. "vegan".
+CREATE TABLE IF NOT EXISTS tags (
+  tag_id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE
+);
+
+-- food_tags relates foods to their tags.
+CREATE TABLE IF NOT EXISTS food_tags (
+  food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE,
+  tag_id INTEGER REFERENCES tags(tag_id),
+  PRIMARY KEY (food_id, tag_id)
+);
+
+-- meal_tags relates meals to their tags.
+CREATE TABLE IF NOT EXISTS meal_tags (
+  meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE,
+  tag_id INTEGER REFERENCES tags(tag_id),
+  PRIMARY KEY (meal_id, tag_id)
+);
+
+-- food_flags contains the allergens and animal products foods contain,
+-- e.g. "gluten", checked against the user's diet restrictions.
+CREATE TABLE IF NOT EXISTS food_flags (
+  food_id INTEGER NOT NULL,
+  flag TEXT NOT NULL,
+  PRIMARY KEY (food_id, flag)
+);
+
+-- leftovers contains the servings remaining of meals cooked in bulk.
+CREATE TABLE IF NOT EXISTS leftovers (
+  id INTEGER PRIMARY KEY,
+  meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE NOT NULL,
+  cooked_date DATE NOT NULL,
+  servings REAL NOT NULL
+);
+
+-- price_history contains the prices paid for foods, imported from
+-- store receipts, with the price per 100 serving units they work out to.
+CREATE TABLE IF NOT EXISTS price_history (
+  id INTEGER PRIMARY KEY,
+  food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE NOT NULL,
+  date DATE NOT NULL,
+  item TEXT NOT NULL,
+  price REAL NOT NULL,
+  quantity REAL NOT NULL,
+  cost REAL NOT NULL
+);
+
+-- pantry contains the quantity on hand of foods, in their serving unit,
+-- and the par quantity staples are kept stocked at.
+CREATE TABLE IF NOT EXISTS pantry (
+  food_id INTEGER PRIMARY KEY REFERENCES foods(food_id) ON DELETE CASCADE,
+  on_hand REAL DEFAULT 0 NOT NULL,
+  par REAL DEFAULT 0 NOT NULL
+);
+
+-- timeline_notes contains the notes of life events on the timeline,
+-- such as "started new job".
+CREATE TABLE IF NOT EXISTS timeline_notes (
+  id INTEGER PRIMARY KEY,
+  date DATE NOT NULL,
+  note TEXT NOT NULL
+);
+
+-- phase_queue contains the diet phases of a started phase template
+-- that are yet to start, in the order they run.
+CREATE TABLE IF NOT EXISTS phase_queue (
+  id INTEGER PRIMARY KEY,
+  template TEXT NOT NULL,
+  name TEXT NOT NULL,
+  weeks REAL NOT NULL
+);
+
+-- attachments lets a weight or food entry refer to a local file, such
+-- as a progress or meal photo.
+CREATE TABLE IF NOT EXISTS attachments (
+  id INTEGER PRIMARY KEY,
+  entry_table TEXT NOT NULL CHECK (entry_table IN ('daily_foods', 'daily_weights')),
+  entry_id INTEGER NOT NULL,
+  path TEXT NOT NULL,
+  created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')) NOT NULL
+);
+
+-- progress_photos contains the progress photos kept in the photos
+-- directory. path is relative to the directory and original is the
+-- file the photo was copied or linked from.
+CREATE TABLE IF NOT EXISTS progress_photos (
+  id INTEGER PRIMARY KEY,
+  date DATE NOT NULL,
+  phase_id INTEGER REFERENCES phase_info(phase_id),
+  path TEXT NOT NULL,
+  original TEXT NOT NULL
 );
 
 -- meal_foods relates meals to the foods the contain.
 CREATE TABLE IF NOT EXISTS meal_foods (
-  meal_id INTEGER REFERENCES meals(meal_id),
-  food_id INTEGER REFERENCES foods(food_id),
+  meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE,
+  food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE,
   PRIMARY KEY (meal_id, food_id)
 );
 
@@ -83,8 +315,8 @@ CREATE TABLE IF NOT EXISTS food_nutrients (
   nutrient_id INTEGER NOT NULL,
   amount REAL NOT NULL,
   derivation_id REAL NOT NULL,
-  FOREIGN KEY (food_id) REFERENCES foods(food_id),
-  FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
+  FOREIGN KEY (food_id) REFERENCES foods(food_id) ON DELETE CASCADE,
+  FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrient_id),
   FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
 );
 
@@ -92,7 +324,7 @@ CREATE TABLE IF NOT EXISTS food_prefs (
   food_id INTEGER PRIMARY KEY,
   serving_size REAL,
   number_of_servings REAL DEFAULT 1 NOT NULL,
-  FOREIGN KEY(food_id) REFERENCES foods(food_id)
+  FOREIGN KEY(food_id) REFERENCES foods(food_id) ON DELETE CASCADE
 );
 
 CREATE TABLE IF NOT EXISTS meal_food_prefs (
@@ -101,8 +333,9 @@ CREATE TABLE IF NOT EXISTS meal_food_prefs (
   serving_size REAL,
   number_of_servings REAL DEFAULT 1 NOT NULL,
   PRIMARY KEY(meal_id, food_id),
-  FOREIGN KEY(food_id) REFERENCES foods(food_id),
-  FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
+  FOREIGN KEY(food_id) REFERENCES foods(food_id) ON DELETE CASCADE,
+  FOREIGN KEY(meal_id) REFERENCES meals(meal_id) ON DELETE CASCADE,
+  FOREIGN KEY(meal_id, food_id) REFERENCES meal_foods(meal_id, food_id) ON DELETE CASCADE
 );
 
 CREATE TABLE IF NOT EXISTS config (
@@ -116,6 +349,9 @@ CREATE TABLE IF NOT EXISTS config (
   system TEXT NOT NULL,
   macros_id INTEGER,
   phase_id INTEGER,
+  bmr_formula TEXT DEFAULT 'mifflin' NOT NULL,
+  body_fat REAL DEFAULT 0 NOT NULL,
+  conditions TEXT DEFAULT '' NOT NULL,
   FOREIGN KEY (macros_id) REFERENCES macros(macros_id),
   FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
 );
@@ -149,5 +385,222 @@ CREATE TABLE IF NOT EXISTS phase_info (
     max_duration REAL NOT NULL,
     min_duration REAL NOT NULL,
     status TEXT NOT NULL CHECK(status IN ('active', 'completed', 'paused', 'stopped', 'scheduled')),
-    FOREIGN KEY (user_id) REFERENCES user_info(user_id)
+    training_calories REAL DEFAULT 0 NOT NULL,
+    training_days INTEGER DEFAULT 0 NOT NULL,
+    target_mode INTEGER DEFAULT 0 NOT NULL,
+    target_week INTEGER DEFAULT 0 NOT NULL,
+    goal_weight_end REAL DEFAULT 0 NOT NULL,
+    manual_calories REAL DEFAULT 0 NOT NULL
+);
+
+-- phase_targets contains the calorie and macro targets of a diet
+-- phase in effect from the start of each week they changed.
+CREATE TABLE IF NOT EXISTS phase_targets (
+    phase_id INTEGER NOT NULL,
+    week DATE NOT NULL,
+    calories REAL NOT NULL,
+    protein REAL NOT NULL,
+    carbs REAL NOT NULL,
+    fats REAL NOT NULL,
+    training_calories REAL DEFAULT 0 NOT NULL,
+    training_days INTEGER DEFAULT 0 NOT NULL,
+    PRIMARY KEY (phase_id, week),
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- calorie_adjustments contains the changes the progress checks made
+-- to the calorie goal and why: the weeks off the weekly change goal and
+-- the macros the calories were taken from or added to.
+CREATE TABLE IF NOT EXISTS calorie_adjustments (
+    id INTEGER PRIMARY KEY,
+    phase_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    weeks INTEGER NOT NULL,
+    weight_change REAL NOT NULL,
+    weekly_change REAL NOT NULL,
+    old_calories REAL NOT NULL,
+    new_calories REAL NOT NULL,
+    protein REAL NOT NULL,
+    carbs REAL NOT NULL,
+    fats REAL NOT NULL,
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- diet_breaks contains the periods of eating at maintenance during a
+-- diet phase. end_date is the first day after the break.
+CREATE TABLE IF NOT EXISTS diet_breaks (
+    id INTEGER PRIMARY KEY,
+    phase_id INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    goal_calories REAL NOT NULL,
+    status TEXT NOT NULL CHECK(status IN ('active', 'completed')),
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- recommendations contains the refeed days and diet breaks recommended
+-- during a cut, and whether the user scheduled or skipped them.
+CREATE TABLE IF NOT EXISTS recommendations (
+    id INTEGER PRIMARY KEY,
+    phase_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    kind TEXT NOT NULL CHECK(kind IN ('refeed', 'break')),
+    weeks INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK(action IN ('scheduled', 'skipped')),
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- plateaus contains the plateaus flagged in the trend weight during a
+-- cut or bulk, and the action the user chose to break them.
+CREATE TABLE IF NOT EXISTS plateaus (
+    id INTEGER PRIMARY KEY,
+    phase_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    change REAL NOT NULL,
+    action TEXT NOT NULL CHECK(action IN ('adjust_calories', 'add_steps', 'diet_break', 'continue')),
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- phase_extensions contains the extensions offered when too few of a
+-- phase's weeks were valid by its end date, and whether the user
+-- extended the phase or let it end.
+CREATE TABLE IF NOT EXISTS phase_extensions (
+    id INTEGER PRIMARY KEY,
+    phase_id INTEGER NOT NULL,
+    date DATE NOT NULL,
+    valid_weeks INTEGER NOT NULL,
+    total_weeks INTEGER NOT NULL,
+    weeks INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK(action IN ('extended', 'declined')),
+    FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+);
+
+-- settings stores user preferences as key/value pairs.
+CREATE TABLE IF NOT EXISTS settings (
+  key TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+-- Keep meals_fts in sync with the meals, meal_foods, and foods tables.
+CREATE TRIGGER IF NOT EXISTS meals_fts_insert AFTER INSERT ON meals
+BEGIN
+  INSERT INTO meals_fts (meal_id, meal_name, ingredients)
+  VALUES (new.meal_id, new.meal_name, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_fts_update AFTER UPDATE OF meal_name ON meals
+BEGIN
+  UPDATE meals_fts SET meal_name = new.meal_name WHERE meal_id = old.meal_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_fts_delete AFTER DELETE ON meals
+BEGIN
+  DELETE FROM meals_fts WHERE meal_id = old.meal_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_fts_meal_food_insert AFTER INSERT ON meal_foods
+BEGIN
+  UPDATE meals_fts SET ingredients = (
+    SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+    FROM meal_foods mf
+    JOIN foods f ON f.food_id = mf.food_id
+    WHERE mf.meal_id = new.meal_id
+  ) WHERE meal_id = new.meal_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_fts_meal_food_delete AFTER DELETE ON meal_foods
+BEGIN
+  UPDATE meals_fts SET ingredients = (
+    SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+    FROM meal_foods mf
+    JOIN foods f ON f.food_id = mf.food_id
+    WHERE mf.meal_id = old.meal_id
+  ) WHERE meal_id = old.meal_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_fts_food_update AFTER UPDATE OF food_name ON foods
+BEGIN
+  UPDATE meals_fts SET ingredients = (
+    SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+    FROM meal_foods mf
+    JOIN foods f ON f.food_id = mf.food_id
+    WHERE mf.meal_id = meals_fts.meal_id
+  ) WHERE meal_id IN (SELECT meal_id FROM meal_foods WHERE food_id = new.food_id);
+END;
+
+-- Index any meals that existed before meals_fts was created.
+INSERT INTO meals_fts (meal_id, meal_name, ingredients)
+SELECT m.meal_id, m.meal_name, COALESCE((
+  SELECT GROUP_CONCAT(f.food_name, ' ')
+  FROM meal_foods mf
+  JOIN foods f ON f.food_id = mf.food_id
+  WHERE mf.meal_id = m.meal_id
+), '')
+FROM meals m
+WHERE m.meal_id NOT IN (SELECT meal_id FROM meals_fts);
+
+-- Carry out the ON DELETE actions of the foreign keys of foods and
+-- meals. bite only turns on foreign key enforcement when the keys hold,
+-- and databases made before these actions were declared have keys that
+-- can't be enforced, so the triggers do the work there instead. db
+-- vacuum adds the triggers to such databases, but not the actions.
+CREATE TRIGGER IF NOT EXISTS foods_delete AFTER DELETE ON foods
+BEGIN
+  DELETE FROM food_prefs WHERE food_id = old.food_id;
+  DELETE FROM meal_food_prefs WHERE food_id = old.food_id;
+  DELETE FROM meal_foods WHERE food_id = old.food_id;
+  DELETE FROM food_groups WHERE food_id = old.food_id;
+  DELETE FROM food_tags WHERE food_id = old.food_id;
+  DELETE FROM food_nutrients WHERE food_id = old.food_id;
+  DELETE FROM daily_foods WHERE food_id = old.food_id;
+  DELETE FROM pantry WHERE food_id = old.food_id;
+  DELETE FROM price_history WHERE food_id = old.food_id;
+  DELETE FROM foods_fts WHERE food_id = old.food_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meals_delete AFTER DELETE ON meals
+BEGIN
+  DELETE FROM meal_food_prefs WHERE meal_id = old.meal_id;
+  DELETE FROM meal_foods WHERE meal_id = old.meal_id;
+  DELETE FROM meal_tags WHERE meal_id = old.meal_id;
+  DELETE FROM leftovers WHERE meal_id = old.meal_id;
+  DELETE FROM daily_meals WHERE meal_id = old.meal_id;
+  UPDATE daily_foods SET meal_id = NULL WHERE meal_id = old.meal_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS meal_foods_delete AFTER DELETE ON meal_foods
+BEGIN
+  DELETE FROM meal_food_prefs
+  WHERE meal_id = old.meal_id AND food_id = old.food_id;
+END;
+
+-- safety_overrides records each safety check the user chose to
+-- override, such as a cut faster than 1.5% of body weight a week or a
+-- calorie goal below the BMR.
+CREATE TABLE IF NOT EXISTS safety_overrides (
+  id INTEGER PRIMARY KEY,
+  date DATE NOT NULL,
+  phase TEXT NOT NULL,
+  rule TEXT NOT NULL,
+  detail TEXT NOT NULL
 );
+
+-- audit_log records every insert, update, and delete of the other
+-- tables, with the row before and after as JSON. Its triggers are made
+-- when bite opens the database, and each change is attributed to the
+-- command and user that made it. The changes of each run of a command
+-- share its run, the id of the run's first change.
+CREATE TABLE IF NOT EXISTS audit_log (
+  id INTEGER PRIMARY KEY,
+  time TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')) NOT NULL,
+  command TEXT DEFAULT '' NOT NULL,
+  user TEXT DEFAULT '' NOT NULL,
+  table_name TEXT NOT NULL,
+  action TEXT NOT NULL CHECK(action IN ('insert', 'update', 'delete')),
+  row_id INTEGER NOT NULL,
+  before TEXT,
+  after TEXT,
+  run INTEGER DEFAULT 0 NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log(time);
@@ -0,0 +1,134 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// WeekStart is the first day of the week in the weekly summaries.
+	WeekStart = time.Monday
+
+	// DateFormats are the layouts tried, in order, when parsing a date
+	// entered by the user. A layout without a year is taken to be in the
+	// current year.
+	DateFormats = []string{dateFormat, "1/2", "1/2/2006"}
+)
+
+// dateFormatNames maps the date formats the user can choose to their
+// layouts.
+var dateFormatNames = map[string]string{
+	"YYYY-MM-DD": dateFormat,
+	"MM/DD":      "1/2",
+	"MM/DD/YYYY": "1/2/2006",
+	"DD/MM":      "2/1",
+	"DD/MM/YYYY": "2/1/2006",
+	"DD.MM":      "2.1",
+	"DD.MM.YYYY": "2.1.2006",
+}
+
+// ParseDateFormat returns the layout of a date format such as
+// "MM/DD" or "DD.MM.YYYY".
+func ParseDateFormat(name string) (string, error) {
+	layout, ok := dateFormatNames[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown date format %q", name)
+	}
+	return layout, nil
+}
+
+// ParseWeekday returns the day of the week with the given name or its
+// first three letters, ignoring case.
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if strings.HasPrefix(name, s) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week %q", s)
+}
+
+// ParseSince parses how far back to look, as a number of minutes,
+// hours, days, or weeks such as "30m", "12h", "7d", or "2w", or as a
+// date accepted by ParseDate, and returns the time that far back from
+// now or the start of the date.
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	units := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	if len(s) > 1 {
+		if unit, ok := units[s[len(s)-1:]]; ok {
+			if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+				return now.Add(-time.Duration(n) * unit), nil
+			}
+		}
+	}
+	d, err := ParseDate(s, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since must be a date or a number of minutes, hours, days, or weeks such as 7d, got %q", s)
+	}
+	// Look back from the start of the day where now is.
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, now.Location()), nil
+}
+
+// ParseDate parses a date entered by the user. Besides the layouts in
+// DateFormats, it accepts "today", "yesterday", "tomorrow", a day of
+// the week for the last such day up to today, and "next" followed by a
+// day of the week for the next such day after today.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	today := dateOf(now)
+
+	switch s {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	if strings.HasPrefix(s, "next ") {
+		d, err := ParseWeekday(strings.TrimPrefix(s, "next "))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Invalid date %q", s)
+		}
+		diff := (int(d) - int(today.Weekday()) + 7) % 7
+		if diff == 0 {
+			diff = 7
+		}
+		return today.AddDate(0, 0, diff), nil
+	}
+	if d, err := ParseWeekday(s); err == nil {
+		return today.AddDate(0, 0, -((int(today.Weekday()) - int(d) + 7) % 7)), nil
+	}
+
+	for _, layout := range DateFormats {
+		date, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(layout, "2006") {
+			date = time.Date(today.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("Invalid date %q", s)
+}
+
+// startOfWeek returns the first day, as set by WeekStart, of the week of
+// the given day.
+func startOfWeek(t time.Time) time.Time {
+	t = dateOf(t)
+	return t.AddDate(0, 0, -((int(t.Weekday()) - int(WeekStart) + 7) % 7))
+}
@@ -0,0 +1,35 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleParseDate() {
+	// Wednesday.
+	now := time.Date(2024, 1, 17, 15, 30, 0, 0, time.UTC)
+	for _, s := range []string{"2024-01-15", "01/15", "1/15/2023", "yesterday", "mon", "Wed", "next wed", "15/01"} {
+		d, err := ParseDate(s, now)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(d.Format(dateFormat))
+	}
+
+	// Weeks starting on Sunday.
+	WeekStart = time.Sunday
+	defer func() { WeekStart = time.Monday }()
+	fmt.Println(startOfWeek(now).Format(dateFormat))
+
+	// Output:
+	// 2024-01-15
+	// 2024-01-15
+	// 2023-01-15
+	// 2024-01-16
+	// 2024-01-15
+	// 2024-01-17
+	// 2024-01-24
+	// Invalid date "15/01"
+	// 2024-01-14
+}
@@ -0,0 +1,130 @@
+package bite
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// FoodEntryHash returns the content hash of a food entry. Entries of the
+// same food logged at the same date and time with the same servings
+// have the same hash.
+func FoodEntryHash(foodID int, date, clock string, servingSize, numServings float64) string {
+	return contentHash("food", strconv.Itoa(foodID), date, clock,
+		formatHashFloat(servingSize), formatHashFloat(numServings))
+}
+
+// WeightEntryHash returns the content hash of a weight entry.
+func WeightEntryHash(date, clock string, weight float64) string {
+	return contentHash("weight", date, clock, formatHashFloat(weight))
+}
+
+// Hash returns the content hash of the food entry.
+func (f SyncFood) Hash() string {
+	return FoodEntryHash(f.FoodID, f.Date, f.Time, f.ServingSize, f.NumberOfServings)
+}
+
+// Hash returns the content hash of the weight entry.
+func (w SyncWeight) Hash() string {
+	return WeightEntryHash(w.Date, w.Time, w.Weight)
+}
+
+// contentHash hashes the fields. The result is stable across devices
+// and versions, so it must not change once hashes are stored.
+func contentHash(fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// formatHashFloat formats a float the same way no matter how it was
+// written, e.g. 1 and 1.0.
+func formatHashFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// BackfillHashes stores the content hash of log rows that don't have
+// one yet. Rows lose their hash when their content changes.
+func BackfillHashes(tx *sqlx.Tx) error {
+	var foods []struct {
+		ID int `db:"id"`
+		SyncFood
+	}
+	const foodsQuery = `
+		SELECT id, food_id, CAST(date AS TEXT) AS date, time, serving_size,
+			number_of_servings
+		FROM daily_foods
+		WHERE content_hash IS NULL
+	`
+	if err := tx.Select(&foods, foodsQuery); err != nil {
+		return fmt.Errorf("couldn't get unhashed food entries: %v", err)
+	}
+	for _, f := range foods {
+		const query = `UPDATE daily_foods SET content_hash = $1 WHERE id = $2`
+		if _, err := tx.Exec(query, f.Hash(), f.ID); err != nil {
+			return fmt.Errorf("couldn't store food entry hash: %v", err)
+		}
+	}
+
+	var weights []struct {
+		ID int `db:"id"`
+		SyncWeight
+	}
+	const weightsQuery = `
+		SELECT id, CAST(date AS TEXT) AS date, time, weight
+		FROM daily_weights
+		WHERE content_hash IS NULL
+	`
+	if err := tx.Select(&weights, weightsQuery); err != nil {
+		return fmt.Errorf("couldn't get unhashed weight entries: %v", err)
+	}
+	for _, w := range weights {
+		const query = `UPDATE daily_weights SET content_hash = $1 WHERE id = $2`
+		if _, err := tx.Exec(query, w.Hash(), w.ID); err != nil {
+			return fmt.Errorf("couldn't store weight entry hash: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// hashExists reports whether a row of the table has the content hash.
+func hashExists(tx *sqlx.Tx, table, hash string) (bool, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE content_hash = $1`, table)
+	var n int
+	if err := tx.Get(&n, query, hash); err != nil {
+		return false, fmt.Errorf("couldn't look up %s hash: %v", table, err)
+	}
+	return n > 0, nil
+}
+
+// DeleteDuplicateEntries removes log rows that have the same content as
+// an earlier row and returns how many were removed.
+func DeleteDuplicateEntries(tx *sqlx.Tx) (int, error) {
+	if err := BackfillHashes(tx); err != nil {
+		return 0, err
+	}
+
+	var total int
+	for _, t := range syncTables {
+		query := fmt.Sprintf(`
+			DELETE FROM %[1]s
+			WHERE id NOT IN (
+				SELECT MIN(id) FROM %[1]s GROUP BY content_hash
+			)
+		`, t)
+		res, err := tx.Exec(query)
+		if err != nil {
+			return total, fmt.Errorf("couldn't delete duplicate %s rows: %v", t, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += int(n)
+	}
+	return total, nil
+}
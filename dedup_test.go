@@ -0,0 +1,87 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+func ExampleFoodEntryHash() {
+	a := FoodEntryHash(1, "2024-01-01", "08:00:00", 100, 1)
+	b := FoodEntryHash(1, "2024-01-01", "08:00:00", 100.0, 1.0)
+	c := FoodEntryHash(1, "2024-01-01", "08:00:00", 100, 2)
+	fmt.Println(a == b, a == c)
+
+	// Output:
+	// true false
+}
+
+func ExampleImportChanges_duplicate() {
+	laptop := syncTestDB()
+	defer laptop.Close()
+	home := syncTestDB()
+	defer home.Close()
+
+	// The same breakfast was logged twice on the laptop and once at
+	// home.
+	const breakfast = `
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (1, '2024-01-01', '08:00:00', 100, 52, 0.3, 0.2, 14)
+	`
+	laptop.MustExec(breakfast)
+	laptop.MustExec(breakfast)
+	home.MustExec(breakfast)
+
+	_, r, err := transfer(laptop, home, time.Time{})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	var n int
+	if err := home.Get(&n, `SELECT COUNT(*) FROM daily_foods`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(n)
+
+	// Output:
+	// 0 inserted, 0 updated, 0 deleted, 0 skipped, 2 duplicates, 0 conflicts
+	// 1
+}
+
+func ExampleDeleteDuplicateEntries() {
+	db := syncTestDB()
+	defer db.Close()
+
+	db.MustExec(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (1, '2024-01-01', '08:00:00', 100, 52, 0.3, 0.2, 14),
+		       (1, '2024-01-01', '08:00:00', 100, 52, 0.3, 0.2, 14),
+		       (1, '2024-01-01', '08:00:00', 200, 104, 0.6, 0.4, 28);
+		INSERT INTO daily_weights (date, time, weight)
+		VALUES ('2024-01-01', '07:00:00', 180),
+		       ('2024-01-01', '07:00:00', 180);
+	`)
+
+	// Editing an entry to match another one makes it a duplicate too.
+	db.MustExec(`UPDATE daily_foods SET serving_size = 100 WHERE serving_size = 200`)
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	n, err := DeleteDuplicateEntries(tx)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(n)
+
+	// Output:
+	// 3
+}
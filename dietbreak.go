@@ -0,0 +1,207 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DietBreak is a period of eating at maintenance during a diet phase.
+// The phase clock stops during a break, so the phase end date is pushed
+// back by its length.
+type DietBreak struct {
+	ID        int       `db:"id"`
+	PhaseID   int       `db:"phase_id"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"` // First day after the break.
+	// GoalCalories is the phase's calorie goal before the break. It is
+	// restored when the break ends.
+	GoalCalories float64 `db:"goal_calories"`
+	Status       string  `db:"status"`
+}
+
+// overlaps reports whether the break overlaps the days from start to
+// end, inclusive.
+func (b DietBreak) overlaps(start, end time.Time) bool {
+	return start.Before(b.EndDate) && !end.Before(b.StartDate)
+}
+
+// onBreak reports whether any of the days from start to end, inclusive,
+// fall in one of the phase's diet breaks.
+func (p PhaseInfo) onBreak(start, end time.Time) bool {
+	for _, b := range p.Breaks {
+		if b.overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveBreak returns the phase's diet break that hasn't ended, or nil.
+func (p PhaseInfo) ActiveBreak() *DietBreak {
+	for i := range p.Breaks {
+		if p.Breaks[i].Status == "active" {
+			return &p.Breaks[i]
+		}
+	}
+	return nil
+}
+
+// loadDietBreaks reads the diet breaks of the user's phase.
+func loadDietBreaks(tx *sqlx.Tx, u *UserInfo) error {
+	const query = `
+		SELECT * FROM diet_breaks
+		WHERE phase_id = $1
+		ORDER BY start_date
+	`
+	u.Phase.Breaks = nil
+	if err := tx.Select(&u.Phase.Breaks, query, u.Phase.PhaseID); err != nil {
+		return fmt.Errorf("couldn't get diet breaks: %v", err)
+	}
+	return nil
+}
+
+// StartDietBreak starts a diet break of the given number of weeks
+// today. The calorie goal is set to the user's TDEE and the phase end
+// date is pushed back by the length of the break.
+func StartDietBreak(db *sqlx.DB, u *UserInfo, weeks int) error {
+	if weeks < 1 {
+		return fmt.Errorf("a diet break must last at least 1 week, got %d", weeks)
+	}
+	if u.Phase.Status != "active" {
+		return errors.New("there is no active diet phase")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := startDietBreak(tx, u, LogNow(), 7*weeks); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	b := u.Phase.ActiveBreak()
+	fmt.Printf("Diet break started. Eat %.0f calories a day until %s.\n",
+		u.Phase.GoalCalories, b.EndDate.AddDate(0, 0, -1).Format(dateFormat))
+	fmt.Printf("Your %s now ends on %s.\n", u.Phase.Name, u.Phase.EndDate.Format(dateFormat))
+	return nil
+}
+
+// startDietBreak records a diet break of the given number of days
+// starting on the given day and updates the phase.
+func startDietBreak(tx *sqlx.Tx, u *UserInfo, start time.Time, days int) error {
+	if err := loadDietBreaks(tx, u); err != nil {
+		return err
+	}
+	if u.Phase.ActiveBreak() != nil {
+		return errors.New("a diet break is already in progress")
+	}
+
+	start = dateOf(start)
+	b := DietBreak{
+		PhaseID:      u.Phase.PhaseID,
+		StartDate:    start,
+		EndDate:      start.AddDate(0, 0, days),
+		GoalCalories: u.Phase.GoalCalories,
+		Status:       "active",
+	}
+	const query = `
+		INSERT INTO diet_breaks (phase_id, start_date, end_date, goal_calories, status)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	res, err := tx.Exec(query, b.PhaseID, b.StartDate.Format(dateFormat),
+		b.EndDate.Format(dateFormat), b.GoalCalories, b.Status)
+	if err != nil {
+		return fmt.Errorf("couldn't save diet break: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	b.ID = int(id)
+	u.Phase.Breaks = append(u.Phase.Breaks, b)
+
+	u.Phase.GoalCalories = u.TDEE
+	u.Phase.EndDate = u.Phase.EndDate.AddDate(0, 0, days)
+	u.Phase.Duration += float64(days) / 7
+	return updatePhaseInfo(tx, u)
+}
+
+// StopDietBreak ends the active diet break early. The phase end date is
+// moved forward by the days of the break that are left.
+func StopDietBreak(db *sqlx.DB, u *UserInfo) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := loadDietBreaks(tx, u); err != nil {
+		return err
+	}
+	b := u.Phase.ActiveBreak()
+	if b == nil {
+		return errors.New("there is no diet break in progress")
+	}
+
+	today := Today()
+	if today.Before(b.EndDate) {
+		left := int(b.EndDate.Sub(today).Hours() / 24)
+		u.Phase.EndDate = u.Phase.EndDate.AddDate(0, 0, -left)
+		u.Phase.Duration -= float64(left) / 7
+		b.EndDate = today
+	}
+	if err := endDietBreak(tx, u, b); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Diet break stopped. Your calorie goal is back to %.0f calories.\n", u.Phase.GoalCalories)
+	return nil
+}
+
+// endDietBreak marks the break as completed and restores the phase's
+// calorie goal.
+func endDietBreak(tx *sqlx.Tx, u *UserInfo, b *DietBreak) error {
+	b.Status = "completed"
+	const query = `UPDATE diet_breaks SET end_date = $1, status = $2 WHERE id = $3`
+	if _, err := tx.Exec(query, b.EndDate.Format(dateFormat), b.Status, b.ID); err != nil {
+		return fmt.Errorf("couldn't update diet break: %v", err)
+	}
+
+	u.Phase.GoalCalories = b.GoalCalories
+	return updatePhaseInfo(tx, u)
+}
+
+// checkDietBreaks loads the phase's diet breaks and ends the active
+// break if it is over.
+func checkDietBreaks(tx *sqlx.Tx, u *UserInfo, now time.Time) error {
+	if err := loadDietBreaks(tx, u); err != nil {
+		return err
+	}
+
+	b := u.Phase.ActiveBreak()
+	if b == nil || dateOf(now).Before(b.EndDate) {
+		return nil
+	}
+	if err := endDietBreak(tx, u, b); err != nil {
+		return err
+	}
+	fmt.Printf("Your diet break is over. Your calorie goal is back to %.0f calories.\n", u.Phase.GoalCalories)
+	return nil
+}
+
+// dateOf returns the date of t at midnight UTC, the way dates are read
+// from the database.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,80 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleStartDietBreak() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{UserID: 1, TDEE: 2500}
+	u.Phase = PhaseInfo{
+		UserID:          1,
+		Name:            "cut",
+		GoalCalories:    2000,
+		StartDate:       start,
+		EndDate:         start.AddDate(0, 0, 56),
+		LastCheckedWeek: start,
+		Duration:        8,
+		Status:          "active",
+	}
+	if err := insertOrUpdatePhaseInfo(tx, &u); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Take a week off after three weeks of cutting.
+	breakStart := start.AddDate(0, 0, 21)
+	if err := startDietBreak(tx, &u, breakStart, 7); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(u.Phase.GoalCalories, u.Phase.EndDate.Format(dateFormat), u.Phase.Duration)
+
+	// Weeks with break days are excluded from progress checks.
+	fmt.Println(u.Phase.onBreak(start.AddDate(0, 0, 14), start.AddDate(0, 0, 20)))
+	fmt.Println(u.Phase.onBreak(start.AddDate(0, 0, 24), start.AddDate(0, 0, 30)))
+	fmt.Println(u.Phase.onBreak(start.AddDate(0, 0, 28), start.AddDate(0, 0, 34)))
+
+	// The break ends on its own once it is over.
+	if err := checkDietBreaks(tx, &u, breakStart.AddDate(0, 0, 6)); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(u.Phase.ActiveBreak() != nil)
+	if err := checkDietBreaks(tx, &u, breakStart.AddDate(0, 0, 7)); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(u.Phase.ActiveBreak() != nil)
+
+	var goal float64
+	if err := tx.Get(&goal, `SELECT goal_calories FROM phase_info`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(goal)
+
+	// Output:
+	// 2500 2024-03-04 9
+	// false
+	// true
+	// false
+	// true
+	// Your diet break is over. Your calorie goal is back to 2000 calories.
+	// false
+	// 2000
+}
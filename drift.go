@@ -0,0 +1,189 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MaintenanceDrift is how far the trend weight may drift from the
+// weight a cut or bulk ended at before it is flagged.
+var MaintenanceDrift = 3.0
+
+// Settings of the weight watched after a cut or bulk is completed.
+const (
+	watchWeightSetting  = "watch.weight"
+	watchSinceSetting   = "watch.since"
+	watchAlertedSetting = "watch.alerted"
+)
+
+// Drift is how far the trend weight has moved from the weight a cut or
+// bulk ended at.
+type Drift struct {
+	Since  time.Time // Date the phase ended.
+	Weight float64   // Weight the phase ended at.
+	Trend  float64   // Trend weight of the weights logged since.
+}
+
+// Change returns the trend weight less the weight the phase ended at.
+func (d Drift) Change() float64 {
+	return d.Trend - d.Weight
+}
+
+// Over reports whether the trend weight has drifted further than
+// MaintenanceDrift.
+func (d Drift) Over() bool {
+	return math.Abs(d.Change()) > MaintenanceDrift
+}
+
+// String returns a warning of the drift.
+func (d Drift) String() string {
+	dir := "above"
+	if d.Change() < 0 {
+		dir = "below"
+	}
+	return fmt.Sprintf("Your trend weight of %s is %s %s the %s your last phase ended at on %s.",
+		FormatWeight(d.Trend), FormatWeight(math.Abs(d.Change())), dir, FormatWeight(d.Weight), d.Since.Format(dateFormat))
+}
+
+// weightDrift is the data of a weight-drift event.
+type weightDrift struct {
+	Since  string  `json:"since"`
+	Weight float64 `json:"weight"`
+	Trend  float64 `json:"trend"`
+	Change float64 `json:"change"`
+}
+
+// watchWeight starts watching the trend weight for drift from the
+// user's weight at the end of their phase.
+func watchWeight(tx *sqlx.Tx, u *UserInfo) error {
+	if err := SetSetting(tx, watchWeightSetting, strconv.FormatFloat(u.Weight, 'f', -1, 64)); err != nil {
+		return err
+	}
+	if err := SetSetting(tx, watchSinceSetting, u.Phase.EndDate.Format(dateFormat)); err != nil {
+		return err
+	}
+	return DeleteSetting(tx, watchAlertedSetting)
+}
+
+// WeightDrift returns the drift of the trend weight from the weight the
+// last completed cut or bulk ended at. The boolean is false when no
+// weight is watched: no cut or bulk was completed, a cut or bulk has
+// started since, or no weight has been logged since.
+func WeightDrift(tx *sqlx.Tx) (Drift, bool, error) {
+	var d Drift
+	var exists bool
+	const existsSQL = `
+		SELECT COUNT(*) > 0 FROM sqlite_master
+		WHERE type = 'table' AND name = 'settings'
+	`
+	if err := tx.Get(&exists, existsSQL); err != nil {
+		return d, false, fmt.Errorf("couldn't look up settings: %v", err)
+	}
+	if !exists {
+		return d, false, nil
+	}
+
+	var watch struct {
+		Weight string `db:"weight"`
+		Since  string `db:"since"`
+	}
+	const watchSQL = `
+		SELECT w.value AS weight, s.value AS since
+		FROM settings w JOIN settings s ON s.key = $2
+		WHERE w.key = $1
+	`
+	err := tx.Get(&watch, watchSQL, watchWeightSetting, watchSinceSetting)
+	if errors.Is(err, sql.ErrNoRows) {
+		return d, false, nil
+	}
+	if err != nil {
+		return d, false, fmt.Errorf("couldn't get watched weight: %v", err)
+	}
+	if d.Weight, err = strconv.ParseFloat(watch.Weight, 64); err != nil {
+		return d, false, fmt.Errorf("invalid %s setting %q: %v", watchWeightSetting, watch.Weight, err)
+	}
+	if d.Since, err = time.Parse(dateFormat, watch.Since); err != nil {
+		return d, false, fmt.Errorf("invalid %s setting %q: %v", watchSinceSetting, watch.Since, err)
+	}
+
+	// A new cut or bulk has its own goal weight.
+	var started bool
+	const startedSQL = `
+		SELECT COUNT(*) > 0 FROM phase_info
+		WHERE name IN ('cut', 'bulk') AND status != 'stopped'
+			AND start_date >= $1 AND start_date <= $2
+	`
+	if err := tx.Get(&started, startedSQL, watch.Since, Today().Format(dateFormat)); err != nil {
+		return d, false, fmt.Errorf("couldn't get phases: %v", err)
+	}
+	if started {
+		return d, false, nil
+	}
+
+	if err := addWeightColumns(tx); err != nil {
+		return d, false, err
+	}
+	const weightsSQL = `
+		SELECT date, weight AS user_weight FROM daily_weights
+		WHERE estimated = 0 AND date >= $1
+		ORDER BY date
+	`
+	var entries []Entry
+	if err := tx.Select(&entries, weightsSQL, watch.Since); err != nil {
+		return d, false, fmt.Errorf("couldn't get weights: %v", err)
+	}
+	if len(entries) == 0 {
+		return d, false, nil
+	}
+	// The trend starts from the weight the phase ended at.
+	entries = append([]Entry{{Date: d.Since, UserWeight: d.Weight}}, entries...)
+	trend := TrendWeights(entries)
+	d.Trend = trend[len(trend)-1]
+	return d, true, nil
+}
+
+// checkDrift emits a weight-drift event when the trend weight first
+// drifts further than MaintenanceDrift, and again only once it has come
+// back within it and drifted again.
+func checkDrift(tx *sqlx.Tx) error {
+	d, ok, err := WeightDrift(tx)
+	if err != nil || !ok {
+		return err
+	}
+	var alerted bool
+	const query = `SELECT COUNT(*) > 0 FROM settings WHERE key = $1`
+	if err := tx.Get(&alerted, query, watchAlertedSetting); err != nil {
+		return fmt.Errorf("couldn't get setting %q: %v", watchAlertedSetting, err)
+	}
+
+	switch {
+	case d.Over() && !alerted:
+		emit(EventWeightDrift, weightDrift{
+			Since:  d.Since.Format(dateFormat),
+			Weight: d.Weight,
+			Trend:  math.Round(d.Trend*10) / 10,
+			Change: math.Round(d.Change()*10) / 10,
+		})
+		return SetSetting(tx, watchAlertedSetting, Today().Format(dateFormat))
+	case !d.Over() && alerted:
+		return DeleteSetting(tx, watchAlertedSetting)
+	}
+	return nil
+}
+
+// DriftReminders returns a warning when the trend weight has drifted
+// further than MaintenanceDrift from the weight the last completed cut
+// or bulk ended at.
+func DriftReminders(tx *sqlx.Tx) ([]string, error) {
+	d, ok, err := WeightDrift(tx)
+	if err != nil || !ok || !d.Over() {
+		return nil, err
+	}
+	return []string{d.String()}, nil
+}
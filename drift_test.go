@@ -0,0 +1,58 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleDriftReminders() {
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	defer DiscardEvents()
+	defer SetClock(FixedClock(time.Date(2024, 3, 26, 7, 0, 0, 0, time.UTC)))()
+
+	u, err := Config(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	if err := watchWeight(tx, u); err != nil {
+		log.Fatal(err)
+	}
+
+	// The weight creeps back up after the cut.
+	for d := 26; d <= 31; d++ {
+		date := time.Date(2024, 3, d, 7, 0, 0, 0, time.UTC)
+		SetClock(FixedClock(date))
+		if err := insertWeightEntry(tx, date, 193); err != nil {
+			log.Fatal(err)
+		}
+		drift, err := DriftReminders(tx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var alerted bool
+		tx.Get(&alerted, `SELECT COUNT(*) > 0 FROM settings WHERE key = $1`, watchAlertedSetting)
+		fmt.Println(d, alerted, drift)
+	}
+
+	// A new cut ends the watch.
+	tx.MustExec(`UPDATE phase_info SET start_date = '2024-03-31', status = 'active'`)
+	drift, err := DriftReminders(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(drift)
+	// Output:
+	// 26 false []
+	// 27 false []
+	// 28 false []
+	// 29 false []
+	// 30 true [Your trend weight of 188.3 is 3.3 above the 185.0 your last phase ended at on 2024-03-25.]
+	// 31 true [Your trend weight of 188.7 is 3.7 above the 185.0 your last phase ended at on 2024-03-25.]
+	// []
+}
@@ -0,0 +1,241 @@
+package bite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite/vfs"
+)
+
+// DryRunning is set while DryRun calls f, so that the changes f would
+// make outside the database, such as running hooks or adding the files
+// of progress photos, are skipped too.
+var DryRunning bool
+
+// DryRun calls f with a copy of db, so that f goes through its whole
+// flow, validation and computed values included, without changing db.
+// It then writes the rows f would have added to or deleted from each
+// table to w. A changed row is written as the old row deleted and the
+// new one added. The copy is kept in memory, so a dry run of an
+// encrypted database doesn't leave its data on disk.
+func DryRun(db *sqlx.DB, w io.Writer, f func(db *sqlx.DB) error) error {
+	image, err := serialize(db)
+	if err != nil {
+		return fmt.Errorf("couldn't copy database for dry run: %v", err)
+	}
+	cp, err := openMemory(image)
+	if err != nil {
+		return err
+	}
+	DryRunning = true
+	err = f(cp)
+	DryRunning = false
+	if err == nil {
+		image, err = serialize(cp)
+	}
+	if cerr := cp.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return writeChanges(w, db, image)
+}
+
+// writeChanges writes the rows of each table of the database image that
+// differ from db's, prefixed with "+" for rows only in the image and
+// "-" for rows only in db.
+func writeChanges(w io.Writer, db *sqlx.DB, image []byte) error {
+	name, vfsFS, err := vfs.New(memFS{name: memDBName, data: image})
+	if err != nil {
+		return err
+	}
+	defer vfsFS.Close()
+
+	var buf bytes.Buffer
+	changes, err := diffRows(&buf, db, "file:"+memDBName+"?vfs="+name)
+	if err != nil {
+		return err
+	}
+	if changes == 0 {
+		_, err = fmt.Fprintln(w, "\nDry run: no changes would have been saved.")
+		return err
+	}
+	fmt.Fprintln(w, "\nDry run: nothing was saved. These changes would have been:")
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// diffRows writes the rows of each table of the database at path that
+// differ from db's to w, as writeChanges does, and returns how many
+// there are. Only the columns both tables have are compared.
+func diffRows(w io.Writer, db *sqlx.DB, path string) (int, error) {
+	ctx := context.Background()
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE $1 AS dry`, path); err != nil {
+		return 0, fmt.Errorf("couldn't attach dry run database: %v", err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE dry`)
+
+	tables, err := changeTables(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	changes := 0
+	for _, t := range tables {
+		before, err := tableColumns(ctx, conn, "main", t)
+		if err != nil {
+			return 0, err
+		}
+		after, err := tableColumns(ctx, conn, "dry", t)
+		if err != nil {
+			return 0, err
+		}
+		cols := after
+		if len(before) > 0 {
+			cols = nil
+			for _, c := range after {
+				for _, b := range before {
+					if c == b {
+						cols = append(cols, c)
+						break
+					}
+				}
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+
+		list := `"` + strings.Join(cols, `", "`) + `"`
+		queries := []struct {
+			sign  string
+			query string
+		}{
+			{"-", fmt.Sprintf(`SELECT %[1]s FROM main."%[2]s" EXCEPT SELECT %[1]s FROM dry."%[2]s"`, list, t)},
+			{"+", fmt.Sprintf(`SELECT %[1]s FROM dry."%[2]s" EXCEPT SELECT %[1]s FROM main."%[2]s"`, list, t)},
+		}
+		if len(before) == 0 {
+			// The table would have been created.
+			queries = queries[1:]
+			queries[0].query = fmt.Sprintf(`SELECT %s FROM dry."%s"`, list, t)
+		}
+		for _, q := range queries {
+			n, err := writeRows(ctx, w, conn, q.sign, t, q.query)
+			if err != nil {
+				return 0, err
+			}
+			changes += n
+		}
+	}
+	return changes, nil
+}
+
+// changeTables returns the names of the tables of the dry run database
+// that are compared, leaving out SQLite's own tables, the audit log,
+// virtual tables, and the tables that store them.
+func changeTables(ctx context.Context, conn *sqlx.Conn) ([]string, error) {
+	var all []struct {
+		Name string `db:"name"`
+		SQL  string `db:"sql"`
+	}
+	const query = `
+		SELECT name, COALESCE(sql, '') AS sql FROM dry.sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != 'audit_log'
+		ORDER BY name
+	`
+	if err := conn.SelectContext(ctx, &all, query); err != nil {
+		return nil, fmt.Errorf("couldn't get tables: %v", err)
+	}
+	var virtual []string
+	for _, t := range all {
+		if strings.HasPrefix(strings.ToUpper(t.SQL), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, t.Name)
+		}
+	}
+
+	var tables []string
+outer:
+	for _, t := range all {
+		for _, v := range virtual {
+			if t.Name == v || strings.HasPrefix(t.Name, v+"_") {
+				continue outer
+			}
+		}
+		tables = append(tables, t.Name)
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// tableColumns returns the column names of the table in schema, or nil
+// if it doesn't exist.
+func tableColumns(ctx context.Context, conn *sqlx.Conn, schema, table string) ([]string, error) {
+	var cols []string
+	query := fmt.Sprintf(`SELECT name FROM pragma_table_info($1, '%s')`, schema)
+	if err := conn.SelectContext(ctx, &cols, query, table); err != nil {
+		return nil, fmt.Errorf("couldn't get columns of %s: %v", table, err)
+	}
+	return cols, nil
+}
+
+// writeRows writes the rows the query returns as "sign table: col=value
+// ..." lines and returns how many there were.
+func writeRows(ctx context.Context, w io.Writer, conn *sqlx.Conn, sign, table, query string) (int, error) {
+	rows, err := conn.QueryxContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't compare %s: %v", table, err)
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for rows.Next() {
+		vals, err := rows.SliceScan()
+		if err != nil {
+			return n, fmt.Errorf("couldn't compare %s: %v", table, err)
+		}
+		fields := make([]string, len(vals))
+		for i, v := range vals {
+			fields[i] = cols[i] + "=" + formatValue(v)
+		}
+		fmt.Fprintf(w, "%s %s: %s\n", sign, table, strings.Join(fields, " "))
+		n++
+	}
+	return n, rows.Err()
+}
+
+// formatValue formats a column value for display.
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return fmt.Sprintf("%q", v)
+	case time.Time:
+		if v.Equal(dateOf(v)) {
+			return v.Format(dateFormat)
+		}
+		return v.Format(time.RFC3339)
+	case string:
+		if v == "" || strings.ContainsAny(v, " \t\n\"") {
+			return fmt.Sprintf("%q", v)
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
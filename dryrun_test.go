@@ -0,0 +1,63 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExampleDryRun() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bite.db")
+	err = dbtest.Create(path, `
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-03-18', '07:00:00', 180), ('2024-03-19', '07:00:00', 179.5);
+	`)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	db, err := Open(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer db.Close()
+
+	err = DryRun(db, os.Stdout, func(db *sqlx.DB) error {
+		fmt.Println("Updating weights.")
+		db.MustExec(`UPDATE daily_weights SET weight = 179 WHERE date = '2024-03-19'`)
+		db.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-20', '07:00:00', 178.5)`)
+		return nil
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM daily_weights WHERE weight < 179.5`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println("Weights under 179.5:", n)
+
+	// Output:
+	// Updating weights.
+	//
+	// Dry run: nothing was saved. These changes would have been:
+	// - daily_weights: id=2 date=2024-03-19 time=07:00:00 weight=179.5 estimated=0 logged_at="" utc_offset=0
+	// + daily_weights: id=2 date=2024-03-19 time=07:00:00 weight=179 estimated=0 logged_at="" utc_offset=0
+	// + daily_weights: id=3 date=2024-03-20 time=07:00:00 weight=178.5 estimated=0 logged_at="" utc_offset=0
+	// Weights under 179.5: 0
+}
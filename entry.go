@@ -1,11 +1,11 @@
 package bite
 
 import (
-	"bufio"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -40,6 +40,21 @@ type Entry struct {
 	Carbs      float64   `db:"carbs"`
 	Fat        float64   `db:"fat"`
 	Price      float64   `db:"price"`
+	Training   bool      `db:"training"`  // Whether a session was logged.
+	Estimated  bool      `db:"estimated"` // Whether the weight was estimated.
+	// Goal is the calorie goal in effect on the day, set by MarkGoals.
+	// Zero means the current goal.
+	Goal float64 `db:"-"`
+	// Sodium and Potassium are the day's sodium and potassium (mg),
+	// set by MarkSodium.
+	Sodium    float64 `db:"-"`
+	Potassium float64 `db:"-"`
+	// Fats is the day's fat breakdown, set by MarkFats. Nil means the
+	// day's foods have none.
+	Fats *FatQuality `db:"-"`
+	// Notes are the timeline notes of the day and of the days without
+	// an entry before it, set by MarkNotes.
+	Notes []Note `db:"-"`
 }
 
 type WeightEntry struct {
@@ -54,6 +69,7 @@ type DailyFood struct {
 	FoodID           int       `db:"food_id"`
 	MealID           *int      `db:"meal_id"`
 	Date             time.Time `db:"date"`
+	Time             string    `db:"time"`
 	ServingSize      float64   `db:"serving_size"`
 	ServingUnit      string    `db:"serving_unit"`
 	NumberOfServings float64   `db:"number_of_servings"`
@@ -68,23 +84,26 @@ type DailyFoodCount struct {
 }
 
 // AllEntries returns all the user's entries from the database.
-func AllEntries(db *sqlx.DB) (*[]Entry, error) {
-	query := `
+func AllEntries(q sqlx.Queryer) (*[]Entry, error) {
+	totals, err := dailyTotals(q)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
 	SELECT
 		dw.date,
 		dw.weight AS user_weight,
-		SUM(df.calories) AS calories,
-		SUM(df.protein) AS protein,
-		SUM(df.carbs) AS carbs,
-		SUM(df.fat) AS fat
+		t.calories,
+		t.protein,
+		t.carbs,
+		t.fat
 	FROM daily_weights dw
-	JOIN daily_foods df ON dw.date = df.date
-	GROUP BY dw.date, dw.weight
+	JOIN %s t ON dw.date = t.date
 	ORDER BY dw.date
-	`
+	`, totals)
 
 	var entries []Entry
-	if err := db.Select(&entries, query); err != nil {
+	if err := sqlx.Select(q, &entries, query); err != nil {
 		return &entries, err
 	}
 
@@ -98,7 +117,8 @@ func PrintEntries(entries []Entry) {
 	fmt.Println("-------------------------------------------------------------------------")
 	for _, entry := range entries {
 		dateStr := entry.Date.Format("2006-01-02")
-		fmt.Printf("| %-10s | %-12.2f | %-8.2f | %-11.2f | %-9.2f | %-7.2f |\n", dateStr, entry.UserWeight, entry.Calories, entry.Protein, entry.Carbs, entry.Fat)
+		fmt.Printf("| %-10s | %-11s | %-8.2f | %-11s | %-9s | %-7s |\n", dateStr, FormatWeight(entry.UserWeight), entry.Calories,
+			FormatMacro(entry.Protein), FormatMacro(entry.Carbs), FormatMacro(entry.Fat))
 	}
 	fmt.Println("-------------------------------------------------------------------------")
 }
@@ -122,12 +142,35 @@ func LogWeight(u *UserInfo, db *sqlx.DB) error {
 		}
 
 		// Get weight entry date from user
-		date := promptDateNotPast("Enter weight entry date")
+		date := promptDateNotPast("Enter weight entry date", LogNow())
+		// Today's weight is logged at the time of day, for the weigh-in
+		// protocol.
+		if now := LogNow(); isSameDay(date, now) {
+			date = now
+		}
+
+		// Ask before logging a weight that looks like a typo.
+		o, err := CheckWeight(tx, date, weight)
+		if err != nil {
+			return err
+		}
+		if o != nil && !confirmOutlier(o) {
+			continue
+		}
 
 		if err = addWeightEntry(tx, date, weight); err != nil {
 			fmt.Printf("%v. Please try again.\n", err)
 			continue
 		}
+		if isSameDay(date, LogNow()) {
+			p, err := weighInProtocol(tx, date)
+			if err != nil {
+				return err
+			}
+			if w := weighInWarning(p, date); w != "" {
+				fmt.Println(paint(colorMissed, w))
+			}
+		}
 
 		// Update users weight
 		u.Weight = weight
@@ -142,6 +185,32 @@ func LogWeight(u *UserInfo, db *sqlx.DB) error {
 
 // addWeightEntry inserts a weight entry into the database.
 func addWeightEntry(tx *sqlx.Tx, date time.Time, weight float64) error {
+	if err := insertWeightEntry(tx, date, weight); err != nil {
+		return err
+	}
+	fmt.Println("Successfully added weight entry.")
+	drift, err := DriftReminders(tx)
+	if err != nil {
+		return err
+	}
+	for _, d := range drift {
+		fmt.Println(paint(colorMissed, d))
+	}
+	return nil
+}
+
+// insertWeightEntry inserts a weight entry into the database, replacing
+// an estimated weight for the date.
+func insertWeightEntry(tx *sqlx.Tx, date time.Time, weight float64) error {
+	// A logged weight replaces an estimated one.
+	if err := addWeightColumns(tx); err != nil {
+		return err
+	}
+	const remove = `DELETE FROM daily_weights WHERE date = $1 AND estimated = 1`
+	if _, err := tx.Exec(remove, date.Format(dateFormat)); err != nil {
+		return fmt.Errorf("couldn't remove estimated weight: %v", err)
+	}
+
 	// Ensure weight hasn't already been logged for given date.
 	exists, err := checkWeightExists(tx, date)
 	if err != nil {
@@ -152,18 +221,25 @@ func addWeightEntry(tx *sqlx.Tx, date time.Time, weight float64) error {
 	}
 
 	// Insert the new weight entry into the weight database.
-	_, err = tx.Exec(`INSERT INTO daily_weights (date, time, weight) VALUES ($1, $2, $3)`, date.Format(dateFormat), date.Format(dateFormatTime), weight)
+	loggedAt, offset := logStamp()
+	const query = `
+		INSERT INTO daily_weights (date, time, weight, logged_at, utc_offset)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.Exec(query, date.Format(dateFormat), date.Format(dateFormatTime), weight, loggedAt, offset)
 	if err != nil {
 		return err
 	}
-
-	fmt.Println("Successfully added weight entry.")
-	return nil
+	emit(EventWeightLogged, weightLogged{Date: date.Format(dateFormat), Weight: weight})
+	return checkDrift(tx)
 }
 
 // promptDateNotPast prompts user for date that it not in the past, validates user
 // response until user enters a valid date, and return the valid date.
-func promptDateNotPast(s string) (date time.Time) {
+// The day of now is the default and the earliest date allowed, so food
+// can be logged on the day FoodLogNow falls in.
+func promptDateNotPast(s string, now time.Time) (date time.Time) {
+	today := dateOf(now)
 	for {
 		// Prompt user for diet start date.
 		r := promptDate(fmt.Sprintf("%s (YYYY-MM-DD) [Press <Enter> for today's date]: ", s))
@@ -171,7 +247,7 @@ func promptDateNotPast(s string) (date time.Time) {
 		// If user entered default date,
 		if r == "" {
 			// set date to today's date.
-			r = time.Now().Format(dateFormat)
+			r = today.Format(dateFormat)
 		}
 
 		// Ensure user response is a date.
@@ -183,7 +259,7 @@ func promptDateNotPast(s string) (date time.Time) {
 		}
 
 		// Ensure date is not in the past.
-		if !validateDateIsNotPast(date) {
+		if date.Before(today) {
 			fmt.Println("Date must be today or future date. Please try again.")
 			continue
 		}
@@ -193,13 +269,19 @@ func promptDateNotPast(s string) (date time.Time) {
 	return date
 }
 
-// ShowWeightLog prints entire weight log.
-func ShowWeightLog(db *sqlx.DB) error {
-	log, err := allWeightEntries(db)
+// ShowWeightLog prints a page of the weight log, most recent first.
+func ShowWeightLog(db *sqlx.DB, p Page) error {
+	log, err := weightLogPage(db, p)
 	if err != nil {
 		return err
 	}
 	printWeightEntries(log)
+
+	var total int
+	if err := db.Get(&total, `SELECT COUNT(*) FROM daily_weights`); err != nil {
+		return fmt.Errorf("couldn't count weight entries: %v", err)
+	}
+	printPageFooter(p, total)
 	return nil
 }
 
@@ -271,6 +353,9 @@ func deleteOneWeightEntry(db *sqlx.DB, id int) error {
 	if _, err := tx.Exec(deleteSQL, id); err != nil {
 		return err
 	}
+	if err := deleteAttachments(tx, "daily_weights", id); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
@@ -328,7 +413,7 @@ func selectWeightEntry(db *sqlx.DB) (WeightEntry, error) {
 		}
 
 		// Print entry.
-		fmt.Printf("[1] %s %f\n", entry.Date.Format(dateFormat), entry.Weight)
+		fmt.Printf("[1] %s %s\n", entry.Date.Format(dateFormat), FormatWeight(entry.Weight))
 
 		response = promptSelectEntry("Enter entry index to select or date to search (YYYY-MM-DD): ")
 		idx, err := strconv.Atoi(response)
@@ -352,20 +437,28 @@ func selectWeightEntry(db *sqlx.DB) (WeightEntry, error) {
 // printWeightEntries prints out specified weight entries.
 func printWeightEntries(entries []WeightEntry) {
 	for i, entry := range entries {
-		fmt.Printf("[%d] %s %f\n", i+1, entry.Date.Format(dateFormat), entry.Weight)
+		note := ""
+		if Cycle.InWindow(entry.Date) {
+			note = " (cycle water retention)"
+		}
+		fmt.Printf("[%d] %s %s%s\n", i+1, entry.Date.Format(dateFormat), FormatWeight(entry.Weight), note)
 	}
 }
 
-// allWeightEntries returns all the user's logged weight entries.
-func allWeightEntries(db *sqlx.DB) ([]WeightEntry, error) {
+// weightLogPage returns a page of the user's logged weight entries,
+// most recent first.
+func weightLogPage(q sqlx.Queryer, p Page) ([]WeightEntry, error) {
 	// Since DailyWeight struct does not currently support time field, the
 	// queury excludes the time field from the selected records.
 	const query = `
-		SELECT id, date, weight FROM daily_weights ORDER by date DESC"
+		SELECT id, date, weight FROM daily_weights
+		ORDER BY date DESC
+		LIMIT $1 OFFSET $2
 		`
+	limit, offset := p.limit()
 	wl := []WeightEntry{}
-	if err := db.Select(&wl, query); err != nil {
-		return nil, err
+	if err := sqlx.Select(q, &wl, query, limit, offset); err != nil {
+		return nil, fmt.Errorf("couldn't get weight entries: %v", err)
 	}
 	return wl, nil
 }
@@ -390,7 +483,7 @@ func recentWeightEntries(db *sqlx.DB) ([]WeightEntry, error) {
 // promptSelectEntry prompts and returns entry to select or a search
 // term.
 func promptSelectEntry(s string) string {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	fmt.Printf("%s: ", s)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -433,6 +526,8 @@ func checkWeightExists(tx *sqlx.Tx, date time.Time) (bool, error) {
 }
 
 // LogFood lets the user log multiple foods.
+//
+// Deprecated: Use Client.LogFood, which logs a food without prompting.
 func LogFood(db *sqlx.DB) error {
 	tx, err := db.Beginx()
 	if err != nil {
@@ -459,13 +554,29 @@ OuterLoop:
 			return fmt.Errorf("couldn't get food preferences: %v", err)
 		}
 
+		// Default to the median of the food's recently logged servings.
+		history, err := ServingHistory(tx, food.ID)
+		if err != nil {
+			return err
+		}
+		if s, ok := MedianServing(history); ok {
+			f.ServingSize, f.NumberOfServings = s.ServingSize, s.NumberOfServings
+		}
+		recent := RecentServings(history)
+
 		// Display any existing preferences for the selected food.
 		printFoodPref(*f)
+		printRecentServings(recent, f.ServingUnit)
+
+		choices := "1 = Update Values, 2 = Search Again"
+		if len(recent) > 0 {
+			choices += ", 3 = Recent Serving"
+		}
 
-		reader := bufio.NewReader(os.Stdin)
+		reader := Input
 	UserInputLoop:
 		for {
-			fmt.Printf("What would you like to do? (1 = Update Values, 2 = Search Again) [Press <Enter> for Existing]: ")
+			fmt.Printf("What would you like to do? (%s) [Press <Enter> for Existing]: ", choices)
 			s, err := reader.ReadString('\n')
 			if err != nil {
 				fmt.Println("Error reading input:", err)
@@ -487,6 +598,14 @@ OuterLoop:
 				break UserInputLoop
 			case "2": // User indicates they want to search again
 				continue OuterLoop
+			case "3": // User indicates they want a recently logged serving
+				if len(recent) == 0 {
+					fmt.Println("Invalid choice. Please enter 1, 2, or press <Enter>.")
+					continue
+				}
+				r := selectRecentServing(recent)
+				f.ServingSize, f.NumberOfServings = r.ServingSize, r.NumberOfServings
+				break UserInputLoop
 			default:
 				fmt.Println("Invalid choice. Please enter 1, 2, or press <Enter>.")
 			}
@@ -497,6 +616,7 @@ OuterLoop:
 		if err != nil {
 			return err
 		}
+		ScaleServings(foodWithPref, f.ServingSize, f.NumberOfServings)
 
 		selectedFoods = append(selectedFoods, *foodWithPref)
 	}
@@ -509,14 +629,12 @@ OuterLoop:
 	}
 
 	// Get date of food entry.
-	date := promptDateNotPast("Enter food entry date")
+	date := promptDateNotPast("Enter food entry date", FoodLogNow())
 
-	for _, f := range selectedFoods {
-		// Log selected food to the food log database table. Taking into
-		// account food preferences.
-		if err := AddFoodEntry(tx, &f, date); err != nil {
-			return fmt.Errorf("couldn't add food entry: %v", err)
-		}
+	// Log selected foods to the food log database table. Taking into
+	// account food preferences.
+	if err := AddFoodEntries(tx, selectedFoods, date); err != nil {
+		return err
 	}
 
 	fmt.Println("Successfully added food entry.")
@@ -560,6 +678,10 @@ func selectFood(db *sqlx.DB) (Food, error) {
 	}
 
 	// User response was a search term.
+	restricted, err := RestrictedFoods(db)
+	if err != nil {
+		return Food{}, err
+	}
 
 	// While user response is not an integer
 	for {
@@ -571,7 +693,16 @@ func selectFood(db *sqlx.DB) (Food, error) {
 
 		// If no matches found,
 		if len(filteredFoods) == 0 {
-			fmt.Println("No matches found. Please try again.")
+			fmt.Println("No matches found.")
+			name, ok, err := searchRemoteFoods(db, response)
+			if err != nil {
+				fmt.Println(err)
+			}
+			if ok {
+				response = name
+				continue
+			}
+			fmt.Println("Please try again.")
 			response = promptSelectResponse("food")
 			continue
 		}
@@ -582,6 +713,12 @@ func selectFood(db *sqlx.DB) (Food, error) {
 			if food.BrandName != "" {
 				brandDetail = " (Brand: " + food.BrandName + ")"
 			}
+			if s := SourceName(food.Source); s != "" {
+				brandDetail += " [" + s + "]"
+			}
+			if flags, ok := restricted[food.ID]; ok {
+				brandDetail += " (contains " + strings.Join(flags, ", ") + ")"
+			}
 			fmt.Printf("[%d] %s%s\n", i+1, food.Name, brandDetail)
 		}
 
@@ -666,24 +803,35 @@ func RecentlyLoggedFoods(db *sqlx.DB, limit int) ([]Food, error) {
 		foods[i].FoodMacros.Fat *= ratio * foods[i].NumberOfServings
 		foods[i].FoodMacros.Carbs *= ratio * foods[i].NumberOfServings
 		foods[i].Price *= ratio * foods[i].NumberOfServings
+
+		// Default to the median of the food's recently logged servings.
+		if err := DefaultServing(db, &foods[i]); err != nil {
+			return nil, fmt.Errorf("couldn't get default serving for %q: %v", foods[i].Name, err)
+		}
 	}
 
-	return foods, nil
+	if PreferVerified {
+		foods = preferVerified(foods)
+	}
+	// Recent foods are suggestions, so those the user avoids are left
+	// out.
+	return withoutRestricted(db, foods)
 }
 
 // SearchFoods searches through all foods and returns food that contain
-// the search term. The matching foods have associated preferences,
-// calorie, and macros.
+// the search term. Words like "tag:vegan" in the term keep only the
+// foods with the tag. Foods the user logs often and recently rank above
+// foods that only match the term better. The matching foods have
+// associated preferences, calorie, and macros. At most SearchLimit foods
+// are returned.
 func SearchFoods(db *sqlx.DB, term string) ([]Food, error) {
-	const (
-		searchSQL = `
-			SELECT f.*
-			FROM foods f
-			INNER JOIN foods_fts s ON s.food_id = f.food_id
-			WHERE foods_fts MATCH $1
-			ORDER BY bm25(foods_fts)
-			LIMIT $2`
+	return SearchFoodsPage(db, term, 0)
+}
 
+// SearchFoodsPage is like SearchFoods, but skips the first offset
+// matching foods so that results can be loaded a page at a time.
+func SearchFoodsPage(db *sqlx.DB, term string, offset int) ([]Food, error) {
+	const (
 		// Override existing serving size and number of servings if there
 		// exists a matching entry in the food_prefs table for the food id.
 		query = `
@@ -704,8 +852,16 @@ func SearchFoods(db *sqlx.DB, term string) ([]Food, error) {
 	)
 	foods := []Food{}
 
+	// Prefer cheaper foods while the week's food budget is tight.
+	cheaper, err := budgetTight(db)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get all matching foods.
-	if err := db.Select(&foods, searchSQL, term, SearchLimit); err != nil {
+	searchSQL, args := rankedSearchQuery("foods", "foods_fts", "food_id", "food_name", "food_tags", term,
+		searchOptions{logTable: "daily_foods", now: LogNow(), offset: offset, cheaper: cheaper})
+	if err := db.Select(&foods, searchSQL, args...); err != nil {
 		return nil, fmt.Errorf("couldn't get result foods: %v", err)
 	}
 
@@ -733,6 +889,11 @@ func SearchFoods(db *sqlx.DB, term string) ([]Food, error) {
 		foods[i].FoodMacros.Fat *= ratio * foods[i].NumberOfServings
 		foods[i].FoodMacros.Carbs *= ratio * foods[i].NumberOfServings
 		foods[i].Price *= ratio * foods[i].NumberOfServings
+
+		// Default to the median of the food's recently logged servings.
+		if err := DefaultServing(db, &foods[i]); err != nil {
+			return nil, fmt.Errorf("couldn't get default serving for %q: %v", foods[i].Name, err)
+		}
 	}
 
 	return foods, nil
@@ -740,7 +901,7 @@ func SearchFoods(db *sqlx.DB, term string) ([]Food, error) {
 
 // promptSelectResponse prompts and returns meal to select or a search term.
 func promptSelectResponse(item string) string {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	fmt.Printf("Enter either the index of the %s to select or a search term: ", item)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -781,6 +942,37 @@ func printFoodPref(pref FoodPref) {
 	fmt.Printf("Number of Servings: %.1f\n", pref.NumberOfServings)
 }
 
+// printRecentServings prints the recently logged servings of a food.
+func printRecentServings(recent []Serving, unit string) {
+	if len(recent) == 0 {
+		return
+	}
+
+	now := LogNow()
+	fmt.Println("Recent servings:")
+	for i, r := range recent {
+		fmt.Printf("[%d] %s\n", i+1, ServingLabel(r, unit, i == 0, now))
+	}
+}
+
+// selectRecentServing prompts the user for the index of a recent
+// serving, validates their response until they've entered a valid
+// index, and returns the selected serving.
+func selectRecentServing(recent []Serving) Serving {
+	for {
+		var response string
+		fmt.Printf("Enter recent serving index: ")
+		fmt.Fscanln(Input, &response)
+
+		idx, err := strconv.Atoi(response)
+		if err != nil || idx < 1 || idx > len(recent) {
+			fmt.Println("Invalid index. Please try again.")
+			continue
+		}
+		return recent[idx-1]
+	}
+}
+
 // promptFoodPref prompts user for food preferences, validates their
 // response until they've entered a valid response, and returns the
 // valid response.
@@ -799,7 +991,7 @@ func promptUpdateNumServings(existingNumServings float64) float64 {
 	fmt.Printf("Current serving size: %.2f\n", existingNumServings)
 	for {
 		fmt.Printf("Enter new serving size [Press <Enter> to keep]: ")
-		fmt.Scanln(&newNumServings)
+		fmt.Fscanln(Input, &newNumServings)
 
 		// User pressed <Enter>
 		if newNumServings == "" {
@@ -841,19 +1033,45 @@ func UpdateFoodPrefs(tx *sqlx.Tx, pref *FoodPref) error {
 	return err
 }
 
+// addFoodLogColumns adds the log stamp columns to food logs created
+// before they existed.
+func addFoodLogColumns(tx *sqlx.Tx) error {
+	return addColumns(tx, "daily_foods", "logged_at TEXT DEFAULT '' NOT NULL",
+		"utc_offset INTEGER DEFAULT 0 NOT NULL")
+}
+
 // AddFoodEntry inserts a food entry into the database.
 func AddFoodEntry(tx *sqlx.Tx, f *Food, date time.Time) error {
+	if err := addFoodLogColumns(tx); err != nil {
+		return err
+	}
 	const query = `
-	INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price, logged_at, utc_offset)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
+	loggedAt, offset := logStamp()
 	_, err := tx.Exec(query, f.ID, date.Format(dateFormat), date.Format(dateFormatTime),
 		f.ServingSize, f.NumberOfServings, f.Calories, f.FoodMacros.Protein,
-		f.FoodMacros.Fat, f.FoodMacros.Carbs, f.Price)
+		f.FoodMacros.Fat, f.FoodMacros.Carbs, f.Price, loggedAt, offset)
 	// If there was an error executing the query, return the error
 	if err != nil {
 		return fmt.Errorf("couldn't insert food entry: %v", err)
 	}
+	if err := usePantry(tx, f.ID, f.ServingSize*f.NumberOfServings); err != nil {
+		return err
+	}
+	emitFoodLogged(f, 0, f.ServingSize, f.NumberOfServings, date)
+	return nil
+}
+
+// AddFoodEntries inserts a batch of food entries into the database, all
+// logged at the same date and time.
+func AddFoodEntries(tx *sqlx.Tx, foods []Food, date time.Time) error {
+	for i := range foods {
+		if err := AddFoodEntry(tx, &foods[i], date); err != nil {
+			return fmt.Errorf("couldn't add food entry for %q: %v", foods[i].Name, err)
+		}
+	}
 	return nil
 }
 
@@ -1033,6 +1251,65 @@ func updateFoodEntry(tx *sqlx.Tx, entryID int, f Food) error {
 	return nil
 }
 
+// UpdateFoodEntryServings updates the serving size and number of
+// servings of the given food entry. The entry's calories, macros, and
+// price are rescaled to match.
+func UpdateFoodEntryServings(tx *sqlx.Tx, e *DailyFood, servingSize, numServings float64) error {
+	if e.ServingSize <= 0 || e.NumberOfServings <= 0 {
+		return fmt.Errorf("entry %d has no servings to scale from", e.ID)
+	}
+	ratio := (servingSize * numServings) / (e.ServingSize * e.NumberOfServings)
+
+	f := Food{
+		ServingSize:      servingSize,
+		NumberOfServings: numServings,
+		Calories:         e.Calories * ratio,
+		FoodMacros: &FoodMacros{
+			Protein: e.FoodMacros.Protein * ratio,
+			Fat:     e.FoodMacros.Fat * ratio,
+			Carbs:   e.FoodMacros.Carbs * ratio,
+		},
+		Price: e.Price * ratio,
+	}
+	if err := updateFoodEntry(tx, e.ID, f); err != nil {
+		return fmt.Errorf("couldn't update food entry: %v", err)
+	}
+
+	e.ServingSize = f.ServingSize
+	e.NumberOfServings = f.NumberOfServings
+	e.Calories = f.Calories
+	e.FoodMacros = f.FoodMacros
+	e.Price = f.Price
+	return nil
+}
+
+// UpdateFoodEntrySlot moves the given food entry to a meal slot. Since
+// an entry's slot is derived from the time it was logged at, an entry
+// logged outside the slot is moved to the time the slot starts. An
+// entry already logged in the slot keeps its time.
+func UpdateFoodEntrySlot(tx *sqlx.Tx, entryID int, slot MealSlot) error {
+	const query = `
+			UPDATE daily_foods
+			SET time = $1
+			WHERE id = $2
+	`
+	start, ok := SlotTimes[slot]
+	if !ok {
+		return fmt.Errorf("unknown meal slot %q", slot)
+	}
+	e := DailyFood{ID: entryID}
+	if err := tx.Get(&e.Time, `SELECT time FROM daily_foods WHERE id = $1`, entryID); err != nil {
+		return fmt.Errorf("couldn't get food entry time: %v", err)
+	}
+	if e.Slot() == slot {
+		return nil
+	}
+	if _, err := tx.Exec(query, start, entryID); err != nil {
+		return fmt.Errorf("couldn't update food entry time: %v", err)
+	}
+	return nil
+}
+
 // DeleteFoodEntry deletes a logged food entry.
 func DeleteFoodEntry(db *sqlx.DB) error {
 	tx, err := db.Beginx()
@@ -1048,7 +1325,7 @@ func DeleteFoodEntry(db *sqlx.DB) error {
 	}
 
 	// Delete selected entry.
-	if err := deleteOneFoodEntry(tx, entry.ID); err != nil {
+	if err := DeleteOneFoodEntry(tx, entry.ID); err != nil {
 		return err
 	}
 
@@ -1056,8 +1333,8 @@ func DeleteFoodEntry(db *sqlx.DB) error {
 	return tx.Commit()
 }
 
-// deleteOneFoodEntry deletes a logged food entry from the database.
-func deleteOneFoodEntry(tx *sqlx.Tx, entryID int) error {
+// DeleteOneFoodEntry deletes a logged food entry from the database.
+func DeleteOneFoodEntry(tx *sqlx.Tx, entryID int) error {
 	const query = `
 		DELETE FROM daily_foods
 		WHERE id = $1
@@ -1065,25 +1342,16 @@ func deleteOneFoodEntry(tx *sqlx.Tx, entryID int) error {
 	if _, err := tx.Exec(query, entryID); err != nil {
 		return err
 	}
-	return nil
+	return deleteAttachments(tx, "daily_foods", entryID)
 }
 
-// ShowFoodLog fetches and prints entire food log.
-func ShowFoodLog(db *sqlx.DB) error {
-	tx, err := db.Beginx()
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	entries, err := allFoodEntries(tx)
-	if err != nil {
-		return err
-	}
-
+// ShowFoodLog prints a page of the food log, organized by date. Entries
+// are streamed from the database, so printing every entry doesn't load
+// the whole log into memory.
+func ShowFoodLog(db *sqlx.DB, p Page) error {
 	// Print food entries organized by date.
 	var currentDate time.Time
-	for _, entry := range entries {
+	err := eachFoodEntry(db, p, func(entry DailyFood) error {
 		if !entry.Date.Equal(currentDate) {
 			currentDate = entry.Date
 			fmt.Printf("\n%v\n", currentDate.Format(("January 2, 2006")))
@@ -1091,46 +1359,54 @@ func ShowFoodLog(db *sqlx.DB) error {
 		fmt.Printf("- %s: %.1f %s x %.1f serving | %.0f cals |\n",
 			entry.FoodName, entry.ServingSize, entry.ServingUnit,
 			entry.NumberOfServings, entry.Calories)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return tx.Commit()
+	var total int
+	if err := db.Get(&total, `SELECT COUNT(*) FROM daily_foods`); err != nil {
+		return fmt.Errorf("couldn't count food entries: %v", err)
+	}
+	printPageFooter(p, total)
+	return nil
 }
 
-// allFoodEntries retrieves all logged food entries. Ordered by most
-// most recent date.
-func allFoodEntries(tx *sqlx.Tx) ([]DailyFood, error) {
+// eachFoodEntry calls f with each logged food entry of the page, oldest
+// first, as it is read from the database.
+func eachFoodEntry(q sqlx.Queryer, p Page, f func(DailyFood) error) error {
 	// Since DailyFood struct does not currently support time field, the
 	// queury excludes the time field from the selected records.
-	const (
-		query = `
+	const query = `
+		SELECT * FROM (
 			SELECT df.id, df.food_id, df.meal_id, df.date, df.serving_size,
 			df.number_of_servings, df.calories, df.price, f.food_name,
 			f.serving_unit
 			FROM daily_foods df
 			INNER JOIN foods f ON df.food_id = f.food_id
-			ORDER BY df.date ASC
+			ORDER BY df.date DESC, df.id DESC
+			LIMIT $1 OFFSET $2
+		)
+		ORDER BY date ASC, id ASC
 	`
-		macrosQuery = `
-	  	SELECT protein, fat, carbs
-	  	FROM daily_foods
-			WHERE id = $1
-	`
-	)
-
-	var entries []DailyFood
-	if err := tx.Select(&entries, query); err != nil {
-		return nil, fmt.Errorf("couldn't get details: %v", err)
+	limit, offset := p.limit()
+	rows, err := q.Queryx(query, limit, offset)
+	if err != nil {
+		return fmt.Errorf("couldn't get details: %v", err)
 	}
+	defer rows.Close()
 
-	for i, entry := range entries {
-		macros := &FoodMacros{}
-		if err := tx.Get(macros, macrosQuery, entry.ID); err != nil {
-			return nil, fmt.Errorf("couldn't get macros: %v", err)
+	for rows.Next() {
+		var entry DailyFood
+		if err := rows.StructScan(&entry); err != nil {
+			return fmt.Errorf("couldn't read food entry: %v", err)
+		}
+		if err := f(entry); err != nil {
+			return err
 		}
-		entries[i].FoodMacros = macros
 	}
-
-	return entries, nil
+	return rows.Err()
 }
 
 // LogMeal allows the user to create a new meal entry.
@@ -1199,7 +1475,13 @@ func LogMeal(db *sqlx.DB) error {
 	}
 
 	// Get date of meal entry.
-	date := promptDateNotPast("Enter meal entry date")
+	date := promptDateNotPast("Enter meal entry date", FoodLogNow())
+
+	// Get the portion of the meal eaten.
+	portion := promptMealPortion()
+
+	// Get servings left over if the meal was cooked in bulk.
+	leftover := promptLeftoverServings()
 
 	// Log selected meal to the meal log database table. Taking into
 	// account food preferences.
@@ -1208,11 +1490,15 @@ func LogMeal(db *sqlx.DB) error {
 	}
 
 	// Bulk insert the foods that make up the meal into the daily_foods table.
-	err = AddMealFoodEntries(tx, meal.ID, updatedMealFoods, date)
+	err = AddMealFoodEntries(tx, meal.ID, updatedMealFoods, portion, date)
 	if err != nil {
 		return err
 	}
 
+	if err := AddLeftover(tx, meal.ID, date, leftover); err != nil {
+		return err
+	}
+
 	fmt.Println("Successfully added meal entry.")
 	return tx.Commit()
 }
@@ -1342,31 +1628,111 @@ func totalMacros(foods []MealFood) (float64, float64, float64) {
 	return protein, carbs, fats
 }
 
-// SearchMeals searches through meals slice and returns meals that
-// contain the search term.
-func SearchMeals(db *sqlx.DB, response string) ([]Meal, error) {
-	var meals []Meal
+// mealsFTSSchema creates the meals_fts table and the triggers keeping it
+// in sync with the meals, meal_foods, and foods tables, then indexes the
+// meals that already exist, in databases made before it existed.
+const mealsFTSSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS meals_fts
+	USING fts5 (
+		meal_id UNINDEXED, meal_name, ingredients
+	);
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_insert AFTER INSERT ON meals
+	BEGIN
+		INSERT INTO meals_fts (meal_id, meal_name, ingredients)
+		VALUES (new.meal_id, new.meal_name, '');
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_update AFTER UPDATE OF meal_name ON meals
+	BEGIN
+		UPDATE meals_fts SET meal_name = new.meal_name WHERE meal_id = old.meal_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_delete AFTER DELETE ON meals
+	BEGIN
+		DELETE FROM meals_fts WHERE meal_id = old.meal_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_meal_food_insert AFTER INSERT ON meal_foods
+	BEGIN
+		UPDATE meals_fts SET ingredients = (
+			SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+			FROM meal_foods mf
+			JOIN foods f ON f.food_id = mf.food_id
+			WHERE mf.meal_id = new.meal_id
+		) WHERE meal_id = new.meal_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_meal_food_delete AFTER DELETE ON meal_foods
+	BEGIN
+		UPDATE meals_fts SET ingredients = (
+			SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+			FROM meal_foods mf
+			JOIN foods f ON f.food_id = mf.food_id
+			WHERE mf.meal_id = old.meal_id
+		) WHERE meal_id = old.meal_id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS meals_fts_food_update AFTER UPDATE OF food_name ON foods
+	BEGIN
+		UPDATE meals_fts SET ingredients = (
+			SELECT COALESCE(GROUP_CONCAT(f.food_name, ' '), '')
+			FROM meal_foods mf
+			JOIN foods f ON f.food_id = mf.food_id
+			WHERE mf.meal_id = meals_fts.meal_id
+		) WHERE meal_id IN (SELECT meal_id FROM meal_foods WHERE food_id = new.food_id);
+	END;
+
+	INSERT INTO meals_fts (meal_id, meal_name, ingredients)
+	SELECT m.meal_id, m.meal_name, COALESCE((
+		SELECT GROUP_CONCAT(f.food_name, ' ')
+		FROM meal_foods mf
+		JOIN foods f ON f.food_id = mf.food_id
+		WHERE mf.meal_id = m.meal_id
+	), '')
+	FROM meals m
+	WHERE m.meal_id NOT IN (SELECT meal_id FROM meals_fts);
+`
 
-	// Prioritize exact match, then match meals where `meal_name` starts
-	// with the search term, and finally any meals where the `meal_name`
-	// contains the search term.
-	const query = `
-			SELECT * FROM meals
-			WHERE meal_name LIKE $1
-			ORDER BY
-					CASE
-							WHEN meal_name = $2 THEN 1
-							WHEN meal_name LIKE $3 THEN 2
-							ELSE 3
-					END
-			LIMIT $4`
-
-	// Search for meals in the database
-	err := db.Select(&meals, query, "%"+response+"%", response, response+"%", SearchLimit)
+// addMealsFTS creates the meals_fts table if it doesn't exist, along
+// with its triggers, and indexes the existing meals. Until it exists,
+// changes to meals don't need indexing, since every meal is indexed
+// when it is created.
+func addMealsFTS(db *sqlx.DB) error {
+	var n int
+	const query = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'meals_fts'`
+	if err := db.Get(&n, query); err != nil {
+		return fmt.Errorf("couldn't look up meals search table: %v", err)
+	}
+	if n > 0 {
+		return nil
+	}
+	tx, err := db.Beginx()
 	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(mealsFTSSchema); err != nil {
+		return fmt.Errorf("couldn't create meals search table: %v", err)
+	}
+	return tx.Commit()
+}
+
+// SearchMeals searches through all meals and returns meals whose name
+// or ingredients match the search term. Matches are ranked using BM25.
+// Words like "tag:vegan" in the term keep only the meals with the tag.
+func SearchMeals(db *sqlx.DB, term string) ([]Meal, error) {
+	meals := []Meal{}
+	if err := addMealsFTS(db); err != nil {
 		return nil, err
 	}
 
+	// Get all matching meals.
+	query, args := searchQuery("meals", "meals_fts", "meal_id", "meal_name", "meal_tags", term)
+	if err := db.Select(&meals, query, args...); err != nil {
+		return nil, fmt.Errorf("couldn't get result meals: %v", err)
+	}
+
 	for i, _ := range meals {
 		m := &meals[i]
 		mealFoods, err := MealFoodsWithPref(db, m.ID)
@@ -1532,7 +1898,9 @@ WHERE food_id = $1
 	return &f, nil
 }
 
-// printMealDetails prints the foods that make up the meal and their preferences.
+// printMealDetails prints the foods that make up the meal and their
+// preferences, and flags a meal that falls short of the macro target
+// of the current meal slot.
 func printMealDetails(mealFoods []MealFood) {
 	var priceTotal float64
 	for i, mf := range mealFoods {
@@ -1541,6 +1909,9 @@ func printMealDetails(mealFoods []MealFood) {
 		priceTotal += mf.Food.Price
 	}
 	fmt.Printf("Total estimated cost of meal: $%.2f\n", priceTotal)
+
+	protein, carbs, fats := totalMacros(mealFoods)
+	printSlotShortfalls(SlotForTime(Now()), FoodMacros{Protein: protein, Carbs: carbs, Fat: fats})
 }
 
 // printMealFood prints details of a given MealFood object.
@@ -1549,13 +1920,14 @@ func printMealFood(mealFood MealFood) {
 		mealFood.Food.Name, mealFood.ServingSize, mealFood.Food.ServingUnit,
 		mealFood.NumberOfServings, mealFood.Food.Calories, mealFood.Food.Price)
 
-	fmt.Printf("    Macros: | Protein: %-3.2fg | Carbs: %-3.2fg | Fat: %-3.2fg |\n", mealFood.Food.FoodMacros.Protein, mealFood.Food.FoodMacros.Carbs, mealFood.Food.FoodMacros.Fat)
+	fmt.Printf("    Macros: | Protein: %sg | Carbs: %sg | Fat: %sg |\n", FormatMacro(mealFood.Food.FoodMacros.Protein),
+		FormatMacro(mealFood.Food.FoodMacros.Carbs), FormatMacro(mealFood.Food.FoodMacros.Fat))
 }
 
 // promptUserEditDecision prompts the user to select one of foods that
 // make up a meal to edit or <enter> to use existing values.
 func promptUserEditDecision() string {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	fmt.Printf("Enter index of food to edit [Press <Enter> for existing values]: ")
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -1579,10 +1951,21 @@ func AddMealEntry(tx *sqlx.Tx, mealID int, date time.Time) error {
 	return nil
 }
 
-// AddMealFoodEntries bulk inserts foods that make up the meal into the database.
-func AddMealFoodEntries(tx *sqlx.Tx, mealID int, mealFoods []MealFood, date time.Time) error {
+// AddMealFoodEntries bulk inserts foods that make up the meal into the
+// database. The number of servings of every food is multiplied by
+// portion, e.g. 0.5 to log half of the meal.
+func AddMealFoodEntries(tx *sqlx.Tx, mealID int, mealFoods []MealFood, portion float64, date time.Time) error {
+	if portion <= 0 {
+		return fmt.Errorf("portion must be greater than 0, got %v", portion)
+	}
+
+	if err := addFoodLogColumns(tx); err != nil {
+		return err
+	}
+	loggedAt, offset := logStamp()
+
 	// Prepare a statement for bulk insert
-	stmt, err := tx.Preparex("INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)")
+	stmt, err := tx.Preparex("INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price, logged_at, utc_offset) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)")
 	if err != nil {
 		return err
 	}
@@ -1590,18 +1973,90 @@ func AddMealFoodEntries(tx *sqlx.Tx, mealID int, mealFoods []MealFood, date time
 
 	// Iterate over each food and insert into the database
 	for _, mf := range mealFoods {
+		if portion != 1 {
+			mf = scaleMealFood(mf, portion)
+		}
 		_, err = stmt.Exec(mf.Food.ID, mealID, date.Format(dateFormat),
 			date.Format(dateFormatTime), mf.ServingSize, mf.NumberOfServings,
 			mf.Food.Calories, mf.Food.FoodMacros.Protein, mf.Food.FoodMacros.Fat,
-			mf.Food.FoodMacros.Carbs, mf.Food.Price)
+			mf.Food.FoodMacros.Carbs, mf.Food.Price, loggedAt, offset)
 		if err != nil {
 			return fmt.Errorf("couldn't insert bulk meal foods: %v", err)
 		}
+		if err := usePantry(tx, mf.Food.ID, mf.ServingSize*mf.NumberOfServings); err != nil {
+			return err
+		}
+		emitFoodLogged(&mf.Food, mealID, mf.ServingSize, mf.NumberOfServings, date)
 	}
 
 	return err
 }
 
+// ParsePortion parses the portion of a meal eaten, written as a
+// multiplier such as "0.5" or "0.5x", or a fraction such as "1/2".
+func ParsePortion(s string) (float64, error) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x")
+	var p float64
+	var err error
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		var n, d float64
+		n, err = strconv.ParseFloat(strings.TrimSpace(num), 64)
+		if err == nil {
+			d, err = strconv.ParseFloat(strings.TrimSpace(den), 64)
+		}
+		if err == nil && d == 0 {
+			err = errors.New("division by zero")
+		}
+		if err == nil {
+			p = n / d
+		}
+	} else {
+		p, err = strconv.ParseFloat(s, 64)
+	}
+	if err != nil || p <= 0 || math.IsInf(p, 0) || math.IsNaN(p) {
+		return 0, fmt.Errorf("invalid portion %q: want a number greater than 0, such as 0.5 or 1/2", s)
+	}
+	return p, nil
+}
+
+// promptMealPortion prompts the user for the portion of the meal eaten,
+// validates their response, and returns the valid portion.
+func promptMealPortion() float64 {
+	reader := Input
+	for {
+		fmt.Printf("Enter portion of the meal eaten, e.g. 0.5 [Press <Enter> for all of it]: ")
+		r, err := reader.ReadString('\n')
+		if err != nil {
+			return 1
+		}
+		if strings.TrimSpace(r) == "" {
+			return 1
+		}
+		p, err := ParsePortion(r)
+		if err != nil {
+			fmt.Println("Invalid portion. Please try again.")
+			continue
+		}
+		return p
+	}
+}
+
+// scaleMealFood returns a copy of the meal food with its number of
+// servings, calories, macros, and price multiplied by r.
+func scaleMealFood(mf MealFood, r float64) MealFood {
+	mf.NumberOfServings *= r
+	mf.Food.Calories *= r
+	mf.Food.Price *= r
+	if mf.Food.FoodMacros != nil {
+		m := *mf.Food.FoodMacros
+		m.Protein *= r
+		m.Fat *= r
+		m.Carbs *= r
+		mf.Food.FoodMacros = &m
+	}
+	return mf
+}
+
 // FoodLogSummary fetches and prints a food log summary.
 func FoodLogSummary(db *sqlx.DB) error {
 	tx, err := db.Beginx()
@@ -1630,6 +2085,14 @@ func FoodLogSummary(db *sqlx.DB) error {
 		fmt.Printf("- %s: eaten %d times\n", food.FoodName, food.Count)
 	}
 
+	// Print how often each tag was eaten.
+	tags, err := TagCloud(tx, time.Time{}, LogNow().AddDate(1, 0, 0))
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	printTagCloud(tags)
+
 	return tx.Commit()
 }
 
@@ -1668,7 +2131,8 @@ func frequentFoods(tx *sqlx.Tx, limit int) ([]DailyFoodCount, error) {
 }
 
 // FoodLogSummaryDay prints the current nutritional totals for a given
-// day and provides insight on progress towards nutritional goals.
+// day and provides insight on progress towards nutritional goals. The
+// user's "day" template, if any, controls what is printed.
 func FoodLogSummaryDay(db *sqlx.DB, u *UserInfo) error {
 	tx, err := db.Beginx()
 	if err != nil {
@@ -1677,63 +2141,68 @@ func FoodLogSummaryDay(db *sqlx.DB, u *UserInfo) error {
 	defer tx.Rollback()
 
 	// Get the food entries for the present day.
-	entries, err := foodEntriesForDate(tx, time.Now())
+	now := FoodLogNow()
+	entries, err := FoodEntriesForDate(tx, now)
 	if err != nil {
 		return err
 	}
 
 	// If there are zero entries for today, then return early.
 	if len(entries) == 0 {
-		fmt.Println("No foods logged for today.")
+		fmt.Println(tr("No foods logged for today."))
 		return nil
 	}
 
-	var calorieTotal float64
-	var proteinTotal float64
-	var fatTotal float64
-	var carbTotal float64
-	var priceTotal float64
-
-	// Calculate nutritional totals.
+	r := &DayReport{Date: dateOf(now), Foods: entries}
 	for _, entry := range entries {
-		calorieTotal += entry.Calories
-		proteinTotal += entry.FoodMacros.Protein
-		fatTotal += entry.FoodMacros.Fat
-		carbTotal += entry.FoodMacros.Carbs
-		priceTotal += entry.Price
+		r.Calories += entry.Calories
+		r.Protein += entry.FoodMacros.Protein
+		r.Fat += entry.FoodMacros.Fat
+		r.Carbs += entry.FoodMacros.Carbs
+		r.Price += entry.Price
 	}
 
 	// Get nutritional goals.
-	calorieGoal := u.Phase.GoalCalories
-	if u.Phase.Status != "active" {
-		calorieGoal = u.TDEE
+	if r.Goal.Calories, err = dayGoal(tx, u, r.Date); err != nil {
+		return err
+	}
+	r.Goal.Protein = u.Macros.Protein
+	r.Goal.Fat = u.Macros.Fats
+	r.Goal.Carbs = u.Macros.Carbs
+	if r.CarbSplit, err = carbSplitOn(tx, r.Date, r.Goal.Calories); err != nil {
+		return err
+	}
+	if r.Electrolytes, err = electrolytesOn(tx, r.Date); err != nil {
+		return err
+	}
+	if r.GlycemicLoad, err = glycemicLoadOn(tx, r.Date, r.Carbs); err != nil {
+		return err
+	}
+	if r.Caffeine, err = caffeineOn(tx, r.Date); err != nil {
+		return err
+	}
+	if r.Budget, err = weekBudget(tx, now); err != nil {
+		return err
 	}
-	proteinGoal := u.Macros.Protein
-	fatGoal := u.Macros.Fats
-	carbGoal := u.Macros.Carbs
 
-	printNutrientProgress(proteinTotal, proteinGoal, "Protein")
-	printNutrientProgress(fatTotal, fatGoal, "Fat")
-	printNutrientProgress(carbTotal, carbGoal, "Carbs")
-	printCalorieProgress(calorieTotal, calorieGoal, "Calories")
-	fmt.Printf("\n%.2f calories remaining.\n", calorieGoal-calorieTotal)
-	fmt.Printf("Eaten $%.2f worth of food today.\n", priceTotal)
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		return err
+	}
 
 	return tx.Commit()
 }
 
-// foodEntriesForDate retrieves the food entries for a given date.
-func foodEntriesForDate(tx *sqlx.Tx, date time.Time) ([]DailyFood, error) {
+// FoodEntriesForDate retrieves the food entries for a given date.
+// Ordered by the time they were logged at.
+func FoodEntriesForDate(tx *sqlx.Tx, date time.Time) ([]DailyFood, error) {
 	const (
-		// Since DailyFood struct does not currently support time field, the
-		// queury excludes the time field from the selected records.
 		query = `
-      SELECT df.id, df.food_id, df.meal_id, df.date, df.serving_size,
+      SELECT df.id, df.food_id, df.meal_id, df.date, df.time, df.serving_size,
 	      df.number_of_servings, df.calories, df.price, f.food_name, f.serving_unit
       FROM daily_foods df
       INNER JOIN foods f ON df.food_id = f.food_id
 	    WHERE date = $1
-      ORDER BY df.date DESC
+      ORDER BY df.time ASC, df.id ASC
     `
 		macrosQuery = `
       SELECT protein, fat, carbs
@@ -1759,20 +2228,6 @@ func foodEntriesForDate(tx *sqlx.Tx, date time.Time) ([]DailyFood, error) {
 	return entries, nil
 }
 
-// printNutrientProgress prints the nutrient progress.
-func printNutrientProgress(current, goal float64, name string) {
-	progressBar := renderProgressBar(current, goal)
-	fmt.Printf("%-9s %s %3.0f%% (%.0fg / %.0fg)\n", name+":", progressBar,
-		current*100/goal, current, goal)
-}
-
-// printCalorieProgress prints the calories progress.
-func printCalorieProgress(current, goal float64, name string) {
-	progressBar := renderProgressBar(current, goal)
-	fmt.Printf("%-9s %s %3.0f%% (%.0f / %.0f)\n", name+":", progressBar,
-		current*100/goal, current, goal)
-}
-
 // renderProgressBar renders an ASCII progress bar.
 func renderProgressBar(current, goal float64) string {
 	const barLength = 10
@@ -1802,7 +2257,7 @@ func renderProgressBar(current, goal float64) string {
 // * Diet phase activity has been checked. That is, this function should
 // not be called for a diet phase that is not currently active.
 func ValidLog(u *UserInfo, entries *[]Entry) *[]Entry {
-	today := time.Now()
+	today := LogNow()
 
 	var subset []Entry
 	for _, entry := range *entries {
@@ -1,174 +1,84 @@
 package bite
 
 import (
+	"bufio"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/ericstrs/bite/internal/dbtest"
 )
 
 func ExampleGetAllEntries() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
-
-	defer db.Close()
-
-	// Create the food_nutrient_derivation table
-	db.MustExec(`CREATE TABLE food_nutrient_derivation (
-  id INT PRIMARY KEY,
-  code VARCHAR(255) NOT NULL,
-  description TEXT NOT NULL
-	)`)
-
-	// Insert a derivation code and description
-	db.MustExec(`INSERT INTO food_nutrient_derivation (code, description) VALUES (71, "portion size")`)
-
-	// Create the foods table
-	db.MustExec(` CREATE TABLE IF NOT EXISTS foods (
-  food_id INTEGER PRIMARY KEY,
-  food_name TEXT NOT NULL,
-  serving_size REAL NOT NULL,
-  serving_unit TEXT NOT NULL,
-  household_serving TEXT NOT NULL
-	)`)
-
-	// Insert foods
-	db.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
-	(1, 'Chicken Breast', 100, 'g', '1/2 piece'),
-	(2, 'Broccoli', 156, 'g', '1 cup'),
-	(3, 'Brown Rice', 100, 'g', '1/2 cup cooked'),
-	(4, 'Pizza', 124, 'g', '1 slice'),
-	(5, 'Taco', 170, 'g', '1 taco')
-	`)
-
-	// Create the nutrients table
-	db.MustExec(`CREATE TABLE IF NOT EXISTS nutrients (
-  nutrient_id INTEGER PRIMARY KEY,
-  nutrient_name TEXT NOT NULL,
-  unit_name TEXT NOT NULL
-	)`)
-
-	// Insert nutrients
-	db.MustExec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-	(1003, 'Protein', 'G'),
-	(1004, 'Total lipid (fat)', 'G'),
-	(1005, 'Carbohydrate, by difference', 'G'),
-	(1008, 'Energy, KCAL', 'G')
-	`)
-
-	// Create the food_nutrients table
-	db.MustExec(`CREATE TABLE food_nutrients (
-  id INTEGER PRIMARY KEY,
-  food_id INTEGER NOT NULL,
-  nutrient_id INTEGER NOT NULL,
-  amount REAL NOT NULL,
-  derivation_id REAL NOT NULL,
-  FOREIGN KEY (food_id) REFERENCES foods(food_id),
-  FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-  FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-	)`)
-
-	// Insert food_nutrients
-	db.MustExec(`INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
-	(1, 1003, 31, 71),
-	(1, 1004, 3.6, 71),
-	(1, 1005, 0, 71),
-	(1, 1008, 165, 71),
-	(2, 1003, 2.8, 71),
-	(2, 1004, 0.4, 71),
-	(2, 1005, 7, 71),
-	(2, 1008, 34, 71),
-	(3, 1003, 2.73, 71),
-	(3, 1004, 0.96, 71),
-	(3, 1005, 25.5, 71),
-	(3, 1008, 122, 71),
-	(4, 1003, 11, 71),
-	(4, 1004, 10, 71),
-	(4, 1005, 33, 71),
-	(4, 1008, 266, 71),
-	(5, 1003, 12, 71),
-	(5, 1004, 12, 71),
-	(5, 1005, 15, 71),
-	(5, 1008, 216, 71)
-	`)
-
-	// Create the daily_foods table
-	db.MustExec(`CREATE TABLE daily_foods (
-  id INTEGER PRIMARY KEY,
-	food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_ID INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  number_of_servings REAL DEFAULT 1 NOT NULL,
-	calories REAL NOT NULL,
-  protein REAL NOT NULL,
-  fat REAL NOT NULL,
-  carbs REAL NOT NULL
-	)`)
-
-	// Note: 5th day user did not log any foods.
-	db.MustExec(`INSERT INTO daily_foods (food_id, date, time, number_of_servings, calories, protein, fat, carbs) VALUES
-		(1, '2023-01-01', '00:00:00', 1, 165, 31, 3.6, 0),
-		(2, '2023-01-01', '00:00:00', 1, 34, 2.8, 0.4, 7),
-		(2, '2023-01-02', '00:00:00', 1, 34, 2.8, 0.4, 7),
-		(4, '2023-01-02', '00:00:00', 1, 266, 11, 10, 33),
-		(5, '2023-01-03', '00:00:00', 1, 216, 12, 12, 15),
-		(1, '2023-01-03', '00:00:00', 1, 165, 31, 3.6, 0),
-		(3, '2023-01-04', '00:00:00', 1, 122, 2.73, 0.96, 25.5),
-		(4, '2023-01-04', '00:00:00', 1, 266, 11, 10, 33)
-	`)
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
 
-	// Create the daily_weights table
-	db.MustExec(`CREATE TABLE daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  weight REAL NOT NULL
-	)`)
-
-	db.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES
-	('2023-01-01', "00:00:00", 180),
-	('2023-01-02', "00:00:00", 181),
-	('2023-01-03', "00:00:00", 182),
-	('2023-01-04', "00:00:00", 183),
-	('2023-01-05', "00:00:00", 184)
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Chicken Breast', 100, 'g', '1/2 piece'),
+			(2, 'Broccoli', 156, 'g', '1 cup'),
+			(3, 'Brown Rice', 100, 'g', '1/2 cup cooked'),
+			(4, 'Pizza', 124, 'g', '1 slice'),
+			(5, 'Taco', 170, 'g', '1 taco');
+
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1003, 'Protein', 'G'),
+			(1004, 'Total lipid (fat)', 'G'),
+			(1005, 'Carbohydrate, by difference', 'G'),
+			(1008, 'Energy, KCAL', 'G');
+
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1003, 31, 71),
+			(1, 1004, 3.6, 71),
+			(1, 1005, 0, 71),
+			(1, 1008, 165, 71),
+			(2, 1003, 2.8, 71),
+			(2, 1004, 0.4, 71),
+			(2, 1005, 7, 71),
+			(2, 1008, 34, 71),
+			(3, 1003, 2.73, 71),
+			(3, 1004, 0.96, 71),
+			(3, 1005, 25.5, 71),
+			(3, 1008, 122, 71),
+			(4, 1003, 11, 71),
+			(4, 1004, 10, 71),
+			(4, 1005, 33, 71),
+			(4, 1008, 266, 71),
+			(5, 1003, 12, 71),
+			(5, 1004, 12, 71),
+			(5, 1005, 15, 71),
+			(5, 1008, 216, 71);
+
+		-- Note: 5th day user did not log any foods.
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, '2023-01-01', '00:00:00', 100, 1, 165, 31, 3.6, 0),
+			(2, '2023-01-01', '00:00:00', 156, 1, 34, 2.8, 0.4, 7),
+			(2, '2023-01-02', '00:00:00', 156, 1, 34, 2.8, 0.4, 7),
+			(4, '2023-01-02', '00:00:00', 124, 1, 266, 11, 10, 33),
+			(5, '2023-01-03', '00:00:00', 170, 1, 216, 12, 12, 15),
+			(1, '2023-01-03', '00:00:00', 100, 1, 165, 31, 3.6, 0),
+			(3, '2023-01-04', '00:00:00', 100, 1, 122, 2.73, 0.96, 25.5),
+			(4, '2023-01-04', '00:00:00', 124, 1, 266, 11, 10, 33);
+
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2023-01-01', '00:00:00', 180),
+			('2023-01-02', '00:00:00', 181),
+			('2023-01-03', '00:00:00', 182),
+			('2023-01-04', '00:00:00', 183),
+			('2023-01-05', '00:00:00', 184);
+
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Chicken dinner');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1);
 	`)
-
-	db.MustExec(`CREATE TABLE IF NOT EXISTS daily_meals (
-  id INTEGER PRIMARY KEY,
-  meal_id INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-	time TIME NOT NULL
-	)`)
-
-	db.MustExec(`CREATE TABLE IF NOT EXISTS food_prefs (
-  food_id INTEGER PRIMARY KEY,
-  serving_size REAL,
-  number_of_servings REAL DEFAULT 1 NOT NULL,
-  FOREIGN KEY(food_id) REFERENCES foods(food_id)
-	)`)
-
-	db.MustExec(`CREATE TABLE IF NOT EXISTS meal_food_prefs (
-  meal_id INTEGER,
-  food_id INTEGER,
-  serving_size REAL,
-  number_of_servings REAL DEFAULT 1 NOT NULL,
-  PRIMARY KEY(meal_id, food_id),
-  FOREIGN KEY(food_id) REFERENCES foods(food_id),
-  FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-	)`)
+	defer db.Close()
 
 	// This tests to ensure that entering food and meal food preference
 	// only affect future food logging. That is, the inserts below should
 	// not change the output values.
-	_, err = db.Exec(`
-				INSERT INTO food_prefs VALUES (1, 160, 1);
-		  	INSERT INTO meal_food_prefs VALUES (1, 1, 180, 2);
-			`)
+	db.MustExec(`
+		INSERT INTO food_prefs VALUES (1, 160, 1);
+		INSERT INTO meal_food_prefs VALUES (1, 1, 180, 2);
+	`)
 
 	// Get all entries
 	entries, err := AllEntries(db)
@@ -198,11 +108,7 @@ func ExampleGetAllEntries() {
 }
 
 func ExampleAddWeightEntry() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -213,13 +119,6 @@ func ExampleAddWeightEntry() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	tx.MustExec(`CREATE TABLE IF NOT EXISTS daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  weight REAL NOT NULL
-)`)
-
 	testWeight := 220.2
 	date := time.Now()
 
@@ -245,11 +144,7 @@ func ExampleAddWeightEntry() {
 }
 
 func ExampleAddWeightEntry_exists() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -260,13 +155,6 @@ func ExampleAddWeightEntry_exists() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	tx.MustExec(`CREATE TABLE IF NOT EXISTS daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-  weight REAL NOT NULL,
-	time TIME NOT NULL
-)`)
-
 	testWeight := 220.2
 	date := time.Now()
 
@@ -282,11 +170,7 @@ func ExampleAddWeightEntry_exists() {
 }
 
 func ExampleCheckWeightExists() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -297,13 +181,6 @@ func ExampleCheckWeightExists() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	tx.MustExec(`CREATE TABLE IF NOT EXISTS daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-  weight REAL NOT NULL,
-	time TIME NOT NULL
-)`)
-
 	testWeight := 220.2
 	date := time.Now()
 
@@ -320,20 +197,9 @@ func ExampleCheckWeightExists() {
 }
 
 func ExampleUpdateWeightEntry() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
-	db.MustExec(`CREATE TABLE IF NOT EXISTS daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  weight REAL NOT NULL
-)`)
-
 	testWeight := 220.2
 	date := time.Now()
 
@@ -342,7 +208,7 @@ func ExampleUpdateWeightEntry() {
 
 	newWeight := 225.2
 
-	err = updateWeightEntry(db, 1, newWeight)
+	err := updateWeightEntry(db, 1, newWeight)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -361,27 +227,16 @@ func ExampleUpdateWeightEntry() {
 }
 
 func ExampleDeleteOneWeightEntry() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
-	db.MustExec(`CREATE TABLE IF NOT EXISTS daily_weights (
-  id INTEGER PRIMARY KEY,
-  date DATE NOT NULL,
-  weight REAL NOT NULL,
-	time TIME NOT NULL
-)`)
-
 	testWeight := 220.2
 	date := time.Now()
 
 	// Insert a weight for date.
 	db.Exec(`INSERT INTO daily_weights (date, time, weight) VALUES ($1, $2, $3)`, date.Format(dateFormat), date.Format(dateFormatTime), testWeight)
 
-	err = deleteOneWeightEntry(db, 1)
+	err := deleteOneWeightEntry(db, 1)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -399,11 +254,7 @@ func ExampleDeleteOneWeightEntry() {
 }
 
 func ExampleUpdateFoodEntry() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -414,36 +265,11 @@ func ExampleUpdateFoodEntry() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the foods table
-	tx.MustExec(` CREATE TABLE IF NOT EXISTS foods (
-  food_id INTEGER PRIMARY KEY,
-  food_name TEXT NOT NULL,
-  serving_size REAL NOT NULL,
-  serving_unit TEXT NOT NULL,
-  household_serving TEXT NOT NULL
-	)`)
-
 	// Insert foods
 	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
 	(1, 'Chicken Breast', 100, 'g', '1/2 piece')
 	`)
 
-	// Create daily foods table
-	tx.MustExec(`CREATE TABLE daily_foods (
-  id INTEGER PRIMARY KEY,
-  food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_id INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  serving_size REAL NOT NULL,
-  number_of_servings REAL DEFAULT 1 NOT NULL,
-	calories REAL NOT NULL,
-  protein REAL NOT NULL,
-  fat REAL NOT NULL,
-  carbs REAL NOT NULL,
-	price REAL DEFAULT 0
-)`)
-
 	// Insert daily food entry.
 	tx.MustExec(`INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
 (1, "2023-01-01", "00:00:00", 100, 1, 56, 5, 4, 5)
@@ -484,12 +310,8 @@ func ExampleUpdateFoodEntry() {
 	// <nil>
 }
 
-func ExampleGetRecentFoodEntries() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+func ExampleAddFoodEntries() {
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -500,246 +322,221 @@ func ExampleGetRecentFoodEntries() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	_, err = tx.Exec(`
-	CREATE TABLE IF NOT EXISTS foods (
-		food_id INTEGER PRIMARY KEY,
-		food_name TEXT NOT NULL,
-		serving_size REAL NOT NULL,
-		serving_unit TEXT NOT NULL,
-		household_serving TEXT NOT NULL,
-		brand_name TEXT,
-		cost REAL
-	);
-
-	CREATE TABLE IF NOT EXISTS daily_foods (
-		id INTEGER PRIMARY KEY,
-		food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-		meal_id INTEGER REFERENCES meals(meal_id),
-		date DATE NOT NULL,
-		time TIME NOT NULL,
-		serving_size REAL NOT NULL,
-		number_of_servings REAL DEFAULT 1 NOT NULL,
-		calories REAL NOT NULL,
-		protein REAL NOT NULL,
-		fat REAL NOT NULL,
-		carbs REAL NOT NULL
-	);
-	`)
-
-	if err != nil {
-		fmt.Printf("Failed to build tables: %v\n", err)
-		return
+	foods := []Food{
+		{
+			ID:               1,
+			Name:             "Chicken Breast",
+			ServingSize:      100,
+			NumberOfServings: 2,
+			Calories:         330,
+			FoodMacros:       &FoodMacros{Protein: 62, Fat: 7.2},
+		},
+		{
+			ID:               2,
+			Name:             "Rice",
+			ServingSize:      150,
+			NumberOfServings: 1,
+			Calories:         195,
+			FoodMacros:       &FoodMacros{Protein: 4, Fat: 0.4, Carbs: 42},
+		},
 	}
 
-	_, err = tx.Exec(`
-	INSERT INTO foods VALUES (1, 'Apple', 100, 'g', '1 medium', NULL, NULL);
-	INSERT INTO foods VALUES (2, 'Bread', 100, 'g', '1 medium', NULL, NULL);
-	INSERT INTO foods VALUES (3, 'Tomato', 100, 'g', '1 medium', NULL, NULL);
-	INSERT INTO daily_foods VALUES (1, 1, NULL, '2023-01-01', '00:00:00', 42, 1, 50, 5, 5, 5);
-	INSERT INTO daily_foods VALUES (2, 1, NULL, '2023-01-01', '00:00:00', 42, 1, 50, 5, 5, 5);
-	INSERT INTO daily_foods VALUES (3, 2, NULL, '2023-01-02', '00:00:00', 42, 1, 50, 5, 5, 5);
-	`)
-	if err != nil {
-		fmt.Println("Failed to insert data:", err)
+	date := time.Date(2023, time.January, 1, 12, 30, 0, 0, time.UTC)
+	if err := AddFoodEntries(tx, foods, date); err != nil {
+		log.Println(err)
 		return
 	}
 
-	dailyFoods, err := recentFoodEntries(tx, 10)
+	tx.Commit()
+
+	// Verify both entries were logged at the same date and time.
+	var entries []struct {
+		FoodID   int     `db:"food_id"`
+		LoggedAt string  `db:"logged_at"`
+		Calories float64 `db:"calories"`
+	}
+	err = db.Select(&entries, `SELECT food_id, date || ' ' || time AS logged_at, calories FROM daily_foods`)
 	if err != nil {
-		fmt.Println(err)
+		log.Println(err)
 		return
 	}
 
-	for _, food := range dailyFoods {
-		fmt.Println(food.FoodName)
+	for _, e := range entries {
+		fmt.Println(e.FoodID, e.LoggedAt, e.Calories)
 	}
 
 	// Output:
-	// Bread
-	// Apple
+	// 1 2023-01-01 12:30:00 330
+	// 2 2023-01-01 12:30:00 195
 }
 
-func ExampleGetMealsWithRecentFirst() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+func ExampleUpdateFoodEntryServings() {
+	db := dbtest.MustNew()
 	defer db.Close()
 
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS foods (
-			food_id INTEGER PRIMARY KEY,
-			food_name TEXT NOT NULL,
-			serving_size REAL NOT NULL,
-			serving_unit TEXT NOT NULL,
-			household_serving TEXT NOT NULL,
-			brand_name TEXT DEFAULT '',
-			cost REAL DEFAULT 0
-		);
-
-    CREATE TABLE IF NOT EXISTS meals (
-        meal_id INTEGER PRIMARY KEY,
-        meal_name TEXT NOT NULL
-    );
-
-    CREATE TABLE IF NOT EXISTS daily_meals (
-      id INTEGER PRIMARY KEY,
-      meal_id INTEGER REFERENCES meals(meal_id),
-      date DATE NOT NULL,
-      time TIME NOT NULL
-    );
-
-		CREATE TABLE IF NOT EXISTS meal_foods (
-  		meal_id INTEGER REFERENCES meals(meal_id),
-  		food_id INTEGER REFERENCES foods(food_id),
-  		PRIMARY KEY (meal_id, food_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS meal_food_prefs (
-			meal_id INTEGER,
-			food_id INTEGER,
-			serving_size REAL,
-			number_of_servings REAL DEFAULT 1 NOT NULL,
-			PRIMARY KEY(meal_id, food_id),
-			FOREIGN KEY(food_id) REFERENCES foods(food_id),
-			FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS food_prefs (
-			food_id INTEGER PRIMARY KEY,
-			serving_size REAL,
-			number_of_servings REAL DEFAULT 1 NOT NULL,
-			FOREIGN KEY(food_id) REFERENCES foods(food_id)
-		);
-
-  CREATE TABLE food_nutrient_derivation (
-  id INT PRIMARY KEY,
-  code VARCHAR(255) NOT NULL,
-  description TEXT NOT NULL
-  );
-
-		CREATE TABLE IF NOT EXISTS food_nutrients (
-			id INTEGER PRIMARY KEY,
-			food_id INTEGER NOT NULL,
-			nutrient_id INTEGER NOT NULL,
-			amount REAL NOT NULL,
-			derivation_id REAL NOT NULL,
-			FOREIGN KEY (food_id) REFERENCES foods(food_id),
-			FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-			FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-		);
-
-		CREATE TABLE IF NOT EXISTS nutrients (
-			nutrient_id INTEGER PRIMARY KEY,
-			nutrient_name TEXT NOT NULL,
-			unit_name TEXT NOT NULL
-		);
-
-  `)
+	// Start a new transaction
+	tx, err := db.Beginx()
 	if err != nil {
-		fmt.Printf("Failed to setup tables: %v\n", err)
 		return
 	}
+	// If anything goes wrong, rollback the transaction
+	defer tx.Rollback()
 
-	// Insert a derivation code and description
-	_, err = db.Exec(`INSERT INTO food_nutrient_derivation (code, description) VALUES (71, "portion size")`)
+	tx.MustExec(`INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price) VALUES
+(1, '2023-01-01', '08:15:00', 40, 1, 150, 5, 3, 27, 0.2)
+	`)
+
+	date := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entries, err := FoodEntriesForDate(tx, date)
 	if err != nil {
-		fmt.Printf("Failed to insert food nutrient derivation: %v\n", err)
+		log.Println(err)
 		return
 	}
+	e := entries[0]
+	fmt.Printf("%s %s: %.0f cals\n", e.Slot(), e.FoodName, e.Calories)
 
-	// Insert nutrients
-	_, err = db.Exec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-  (1003, 'Protein', 'G'),
-  (1004, 'Total lipid (fat)', 'G'),
-  (1005, 'Carbohydrate, by difference', 'G'),
-  (1008, 'Energy', 'KCAL')
-  `)
-	if err != nil {
-		fmt.Printf("Failed to insert nutrients: %v\n", err)
+	if err := UpdateFoodEntryServings(tx, &e, 40, 2); err != nil {
+		log.Println(err)
 		return
 	}
-
-	_, err = db.Exec(`
-	INSERT INTO meals VALUES
-	(1, 'Pie'),
-	(2, 'Shake'),
-	(3, 'Pizza')
-	`)
-	if err != nil {
-		fmt.Printf("Failed to insert data into the meals table: %v\n", err)
+	if err := UpdateFoodEntrySlot(tx, e.ID, Snack); err != nil {
+		log.Println(err)
 		return
 	}
 
-	_, err = db.Exec(`
-	INSERT INTO meal_foods VALUES (1, 1);
-	INSERT INTO meal_foods VALUES (2, 2);
-	INSERT INTO meal_foods VALUES (3, 3);
-	`)
+	entries, err = FoodEntriesForDate(tx, date)
 	if err != nil {
-		fmt.Printf("Failed to insert foods: %v\n", err)
+		log.Println(err)
 		return
 	}
+	e = entries[0]
+	fmt.Printf("%s %s: %.0f cals, %.0fg protein, $%.2f\n", e.Slot(), e.FoodName,
+		e.Calories, e.FoodMacros.Protein, e.Price)
+
+	// Output:
+	// breakfast Oats: 150 cals
+	// snack Oats: 300 cals, 10g protein, $0.40
+}
 
-	_, err = db.Exec(`
-	INSERT INTO foods VALUES (1, 'Apple', 100, 'g', '1 medium', '', 0);
-	INSERT INTO foods VALUES (2, 'Milk', 100, 'g', '', '', 0);
-	INSERT INTO foods VALUES (3, 'Tomato', 100, 'g', '', '', 0);
+func ExampleUpdateFoodEntrySlot() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES
+			(1, '2024-01-01', '08:15:00', 40, 1, 150, 5, 3, 27),
+			(3, '2024-01-01', '16:40:00', 118, 1, 105, 1, 0, 27);
 	`)
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	// The oats move to lunch, and the banana, already logged at snack
+	// time, stays at the time it was eaten.
+	for id, slot := range map[int]MealSlot{1: Lunch, 2: Snack} {
+		if err := UpdateFoodEntrySlot(tx, id, slot); err != nil {
+			log.Fatal(err)
+		}
+	}
+	entries, err := FoodEntriesForDate(tx, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
 	if err != nil {
-		fmt.Printf("Failed to insert foods: %v\n", err)
-		return
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		fmt.Println(e.FoodName, e.Time, e.Slot())
 	}
+	// Output:
+	// Oats 11:00:00 lunch
+	// Banana 16:40:00 snack
+}
 
-	_, err = db.Exec(`
-  INSERT INTO food_prefs VALUES (1, 100, 1);
-  INSERT INTO food_prefs VALUES (2, 100, 1);
-  INSERT INTO food_prefs VALUES (3, 100, 1);
+func ExampleGetRecentFoodEntries() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Apple', 100, 'g', '1 medium'),
+			(2, 'Bread', 100, 'g', '1 medium'),
+			(3, 'Tomato', 100, 'g', '1 medium');
+		INSERT INTO daily_foods (id, food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, 1, '2023-01-01', '00:00:00', 42, 1, 50, 5, 5, 5),
+			(2, 1, '2023-01-01', '00:00:00', 42, 1, 50, 5, 5, 5),
+			(3, 2, '2023-01-02', '00:00:00', 42, 1, 50, 5, 5, 5);
 	`)
+	defer db.Close()
+
+	// Start a new transaction
+	tx, err := db.Beginx()
 	if err != nil {
-		fmt.Printf("Failed to insert food preferences: %v\n", err)
 		return
 	}
+	// If anything goes wrong, rollback the transaction
+	defer tx.Rollback()
 
-	_, err = db.Exec(`
-  INSERT INTO meal_food_prefs VALUES (1, 1, 100, 1);
-  INSERT INTO meal_food_prefs VALUES (2, 2, 100, 1);
-  INSERT INTO meal_food_prefs VALUES (3, 3, 120, 1);
-	`)
+	dailyFoods, err := recentFoodEntries(tx, 10)
 	if err != nil {
-		fmt.Printf("Failed to insert meal foods prefs: %v\n", err)
+		fmt.Println(err)
 		return
 	}
 
-	_, err = db.Exec(`
-	INSERT INTO daily_meals VALUES (1, 3, '2023-01-01', '00:00:00:');
-	INSERT INTO daily_meals VALUES (2, 3, '2023-01-01', '00:00:00:');
-	INSERT INTO daily_meals VALUES (3, 2, '2023-01-01', '00:00:00:');
-	`)
-	if err != nil {
-		fmt.Printf("Failed to insert data into the daily meals table: %v\n", err)
-	}
-
-	_, err = db.Exec(`
-  INSERT INTO food_nutrients VALUES (1, 1, 1003, 0.3, 71);  -- 0.3g Protein
-  INSERT INTO food_nutrients VALUES (2, 1, 1004, 0.2, 71);  -- 0.2g Fat
-  INSERT INTO food_nutrients VALUES (3, 1, 1005, 12, 71);   -- 12g Carbohydrates
-  INSERT INTO food_nutrients VALUES (4, 1, 1008, 52, 71);   -- 52KCAL Energy
-  INSERT INTO food_nutrients VALUES (5, 2, 1003, 0.3, 71);  -- 0.3g Protein
-  INSERT INTO food_nutrients VALUES (6, 2, 1004, 0.2, 71);  -- 0.2g Fat
-  INSERT INTO food_nutrients VALUES (7, 2, 1005, 12, 71);   -- 12g Carbohydrates
-  INSERT INTO food_nutrients VALUES (8, 2, 1008, 52, 71);   -- 52KCAL Energy
-  INSERT INTO food_nutrients VALUES (9, 3, 1003, 0.3, 71);  -- 0.3g Protein
-  INSERT INTO food_nutrients VALUES (10, 3, 1004, 0.2, 71);  -- 0.2g Fat
-  INSERT INTO food_nutrients VALUES (11, 3, 1005, 12, 71);   -- 12g Carbohydrates
-  INSERT INTO food_nutrients VALUES (12, 3, 1008, 52, 71);   -- 52KCAL Energy
-`)
-	if err != nil {
-		fmt.Printf("failed to insert food nutrients: %s", err)
-		return
+	for _, food := range dailyFoods {
+		fmt.Println(food.FoodName)
 	}
 
+	// Output:
+	// Bread
+	// Apple
+}
+
+func ExampleGetMealsWithRecentFirst() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1003, 'Protein', 'G'),
+			(1004, 'Total lipid (fat)', 'G'),
+			(1005, 'Carbohydrate, by difference', 'G'),
+			(1008, 'Energy', 'KCAL');
+
+		INSERT INTO meals (meal_id, meal_name) VALUES
+			(1, 'Pie'),
+			(2, 'Shake'),
+			(3, 'Pizza');
+
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Apple', 100, 'g', '1 medium'),
+			(2, 'Milk', 100, 'g', ''),
+			(3, 'Tomato', 100, 'g', '');
+
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1), (2, 2), (3, 3);
+
+		INSERT INTO food_prefs (food_id, serving_size, number_of_servings) VALUES
+			(1, 100, 1),
+			(2, 100, 1),
+			(3, 100, 1);
+
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES
+			(1, 1, 100, 1),
+			(2, 2, 100, 1),
+			(3, 3, 120, 1);
+
+		INSERT INTO daily_meals (id, meal_id, date, time) VALUES
+			(1, 3, '2023-01-01', '00:00:00'),
+			(2, 3, '2023-01-01', '00:00:00'),
+			(3, 2, '2023-01-01', '00:00:00');
+
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1003, 0.3, 71), -- 0.3g Protein
+			(1, 1004, 0.2, 71), -- 0.2g Fat
+			(1, 1005, 12, 71),  -- 12g Carbohydrates
+			(1, 1008, 52, 71),  -- 52KCAL Energy
+			(2, 1003, 0.3, 71),
+			(2, 1004, 0.2, 71),
+			(2, 1005, 12, 71),
+			(2, 1008, 52, 71),
+			(3, 1003, 0.3, 71),
+			(3, 1004, 0.2, 71),
+			(3, 1005, 12, 71),
+			(3, 1008, 52, 71);
+	`)
+	defer db.Close()
+
 	meals, err := MealsWithRecentFirst(db)
 	if err != nil {
 		fmt.Println(err)
@@ -756,104 +553,115 @@ func ExampleGetMealsWithRecentFirst() {
 	// Pie
 }
 
-func ExampleGetMealFoodWithPref() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+func ExampleSearchMeals() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1003, 'Protein', 'G'),
+			(1004, 'Total lipid (fat)', 'G'),
+			(1005, 'Carbohydrate, by difference', 'G'),
+			(1008, 'Energy', 'KCAL');
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Apple', 100, 'g', '1 medium'),
+			(2, 'Milk', 100, 'g', '');
+
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1008, 52, 71),
+			(2, 1008, 42, 71);
+
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Pie'), (2, 'Shake'), (3, 'Pizza');
+
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1), (2, 1), (2, 2);
+
+		UPDATE meals SET meal_name = 'Banana shake' WHERE meal_id = 2;
+	`)
 	defer db.Close()
 
-	// Create tables.
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS foods (
-			food_id INTEGER PRIMARY KEY,
-			food_name TEXT NOT NULL,
-			serving_size REAL NOT NULL,
-			serving_unit TEXT NOT NULL,
-			household_serving TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS food_prefs (
-			food_id INTEGER PRIMARY KEY,
-			serving_size REAL,
-			number_of_servings REAL DEFAULT 1 NOT NULL,
-			FOREIGN KEY(food_id) REFERENCES foods(food_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS meal_food_prefs (
-			meal_id INTEGER,
-			food_id INTEGER,
-			serving_size REAL,
-			number_of_servings REAL DEFAULT 1 NOT NULL,
-			PRIMARY KEY(meal_id, food_id),
-			FOREIGN KEY(food_id) REFERENCES foods(food_id),
-			FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-		);
-
-		CREATE TABLE IF NOT EXISTS food_nutrients (
-		id INTEGER PRIMARY KEY,
-		food_id INTEGER NOT NULL,
-		nutrient_id INTEGER NOT NULL,
-		amount REAL NOT NULL,
-		derivation_id REAL NOT NULL,
-		FOREIGN KEY (food_id) REFERENCES foods(food_id),
-		FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-		FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-		);
-
-		CREATE TABLE IF NOT EXISTS nutrients (
-			nutrient_id INTEGER PRIMARY KEY,
-			nutrient_name TEXT NOT NULL,
-			unit_name TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS daily_foods (
-  		id INTEGER PRIMARY KEY,
-  		food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  		meal_id INTEGER REFERENCES meals(meal_id),
-  		date DATE NOT NULL,
-			time TIME NOT NULL,
-  		serving_size REAL NOT NULL,
-  		number_of_servings REAL DEFAULT 1 NOT NULL,
-			calories REAL NOT NULL,
-			protein REAL NOT NULL,
-  		fat REAL NOT NULL,
-  		carbs REAL NOT NULL
-		);
-  `)
-	if err != nil {
-		log.Fatalf("failed to create schema: %s", err)
+	for _, term := range []string{"apple", "milk", "shake", "pi*"} {
+		meals, err := SearchMeals(db, term)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%s:", term)
+		for _, meal := range meals {
+			fmt.Printf(" %s (%d foods)", meal.Name, len(meal.Foods))
+		}
+		fmt.Println()
 	}
 
-	// Insert test data.
-	_, err = db.Exec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-  (1, 'Protein', 'g'),
-  (2, 'Total lipid (fat)', 'g'),
-  (3, 'Carbohydrate, by difference', 'g'),
-  (4, 'Energy', 'KCAL')`)
-	if err != nil {
-		log.Fatalf("failed to insert data into nutrients: %s", err)
-	}
+	// Output:
+	// apple: Pie (1 foods) Banana shake (2 foods)
+	// milk: Banana shake (2 foods)
+	// shake: Banana shake (2 foods)
+	// pi*: Pizza (0 foods) Pie (1 foods)
+}
 
-	_, err = db.Exec(`
-  INSERT INTO foods VALUES (1, 'Apple', 150, 'g', '1 medium');
-  INSERT INTO food_prefs VALUES (1, 160, 1);
-  INSERT INTO meal_food_prefs VALUES (1, 1, 180, 2);
-`)
-	if err != nil {
-		log.Fatalf("failed to insert data into foods, food_prefs, meal_food_prefs: %s", err)
+func ExampleSearchMeals_unindexed() {
+	// A database made before meals could be searched, with meals added
+	// before its first search.
+	db := dbtest.MustNew(`
+		DROP TRIGGER meals_fts_insert;
+		DROP TRIGGER meals_fts_update;
+		DROP TRIGGER meals_fts_delete;
+		DROP TRIGGER meals_fts_meal_food_insert;
+		DROP TRIGGER meals_fts_meal_food_delete;
+		DROP TRIGGER meals_fts_food_update;
+		DROP TABLE meals_fts;
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Breakfast'), (2, 'Dinner');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1), (1, 3), (2, 2);
+	`)
+	defer db.Close()
+
+	for _, term := range []string{"banana", "dinner"} {
+		meals, err := SearchMeals(db, term)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, m := range meals {
+			fmt.Printf("%s: %s\n", term, m.Name)
+		}
 	}
 
-	_, err = db.Exec(`
-  INSERT INTO food_nutrients VALUES (1, 1, 1, 0.3, 71);  -- 0.3g Protein
-  INSERT INTO food_nutrients VALUES (2, 1, 2, 0.2, 71);  -- 0.2g Fat
-  INSERT INTO food_nutrients VALUES (3, 1, 3, 12, 71);   -- 12g Carbohydrates
-  INSERT INTO food_nutrients VALUES (4, 1, 4, 52, 71);   -- 52KCAL Energy
-`)
+	// Meals added once it exists are indexed too.
+	if _, err := db.Exec(`INSERT INTO meals (meal_id, meal_name) VALUES (3, 'Banana bread')`); err != nil {
+		log.Fatal(err)
+	}
+	meals, err := SearchMeals(db, "banana")
 	if err != nil {
-		log.Fatalf("failed to insert data into food_nutrients: %s", err)
+		log.Fatal(err)
 	}
+	fmt.Println(len(meals))
+	// Output:
+	// banana: Breakfast
+	// dinner: Dinner
+	// 2
+}
+
+func ExampleGetMealFoodWithPref() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1, 'Protein', 'g'),
+			(2, 'Total lipid (fat)', 'g'),
+			(3, 'Carbohydrate, by difference', 'g'),
+			(4, 'Energy', 'KCAL');
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (1, 'Apple', 150, 'g', '1 medium');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Snack');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1);
+		INSERT INTO food_prefs (food_id, serving_size, number_of_servings) VALUES (1, 160, 1);
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES (1, 1, 180, 2);
+
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1, 0.3, 71), -- 0.3g Protein
+			(1, 2, 0.2, 71), -- 0.2g Fat
+			(1, 3, 12, 71),  -- 12g Carbohydrates
+			(1, 4, 52, 71);  -- 52KCAL Energy
+	`)
+	defer db.Close()
 
 	// Test getMealFoodWithPref.
 	mealFood, err := mealFoodWithPref(db, 1, 1)
@@ -883,81 +691,26 @@ func ExampleGetMealFoodWithPref() {
 }
 
 func ExampleGetFoodWithPref() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1, 'Protein', 'g'),
+			(2, 'Total lipid (fat)', 'g'),
+			(3, 'Carbohydrate, by difference', 'g'),
+			(4, 'Energy', 'KCAL');
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (1, 'Apple', 100, 'g', '1 medium');
+		INSERT INTO food_prefs (food_id, serving_size, number_of_servings) VALUES (1, 160, 1);
+
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1, 0.3, 71), -- 0.3g Protein
+			(1, 2, 0.2, 71), -- 0.2g Fat
+			(1, 3, 12, 71),  -- 12g Carbohydrates
+			(1, 4, 52, 71);  -- 52KCAL Energy
+	`)
 	defer db.Close()
 
-	// Create tables.
-	_, err = db.Exec(`
-    CREATE TABLE IF NOT EXISTS foods (
-      food_id INTEGER PRIMARY KEY,
-      food_name TEXT NOT NULL,
-      serving_size REAL NOT NULL,
-      serving_unit TEXT NOT NULL,
-      household_serving TEXT NOT NULL
-    );
-
-    CREATE TABLE IF NOT EXISTS food_prefs (
-      food_id INTEGER PRIMARY KEY,
-      serving_size REAL,
-      number_of_servings REAL DEFAULT 1 NOT NULL,
-      FOREIGN KEY(food_id) REFERENCES foods(food_id)
-    );
-
-    CREATE TABLE IF NOT EXISTS food_nutrients (
-    id INTEGER PRIMARY KEY,
-    food_id INTEGER NOT NULL,
-    nutrient_id INTEGER NOT NULL,
-    amount REAL NOT NULL,
-    derivation_id REAL NOT NULL,
-    FOREIGN KEY (food_id) REFERENCES foods(food_id),
-    FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-    FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-    );
-
-    CREATE TABLE IF NOT EXISTS nutrients (
-      nutrient_id INTEGER PRIMARY KEY,
-      nutrient_name TEXT NOT NULL,
-      unit_name TEXT NOT NULL
-    );
-
-  `)
-	if err != nil {
-		log.Fatalf("failed to create schema: %s", err)
-	}
-
-	// Insert test data.
-	_, err = db.Exec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-  (1, 'Protein', 'g'),
-  (2, 'Total lipid (fat)', 'g'),
-  (3, 'Carbohydrate, by difference', 'g'),
-  (4, 'Energy', 'KCAL')`)
-	if err != nil {
-		log.Fatalf("failed to insert data into nutrients: %s", err)
-	}
-
-	_, err = db.Exec(`
-  INSERT INTO foods VALUES (1, 'Apple', 100, 'g', '1 medium');
-  INSERT INTO food_prefs VALUES (1, 160, 1);
-`)
-	if err != nil {
-		log.Fatalf("failed to insert data into foods, food_prefs: %s", err)
-	}
-
-	_, err = db.Exec(`
-  INSERT INTO food_nutrients VALUES (1, 1, 1, 0.3, 71);  -- 0.3g Protein
-  INSERT INTO food_nutrients VALUES (2, 1, 2, 0.2, 71);  -- 0.2g Fat
-  INSERT INTO food_nutrients VALUES (3, 1, 3, 12, 71);   -- 12g Carbohydrates
-  INSERT INTO food_nutrients VALUES (4, 1, 4, 52, 71);   -- 52KCAL Energy
-`)
-	if err != nil {
-		fmt.Printf("failed to insert data into food_nutrients: %s\n", err)
-		return
-	}
-
 	food, err := FoodWithPref(db, 1)
 	if err != nil {
 		fmt.Println(err)
@@ -986,11 +739,7 @@ func ExampleGetFoodWithPref() {
 }
 
 func ExampleAddMealEntry() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1001,20 +750,6 @@ func ExampleAddMealEntry() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	tx.MustExec(`
-		CREATE TABLE IF NOT EXISTS meals (
-				meal_id INTEGER PRIMARY KEY,
-				meal_name TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS daily_meals (
-  		id INTEGER PRIMARY KEY,
-  		meal_id INTEGER REFERENCES meals(meal_id),
-  		date DATE NOT NULL,
-			time TIME NOT NULL
-		);
-	`)
-
 	_, err = tx.Exec(`INSERT INTO meals VALUES (1, 'Pie')`)
 	if err != nil {
 		log.Printf("Failed to insert data into meal table: %v\n", err)
@@ -1053,11 +788,7 @@ func ExampleAddMealEntry() {
 }
 
 func ExampleAddMealFoodEntries() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		log.Println("Could not connect to test database:", err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1076,48 +807,6 @@ func ExampleAddMealFoodEntries() {
 		}
 	}()
 
-	// Create the foods table
-	tx.MustExec(`
-	CREATE TABLE IF NOT EXISTS foods (
-  food_id INTEGER PRIMARY KEY,
-  food_name TEXT NOT NULL,
-  serving_size REAL NOT NULL,
-  serving_unit TEXT NOT NULL,
-  household_serving TEXT NOT NULL
-  );
-
-	CREATE TABLE daily_foods (
-  id INTEGER PRIMARY KEY,
-  food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_id INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-	time TIME NOT NULL,
-  serving_size REAL NOT NULL,
-  number_of_servings REAL DEFAULT 1 NOT NULL,
-	calories REAL NOT NULL,
-  protein REAL NOT NULL,
-  fat REAL NOT NULL,
-  carbs REAL NOT NULL,
-	price REAL DEFAULT 0
-	);
-
-	CREATE TABLE IF NOT EXISTS meals (
-  meal_id INTEGER PRIMARY KEY,
-  meal_name TEXT NOT NULL
-  );
-
-  CREATE TABLE IF NOT EXISTS daily_meals (
-  id INTEGER PRIMARY KEY,
-  meal_id INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-	time TIME NOT NULL
-  );
-	`)
-	if err != nil {
-		log.Printf("failed to create schema: %v\n", err)
-		return
-	}
-
 	// Insert foods
 	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
   (1, 'Chicken Breast', 100, 'g', '1/2 piece'),
@@ -1185,7 +874,7 @@ func ExampleAddMealFoodEntries() {
 	}
 
 	testDate := time.Date(2023, 7, 15, 0, 0, 0, 0, time.UTC)
-	err = AddMealFoodEntries(tx, 1, mealFoods, testDate)
+	err = AddMealFoodEntries(tx, 1, mealFoods, 1, testDate)
 	if err != nil {
 		log.Printf("Failed to add meal food entries: %v\n.", err)
 		return
@@ -1210,6 +899,62 @@ func ExampleAddMealFoodEntries() {
 	// <nil>
 }
 
+func ExampleAddMealFoodEntries_portion() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (1, 'Chili', 250, 'g', '1 bowl');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Chili');
+	`)
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	mealFoods := []MealFood{{
+		Food: Food{ID: 1, Name: "Chili", Calories: 800, Price: 4,
+			FoodMacros: &FoodMacros{Protein: 60, Fat: 30, Carbs: 70}},
+		ServingSize:      250,
+		NumberOfServings: 2,
+	}}
+
+	portion, err := ParsePortion("1/2")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	date := time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC)
+	if err := AddMealFoodEntries(tx, 1, mealFoods, portion, date); err != nil {
+		log.Println(err)
+		return
+	}
+
+	var e struct {
+		Servings float64 `db:"number_of_servings"`
+		Calories float64 `db:"calories"`
+		Protein  float64 `db:"protein"`
+		Price    float64 `db:"price"`
+	}
+	if err := tx.Get(&e, `SELECT number_of_servings, calories, protein, price FROM daily_foods`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(e.Servings, e.Calories, e.Protein, e.Price)
+	// The meal's foods are left as they were.
+	fmt.Println(mealFoods[0].NumberOfServings, mealFoods[0].Calories)
+
+	_, err = ParsePortion("0")
+	fmt.Println(err)
+
+	// Output:
+	// 1 400 30 2
+	// 2 800
+	// invalid portion "0": want a number greater than 0, such as 0.5 or 1/2
+}
+
 func ExampleGetValidLog() {
 	entries := &[]Entry{
 		{
@@ -1265,11 +1010,7 @@ func ExampleGetValidLog() {
 }
 
 func ExampleUpdateFoodPrefs() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1279,24 +1020,6 @@ func ExampleUpdateFoodPrefs() {
 	}
 	defer tx.Rollback()
 
-	// Create the food_nutrients table
-	tx.MustExec(`
-      CREATE TABLE IF NOT EXISTS foods (
-        food_id INTEGER PRIMARY KEY,
-        food_name TEXT NOT NULL,
-        serving_size REAL NOT NULL,
-        serving_unit TEXT NOT NULL,
-        household_serving TEXT NOT NULL
-      );
-
-			CREATE TABLE IF NOT EXISTS food_prefs (
-				food_id INTEGER PRIMARY KEY,
-				serving_size REAL,
-				number_of_servings REAL DEFAULT 1 NOT NULL,
-				FOREIGN KEY(food_id) REFERENCES foods(food_id)
-			);
-	`)
-
 	// Insert food
 	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
   (1, 'Chicken Breast', 100, 'g', '1/2 piece')
@@ -1332,11 +1055,7 @@ func ExampleUpdateFoodPrefs() {
 }
 
 func ExampleGetTotalFoodsLogged() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1347,26 +1066,16 @@ func ExampleGetTotalFoodsLogged() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the daily_foods table
-	tx.MustExec(`CREATE TABLE daily_foods (
-  id INTEGER PRIMARY KEY,
-  food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_ID INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-  time TIME NOT NULL,
-  number_of_servings REAL DEFAULT 1 NOT NULL
-  )`)
-
 	// Insert data
-	tx.MustExec(`INSERT INTO daily_foods (food_id, date, time, number_of_servings) VALUES
-	(1, '2023-01-01', '00:00:00', 1),
-	(2, '2023-01-01', '00:00:00', 1),
-	(2, '2023-01-02','00:00:00',  1),
-	(4, '2023-01-02','00:00:00',  1),
-	(5, '2023-01-03', '00:00:00', 1),
-	(1, '2023-01-03', '00:00:00', 1),
-	(3, '2023-01-04', '00:00:00', 1),
-	(4, '2023-01-04', '00:00:00', 1)
+	tx.MustExec(`INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+	(1, '2023-01-01', '00:00:00', 40, 1, 156, 5, 3, 27),
+	(2, '2023-01-01', '00:00:00', 100, 1, 165, 31, 3.6, 0),
+	(2, '2023-01-02', '00:00:00', 100, 1, 165, 31, 3.6, 0),
+	(3, '2023-01-02', '00:00:00', 118, 1, 105, 1.3, 0.4, 27),
+	(3, '2023-01-03', '00:00:00', 118, 1, 105, 1.3, 0.4, 27),
+	(1, '2023-01-03', '00:00:00', 40, 1, 156, 5, 3, 27),
+	(3, '2023-01-04', '00:00:00', 118, 1, 105, 1.3, 0.4, 27),
+	(2, '2023-01-04', '00:00:00', 100, 1, 165, 31, 3.6, 0)
 	`)
 
 	total, err := totalFoodsLogged(tx)
@@ -1382,11 +1091,7 @@ func ExampleGetTotalFoodsLogged() {
 }
 
 func ExampleGetFrequentFoods() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1397,27 +1102,6 @@ func ExampleGetFrequentFoods() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the foods table
-	tx.MustExec(`
-  CREATE TABLE IF NOT EXISTS foods (
-  food_id INTEGER PRIMARY KEY,
-  food_name TEXT NOT NULL,
-  serving_size REAL NOT NULL,
-  serving_unit TEXT NOT NULL,
-  household_serving TEXT NOT NULL
-  );
-
-  CREATE TABLE daily_foods (
-  id INTEGER PRIMARY KEY,
-  food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-  meal_id INTEGER REFERENCES meals(meal_id),
-  date DATE NOT NULL,
-  time TIME NOT NULL,
-  serving_size REAL NOT NULL,
-  number_of_servings REAL DEFAULT 1 NOT NULL
-  );
-	`)
-
 	// Insert foods
 	tx.MustExec(`
 		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
@@ -1428,13 +1112,13 @@ func ExampleGetFrequentFoods() {
 
 	// Insert daily foods
 	tx.MustExec(`
-		INSERT INTO daily_foods (food_id, date, time ,serving_size) VALUES
-		(1, '2023-07-10', '00:00:00', 100),
-		(1, '2023-07-10','00:00:00',  100),
-		(1, '2023-07-10', '00:00:00', 100),
-		(2, '2023-07-10', '00:00:00', 200),
-		(2, '2023-07-11', '00:00:00', 200),
-		(3, '2023-07-12', '00:00:00', 300);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+		(1, '2023-07-10', '00:00:00', 100, 165, 31, 3.6, 0),
+		(1, '2023-07-10', '00:00:00', 100, 165, 31, 3.6, 0),
+		(1, '2023-07-10', '00:00:00', 100, 165, 31, 3.6, 0),
+		(2, '2023-07-10', '00:00:00', 200, 500, 52, 32, 0),
+		(2, '2023-07-11', '00:00:00', 200, 500, 52, 32, 0),
+		(3, '2023-07-12', '00:00:00', 300, 726, 81, 42, 0);
 	`)
 
 	// Call the function to test
@@ -1455,11 +1139,7 @@ func ExampleGetFrequentFoods() {
 }
 
 func ExampleGetFoodEntriesForDate() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -1470,36 +1150,6 @@ func ExampleGetFoodEntriesForDate() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create tables.
-	_, err = tx.Exec(`
-    CREATE TABLE IF NOT EXISTS foods (
-      food_id INTEGER PRIMARY KEY,
-      food_name TEXT NOT NULL,
-      serving_size REAL NOT NULL,
-      serving_unit TEXT NOT NULL,
-      household_serving TEXT NOT NULL
-    );
-		CREATE TABLE IF NOT EXISTS daily_foods (
-      id INTEGER PRIMARY KEY,
-      food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-      meal_id INTEGER REFERENCES meals(meal_id),
-      date DATE NOT NULL,
-      time TIME NOT NULL,
-      serving_size REAL NOT NULL,
-      number_of_servings REAL DEFAULT 1 NOT NULL,
-      calories REAL NOT NULL,
-      protein REAL NOT NULL,
-      fat REAL NOT NULL,
-      carbs REAL NOT NULL,
-			price REAL DEFAULT 0
-    );
-  `)
-
-	if err != nil {
-		fmt.Println("Failed to setup tables:", err)
-		return
-	}
-
 	// Insert foods
 	tx.MustExec(`
     INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
@@ -1520,7 +1170,7 @@ func ExampleGetFoodEntriesForDate() {
   `)
 
 	date := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
-	entries, err := foodEntriesForDate(tx, date)
+	entries, err := FoodEntriesForDate(tx, date)
 	if err != nil {
 		log.Println(err)
 		return
@@ -1550,3 +1200,29 @@ func ExampleRenderProgressBar_full() {
 	// Output:
 	// [███████████]
 }
+
+func Example_promptDateNotPast() {
+	defer SetClock(FixedClock(time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)))()
+	defer func(c string) { LateNightCutoff = c }(LateNightCutoff)
+	defer func(r *bufio.Reader) { Input = r }(Input)
+	LateNightCutoff = "03:00"
+
+	// At 1 a.m., food is logged on the day before by default, and that
+	// day isn't in the past.
+	Input = bufio.NewReader(strings.NewReader("\n"))
+	fmt.Println()
+	fmt.Println(promptDateNotPast("Enter food entry date", FoodLogNow()).Format(dateFormat))
+	Input = bufio.NewReader(strings.NewReader("2024-01-01\n"))
+	fmt.Println()
+	fmt.Println(promptDateNotPast("Enter food entry date", FoodLogNow()).Format(dateFormat))
+	Input = bufio.NewReader(strings.NewReader("\n"))
+	fmt.Println()
+	fmt.Println(promptDateNotPast("Enter weight entry date", LogNow()).Format(dateFormat))
+	// Output:
+	//
+	// Enter food entry date (YYYY-MM-DD) [Press <Enter> for today's date]:  2024-01-01
+	//
+	// Enter food entry date (YYYY-MM-DD) [Press <Enter> for today's date]:  2024-01-01
+	//
+	// Enter weight entry date (YYYY-MM-DD) [Press <Enter> for today's date]:  2024-01-02
+}
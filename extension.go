@@ -0,0 +1,176 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// phaseExtensionsSchema creates the phase_extensions table in
+// databases made before it existed.
+const phaseExtensionsSchema = `
+	CREATE TABLE IF NOT EXISTS phase_extensions (
+		id INTEGER PRIMARY KEY,
+		phase_id INTEGER NOT NULL,
+		date DATE NOT NULL,
+		valid_weeks INTEGER NOT NULL,
+		total_weeks INTEGER NOT NULL,
+		weeks INTEGER NOT NULL,
+		action TEXT NOT NULL CHECK(action IN ('extended', 'declined'))
+	)
+`
+
+// PhaseAdherence is the fraction of a phase's weeks that must be valid
+// for the phase to end on schedule. Below it, the user is offered to
+// extend the phase by the weeks that weren't valid instead.
+var PhaseAdherence = 0.6
+
+// PhaseExtension is an extension of a phase offered when too few of its
+// weeks were valid, and whether the user took it.
+type PhaseExtension struct {
+	ID         int       `db:"id"`
+	PhaseID    int       `db:"phase_id"`
+	Date       time.Time `db:"date"`
+	ValidWeeks int       `db:"valid_weeks"`
+	TotalWeeks int       `db:"total_weeks"`
+	Weeks      int       `db:"weeks"`  // Weeks the phase is extended by.
+	Action     string    `db:"action"` // "extended" or "declined".
+}
+
+// phaseWeeks counts the full weeks of the user's phase and how many of
+// them were valid: the user logged at least minEntriesPerWeek days and
+// met the weekly calorie goal. Weeks are counted in 7 day steps from the
+// phase start date, and weeks with a diet break are left out.
+func phaseWeeks(u *UserInfo, entries []Entry) (valid, total int) {
+	for weekStart := u.Phase.StartDate; !weekStart.AddDate(0, 0, 6).After(u.Phase.EndDate); weekStart = weekStart.AddDate(0, 0, 7) {
+		weekEnd := weekStart.AddDate(0, 0, 6)
+		if u.Phase.onBreak(weekStart, weekEnd) {
+			continue
+		}
+		total++
+
+		var days []Entry
+		for _, e := range entries {
+			if !e.Date.Before(weekStart) && !e.Date.After(weekEnd) {
+				days = append(days, e)
+			}
+		}
+		if len(days) >= minEntriesPerWeek && metWeeklyCalGoal(u, days) {
+			valid++
+		}
+	}
+	return valid, total
+}
+
+// addPhaseExtensionsTable creates the phase_extensions table if it
+// doesn't exist, along with its audit triggers.
+func addPhaseExtensionsTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(phaseExtensionsSchema); err != nil {
+		return fmt.Errorf("couldn't create phase extensions table: %v", err)
+	}
+	return auditTable(tx, "phase_extensions")
+}
+
+// phaseExtension returns the extension offered for the phase, or nil.
+func phaseExtension(tx *sqlx.Tx, phaseID int) (*PhaseExtension, error) {
+	if err := addPhaseExtensionsTable(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT * FROM phase_extensions
+		WHERE phase_id = $1
+		ORDER BY date DESC, id DESC
+		LIMIT 1
+	`
+	x := &PhaseExtension{}
+	if err := tx.Get(x, query, phaseID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("couldn't get phase extension: %v", err)
+	}
+	return x, nil
+}
+
+// addPhaseExtension records an offered extension and the user's
+// decision.
+func addPhaseExtension(tx *sqlx.Tx, x PhaseExtension) error {
+	const query = `
+		INSERT INTO phase_extensions (phase_id, date, valid_weeks, total_weeks, weeks, action)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := tx.Exec(query, x.PhaseID, x.Date.Format(dateFormat), x.ValidWeeks, x.TotalWeeks, x.Weeks, x.Action)
+	if err != nil {
+		return fmt.Errorf("couldn't save phase extension: %v", err)
+	}
+	return nil
+}
+
+// checkExtension is run once the user's phase is over. If fewer than
+// PhaseAdherence of its weeks were valid, it offers to push the end
+// date back by the weeks that weren't, records the decision, and
+// reports whether the phase was extended. The extension is offered once
+// per phase, so a phase that keeps going off track still ends, and not
+// at all if nothing was logged during the phase.
+func checkExtension(tx *sqlx.Tx, u *UserInfo, now time.Time) (bool, error) {
+	prev, err := phaseExtension(tx, u.Phase.PhaseID)
+	if err != nil || prev != nil {
+		return false, err
+	}
+	if err := loadDietBreaks(tx, u); err != nil {
+		return false, err
+	}
+	entries, err := AllEntries(tx)
+	if err != nil {
+		return false, err
+	}
+	if err := MarkTrainingDays(tx, entries); err != nil {
+		return false, err
+	}
+	if err := markGoals(tx, u, entries); err != nil {
+		return false, err
+	}
+
+	logged := false
+	for _, e := range *entries {
+		if !e.Date.Before(u.Phase.StartDate) && !e.Date.After(u.Phase.EndDate) {
+			logged = true
+			break
+		}
+	}
+	valid, total := phaseWeeks(u, *entries)
+	if !logged || total == 0 || float64(valid)/float64(total) >= PhaseAdherence {
+		return false, nil
+	}
+
+	x := PhaseExtension{
+		PhaseID:    u.Phase.PhaseID,
+		Date:       dateOf(now),
+		ValidWeeks: valid,
+		TotalWeeks: total,
+		Weeks:      total - valid,
+		Action:     "declined",
+	}
+	end := u.Phase.EndDate.AddDate(0, 0, 7*x.Weeks)
+	fmt.Printf("Only %d of the %d weeks of your %s were on track.\n", valid, total, u.Phase.Name)
+	fmt.Printf("Extend the %s by %d weeks, to %s, instead of ending it? (y/n): ", u.Phase.Name, x.Weeks, end.Format(dateFormat))
+	s, _ := Input.ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(s)); a == "y" || a == "yes" {
+		x.Action = "extended"
+		u.Phase.EndDate = end
+		u.Phase.Duration += float64(x.Weeks)
+		if err := updatePhaseInfo(tx, u); err != nil {
+			return false, err
+		}
+		fmt.Printf("Your %s now ends on %s.\n", u.Phase.Name, end.Format(dateFormat))
+	}
+
+	if err := addPhaseExtension(tx, x); err != nil {
+		return false, err
+	}
+	return x.Action == "extended", nil
+}
@@ -0,0 +1,61 @@
+package bite
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExamplePhaseExtension() {
+	// Food and weight were logged on two days of each of the first 5
+	// weeks of the 12 week cut, and on none after.
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '07:00:00', 185.0), ('2024-01-03', '07:00:00', 184.6),
+			('2024-01-08', '07:00:00', 184.0), ('2024-01-10', '07:00:00', 183.8),
+			('2024-01-15', '07:00:00', 183.1), ('2024-01-17', '07:00:00', 182.9),
+			('2024-01-22', '07:00:00', 182.4), ('2024-01-24', '07:00:00', 182.0),
+			('2024-01-29', '07:00:00', 181.6), ('2024-01-31', '07:00:00', 181.2);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES
+			(1, '2024-01-01', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-03', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-08', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-10', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-15', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-17', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-22', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-24', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-29', '08:00:00', 40, 1, 2000, 150, 60, 210),
+			(1, '2024-01-31', '08:00:00', 40, 1, 2000, 150, 60, 210);
+	`)
+	defer db.Close()
+	defer SetClock(FixedClock(time.Date(2024, 3, 27, 9, 0, 0, 0, time.UTC)))()
+	defer func(r *bufio.Reader) { Input = r }(Input)
+	Input = bufio.NewReader(strings.NewReader("y\n"))
+
+	u, err := Config(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	status, err := CheckPhaseStatus(db, u)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(status, u.Phase.EndDate.Format(dateFormat), u.Phase.Duration)
+
+	var x PhaseExtension
+	if err := db.Get(&x, `SELECT * FROM phase_extensions`); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(x.Date.Format(dateFormat), x.ValidWeeks, x.TotalWeeks, x.Weeks, x.Action)
+	// Output:
+	// Only 5 of the 12 weeks of your cut were on track.
+	// Extend the cut by 7 weeks, to 2024-05-13, instead of ending it? (y/n): Your cut now ends on 2024-05-13.
+	// active 2024-05-13 19
+	// 2024-03-27 5 12 7 extended
+}
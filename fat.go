@@ -0,0 +1,209 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// USDA nutrient ids of the kinds of fat.
+const (
+	transNutrientID     = 1257 // Fatty acids, total trans.
+	saturatedNutrientID = 1258 // Fatty acids, total saturated.
+	monoNutrientID      = 1292 // Fatty acids, total monounsaturated.
+	polyNutrientID      = 1293 // Fatty acids, total polyunsaturated.
+)
+
+// SaturatedFatMax is the most saturated fat (g) to eat a day. Zero
+// means no limit.
+var SaturatedFatMax float64
+
+// FatQuality is how fat (g) splits between its kinds.
+type FatQuality struct {
+	Saturated float64
+	Mono      float64 // Monounsaturated.
+	Poly      float64 // Polyunsaturated.
+	Trans     float64
+}
+
+// Unsaturated returns the monounsaturated and polyunsaturated fat.
+func (f FatQuality) Unsaturated() float64 {
+	return f.Mono + f.Poly
+}
+
+// times returns the fats scaled by the factor.
+func (f FatQuality) times(x float64) FatQuality {
+	return FatQuality{f.Saturated * x, f.Mono * x, f.Poly * x, f.Trans * x}
+}
+
+// String lists the kinds of fat, such as "saturated 3.2g,
+// monounsaturated 5.1g, polyunsaturated 2.0g, trans 0.1g".
+func (f FatQuality) String() string {
+	parts := []string{
+		"saturated " + FormatMacro(f.Saturated) + "g",
+		"monounsaturated " + FormatMacro(f.Mono) + "g",
+		"polyunsaturated " + FormatMacro(f.Poly) + "g",
+	}
+	if f.Trans > 0 {
+		parts = append(parts, "trans "+FormatMacro(f.Trans)+"g")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fatQuality returns the fats of the nutrient amounts, keyed by
+// nutrient id.
+func fatQuality(n map[int]float64) FatQuality {
+	return FatQuality{
+		Saturated: n[saturatedNutrientID],
+		Mono:      n[monoNutrientID],
+		Poly:      n[polyNutrientID],
+		Trans:     n[transNutrientID],
+	}
+}
+
+// dailyFats returns the fats eaten each day from from to to, inclusive,
+// keyed by date. Days whose foods have no fat breakdown are left out.
+func dailyFats(q sqlx.Queryer, from, to time.Time) (map[string]FatQuality, error) {
+	days, err := dailyNutrients(q, from, to, saturatedNutrientID, monoNutrientID, polyNutrientID, transNutrientID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get fat breakdown: %v", err)
+	}
+	fats := make(map[string]FatQuality, len(days))
+	for date, n := range days {
+		fats[date] = fatQuality(n)
+	}
+	return fats, nil
+}
+
+// foodFats returns the fats of PortionSize grams of the food, or nil
+// if the food has no fat breakdown.
+func foodFats(q sqlx.Queryer, foodID int) (*FatQuality, error) {
+	query, args, err := sqlx.In(`
+		SELECT nutrient_id, amount FROM food_nutrients
+		WHERE food_id = ? AND nutrient_id IN (?)
+	`, foodID, []int{saturatedNutrientID, monoNutrientID, polyNutrientID, transNutrientID})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get fat breakdown: %v", err)
+	}
+	defer rows.Close()
+	n := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var amount float64
+		if err := rows.Scan(&id, &amount); err != nil {
+			return nil, fmt.Errorf("couldn't get fat breakdown: %v", err)
+		}
+		n[id] = amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't get fat breakdown: %v", err)
+	}
+	if len(n) == 0 {
+		return nil, nil
+	}
+	f := fatQuality(n)
+	return &f, nil
+}
+
+// MarkFats sets Fats on the entries from their day's food log.
+func MarkFats(db *sqlx.DB, entries *[]Entry) error {
+	if len(*entries) == 0 {
+		return nil
+	}
+	first, last := (*entries)[0].Date, (*entries)[len(*entries)-1].Date
+	fats, err := dailyFats(db, first, last)
+	if err != nil {
+		return err
+	}
+	for i := range *entries {
+		e := &(*entries)[i]
+		if f, ok := fats[e.Date.Format(dateFormat)]; ok {
+			e.Fats = &f
+		}
+	}
+	return nil
+}
+
+// weekFats returns the fats of the days with a fat breakdown, how many
+// days had one, and how many went over SaturatedFatMax.
+func weekFats(days []Entry) (total FatQuality, known, over int) {
+	for _, e := range days {
+		if e.Fats == nil {
+			continue
+		}
+		known++
+		total.Saturated += e.Fats.Saturated
+		total.Mono += e.Fats.Mono
+		total.Poly += e.Fats.Poly
+		total.Trans += e.Fats.Trans
+		if SaturatedFatMax > 0 && e.Fats.Saturated > SaturatedFatMax {
+			over++
+		}
+	}
+	return total, known, over
+}
+
+// printWeekFats prints the average fat breakdown of the week's days and
+// the days over the saturated fat limit.
+func printWeekFats(days []Entry) {
+	total, known, over := weekFats(days)
+	if known == 0 {
+		return
+	}
+	fmt.Printf(tr("Fats a day: %s\n"), total.times(1/float64(known)))
+	if over > 0 {
+		fmt.Printf(tr("Saturated fat was over the %sg limit on %d of %d days.\n"), FormatMacro(SaturatedFatMax), over, known)
+	}
+}
+
+// ShowFood lets the user select a food and prints its serving, calories,
+// macros, and fat breakdown.
+func ShowFood(db *sqlx.DB) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+	n, err := foodNutrition(db, food.ID)
+	if err != nil {
+		return err
+	}
+	fats, err := foodFats(db, food.ID)
+	if err != nil {
+		return err
+	}
+	printFood(food, n, fats)
+	return nil
+}
+
+// printFood prints a serving of the food given its nutrition and fats
+// per PortionSize grams.
+func printFood(food Food, n Nutrition, fats *FatQuality) {
+	name := food.Name
+	if food.BrandName != "" {
+		name += " (" + food.BrandName + ")"
+	}
+	fmt.Println(name)
+	serving := fmt.Sprintf("%s %s", formatNumber(food.ServingSize, 0), food.ServingUnit)
+	if food.HouseholdServing != "" {
+		serving = food.HouseholdServing + ", " + serving
+	}
+	fmt.Printf("Serving: %s\n", serving)
+
+	s := n.times(food.ServingSize / PortionSize)
+	fmt.Printf("Calories: %.0f\n", s.Calories)
+	fmt.Printf("Protein %sg, carbs %sg, fat %sg\n", FormatMacro(s.Protein), FormatMacro(s.Carbs), FormatMacro(s.Fat))
+	if fats == nil {
+		return
+	}
+	f := fats.times(food.ServingSize / PortionSize)
+	fmt.Printf("Fat: %s\n", f)
+	if SaturatedFatMax > 0 {
+		fmt.Printf("Saturated fat: %.0f%% of the %sg daily limit\n", f.Saturated*100/SaturatedFatMax, FormatMacro(SaturatedFatMax))
+	}
+}
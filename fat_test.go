@@ -0,0 +1,56 @@
+package bite
+
+import (
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleFatQuality() {
+	db := dbtest.MustNew(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1258, 'Fatty acids, total saturated', 'G'), (1292, 'Fatty acids, total monounsaturated', 'G'),
+			(1293, 'Fatty acids, total polyunsaturated', 'G');
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1258, 1.2, 71), (1, 1292, 2.2, 71), (1, 1293, 2.5, 71),
+			(2, 1258, 1, 71), (2, 1292, 1.2, 71), (2, 1293, 0.8, 71);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, '2024-03-18', '08:00:00', 40, 2, 311, 10.4, 5.6, 54.4),
+			(2, '2024-03-18', '12:00:00', 100, 2, 330, 62, 7.2, 0),
+			(2, '2024-03-19', '12:00:00', 100, 1, 165, 31, 3.6, 0);
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-03-18', '07:00:00', 180), ('2024-03-19', '07:00:00', 180.2);
+	`)
+	defer db.Close()
+	SaturatedFatMax = 2.5
+	defer func() { SaturatedFatMax = 0 }()
+
+	oats, err := foodFats(db, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	n, err := foodNutrition(db, 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	printFood(Food{Name: "Oats", ServingSize: 40, ServingUnit: "g", HouseholdServing: "1/2 cup"}, n, oats)
+
+	entries, err := AllEntries(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := MarkFats(db, entries); err != nil {
+		log.Fatal(err)
+	}
+	printWeekFats(*entries)
+
+	// Output:
+	// Oats
+	// Serving: 1/2 cup, 40 g
+	// Calories: 156
+	// Protein 5.2g, carbs 27.2g, fat 2.8g
+	// Fat: saturated 0.5g, monounsaturated 0.9g, polyunsaturated 1.0g
+	// Saturated fat: 19% of the 2.5g daily limit
+	// Fats a day: saturated 2.0g, monounsaturated 2.7g, polyunsaturated 2.2g
+	// Saturated fat was over the 2.5g limit on 1 of 2 days.
+}
@@ -0,0 +1,171 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// USDA nutrient ids of the parts of carbs tracked on their own.
+const (
+	fiberNutrientID = 1079 // Fiber, total dietary.
+	sugarNutrientID = 2000 // Sugars, total.
+)
+
+// FiberMin is the least fiber (g) to eat a day. Zero means 14 g per
+// 1000 calories of the day's calorie goal.
+var FiberMin float64
+
+// SugarMax is the most sugar (g) to eat a day. Zero means 10% of the
+// day's calorie goal.
+var SugarMax float64
+
+// fiberShortDays is the fraction of a week's logged days that must fall
+// short of the fiber minimum for the shortfall to be chronic.
+const fiberShortDays = 0.5
+
+// CarbSplit is the fiber and sugar (g) of a day's carbs, and the day's
+// fiber minimum and sugar maximum.
+type CarbSplit struct {
+	Fiber    float64
+	Sugar    float64
+	FiberMin float64
+	SugarMax float64
+}
+
+// LowFiber reports whether the day's fiber fell short of its minimum.
+func (c CarbSplit) LowFiber() bool {
+	return c.Fiber < c.FiberMin
+}
+
+// HighSugar reports whether the day's sugar went over its maximum.
+func (c CarbSplit) HighSugar() bool {
+	return c.Sugar > c.SugarMax
+}
+
+// fiberMin returns the fiber minimum of a day with the calorie goal.
+func fiberMin(goal float64) float64 {
+	if FiberMin > 0 {
+		return FiberMin
+	}
+	return 14 * goal / 1000
+}
+
+// sugarMax returns the sugar maximum of a day with the calorie goal.
+func sugarMax(goal float64) float64 {
+	if SugarMax > 0 {
+		return SugarMax
+	}
+	return goal * 0.1 / 4 // 4 calories a gram.
+}
+
+// carbSplits returns the fiber and sugar of each day from from to to,
+// inclusive, keyed by date. Days whose foods have neither are left
+// out, since their fiber is unknown rather than zero.
+func carbSplits(tx *sqlx.Tx, from, to time.Time) (map[string]CarbSplit, error) {
+	days, err := dailyNutrients(tx, from, to, fiberNutrientID, sugarNutrientID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get fiber and sugar: %v", err)
+	}
+	splits := make(map[string]CarbSplit, len(days))
+	for date, n := range days {
+		splits[date] = CarbSplit{Fiber: n[fiberNutrientID], Sugar: n[sugarNutrientID]}
+	}
+	return splits, nil
+}
+
+// dailyNutrients returns the amounts of the nutrients with the given
+// ids eaten each day from from to to, inclusive, keyed by date and
+// nutrient id. Days whose foods have none of the nutrients are left
+// out.
+func dailyNutrients(q sqlx.Queryer, from, to time.Time, ids ...int) (map[string]map[int]float64, error) {
+	// Nutrient amounts are per PortionSize grams of a food.
+	query, args, err := sqlx.In(fmt.Sprintf(`
+		SELECT CAST(df.date AS TEXT), fn.nutrient_id,
+			SUM(fn.amount * df.serving_size * df.number_of_servings / %d)
+		FROM daily_foods df
+		INNER JOIN food_nutrients fn ON fn.food_id = df.food_id
+		WHERE df.date BETWEEN ? AND ? AND fn.nutrient_id IN (?)
+		GROUP BY df.date, fn.nutrient_id
+	`, PortionSize), dateOf(from).Format(dateFormat), dateOf(to).Format(dateFormat), ids)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := make(map[string]map[int]float64)
+	for rows.Next() {
+		var date string
+		var id int
+		var amount float64
+		if err := rows.Scan(&date, &id, &amount); err != nil {
+			return nil, err
+		}
+		if days[date] == nil {
+			days[date] = make(map[int]float64)
+		}
+		days[date][id] = amount
+	}
+	return days, rows.Err()
+}
+
+// carbSplitOn returns the fiber and sugar of the day against the goals
+// of a day with the calorie goal, or nil if the day's foods have
+// neither.
+func carbSplitOn(tx *sqlx.Tx, date time.Time, goal float64) (*CarbSplit, error) {
+	splits, err := carbSplits(tx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := splits[dateOf(date).Format(dateFormat)]
+	if !ok {
+		return nil, nil
+	}
+	c.FiberMin, c.SugarMax = fiberMin(goal), sugarMax(goal)
+	return &c, nil
+}
+
+// FiberWeek is the fiber and sugar of a week's logged days with fiber
+// or sugar data.
+type FiberWeek struct {
+	Days      int
+	Fiber     float64 // Average fiber (g) a day.
+	FiberMin  float64 // Average fiber minimum (g).
+	Sugar     float64 // Average sugar (g) a day.
+	LowFiber  int     // Days short of the fiber minimum.
+	HighSugar int     // Days over the sugar maximum.
+}
+
+// Chronic reports whether the week fell short of the fiber minimum on
+// most of its days.
+func (w FiberWeek) Chronic() bool {
+	return w.Days > 0 && float64(w.LowFiber) > fiberShortDays*float64(w.Days)
+}
+
+// fiberWeek sums up the fiber and sugar of the days.
+func fiberWeek(days []CarbSplit) FiberWeek {
+	var w FiberWeek
+	for _, c := range days {
+		w.Days++
+		w.Fiber += c.Fiber
+		w.FiberMin += c.FiberMin
+		w.Sugar += c.Sugar
+		if c.LowFiber() {
+			w.LowFiber++
+		}
+		if c.HighSugar() {
+			w.HighSugar++
+		}
+	}
+	if w.Days > 0 {
+		w.Fiber /= float64(w.Days)
+		w.FiberMin /= float64(w.Days)
+		w.Sugar /= float64(w.Days)
+	}
+	return w
+}
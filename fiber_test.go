@@ -0,0 +1,72 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleCarbSplit() {
+	db := dbtest.MustNew(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1079, 'Fiber, total dietary', 'G'), (2000, 'Sugars, total including NLEA', 'G');
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1079, 10, 71), (1, 2000, 1, 71), (3, 1079, 2.6, 71), (3, 2000, 12, 71);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, '2024-03-18', '08:00:00', 40, 2, 311, 10.4, 5.6, 54.4),
+			(3, '2024-03-18', '10:00:00', 118, 1, 105, 1.3, 0.4, 27),
+			(2, '2024-03-19', '12:00:00', 100, 2, 330, 62, 7.2, 0);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	// Chicken breast has no fiber or sugar data, so the second day has
+	// none rather than zero.
+	days := make([]CarbSplit, 0, 2)
+	for _, d := range []time.Time{
+		time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 19, 0, 0, 0, 0, time.UTC),
+	} {
+		c, err := carbSplitOn(tx, d, 2000)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if c == nil {
+			fmt.Println(d.Format(dateFormat), "no data")
+			continue
+		}
+		days = append(days, *c)
+		r := &DayReport{
+			Date:      d,
+			Nutrition: Nutrition{Calories: 416, Protein: 11.7, Fat: 6, Carbs: 81.4},
+			Goal:      Nutrition{Calories: 2000, Protein: 150, Fat: 70, Carbs: 200},
+			CarbSplit: c,
+		}
+		if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	w := fiberWeek(days)
+	fmt.Printf("%d days, %.1fg fiber, chronic %v\n", w.Days, w.Fiber, w.Chronic())
+
+	// Output:
+	// Protein:  [▒▒▒▒▒▒▒▒▒▒]   8% (12g / 150g)
+	// Fat:      [▒▒▒▒▒▒▒▒▒▒]   9% (6g / 70g)
+	// Carbs:    [████▒▒▒▒▒▒]  41% (81g / 200g)
+	// Calories: [██▒▒▒▒▒▒▒▒]  21% (416 / 2000)
+	// Macros:   P 11% / C 76% / F 13% of calories
+	//
+	// 1584.00 calories remaining.
+	// Eaten $0.00 worth of food today.
+	// Fiber:    [███▒▒▒▒▒▒▒]  40% (11g / 28g)
+	// Sugar:    [██▒▒▒▒▒▒▒▒]  30% (15g / 50g)
+	// Fiber is 17g short of the 28g minimum.
+	// 2024-03-19 no data
+	// 1 days, 11.1g fiber, chronic true
+}
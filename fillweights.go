@@ -0,0 +1,133 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// excludeEstimatesSetting is the setting that, when "true", leaves
+// estimated weights out of the diet checks.
+const excludeEstimatesSetting = "weights.exclude_estimates"
+
+// addWeightColumns adds the estimated and log stamp columns to weight
+// logs created before they existed.
+func addWeightColumns(tx *sqlx.Tx) error {
+	return addColumns(tx, "daily_weights", "estimated INTEGER DEFAULT 0 NOT NULL",
+		"logged_at TEXT DEFAULT '' NOT NULL", "utc_offset INTEGER DEFAULT 0 NOT NULL")
+}
+
+// interpolateWeights returns an estimated weight for each day missing
+// between the given logged weights, which must be ordered by date. The
+// estimates lie on the straight line between the logged weights on
+// either side of the gap.
+func interpolateWeights(logged []WeightEntry) []WeightEntry {
+	var estimates []WeightEntry
+	for i := 1; i < len(logged); i++ {
+		prev, next := logged[i-1], logged[i]
+		days := int(dateOf(next.Date).Sub(dateOf(prev.Date)).Hours() / 24)
+		for d := 1; d < days; d++ {
+			w := prev.Weight + (next.Weight-prev.Weight)*float64(d)/float64(days)
+			estimates = append(estimates, WeightEntry{
+				Date:   dateOf(prev.Date).AddDate(0, 0, d),
+				Weight: w,
+			})
+		}
+	}
+	return estimates
+}
+
+// FillWeights estimates the weights missing between the logged weights
+// from start to end, inclusive, and saves them flagged as estimated. It
+// returns the number of weights added. Earlier estimates in the range
+// are replaced.
+func FillWeights(tx *sqlx.Tx, start, end time.Time) (int, error) {
+	if err := addWeightColumns(tx); err != nil {
+		return 0, err
+	}
+
+	const remove = `
+		DELETE FROM daily_weights
+		WHERE estimated = 1 AND date BETWEEN $1 AND $2
+	`
+	if _, err := tx.Exec(remove, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return 0, fmt.Errorf("couldn't remove estimated weights: %v", err)
+	}
+
+	const query = `
+		SELECT id, date, weight FROM daily_weights
+		WHERE date BETWEEN $1 AND $2
+		ORDER BY date
+	`
+	var logged []WeightEntry
+	if err := tx.Select(&logged, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return 0, fmt.Errorf("couldn't get logged weights: %v", err)
+	}
+
+	const insert = `
+		INSERT INTO daily_weights (date, time, weight, estimated)
+		VALUES ($1, $2, $3, 1)
+	`
+	estimates := interpolateWeights(logged)
+	for _, w := range estimates {
+		if _, err := tx.Exec(insert, w.Date.Format(dateFormat), w.Date.Format(dateFormatTime), w.Weight); err != nil {
+			return 0, fmt.Errorf("couldn't save estimated weight: %v", err)
+		}
+	}
+	return len(estimates), nil
+}
+
+// MarkEstimatedWeights sets Estimated on the entries whose weight was
+// estimated by FillWeights.
+func MarkEstimatedWeights(db *sqlx.DB, entries *[]Entry) error {
+	var cols []string
+	if err := db.Select(&cols, `SELECT name FROM pragma_table_info('daily_weights')`); err != nil {
+		return fmt.Errorf("couldn't get daily_weights columns: %v", err)
+	}
+	if !contains(cols, "estimated") {
+		// No weights have been estimated yet.
+		return nil
+	}
+
+	var dates []string
+	const query = `SELECT CAST(date AS TEXT) FROM daily_weights WHERE estimated = 1`
+	if err := db.Select(&dates, query); err != nil {
+		return fmt.Errorf("couldn't get estimated weights: %v", err)
+	}
+
+	days := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		days[d] = true
+	}
+	for i := range *entries {
+		e := &(*entries)[i]
+		e.Estimated = days[e.Date.Format(dateFormat)]
+	}
+	return nil
+}
+
+// ExcludeEstimates reports whether estimated weights are left out of
+// the diet checks.
+func ExcludeEstimates(db *sqlx.DB) (bool, error) {
+	v, _, err := Setting(db, excludeEstimatesSetting)
+	return v == "true", err
+}
+
+// SetExcludeEstimates sets whether estimated weights are left out of
+// the diet checks.
+func SetExcludeEstimates(tx *sqlx.Tx, exclude bool) error {
+	return SetSetting(tx, excludeEstimatesSetting, fmt.Sprint(exclude))
+}
+
+// WithoutEstimates returns the entries whose weight was logged rather
+// than estimated.
+func WithoutEstimates(entries *[]Entry) *[]Entry {
+	logged := []Entry{}
+	for _, e := range *entries {
+		if !e.Estimated {
+			logged = append(logged, e)
+		}
+	}
+	return &logged
+}
@@ -0,0 +1,63 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleFillWeights() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '07:00:00', 180),
+			('2024-01-04', '07:00:00', 177),
+			('2024-01-05', '07:00:00', 178);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	n, err := FillWeights(tx, start, start.AddDate(0, 0, 6))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// Logging a weight replaces the estimate.
+	if err := addWeightEntry(tx, start.AddDate(0, 0, 2), 178.5); err != nil {
+		fmt.Println(err)
+		return
+	}
+	tx.Commit()
+	fmt.Println("Estimated:", n)
+
+	entries := []Entry{}
+	for i := 0; i < 5; i++ {
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i)})
+	}
+	if err := MarkEstimatedWeights(db, &entries); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Logged:", len(*WithoutEstimates(&entries)))
+
+	var rows []struct {
+		Date      string  `db:"date"`
+		Weight    float64 `db:"weight"`
+		Estimated bool    `db:"estimated"`
+	}
+	db.Select(&rows, `SELECT CAST(date AS TEXT) AS date, weight, estimated FROM daily_weights ORDER BY date`)
+	for _, r := range rows {
+		fmt.Println(r.Date, r.Weight, r.Estimated)
+	}
+
+	// Output:
+	// Successfully added weight entry.
+	// Estimated: 2
+	// Logged: 4
+	// 2024-01-01 180 false
+	// 2024-01-02 179 true
+	// 2024-01-03 178.5 false
+	// 2024-01-04 177 false
+	// 2024-01-05 178 false
+}
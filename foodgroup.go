@@ -0,0 +1,157 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// untagged is the group of foods that haven't been tagged with a group.
+const untagged = "Untagged"
+
+// GroupTotals is the calories, macros, and cost of the foods of a food
+// group eaten over some period.
+type GroupTotals struct {
+	Group    string  `db:"group_name"`
+	Calories float64 `db:"calories"`
+	Protein  float64 `db:"protein"`
+	Fat      float64 `db:"fat"`
+	Carbs    float64 `db:"carbs"`
+	Cost     float64 `db:"cost"`
+}
+
+// SetFoodGroup puts a food in a group, such as a restaurant or "Home
+// cooking". A food belongs to at most one group. An empty group removes
+// the food from its group.
+func SetFoodGroup(tx *sqlx.Tx, foodID int, group string) error {
+	group = strings.TrimSpace(group)
+	if group == "" {
+		if _, err := tx.Exec(`DELETE FROM food_groups WHERE food_id = $1`, foodID); err != nil {
+			return fmt.Errorf("couldn't remove food group: %v", err)
+		}
+		return nil
+	}
+
+	const query = `
+		INSERT INTO food_groups (food_id, group_name)
+		VALUES ($1, $2)
+		ON CONFLICT(food_id) DO UPDATE SET group_name = $2
+	`
+	if _, err := tx.Exec(query, foodID, group); err != nil {
+		return fmt.Errorf("couldn't set food group: %v", err)
+	}
+	return nil
+}
+
+// TagFoods lets the user select foods and puts them in the given group.
+func TagFoods(db *sqlx.DB, group string) error {
+	if strings.TrimSpace(group) == "" {
+		return errors.New("group name can't be empty")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n := 0
+	for {
+		food, err := selectFood(db)
+		if err != nil {
+			if errors.Is(err, ErrDone) {
+				break
+			}
+			return err
+		}
+		if err := SetFoodGroup(tx, food.ID, group); err != nil {
+			return err
+		}
+		fmt.Printf("Tagged %q as %q.\n", food.Name, group)
+		n++
+	}
+
+	if n == 0 {
+		fmt.Println("No food selected.")
+		return nil
+	}
+	return tx.Commit()
+}
+
+// GroupTotalsBetween returns the totals of each food group for the food
+// entries from start up to, but not including, end, largest calories
+// first. Foods without a group are totaled as "Untagged".
+func GroupTotalsBetween(db *sqlx.DB, start, end time.Time) ([]GroupTotals, error) {
+	const query = `
+		SELECT COALESCE(g.group_name, $1) AS group_name,
+			SUM(df.calories) AS calories,
+			SUM(df.protein) AS protein,
+			SUM(df.fat) AS fat,
+			SUM(df.carbs) AS carbs,
+			SUM(COALESCE(df.price, 0)) AS cost
+		FROM daily_foods df
+		LEFT JOIN food_groups g ON g.food_id = df.food_id
+		WHERE df.date >= $2 AND df.date < $3
+		GROUP BY 1
+		ORDER BY calories DESC
+	`
+	var totals []GroupTotals
+	if err := db.Select(&totals, query, untagged, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get food group totals: %v", err)
+	}
+	return totals, nil
+}
+
+// GroupSummary prints the calories, macros, and cost of each food group
+// for each of the last given number of weeks, starting on WeekStart,
+// each group's share of the week's calories, and the week's tag cloud.
+func GroupSummary(db *sqlx.DB, weeks int, now time.Time) error {
+	if weeks < 1 {
+		return fmt.Errorf("weeks must be at least 1, got %d", weeks)
+	}
+
+	start := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	for w := start; !w.After(now); w = w.AddDate(0, 0, 7) {
+		totals, err := GroupTotalsBetween(db, w, w.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(paint(colorUnderline, "Week of "+w.Format(dateFormat)))
+		if len(totals) == 0 {
+			fmt.Println("No foods logged.")
+			fmt.Println()
+			continue
+		}
+		printGroupTotals(totals)
+
+		tags, err := TagCloud(db, w, w.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+		printTagCloud(tags)
+		fmt.Println()
+	}
+	return nil
+}
+
+// printGroupTotals prints a table of food group totals.
+func printGroupTotals(totals []GroupTotals) {
+	var cals float64
+	for _, t := range totals {
+		cals += t.Calories
+	}
+
+	fmt.Printf("%-20s %-10s %-8s %-10s %-10s %-8s %-8s\n", "Group", "Calories", "Share", "Protein", "Carbs", "Fat", "Cost")
+	for _, t := range totals {
+		share := 0.0
+		if cals > 0 {
+			share = t.Calories / cals * 100
+		}
+		fmt.Printf("%-20s %-10.0f %-8s %-10s %-10s %-8s $%-8.2f\n", t.Group, t.Calories,
+			fmt.Sprintf("%.0f%%", share), FormatMacro(t.Protein), FormatMacro(t.Carbs), FormatMacro(t.Fat), t.Cost)
+	}
+}
@@ -0,0 +1,65 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleGroupTotalsBetween() {
+	db := dbtest.MustNew(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(4, 'Apple', 182, 'g', '1 medium');
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	if err := SetFoodGroup(tx, 1, "Chipotle"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetFoodGroup(tx, 2, "Home cooking"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetFoodGroup(tx, 3, "Chipotle"); err != nil {
+		log.Println(err)
+		return
+	}
+	// Tagging a food again moves it to the new group.
+	if err := SetFoodGroup(tx, 3, "Home cooking"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	db.MustExec(`INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs, price) VALUES
+		(1, '2024-01-01', '12:00:00', 40, 1000, 50, 40, 110, 12.5),
+		(2, '2024-01-02', '12:00:00', 100, 600, 45, 20, 60, 3),
+		(3, '2024-01-03', '12:00:00', 118, 300, 10, 5, 50, 1),
+		(4, '2024-01-03', '15:00:00', 182, 100, 0, 0, 25, 0),
+		(1, '2024-01-08', '12:00:00', 40, 1000, 50, 40, 110, 12.5)`)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := GroupTotalsBetween(db, start, start.AddDate(0, 0, 7))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, t := range totals {
+		fmt.Println(t.Group, t.Calories, t.Protein, t.Carbs, t.Fat, t.Cost)
+	}
+
+	fmt.Println(startOfWeek(time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)).Format(dateFormat))
+
+	// Output:
+	// Chipotle 1000 50 110 40 12.5
+	// Home cooking 900 55 110 25 4
+	// Untagged 100 0 25 0 0
+	// 2024-01-01
+}
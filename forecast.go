@@ -0,0 +1,154 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// forecastDays is the number of recent days of weights the forecast
+	// is fit to, so that it follows the current intake.
+	forecastDays = 28
+
+	// forecastWeeks is the number of weeks ahead weight is forecast.
+	forecastWeeks = 4
+
+	// forecastMinWeights is the fewest weights a forecast is fit to.
+	forecastMinWeights = 7
+
+	// forecastZ is the number of standard errors either side of a
+	// forecast that make up its 95% band.
+	forecastZ = 1.96
+)
+
+// WeightModel is a straight line fit by least squares to the recent
+// weights, which forecasts weight as long as intake stays as it was.
+type WeightModel struct {
+	Last  time.Time // Date of the last weight fit.
+	Slope float64   // Change in weight a day.
+
+	origin    time.Time // Date of the first weight fit.
+	intercept float64
+	n         int
+	meanX     float64 // Mean days since origin.
+	sxx       float64 // Sum of squared deviations of days from meanX.
+	se        float64 // Standard error of the residuals.
+}
+
+// Forecast is the weight forecast on a date, with the band it falls in
+// 95% of the time.
+type Forecast struct {
+	Date   time.Time
+	Weight float64
+	Low    float64
+	High   float64
+}
+
+// String returns the forecast as its date, weight, and band.
+func (f Forecast) String() string {
+	return fmt.Sprintf("%s: %s (%s to %s)", f.Date.Format(dateFormat),
+		FormatWeight(f.Weight), FormatWeight(f.Low), FormatWeight(f.High))
+}
+
+// FitWeight fits a WeightModel to the weights of the entries logged in
+// the forecastDays days up to the last one. It returns false if fewer
+// than forecastMinWeights weights were logged or they span less than a
+// week.
+func FitWeight(entries []Entry) (WeightModel, bool) {
+	m := WeightModel{}
+	if len(entries) == 0 {
+		return m, false
+	}
+	m.Last = dateOf(entries[len(entries)-1].Date)
+	from := m.Last.AddDate(0, 0, -forecastDays+1)
+
+	var xs, ys []float64
+	for _, e := range entries {
+		if e.UserWeight == 0 || dateOf(e.Date).Before(from) {
+			continue
+		}
+		if m.origin.IsZero() {
+			m.origin = dateOf(e.Date)
+		}
+		xs = append(xs, daysBetween(m.origin, e.Date))
+		ys = append(ys, e.UserWeight)
+	}
+	m.n = len(xs)
+	if m.n < forecastMinWeights || xs[m.n-1] < 7 {
+		return m, false
+	}
+
+	m.Slope, m.intercept, m.meanX, m.sxx = leastSquares(xs, ys)
+
+	var sse float64
+	for i := range xs {
+		r := ys[i] - (m.intercept + m.Slope*xs[i])
+		sse += r * r
+	}
+	m.se = math.Sqrt(sse / float64(m.n-2))
+	return m, true
+}
+
+// leastSquares fits a line to the points by least squares and returns
+// its slope and intercept, the mean of xs, and the sum of the squared
+// deviations of xs from it.
+func leastSquares(xs, ys []float64) (slope, intercept, meanX, sxx float64) {
+	var meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(xs))
+	var sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	slope = sxy / sxx
+	return slope, meanY - slope*meanX, meanX, sxx
+}
+
+// daysBetween returns the number of days from the day of a to the day
+// of b.
+func daysBetween(a, b time.Time) float64 {
+	return math.Round(dateOf(b).Sub(dateOf(a)).Hours() / 24)
+}
+
+// At returns the forecast weight on the date. The band widens the
+// further the date is from the weights the model was fit to.
+func (m WeightModel) At(date time.Time) Forecast {
+	x := daysBetween(m.origin, date)
+	w := m.intercept + m.Slope*x
+	band := forecastZ * m.se * math.Sqrt(1+1/float64(m.n)+(x-m.meanX)*(x-m.meanX)/m.sxx)
+	return Forecast{Date: dateOf(date), Weight: w, Low: w - band, High: w + band}
+}
+
+// Weeks returns the forecast for each of the forecastWeeks weeks after
+// the last weight.
+func (m WeightModel) Weeks() []Forecast {
+	fs := make([]Forecast, forecastWeeks)
+	for i := range fs {
+		fs[i] = m.At(m.Last.AddDate(0, 0, 7*(i+1)))
+	}
+	return fs
+}
+
+// printForecast prints the weight forecast for the coming weeks and, for
+// a cut or bulk ending after the last weight, on the phase end date.
+func printForecast(u *UserInfo, entries []Entry) {
+	m, ok := FitWeight(entries)
+	if !ok {
+		return
+	}
+	fmt.Printf("Weight forecast at current intake (%+.2f lbs per week):\n", m.Slope*7)
+	for _, f := range m.Weeks() {
+		fmt.Println("  " + f.String())
+	}
+	if u.Phase.Name != "cut" && u.Phase.Name != "bulk" || !u.Phase.EndDate.After(m.Last) {
+		return
+	}
+	f := m.At(u.Phase.EndDate)
+	fmt.Printf("  %s (end date), goal %s\n", f, FormatGoalWeight(u.Phase))
+}
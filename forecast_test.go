@@ -0,0 +1,39 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleFitWeight() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Six weeks of losing a pound a week, with the usual swings.
+	swings := []float64{0.4, -0.3, 0.1, -0.5, 0.6, 0, -0.2}
+	var entries []Entry
+	for i := 0; i < 42; i++ {
+		w := 190 - float64(i)/7 + swings[i%len(swings)]
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: w})
+	}
+
+	m, ok := FitWeight(entries)
+	fmt.Println(ok, m.Last.Format(dateFormat), formatNumber(m.Slope*7, 2))
+	for _, f := range m.Weeks() {
+		fmt.Println(f)
+	}
+	// The band widens further out.
+	near, far := m.Weeks()[0], m.Weeks()[forecastWeeks-1]
+	fmt.Println(far.High-far.Low > near.High-near.Low)
+
+	// Too few weights to fit.
+	_, ok = FitWeight(entries[:5])
+	fmt.Println(ok)
+	// Output:
+	// true 2024-02-11 -1.01
+	// 2024-02-18: 183.1 (182.3 to 183.9)
+	// 2024-02-25: 182.1 (181.2 to 183.0)
+	// 2024-03-03: 181.1 (180.2 to 182.1)
+	// 2024-03-10: 180.1 (179.1 to 181.1)
+	// true
+	// false
+}
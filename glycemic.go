@@ -0,0 +1,207 @@
+package bite
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Daily glycemic loads at or above which a day's load is medium or
+// high.
+const (
+	glycemicLoadMedium = 80
+	glycemicLoadHigh   = 120
+)
+
+// GlycemicLoad is the approximate glycemic load of a day's food log,
+// from the foods with a glycemic index.
+type GlycemicLoad struct {
+	Load float64
+	// Carbs is the carbs (g) of the foods with a glycemic index, and
+	// Total the carbs of all the day's foods.
+	Carbs float64
+	Total float64
+}
+
+// Level returns "low", "medium", or "high" for the day's load.
+func (g GlycemicLoad) Level() string {
+	switch {
+	case g.Load >= glycemicLoadHigh:
+		return "high"
+	case g.Load >= glycemicLoadMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// Coverage returns the percentage of the day's carbs from foods with a
+// glycemic index.
+func (g GlycemicLoad) Coverage() float64 {
+	if g.Total == 0 {
+		return 0
+	}
+	return g.Carbs * 100 / g.Total
+}
+
+// addGlycemicColumn adds the glycemic_index column to food tables
+// created before it existed.
+func addGlycemicColumn(tx *sqlx.Tx) error {
+	return addColumns(tx, "foods", "glycemic_index REAL DEFAULT 0 NOT NULL")
+}
+
+// SetGlycemicIndex sets the glycemic index of a food. Zero removes it.
+func SetGlycemicIndex(tx *sqlx.Tx, foodID int, gi float64) error {
+	if gi < 0 || gi > 150 {
+		return fmt.Errorf("glycemic index must be between 0 and 150, got %v", gi)
+	}
+	if err := addGlycemicColumn(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE foods SET glycemic_index = $1 WHERE food_id = $2`, gi, foodID); err != nil {
+		return fmt.Errorf("couldn't set glycemic index: %v", err)
+	}
+	return nil
+}
+
+// FlagGlycemicIndex lets the user select a food and sets its glycemic
+// index.
+func FlagGlycemicIndex(db *sqlx.DB, gi float64) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := SetGlycemicIndex(tx, food.ID, gi); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if gi == 0 {
+		fmt.Printf("%s no longer has a glycemic index.\n", food.Name)
+		return nil
+	}
+	fmt.Printf("%s has a glycemic index of %s.\n", food.Name, formatNumber(gi, 0))
+	return nil
+}
+
+// GlycemicImport is the result of importing glycemic indexes.
+type GlycemicImport struct {
+	Set       int
+	Unmatched []string // Foods of rows that matched no food.
+}
+
+// ImportGlycemicIndexes sets the glycemic indexes of foods from CSV
+// rows of a food and its glycemic index, such as "12,55" or "Banana,51".
+// A food is its id or its exact name, and a first row that isn't a
+// glycemic index is taken as a header.
+func ImportGlycemicIndexes(tx *sqlx.Tx, r io.Reader) (GlycemicImport, error) {
+	var res GlycemicImport
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("couldn't read glycemic index file: %v", err)
+		}
+		if len(rec) < 2 || strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		food := strings.TrimSpace(rec[0])
+		gi, err := strconv.ParseFloat(strings.TrimSpace(rec[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue // Header.
+			}
+			return res, fmt.Errorf("line %d: invalid glycemic index %q", line, rec[1])
+		}
+
+		var ids []int
+		const query = `SELECT food_id FROM foods WHERE CAST(food_id AS TEXT) = $1 OR food_name = $1 COLLATE NOCASE`
+		if err := tx.Select(&ids, query, food); err != nil {
+			return res, fmt.Errorf("line %d: couldn't find %s: %v", line, food, err)
+		}
+		if len(ids) == 0 {
+			res.Unmatched = append(res.Unmatched, food)
+			continue
+		}
+		for _, id := range ids {
+			if err := SetGlycemicIndex(tx, id, gi); err != nil {
+				return res, fmt.Errorf("line %d: %v", line, err)
+			}
+			res.Set++
+		}
+	}
+	return res, nil
+}
+
+// glycemicLoads returns the glycemic load of each day from from to to,
+// inclusive, keyed by date, with only the carbs of the foods with a
+// glycemic index. Days without such foods are left out. A food's load
+// is its glycemic index times its available carbs, net of fiber, over
+// 100.
+func glycemicLoads(tx *sqlx.Tx, from, to time.Time) (map[string]GlycemicLoad, error) {
+	if err := addGlycemicColumn(tx); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT CAST(df.date AS TEXT), SUM(f.glycemic_index * MAX(df.carbs - COALESCE((
+				SELECT fn.amount FROM food_nutrients fn
+				WHERE fn.food_id = df.food_id AND fn.nutrient_id = %d LIMIT 1
+			), 0) * df.serving_size * df.number_of_servings / %d, 0)) / 100,
+			SUM(df.carbs)
+		FROM daily_foods df
+		INNER JOIN foods f ON df.food_id = f.food_id
+		WHERE df.date BETWEEN $1 AND $2 AND f.glycemic_index > 0
+		GROUP BY df.date
+	`, fiberNutrientID, PortionSize)
+	rows, err := tx.Query(query, dateOf(from).Format(dateFormat), dateOf(to).Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get glycemic load: %v", err)
+	}
+	defer rows.Close()
+
+	loads := make(map[string]GlycemicLoad)
+	for rows.Next() {
+		var date string
+		var g GlycemicLoad
+		if err := rows.Scan(&date, &g.Load, &g.Carbs); err != nil {
+			return nil, fmt.Errorf("couldn't get glycemic load: %v", err)
+		}
+		loads[date] = g
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't get glycemic load: %v", err)
+	}
+	return loads, nil
+}
+
+// glycemicLoadOn returns the glycemic load of the day with the given
+// carbs, or nil if none of the day's foods has a glycemic index.
+func glycemicLoadOn(tx *sqlx.Tx, date time.Time, carbs float64) (*GlycemicLoad, error) {
+	loads, err := glycemicLoads(tx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := loads[dateOf(date).Format(dateFormat)]
+	if !ok {
+		return nil, nil
+	}
+	g.Total = carbs
+	return &g, nil
+}
@@ -0,0 +1,57 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleImportGlycemicIndexes() {
+	db := dbtest.MustNew(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES (1079, 'Fiber, total dietary', 'G');
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES (1, 1079, 10, 71), (3, 1079, 2.6, 71);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, '2024-03-18', '08:00:00', 40, 2, 311, 10.4, 5.6, 54.4),
+			(3, '2024-03-18', '10:00:00', 118, 1, 105, 1.3, 0.4, 27),
+			(2, '2024-03-18', '12:00:00', 100, 2, 330, 62, 7.2, 0);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	res, err := ImportGlycemicIndexes(tx, strings.NewReader("food,gi\n1,55\nbanana,51\nWhite bread,75\n"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(res.Set, res.Unmatched)
+
+	// Oats have 46.4g of available carbs and a banana 23.9g.
+	day := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	r := &DayReport{
+		Date:      day,
+		Nutrition: Nutrition{Calories: 746, Protein: 73.7, Fat: 13.2, Carbs: 81.4},
+		Goal:      Nutrition{Calories: 2000, Protein: 150, Fat: 70, Carbs: 200},
+	}
+	if r.GlycemicLoad, err = glycemicLoadOn(tx, day, r.Carbs); err != nil {
+		log.Fatal(err)
+	}
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// 2 [White bread]
+	// Protein:  [████▒▒▒▒▒▒]  49% (74g / 150g)
+	// Fat:      [█▒▒▒▒▒▒▒▒▒]  19% (13g / 70g)
+	// Carbs:    [████▒▒▒▒▒▒]  41% (81g / 200g)
+	// Calories: [███▒▒▒▒▒▒▒]  37% (746 / 2000)
+	// Macros:   P 40% / C 44% / F 16% of calories
+	//
+	// 1254.00 calories remaining.
+	// Eaten $0.00 worth of food today.
+	// Glycemic load: 38 (low), from 100% of the day's carbs.
+}
@@ -3,9 +3,11 @@ module github.com/ericstrs/bite
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/gdamore/tcell/v2 v2.6.0
 	github.com/jmoiron/sqlx v1.3.5
 	github.com/rivo/tview v0.0.0-20231126152417-33a1d271f2b6
+	golang.org/x/term v0.5.0
 	modernc.org/sqlite v1.24.0
 )
 
@@ -21,7 +23,6 @@ require (
 	github.com/rivo/uniseg v0.4.3 // indirect
 	golang.org/x/mod v0.6.0-dev.0.20220419223038-86c51ed26bb4 // indirect
 	golang.org/x/sys v0.5.0 // indirect
-	golang.org/x/term v0.5.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
 	golang.org/x/tools v0.1.12 // indirect
 	lukechampine.com/uint128 v1.2.0 // indirect
@@ -0,0 +1,245 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// healthDays is the number of days checked for logging gaps when there
+// is no active diet phase.
+const healthDays = 28
+
+// healthExamples is the most problems of a check that are printed.
+const healthExamples = 5
+
+// HealthCheck is a check of the quality of the data, the problems it
+// found, and the command that fixes them.
+type HealthCheck struct {
+	Name     string
+	Problems []string
+	Fix      string
+}
+
+// Health runs the data quality checks. Logging gaps are looked for over
+// the active diet phase, or the last healthDays days without one, up to
+// the day before now.
+func Health(tx *sqlx.Tx, u *UserInfo, now time.Time) ([]HealthCheck, error) {
+	to := dateOf(now).AddDate(0, 0, -1)
+	from := dateOf(now).AddDate(0, 0, -healthDays)
+	if u.Phase.Status == "active" {
+		from = dateOf(u.Phase.StartDate)
+	}
+
+	weights, err := loggedDates(tx, `SELECT DISTINCT CAST(date AS TEXT) FROM daily_weights WHERE date BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	foods, err := loggedDates(tx, `SELECT DISTINCT CAST(date AS TEXT) FROM daily_foods WHERE date BETWEEN $1 AND $2`, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []HealthCheck{
+		{
+			Name:     "Days without a weight",
+			Problems: loggingGaps(weights, from, to),
+			Fix:      "bite log weight, or bite log fill-weights to estimate them",
+		},
+		{
+			Name:     "Days without food",
+			Problems: loggingGaps(foods, from, to),
+			Fix:      "bite log food",
+		},
+		{
+			Name:     fmt.Sprintf("Weeks with fewer than %d days of food and weight", minEntriesPerWeek),
+			Problems: sparseWeeks(weights, foods, from, to),
+			Fix:      "bite log weight and bite log food, since progress checks skip these weeks",
+		},
+	}
+
+	queries := []struct {
+		name, query, fix string
+	}{
+		{
+			name: "Foods missing macros",
+			query: `
+				SELECT f.food_name || ' (' || f.food_id || ')' FROM foods f
+				WHERE (f.food_id IN (SELECT food_id FROM daily_foods)
+					OR f.food_id IN (SELECT food_id FROM meal_foods))
+					AND (SELECT COUNT(DISTINCT nutrient_id) FROM food_nutrients
+						WHERE food_id = f.food_id AND nutrient_id IN (1003, 1004, 1005, 1008)) < 4
+				ORDER BY f.food_name
+			`,
+			fix: "bite update food",
+		},
+		{
+			name: "Orphaned serving preferences",
+			query: `
+				SELECT 'food ' || food_id FROM food_prefs
+				WHERE food_id NOT IN (SELECT food_id FROM foods)
+				UNION ALL
+				SELECT 'food ' || food_id || ' of meal ' || meal_id FROM meal_food_prefs
+				WHERE (meal_id, food_id) NOT IN (SELECT meal_id, food_id FROM meal_foods)
+			`,
+			fix: "bite db health --fix",
+		},
+		{
+			name: "Food entries of deleted foods",
+			query: `
+				SELECT CAST(date AS TEXT) || ' food ' || food_id FROM daily_foods
+				WHERE food_id NOT IN (SELECT food_id FROM foods)
+				ORDER BY date
+			`,
+			fix: "bite log delete food",
+		},
+	}
+	for _, q := range queries {
+		var problems []string
+		if err := tx.Select(&problems, q.query); err != nil {
+			return nil, fmt.Errorf("couldn't check %s: %v", strings.ToLower(q.name), err)
+		}
+		checks = append(checks, HealthCheck{Name: q.name, Problems: problems, Fix: q.fix})
+	}
+
+	outliers, err := WeightOutliers(tx)
+	if err != nil {
+		return nil, err
+	}
+	c := HealthCheck{Name: "Implausible weights", Fix: "bite db outliers"}
+	for _, o := range outliers {
+		c.Problems = append(c.Problems, o.String())
+	}
+	checks = append(checks, c)
+
+	totals, err := dailyTotals(tx)
+	if err != nil {
+		return nil, err
+	}
+	c = HealthCheck{Name: "Out of date daily totals", Fix: "bite db rollup"}
+	if totals != "daily_rollups" {
+		c.Problems = []string{"the daily totals don't account for every food entry"}
+	}
+	checks = append(checks, c)
+
+	var indexes int
+	const indexesSQL = `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'index' AND name IN ('idx_daily_foods_date', 'idx_daily_foods_food_date', 'idx_daily_weights_date')
+	`
+	if err := tx.Get(&indexes, indexesSQL); err != nil {
+		return nil, fmt.Errorf("couldn't look up indexes: %v", err)
+	}
+	c = HealthCheck{Name: "Missing log indexes", Fix: "bite db index"}
+	if indexes < 3 {
+		c.Problems = []string{fmt.Sprintf("%d of 3 indexes are missing", 3-indexes)}
+	}
+	checks = append(checks, c)
+	return checks, nil
+}
+
+// loggedDates returns the set of dates the query returns for the dates
+// from from to to.
+func loggedDates(tx *sqlx.Tx, query string, from, to time.Time) (map[string]bool, error) {
+	var dates []string
+	if err := tx.Select(&dates, query, from.Format(dateFormat), to.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get logged dates: %v", err)
+	}
+	set := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		set[d] = true
+	}
+	return set, nil
+}
+
+// loggingGaps returns the runs of days from from to to without a date
+// in logged, such as "2024-03-04 to 2024-03-06 (3 days)".
+func loggingGaps(logged map[string]bool, from, to time.Time) []string {
+	var gaps []string
+	var start time.Time
+	for d := from; !d.After(to.AddDate(0, 0, 1)); d = d.AddDate(0, 0, 1) {
+		missing := !d.After(to) && !logged[d.Format(dateFormat)]
+		switch {
+		case missing && start.IsZero():
+			start = d
+		case !missing && !start.IsZero():
+			end := d.AddDate(0, 0, -1)
+			if n := int(daysBetween(start, end)) + 1; n == 1 {
+				gaps = append(gaps, start.Format(dateFormat))
+			} else {
+				gaps = append(gaps, fmt.Sprintf("%s to %s (%d days)", start.Format(dateFormat), end.Format(dateFormat), n))
+			}
+			start = time.Time{}
+		}
+	}
+	return gaps
+}
+
+// sparseWeeks returns the weeks, counted in 7 day steps from from, that
+// are over by to and have fewer than minEntriesPerWeek days with both a
+// weight and food.
+func sparseWeeks(weights, foods map[string]bool, from, to time.Time) []string {
+	var weeks []string
+	for start := from; !start.AddDate(0, 0, 6).After(to); start = start.AddDate(0, 0, 7) {
+		n := 0
+		for d := start; d.Before(start.AddDate(0, 0, 7)); d = d.AddDate(0, 0, 1) {
+			if s := d.Format(dateFormat); weights[s] && foods[s] {
+				n++
+			}
+		}
+		if n < minEntriesPerWeek {
+			weeks = append(weeks, fmt.Sprintf("week of %s (%d days)", start.Format(dateFormat), n))
+		}
+	}
+	return weeks
+}
+
+// PrintHealth prints each check and, for the checks that found
+// problems, the first healthExamples of them and the command that fixes
+// them.
+func PrintHealth(checks []HealthCheck) {
+	issues := 0
+	for _, c := range checks {
+		if len(c.Problems) == 0 {
+			fmt.Printf("%s: none\n", c.Name)
+			continue
+		}
+		issues++
+		fmt.Println(paint(colorMissed, fmt.Sprintf("%s: %d", c.Name, len(c.Problems))))
+		for i, p := range c.Problems {
+			if i == healthExamples {
+				fmt.Printf("  ...and %d more\n", len(c.Problems)-healthExamples)
+				break
+			}
+			fmt.Println("  " + p)
+		}
+		fmt.Println("  Fix: " + c.Fix)
+	}
+	if issues == 0 {
+		fmt.Println("No data issues found.")
+	}
+}
+
+// RemoveOrphanedPrefs removes the serving preferences of foods that
+// were deleted and of foods no longer in their meal. It returns the
+// number removed.
+func RemoveOrphanedPrefs(tx *sqlx.Tx) (int, error) {
+	n := 0
+	for _, query := range []string{
+		`DELETE FROM food_prefs WHERE food_id NOT IN (SELECT food_id FROM foods)`,
+		`DELETE FROM meal_food_prefs WHERE (meal_id, food_id) NOT IN (SELECT meal_id, food_id FROM meal_foods)`,
+	} {
+		res, err := tx.Exec(query)
+		if err != nil {
+			return n, fmt.Errorf("couldn't remove orphaned preferences: %v", err)
+		}
+		m, err := res.RowsAffected()
+		if err != nil {
+			return n, err
+		}
+		n += int(m)
+	}
+	return n, nil
+}
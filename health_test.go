@@ -0,0 +1,79 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleHealth() {
+	db := dbtest.MustNew(dbtest.User, `
+		-- Rows left behind before foreign keys were enforced.
+		PRAGMA foreign_keys = OFF;
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '07:00:00', 185.0), ('2024-01-02', '07:00:00', 184.6),
+			('2024-01-03', '07:00:00', 184.8), ('2024-01-08', '07:00:00', 184.1),
+			('2024-01-09', '07:00:00', 183.9), ('2024-01-10', '07:00:00', 184.2);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES
+			(1, '2024-01-01', '08:00:00', 40, 1, 156, 5, 3, 27),
+			(1, '2024-01-02', '08:00:00', 40, 1, 156, 5, 3, 27),
+			(1, '2024-01-03', '08:00:00', 40, 1, 156, 5, 3, 27),
+			(1, '2024-01-08', '08:00:00', 40, 1, 156, 5, 3, 27),
+			(4, '2024-01-09', '08:00:00', 100, 1, 120, 0, 0, 30);
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(5, 'Rice', 100, 'g', '');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Bowl');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 2), (1, 5);
+		INSERT INTO food_prefs (food_id, serving_size) VALUES (9, 50);
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size) VALUES (1, 2, 150), (1, 3, 118);
+	`)
+	defer db.Close()
+	defer func(c bool) { NoColor = c }(NoColor)
+	NoColor = true
+
+	u, err := Config(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	now := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+	checks, err := Health(tx, u, now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	PrintHealth(checks)
+
+	n, err := RemoveOrphanedPrefs(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+	// Output:
+	// Days without a weight: 2
+	//   2024-01-04 to 2024-01-07 (4 days)
+	//   2024-01-11 to 2024-01-15 (5 days)
+	//   Fix: bite log weight, or bite log fill-weights to estimate them
+	// Days without food: 2
+	//   2024-01-04 to 2024-01-07 (4 days)
+	//   2024-01-10 to 2024-01-15 (6 days)
+	//   Fix: bite log food
+	// Weeks with fewer than 2 days of food and weight: none
+	// Foods missing macros: 1
+	//   Rice (5)
+	//   Fix: bite update food
+	// Orphaned serving preferences: 2
+	//   food 9
+	//   food 3 of meal 1
+	//   Fix: bite db health --fix
+	// Food entries of deleted foods: 1
+	//   2024-01-09 food 4
+	//   Fix: bite log delete food
+	// Implausible weights: none
+	// Out of date daily totals: none
+	// Missing log indexes: none
+	// 2
+}
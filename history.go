@@ -0,0 +1,269 @@
+package bite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Run is a run of a command recorded in the audit log and the changes
+// it made.
+type Run struct {
+	ID      int64  // Id of its first change.
+	Time    string // In SyncTimeFormat.
+	Command string
+	User    string
+	Changes []AuditEntry
+}
+
+// History returns the last n runs of commands that changed the
+// database, newest first. Changes made before runs were recorded, or by
+// other programs, are left out.
+func History(db *sqlx.DB, n int) ([]Run, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := addColumns(tx, "audit_log", "run INTEGER DEFAULT 0 NOT NULL"); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT * FROM audit_log
+		WHERE run IN (
+			SELECT DISTINCT run FROM audit_log
+			WHERE run > 0
+			ORDER BY run DESC
+			LIMIT $1
+		)
+		ORDER BY run DESC, id
+	`
+	var entries []AuditEntry
+	if err := tx.Select(&entries, query, n); err != nil {
+		return nil, fmt.Errorf("couldn't read audit log: %v", err)
+	}
+
+	var runs []Run
+	for _, e := range entries {
+		if len(runs) == 0 || runs[len(runs)-1].ID != e.Run {
+			runs = append(runs, Run{ID: e.Run, Time: e.Time, Command: e.Command, User: e.User})
+		}
+		r := &runs[len(runs)-1]
+		r.Changes = append(r.Changes, e)
+	}
+	return runs, tx.Commit()
+}
+
+// WriteHistory writes the runs, one per line, each followed by how
+// many rows of each table it inserted, updated, and deleted. Times are
+// written in Location.
+func WriteHistory(w io.Writer, runs []Run) error {
+	if len(runs) == 0 {
+		_, err := fmt.Fprintln(w, "No commands recorded.")
+		return err
+	}
+	for _, r := range runs {
+		at := r.Time
+		if t, err := time.Parse(SyncTimeFormat, r.Time); err == nil {
+			at = inLocation(t).Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s  %s", at, r.Command)
+		if r.User != "" {
+			fmt.Fprintf(w, " (%s)", r.User)
+		}
+		fmt.Fprintln(w)
+
+		counts := make(map[string]int)
+		var keys []string
+		for _, c := range r.Changes {
+			k := c.Table + " " + strings.TrimSuffix(c.Action, "e") + "ed"
+			if counts[k] == 0 {
+				keys = append(keys, k)
+			}
+			counts[k]++
+		}
+		sort.Strings(keys)
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[k], k))
+		}
+		if _, err := fmt.Fprintf(w, "  %s\n", strings.Join(parts, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loggedFood is a food entry as recorded in the audit log.
+type loggedFood struct {
+	FoodID           int     `json:"food_id"`
+	MealID           *int    `json:"meal_id"`
+	ServingSize      float64 `json:"serving_size"`
+	NumberOfServings float64 `json:"number_of_servings"`
+	Calories         float64 `json:"calories"`
+	Protein          float64 `json:"protein"`
+	Fat              float64 `json:"fat"`
+	Carbs            float64 `json:"carbs"`
+	Price            float64 `json:"price"`
+}
+
+// foodLogCommands are the commands that log food, by the words that
+// name them after "bite". Other commands that add food entries, such as
+// a sync import, aren't repeated.
+var foodLogCommands = []string{"log food", "log meal", "log paste", "q", "redo"}
+
+// rootValueFlags are the flags of the bite command that take a value,
+// which can come before the name of the command run.
+var rootValueFlags = map[string]bool{"db": true, "units": true}
+
+// isFoodLogCommand reports whether the command line recorded in the
+// audit log, such as "bite --db bite.db q 2 eggs", ran one of the
+// foodLogCommands.
+func isFoodLogCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || fields[0] != "bite" {
+		return false
+	}
+	var words []string
+	for i := 1; i < len(fields); i++ {
+		f := fields[i]
+		if !strings.HasPrefix(f, "-") {
+			words = append(words, f)
+			continue
+		}
+		name := strings.TrimLeft(f, "-")
+		if len(words) == 0 && !strings.Contains(name, "=") && rootValueFlags[name] {
+			i++ // Skip the flag's value.
+		}
+	}
+	name := " " + strings.Join(words, " ") + " "
+	for _, c := range foodLogCommands {
+		if strings.HasPrefix(name, " "+c+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// lastFoodLog returns the last run of a command that logged food, or
+// nil if there isn't one.
+func lastFoodLog(tx *sqlx.Tx) (*Run, error) {
+	if err := addColumns(tx, "audit_log", "run INTEGER DEFAULT 0 NOT NULL"); err != nil {
+		return nil, err
+	}
+	var runs []struct {
+		Run     int64  `db:"run"`
+		Command string `db:"command"`
+	}
+	const runsQuery = `
+		SELECT run, MIN(command) AS command FROM audit_log
+		WHERE run > 0 AND table_name = 'daily_foods' AND action = 'insert'
+		GROUP BY run
+		ORDER BY run DESC
+	`
+	if err := tx.Select(&runs, runsQuery); err != nil {
+		return nil, fmt.Errorf("couldn't read audit log: %v", err)
+	}
+	for _, r := range runs {
+		if !isFoodLogCommand(r.Command) {
+			continue
+		}
+		var entries []AuditEntry
+		const query = `SELECT * FROM audit_log WHERE run = $1 ORDER BY id`
+		if err := tx.Select(&entries, query, r.Run); err != nil {
+			return nil, fmt.Errorf("couldn't read audit log: %v", err)
+		}
+		e := entries[0]
+		return &Run{ID: e.Run, Time: e.Time, Command: e.Command, User: e.User, Changes: entries}, nil
+	}
+	return nil, nil
+}
+
+// redoFoodLog logs the food entries the run inserted again at the given
+// date and time, along with the meals it logged, and returns the foods
+// logged.
+func redoFoodLog(tx *sqlx.Tx, r *Run, at time.Time) ([]Food, error) {
+	var foods []Food
+	for _, c := range r.Changes {
+		if c.Action != "insert" || c.After == nil {
+			continue
+		}
+		switch c.Table {
+		case "daily_meals":
+			var m struct {
+				MealID int `json:"meal_id"`
+			}
+			if err := json.Unmarshal([]byte(*c.After), &m); err != nil {
+				return nil, fmt.Errorf("couldn't decode audit log row: %v", err)
+			}
+			if err := AddMealEntry(tx, m.MealID, at); err != nil {
+				return nil, fmt.Errorf("couldn't add meal entry: %v", err)
+			}
+		case "daily_foods":
+			var l loggedFood
+			if err := json.Unmarshal([]byte(*c.After), &l); err != nil {
+				return nil, fmt.Errorf("couldn't decode audit log row: %v", err)
+			}
+			f := Food{
+				ID:               l.FoodID,
+				ServingSize:      l.ServingSize,
+				NumberOfServings: l.NumberOfServings,
+				Calories:         l.Calories,
+				FoodMacros:       &FoodMacros{Protein: l.Protein, Fat: l.Fat, Carbs: l.Carbs},
+				Price:            l.Price,
+			}
+			const query = `SELECT food_name, serving_unit FROM foods WHERE food_id = $1`
+			if err := tx.QueryRowx(query, f.ID).Scan(&f.Name, &f.ServingUnit); err != nil {
+				return nil, fmt.Errorf("couldn't get food %d: %v", f.ID, err)
+			}
+			if l.MealID == nil {
+				if err := AddFoodEntry(tx, &f, at); err != nil {
+					return nil, err
+				}
+			} else {
+				mf := MealFood{Food: f, ServingSize: f.ServingSize, NumberOfServings: f.NumberOfServings}
+				if err := AddMealFoodEntries(tx, *l.MealID, []MealFood{mf}, 1, at); err != nil {
+					return nil, err
+				}
+			}
+			foods = append(foods, f)
+		}
+	}
+	return foods, nil
+}
+
+// RedoFoodLog repeats the last command that logged food, such as the
+// same protein shake as yesterday, by logging its foods and meals again
+// at the given date and time.
+func RedoFoodLog(db *sqlx.DB, at time.Time) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	r, err := lastFoodLog(tx)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return errors.New("no logged food to repeat")
+	}
+	fmt.Printf("Repeating %s\n", r.Command)
+	foods, err := redoFoodLog(tx, r, at)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, f := range foods {
+		fmt.Printf("Logged %s at %s.\n", quickLabel(f), at.Format(dateFormat+" 15:04"))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package bite
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleRedoFoodLog() {
+	db := dbtest.MustNew()
+	defer db.Close()
+	if err := EnableAudit(db); err != nil {
+		log.Fatal(err)
+	}
+
+	// Log a shake of oats and a banana, then a cup of oats by itself.
+	logged := func(command string, foods ...Food) {
+		if err := AttributeChanges(db, command, "sam"); err != nil {
+			log.Fatal(err)
+		}
+		tx := db.MustBegin()
+		defer tx.Rollback()
+		date := time.Date(2024, 5, 2, 7, 30, 0, 0, time.UTC)
+		if err := AddFoodEntries(tx, foods, date); err != nil {
+			log.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	oats := Food{ID: 1, ServingSize: 40, NumberOfServings: 1, Calories: 156,
+		FoodMacros: &FoodMacros{Protein: 5.2, Fat: 2.8, Carbs: 27.2}, Price: 0.5}
+	banana := Food{ID: 3, ServingSize: 118, NumberOfServings: 1, Calories: 105,
+		FoodMacros: &FoodMacros{Protein: 1.3, Fat: 0.4, Carbs: 27.1}}
+	logged("bite q 40g oats", oats)
+	logged("bite log paste", oats, banana)
+
+	// The shake is logged again the next morning.
+	if err := AttributeChanges(db, "bite redo", "sam"); err != nil {
+		log.Fatal(err)
+	}
+	if err := RedoFoodLog(db, time.Date(2024, 5, 3, 7, 45, 0, 0, time.UTC)); err != nil {
+		log.Fatal(err)
+	}
+
+	runs, err := History(db, 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func(l *time.Location) { Location = l }(Location)
+	Location = time.FixedZone("EST", -5*60*60)
+	for i := range runs {
+		runs[i].Time = "2024-05-03T07:45:00.000Z"
+	}
+	if err := WriteHistory(os.Stdout, runs); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// Repeating bite log paste
+	// Logged 40 g Oats at 2024-05-03 07:45.
+	// Logged 118 g Banana at 2024-05-03 07:45.
+	// 2024-05-03 02:45:00  bite redo (sam)
+	//   2 daily_foods inserted
+	// 2024-05-03 02:45:00  bite log paste (sam)
+	//   2 daily_foods inserted
+}
+
+func ExampleRedoFoodLog_sync() {
+	db := dbtest.MustNew()
+	defer db.Close()
+	if err := EnableAudit(db); err != nil {
+		log.Fatal(err)
+	}
+	logged := func(command string, f Food) {
+		if err := AttributeChanges(db, command, "sam"); err != nil {
+			log.Fatal(err)
+		}
+		tx := db.MustBegin()
+		defer tx.Rollback()
+		if err := AddFoodEntry(tx, &f, time.Date(2024, 5, 2, 7, 30, 0, 0, time.UTC)); err != nil {
+			log.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	logged("bite --db bite.db q 40g oats", Food{ID: 1, ServingSize: 40, NumberOfServings: 1, Calories: 156,
+		FoodMacros: &FoodMacros{Protein: 5.2, Fat: 2.8, Carbs: 27.2}})
+
+	// Entries imported from another device aren't a food log to repeat.
+	logged("bite sync import laptop.json", Food{ID: 3, ServingSize: 118, NumberOfServings: 1, Calories: 105,
+		FoodMacros: &FoodMacros{Protein: 1.3, Fat: 0.4, Carbs: 27.1}})
+
+	if err := RedoFoodLog(db, time.Date(2024, 5, 3, 7, 45, 0, 0, time.UTC)); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// Repeating bite --db bite.db q 40g oats
+	// Logged 40 g Oats at 2024-05-03 07:45.
+}
@@ -0,0 +1,169 @@
+package bite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Events passed to hooks.
+const (
+	EventFoodLogged      = "food-logged"
+	EventWeightLogged    = "weight-logged"
+	EventPhaseTransition = "phase-transition"
+	EventWeightDrift     = "weight-drift"
+)
+
+// hookTimeout is how long a hook may run before it is killed.
+const hookTimeout = 30 * time.Second
+
+// HooksDir is the directory of the user's hooks. A hook is an
+// executable named after the event it runs after, such as
+// "food-logged". No hooks run when it is empty.
+var HooksDir = ""
+
+// Event is something that happened, passed to its hook as JSON on
+// standard input.
+type Event struct {
+	Name string      `json:"event"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// foodLogged is the data of a food-logged event.
+type foodLogged struct {
+	FoodID           int     `json:"food_id"`
+	Name             string  `json:"name"`
+	MealID           int     `json:"meal_id,omitempty"`
+	Date             string  `json:"date"`
+	Time             string  `json:"time"`
+	ServingSize      float64 `json:"serving_size"`
+	ServingUnit      string  `json:"serving_unit"`
+	NumberOfServings float64 `json:"number_of_servings"`
+	Calories         float64 `json:"calories"`
+	Protein          float64 `json:"protein"`
+	Fat              float64 `json:"fat"`
+	Carbs            float64 `json:"carbs"`
+	Price            float64 `json:"price"`
+}
+
+// weightLogged is the data of a weight-logged event.
+type weightLogged struct {
+	Date   string  `json:"date"`
+	Weight float64 `json:"weight"` // Pounds.
+}
+
+// phaseTransition is the data of a phase-transition event.
+type phaseTransition struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// events holds the events emitted since the hooks last ran.
+var events struct {
+	sync.Mutex
+	pending []Event
+}
+
+// emit queues an event for its hook. Events are emitted as changes are
+// made, inside transactions, so hooks only run once RunHooks is called
+// after the changes are committed. Nothing is queued during a dry run,
+// whose changes are never saved.
+func emit(name string, data interface{}) {
+	if HooksDir == "" || DryRunning {
+		return
+	}
+	events.Lock()
+	defer events.Unlock()
+	events.pending = append(events.pending, Event{Name: name, Time: Now(), Data: data})
+}
+
+// emitFoodLogged queues a food-logged event for the food entry.
+func emitFoodLogged(f *Food, mealID int, servingSize, servings float64, date time.Time) {
+	e := foodLogged{
+		FoodID:           f.ID,
+		Name:             f.Name,
+		MealID:           mealID,
+		Date:             date.Format(dateFormat),
+		Time:             date.Format(dateFormatTime),
+		ServingSize:      servingSize,
+		ServingUnit:      f.ServingUnit,
+		NumberOfServings: servings,
+		Calories:         f.Calories,
+		Price:            f.Price,
+	}
+	if f.FoodMacros != nil {
+		e.Protein, e.Fat, e.Carbs = f.FoodMacros.Protein, f.FoodMacros.Fat, f.FoodMacros.Carbs
+	}
+	emit(EventFoodLogged, e)
+}
+
+// DiscardEvents drops the events emitted since the hooks last ran. It
+// is called when the changes they describe were rolled back.
+func DiscardEvents() {
+	events.Lock()
+	defer events.Unlock()
+	events.pending = nil
+}
+
+// RunHooks runs the hook of each event emitted since it was last
+// called, in order, writing their output to w. Events without a hook
+// are skipped. A failing hook doesn't stop the others; the first error
+// is returned.
+func RunHooks(w io.Writer) error {
+	events.Lock()
+	pending := events.pending
+	events.pending = nil
+	events.Unlock()
+
+	var first error
+	for _, e := range pending {
+		if err := runHook(e, w); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// runHook runs the hook of the event with the event as JSON on
+// standard input.
+func runHook(e Event, w io.Writer) error {
+	path := filepath.Join(HooksDir, e.Name)
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't find %s hook: %v", e.Name, err)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return fmt.Errorf("%s hook %s isn't executable", e.Name, path)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("couldn't encode %s event: %v", e.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(append(data, '\n'))
+	cmd.Stdout = w
+	cmd.Stderr = w
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %v", e.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+//go:build unix
+
+package bite
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExampleRunHooks() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(d string) { HooksDir = d }(HooksDir)
+	HooksDir = dir
+
+	// The hook prints its event without the time it was emitted.
+	hook := "#!/bin/sh\nsed 's/\"time\":\"[^\"]*\",//'\n"
+	if err := os.WriteFile(filepath.Join(dir, EventWeightLogged), []byte(hook), 0755); err != nil {
+		log.Println(err)
+		return
+	}
+
+	db := dbtest.MustNew()
+	defer db.Close()
+	date := time.Date(2024, 1, 8, 7, 30, 0, 0, time.UTC)
+
+	// Events of changes that were rolled back are discarded.
+	tx := db.MustBegin()
+	if err := insertWeightEntry(tx, date, 183.2); err != nil {
+		log.Println(err)
+		return
+	}
+	tx.Rollback()
+	DiscardEvents()
+
+	tx = db.MustBegin()
+	if err := insertWeightEntry(tx, date, 183.6); err != nil {
+		log.Println(err)
+		return
+	}
+	// There is no food-logged hook, so the event is skipped.
+	emitFoodLogged(&Food{ID: 1, Name: "Oats"}, 0, 40, 1, date)
+	tx.Commit()
+
+	if err := RunHooks(os.Stdout); err != nil {
+		log.Println(err)
+	}
+	// Hooks only run once per event.
+	if err := RunHooks(os.Stdout); err != nil {
+		log.Println(err)
+	}
+
+	// Output:
+	// {"event":"weight-logged","data":{"date":"2024-01-08","weight":183.6}}
+}
+
+func ExampleRunHooks_dryRun() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(d string) { HooksDir = d }(HooksDir)
+	HooksDir = dir
+
+	hook := "#!/bin/sh\necho hook ran\n"
+	if err := os.WriteFile(filepath.Join(dir, EventWeightLogged), []byte(hook), 0755); err != nil {
+		log.Println(err)
+		return
+	}
+
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	// The weight logged in a dry run is never saved, so its hook
+	// doesn't run.
+	err = DryRun(db, io.Discard, func(db *sqlx.DB) error {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := insertWeightEntry(tx, time.Date(2024, 1, 8, 7, 30, 0, 0, time.UTC), 183.6); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := RunHooks(os.Stdout); err != nil {
+		log.Println(err)
+	}
+	fmt.Println("done")
+
+	// Output:
+	// done
+}
@@ -0,0 +1,87 @@
+package bite
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale is the language prompts and summaries are printed in, such as
+// "es". Messages without a translation are printed in English.
+var Locale = "en"
+
+// locale holds the messages of a language and how it writes numbers
+// and dates.
+type locale struct {
+	// messages are the translations of the English messages, looked up
+	// by their English text including any formatting verbs. A
+	// translation must keep the verbs in the same order.
+	messages map[string]string
+
+	// decimalComma writes decimals with a comma instead of a point.
+	decimalComma bool
+
+	// dateLayout is the layout dates are displayed with.
+	dateLayout string
+}
+
+// locales are the languages prompts and summaries are translated to.
+var locales = map[string]*locale{
+	"es": &localeES,
+}
+
+// ParseLocale returns the language of a locale name such as
+// "es_ES.UTF-8", "es-MX", or "es", and whether prompts and summaries
+// can be printed in it. "C" and "POSIX" are English.
+func ParseLocale(name string) (string, bool) {
+	lang := strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case "en", "c", "posix":
+		return "en", true
+	}
+	_, ok := locales[lang]
+	return lang, ok
+}
+
+// tr returns the message in Locale, or as is when it has no
+// translation.
+func tr(msg string) string {
+	if l, ok := locales[Locale]; ok {
+		if t, ok := l.messages[msg]; ok {
+			return t
+		}
+	}
+	return msg
+}
+
+// formatNumber formats a number with the given decimals and Locale's
+// decimal separator.
+func formatNumber(f float64, decimals int) string {
+	return localizeNumber(strconv.FormatFloat(f, 'f', decimals, 64))
+}
+
+// localizeNumber replaces the decimal point of a formatted number with
+// Locale's decimal separator.
+func localizeNumber(s string) string {
+	if l, ok := locales[Locale]; ok && l.decimalComma {
+		return strings.Replace(s, ".", ",", 1)
+	}
+	return s
+}
+
+// FormatDate formats a date for display in Locale. Dates are always
+// entered and stored as YYYY-MM-DD.
+func FormatDate(t time.Time) string {
+	if l, ok := locales[Locale]; ok && l.dateLayout != "" {
+		return t.Format(l.dateLayout)
+	}
+	return t.Format(dateFormat)
+}
+
+// weekdayName returns the name of the weekday in Locale.
+func weekdayName(d time.Weekday) string {
+	return tr(d.String())
+}
@@ -0,0 +1,46 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func ExampleParseLocale() {
+	for _, name := range []string{"es_ES.UTF-8", "es-MX", "C", "fr_FR.UTF-8"} {
+		fmt.Println(ParseLocale(name))
+	}
+
+	// Output:
+	// es true
+	// es true
+	// en true
+	// fr false
+}
+
+func ExampleFormatDate() {
+	Locale = "es"
+	defer func() { Locale = "en" }()
+
+	d := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	fmt.Println(FormatDate(d), weekdayName(d.Weekday()), FormatWeight(180.25))
+	r := &DayReport{
+		Date:      d,
+		Nutrition: Nutrition{Calories: 1500, Protein: 75, Fat: 50, Carbs: 180, Price: 6.5},
+		Goal:      Nutrition{Calories: 2000, Protein: 150, Fat: 70, Carbs: 200},
+	}
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// 18/03/2024 Lunes 180,2
+	// Proteína: [█████▒▒▒▒▒]  50% (75g / 150g)
+	// Grasa:    [███████▒▒▒]  71% (50g / 70g)
+	// Hidratos: [█████████▒]  90% (180g / 200g)
+	// Calorías: [███████▒▒▒]  75% (1500 / 2000)
+	// Macros:   P 20% / H 49% / G 31% de las calorías
+	//
+	// Quedan 500,00 calorías.
+	// Hoy has comido $6,50 en comida.
+}
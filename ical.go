@@ -0,0 +1,145 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// icalDate is the layout of iCalendar dates.
+const icalDate = "20060102"
+
+// CalendarEvent is an all-day event of the diet phase.
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time // First day after the event.
+	// Until is the last day a weekly event repeats on. Events without it
+	// happen once.
+	Until time.Time
+}
+
+// PhaseCalendar returns the milestones of the user's active or
+// scheduled diet phase: its start and end, its diet breaks and refeed
+// days, a weekly weigh-in reminder at the start of each phase week, and
+// a weekly check-in at the end of each.
+func PhaseCalendar(db *sqlx.DB, u *UserInfo) ([]CalendarEvent, error) {
+	if u.Phase.Status != "active" && u.Phase.Status != "scheduled" {
+		return nil, errors.New("there is no active or scheduled diet phase")
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := loadDietBreaks(tx, u); err != nil {
+		return nil, err
+	}
+
+	p := u.Phase
+	start, end := dateOf(p.StartDate), dateOf(p.EndDate)
+	name := p.Name
+	if name != "" {
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	uid := func(s string) string { return fmt.Sprintf("phase-%d-%s@bite", p.PhaseID, s) }
+
+	events := []CalendarEvent{
+		{
+			UID:         uid("start"),
+			Summary:     fmt.Sprintf("%s phase starts", name),
+			Description: fmt.Sprintf("Eat %.0f calories a day, aiming for %s lbs.", p.GoalCalories, FormatGoalWeight(p)),
+			Start:       start,
+			End:         start.AddDate(0, 0, 1),
+		},
+		{
+			UID:         uid("weigh-in"),
+			Summary:     "Weigh-in",
+			Description: fmt.Sprintf("Log your weight at least %d times this week.", minEntriesPerWeek),
+			Start:       start,
+			End:         start.AddDate(0, 0, 1),
+			Until:       end,
+		},
+		{
+			UID:         uid("check-in"),
+			Summary:     "Check-in",
+			Description: `Rate the week's hunger, energy, sleep, and gym performance with "bite checkin".`,
+			Start:       start.AddDate(0, 0, 6),
+			End:         start.AddDate(0, 0, 7),
+			Until:       end,
+		},
+	}
+	for _, b := range p.Breaks {
+		e := CalendarEvent{
+			UID:         uid(fmt.Sprintf("break-%d", b.ID)),
+			Summary:     "Diet break",
+			Description: fmt.Sprintf("Eat at maintenance, %.0f calories a day.", u.TDEE),
+			Start:       dateOf(b.StartDate),
+			End:         dateOf(b.EndDate),
+		}
+		if e.End.Sub(e.Start) <= 24*time.Hour {
+			e.Summary = "Refeed day"
+		}
+		events = append(events, e)
+	}
+	events = append(events, CalendarEvent{
+		UID:     uid("end"),
+		Summary: fmt.Sprintf("%s phase ends", name),
+		Start:   end,
+		End:     end.AddDate(0, 0, 1),
+	})
+	return events, nil
+}
+
+// WriteICal writes the events as an iCalendar file. stamp is the time
+// the file was made.
+func WriteICal(w io.Writer, events []CalendarEvent, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		// Lines longer than 75 octets are folded onto lines starting
+		// with a space.
+		for len(s) > 75 {
+			n := 75
+			for n > 0 && s[n]&0xC0 == 0x80 {
+				n-- // Don't split a UTF-8 sequence.
+			}
+			bw.WriteString(s[:n] + "\r\n")
+			s = " " + s[n:]
+		}
+		bw.WriteString(s + "\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//bite//bite//EN")
+	line("CALSCALE:GREGORIAN")
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + e.Start.Format(icalDate))
+		line("DTEND;VALUE=DATE:" + e.End.Format(icalDate))
+		if !e.Until.IsZero() {
+			line("RRULE:FREQ=WEEKLY;UNTIL=" + e.Until.Format(icalDate))
+		}
+		line("SUMMARY:" + icalText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + icalText(e.Description))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icalText escapes the characters that are special in iCalendar text.
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,89 @@
+package bite
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleWriteICal() {
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO diet_breaks (phase_id, start_date, end_date, goal_calories, status)
+			VALUES (1, '2024-02-12', '2024-02-13', 2200, 'completed');
+	`)
+	defer db.Close()
+
+	u := UserInfo{TDEE: 2700}
+	u.Phase = PhaseInfo{
+		PhaseID:      1,
+		Name:         "cut",
+		GoalCalories: 2200,
+		GoalWeight:   175,
+		StartDate:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:      time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+		Status:       "active",
+	}
+	events, err := PhaseCalendar(db, &u)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var b strings.Builder
+	stamp := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if err := WriteICal(&b, events, stamp); err != nil {
+		log.Fatal(err)
+	}
+	os.Stdout.WriteString(strings.ReplaceAll(b.String(), "\r\n", "\n"))
+
+	// Output:
+	// BEGIN:VCALENDAR
+	// VERSION:2.0
+	// PRODID:-//bite//bite//EN
+	// CALSCALE:GREGORIAN
+	// BEGIN:VEVENT
+	// UID:phase-1-start@bite
+	// DTSTAMP:20240101T120000Z
+	// DTSTART;VALUE=DATE:20240101
+	// DTEND;VALUE=DATE:20240102
+	// SUMMARY:Cut phase starts
+	// DESCRIPTION:Eat 2200 calories a day\, aiming for 175.0 lbs.
+	// END:VEVENT
+	// BEGIN:VEVENT
+	// UID:phase-1-weigh-in@bite
+	// DTSTAMP:20240101T120000Z
+	// DTSTART;VALUE=DATE:20240101
+	// DTEND;VALUE=DATE:20240102
+	// RRULE:FREQ=WEEKLY;UNTIL=20240325
+	// SUMMARY:Weigh-in
+	// DESCRIPTION:Log your weight at least 2 times this week.
+	// END:VEVENT
+	// BEGIN:VEVENT
+	// UID:phase-1-check-in@bite
+	// DTSTAMP:20240101T120000Z
+	// DTSTART;VALUE=DATE:20240107
+	// DTEND;VALUE=DATE:20240108
+	// RRULE:FREQ=WEEKLY;UNTIL=20240325
+	// SUMMARY:Check-in
+	// DESCRIPTION:Rate the week's hunger\, energy\, sleep\, and gym performance w
+	//  ith "bite checkin".
+	// END:VEVENT
+	// BEGIN:VEVENT
+	// UID:phase-1-break-1@bite
+	// DTSTAMP:20240101T120000Z
+	// DTSTART;VALUE=DATE:20240212
+	// DTEND;VALUE=DATE:20240213
+	// SUMMARY:Refeed day
+	// DESCRIPTION:Eat at maintenance\, 2700 calories a day.
+	// END:VEVENT
+	// BEGIN:VEVENT
+	// UID:phase-1-end@bite
+	// DTSTAMP:20240101T120000Z
+	// DTSTART;VALUE=DATE:20240325
+	// DTEND;VALUE=DATE:20240326
+	// SUMMARY:Cut phase ends
+	// END:VEVENT
+	// END:VCALENDAR
+}
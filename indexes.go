@@ -0,0 +1,32 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// indexesSchema creates the indexes that the food and weight logs are
+// read through. Food entries are looked up by day for the log and
+// summaries, and by food for recalculations and search ranking. Weights
+// are looked up by day. The foods of a meal are found through the
+// meal_foods primary key, which starts with meal_id. Databases made
+// before they existed get them from CreateIndexes.
+const indexesSchema = `
+	CREATE INDEX IF NOT EXISTS idx_daily_foods_date ON daily_foods(date);
+	CREATE INDEX IF NOT EXISTS idx_daily_foods_food_date ON daily_foods(food_id, date);
+	CREATE INDEX IF NOT EXISTS idx_daily_weights_date ON daily_weights(date);
+`
+
+// CreateIndexes adds the log indexes to a database that doesn't have
+// them and updates the statistics the query planner uses to choose
+// them.
+func CreateIndexes(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(indexesSchema); err != nil {
+		return fmt.Errorf("couldn't create indexes: %v", err)
+	}
+	if _, err := tx.Exec(`ANALYZE`); err != nil {
+		return fmt.Errorf("couldn't analyze database: %v", err)
+	}
+	return nil
+}
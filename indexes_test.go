@@ -0,0 +1,71 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+// planIndex returns the index the query is planned to read through, or
+// "full scan" when it reads a whole table.
+func planIndex(db *sqlx.DB, query string, args ...interface{}) string {
+	var plan []struct {
+		ID      int    `db:"id"`
+		Parent  int    `db:"parent"`
+		NotUsed int    `db:"notused"`
+		Detail  string `db:"detail"`
+	}
+	if err := db.Select(&plan, `EXPLAIN QUERY PLAN `+query, args...); err != nil {
+		log.Fatal(err)
+	}
+	re := regexp.MustCompile(`USING (?:COVERING )?INDEX (\w+)`)
+	for _, p := range plan {
+		if m := re.FindStringSubmatch(p.Detail); m != nil {
+			return m[1]
+		}
+	}
+	return "full scan"
+}
+
+func ExampleCreateIndexes() {
+	// A database made before the indexes existed.
+	db := dbtest.MustNew(`
+		DROP INDEX idx_daily_foods_date;
+		DROP INDEX idx_daily_foods_food_date;
+		DROP INDEX idx_daily_weights_date;
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	if err := CreateIndexes(tx); err != nil {
+		log.Fatal(err)
+	}
+	tx.Commit()
+
+	queries := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"food log by day", `SELECT * FROM daily_foods WHERE date = $1`, []interface{}{"2024-01-01"}},
+		{"food log by range", `SELECT date, SUM(calories) FROM daily_foods WHERE date >= $1 AND date < $2 GROUP BY date`, []interface{}{"2024-01-01", "2024-02-01"}},
+		{"entries of a food", `SELECT id FROM daily_foods WHERE food_id = $1 AND date >= $2`, []interface{}{1, "2024-01-01"}},
+		{"food popularity", `SELECT food_id, COUNT(*), MAX(date) FROM daily_foods GROUP BY food_id`, nil},
+		{"weight by day", `SELECT id FROM daily_weights WHERE date = $1 LIMIT 1`, []interface{}{"2024-01-01"}},
+		{"foods of a meal", `SELECT food_id FROM meal_foods WHERE meal_id = $1`, []interface{}{1}},
+	}
+	for _, q := range queries {
+		fmt.Printf("%s: %s\n", q.name, planIndex(db, q.query, q.args...))
+	}
+
+	// Output:
+	// food log by day: idx_daily_foods_date
+	// food log by range: idx_daily_foods_date
+	// entries of a food: idx_daily_foods_food_date
+	// food popularity: idx_daily_foods_food_date
+	// weight by day: idx_daily_weights_date
+	// foods of a meal: sqlite_autoindex_meal_foods_1
+}
@@ -0,0 +1,12 @@
+package bite
+
+import (
+	"bufio"
+	"os"
+)
+
+// Input is the reader prompts read the user's answers from. It defaults
+// to standard input and can be replaced, such as by a script of answers.
+// Every prompt shares it, so that answers piped in are read a line at a
+// time instead of being buffered away by the first prompt.
+var Input = bufio.NewReader(os.Stdin)
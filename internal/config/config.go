@@ -0,0 +1,409 @@
+// Package config loads the bite configuration file, which holds
+// defaults for the command-line interface.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds the CLI defaults read from the configuration file. Zero
+// values mean the setting was not set.
+type Config struct {
+	// DBPath is the path to the SQLite database.
+	DBPath string `toml:"db_path"`
+
+	// Units is the preferred measurement system: "metric" or "imperial".
+	Units string `toml:"units"`
+
+	// JSON makes commands that support it print JSON.
+	JSON bool `toml:"json"`
+
+	// MealSlots maps meal slot names to the time of day ("15:04") they
+	// start.
+	MealSlots map[string]string `toml:"meal_slots"`
+
+	// MealTargets maps meal slot names to the least macros a meal in
+	// the slot should have.
+	MealTargets map[string]MealTarget `toml:"meal_targets"`
+
+	// Locale is the language of prompts and summaries, such as "es".
+	Locale string `toml:"locale"`
+
+	// WeekStart is the first day of the week, such as "sunday".
+	WeekStart string `toml:"week_start"`
+
+	// DateFormats are the accepted formats of dates entered by the
+	// user, such as "DD/MM" or "MM/DD/YYYY", tried in order.
+	DateFormats []string `toml:"date_formats"`
+
+	// TimeZone is the IANA time zone days are kept in, such as
+	// "America/Chicago", so they don't shift while traveling. Empty
+	// means the system's time zone.
+	TimeZone string `toml:"time_zone"`
+
+	// DayBoundaryHour is the hour (0-23) a day of logs starts at, such
+	// as 4 so food eaten after midnight on a night shift counts toward
+	// the day before. Zero means midnight.
+	DayBoundaryHour int `toml:"day_boundary_hour"`
+
+	// LateNightCutoff is the time of day ("15:04") until which food
+	// logged after midnight counts toward the day before, such as
+	// "03:00" for yesterday's late dinner. Empty means midnight.
+	LateNightCutoff string `toml:"late_night_cutoff"`
+
+	// PreferVerified hides user created foods from search results when
+	// a USDA or Open Food Facts food has the same name.
+	PreferVerified bool `toml:"prefer_verified"`
+
+	// CalorieFloor is the lowest daily calorie goal that missed weeks
+	// lower the goal to, such as 1500. Zero means the user's BMR.
+	CalorieFloor float64 `toml:"calorie_floor"`
+
+	// ManualGoal is how the progress checks resolve an adjustment of a
+	// calorie goal set by hand: "ask", "respect", "blend", or "replace".
+	// Empty means "ask".
+	ManualGoal string `toml:"manual_goal"`
+
+	// CaffeineCutoff is the time of day ("15:04") after which caffeine
+	// is flagged in the day summary, such as "14:00".
+	CaffeineCutoff string `toml:"caffeine_cutoff"`
+
+	// FiberMin is the least fiber (g) to eat a day. Zero means 14 g per
+	// 1000 calories of the calorie goal.
+	FiberMin float64 `toml:"fiber_min"`
+
+	// SugarMax is the most sugar (g) to eat a day. Zero means 10% of
+	// the calorie goal.
+	SugarMax float64 `toml:"sugar_max"`
+
+	// SaturatedFatMax is the most saturated fat (g) to eat a day. Zero
+	// means no limit.
+	SaturatedFatMax float64 `toml:"saturated_fat_max"`
+
+	// SodiumHigh is the sodium (mg) of a day high enough that a weight
+	// spike the next day is noted as likely water, 3000 when not set.
+	SodiumHigh float64 `toml:"sodium_high"`
+
+	// MaintenanceDrift is how far (lbs) the trend weight may drift from
+	// the weight a cut or bulk ended at before it is flagged, 3 when not
+	// set.
+	MaintenanceDrift float64 `toml:"maintenance_drift"`
+
+	// WeeklyBudget is the most to spend on food a week. Summaries show
+	// the week's spend against it, and search suggests cheaper foods
+	// while the spend is ahead of pace. Zero means no budget.
+	WeeklyBudget float64 `toml:"weekly_budget"`
+
+	// Restrictions are the foods the user avoids, each a food flag such
+	// as "gluten" or "dairy" or a diet such as "vegan". Foods flagged
+	// with them are warned about when logged and left out of
+	// suggestions.
+	Restrictions []string `toml:"restrictions"`
+
+	// PhaseTemplates maps the names of phase templates to the diet
+	// phases they run back-to-back, such as "4w maintain, 8w cut".
+	PhaseTemplates map[string]string `toml:"phase_templates"`
+
+	// HooksDir is the directory of the hooks run after foods or weights
+	// are logged, a phase ends, or the weight drifts after a phase.
+	HooksDir string `toml:"hooks_dir"`
+
+	// PhotosDir is the directory progress photos are kept in.
+	PhotosDir string `toml:"photos_dir"`
+
+	// TemplatesDir is the directory of the templates that replace how
+	// reports are printed.
+	TemplatesDir string `toml:"templates_dir"`
+
+	Adherence Adherence `toml:"adherence"`
+
+	Colors Colors `toml:"colors"`
+
+	Precision Precision `toml:"precision"`
+
+	Search Search `toml:"search"`
+
+	NutritionAPI NutritionAPI `toml:"nutrition_api"`
+
+	Cycle Cycle `toml:"cycle"`
+
+	WeighIn WeighIn `toml:"weigh_in"`
+}
+
+// WeighIn holds the user's weigh-in protocol.
+type WeighIn struct {
+	// Time is the time of day ("15:04") to weigh in, such as "07:00".
+	// Empty means the usual time of the recent weigh-ins.
+	Time string `toml:"time"`
+
+	// Window is how many minutes from Time a weight may be logged
+	// before it is flagged, 60 when not set.
+	Window int `toml:"window"`
+
+	// Conditions describes how to weigh in, such as "after the
+	// bathroom, before eating or drinking".
+	Conditions string `toml:"conditions"`
+}
+
+// Cycle holds the menstrual cycle used to leave weeks of expected water
+// retention out of progress checks.
+type Cycle struct {
+	// PeriodStart is the first day (YYYY-MM-DD) of a recent period.
+	// Empty means cycle tracking is off.
+	PeriodStart string `toml:"period_start"`
+
+	// Length is the number of days from the start of a period to the
+	// next, 28 when not set.
+	Length int `toml:"length"`
+
+	// DaysBefore is the number of days before a period starts with
+	// water retention, 5 when not set.
+	DaysBefore int `toml:"days_before"`
+
+	// DaysAfter is the number of days from the start of a period with
+	// water retention, 2 when not set.
+	DaysAfter int `toml:"days_after"`
+}
+
+// NutritionAPI holds the online nutrition database that foods missing
+// from the local database are searched for.
+type NutritionAPI struct {
+	// Provider is "calorieninjas" or "nutritionix". Empty means foods
+	// are only searched for locally.
+	Provider string `toml:"provider"`
+
+	// APIKey is the provider's API key.
+	APIKey string `toml:"api_key"`
+
+	// AppID is the Nutritionix application id.
+	AppID string `toml:"app_id"`
+
+	// URL replaces the provider's endpoint.
+	URL string `toml:"url"`
+}
+
+// MealTarget holds the least protein, carbs, and fat (g) of a meal
+// slot. Zero means no target.
+type MealTarget struct {
+	Protein float64 `toml:"protein"`
+	Carbs   float64 `toml:"carbs"`
+	Fat     float64 `toml:"fat"`
+}
+
+// Search holds how search results are ranked. Nil means not set.
+type Search struct {
+	// FrequencyWeight is how much foods logged often are raised in
+	// search results.
+	FrequencyWeight *float64 `toml:"frequency_weight"`
+
+	// RecencyWeight is how much foods logged recently are raised in
+	// search results.
+	RecencyWeight *float64 `toml:"recency_weight"`
+}
+
+// Precision holds the number of decimals values are displayed with.
+// Values are always stored at full precision. Nil means not set.
+type Precision struct {
+	// Weight is the number of decimals of weights.
+	Weight *int `toml:"weight"`
+
+	// Macros is the number of decimals of protein, carbs, and fat.
+	Macros *int `toml:"macros"`
+}
+
+// Colors holds how output is colored.
+type Colors struct {
+	// Enabled turns colored output on or off. Nil means output is
+	// colored when printed to a terminal.
+	Enabled *bool `toml:"enabled"`
+
+	// Met is the color of days that met the calorie goal, such as
+	// "green", or "none" for plain text.
+	Met string `toml:"met"`
+
+	// Missed is the color of days that missed the calorie goal.
+	Missed string `toml:"missed"`
+}
+
+// Adherence holds the thresholds used to decide whether the user stuck
+// to their calorie goal.
+type Adherence struct {
+	// DayTolerance is the fraction of the goal calories a maintenance
+	// day may be off by.
+	DayTolerance float64 `toml:"day_tolerance"`
+
+	// WeekDays is the fraction of days in a week that must meet the
+	// daily calorie goal.
+	WeekDays float64 `toml:"week_days"`
+
+	// PhaseWeeks is the fraction of a phase's weeks that must be valid
+	// for it to end on schedule. Below it, extending the phase by the
+	// weeks that weren't is offered instead.
+	PhaseWeeks float64 `toml:"phase_weeks"`
+}
+
+// maxDecimals is the largest number of decimals values can be displayed
+// with.
+const maxDecimals = 4
+
+// Path returns the path of the configuration file. It is read from the
+// BITE_CONFIG environment variable, falling back to
+// $XDG_CONFIG_HOME/bite/config.toml or ~/.config/bite/config.toml.
+func Path() (string, error) {
+	if p := os.Getenv("BITE_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("couldn't find home directory: %v", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "bite", "config.toml"), nil
+}
+
+// Load reads the configuration file at the given path. A missing file
+// is not an error and results in an empty config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	md, err := toml.DecodeFile(path, c)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("couldn't read config file %s: %v", path, err)
+	}
+	if keys := md.Undecoded(); len(keys) > 0 {
+		return nil, fmt.Errorf("unknown config key %q in %s", keys[0].String(), path)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	c.DBPath = expandHome(c.DBPath)
+	c.HooksDir = expandHome(c.HooksDir)
+	c.TemplatesDir = expandHome(c.TemplatesDir)
+	c.PhotosDir = expandHome(c.PhotosDir)
+	return c, nil
+}
+
+// validate checks the values of the config.
+func (c *Config) validate() error {
+	switch c.Units {
+	case "", "metric", "imperial":
+	default:
+		return fmt.Errorf("units must be \"metric\" or \"imperial\", got %q", c.Units)
+	}
+	if t := c.Adherence.DayTolerance; t < 0 || t > 1 {
+		return fmt.Errorf("adherence.day_tolerance must be between 0 and 1, got %v", t)
+	}
+	if d := c.Adherence.WeekDays; d < 0 || d > 1 {
+		return fmt.Errorf("adherence.week_days must be between 0 and 1, got %v", d)
+	}
+	if w := c.Adherence.PhaseWeeks; w < 0 || w > 1 {
+		return fmt.Errorf("adherence.phase_weeks must be between 0 and 1, got %v", w)
+	}
+	if p := c.Precision.Weight; p != nil && (*p < 0 || *p > maxDecimals) {
+		return fmt.Errorf("precision.weight must be between 0 and %d, got %d", maxDecimals, *p)
+	}
+	if p := c.Precision.Macros; p != nil && (*p < 0 || *p > maxDecimals) {
+		return fmt.Errorf("precision.macros must be between 0 and %d, got %d", maxDecimals, *p)
+	}
+	if h := c.DayBoundaryHour; h < 0 || h > 23 {
+		return fmt.Errorf("day_boundary_hour must be between 0 and 23, got %d", h)
+	}
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			return fmt.Errorf("unknown time_zone %q", c.TimeZone)
+		}
+	}
+	if c.CalorieFloor < 0 {
+		return fmt.Errorf("calorie_floor can't be negative, got %v", c.CalorieFloor)
+	}
+	if c.FiberMin < 0 {
+		return fmt.Errorf("fiber_min can't be negative, got %v", c.FiberMin)
+	}
+	if c.SugarMax < 0 {
+		return fmt.Errorf("sugar_max can't be negative, got %v", c.SugarMax)
+	}
+	if c.SaturatedFatMax < 0 {
+		return fmt.Errorf("saturated_fat_max can't be negative, got %v", c.SaturatedFatMax)
+	}
+	if c.SodiumHigh < 0 {
+		return fmt.Errorf("sodium_high can't be negative, got %v", c.SodiumHigh)
+	}
+	if c.MaintenanceDrift < 0 {
+		return fmt.Errorf("maintenance_drift can't be negative, got %v", c.MaintenanceDrift)
+	}
+	if c.WeeklyBudget < 0 {
+		return fmt.Errorf("weekly_budget can't be negative, got %v", c.WeeklyBudget)
+	}
+	switch c.ManualGoal {
+	case "", "ask", "respect", "blend", "replace":
+	default:
+		return fmt.Errorf("manual_goal must be \"ask\", \"respect\", \"blend\", or \"replace\", got %q", c.ManualGoal)
+	}
+	for slot, t := range c.MealTargets {
+		if t.Protein < 0 || t.Carbs < 0 || t.Fat < 0 {
+			return fmt.Errorf("meal_targets.%s can't be negative", slot)
+		}
+	}
+	if w := c.Search.FrequencyWeight; w != nil && *w < 0 {
+		return fmt.Errorf("search.frequency_weight can't be negative, got %v", *w)
+	}
+	if w := c.Search.RecencyWeight; w != nil && *w < 0 {
+		return fmt.Errorf("search.recency_weight can't be negative, got %v", *w)
+	}
+	switch a := c.NutritionAPI; a.Provider {
+	case "":
+	case "calorieninjas", "nutritionix":
+		if a.APIKey == "" {
+			return errors.New("nutrition_api.api_key must be set")
+		}
+		if a.Provider == "nutritionix" && a.AppID == "" {
+			return errors.New("nutrition_api.app_id must be set for nutritionix")
+		}
+	default:
+		return fmt.Errorf("nutrition_api.provider must be \"calorieninjas\" or \"nutritionix\", got %q", a.Provider)
+	}
+	if c.Cycle.PeriodStart != "" {
+		if _, err := time.Parse("2006-01-02", c.Cycle.PeriodStart); err != nil {
+			return fmt.Errorf("cycle.period_start must be YYYY-MM-DD, got %q", c.Cycle.PeriodStart)
+		}
+	}
+	if l := c.Cycle.Length; l != 0 && (l < 15 || l > 60) {
+		return fmt.Errorf("cycle.length must be between 15 and 60 days, got %d", l)
+	}
+	if c.WeighIn.Window < 0 {
+		return fmt.Errorf("weigh_in.window can't be negative, got %d", c.WeighIn.Window)
+	}
+	if c.Cycle.DaysBefore < 0 || c.Cycle.DaysAfter < 0 {
+		return errors.New("cycle.days_before and cycle.days_after can't be negative")
+	}
+	if l := c.Cycle.Length; l != 0 && c.Cycle.DaysBefore+c.Cycle.DaysAfter >= l {
+		return errors.New("cycle.days_before and cycle.days_after must add up to less than cycle.length")
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~/" in the path with the user's home
+// directory.
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
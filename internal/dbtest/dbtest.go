@@ -0,0 +1,121 @@
+// Package dbtest creates in-memory bite databases for tests.
+package dbtest
+
+import (
+	"fmt"
+
+	"github.com/ericstrs/bite/database"
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+// Seed adds the macro nutrients of the food data and a few foods:
+//
+//	1  Oats            40 g, 1/2 cup, $0.50
+//	2  Chicken breast  100 g
+//	3  Banana          118 g, 1 medium
+const Seed = `
+	INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+		(1003, 'Protein', 'G'),
+		(1004, 'Total lipid (fat)', 'G'),
+		(1005, 'Carbohydrate, by difference', 'G'),
+		(1008, 'Energy', 'KCAL');
+	INSERT INTO food_nutrient_derivation (id, code, description) VALUES
+		(71, 'PORT', 'Portion size');
+	INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving, cost) VALUES
+		(1, 'Oats', 40, 'g', '1/2 cup', 0.5),
+		(2, 'Chicken breast', 100, 'g', '', 0),
+		(3, 'Banana', 118, 'g', '1 medium', 0);
+	INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+		(1, 1003, 13, 71), (1, 1004, 7, 71), (1, 1005, 68, 71), (1, 1008, 389, 71),
+		(2, 1003, 31, 71), (2, 1004, 3.6, 71), (2, 1005, 0, 71), (2, 1008, 165, 71),
+		(3, 1003, 1.1, 71), (3, 1004, 0.3, 71), (3, 1005, 23, 71), (3, 1008, 89, 71);
+	INSERT INTO foods_fts (food_id, food_name, brand_name)
+		SELECT food_id, food_name, brand_name FROM foods;
+`
+
+// User adds a male user of 185 lbs with a TDEE of 2700 calories, on a
+// cut to 175 lbs from 2024-01-01 to 2024-03-25 at 2200 calories a day.
+const User = `
+	INSERT INTO macros (protein, min_protein, max_protein, carbs, min_carbs, max_carbs, fats, min_fats, max_fats)
+		VALUES (150, 130, 170, 250, 200, 300, 70, 60, 80);
+	INSERT INTO phase_info (user_id, name, goal_calories, start_weight, goal_weight, weight_change_threshold,
+		weekly_change, start_date, end_date, last_checked_week, duration, max_duration, min_duration, status)
+		VALUES (1, 'cut', 2200, 185, 175, 18.5, -1, '2024-01-01', '2024-03-25', '2024-01-01', 12, 16, 8, 'active');
+	INSERT INTO config (user_id, sex, weight, height, age, activity_level, tdee, system, macros_id, phase_id)
+		VALUES (1, 'male', 185, 180, 30, 'moderate', 2700, 'imperial', 1, 1);
+`
+
+// New returns an in-memory database with the full bite schema and the
+// Seed data, then runs each of the given SQL scripts, such as User.
+// Foreign keys are enforced, as Open enforces them on databases made
+// from the full schema.
+//
+// The database has a single connection, since each connection to an
+// in-memory database opens a database of its own.
+func New(scripts ...string) (*sqlx.DB, error) {
+	return NewEmpty(append([]string{Seed}, scripts...)...)
+}
+
+// MustNew is like New but panics if the database can't be created.
+func MustNew(scripts ...string) *sqlx.DB {
+	db, err := New(scripts...)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// NewEmpty is like New but leaves out the Seed data, for tests that
+// add foods of their own.
+func NewEmpty(scripts ...string) (*sqlx.DB, error) {
+	db, err := sqlx.Connect("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := setup(db, scripts); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// MustNewEmpty is like NewEmpty but panics if the database can't be
+// created.
+func MustNewEmpty(scripts ...string) *sqlx.DB {
+	db, err := NewEmpty(scripts...)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// Create is like New but creates the database as a file at path, for
+// tests that run the bite command against it.
+func Create(path string, scripts ...string) error {
+	db, err := sqlx.Connect("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("couldn't open database: %v", err)
+	}
+	if err := setup(db, append([]string{Seed}, scripts...)); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// setup creates the tables of the database and runs the given
+// scripts.
+func setup(db *sqlx.DB, scripts []string) error {
+	if _, err := db.Exec(database.Setup); err != nil {
+		return fmt.Errorf("couldn't create tables: %v", err)
+	}
+	for _, s := range scripts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("couldn't seed database: %v", err)
+		}
+	}
+	return nil
+}
@@ -1,381 +1,3043 @@
 package ui
 
 import (
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ericstrs/bite"
+	"github.com/ericstrs/bite/internal/config"
 	"github.com/jmoiron/sqlx"
+	"golang.org/x/term"
 )
 
 const (
-	logUsage = `USAGE
-
-  bite log food   - Log food.
-  bite log meal   - Log meal.
-  bite log weight - Log weight.
-  bite log update [weight|food]     - Update food or weight log.
-  bite log delete [weight|food]     - Delete food or weight log.
-  bite log show   [all|weight|food] - Shows food and weight log and full log.
-`
-	createUsage = `USAGE
-
-  bite create food - Create new food.
-  bite create meal - Create new meal.
-`
-	deleteUsage = `USAGE
-
-  bite delete food - Delete existing food.
-  bite delete meal - Delete existing meal.
-`
-	updateUsage = `USAGE
-
-  bite update food - Update food information.
-  bite update weight - Update user information.
-`
-	summaryUsage = `USAGE
-
-  bite summary phase - Print phase summary.
-  bite summary diet  - Print diet summary.
-  bite summary user  - Print user summary.
-`
-	stopUsage = `USAGE
-
-  bite stop phase - Stop current phase.
-`
+	rootLong = `  Bite is a command-line utility for managing diet phases and food logging.
+
+  Appending "help" or --help after any command will print more command
+  information.
+
+  Defaults are read from ~/.config/bite/config.toml, or the file named by
+  BITE_CONFIG. Environment variables and flags take precedence over it.
+
+  With --dry-run, a command runs on a copy of the database, checking
+  its input and computing its values as usual, and prints the rows it
+  would have added or deleted instead of saving them. Hooks don't run,
+  but files a command writes outside the database, such as exports,
+  are still written.
+
+  Output is colored only when printed to a terminal. Set NO_COLOR or
+  pass --no-color to turn colors off. The [colors] table of the config
+  file sets enabled = true or false, and the met and missed colors of
+  days by name: none, bold, black, red, green, yellow, blue, magenta,
+  cyan, or white.
+
+  Hooks are executables in ~/.config/bite/hooks, or the hooks_dir of the
+  config file, named after the event they run after: food-logged,
+  weight-logged, phase-transition, or weight-drift. Each is given the
+  event as JSON on standard input once the command's changes are saved.
+
+  The day summary is printed with ~/.config/bite/templates/day.tmpl, or
+  day.tmpl in the templates_dir of the config file, when it exists. It
+  is a Go text/template given the day's Foods, their Calories, Protein,
+  Fat, Carbs, and Price, the day's Goal for each, and Remaining
+  calories. Templates can also call bar, percent, progress, macro,
+  weight, split, date, num, and tr.
+
+  Prompts and summaries are printed in the locale of the config file,
+  such as locale = "es", or else the language of LC_ALL, LC_MESSAGES,
+  or LANG. English and Spanish are available; messages without a
+  translation are printed in English. Dates are entered as YYYY-MM-DD
+  in every locale.
+
+  Setting period_start in the [cycle] table of the config file, such as
+  period_start = "2024-03-01", turns on menstrual cycle tracking. Weeks
+  with days of expected water retention, from days_before (5) days
+  before each period through its first days_after (2) days, are left
+  out of progress checks and marked in the weight log. Cycles are
+  length (28) days long.
+
+  Weighing in at the same time each day keeps the weight trend steady.
+  The [weigh_in] table of the config file sets the time to weigh in,
+  such as time = "07:00", the window in minutes around it (60), and the
+  conditions to weigh in under, such as conditions = "after the
+  bathroom, before eating". Without a time, the usual time of the
+  recent weigh-ins is used. Weights logged outside the window are
+  flagged when logged and by "bite notify".`
+
+	keysLong = `  Actions: down, up, top, bottom, search, half_page_down, half_page_up, help
+
+  Key sequences are typed characters (e.g. "gg") or a control key
+  written as "ctrl-<letter>".`
+
+	shareLong = `  Share the diet with a coach. Export writes a read-only snapshot of the
+  database that leaves out the audit log, sync state, and the paths of
+  attached files and progress photos. The snapshot isn't encrypted.
+
+  The coach runs a command on the snapshot with view, such as "bite
+  share view diet.share summary phase", which defaults to the phase
+  summary. Commands see the snapshot as of the day it was exported, and
+  ones that would change it fail without saving anything.`
+	syncLong = `  Sync keeps the food and weight logs of two devices in step through a
+  shared file, e.g. in Dropbox or Syncthing. Export the changes on one
+  device and import the file on the other.
+
+  By default, export writes the changes made since the last export. The
+  first export, or one with --all, writes every entry. When an entry was
+  changed on both devices, the most recent change wins. Food entries of
+  foods that only exist on the exporting device are skipped. Attached
+  files are exported as paths; copy the files themselves separately.
+
+  Entries with the same content as an existing entry, e.g. a food logged
+  on both devices at the same time with the same servings, aren't
+  imported. Use dedup to remove such duplicates from the log.`
+
+	breakLong = `  A diet break is a period of eating at maintenance during a diet
+  phase. During the break the calorie goal is your TDEE, and the phase
+  end date is pushed back by the length of the break. Weeks with break
+  days are left out of progress checks. The previous calorie goal is
+  restored when the break ends.`
+
+	adjustmentsLong = `  List each change the progress checks made to the calorie goal of the
+  diet phase and why, such as "2 weeks at -0.1 lbs vs target -0.9 lbs ⇒
+  -175 calories a day, taken from fats 10g, carbs 15g". The goal is
+  adjusted after two weeks in a row off the weekly change goal.`
+
+	goalLong = `  Set the daily calorie goal of the diet phase, such as "bite update goal
+  --cals 2300". The difference is taken from or added to the macros.
+
+  When the progress checks later want to adjust a goal set by hand,
+  they ask whether to respect it, blend the two goals halfway, or
+  replace it with the adjusted goal. Set manual_goal in the config file
+  to "respect", "blend", or "replace" to always do the same.`
+
+	portionLong = `  Create a food from a batch cooked in bulk, given the raw weight in
+  grams of each ingredient and the weight of the whole batch once
+  cooked, such as:
+
+    bite recipe portion --name "Chili" --cooked 2000 12=500 40=800 7=400
+
+  Cooking adds or loses water, so the nutrition of the ingredients is
+  spread over the cooked weight. Log servings of the new food by their
+  cooked weight, or set --servings to also make a serving of an equal
+  share of the batch.`
+
+	recheckLong = `  Progress is checked a week at a time, and a week is only checked
+  once. Entries edited or backfilled after their week was checked don't
+  change the calorie goal until the phase is rechecked. A recheck
+  replays every week of the phase from its starting calorie goal and
+  prints the calorie goal it arrives at, so rechecking again gives the
+  same goal. The new goal is saved once you confirm.`
+
+	tdeeLong = `  Each week of the diet phase, the TDEE is back-calculated as the
+  average calories eaten less the calories of the weight lost or gained,
+  at 3500 calories a pound. The rate of weight change is fit to the
+  weights of the week and the week before it. Over a long cut the chart
+  shows how much the metabolism has adapted. Weeks need calories logged
+  on at least 4 days.`
+
+	noteLong = `  Annotate the timeline with a life event, such as "bite note add
+  --date 2024-03-01 started new job". Notes are shown in the week
+  summary of "bite summary phase", under the weight chart of "bite
+  summary progress", and in the weekly journal, to help explain changes
+  in weight or adherence when looking back. A note of a day without an
+  entry is shown with the next day that has one.`
+
+	phaseStartLong = `  Start a phase template, such as "bite phase start recomp", which
+  runs several diet phases back-to-back. The current phase is stopped,
+  the template's first phase starts on --date, and each phase after it
+  starts on its own when the one before it is completed, at the
+  recommended pace from your weight at the time. Stopping a phase ends
+  its template. Templates are defined in the config file's
+  phase_templates table, such as recomp = "4w maintain, 8w cut", and
+  are listed by "bite phase templates".`
+
+	liftLong = `  Log a set of a lift, such as "bite log lift bench press 100 5". The
+  best estimated one-rep max of each session is tracked in "bite summary
+  phase", which shows whether each lift is climbing, maintained, or
+  dropping over the diet phase.`
+
+	sleepLong = `  Log the hours slept the night before, such as "bite log sleep 7.5h" or
+  "bite log sleep 7h30m". Logging sleep again for a day replaces it.
+  Weeks of poor sleep are compared with weeks of good sleep in "bite
+  summary sleep" and the weekly journal.`
+
+	sleepSummaryLong = `  For each week, print the average hours slept a night, the change in
+  average weight from the week before and how far it was from the
+  weekly goal, and how many logged days met the calorie goal. Weeks
+  averaging under 7 hours of sleep are weeks of poor sleep, and are
+  compared with the weeks of good sleep. Poor sleep raises hunger and
+  water retention, so it often shows up as missed goals and stalled
+  weigh-ins.`
+
+	conditionLong = `  Flag a condition or medication, such as "bite condition add glp1", to
+  make the recommendations more conservative: new cuts are planned at a
+  slower rate, the fastest safe rate of weight loss is lowered, and the
+  protein or carb minimums are raised. Flagged conditions are shown in
+  "bite summary user" with why they matter.
+
+  These adjustments are not medical advice. Follow the advice of your
+  doctor.`
+
+	trainingLong = `  Calorie cycling gives training days a higher calorie goal than rest
+  days. The extra calories are taken from the rest days, so the weekly
+  calorie goal of the active diet phase stays the same. A day counts as
+  a training day when a session is logged for it with "bite log
+  training". Setting 0 calories gives every day the same goal.`
+
+	foodTagLong = `  Food groups, such as a restaurant or "Home cooking", show how much
+  each source contributes to your diet in "bite summary tags". Select
+  the foods to put in the group, then enter "done". A food belongs to
+  one group, so tagging it again moves it to the new group.`
+
+	caffeineLong = `  Select a food and set the caffeine (mg) in a serving of it, or 0 to
+  unflag it. The day summary totals the caffeine of the day's foods and
+  warns about caffeine logged after caffeine_cutoff in the config file,
+  14:00 by default, since late caffeine disturbs sleep, which can hide
+  weight trends behind water retention and hunger.`
+
+	yieldLong = `  Select a food and set its cooked yield: the weight of a gram of it once
+  cooked, such as 0.75 for chicken breast that loses a quarter of its
+  weight or 2.5 for rice. Foods without a yield use the standard one for
+  their kind, from rice, pasta, grains, legumes, meats, and fish, or 1
+  when their name says they're cooked already. Pass 0 to go back to the
+  standard yield.`
+
+	giLong = `  The glycemic index (GI) of a food is how fast its carbs raise blood
+  sugar, from 0 to 100 for glucose. Set it for one food with "bite food
+  gi", or import a CSV file of foods, by id or exact name, and their
+  glycemic indexes with "bite food import-gi". Each day's glycemic load
+  is then estimated from the available carbs, net of fiber, of its
+  foods with a GI, and shown in the day and diet summaries with the
+  share of the day's carbs it covers: under 80 is low and 120 or more
+  is high. Pass 0 to remove a food's GI.`
+
+	flagLong = `  Flag the foods you select as containing allergens or animal products:
+  gluten, dairy, egg, nuts, peanuts, soy, fish, shellfish, or meat. Set
+  restrictions in the config file to the flags you avoid or to a diet,
+  vegan, vegetarian, or pescatarian, such as restrictions = ["vegan",
+  "gluten"]. Logging a flagged food you avoid prints a warning, and it
+  is marked in search results and left out of recent foods. Pass
+  --remove to take flags off foods.`
+
+	importMenuLong = `  Chain restaurants publish the nutrition of their menu items, often as
+  a spreadsheet. Save it as CSV with a header row naming the item name,
+  calories, protein, fat, and carbs columns, and optionally a serving
+  size column in grams. The items are added as foods with the
+  restaurant as their brand and food group. Importing a newer menu
+  replaces the nutrition of the items already imported.`
+
+	suppLong = `  Supplements, such as creatine, vitamin D, or fish oil, are taken every
+  day and have no calories, so they are tracked apart from foods. Log
+  them with "bite log supp <name>", see how many days a week each was
+  taken with "bite summary supps", and get reminders of the ones due
+  with "bite notify".`
+
+	notifyLong = `  Print a line for each supplement due by now that hasn't been logged
+  today, a reminder to weigh in once the weigh-in window has opened,
+  a warning when today's weight was logged outside it, or a warning when
+  the trend weight has drifted more than maintenance_drift (3 lbs by
+  default) from the weight the last completed cut or bulk ended at, and
+  nothing when nothing is due. Run it from cron or a timer to get desktop
+  notifications, e.g.
+
+    bite notify | while read -r line; do notify-send bite "$line"; done`
+
+	tagLong = `  Tags are single words, such as high-protein, vegan, or prep-friendly,
+  and a food or meal can have any number of them. Add "tag:<tag>" to a
+  search to keep only the foods or meals with the tag, e.g. "chicken
+  tag:high-protein". A search of only tags lists everything with them.`
+
+	leftoversLong = `  When logging a meal cooked in bulk, enter the number of servings left
+  over. Leftovers lists what's in the fridge and how soon to eat it.
+  Cooked food is assumed to keep for 4 days. Enter a leftover's index to
+  log a serving of the meal today, or "d" and its index to throw it out.`
+
+	prepLong = `  Plan next week's lunches and dinners from the meals tagged
+  prep-friendly, such as with "bite tag meal prep-friendly". Each meal
+  aims for 30% of the day's calorie and macro goals: meals are
+  portioned in quarters to the calories, and up to 4 of the meals whose
+  macros come closest are cooked and take turns across the week. The
+  plan is printed with the servings of each meal to cook and a grocery
+  list of their foods, less what's in the pantry.`
+
+	receiptLong = `  Read a store receipt, as CSV or text with an item a line such as
+  "OATS 1KG 3.49", and update the prices of the foods bought. Each item
+  is matched to a food by searching for its name: enter the index of
+  the right food, another search term, or nothing to skip the item.
+  Items matched before are matched to the same food again. Without a
+  size on the receipt, enter the quantity bought in the food's serving
+  unit. Every price is kept in the food's price history, and the latest
+  one is the price of the food logged from then on.`
+
+	pantryLong = `  Track the quantity on hand of foods, in their serving unit, with
+  "bite pantry add". Logging a food takes the quantity eaten out of the
+  pantry. Staples are foods kept stocked at a par quantity, set with
+  "bite pantry staple", and run low at a quarter of it. "bite pantry
+  low" lists the staples running low, and "bite pantry grocery" prints
+  a grocery list of them with the quantity that restocks each.`
+
+	fillWeightsLong = `  Fill weights estimates the days without a weigh-in by drawing a
+  straight line between the logged weights on either side, so sparse
+  logging doesn't skew the weekly weight change. Estimates are flagged
+  and replaced when a weight is logged for that day. Pass --checks
+  exclude to leave them out of the diet checks.`
+	quickLong = `  Separate foods with commas, "+", or "and". Each food is written as a
+  quantity, an optional unit, and a name, such as "2 eggs", "40g oats",
+  or "1/2 cup milk". Without a unit, the quantity is the number of
+  servings. Units are g, kg, oz, lb, ml, l, cup, tbsp, and tsp. When a
+  name matches more than one food, you pick which one you meant.
+
+  Put "cooked" before the name, as in "150g cooked chicken", to log the
+  cooked weight of a food whose data is for it raw or dry. The weight is
+  converted by the food's cooked yield; see "bite food yield".`
+	pasteLong = `  Paste foods from your notes, one per line in the form "qty unit name"
+  as in "bite q", and end with a blank line. Each line is matched to its
+  closest food, and the matches and their totals are shown before you
+  confirm. Lines that don't match a food are skipped. Pass --yes when
+  piping the list in.`
+	logEditLong = `  Edit the food log of a day all at once in $VISUAL or $EDITOR, falling
+  back to vi. Each entry is a line of the form
+  "id | time | servings x size unit | food". Change an entry's time,
+  servings, or food, delete its line to delete the entry, or add a line
+  without an id to log another food. A changed or added food is matched
+  to its closest food by name, as with "bite log paste". The changes are
+  saved together once the editor exits, and nothing is saved if any
+  line can't be applied.`
+	dietLong = `  Summarize the food log from --from to --to, or over this --month or
+  --week: the average and total calories, macros, and cost, the days
+  closest to and furthest from the calorie goal, and how the calories
+  from macros split between protein, carbs, and fat.`
+	icalLong = `  Write the active or scheduled diet phase as an .ics file for calendar
+  apps: the phase's start and end dates, its diet breaks and refeed
+  days, a weekly weigh-in reminder on the first day of each phase week,
+  and a weekly check-in on the last. Without a file name the calendar
+  is printed. Export again after the phase changes.`
+	journalLong = `  Write a week of the diet as Markdown for a diet journal: a table of
+  each day's weight, calories, calorie goal, and macros with a ✓ or ✗
+  for whether the goal was met, the week's averages, its fiber and
+  sugar with a warning when fiber fell short most days, and the week's
+  check-ins with their notes, supplements, and sleep. Weeks are ISO weeks, Monday to Sunday,
+  such as 2024-W12, and default to the current week. Without a file
+  name the journal is printed.`
+	historyLong = `  List the recent commands that changed the database, newest first,
+  with how many rows of each table they inserted, updated, and deleted.
+  Commands are recorded in the audit log; see "bite audit".`
+	redoLong = `  Repeat the last command that logged food, such as a protein shake
+  logged yesterday, by logging the same foods and servings again. Meals
+  it logged are logged again too. The foods are logged now, or on --date
+  at --time. Only log food, log meal, log paste, q, and redo are
+  repeated, not other commands that add food entries, such as a sync
+  import.`
+	auditLong = `  Every insert, update, and delete of the database is recorded in its
+  audit log with the command line that made it, the user who ran it,
+  and the row before and after the change. Changes made by other
+  programs are recorded too, but without a command. Inserts and
+  deletes are shown with the whole row, updates with the columns that
+  changed.`
+	createFoodLong = `  With --label, paste the nutrition facts of the food, such as "Serving
+  size 2/3 cup (55g)", "Calories 230", "Total Fat 8g", "Total
+  Carbohydrate 37g", and "Protein 3g", and end with a blank line. Only
+  the values that couldn't be read from the label are asked for.
+  Missing calories are calculated from the macros.`
+
+	logMealLong = `  With --portion, every food of a meal is logged at that fraction of its
+  servings, e.g. --portion 0.5 when eating half of a batch of chili.
+  Pressing l on a meal lets you enter the portion for that meal alone.`
+
+	slotsLong = `  Meal targets are the least protein, carbs, or fat (g) a meal slot
+  should have, set in the [meal_targets] table of the config file, e.g.
+
+    [meal_targets.breakfast]
+    protein = 30
+
+  Slots shows, for each slot with a target, on how many of the last 7
+  days with food logged in the slot its foods hit the target. Meals that
+  fall short of the target of the current slot are flagged when they
+  are shown for logging.`
+
+	createMealLong = `  With --wizard, search for the foods of the meal and press enter to
+  set the servings of a food and add it. The meal pane shows the
+  running calories, macros, and cost of the meal. Press tab to move
+  between the search results and the meal pane, s to change the
+  servings of a food in the meal, d to remove it, and c to name and
+  save the meal. Each food's servings are saved as its servings in the
+  meal.
+
+  With --from, the new meal starts as a copy of an existing meal's
+  foods and their servings, so a meal similar to one you already have
+  doesn't need to be built from scratch. Without --wizard the copy is
+  saved right away, and can then be changed with "bite log meal". With
+  --wizard the foods are added to the meal pane to tweak before
+  saving.`
 )
 
-func LogCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, logUsage)
-	}
-	dbPath := os.Getenv("BITE_DB_PATH")
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
-	}
-	db, err := sqlx.Connect("sqlite", dbPath)
+// syncLastExport is the setting that holds the time of the last sync
+// export.
+const syncLastExport = "sync.last_export"
+
+// dbPath is the path to the SQLite database. It defaults to the
+// BITE_DB_PATH environment variable and can be overridden with --db.
+var dbPath = os.Getenv(`BITE_DB_PATH`)
+
+// dryRun makes commands run on a copy of the database and print the
+// changes they would have saved.
+var dryRun bool
+
+// shareView is the path of the share that commands are viewing a copy
+// of, or empty if they aren't. Commands that would change the share
+// fail.
+var shareView string
+
+// outputJSON makes commands that support it print JSON.
+var outputJSON bool
+
+// LoadConfig reads the configuration file and applies its defaults.
+// Environment variables and flags take precedence over the file.
+func LoadConfig() error {
+	path, err := config.Path()
 	if err != nil {
 		return err
 	}
-	defer db.Close()
-	c, err := bite.Config(db)
+	c, err := config.Load(path)
 	if err != nil {
-		return fmt.Errorf("ERROR: reading config: %v", err)
+		return err
 	}
 
-	switch strings.ToLower(args[2]) {
-	case `meal`:
-		if err := NewSearchUI(db, "", `meal`).Run(); err != nil {
-			return fmt.Errorf("couldn't run search ui: %v", err)
+	if dbPath == "" {
+		dbPath = c.DBPath
+	}
+	outputJSON = c.JSON
+	bite.HooksDir = c.HooksDir
+	if bite.HooksDir == "" {
+		bite.HooksDir = filepath.Join(filepath.Dir(path), "hooks")
+	}
+	bite.TemplatesDir = c.TemplatesDir
+	if bite.TemplatesDir == "" {
+		bite.TemplatesDir = filepath.Join(filepath.Dir(path), "templates")
+	}
+	bite.PhotosDir = c.PhotosDir
+	bite.PreferVerified = c.PreferVerified
+	bite.NoColor = !term.IsTerminal(int(os.Stdout.Fd())) || os.Getenv(`TERM`) == `dumb`
+	if c.Colors.Enabled != nil {
+		bite.NoColor = !*c.Colors.Enabled
+	}
+	if os.Getenv(`NO_COLOR`) != "" {
+		bite.NoColor = true
+	}
+	if err := bite.SetAdherenceColors(c.Colors.Met, c.Colors.Missed); err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	if c.Locale != "" {
+		lang, ok := bite.ParseLocale(c.Locale)
+		if !ok {
+			return fmt.Errorf("invalid config file %s: no translation for locale %q", path, c.Locale)
+		}
+		bite.Locale = lang
+	} else {
+		// Follow the system locale, falling back to English for
+		// languages without a translation.
+		for _, v := range []string{`LC_ALL`, `LC_MESSAGES`, `LANG`} {
+			if name := os.Getenv(v); name != "" {
+				if lang, ok := bite.ParseLocale(name); ok {
+					bite.Locale = lang
+				}
+				break
+			}
 		}
-		if err := SummaryCmd([]string{`zet`, `summary`, `diet`, `day`}); err != nil {
-			return fmt.Errorf("couldn't get daily summary: %v", err)
+	}
+	if c.Units != "" {
+		bite.DefaultSystem = c.Units
+	}
+	for name, start := range c.MealSlots {
+		slot, err := bite.ParseMealSlot(name)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
 		}
-	case `food`:
-		if err := NewSearchUI(db, "", `food`).Run(); err != nil {
-			return fmt.Errorf("couldn't run search ui: %v", err)
+		t, err := time.Parse("15:04", start)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: meal slot %s must be HH:MM, got %q", path, slot, start)
 		}
-		if err := SummaryCmd([]string{`zet`, `summary`, `diet`, `day`}); err != nil {
-			return fmt.Errorf("couldn't get daily summary: %v", err)
+		bite.SlotTimes[slot] = t.Format("15:04:05")
+	}
+	for name, t := range c.MealTargets {
+		slot, err := bite.ParseMealSlot(name)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
 		}
-	case `weight`:
-		if err := bite.LogWeight(c, db); err != nil {
-			return err
+		bite.SlotTargets[slot] = bite.MacroTarget{Protein: t.Protein, Carbs: t.Carbs, Fat: t.Fat}
+	}
+	if c.Adherence.DayTolerance != 0 {
+		bite.DayCalTolerance = c.Adherence.DayTolerance
+	}
+	if c.Adherence.WeekDays != 0 {
+		bite.WeekAdherence = c.Adherence.WeekDays
+	}
+	if c.Adherence.PhaseWeeks != 0 {
+		bite.PhaseAdherence = c.Adherence.PhaseWeeks
+	}
+	if c.CalorieFloor != 0 {
+		bite.CalorieFloor = c.CalorieFloor
+	}
+	if c.ManualGoal != "" {
+		bite.ManualGoalPolicy = c.ManualGoal
+	}
+	bite.FiberMin = c.FiberMin
+	bite.SugarMax = c.SugarMax
+	bite.SaturatedFatMax = c.SaturatedFatMax
+	if c.SodiumHigh != 0 {
+		bite.SodiumHigh = c.SodiumHigh
+	}
+	if c.MaintenanceDrift != 0 {
+		bite.MaintenanceDrift = c.MaintenanceDrift
+	}
+	bite.WeeklyBudget = c.WeeklyBudget
+	restrictions, err := bite.ParseRestrictions(c.Restrictions)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	bite.Restrictions = restrictions
+	for name, spec := range c.PhaseTemplates {
+		steps, err := bite.ParsePhaseTemplate(spec)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: phase template %s: %v", path, name, err)
 		}
-	case `update`:
-		if n < 4 {
-			printUsageExit(`ERROR: Not enough arguments`, logUsage)
+		bite.PhaseTemplates[strings.ToLower(name)] = steps
+	}
+	if c.CaffeineCutoff != "" {
+		t, err := time.Parse("15:04", c.CaffeineCutoff)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: caffeine_cutoff must be HH:MM, got %q", path, c.CaffeineCutoff)
 		}
-		switch strings.ToLower(args[3]) {
-		case `food`:
-			if err := bite.UpdateFoodLog(db); err != nil {
-				return err
-			}
-		case `weight`:
-			if err := bite.UpdateWeightLog(db, c); err != nil {
-				return err
+		bite.CaffeineCutoff = t.Format("15:04")
+	}
+	if c.WeighIn.Time != "" {
+		t, err := time.Parse("15:04", c.WeighIn.Time)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: weigh_in.time must be HH:MM, got %q", path, c.WeighIn.Time)
+		}
+		bite.WeighInTime = t.Format("15:04")
+	}
+	if c.WeighIn.Window != 0 {
+		bite.WeighInWindow = time.Duration(c.WeighIn.Window) * time.Minute
+	}
+	bite.WeighInConditions = c.WeighIn.Conditions
+	if c.Precision.Weight != nil {
+		bite.WeightDecimals = *c.Precision.Weight
+	}
+	if c.Precision.Macros != nil {
+		bite.MacroDecimals = *c.Precision.Macros
+	}
+	if c.Search.FrequencyWeight != nil {
+		bite.SearchFrequencyWeight = *c.Search.FrequencyWeight
+	}
+	if c.Search.RecencyWeight != nil {
+		bite.SearchRecencyWeight = *c.Search.RecencyWeight
+	}
+	if a := c.NutritionAPI; a.Provider != "" {
+		bite.RemoteFoods = &bite.NutritionAPI{
+			Provider: a.Provider,
+			Key:      a.APIKey,
+			AppID:    a.AppID,
+			URL:      a.URL,
+		}
+	}
+	if cy := c.Cycle; cy.PeriodStart != "" {
+		start, err := time.Parse("2006-01-02", cy.PeriodStart)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: cycle.period_start must be YYYY-MM-DD, got %q", path, cy.PeriodStart)
+		}
+		bite.Cycle = bite.NewMenstrualCycle(start, cy.Length, cy.DaysBefore, cy.DaysAfter)
+	}
+	if c.WeekStart != "" {
+		d, err := bite.ParseWeekday(c.WeekStart)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
+		}
+		bite.WeekStart = d
+	}
+	if len(c.DateFormats) > 0 {
+		layouts := make([]string, len(c.DateFormats))
+		for i, f := range c.DateFormats {
+			layout, err := bite.ParseDateFormat(f)
+			if err != nil {
+				return fmt.Errorf("invalid config file %s: %v", path, err)
 			}
-		default:
-			printUsageExit(`ERROR: Incorrect argument`, logUsage)
+			layouts[i] = layout
+		}
+		bite.DateFormats = layouts
+	}
+	if c.TimeZone != "" {
+		loc, err := time.LoadLocation(c.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: %v", path, err)
 		}
-	case `delete`:
-		if n < 4 {
-			printUsageExit(`ERROR: Not enough arguments`, logUsage)
+		bite.Location = loc
+	}
+	bite.DayBoundaryHour = c.DayBoundaryHour
+	if c.LateNightCutoff != "" {
+		t, err := time.Parse("15:04", c.LateNightCutoff)
+		if err != nil {
+			return fmt.Errorf("invalid config file %s: late_night_cutoff must be HH:MM, got %q", path, c.LateNightCutoff)
 		}
-		switch strings.ToLower(args[3]) {
-		case `food`:
-			if err := bite.DeleteFoodEntry(db); err != nil {
-				return err
+		bite.LateNightCutoff = t.Format("15:04")
+	}
+	return nil
+}
+
+// Root returns the root bite command.
+func Root() *Command {
+	return &Command{
+		Name: `bite`,
+		Long: rootLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dbPath, `db`, dbPath, `path to the SQLite database, also set by $BITE_DB_PATH`)
+			fs.BoolVar(&outputJSON, `json`, outputJSON, `print JSON where supported`)
+			fs.BoolVar(&dryRun, `dry-run`, false, `print the changes a command would save without saving them`)
+			fs.BoolVar(&bite.NoColor, `no-color`, bite.NoColor, `print without colors, also set by $NO_COLOR`)
+			fs.Func(`units`, `measurement system for new user details: metric or imperial`, func(s string) error {
+				if s != `metric` && s != `imperial` {
+					return errors.New(`must be "metric" or "imperial"`)
+				}
+				bite.DefaultSystem = s
+				return nil
+			})
+		},
+		Commands: []*Command{
+			logCmd(),
+			quickCmd(),
+			createCmd(),
+			foodCmd(),
+			tagCmd(),
+			deleteCmd(),
+			updateCmd(),
+			summaryCmd(),
+			stopCmd(),
+			checkinCmd(),
+			noteCmd(),
+			leftoversCmd(),
+			pantryCmd(),
+			prepCmd(),
+			suppCmd(),
+			conditionCmd(),
+			notifyCmd(),
+			photoCmd(),
+			breakCmd(),
+			phaseCmd(),
+			recipeCmd(),
+			keysCmd(),
+			dbCmd(),
+			syncCmd(),
+			shareCmd(),
+			exportCmd(),
+			importCmd(),
+			serveCmd(),
+			auditCmd(),
+			historyCmd(),
+			redoCmd(),
+		},
+	}
+}
+
+func logCmd() *Command {
+	var query string
+	queryFlag := func(fs *flag.FlagSet) {
+		fs.StringVar(&query, `query`, ``, `initial search query`)
+	}
+	var date, stepsFile, portion string
+	var start, end, checks string
+	var yes, attachments bool
+	var page int
+	var all bool
+	pageFlags := func(fs *flag.FlagSet) {
+		fs.IntVar(&page, `page`, 1, `page of the log to show, 1 being the most recent`)
+		fs.BoolVar(&all, `all`, false, `show every entry`)
+	}
+	logPage := func() (bite.Page, error) {
+		if all {
+			return bite.Page{}, nil
+		}
+		if page < 1 {
+			return bite.Page{}, fmt.Errorf("invalid --page %d: must be at least 1", page)
+		}
+		return bite.Page{Number: page, Size: bite.LogPageSize}, nil
+	}
+
+	return &Command{
+		Name:  `log`,
+		Short: `Manages food, meal, and weight log.`,
+		Commands: []*Command{
+			{
+				Name:  `food`,
+				Short: `Log food.`,
+				Flags: queryFlag,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					if err := NewSearchUI(db, query, `food`).Run(); err != nil {
+						return fmt.Errorf("couldn't run search ui: %v", err)
+					}
+					return daySummary(db)
+				}),
+			},
+			{
+				Name:  `meal`,
+				Short: `Log meal.`,
+				Long:  logMealLong,
+				Flags: func(fs *flag.FlagSet) {
+					queryFlag(fs)
+					fs.StringVar(&portion, `portion`, `1`, `portion of the meal eaten, e.g. 0.5 or 1/2`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					p, err := bite.ParsePortion(portion)
+					if err != nil {
+						return err
+					}
+					sui := NewSearchUI(db, query, `meal`)
+					sui.portion = p
+					if err := sui.Run(); err != nil {
+						return fmt.Errorf("couldn't run search ui: %v", err)
+					}
+					return daySummary(db)
+				}),
+			},
+			{
+				Name:  `weight`,
+				Short: `Log weight.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.LogWeight(c, db)
+				}),
+			},
+			{
+				Name:  `paste`,
+				Short: `Log a pasted list of foods, one per line.`,
+				Long:  pasteLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the entries (YYYY-MM-DD), defaults to now`)
+					fs.BoolVar(&yes, `yes`, false, `log without asking for confirmation`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					d := bite.FoodLogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+					return bite.PasteLog(db, bite.Input, d, yes)
+				}),
+			},
+			{
+				Name:  `edit`,
+				Short: `Edit a day's food log in an editor.`,
+				Long:  logEditLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the log to edit (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					d := bite.FoodLogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+					return bite.EditFoodLog(db, d, editText)
+				}),
+			},
+			{
+				Name:  `fill-weights`,
+				Short: `Estimate missing weights between logged weights.`,
+				Long:  fillWeightsLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&start, `start`, ``, `first date to fill (YYYY-MM-DD), defaults to the first logged weight`)
+					fs.StringVar(&end, `end`, ``, `last date to fill (YYYY-MM-DD), defaults to today`)
+					fs.StringVar(&checks, `checks`, ``, `whether the diet checks "include" or "exclude" estimated weights`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					from, to := time.Time{}, bite.LogNow()
+					var err error
+					if start != "" {
+						if from, err = bite.ValidateDateStr(start); err != nil {
+							return fmt.Errorf("invalid --start %q: %v", start, err)
+						}
+					}
+					if end != "" {
+						if to, err = bite.ValidateDateStr(end); err != nil {
+							return fmt.Errorf("invalid --end %q: %v", end, err)
+						}
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					switch checks {
+					case ``:
+					case `include`, `exclude`:
+						if err := bite.SetExcludeEstimates(tx, checks == `exclude`); err != nil {
+							return err
+						}
+					default:
+						return fmt.Errorf("invalid --checks %q: must be include or exclude", checks)
+					}
+					n, err := bite.FillWeights(tx, from, to)
+					if err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Estimated weights for %d days.\n", n)
+					return nil
+				}),
+			},
+			{
+				Name:  `steps`,
+				Short: `Log daily step count.`,
+				Args:  `[steps]`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the step count (YYYY-MM-DD), defaults to today`)
+					fs.StringVar(&stepsFile, `import`, ``, `import step counts from a CSV file of date and steps`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, args []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+
+					switch {
+					case stepsFile != "" && len(args) == 0:
+						f, err := os.Open(stepsFile)
+						if err != nil {
+							return fmt.Errorf("couldn't open steps file: %v", err)
+						}
+						defer f.Close()
+						n, err := bite.ImportSteps(tx, f)
+						if err != nil {
+							return err
+						}
+						fmt.Printf("Imported steps for %d days.\n", n)
+					case stepsFile == "" && len(args) == 1:
+						d := bite.LogNow()
+						if date != "" {
+							d, err = bite.ValidateDateStr(date)
+							if err != nil {
+								return fmt.Errorf("invalid --date %q: %v", date, err)
+							}
+						}
+						steps, err := strconv.Atoi(args[0])
+						if err != nil {
+							return fmt.Errorf("invalid steps %q", args[0])
+						}
+						if err := bite.LogSteps(tx, d, steps); err != nil {
+							return err
+						}
+					default:
+						return errors.New("steps takes a step count or --import")
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+
+					avg, err := bite.RollingSteps(db, bite.LogNow())
+					if err != nil {
+						return err
+					}
+					fmt.Printf("7-day average: %.0f steps.\n", avg)
+					drop, err := bite.CheckSteps(db, c, bite.LogNow())
+					if err != nil {
+						return err
+					}
+					if drop != nil {
+						bite.PrintStepsDrop(drop)
+					}
+					return nil
+				}),
+			},
+			{
+				Name:  `training`,
+				Short: `Log a training session.`,
+				Args:  `<type> <minutes> [calories]`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the session (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) < 2 || len(args) > 3 {
+						return errors.New("training takes a type, minutes, and optionally calories burned")
+					}
+					e := bite.TrainingEntry{Type: args[0], Date: bite.LogNow()}
+					if date != "" {
+						d, err := bite.ValidateDateStr(date)
+						if err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+						e.Date = d
+					}
+					minutes, err := strconv.ParseFloat(args[1], 64)
+					if err != nil || minutes <= 0 {
+						return fmt.Errorf("invalid minutes %q", args[1])
+					}
+					e.Duration = minutes
+					if len(args) == 3 {
+						cals, err := strconv.ParseFloat(args[2], 64)
+						if err != nil || cals < 0 {
+							return fmt.Errorf("invalid calories %q", args[2])
+						}
+						e.Calories = cals
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.AddTrainingEntry(tx, &e); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `sleep`,
+				Short: `Log hours slept.`,
+				Args:  `<hours>`,
+				Long:  sleepLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the morning after the night (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("sleep takes the hours slept, such as 7.5h or 7h30m")
+					}
+					hours, err := bite.ParseSleep(args[0])
+					if err != nil {
+						return err
+					}
+					d := bite.LogNow()
+					if date != "" {
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.LogSleep(tx, d, hours); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `lift`,
+				Short: `Log a lifting set.`,
+				Args:  `<lift> <weight> <reps>`,
+				Long:  liftLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the set (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) < 3 {
+						return errors.New("lift takes a lift name, weight, and reps")
+					}
+					s := bite.LiftSet{Lift: strings.Join(args[:len(args)-2], " "), Date: bite.LogNow()}
+					if date != "" {
+						d, err := bite.ValidateDateStr(date)
+						if err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+						s.Date = d
+					}
+					w := args[len(args)-2]
+					weight, err := strconv.ParseFloat(w, 64)
+					if err != nil || weight <= 0 {
+						return fmt.Errorf("invalid weight %q", w)
+					}
+					s.Weight = weight
+					r := args[len(args)-1]
+					reps, err := strconv.Atoi(r)
+					if err != nil || reps < 1 {
+						return fmt.Errorf("invalid reps %q", r)
+					}
+					s.Reps = reps
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.AddLiftSet(tx, &s); err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Logged %s %s x %d, an estimated one-rep max of %s.\n", s.Lift,
+						bite.FormatWeight(s.Weight), s.Reps, bite.FormatWeight(s.E1RM()))
+					return nil
+				}),
+			},
+			{
+				Name:  `supp`,
+				Short: `Log supplements taken.`,
+				Args:  `<supplement>...`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date the supplements were taken (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("supp takes the supplements to log")
+					}
+					d := bite.LogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					for _, name := range args {
+						if err := bite.LogSupplement(tx, name, d); err != nil {
+							return err
+						}
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Logged %s.\n", strings.Join(args, ", "))
+					return nil
+				}),
+			},
+			{
+				Name:  `update`,
+				Short: `Update food or weight log.`,
+				Commands: []*Command{
+					{
+						Name:  `food`,
+						Short: `Update food log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.UpdateFoodLog(db)
+						}),
+					},
+					{
+						Name:  `weight`,
+						Short: `Update weight log.`,
+						Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+							return bite.UpdateWeightLog(db, c)
+						}),
+					},
+				},
+			},
+			{
+				Name:  `delete`,
+				Short: `Delete food or weight log.`,
+				Commands: []*Command{
+					{
+						Name:  `food`,
+						Short: `Delete food log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.DeleteFoodEntry(db)
+						}),
+					},
+					{
+						Name:  `weight`,
+						Short: `Delete weight log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.DeleteWeightEntry(db)
+						}),
+					},
+				},
+			},
+			{
+				Name:  `attach`,
+				Short: `Attach a local file, such as a photo, to a log entry.`,
+				Commands: []*Command{
+					{
+						Name:  `weight`,
+						Short: `Attach a file to the weight entry of a date.`,
+						Args:  `<date> <path>`,
+						Run: withDB(func(db *sqlx.DB, args []string) error {
+							if len(args) != 2 {
+								return errors.New("attach weight takes a date and a file path")
+							}
+							d, err := bite.ValidateDateStr(args[0])
+							if err != nil {
+								return fmt.Errorf("invalid date %q: %v", args[0], err)
+							}
+							return bite.AttachWeight(db, d, args[1])
+						}),
+					},
+					{
+						Name:  `food`,
+						Short: `Attach a file to a selected food entry.`,
+						Args:  `<path>`,
+						Run: withDB(func(db *sqlx.DB, args []string) error {
+							if len(args) != 1 {
+								return errors.New("attach food takes a file path")
+							}
+							return bite.AttachFood(db, args[0])
+						}),
+					},
+				},
+			},
+			{
+				Name:  `show`,
+				Short: `Shows food, weight, training, and strength log and full log.`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&attachments, `attachments`, false, `list the files attached to entries`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					if !attachments {
+						return errors.New("show takes a log to show or --attachments")
+					}
+					as, err := bite.Attachments(db)
+					if err != nil {
+						return err
+					}
+					if outputJSON {
+						return json.NewEncoder(os.Stdout).Encode(as)
+					}
+					bite.PrintAttachments(as)
+					return nil
+				}),
+				Commands: []*Command{
+					{
+						Name:  `all`,
+						Short: `Show full log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							entries, err := bite.AllEntries(db)
+							if err != nil {
+								return err
+							}
+							if outputJSON {
+								return json.NewEncoder(os.Stdout).Encode(entries)
+							}
+							bite.PrintEntries(*entries)
+							return nil
+						}),
+					},
+					{
+						Name:  `food`,
+						Short: `Show food log.`,
+						Flags: pageFlags,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							p, err := logPage()
+							if err != nil {
+								return err
+							}
+							return bite.ShowFoodLog(db, p)
+						}),
+					},
+					{
+						Name:  `weight`,
+						Short: `Show weight log.`,
+						Flags: pageFlags,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							p, err := logPage()
+							if err != nil {
+								return err
+							}
+							return bite.ShowWeightLog(db, p)
+						}),
+					},
+					{
+						Name:  `training`,
+						Short: `Show training log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.ShowTrainingLog(db)
+						}),
+					},
+					{
+						Name:  `lifts`,
+						Short: `Show strength log.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.ShowLiftLog(db)
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+func quickCmd() *Command {
+	var date string
+	return &Command{
+		Name:  `q`,
+		Short: `Quickly logs foods written out, such as "2 eggs and 40g oats".`,
+		Args:  `<foods>`,
+		Long:  quickLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&date, `date`, ``, `date of the entries (YYYY-MM-DD), defaults to now`)
+		},
+		Run: withDB(func(db *sqlx.DB, args []string) error {
+			if len(args) == 0 {
+				return errors.New("q takes the foods to log")
+			}
+			d := bite.FoodLogNow()
+			if date != "" {
+				var err error
+				if d, err = bite.ValidateDateStr(date); err != nil {
+					return fmt.Errorf("invalid --date %q: %v", date, err)
+				}
 			}
-		case `weight`:
-			if err := bite.DeleteWeightEntry(db); err != nil {
+			if err := bite.QuickLog(db, strings.Join(args, " "), d); err != nil {
 				return err
 			}
-		default:
-			printUsageExit(`ERROR: Incorrect argument`, logUsage)
-		}
-	case `show`:
-		if n < 4 {
-			printUsageExit(`ERROR: Not enough arguments`, logUsage)
-		}
-		switch strings.ToLower(args[3]) {
-		case `all`:
-			entries, err := bite.AllEntries(db)
+			return daySummary(db)
+		}),
+	}
+}
+
+func createCmd() *Command {
+	var label, wizard bool
+	var from string
+
+	return &Command{
+		Name:  `create`,
+		Short: `Creates food or meal.`,
+		Commands: []*Command{
+			{
+				Name:  `food`,
+				Short: `Create new food.`,
+				Long:  createFoodLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&label, `label`, false, `read the nutrients from a pasted nutrition label`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					if label {
+						return bite.CreateFoodFromLabel(db, bite.Input)
+					}
+					return bite.CreateAddFood(db)
+				}),
+			},
+			{
+				Name:  `meal`,
+				Short: `Create new meal.`,
+				Args:  `[<name>]`,
+				Long:  createMealLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&wizard, `wizard`, false, `build the meal by searching for its foods`)
+					fs.StringVar(&from, `from`, ``, `start from a copy of the named meal`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					name := strings.Join(args, " ")
+					switch {
+					case !wizard && from != "":
+						return bite.DuplicateMeal(db, from, name)
+					case !wizard:
+						return bite.CreateAddMeal(db)
+					}
+
+					var foods []bite.Food
+					if from != "" {
+						var err error
+						if foods, err = mealFoods(db, from); err != nil {
+							return err
+						}
+					}
+					if err := NewMealWizard(db, name, foods).Run(); err != nil {
+						return fmt.Errorf("couldn't run meal wizard: %v", err)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func foodCmd() *Command {
+	var remove bool
+	return &Command{
+		Name:  `food`,
+		Short: `Manages food groups.`,
+		Commands: []*Command{
+			{
+				Name:  `tag`,
+				Short: `Put foods in a group, e.g. a restaurant.`,
+				Args:  `<group>`,
+				Long:  foodTagLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("tag takes a group name")
+					}
+					return bite.TagFoods(db, args[0])
+				}),
+			},
+			{
+				Name:  `info`,
+				Short: `Show a food's serving, macros, and fat breakdown.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.ShowFood(db)
+				}),
+			},
+			{
+				Name:  `caffeine`,
+				Short: `Flag a food as a caffeine source.`,
+				Args:  `<mg>`,
+				Long:  caffeineLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("caffeine takes the caffeine (mg) of a serving")
+					}
+					mg, err := strconv.ParseFloat(args[0], 64)
+					if err != nil || mg < 0 {
+						return fmt.Errorf("invalid caffeine %q", args[0])
+					}
+					return bite.FlagCaffeine(db, mg)
+				}),
+			},
+			{
+				Name:  `yield`,
+				Short: `Set the cooked weight of a gram of a raw food.`,
+				Args:  `<factor>`,
+				Long:  yieldLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("yield takes the cooked weight of a gram of the food")
+					}
+					yield, err := strconv.ParseFloat(args[0], 64)
+					if err != nil || yield < 0 {
+						return fmt.Errorf("invalid cooked yield %q", args[0])
+					}
+					return bite.FlagCookedYield(db, yield)
+				}),
+			},
+			{
+				Name:  `gi`,
+				Short: `Set the glycemic index of a food.`,
+				Args:  `<index>`,
+				Long:  giLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("gi takes the glycemic index of the food")
+					}
+					gi, err := strconv.ParseFloat(args[0], 64)
+					if err != nil || gi < 0 {
+						return fmt.Errorf("invalid glycemic index %q", args[0])
+					}
+					return bite.FlagGlycemicIndex(db, gi)
+				}),
+			},
+			{
+				Name:  `flag`,
+				Short: `Flag foods as containing allergens, e.g. "gluten".`,
+				Args:  `<flag>...`,
+				Long:  flagLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&remove, `remove`, false, `remove the flags instead of adding them`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("flag takes at least one food flag")
+					}
+					return bite.FlagFoods(db, args, remove)
+				}),
+			},
+			{
+				Name:  `import-gi`,
+				Short: `Import the glycemic indexes of foods from a CSV file.`,
+				Args:  `<file>`,
+				Long:  giLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("import-gi takes a CSV file")
+					}
+					f, err := os.Open(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't open glycemic index file: %v", err)
+					}
+					defer f.Close()
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					res, err := bite.ImportGlycemicIndexes(tx, f)
+					if err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Set the glycemic index of %d foods.\n", res.Set)
+					if len(res.Unmatched) > 0 {
+						fmt.Printf("No food matched: %s\n", strings.Join(res.Unmatched, ", "))
+					}
+					return nil
+				}),
+			},
+			{
+				Name:  `import-menu`,
+				Short: `Import a restaurant's nutrition CSV file as foods.`,
+				Args:  `<restaurant> <file>`,
+				Long:  importMenuLong,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 2 {
+						return errors.New("import-menu takes a restaurant name and a CSV file")
+					}
+					f, err := os.Open(args[1])
+					if err != nil {
+						return fmt.Errorf("couldn't open menu file: %v", err)
+					}
+					defer f.Close()
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					res, err := bite.ImportMenu(tx, f, args[0])
+					if err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Imported %d new and %d updated %s menu items.\n", res.Added, res.Updated, args[0])
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func tagCmd() *Command {
+	var remove bool
+	removeFlag := func(fs *flag.FlagSet) {
+		fs.BoolVar(&remove, `remove`, false, `remove the tags instead of adding them`)
+	}
+
+	return &Command{
+		Name:  `tag`,
+		Short: `Tags foods and meals, e.g. "vegan".`,
+		Long:  tagLong,
+		Commands: []*Command{
+			{
+				Name:  `food`,
+				Short: `Add tags to foods.`,
+				Args:  `<tag>...`,
+				Flags: removeFlag,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("food takes at least one tag")
+					}
+					return bite.EditFoodTags(db, args, remove)
+				}),
+			},
+			{
+				Name:  `meal`,
+				Short: `Add tags to a meal.`,
+				Args:  `<tag>...`,
+				Flags: removeFlag,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("meal takes at least one tag")
+					}
+					return bite.EditMealTags(db, args, remove)
+				}),
+			},
+			{
+				Name:  `list`,
+				Short: `List tags and how often they were logged.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.ListTags(db)
+				}),
+			},
+		},
+	}
+}
+
+func deleteCmd() *Command {
+	return &Command{
+		Name:  `delete`,
+		Short: `Deletes food or meal.`,
+		Commands: []*Command{
+			{
+				Name:  `food`,
+				Short: `Delete existing food.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.SelectDeleteFood(db)
+				}),
+			},
+			{
+				Name:  `meal`,
+				Short: `Delete existing meal.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.SelectDeleteMeal(db)
+				}),
+			},
+		},
+	}
+}
+
+func updateCmd() *Command {
+	var goalCals float64
+	return &Command{
+		Name:  `update`,
+		Short: `Updates food, meal, or user information.`,
+		Commands: []*Command{
+			{
+				Name:  `user`,
+				Short: `Update user information.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.UpdateUserInfo(db, c)
+				}),
+			},
+			{
+				Name:  `goal`,
+				Short: `Set the calorie goal of the diet phase by hand.`,
+				Long:  goalLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.Float64Var(&goalCals, `cals`, 0, `daily calorie goal`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					if goalCals == 0 {
+						return errors.New("--cals must be set")
+					}
+					return bite.SetGoalCalories(db, c, goalCals)
+				}),
+			},
+			{
+				Name:  `training`,
+				Short: `Set extra calories for training days.`,
+				Args:  `<calories> <days-per-week>`,
+				Long:  trainingLong,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, args []string) error {
+					if len(args) != 2 {
+						return errors.New("training takes calories and training days per week")
+					}
+					cals, err := strconv.ParseFloat(args[0], 64)
+					if err != nil {
+						return fmt.Errorf("invalid calories %q", args[0])
+					}
+					days, err := strconv.Atoi(args[1])
+					if err != nil {
+						return fmt.Errorf("invalid training days %q", args[1])
+					}
+					if err := bite.SetTrainingCalories(db, c, cals, days); err != nil {
+						return err
+					}
+					fmt.Printf("Training day goal: %.0f calories. Rest day goal: %.0f calories.\n",
+						bite.DayGoalCalories(c, true), bite.DayGoalCalories(c, false))
+					return nil
+				}),
+			},
+			{
+				Name:  `food`,
+				Short: `Update food information.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.UpdateFood(db)
+				}),
+			},
+			{
+				Name:  `meal`,
+				Short: `Update the foods of a meal.`,
+				Commands: []*Command{
+					{
+						Name:  `add`,
+						Short: `Add a food to an existing meal.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.PromptAddMealFood(db)
+						}),
+					},
+					{
+						Name:  `delete`,
+						Short: `Delete a food from an existing meal.`,
+						Run: withDB(func(db *sqlx.DB, _ []string) error {
+							return bite.SelectDeleteFoodMealFood(db)
+						}),
+					},
+				},
+			},
+		},
+	}
+}
+
+func summaryCmd() *Command {
+	var weeks int
+	var from, to string
+	var month, week bool
+	var dietCmd *Command
+	dietCmd = &Command{
+		Name:  `diet`,
+		Short: `Print diet summary.`,
+		Long:  dietLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&from, `from`, ``, `first date of the summary (YYYY-MM-DD)`)
+			fs.StringVar(&to, `to`, ``, `last date of the summary (YYYY-MM-DD), defaults to today`)
+			fs.BoolVar(&month, `month`, false, `summarize this month`)
+			fs.BoolVar(&week, `week`, false, `summarize this week`)
+		},
+		Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+			start, end := time.Time{}, bite.LogNow()
+			var err error
+			switch {
+			case month && week:
+				return errors.New("--month and --week can't be used together")
+			case (month || week) && (from != "" || to != ""):
+				return errors.New("--month and --week can't be used with --from or --to")
+			case month:
+				start, end = bite.MonthRange(bite.LogNow())
+			case week:
+				start, end = bite.WeekRange(bite.LogNow())
+			case from == "":
+				return dietCmd.usageErr(`Not enough arguments`)
+			default:
+				if start, err = bite.ValidateDateStr(from); err != nil {
+					return fmt.Errorf("invalid --from %q: %v", from, err)
+				}
+				if to != "" {
+					if end, err = bite.ValidateDateStr(to); err != nil {
+						return fmt.Errorf("invalid --to %q: %v", to, err)
+					}
+				}
+				if end.Before(start) {
+					return errors.New("--to can't be before --from")
+				}
+			}
+
+			tx, err := db.Beginx()
 			if err != nil {
 				return err
 			}
-			bite.PrintEntries(*entries)
-		case `food`:
-			if err := bite.ShowFoodLog(db); err != nil {
+			defer tx.Rollback()
+			s, err := bite.DietRange(tx, c, start, end)
+			if err != nil {
 				return err
 			}
-		case `weight`:
-			if err := bite.ShowWeightLog(db); err != nil {
+			if err := bite.MarkGlycemicLoads(tx, s); err != nil {
 				return err
 			}
-		default:
-			printUsageExit(`ERROR: Incorrect argument`, logUsage)
-		}
-	case `help`:
-		fmt.Printf(logUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, logUsage)
+			bite.PrintRangeSummary(s)
+			return tx.Commit()
+		}),
+		Commands: []*Command{
+			{
+				Name:  `all`,
+				Short: `Print summary of the entire food log.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.FoodLogSummary(db)
+				}),
+			},
+			{
+				Name:  `day`,
+				Short: `Print summary of today's food log.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return daySummary(db)
+				}),
+			},
+		},
+	}
+
+	return &Command{
+		Name:  `summary`,
+		Short: `Provides phase, diet, and user summary.`,
+		Commands: []*Command{
+			{
+				Name:  `phase`,
+				Short: `Print phase summary.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					activeLog, err := activePhaseLog(db, c)
+					if err != nil {
+						return err
+					}
+
+					// Get user progress.
+					if err := bite.CheckProgress(db, c, activeLog); err != nil {
+						return err
+					}
+					drop, err := bite.CheckSteps(db, c, bite.LogNow())
+					if err != nil {
+						return err
+					}
+					if drop != nil {
+						bite.PrintStepsDrop(drop)
+					}
+
+					bite.Summary(c, activeLog)
+					if err := bite.TrainingSummary(db, c); err != nil {
+						return err
+					}
+					return bite.StrengthSummary(db, c)
+				}),
+			},
+			{
+				Name:  `progress`,
+				Short: `Show phase progress screen.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					activeLog, err := activePhaseLog(db, c)
+					if err != nil {
+						return err
+					}
+
+					if err := NewPhaseUI(c, *activeLog).Run(); err != nil {
+						return fmt.Errorf("couldn't run phase ui: %v", err)
+					}
+					return nil
+				}),
+			},
+			dietCmd,
+			{
+				Name:  `tags`,
+				Short: `Print calories, macros, and cost by food group per week.`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&weeks, `weeks`, 4, `number of weeks to show`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.GroupSummary(db, weeks, bite.LogNow())
+				}),
+			},
+			{
+				Name:  `supps`,
+				Short: `Print how many days each supplement was taken per week.`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&weeks, `weeks`, 4, `number of weeks to show`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.SupplementSummary(db, weeks, bite.LogNow())
+				}),
+			},
+			{
+				Name:  `sleep`,
+				Short: `Compare weeks of poor sleep with weight change and adherence.`,
+				Long:  sleepSummaryLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&weeks, `weeks`, 8, `number of weeks to compare`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.SleepReport(db, c, weeks, bite.LogNow())
+				}),
+			},
+			{
+				Name:  `tdee`,
+				Short: `Chart the TDEE back-calculated from each week of the diet phase.`,
+				Long:  tdeeLong,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					activeLog, err := activePhaseLog(db, c)
+					if err != nil {
+						return err
+					}
+					bite.PrintTDEE(c, *activeLog)
+					return nil
+				}),
+			},
+			{
+				Name:  `adjustments`,
+				Short: `Print the calorie goal adjustments of the diet phase.`,
+				Long:  adjustmentsLong,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.AdjustmentSummary(db, c)
+				}),
+			},
+			{
+				Name:  `slots`,
+				Short: `Print how often each meal slot hit its macro target this week.`,
+				Long:  slotsLong,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.SlotSummary(db, bite.LogNow())
+				}),
+			},
+			{
+				Name:  `user`,
+				Short: `Print user summary.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					bite.PrintUserInfo(c)
+					bite.PrintConditionDisclaimers(c)
+					return nil
+				}),
+			},
+		},
 	}
-	return nil
 }
 
-func CreateCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, createUsage)
+func stopCmd() *Command {
+	return &Command{
+		Name:  `stop`,
+		Short: `Stops a current phase.`,
+		Commands: []*Command{
+			{
+				Name:  `phase`,
+				Short: `Stop current phase.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.StopPhase(db, c)
+				}),
+			},
+		},
 	}
-	dbPath := os.Getenv(`BITE_DB_PATH`)
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
+}
+
+func noteCmd() *Command {
+	var date string
+	return &Command{
+		Name:  `note`,
+		Short: `Annotates the timeline with life events.`,
+		Long:  noteLong,
+		Commands: []*Command{
+			{
+				Name:  `add`,
+				Short: `Add a note to the timeline.`,
+				Args:  `<note>...`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the event (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("add takes the note, such as \"started new job\"")
+					}
+					d := bite.LogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					n, err := bite.AddNote(tx, d, strings.Join(args, " "))
+					if err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Added note %d: %s\n", n.ID, n)
+					return nil
+				}),
+			},
+			{
+				Name:  `list`,
+				Short: `List the notes on the timeline.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.PrintNotes(db)
+				}),
+			},
+			{
+				Name:  `delete`,
+				Short: `Delete a note from the timeline.`,
+				Args:  `<id>`,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("delete takes the id of a note, listed by \"bite note list\"")
+					}
+					id, err := strconv.Atoi(args[0])
+					if err != nil {
+						return fmt.Errorf("invalid note id %q", args[0])
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.DeleteNote(tx, id); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+		},
 	}
-	db, err := sqlx.Connect(`sqlite`, dbPath)
-	if err != nil {
-		return err
+}
+
+func checkinCmd() *Command {
+	return &Command{
+		Name:  `checkin`,
+		Short: `Rates hunger, energy, sleep, and gym performance for the week.`,
+		Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+			return bite.LogCheckIn(db, c)
+		}),
 	}
-	defer db.Close()
+}
 
-	switch strings.ToLower(args[2]) {
-	case `meal`:
-		if err := bite.CreateAddMeal(db); err != nil {
-			return err
-		}
-	case `food`:
-		if err := bite.CreateAddFood(db); err != nil {
-			return err
-		}
-	case `help`:
-		fmt.Printf(createUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, createUsage)
+func prepCmd() *Command {
+	return &Command{
+		Name:  `prep`,
+		Short: `Plans meals cooked ahead for the week.`,
+		Commands: []*Command{
+			{
+				Name:  `plan`,
+				Short: `Plan a week of lunches and dinners to cook ahead.`,
+				Long:  prepLong,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.PrintPrepPlan(db, c)
+				}),
+			},
+		},
 	}
-	return nil
 }
 
-func DeleteCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, deleteUsage)
+func importCmd() *Command {
+	var date string
+	return &Command{
+		Name:  `import`,
+		Short: `Imports data from other sources.`,
+		Commands: []*Command{
+			{
+				Name:  `receipt`,
+				Short: `Update food prices from a store receipt.`,
+				Args:  `<file>`,
+				Long:  receiptLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date of the receipt (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("receipt takes a receipt file")
+					}
+					d := bite.LogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+					f, err := os.Open(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't open receipt file: %v", err)
+					}
+					defer f.Close()
+					return bite.ImportReceipt(db, f, d)
+				}),
+			},
+		},
 	}
-	dbPath := os.Getenv(`BITE_DB_PATH`)
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
+}
+
+func shareCmd() *Command {
+	readonly := true
+	return &Command{
+		Name:  `share`,
+		Short: `Shares the diet with a coach.`,
+		Long:  shareLong,
+		Commands: []*Command{
+			{
+				Name:  `export`,
+				Short: `Write a read-only snapshot of the database.`,
+				Args:  `<file>`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&readonly, `readonly`, true, `make the snapshot read-only, the only kind of share`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("export takes a file name")
+					}
+					if !readonly {
+						return errors.New(`shares are always read-only, use "bite sync export" to share the log with your own devices`)
+					}
+					if err := bite.ExportShare(db, args[0]); err != nil {
+						return err
+					}
+					fmt.Printf("Wrote a read-only share to %s.\n", args[0])
+					return nil
+				}),
+			},
+			{
+				Name:  `view`,
+				Short: `Run a command on a share without changing it.`,
+				Args:  `<file> [command]...`,
+				Run: func(args []string) error {
+					if len(args) < 1 {
+						return errors.New("view takes the file name of a share")
+					}
+					dir, err := os.MkdirTemp("", "bite-share")
+					if err != nil {
+						return fmt.Errorf("couldn't create share directory: %v", err)
+					}
+					defer os.RemoveAll(dir)
+					view := filepath.Join(dir, "share.db")
+					created, err := bite.CopyShare(args[0], view)
+					if err != nil {
+						return err
+					}
+
+					defer bite.SetClock(bite.FixedClock(created.Local()))()
+					defer func(path, share string) { dbPath, shareView = path, share }(dbPath, shareView)
+					dbPath, shareView = view, args[0]
+					cmd := args[1:]
+					if len(cmd) == 0 {
+						cmd = []string{`summary`, `phase`}
+					}
+					fmt.Printf("Viewing the share of %s, read-only.\n\n", bite.FormatDate(bite.Now()))
+					return Root().Execute(cmd)
+				},
+			},
+		},
 	}
-	db, err := sqlx.Connect(`sqlite`, dbPath)
-	if err != nil {
-		return err
+}
+
+func exportCmd() *Command {
+	var week string
+	return &Command{
+		Name:  `export`,
+		Short: `Exports the diet phase to other apps.`,
+		Commands: []*Command{
+			{
+				Name:  `ical`,
+				Short: `Write the phase milestones as an iCalendar file.`,
+				Long:  icalLong,
+				Args:  `[<file>]`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, args []string) error {
+					if len(args) > 1 {
+						return errors.New("ical takes at most one file name")
+					}
+					events, err := bite.PhaseCalendar(db, c)
+					if err != nil {
+						return err
+					}
+					if len(args) == 0 {
+						return bite.WriteICal(os.Stdout, events, bite.LogNow())
+					}
+					f, err := os.Create(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't create calendar file: %v", err)
+					}
+					if err := bite.WriteICal(f, events, bite.LogNow()); err != nil {
+						f.Close()
+						return fmt.Errorf("couldn't write calendar file: %v", err)
+					}
+					return f.Close()
+				}),
+			},
+			{
+				Name:  `journal`,
+				Short: `Write a week of the diet as Markdown.`,
+				Long:  journalLong,
+				Args:  `[<file>]`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&week, `week`, ``, `ISO week to write, e.g. 2024-W12`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, args []string) error {
+					if len(args) > 1 {
+						return errors.New("journal takes at most one file name")
+					}
+					day := bite.LogNow()
+					if week != "" {
+						var err error
+						if day, err = bite.ParseISOWeek(week); err != nil {
+							return err
+						}
+					}
+					j, err := bite.WeekJournal(db, c, day)
+					if err != nil {
+						return err
+					}
+					if len(args) == 0 {
+						return bite.WriteJournal(os.Stdout, j)
+					}
+					f, err := os.Create(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't create journal file: %v", err)
+					}
+					if err := bite.WriteJournal(f, j); err != nil {
+						f.Close()
+						return fmt.Errorf("couldn't write journal file: %v", err)
+					}
+					return f.Close()
+				}),
+			},
+			exportPhotosCmd(),
+		},
 	}
-	defer db.Close()
+}
 
-	switch strings.ToLower(args[2]) {
-	case `meal`:
-		if err := bite.SelectDeleteMeal(db); err != nil {
-			return err
-		}
-	case `food`:
-		if err := bite.SelectDeleteFood(db); err != nil {
-			return err
-		}
-	case `help`:
-		fmt.Printf(deleteUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, deleteUsage)
+func historyCmd() *Command {
+	var n int
+	return &Command{
+		Name:  `history`,
+		Short: `Lists the recent commands that changed the database.`,
+		Long:  historyLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&n, `n`, 20, `number of commands to show`)
+		},
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			if n < 1 {
+				return fmt.Errorf("invalid -n %d: must be at least 1", n)
+			}
+			runs, err := bite.History(db, n)
+			if err != nil {
+				return err
+			}
+			return bite.WriteHistory(os.Stdout, runs)
+		}),
 	}
-	return nil
 }
 
-func UpdateCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, updateUsage)
+func redoCmd() *Command {
+	var date, clock string
+	return &Command{
+		Name:  `redo`,
+		Short: `Repeats the last food logged.`,
+		Long:  redoLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&date, `date`, ``, `date to log the foods on (YYYY-MM-DD), defaults to today`)
+			fs.StringVar(&clock, `time`, ``, `time of day to log the foods at (HH:MM), defaults to now`)
+		},
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			at := bite.FoodLogNow()
+			if date != "" {
+				d, err := bite.ValidateDateStr(date)
+				if err != nil {
+					return fmt.Errorf("invalid --date %q: %v", date, err)
+				}
+				at = time.Date(d.Year(), d.Month(), d.Day(), at.Hour(), at.Minute(), at.Second(), 0, time.UTC)
+			}
+			if clock != "" {
+				t, err := time.Parse("15:04", clock)
+				if err != nil {
+					return fmt.Errorf("invalid --time %q: must be HH:MM", clock)
+				}
+				at = time.Date(at.Year(), at.Month(), at.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+			}
+			if err := bite.RedoFoodLog(db, at); err != nil {
+				return err
+			}
+			return daySummary(db)
+		}),
 	}
-	dbPath := os.Getenv(`BITE_DB_PATH`)
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
+}
+
+func auditCmd() *Command {
+	var since, table string
+	return &Command{
+		Name:  `audit`,
+		Short: `Reviews the changes made to the database.`,
+		Long:  auditLong,
+		Commands: []*Command{
+			{
+				Name:  `show`,
+				Short: `Show the changes made recently.`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&since, `since`, `7d`, `how far back to look, e.g. 12h, 7d, 2w, or a date`)
+					fs.StringVar(&table, `table`, ``, `only show changes to this table`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					from, err := bite.ParseSince(since, bite.Now())
+					if err != nil {
+						return err
+					}
+					entries, err := bite.AuditLog(db, from)
+					if err != nil {
+						return err
+					}
+					if table != "" {
+						var matched []bite.AuditEntry
+						for _, e := range entries {
+							if e.Table == table {
+								matched = append(matched, e)
+							}
+						}
+						entries = matched
+					}
+					return bite.WriteAuditLog(os.Stdout, entries)
+				}),
+			},
+		},
 	}
-	db, err := sqlx.Connect(`sqlite`, dbPath)
-	if err != nil {
-		return err
+}
+
+func leftoversCmd() *Command {
+	return &Command{
+		Name:  `leftovers`,
+		Short: `Lists leftovers and logs a serving of one.`,
+		Long:  leftoversLong,
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			return bite.ShowLeftovers(db)
+		}),
 	}
-	defer db.Close()
-	c, err := bite.Config(db)
-	if err != nil {
-		return fmt.Errorf("ERROR: reading config: %v", err)
+}
+
+func pantryCmd() *Command {
+	var set bool
+	return &Command{
+		Name:  `pantry`,
+		Short: `Tracks the foods on hand and the staples running low.`,
+		Long:  pantryLong,
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			return bite.PrintPantry(db, false)
+		}),
+		Commands: []*Command{
+			{
+				Name:  `add`,
+				Short: `Add a quantity of a food to the pantry.`,
+				Args:  `<quantity>`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&set, `set`, false, `set the quantity on hand instead of adding to it`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("add takes the quantity of the food")
+					}
+					q, err := strconv.ParseFloat(args[0], 64)
+					if err != nil || q < 0 {
+						return fmt.Errorf("invalid quantity %q", args[0])
+					}
+					return bite.StockFood(db, q, set)
+				}),
+			},
+			{
+				Name:  `staple`,
+				Short: `Keep a food stocked at a par quantity.`,
+				Args:  `<quantity>`,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("staple takes the quantity to keep stocked")
+					}
+					par, err := strconv.ParseFloat(args[0], 64)
+					if err != nil || par < 0 {
+						return fmt.Errorf("invalid quantity %q", args[0])
+					}
+					return bite.FlagStaple(db, par)
+				}),
+			},
+			{
+				Name:  `low`,
+				Short: `List the staples running low.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.PrintPantry(db, true)
+				}),
+			},
+			{
+				Name:  `grocery`,
+				Short: `Print a grocery list of the staples running low.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					return bite.PrintGroceryList(db)
+				}),
+			},
+		},
+	}
+}
+
+func suppCmd() *Command {
+	var dose, at string
+	return &Command{
+		Name:  `supp`,
+		Short: `Manages the supplements taken every day.`,
+		Long:  suppLong,
+		Commands: []*Command{
+			{
+				Name:  `add`,
+				Short: `Take a supplement every day.`,
+				Args:  `<name>`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&dose, `dose`, ``, `dose of the supplement, e.g. "5 g"`)
+					fs.StringVar(&at, `at`, ``, `time of day (HH:MM) the supplement is due`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("add takes a supplement name")
+					}
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.AddSupplement(tx, bite.Supplement{Name: args[0], Dose: dose, Time: at}); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `remove`,
+				Short: `Stop taking a supplement.`,
+				Args:  `<name>`,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("remove takes a supplement name")
+					}
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.RemoveSupplement(tx, args[0]); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `list`,
+				Short: `List the supplements taken every day.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					supps, err := bite.Supplements(tx)
+					if err != nil {
+						return err
+					}
+					if len(supps) == 0 {
+						fmt.Println("No supplements scheduled.")
+					}
+					for _, s := range supps {
+						at := s.Time
+						if at == "" {
+							at = "any time"
+						}
+						fmt.Printf("%-30s %s\n", s, at)
+					}
+					return nil
+				}),
+			},
+		},
 	}
+}
 
-	switch strings.ToLower(args[2]) {
-	case `user`:
-		if err := bite.UpdateUserInfo(db, c); err != nil {
+func conditionCmd() *Command {
+	// set flags or unflags the named conditions.
+	set := func(db *sqlx.DB, u *bite.UserInfo, names []string, flag bool) error {
+		flagged := make(map[string]bool)
+		for _, c := range u.UserConditions() {
+			flagged[c.Name] = true
+		}
+		for _, name := range names {
+			c, err := bite.ParseCondition(name)
+			if err != nil {
+				return err
+			}
+			flagged[c.Name] = flag
+		}
+		var keep []string
+		for name, ok := range flagged {
+			if ok {
+				keep = append(keep, name)
+			}
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if err := bite.SetConditions(tx, u, keep); err != nil {
 			return err
 		}
-	case `food`:
-		if err := bite.UpdateFood(db); err != nil {
+		if err := tx.Commit(); err != nil {
 			return err
 		}
-	case `meal`:
-		if len(os.Args) < 4 {
-			printUsageExit(`ERROR: Not enough arguments`, updateUsage)
+		if len(keep) == 0 {
+			fmt.Println("No conditions flagged.")
 		}
-		switch strings.ToLower(args[3]) {
-		case `add`: // Adds a food to an existing meal.
-			if err := bite.PromptAddMealFood(db); err != nil {
+		bite.PrintConditionDisclaimers(u)
+		return nil
+	}
+
+	return &Command{
+		Name:  `condition`,
+		Short: `Flags conditions and medications that change safe targets.`,
+		Long:  conditionLong,
+		Commands: []*Command{
+			{
+				Name:  `add`,
+				Short: `Flag conditions.`,
+				Args:  `<name>...`,
+				Run: withConfig(func(db *sqlx.DB, u *bite.UserInfo, args []string) error {
+					if len(args) == 0 {
+						return errors.New("add takes the names of the conditions")
+					}
+					return set(db, u, args, true)
+				}),
+			},
+			{
+				Name:  `remove`,
+				Short: `Unflag conditions.`,
+				Args:  `<name>...`,
+				Run: withConfig(func(db *sqlx.DB, u *bite.UserInfo, args []string) error {
+					if len(args) == 0 {
+						return errors.New("remove takes the names of the conditions")
+					}
+					return set(db, u, args, false)
+				}),
+			},
+			{
+				Name:  `list`,
+				Short: `List the conditions that can be flagged.`,
+				Run: withConfig(func(db *sqlx.DB, u *bite.UserInfo, _ []string) error {
+					flagged := make(map[string]bool)
+					for _, c := range u.UserConditions() {
+						flagged[c.Name] = true
+					}
+					for _, c := range bite.Conditions() {
+						mark := " "
+						if flagged[c.Name] {
+							mark = "x"
+						}
+						fmt.Printf("[%s] %-14s %s\n", mark, c.Name, c.Description)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+func notifyCmd() *Command {
+	return &Command{
+		Name:  `notify`,
+		Short: `Prints reminders of what is due now.`,
+		Long:  notifyLong,
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			tx, err := db.Beginx()
+			if err != nil {
 				return err
 			}
-		case `delete`: // Deletes a food from an existing meal.
-			if err := bite.SelectDeleteFoodMealFood(db); err != nil {
+			defer tx.Rollback()
+			now := bite.LogNow()
+			due, err := bite.DueSupplements(tx, now)
+			if err != nil {
 				return err
 			}
-		default:
-			printUsageExit(`ERROR: Incorrect argument`, updateUsage)
-		}
-	case `help`:
-		fmt.Printf(updateUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, updateUsage)
+			for _, s := range due {
+				fmt.Printf("Take %s.\n", s)
+			}
+			reminders, err := bite.WeighInReminders(tx, now)
+			if err != nil {
+				return err
+			}
+			for _, r := range reminders {
+				fmt.Println(r)
+			}
+			drift, err := bite.DriftReminders(tx)
+			if err != nil {
+				return err
+			}
+			for _, d := range drift {
+				fmt.Println(d)
+			}
+			return nil
+		}),
 	}
-	return nil
 }
 
-func SummaryCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, summaryUsage)
+func breakCmd() *Command {
+	var weeks int
+	return &Command{
+		Name:  `break`,
+		Short: `Starts or stops a diet break.`,
+		Long:  breakLong,
+		Commands: []*Command{
+			{
+				Name:  `start`,
+				Short: `Start a diet break today.`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&weeks, `weeks`, 1, `length of the break in weeks`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.StartDietBreak(db, c, weeks)
+				}),
+			},
+			{
+				Name:  `stop`,
+				Short: `End the current diet break early.`,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.StopDietBreak(db, c)
+				}),
+			},
+		},
 	}
-	dbPath := os.Getenv(`BITE_DB_PATH`)
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
+}
+
+func phaseCmd() *Command {
+	var yes bool
+	var date string
+	return &Command{
+		Name:  `phase`,
+		Short: `Manages the diet phase.`,
+		Commands: []*Command{
+			{
+				Name:  `recheck`,
+				Short: `Re-evaluate every week of the diet phase.`,
+				Long:  recheckLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&yes, `yes`, false, `update without asking for confirmation`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					activeLog, err := activePhaseLog(db, c)
+					if err != nil {
+						return err
+					}
+					return bite.RecheckPhase(db, bite.Input, c, activeLog, yes)
+				}),
+			},
+			{
+				Name:  `start`,
+				Short: `Start a template of back-to-back diet phases.`,
+				Args:  `<template>`,
+				Long:  phaseStartLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date the first phase starts (YYYY-MM-DD), defaults to today`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, args []string) error {
+					if len(args) != 1 {
+						return errors.New("start takes the name of a phase template, such as recomp")
+					}
+					d := bite.LogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+					return bite.StartTemplate(db, c, args[0], d)
+				}),
+			},
+			{
+				Name:  `templates`,
+				Short: `List the phase templates.`,
+				Run: func(_ []string) error {
+					bite.PrintPhaseTemplates()
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func recipeCmd() *Command {
+	var name string
+	var cooked float64
+	var servings int
+	return &Command{
+		Name:  `recipe`,
+		Short: `Works out the nutrition of recipes cooked in batches.`,
+		Commands: []*Command{
+			{
+				Name:  `portion`,
+				Short: `Create a food from a cooked batch.`,
+				Args:  `<food-id>=<grams>...`,
+				Long:  portionLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&name, `name`, ``, `name of the new food`)
+					fs.Float64Var(&cooked, `cooked`, 0, `weight of the whole batch once cooked (g)`)
+					fs.IntVar(&servings, `servings`, 0, `number of servings the batch is split into`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					b := bite.Batch{Name: name, Cooked: cooked, Servings: servings}
+					for _, a := range args {
+						in, err := bite.ParseIngredient(a)
+						if err != nil {
+							return err
+						}
+						b.Ingredients = append(b.Ingredients, in)
+					}
+					_, err := bite.PortionBatch(db, b)
+					return err
+				}),
+			},
+		},
+	}
+}
+
+func keysCmd() *Command {
+	return &Command{
+		Name:  `keys`,
+		Short: `Lists or changes search UI keybindings.`,
+		Long:  keysLong,
+		Run: withDB(func(db *sqlx.DB, _ []string) error {
+			km, err := loadKeymap(db)
+			if err != nil {
+				return err
+			}
+			fmt.Println(km)
+			return nil
+		}),
+		Commands: []*Command{
+			{
+				Name:  `set`,
+				Short: `Bind action to a key sequence.`,
+				Args:  `<action> <keys>`,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 2 {
+						return errors.New("set takes an action and a key sequence")
+					}
+					a := action(args[0])
+					if _, ok := defaultKeys[a]; !ok {
+						return fmt.Errorf("unknown action %q", a)
+					}
+					custom, err := bite.SettingsWithPrefix(db, keySettingPrefix)
+					if err != nil {
+						return err
+					}
+					custom[keySettingPrefix+string(a)] = args[1]
+					keys, err := customKeys(custom)
+					if err != nil {
+						return err
+					}
+					if _, err := newKeymap(keys); err != nil {
+						return err
+					}
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.SetSetting(tx, keySettingPrefix+string(a), args[1]); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `reset`,
+				Short: `Restore default keybindings.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					for a := range defaultKeys {
+						if err := bite.DeleteSetting(tx, keySettingPrefix+string(a)); err != nil {
+							return err
+						}
+					}
+					return tx.Commit()
+				}),
+			},
+		},
+	}
+}
+
+func syncCmd() *Command {
+	var (
+		all   bool
+		since string
+	)
+	return &Command{
+		Name:  `sync`,
+		Short: `Syncs the log between devices through a file.`,
+		Long:  syncLong,
+		Commands: []*Command{
+			{
+				Name:  `export`,
+				Short: `Write log changes to a file.`,
+				Args:  `<file>`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&all, `all`, false, `export every entry`)
+					fs.StringVar(&since, `since`, ``, `export changes after this date (YYYY-MM-DD)`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("export takes a file name")
+					}
+					if err := bite.PrepareSync(db); err != nil {
+						return err
+					}
+
+					var from time.Time
+					switch {
+					case all:
+					case since != "":
+						t, err := time.ParseInLocation("2006-01-02", since, time.Local)
+						if err != nil {
+							return fmt.Errorf("invalid --since date %q: %v", since, err)
+						}
+						from = t
+					default:
+						last, ok, err := bite.Setting(db, syncLastExport)
+						if err != nil {
+							return err
+						}
+						if ok {
+							t, err := time.Parse(bite.SyncTimeFormat, last)
+							if err != nil {
+								return fmt.Errorf("invalid %s setting %q: %v", syncLastExport, last, err)
+							}
+							from = t
+						}
+					}
+
+					cs, err := bite.ExportChanges(db, from)
+					if err != nil {
+						return err
+					}
+					f, err := os.Create(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't create changeset file: %v", err)
+					}
+					if err := bite.WriteChangeset(f, cs); err != nil {
+						f.Close()
+						return err
+					}
+					if err := f.Close(); err != nil {
+						return fmt.Errorf("couldn't write changeset file: %v", err)
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.SetSetting(tx, syncLastExport, cs.Created); err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+
+					fmt.Printf("Exported %d food entries, %d weight entries, %d attachments, and %d deletions.\n",
+						len(cs.Foods), len(cs.Weights), len(cs.Attachments), len(cs.Deleted))
+					return nil
+				}),
+			},
+			{
+				Name:  `import`,
+				Short: `Apply log changes from a file.`,
+				Args:  `<file>`,
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) != 1 {
+						return errors.New("import takes a file name")
+					}
+					if err := bite.PrepareSync(db); err != nil {
+						return err
+					}
+
+					f, err := os.Open(args[0])
+					if err != nil {
+						return fmt.Errorf("couldn't open changeset file: %v", err)
+					}
+					defer f.Close()
+					cs, err := bite.ReadChangeset(f)
+					if err != nil {
+						return err
+					}
+
+					r, err := bite.ImportChanges(db, cs)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Imported changes: %s.\n", r)
+					return nil
+				}),
+			},
+			{
+				Name:  `dedup`,
+				Short: `Remove duplicate food and weight entries.`,
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					if err := bite.PrepareSync(db); err != nil {
+						return err
+					}
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					n, err := bite.DeleteDuplicateEntries(tx)
+					if err != nil {
+						return err
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					fmt.Printf("Removed %d duplicate entries.\n", n)
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+// withDB wraps a command action that needs a database connection.
+func withDB(f func(db *sqlx.DB, args []string) error) func([]string) error {
+	return func(args []string) error {
+		return runWithDB(false, f, args)
 	}
-	db, err := sqlx.Connect(`sqlite`, dbPath)
+}
+
+// withMigration wraps a command action that changes the schema of the
+// database. It waits for other commands to finish with the database
+// and keeps new ones out until it is done.
+func withMigration(f func(db *sqlx.DB, args []string) error) func([]string) error {
+	return func(args []string) error {
+		return runWithDB(true, f, args)
+	}
+}
+
+// runWithDB opens the database, calls f, and closes the database. With
+// --dry-run, f is given a copy of the database and the changes it
+// made are printed instead of saved.
+func runWithDB(exclusive bool, f func(db *sqlx.DB, args []string) error, args []string) error {
+	db, closeDB, err := openDB(exclusive, !dryRun && shareView == "")
 	if err != nil {
 		return err
 	}
-	defer db.Close()
-	c, err := bite.Config(db)
-	if err != nil {
-		return fmt.Errorf("ERROR: reading config: %v", err)
+	switch {
+	case dryRun:
+		err = bite.DryRun(db, os.Stdout, func(db *sqlx.DB) error {
+			if err := bite.AddTables(db); err != nil {
+				return err
+			}
+			return f(db, args)
+		})
+	case shareView != "":
+		err = runShared(db, f, args)
+	default:
+		err = runAudited(db, f, args)
+	}
+	if cerr := closeDB(err == nil && !dryRun); cerr != nil && err == nil {
+		err = fmt.Errorf("couldn't close database: %v", cerr)
+	}
+	if err != nil || dryRun || shareView != "" {
+		bite.DiscardEvents()
+		return err
 	}
+	if err := bite.RunHooks(os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+	return nil
+}
 
-	// Execute subcommand
-	switch strings.ToLower(args[2]) {
-	case `phase`:
-		status, err := bite.CheckPhaseStatus(db, c)
-		if err != nil {
-			return err
-		}
+// runAudited calls f and attributes the changes it made in the audit
+// log to the command line and the user running it.
+func runAudited(db *sqlx.DB, f func(db *sqlx.DB, args []string) error, args []string) error {
+	if err := bite.AddTables(db); err != nil {
+		return err
+	}
+	if err := bite.EnableAudit(db); err != nil {
+		return err
+	}
+	command := strings.Join(append([]string{`bite`}, os.Args[1:]...), " ")
+	if err := bite.AttributeChanges(db, command, currentUser()); err != nil {
+		return err
+	}
+	return f(db, args)
+}
+
+// currentUser returns the name of the user running bite.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv(`USER`)
+}
 
-		// Read user entries.
-		entries, err := bite.AllEntries(db)
+// withConfig wraps a command action that needs a database connection
+// and the user's config.
+func withConfig(f func(db *sqlx.DB, c *bite.UserInfo, args []string) error) func([]string) error {
+	return withDB(func(db *sqlx.DB, args []string) error {
+		c, err := bite.Config(db)
 		if err != nil {
-			return err
+			return fmt.Errorf("couldn't read config: %v", err)
 		}
+		return f(db, c, args)
+	})
+}
 
-		var activeLog *[]bite.Entry
-		// If there is an active diet,
-		if status == "active" {
-			// Subset the log for the active diet phase.
-			activeLog = bite.ValidLog(c, entries)
+// runShared runs f on the copy of the share being viewed and fails if
+// f changed any of its rows.
+func runShared(db *sqlx.DB, f func(db *sqlx.DB, args []string) error, args []string) error {
+	if err := bite.AddTables(db); err != nil {
+		return err
+	}
+	if err := f(db, args); err != nil {
+		return err
+	}
+	n, err := bite.ShareChanges(db, shareView)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return errors.New("the share is read-only, so nothing was saved")
+	}
+	return nil
+}
 
-			// Get user progress.
-			if err := bite.CheckProgress(db, c, activeLog); err != nil {
-				return err
-			}
-		}
+// openDB connects to the SQLite database. An encrypted database is
+// decrypted into memory, and the returned close function writes it back
+// to disk when save is set. A command that failed doesn't set it, so
+// the changes it made before failing are dropped rather than saved.
+//
+// The database's lock file is held until the returned close function
+// is called: shared for a plaintext database, which SQLite lets several
+// commands use at once, and exclusive when asked for or when the
+// database is encrypted, since the whole file is then replaced. When
+// audit is set and tables must be added or the audit triggers remade,
+// which changes the schema, the lock is taken exclusively instead.
+func openDB(exclusive, audit bool) (*sqlx.DB, func(save bool) error, error) {
+	if dbPath == "" {
+		return nil, nil, errors.New("environment variable BITE_DB_PATH or --db must be set")
+	}
 
-		// Only call Summary with the active logs if a diet phase is active.
-		if status != `active` {
-			return errors.New("diet is not active. Skipping summary.")
-		}
-		bite.Summary(c, activeLog)
-	case `diet`:
-		if n < 4 {
-			printUsageExit(`ERROR: Not enough arguments`, summaryUsage)
+	enc, err := bite.IsEncrypted(dbPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("couldn't read database: %v", err)
+	}
+	unlock, err := bite.Lock(dbPath, exclusive || enc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't lock database: %v", err)
+	}
+	if !enc {
+		db, err := bite.Open(dbPath)
+		if err != nil {
+			unlock()
+			return nil, nil, err
 		}
-		switch strings.ToLower(args[3]) {
-		case `all`:
-			if err := bite.FoodLogSummary(db); err != nil {
-				return err
+		if audit && !exclusive {
+			current, err := bite.TablesCurrent(db)
+			if err == nil && current {
+				current, err = bite.AuditCurrent(db)
 			}
-		case `day`:
-			if err := bite.FoodLogSummaryDay(db, c); err != nil {
-				return err
+			if err != nil {
+				db.Close()
+				unlock()
+				return nil, nil, err
+			}
+			if !current {
+				db.Close()
+				unlock()
+				return openDB(true, audit)
 			}
-		default:
-			printUsageExit(`ERROR: Incorrect argument`, summaryUsage)
 		}
-	case `user`:
-		bite.PrintUserInfo(c)
-	case `help`:
-		fmt.Printf(summaryUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, summaryUsage)
+		closeDB := func(bool) error {
+			defer unlock()
+			return db.Close()
+		}
+		return db, closeDB, nil
+	}
+
+	var (
+		db  *sqlx.DB
+		key *bite.Key
+	)
+	err = withKey(func(k *bite.Key) error {
+		var err error
+		db, err = bite.OpenEncrypted(dbPath, k)
+		key = k
+		return err
+	})
+	if err != nil {
+		unlock()
+		return nil, nil, fmt.Errorf("couldn't open encrypted database: %v", err)
+	}
+	closeDB := func(save bool) error {
+		defer unlock()
+		defer db.Close()
+		if !save {
+			return nil
+		}
+		return bite.SaveEncrypted(db, dbPath, key)
+	}
+	return db, closeDB, nil
+}
+
+// daySummary prints the summary of today's food log.
+func daySummary(db *sqlx.DB) error {
+	c, err := bite.Config(db)
+	if err != nil {
+		return fmt.Errorf("couldn't read config: %v", err)
+	}
+	if err := bite.FoodLogSummaryDay(db, c); err != nil {
+		return fmt.Errorf("couldn't get daily summary: %v", err)
 	}
 	return nil
 }
 
-func StopCmd(args []string) error {
-	n := len(args)
-	if n < 3 {
-		printUsageExit(`ERROR: Not enough arguments`, stopUsage)
+// editText opens the text in the user's $VISUAL or $EDITOR, or vi, and
+// returns it once the editor exits.
+func editText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
 	}
-	dbPath := os.Getenv(`BITE_DB_PATH`)
-	if dbPath == "" {
-		log.Fatal("Environment variable BITE_DB_PATH must be set")
+	if editor == "" {
+		editor = "vi"
 	}
-	db, err := sqlx.Connect(`sqlite`, dbPath)
+
+	f, err := os.CreateTemp("", "bite-*.txt")
 	if err != nil {
-		return err
+		return "", fmt.Errorf("couldn't create file to edit: %v", err)
 	}
-	defer db.Close()
-	c, err := bite.Config(db)
-	if err != nil {
-		return fmt.Errorf("ERROR: Couldn't read config: %v", err)
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", fmt.Errorf("couldn't write file to edit: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("couldn't write file to edit: %v", err)
 	}
 
-	switch strings.ToLower(os.Args[2]) {
-	case "phase":
-		if err := bite.StopPhase(db, c); err != nil {
-			return err
-		}
-	case `help`:
-		fmt.Printf(stopUsage)
-	default:
-		printUsageExit(`ERROR: Incorrect argument`, stopUsage)
+	// The editor may be given with arguments, such as "code --wait".
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("couldn't run editor %s: %v", editor, err)
 	}
-	return nil
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("couldn't read edited file: %v", err)
+	}
+	return string(b), nil
 }
 
-// printUsageExit prints error message and usage statement, then exits
-// the program with error code 1.
-func printUsageExit(m, s string) {
-	fmt.Fprintln(os.Stderr, m)
-	fmt.Fprintf(os.Stderr, s)
-	os.Exit(1)
+// activePhaseLog returns the user's entries for the active diet phase.
+func activePhaseLog(db *sqlx.DB, c *bite.UserInfo) (*[]bite.Entry, error) {
+	status, err := bite.CheckPhaseStatus(db, c)
+	if err != nil {
+		return nil, err
+	}
+	if status != `active` {
+		return nil, errors.New("diet is not active. Skipping summary.")
+	}
+
+	// Read user entries.
+	entries, err := bite.AllEntries(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := bite.MarkTrainingDays(db, entries); err != nil {
+		return nil, err
+	}
+	if err := bite.MarkEstimatedWeights(db, entries); err != nil {
+		return nil, err
+	}
+	if err := bite.MarkGoals(db, c, entries); err != nil {
+		return nil, err
+	}
+	if err := bite.MarkSodium(db, entries); err != nil {
+		return nil, err
+	}
+	if err := bite.MarkFats(db, entries); err != nil {
+		return nil, err
+	}
+	if err := bite.MarkNotes(db, entries); err != nil {
+		return nil, err
+	}
+	exclude, err := bite.ExcludeEstimates(db)
+	if err != nil {
+		return nil, err
+	}
+	if exclude {
+		entries = bite.WithoutEstimates(entries)
+	}
+
+	// Subset the log for the active diet phase.
+	return bite.ValidLog(c, entries), nil
 }
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is a command-line command. A command either runs an action
+// or dispatches to one of its subcommands. Every command has its own
+// flag set and answers to -h, --help, and a trailing help argument.
+type Command struct {
+	// Name is the word used to invoke the command.
+	Name string
+
+	// Short is a one line description shown in the parent's usage.
+	Short string
+
+	// Args describes the positional arguments the command takes, e.g.
+	// "<action> <keys>". Commands without Args reject extra arguments.
+	Args string
+
+	// Long is an optional description shown in the command's usage.
+	Long string
+
+	// Flags defines the command's flags. It may be nil.
+	Flags func(fs *flag.FlagSet)
+
+	// Run runs the command with the remaining positional arguments. It
+	// may be nil for commands that only dispatch to subcommands.
+	Run func(args []string) error
+
+	// Commands are the subcommands.
+	Commands []*Command
+
+	parent *Command
+	fs     *flag.FlagSet
+}
+
+// UsageError reports incorrect usage of a command.
+type UsageError struct {
+	Cmd *Command
+	Msg string
+}
+
+func (e *UsageError) Error() string {
+	return e.Msg
+}
+
+// Execute parses the command's flags and runs the command, or the
+// subcommand named by the first positional argument.
+func (c *Command) Execute(args []string) error {
+	fs := c.flagSet()
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			c.PrintUsage(os.Stdout)
+			return nil
+		}
+		return c.usageErr(err.Error())
+	}
+	args = fs.Args()
+
+	if len(args) > 0 && strings.ToLower(args[0]) == `help` {
+		c.PrintUsage(os.Stdout)
+		return nil
+	}
+
+	if len(c.Commands) > 0 && len(args) > 0 {
+		name := strings.ToLower(args[0])
+		for _, sub := range c.Commands {
+			if sub.Name == name {
+				sub.parent = c
+				return sub.Execute(args[1:])
+			}
+		}
+		if c.Run == nil {
+			return c.usageErr(fmt.Sprintf("Incorrect argument %q", args[0]))
+		}
+	}
+
+	if c.Run == nil {
+		return c.usageErr(`Not enough arguments`)
+	}
+	if c.Args == "" && len(args) > 0 {
+		return c.usageErr(fmt.Sprintf("Unexpected argument %q", args[0]))
+	}
+	return c.Run(args)
+}
+
+// flagSet returns the command's flag set, creating it on first use.
+func (c *Command) flagSet() *flag.FlagSet {
+	if c.fs != nil {
+		return c.fs
+	}
+	c.fs = flag.NewFlagSet(c.path(), flag.ContinueOnError)
+	// Errors and usage are printed by the caller.
+	c.fs.SetOutput(io.Discard)
+	if c.Flags != nil {
+		c.Flags(c.fs)
+	}
+	return c.fs
+}
+
+// path returns the full invocation of the command, e.g. "bite log food".
+func (c *Command) path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+// usageErr returns a usage error for the command.
+func (c *Command) usageErr(msg string) error {
+	return &UsageError{Cmd: c, Msg: msg}
+}
+
+// PrintUsage writes the usage statement of the command to w.
+func (c *Command) PrintUsage(w io.Writer) {
+	fs := c.flagSet()
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+
+	line := c.path()
+	if len(c.Commands) > 0 {
+		line += " <command>"
+	}
+	if c.Args != "" {
+		line += " " + c.Args
+	}
+	if hasFlags {
+		line += " [flags]"
+	}
+	fmt.Fprintf(w, "USAGE\n\n  %s\n", line)
+
+	if len(c.Commands) > 0 {
+		width := 0
+		for _, sub := range c.Commands {
+			if len(sub.Name) > width {
+				width = len(sub.Name)
+			}
+		}
+		fmt.Fprintf(w, "\nCOMMANDS\n\n")
+		for _, sub := range c.Commands {
+			fmt.Fprintf(w, "  %-*s - %s\n", width, sub.Name, sub.Short)
+		}
+	}
+
+	if hasFlags {
+		fmt.Fprintf(w, "\nFLAGS\n\n")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+
+	if c.Long != "" {
+		fmt.Fprintf(w, "\nDESCRIPTION\n\n%s\n", c.Long)
+	}
+}
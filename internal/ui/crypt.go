@@ -0,0 +1,419 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/term"
+)
+
+const (
+	dbLong = `  An encrypted database is decrypted into memory when a command runs
+  and written back, re-encrypted, when it finishes. The database is never
+  stored on disk in plaintext. Commands using an encrypted database
+  take turns, each waiting up to a few seconds for the one before it.
+
+  After the passphrase is entered, the derived key is cached in
+  $XDG_RUNTIME_DIR until logout so it isn't asked for again. Use
+  "bite db lock" to forget it sooner. Without $XDG_RUNTIME_DIR the key
+  isn't cached.`
+
+	recalcLong = `  Entries store the calories, macros, and price of the food at the time
+  it was logged. After correcting a food's nutrients or cost, recalc
+  recomputes its entries from the current data, keeping each entry's
+  serving, and shows the changes before updating them.`
+
+	healthLong = `  Check the data for problems and print the command that fixes each:
+  days without a weight or food and weeks with too few days of both to
+  count in progress checks, over the diet phase or the last 4 weeks
+  without one; logged foods missing calories or macros; serving
+  preferences left behind by deleted foods or foods removed from their
+  meal; food entries of deleted foods; implausible weights; and out of
+  date daily totals or missing indexes. --fix removes the orphaned
+  serving preferences.`
+
+	vacuumLong = `  Remove, in one transaction, the rows left behind by deleted foods and
+  meals: serving preferences of deleted foods and of foods no longer in
+  their meal, logged meals without any logged foods, and search index
+  rows of deleted foods. It also adds the triggers that remove these
+  rows whenever a food or meal is deleted, for databases made before
+  they existed.`
+
+	purgeLong = `  Delete log history for good, such as to honor a request to erase
+  personal data. --before deletes the food, meal, weight, training,
+  sleep, step, lift, supplement, check-in, note, photo, and price
+  entries dated before the date, along with the diet phase events and
+  audit log entries from before it. --all-user-data deletes everything
+  but the foods, their nutrients, and their allergen flags; the next
+  command asks for the user's details again.
+
+  Before anything is deleted, purge offers to write a backup and asks
+  for a confirmation phrase to be typed. The database is then vacuumed
+  so the deleted rows don't linger in the file, and the photos of the
+  deleted progress photo entries are deleted from the photos directory.
+  Attached files stored outside the database aren't deleted.`
+
+	outliersLong = `  A logged weight is an outlier when it is further from the median of
+  the 6 weights nearest it than 2% of body weight, plus 0.5% for each
+  day to the nearest of them, such as a 20 lb jump in a day or a
+  finger slip. Each outlier is shown with the weight expected, and a key
+  edits (e), deletes (d), or keeps (k) it, or quits (q). Weights logged
+  with "bite log weight" are checked the same way before they're saved.`
+)
+
+func dbCmd() *Command {
+	var food int
+	var from string
+	var yes, all, fix bool
+
+	return &Command{
+		Name:  `db`,
+		Short: `Encrypts, decrypts, or maintains the database.`,
+		Long:  dbLong,
+		Commands: []*Command{
+			{
+				Name:  `encrypt`,
+				Short: `Encrypt the database with a passphrase.`,
+				Run: func([]string) error {
+					return encryptDB()
+				},
+			},
+			{
+				Name:  `decrypt`,
+				Short: `Decrypt the database and remove its passphrase.`,
+				Run: func([]string) error {
+					return decryptDB()
+				},
+			},
+			{
+				Name:  `lock`,
+				Short: `Forget the cached key of the database.`,
+				Run: func([]string) error {
+					return forgetKey()
+				},
+			},
+			{
+				Name:  `rollup`,
+				Short: `Rebuild the daily totals that summaries read.`,
+				Run: withMigration(func(db *sqlx.DB, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					n, err := bite.RebuildRollups(tx)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("Rolled up %d days.\n", n)
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `index`,
+				Short: `Add the indexes that logs are read through.`,
+				Run: withMigration(func(db *sqlx.DB, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if err := bite.CreateIndexes(tx); err != nil {
+						return err
+					}
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `vacuum`,
+				Short: `Remove rows left behind by deleted foods and meals.`,
+				Long:  vacuumLong,
+				Run: withMigration(func(db *sqlx.DB, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					r, err := bite.Vacuum(tx)
+					if err != nil {
+						return err
+					}
+					fmt.Println(r)
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `health`,
+				Short: `Summarize logging gaps and other data issues.`,
+				Long:  healthLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&fix, `fix`, false, `remove orphaned serving preferences`)
+				},
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					if fix {
+						n, err := bite.RemoveOrphanedPrefs(tx)
+						if err != nil {
+							return err
+						}
+						fmt.Printf("Removed %d orphaned serving preferences.\n", n)
+					}
+					checks, err := bite.Health(tx, c, bite.LogNow())
+					if err != nil {
+						return err
+					}
+					bite.PrintHealth(checks)
+					return tx.Commit()
+				}),
+			},
+			{
+				Name:  `purge`,
+				Short: `Irreversibly delete log history, keeping the food data.`,
+				Long:  purgeLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&from, `before`, ``, `delete the log entries dated before this date (YYYY-MM-DD)`)
+					fs.BoolVar(&all, `all-user-data`, false, `delete all user data but the food reference data`)
+				},
+				Run: withMigration(func(db *sqlx.DB, _ []string) error {
+					if (from == "") == !all {
+						return errors.New("exactly one of --before or --all-user-data must be set")
+					}
+					d := time.Time{}
+					if from != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(from); err != nil {
+							return fmt.Errorf("invalid --before %q: %v", from, err)
+						}
+					}
+					return bite.Purge(db, bite.Input, dbPath, photosDir(), d)
+				}),
+			},
+			{
+				Name:  `outliers`,
+				Short: `Find implausible weights and edit, delete, or keep each one.`,
+				Long:  outliersLong,
+				Run: withConfig(func(db *sqlx.DB, c *bite.UserInfo, _ []string) error {
+					return bite.CorrectOutliers(db, c)
+				}),
+			},
+			{
+				Name:  `recalc`,
+				Short: `Recompute logged entries of a food after its data changed.`,
+				Long:  recalcLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.IntVar(&food, `food`, 0, `id of the food whose entries to recompute`)
+					fs.StringVar(&from, `from`, ``, `first date to recompute (YYYY-MM-DD), defaults to all entries`)
+					fs.BoolVar(&yes, `yes`, false, `update without asking for confirmation`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					if food <= 0 {
+						return errors.New("--food must be set")
+					}
+					d := time.Time{}
+					if from != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(from); err != nil {
+							return fmt.Errorf("invalid --from %q: %v", from, err)
+						}
+					}
+					return bite.RecalcFood(db, bite.Input, food, d, yes)
+				}),
+			},
+		},
+	}
+}
+
+// encryptDB encrypts the plaintext database with a new passphrase.
+func encryptDB() error {
+	if dbPath == "" {
+		return errors.New("environment variable BITE_DB_PATH or --db must be set")
+	}
+	enc, err := bite.IsEncrypted(dbPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	if enc {
+		return errors.New("database is already encrypted")
+	}
+
+	pass, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return err
+	}
+	if len(pass) == 0 {
+		return errors.New("passphrase can't be empty")
+	}
+	confirm, err := readPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(pass, confirm) {
+		return errors.New("passphrases don't match")
+	}
+
+	k, err := bite.NewKey(pass)
+	if err != nil {
+		return err
+	}
+	unlock, err := bite.Lock(dbPath, true)
+	if err != nil {
+		return fmt.Errorf("couldn't lock database: %v", err)
+	}
+	defer unlock()
+	if err := bite.EncryptFile(dbPath, k); err != nil {
+		return fmt.Errorf("couldn't encrypt database: %v", err)
+	}
+	if err := cacheKey(k); err != nil {
+		return err
+	}
+	fmt.Println("Database encrypted.")
+	return nil
+}
+
+// decryptDB decrypts the database and forgets its key.
+func decryptDB() error {
+	if dbPath == "" {
+		return errors.New("environment variable BITE_DB_PATH or --db must be set")
+	}
+	enc, err := bite.IsEncrypted(dbPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	if !enc {
+		return errors.New("database isn't encrypted")
+	}
+
+	err = withKey(func(k *bite.Key) error {
+		unlock, err := bite.Lock(dbPath, true)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return bite.DecryptFile(dbPath, k)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't decrypt database: %v", err)
+	}
+	if err := forgetKey(); err != nil {
+		return err
+	}
+	fmt.Println("Database decrypted.")
+	return nil
+}
+
+// withKey calls f with the key of the encrypted database. The cached key
+// is tried first. If there is none, or it is wrong, the user is asked
+// for the passphrase. A key that works is cached.
+func withKey(f func(k *bite.Key) error) error {
+	salt, err := bite.FileSalt(dbPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+
+	if k := cachedKey(salt); k != nil {
+		err := f(k)
+		if !errors.Is(err, bite.ErrWrongPassphrase) {
+			return err
+		}
+		if err := forgetKey(); err != nil {
+			return err
+		}
+	}
+
+	pass, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return err
+	}
+	k := bite.DeriveKey(pass, salt)
+	if err := f(k); err != nil {
+		return err
+	}
+	return cacheKey(k)
+}
+
+// readPassphrase prompts for a passphrase. Input isn't echoed when read
+// from a terminal.
+func readPassphrase(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		pass, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read passphrase: %v", err)
+		}
+		return pass, nil
+	}
+
+	line, err := bite.Input.ReadString('\n')
+	if err != nil && line == "" {
+		return nil, fmt.Errorf("couldn't read passphrase: %v", err)
+	}
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
+
+// keyCachePath returns the path of the cached key, or an empty string
+// if keys can't be cached.
+func keyCachePath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "bite", "key")
+}
+
+// cachedKey returns the cached key if it was derived with the given
+// salt.
+func cachedKey(salt []byte) *bite.Key {
+	path := keyCachePath()
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) <= len(salt) || !bytes.Equal(data[:len(salt)], salt) {
+		return nil
+	}
+	return &bite.Key{Salt: salt, Secret: data[len(salt):]}
+}
+
+// cacheKey stores the key so that it is only asked for once per
+// session.
+func cacheKey(k *bite.Key) error {
+	path := keyCachePath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("couldn't cache key: %v", err)
+	}
+	data := append(append([]byte{}, k.Salt...), k.Secret...)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("couldn't cache key: %v", err)
+	}
+	return nil
+}
+
+// forgetKey removes the cached key.
+func forgetKey() error {
+	path := keyCachePath()
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("couldn't remove cached key: %v", err)
+	}
+	return nil
+}
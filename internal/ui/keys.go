@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ericstrs/bite"
+	"github.com/gdamore/tcell/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/rivo/tview"
+)
+
+// keySettingPrefix prefixes the settings table keys that hold custom
+// keybindings, e.g. "key.down".
+const keySettingPrefix = "key."
+
+// action is a navigation action that can be bound to a key sequence.
+type action string
+
+const (
+	actionDown         action = "down"
+	actionUp           action = "up"
+	actionTop          action = "top"
+	actionBottom       action = "bottom"
+	actionSearch       action = "search"
+	actionHalfPageDown action = "half_page_down"
+	actionHalfPageUp   action = "half_page_up"
+	actionHelp         action = "help"
+)
+
+// keyActions lists the actions in the order keymaps are built and
+// checked in, so that conflicts are reported the same way each time.
+var keyActions = []action{
+	actionDown,
+	actionUp,
+	actionTop,
+	actionBottom,
+	actionSearch,
+	actionHalfPageDown,
+	actionHalfPageUp,
+	actionHelp,
+}
+
+// defaultKeys holds the default vim-like key sequence of each action.
+// Control keys are written as "ctrl-<letter>".
+var defaultKeys = map[action]string{
+	actionDown:         "j",
+	actionUp:           "k",
+	actionTop:          "gg",
+	actionBottom:       "G",
+	actionSearch:       "/",
+	actionHalfPageDown: "ctrl-d",
+	actionHalfPageUp:   "ctrl-u",
+	actionHelp:         "?",
+}
+
+// keymap maps key sequences to actions. It keeps track of a partially
+// typed sequence, such as the first "g" of "gg".
+type keymap struct {
+	bindings map[string]action
+	pending  string
+}
+
+// loadKeymap returns the default keymap overridden by any custom
+// keybindings in the settings table. If the custom keybindings can't be
+// used, it returns the default keymap along with the error.
+func loadKeymap(db *sqlx.DB) (*keymap, error) {
+	defaults, err := newKeymap(defaultKeys)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := bite.SettingsWithPrefix(db, keySettingPrefix)
+	if err != nil {
+		return defaults, err
+	}
+	keys, err := customKeys(custom)
+	if err != nil {
+		return defaults, err
+	}
+	km, err := newKeymap(keys)
+	if err != nil {
+		return defaults, err
+	}
+	return km, nil
+}
+
+// customKeys returns the key sequence of each action, overriding the
+// defaults with the custom keybindings, keyed by setting.
+func customKeys(custom map[string]string) (map[action]string, error) {
+	keys := make(map[action]string, len(defaultKeys))
+	for a, k := range defaultKeys {
+		keys[a] = k
+	}
+	for k, v := range custom {
+		a := action(strings.TrimPrefix(k, keySettingPrefix))
+		if _, ok := defaultKeys[a]; !ok {
+			return nil, fmt.Errorf("unknown key action %q", a)
+		}
+		keys[a] = v
+	}
+	return keys, nil
+}
+
+// newKeymap creates a keymap from the key sequence of each action. It
+// returns an error if two actions have the same sequence, or if one
+// sequence starts with another, such as "g" and "gg", since the longer
+// one could then never be typed.
+func newKeymap(keys map[action]string) (*keymap, error) {
+	km := &keymap{bindings: make(map[string]action, len(keys))}
+	for i, a := range keyActions {
+		seq := splitKeys(keys[a])
+		if len(seq) == 0 {
+			return nil, fmt.Errorf("no keys bound to %s", a)
+		}
+		for _, b := range keyActions[:i] {
+			other := splitKeys(keys[b])
+			if hasKeyPrefix(seq, other) || hasKeyPrefix(other, seq) {
+				return nil, fmt.Errorf("keys %q of %s conflict with keys %q of %s", keys[a], a, keys[b], b)
+			}
+		}
+		km.bindings[keys[a]] = a
+	}
+	return km, nil
+}
+
+// splitKeys splits a key sequence into the names of its keys, such as
+// "gctrl-d" into "g" and "ctrl-d".
+func splitKeys(seq string) []string {
+	var keys []string
+	for seq != "" {
+		if strings.HasPrefix(seq, "ctrl-") && len(seq) > len("ctrl-") {
+			keys = append(keys, seq[:len("ctrl-")+1])
+			seq = seq[len("ctrl-")+1:]
+			continue
+		}
+		r := []rune(seq)[0]
+		keys = append(keys, string(r))
+		seq = seq[len(string(r)):]
+	}
+	return keys
+}
+
+// hasKeyPrefix reports whether the key sequence seq starts with the
+// keys of prefix.
+func hasKeyPrefix(seq, prefix []string) bool {
+	if len(prefix) > len(seq) {
+		return false
+	}
+	for i, k := range prefix {
+		if seq[i] != k {
+			return false
+		}
+	}
+	return true
+}
+
+// match feeds a key event to the keymap. It returns the action bound to
+// the typed sequence and whether the event was consumed. A consumed
+// event with an empty action is part of an unfinished sequence.
+func (km *keymap) match(event *tcell.EventKey) (action, bool) {
+	k := keyName(event)
+	if k == "" {
+		km.pending = ""
+		return "", false
+	}
+
+	for _, seq := range []string{km.pending + k, k} {
+		if a, ok := km.bindings[seq]; ok {
+			km.pending = ""
+			return a, true
+		}
+		for b := range km.bindings {
+			if strings.HasPrefix(b, seq) {
+				km.pending = seq
+				return "", true
+			}
+		}
+		// The pending sequence was abandoned. Try the key on its own.
+		if km.pending == "" {
+			break
+		}
+		km.pending = ""
+	}
+	return "", false
+}
+
+// keyName returns the name of the key in the format used by keymap
+// bindings, or an empty string if the key can't be bound.
+func keyName(event *tcell.EventKey) string {
+	switch k := event.Key(); {
+	case k == tcell.KeyRune:
+		if event.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) != 0 {
+			return ""
+		}
+		return string(event.Rune())
+	case k >= tcell.KeyCtrlA && k <= tcell.KeyCtrlZ:
+		return "ctrl-" + string(rune('a'+k-tcell.KeyCtrlA))
+	}
+	return ""
+}
+
+// String lists the keybindings, one per line.
+func (km *keymap) String() string {
+	lines := make([]string, 0, len(km.bindings))
+	for k, a := range km.bindings {
+		lines = append(lines, fmt.Sprintf("%-15s %s", a, k))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// navigate performs a movement action on the table. Movements skip over
+// rows that can't be selected.
+func navigate(table *tview.Table, a action) {
+	row, _ := table.GetSelection()
+	_, _, _, height := table.GetInnerRect()
+	switch a {
+	case actionDown:
+		selectRow(table, row+1, 1)
+	case actionUp:
+		selectRow(table, row-1, -1)
+	case actionTop:
+		selectRow(table, 0, 1)
+	case actionBottom:
+		selectRow(table, table.GetRowCount()-1, -1)
+	case actionHalfPageDown:
+		selectRow(table, row+height/2, 1)
+	case actionHalfPageUp:
+		selectRow(table, row-height/2, -1)
+	}
+}
+
+// selectRow selects the first selectable row at or after the given row
+// when moving in the direction of step. If there is none, the selection
+// is left unchanged.
+func selectRow(table *tview.Table, row, step int) {
+	n := table.GetRowCount()
+	if row < 0 {
+		row, step = 0, 1
+	}
+	if row >= n {
+		row, step = n-1, -1
+	}
+	for r := row; r >= 0 && r < n; r += step {
+		if cell := table.GetCell(r, 0); cell != nil && !cell.NotSelectable {
+			table.Select(r, 0)
+			return
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/ericstrs/bite"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	logEntryFmt  = "  %-20.20s %5.1f %-2s x %-4.1f %5.0f cals"
+	logTotalsFmt = " Total: %.0f cals | protein: %sg | carbs: %sg | fat: %sg | $%.2f"
+)
+
+// setupLogPane configures the daily log pane, which shows today's
+// logged foods grouped by meal slot along with running totals.
+func (sui *SearchUI) setupLogPane() *tview.Flex {
+	sui.logTable = tview.NewTable()
+	sui.logTotals = tview.NewTextView()
+
+	sui.logTable.SetBorder(true).
+		SetTitle(" Today ")
+	style := tcell.StyleDefault.Background(tcell.Color107).Foreground(tcell.ColorBlack)
+	sui.logTable.SetSelectedStyle(style)
+	sui.logTable.SetSelectable(true, false)
+	sui.logTotals.SetDynamicColors(true)
+
+	sui.logInput()
+	sui.refreshLog()
+
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(sui.logTable, 0, 1, false).
+		AddItem(sui.logTotals, 1, 0, false)
+}
+
+// runHooks runs the hooks of the foods just logged in the background,
+// so a slow hook doesn't hold up the interface. Their output is dropped
+// since it would draw over the interface.
+func (sui *SearchUI) runHooks() {
+	go func() {
+		if err := bite.RunHooks(io.Discard); err != nil {
+			log.Println(err)
+		}
+	}()
+}
+
+// refreshLog reloads today's food entries into the log pane and
+// recalculates the totals.
+func (sui *SearchUI) refreshLog() {
+	if sui.logTable == nil {
+		return
+	}
+
+	tx, err := sui.db.Beginx()
+	if err != nil {
+		log.Println("couldn't create transaction: ", err)
+		return
+	}
+	defer tx.Rollback()
+
+	entries, err := bite.FoodEntriesForDate(tx, bite.FoodLogNow())
+	if err != nil {
+		log.Printf("couldn't get today's food entries: %v\n", err)
+		return
+	}
+
+	sui.updateLogList(entries)
+}
+
+// updateLogList updates the log pane with the given food entries.
+func (sui *SearchUI) updateLogList(entries []bite.DailyFood) {
+	table := sui.logTable
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	var cals, protein, carbs, fat, price float64
+	for _, e := range entries {
+		cals += e.Calories
+		protein += e.FoodMacros.Protein
+		carbs += e.FoodMacros.Carbs
+		fat += e.FoodMacros.Fat
+		price += e.Price
+	}
+	sui.logTotals.SetText(fmt.Sprintf(logTotalsFmt, cals, bite.FormatMacro(protein), bite.FormatMacro(carbs), bite.FormatMacro(fat), price))
+
+	if len(entries) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("No foods logged today.").
+			SetSelectable(false))
+		return
+	}
+
+	r := 0
+	for _, slot := range bite.MealSlots {
+		first := true
+		for i := range entries {
+			e := entries[i]
+			if e.Slot() != slot {
+				continue
+			}
+			if first {
+				table.SetCell(r, 0, tview.NewTableCell("[powderblue]"+string(slot)+"[white]").
+					SetSelectable(false))
+				r++
+				first = false
+			}
+			line := fmt.Sprintf(logEntryFmt, e.FoodName, e.ServingSize,
+				e.ServingUnit, e.NumberOfServings, e.Calories)
+			table.SetCell(r, 0, tview.NewTableCell(line).
+				SetReference(&e))
+			r++
+		}
+	}
+
+	// Keep the selection near where it was before the refresh.
+	if row >= r {
+		row = r - 1
+	}
+	if row < 1 {
+		row = 1
+	}
+	table.Select(row, 0)
+}
+
+// logInput handles input capture for the log pane.
+//
+// Navigation is handled by the configurable keymap, the same as the
+// results list.
+//
+// It interprets the following key bindings and triggers corresponding
+// actions:
+//
+//   - Tab: Set focus on the results list.
+//   - enter, e: Edit servings of selected entry.
+//   - m: Move selected entry to another meal slot.
+//   - d: Delete selected entry.
+//   - q: Exits the search interface.
+func (sui *SearchUI) logInput() {
+	sui.logTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a, ok := sui.keys.match(event); ok {
+			switch a {
+			case "": // Sequence not finished yet.
+			case actionSearch:
+				sui.app.SetFocus(sui.inputField)
+			case actionHelp:
+				sui.showModal(sui.keysForm(sui.logTable))
+			default:
+				navigate(sui.logTable, a)
+			}
+			return nil
+		}
+
+		row, col := sui.logTable.GetSelection()
+		e, _ := sui.logTable.GetCell(row, col).GetReference().(*bite.DailyFood)
+
+		switch event.Key() {
+		case tcell.KeyTab:
+			sui.app.SetFocus(sui.list)
+			return nil
+		case tcell.KeyEnter:
+			if e != nil {
+				sui.showModal(sui.editEntryForm(e))
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 'e':
+			if e != nil {
+				sui.showModal(sui.editEntryForm(e))
+			}
+			return nil
+		case 'm':
+			if e != nil {
+				sui.showModal(sui.entrySlotForm(e))
+			}
+			return nil
+		case 'd':
+			if e != nil {
+				sui.showModal(sui.confirmEntryDeletion(e))
+			}
+			return nil
+		case 'q':
+			sui.app.Stop()
+			for _, message := range sui.messages {
+				fmt.Println(message)
+			}
+		}
+		return event
+	})
+}
+
+// closeLogModal removes the modal page and sets focus back to the log
+// pane.
+func (sui *SearchUI) closeLogModal() {
+	sui.closeModal()
+	sui.app.SetFocus(sui.logTable)
+}
+
+// editEntryForm creates and returns a tview form for editing the
+// servings of a food entry.
+func (sui *SearchUI) editEntryForm(e *bite.DailyFood) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Edit Entry")
+
+	servingSize := e.ServingSize
+	numServings := e.NumberOfServings
+
+	// Define the input fields for the forms and update field variables if
+	// user makes any changes to the default values.
+	form.AddInputField("Serving Size", fmt.Sprintf("%.1f", servingSize), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		servingSize = num
+	})
+	form.AddInputField("Num Servings", fmt.Sprintf("%.1f", numServings), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		numServings = num
+	})
+
+	showingErr := false
+	form.AddButton("Save", func() {
+		if servingSize <= 0 || numServings <= 0 {
+			if !showingErr {
+				errorMsg := "Please enter non-zero values."
+				showingErr = true
+				form.AddFormItem(tview.NewTextView().SetText(errorMsg).SetTextAlign(tview.AlignCenter))
+			}
+			return
+		}
+
+		tx, err := sui.db.Beginx()
+		defer tx.Rollback()
+		if err != nil {
+			log.Println("couldn't create transaction: ", err)
+			return
+		}
+		if err := bite.UpdateFoodEntryServings(tx, e, servingSize, numServings); err != nil {
+			log.Println(err)
+			return
+		}
+		tx.Commit()
+		sui.messages = append(sui.messages, fmt.Sprintf("Updated food entry %q", e.FoodName))
+
+		sui.refreshLog()
+		sui.closeLogModal()
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeLogModal()
+	})
+
+	return form
+}
+
+// entrySlotForm creates and returns a tview form for moving a food
+// entry to another meal slot.
+func (sui *SearchUI) entrySlotForm(e *bite.DailyFood) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Change Meal Slot")
+
+	options := make([]string, len(bite.MealSlots))
+	current := 0
+	for i, s := range bite.MealSlots {
+		options[i] = string(s)
+		if s == e.Slot() {
+			current = i
+		}
+	}
+	slot := e.Slot()
+	form.AddDropDown("Meal Slot", options, current, func(option string, _ int) {
+		slot = bite.MealSlot(option)
+	})
+
+	form.AddButton("Save", func() {
+		tx, err := sui.db.Beginx()
+		defer tx.Rollback()
+		if err != nil {
+			log.Println("couldn't create transaction: ", err)
+			return
+		}
+		if err := bite.UpdateFoodEntrySlot(tx, e.ID, slot); err != nil {
+			log.Println(err)
+			return
+		}
+		tx.Commit()
+		sui.messages = append(sui.messages, fmt.Sprintf("Moved food entry %q to %s", e.FoodName, slot))
+
+		sui.refreshLog()
+		sui.closeLogModal()
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeLogModal()
+	})
+
+	return form
+}
+
+// confirmEntryDeletion creates and returns a tview form for deleting a
+// food entry.
+func (sui *SearchUI) confirmEntryDeletion(e *bite.DailyFood) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle(fmt.Sprintf("Delete entry %q?", e.FoodName))
+
+	form.AddButton("Confirm", func() {
+		tx, err := sui.db.Beginx()
+		defer tx.Rollback()
+		if err != nil {
+			log.Println("couldn't create transaction: ", err)
+			return
+		}
+		if err := bite.DeleteOneFoodEntry(tx, e.ID); err != nil {
+			log.Println("couldn't delete food entry: ", err)
+			return
+		}
+		tx.Commit()
+		sui.messages = append(sui.messages, fmt.Sprintf("Deleted food entry %q", e.FoodName))
+
+		sui.refreshLog()
+		sui.closeLogModal()
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeLogModal()
+	})
+
+	return form
+}
@@ -0,0 +1,412 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/ericstrs/bite"
+	"github.com/gdamore/tcell/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/rivo/tview"
+)
+
+// mealWizard builds a new meal one food at a time. Foods are searched
+// for in the results list and added to the meal pane, which shows the
+// running totals of the meal. Nothing is saved until the meal is.
+type mealWizard struct {
+	sui *SearchUI
+
+	// name is the name of the new meal.
+	name string
+
+	// foods holds the foods added to the meal so far, scaled to their
+	// servings in the meal.
+	foods []bite.Food
+
+	// table displays the foods of the meal.
+	table *tview.Table
+
+	// totals displays the running totals of the meal.
+	totals *tview.TextView
+}
+
+// NewMealWizard creates a search UI for building a new meal with the
+// given name, starting from the given foods.
+func NewMealWizard(db *sqlx.DB, name string, foods []bite.Food) *SearchUI {
+	sui := &SearchUI{
+		app:          tview.NewApplication(),
+		inputField:   tview.NewInputField(),
+		list:         tview.NewTable(),
+		db:           db,
+		item:         `food`,
+		screenWidth:  50,
+		messages:     []string{},
+		selecting:    true,
+		selectedFood: &bite.Food{},
+	}
+
+	var err error
+	sui.keys, err = loadKeymap(db)
+	if err != nil {
+		log.Printf("couldn't load keybindings, using defaults: %v\n", err)
+	}
+
+	mw := &mealWizard{
+		sui:    sui,
+		name:   name,
+		foods:  foods,
+		table:  tview.NewTable(),
+		totals: tview.NewTextView(),
+	}
+	mw.setupUI()
+
+	return sui
+}
+
+// setupUI configures the food search and the meal pane.
+func (mw *mealWizard) setupUI() {
+	sui := mw.sui
+
+	sui.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		sui.screenWidth, _ = screen.Size()
+		return false
+	})
+
+	search := sui.setupSelectUI()
+	sui.list.SetSelectedFunc(func(row, col int) {
+		f, ok := sui.list.GetCell(row, col).GetReference().(*bite.Food)
+		if !ok {
+			return
+		}
+		sui.showModal(mw.servingsForm(*f, sui.list))
+	})
+	mw.listInput()
+
+	mw.table.SetBorder(true)
+	style := tcell.StyleDefault.Background(tcell.Color107).Foreground(tcell.ColorBlack)
+	mw.table.SetSelectedStyle(style)
+	mw.table.SetSelectable(true, false)
+	mw.totals.SetDynamicColors(true)
+	mw.tableInput()
+	mw.refresh()
+
+	pane := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(mw.table, 0, 1, false).
+		AddItem(mw.totals, 1, 0, false)
+
+	flex := tview.NewFlex().
+		AddItem(search, 0, 3, true).
+		AddItem(pane, 0, 2, false)
+
+	sui.pages = tview.NewPages().
+		AddPage("", flex, true, true)
+
+	sui.app.SetRoot(sui.pages, true)
+}
+
+// listInput adds the meal wizard key bindings to the results list:
+//
+//   - enter: Set the servings of the selected food and add it to the
+//     meal.
+//   - Tab: Set focus on the meal pane.
+//   - c: Save the meal.
+func (mw *mealWizard) listInput() {
+	sui := mw.sui
+	capture := sui.list.GetInputCapture()
+	sui.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyTab:
+			sui.app.SetFocus(mw.table)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+			sui.showModal(mw.saveForm(sui.list))
+			return nil
+		}
+		return capture(event)
+	})
+}
+
+// tableInput handles input capture for the meal pane.
+//
+// Navigation is handled by the configurable keymap, the same as the
+// results list.
+//
+// It interprets the following key bindings and triggers corresponding
+// actions:
+//
+//   - Tab: Set focus on the results list.
+//   - enter, s: Set servings of selected food.
+//   - d: Remove selected food from the meal.
+//   - c: Save the meal.
+//   - q: Exits without saving.
+func (mw *mealWizard) tableInput() {
+	sui := mw.sui
+	mw.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a, ok := sui.keys.match(event); ok {
+			switch a {
+			case "": // Sequence not finished yet.
+			case actionSearch:
+				sui.app.SetFocus(sui.inputField)
+			case actionHelp:
+				sui.showModal(sui.keysForm(mw.table))
+			default:
+				navigate(mw.table, a)
+			}
+			return nil
+		}
+
+		row, _ := mw.table.GetSelection()
+		selected := row >= 0 && row < len(mw.foods)
+
+		switch event.Key() {
+		case tcell.KeyTab:
+			sui.app.SetFocus(sui.list)
+			return nil
+		case tcell.KeyEnter:
+			if selected {
+				sui.showModal(mw.servingsForm(mw.foods[row], mw.table))
+			}
+			return nil
+		}
+
+		switch event.Rune() {
+		case 's':
+			if selected {
+				sui.showModal(mw.servingsForm(mw.foods[row], mw.table))
+			}
+			return nil
+		case 'd':
+			if selected {
+				mw.foods = append(mw.foods[:row], mw.foods[row+1:]...)
+				mw.refresh()
+			}
+			return nil
+		case 'c':
+			sui.showModal(mw.saveForm(mw.table))
+			return nil
+		case 'q':
+			sui.app.Stop()
+			for _, message := range sui.messages {
+				fmt.Println(message)
+			}
+		}
+		return event
+	})
+}
+
+// refresh shows the foods of the meal in the meal pane and recalculates
+// the totals.
+func (mw *mealWizard) refresh() {
+	table := mw.table
+	row, _ := table.GetSelection()
+	table.Clear()
+
+	title := "New meal"
+	if mw.name != "" {
+		title = mw.name
+	}
+	table.SetTitle(fmt.Sprintf(" %s: %d foods (c to save) ", title, len(mw.foods)))
+
+	n := bite.FoodsTotal(mw.foods)
+	mw.totals.SetText(fmt.Sprintf(logTotalsFmt, n.Calories, bite.FormatMacro(n.Protein),
+		bite.FormatMacro(n.Carbs), bite.FormatMacro(n.Fat), n.Price))
+
+	if len(mw.foods) == 0 {
+		table.SetCell(0, 0, tview.NewTableCell("Select foods to add them to the meal.").
+			SetSelectable(false))
+		return
+	}
+
+	for i, f := range mw.foods {
+		line := fmt.Sprintf(logEntryFmt, f.Name, f.ServingSize,
+			f.ServingUnit, f.NumberOfServings, f.Calories)
+		table.SetCell(i, 0, tview.NewTableCell(line))
+	}
+
+	// Keep the selection near where it was before the refresh.
+	if row >= len(mw.foods) {
+		row = len(mw.foods) - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	table.Select(row, 0)
+}
+
+// foodIndex returns the index of the food with the given id in the
+// meal, or -1 if the food isn't part of the meal.
+func (mw *mealWizard) foodIndex(foodID int) int {
+	for i, f := range mw.foods {
+		if f.ID == foodID {
+			return i
+		}
+	}
+	return -1
+}
+
+// servingsForm prompts for the serving size and number of servings of
+// a food and adds it to the meal, or updates its servings if it is
+// already part of the meal. Focus returns to the given table when the
+// form is closed.
+func (mw *mealWizard) servingsForm(f bite.Food, table *tview.Table) *tview.Form {
+	sui := mw.sui
+	i := mw.foodIndex(f.ID)
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	switch i {
+	case -1:
+		form.SetTitle("Add " + f.Name)
+	default:
+		form.SetTitle("Edit " + f.Name)
+	}
+
+	servingSize := f.ServingSize
+	numServings := f.NumberOfServings
+
+	// Define the input fields for the forms and update field variables if
+	// user makes any changes to the default values.
+	form.AddInputField(fmt.Sprintf("Serving Size (%s)", f.ServingUnit), fmt.Sprintf("%.1f", servingSize), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		servingSize = num
+	})
+	form.AddInputField("Num Servings", fmt.Sprintf("%.1f", numServings), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		numServings = num
+	})
+
+	showingErr := false
+	form.AddButton("Save", func() {
+		if servingSize <= 0 || numServings <= 0 {
+			if !showingErr {
+				errorMsg := "Please enter non-zero values."
+				showingErr = true
+				form.AddFormItem(tview.NewTextView().SetText(errorMsg).SetTextAlign(tview.AlignCenter))
+			}
+			return
+		}
+
+		// Scale a copy of the macros so the food in the results list is
+		// left unchanged.
+		if f.FoodMacros != nil {
+			m := *f.FoodMacros
+			f.FoodMacros = &m
+		}
+		bite.ScaleServings(&f, servingSize, numServings)
+		switch i {
+		case -1:
+			mw.foods = append(mw.foods, f)
+			mw.table.Select(len(mw.foods)-1, 0)
+		default:
+			mw.foods[i] = f
+		}
+		mw.refresh()
+
+		sui.closeModal()
+		sui.app.SetFocus(table)
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeModal()
+		sui.app.SetFocus(table)
+	})
+
+	return form
+}
+
+// saveForm prompts for the name of the meal and saves it along with
+// the servings of its foods. Focus returns to the given table when the
+// form is closed without saving.
+func (mw *mealWizard) saveForm(table *tview.Table) *tview.Form {
+	sui := mw.sui
+
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Save Meal")
+
+	form.AddInputField("Meal Name", mw.name, 20, nil, func(text string) {
+		mw.name = text
+	})
+
+	var errView *tview.TextView
+	showErr := func(msg string) {
+		if errView == nil {
+			errView = tview.NewTextView().SetTextAlign(tview.AlignCenter)
+			form.AddFormItem(errView)
+		}
+		errView.SetText(msg)
+	}
+
+	form.AddButton("Save", func() {
+		name := strings.TrimSpace(mw.name)
+		switch {
+		case name == "":
+			showErr("Please enter a meal name.")
+			return
+		case len(mw.foods) == 0:
+			showErr("Please add at least one food.")
+			return
+		}
+
+		tx, err := sui.db.Beginx()
+		if err != nil {
+			showErr(fmt.Sprintf("couldn't create transaction: %v", err))
+			return
+		}
+		defer tx.Rollback()
+
+		if _, err := bite.SaveMeal(tx, name, mw.foods); err != nil {
+			showErr(err.Error())
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			showErr(fmt.Sprintf("couldn't save meal: %v", err))
+			return
+		}
+
+		n := bite.FoodsTotal(mw.foods)
+		sui.messages = append(sui.messages, fmt.Sprintf("Created meal %q with %d foods: %.0f cals, $%.2f.",
+			name, len(mw.foods), n.Calories, n.Price))
+		sui.app.Stop()
+		for _, message := range sui.messages {
+			fmt.Println(message)
+		}
+	})
+
+	form.AddButton("Cancel", func() {
+		mw.refresh()
+		sui.closeModal()
+		sui.app.SetFocus(table)
+	})
+
+	return form
+}
+
+// mealFoods returns the foods of the meal with the given name scaled to
+// their servings in the meal, for starting a new meal from it.
+func mealFoods(db *sqlx.DB, name string) ([]bite.Food, error) {
+	m, err := bite.MealByName(db, name)
+	if err != nil {
+		return nil, err
+	}
+	mfs, err := bite.MealFoodsWithPref(db, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	foods := make([]bite.Food, len(mfs))
+	for i, mf := range mfs {
+		foods[i] = mf.Food
+		foods[i].ServingSize = mf.ServingSize
+		foods[i].NumberOfServings = mf.NumberOfServings
+	}
+	return foods, nil
+}
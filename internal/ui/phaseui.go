@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ericstrs/bite"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// sparkTicks are the characters used to draw a sparkline, from lowest
+// to highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+type PhaseUI struct {
+	// app is a reference to the tview application
+	app *tview.Application
+
+	// info displays the phase details and adherence stats.
+	info *tview.TextView
+
+	// weeks displays the weekly progress of the phase.
+	weeks *tview.Table
+
+	u *bite.UserInfo
+	p bite.PhaseProgress
+
+	// forecast is the weight forecast for the weeks ahead, if there are
+	// enough recent weights to fit one.
+	forecast []bite.Forecast
+}
+
+// NewPhaseUI creates and initializes a new PhaseUI for the user's active
+// diet phase.
+func NewPhaseUI(u *bite.UserInfo, entries []bite.Entry) *PhaseUI {
+	pui := &PhaseUI{
+		app:   tview.NewApplication(),
+		info:  tview.NewTextView(),
+		weeks: tview.NewTable(),
+		u:     u,
+		p:     bite.Progress(u, entries, bite.LogNow()),
+	}
+	if m, ok := bite.FitWeight(entries); ok {
+		pui.forecast = m.Weeks()
+	}
+
+	pui.setupUI()
+
+	return pui
+}
+
+// setupUI configures the phase progress UI elements.
+func (pui *PhaseUI) setupUI() {
+	pui.info.SetDynamicColors(true).
+		SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s phase ", pui.u.Phase.Name))
+	pui.info.SetText(pui.infoText())
+
+	pui.weeks.SetBorder(true).
+		SetTitle(" Weeks ")
+	pui.updateWeeksTable()
+
+	pui.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			pui.app.Stop()
+			return nil
+		}
+		return event
+	})
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(pui.info, 11, 0, false).
+		AddItem(pui.weeks, 0, 1, true)
+
+	pui.app.SetRoot(flex, true)
+}
+
+// infoText returns the phase details, weight sparklines followed by the
+// forecast for the weeks ahead with a mark under the weeks that have
+// timeline notes, and adherence stats.
+func (pui *PhaseUI) infoText() string {
+	phase := pui.u.Phase
+	p := pui.p
+
+	var weights, goals []float64
+	var marks strings.Builder
+	for _, w := range p.Weeks {
+		weights = append(weights, w.AvgWeight)
+		goals = append(goals, w.GoalWeight)
+		if len(w.Notes) > 0 {
+			marks.WriteRune('^')
+		} else {
+			marks.WriteRune(' ')
+		}
+	}
+	// The forecast is drawn after the weeks so far.
+	ahead := make([]float64, len(weights))
+	for _, f := range pui.forecast {
+		ahead = append(ahead, f.Weight)
+	}
+	lo, hi := sparkRange(append(append(weights, goals...), ahead...))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " %s to %s | %d days elapsed | [powderblue]%d days remaining[white]\n",
+		phase.StartDate.Format(dateFormat), phase.EndDate.Format(dateFormat),
+		p.DaysElapsed, p.DaysRemaining)
+	fmt.Fprintf(&sb, " Start weight: %.1f | Goal weight: %s | Goal calories: %.0f\n\n",
+		phase.StartWeight, bite.FormatGoalWeight(phase), phase.GoalCalories)
+	fmt.Fprintf(&sb, " Weight:   [green]%s[white]\n", sparkline(weights, lo, hi))
+	fmt.Fprintf(&sb, " Forecast: [blue]%s[white]", sparkline(ahead, lo, hi))
+	if n := len(pui.forecast); n > 0 {
+		f := pui.forecast[n-1]
+		fmt.Fprintf(&sb, " %s (%s to %s) by %s", bite.FormatWeight(f.Weight),
+			bite.FormatWeight(f.Low), bite.FormatWeight(f.High), f.Date.Format(dateFormat))
+	}
+	fmt.Fprintf(&sb, "\n Goal:     [yellow]%s[white]\n", sparkline(goals, lo, hi))
+	fmt.Fprintf(&sb, " Notes:    [orange]%s[white]\n\n", marks.String())
+	fmt.Fprintf(&sb, " Adherence: %d of %d logged days met the calorie goal (%.0f%%)",
+		p.AdherentDays, p.LoggedDays, p.Adherence())
+	return sb.String()
+}
+
+// updateWeeksTable fills the weeks table with the weekly progress.
+func (pui *PhaseUI) updateWeeksTable() {
+	headers := []string{"Week", "Start", "Avg Weight", "Goal Weight", "Logged Days", "Notes"}
+	for col, h := range headers {
+		pui.weeks.SetCell(0, col, tview.NewTableCell("[powderblue]"+h+"[white]").
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	for i, w := range pui.p.Weeks {
+		avg := "-"
+		if w.LoggedDays > 0 {
+			color := "[green]"
+			if !onTrack(pui.u, w) {
+				color = "[red]"
+			}
+			avg = fmt.Sprintf("%s%s[white]", color, bite.FormatWeight(w.AvgWeight))
+		}
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			w.Start.Format(dateFormat),
+			avg,
+			bite.FormatWeight(w.GoalWeight),
+			fmt.Sprintf("%d", w.LoggedDays),
+			weekNoteText(w.Notes),
+		}
+		for col, s := range row {
+			pui.weeks.SetCell(i+1, col, tview.NewTableCell(s).
+				SetExpansion(1))
+		}
+	}
+}
+
+// weekNoteText returns the texts of the week's timeline notes.
+func weekNoteText(notes []bite.Note) string {
+	texts := make([]string, len(notes))
+	for i, n := range notes {
+		texts[i] = n.Text
+	}
+	return tview.Escape(strings.Join(texts, "; "))
+}
+
+// onTrack reports whether the average weight of the week is on the
+// right side of the goal trajectory for the phase.
+func onTrack(u *bite.UserInfo, w bite.WeekProgress) bool {
+	const tolerance = 0.5 // lbs
+	switch u.Phase.Name {
+	case "cut":
+		return w.AvgWeight <= w.GoalWeight+tolerance
+	case "bulk":
+		return w.AvgWeight >= w.GoalWeight-tolerance
+	default:
+		return math.Abs(w.AvgWeight-w.GoalWeight) <= tolerance
+	}
+}
+
+// sparkRange returns the lowest and highest non-zero values.
+func sparkRange(values []float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if v == 0 {
+			continue
+		}
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// sparkline draws the values as a sparkline scaled between lo and hi.
+// Zero values are treated as missing and drawn as a space.
+func sparkline(values []float64, lo, hi float64) string {
+	var sb strings.Builder
+	for _, v := range values {
+		if v == 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		sb.WriteRune(sparkTicks[idx])
+	}
+	return sb.String()
+}
+
+// Run starts the TUI application.
+func (pui *PhaseUI) Run() error {
+	return pui.app.Run()
+}
@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/ericstrs/bite"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	photoLong = `  Progress photos are kept in photos_dir from the config file, or the
+  "photos" directory next to the database, in a directory for each diet
+  phase, named after the day they were taken. Adding photos copies
+  them, or links to them with --link.
+
+  Take photos in the same poses and add them in the same order each
+  time, so "bite photo compare" pairs them up.`
+
+	photoCompareLong = `  Compare pairs the photos of the first day with photos on or after
+  --from with those of the last day with photos on or before --to, in
+  the order they were added, and prints their paths and the weights of
+  the two days. With --open, each photo is opened in the default image
+  viewer.`
+
+	exportPhotosLong = `  Write an HTML contact sheet of every progress photo, grouped by diet
+  phase and captioned with the day and its weight. Without a file name
+  the sheet is written to index.html in the photos directory. A sheet
+  written elsewhere must be moved there to show the photos.`
+)
+
+func photoCmd() *Command {
+	var date, from, to string
+	var link, open bool
+	return &Command{
+		Name:  `photo`,
+		Short: `Manages progress photos.`,
+		Long:  photoLong,
+		Commands: []*Command{
+			{
+				Name:  `add`,
+				Short: `Add progress photos.`,
+				Args:  `<file>...`,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, `date`, ``, `date the photos were taken (YYYY-MM-DD), defaults to today`)
+					fs.BoolVar(&link, `link`, false, `link to the photos instead of copying them`)
+				},
+				Run: withDB(func(db *sqlx.DB, args []string) error {
+					if len(args) == 0 {
+						return errors.New("add takes the photos to add")
+					}
+					d := bite.LogNow()
+					if date != "" {
+						var err error
+						if d, err = bite.ValidateDateStr(date); err != nil {
+							return fmt.Errorf("invalid --date %q: %v", date, err)
+						}
+					}
+					dir := photosDir()
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					var added []string
+					for _, src := range args {
+						p, err := bite.AddPhoto(tx, dir, src, d, link)
+						if err != nil {
+							for _, path := range added {
+								os.Remove(path)
+							}
+							return err
+						}
+						added = append(added, filepath.Join(dir, p.Path))
+					}
+					if err := tx.Commit(); err != nil {
+						return err
+					}
+					for _, path := range added {
+						fmt.Printf("Added %s.\n", path)
+					}
+					return nil
+				}),
+			},
+			{
+				Name:  `compare`,
+				Short: `Pair up the photos of two days.`,
+				Long:  photoCompareLong,
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&from, `from`, ``, `first date to compare (YYYY-MM-DD), defaults to the first photo`)
+					fs.StringVar(&to, `to`, ``, `last date to compare (YYYY-MM-DD), defaults to today`)
+					fs.BoolVar(&open, `open`, false, `open the photos in the default image viewer`)
+				},
+				Run: withDB(func(db *sqlx.DB, _ []string) error {
+					start, end := time.Time{}, bite.LogNow()
+					var err error
+					if from != "" {
+						if start, err = bite.ValidateDateStr(from); err != nil {
+							return fmt.Errorf("invalid --from %q: %v", from, err)
+						}
+					}
+					if to != "" {
+						if end, err = bite.ValidateDateStr(to); err != nil {
+							return fmt.Errorf("invalid --to %q: %v", to, err)
+						}
+					}
+
+					tx, err := db.Beginx()
+					if err != nil {
+						return err
+					}
+					defer tx.Rollback()
+					pairs, err := bite.ComparePhotos(tx, start, end)
+					if err != nil {
+						return err
+					}
+					dir := photosDir()
+					bite.PrintPhotoPairs(dir, pairs)
+					if !open {
+						return nil
+					}
+					for _, pair := range pairs {
+						for _, p := range []*bite.Photo{pair.Before, pair.After} {
+							if p == nil {
+								continue
+							}
+							if err := openFile(filepath.Join(dir, filepath.FromSlash(p.Path))); err != nil {
+								return err
+							}
+						}
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+// exportPhotosCmd writes the contact sheet of the progress photos.
+func exportPhotosCmd() *Command {
+	return &Command{
+		Name:  `photos`,
+		Short: `Write a contact sheet of the progress photos as HTML.`,
+		Long:  exportPhotosLong,
+		Args:  `[<file>]`,
+		Run: withDB(func(db *sqlx.DB, args []string) error {
+			if len(args) > 1 {
+				return errors.New("photos takes at most one file name")
+			}
+			path := filepath.Join(photosDir(), "index.html")
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			tx, err := db.Beginx()
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+			photos, err := bite.Photos(tx, time.Time{}, bite.LogNow())
+			if err != nil {
+				return err
+			}
+			if len(photos) == 0 {
+				return errors.New("no progress photos")
+			}
+
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("couldn't create contact sheet: %v", err)
+			}
+			if err := bite.WriteContactSheet(f, photos); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote a contact sheet of %d photos to %s.\n", len(photos), path)
+			return nil
+		}),
+	}
+}
+
+// photosDir returns the directory progress photos are kept in.
+func photosDir() string {
+	if bite.PhotosDir != "" {
+		return bite.PhotosDir
+	}
+	return filepath.Join(filepath.Dir(dbPath), "photos")
+}
+
+// openFile opens the file in the default application for its type.
+func openFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("couldn't open %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/ericstrs/bite"
+	"github.com/jmoiron/sqlx"
+)
+
+const serveLong = `  Serve exposes gauges of the diet at /metrics in the Prometheus text
+  format, for charting in Grafana or alerting: today's calories and
+  calorie goal, the protein left to reach the goal, the trend weight,
+  and the day of the active phase. Stop it with Ctrl-C.
+
+  The database is opened for each scrape, so the metrics follow new logs
+  and other commands can use it in between. An encrypted database can't
+  be served, as it would have to be decrypted for each scrape.`
+
+func serveCmd() *Command {
+	var addr string
+	return &Command{
+		Name:  `serve`,
+		Short: `Serves diet metrics for monitoring.`,
+		Long:  serveLong,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&addr, `addr`, `localhost:9792`, `address to listen on`)
+		},
+		Run: func([]string) error {
+			return serveMetrics(addr)
+		},
+	}
+}
+
+// serveMetrics serves the diet metrics at addr until interrupted. The
+// database is only locked while a scrape reads it.
+func serveMetrics(addr string) error {
+	if dbPath == "" {
+		return errors.New("environment variable BITE_DB_PATH or --db must be set")
+	}
+	enc, err := bite.IsEncrypted(dbPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	if enc {
+		return errors.New("can't serve an encrypted database")
+	}
+	// Bring the schema up to date once, so scrapes only read.
+	if err := runWithDB(false, func(*sqlx.DB, []string) error { return nil }, nil); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		m, err := scrapeMetrics()
+		if err != nil {
+			log.Printf("couldn't read metrics: %v\n", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		bite.WriteMetrics(w, m)
+	})
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Printf("Serving metrics at http://%s/metrics\n", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// scrapeMetrics opens the database, reads the diet metrics, and closes
+// it again.
+func scrapeMetrics() (*bite.Metrics, error) {
+	db, closeDB, err := openDB(false, false)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(false)
+	return bite.ReadMetrics(db, bite.LogNow())
+}
@@ -15,8 +15,12 @@ import (
 
 const (
 	dateFormat   = "2006-01-02"
-	resultsFmt   = "%-5.1f %-2s x %-2.1f serving  |%-3.0f cals| protein: %.1fg, carbs: %.1fg, fat: %.1fg\n"
-	mfResultsFmt = "  %-5.1f %-2s x %-2.1f serving %6.0f %10.1fg %13.1fg %11.1fg\n"
+	resultsFmt   = "%-5.1f %-2s x %-2.1f serving  |%-3.0f cals| protein: %sg, carbs: %sg, fat: %sg\n"
+	mfResultsFmt = "  %-5.1f %-2s x %-2.1f serving %6.0f %10sg %13sg %11sg\n"
+
+	// lazyLoadFoods is how many foods before the end of the results list
+	// the next page of results is loaded.
+	lazyLoadFoods = 5
 )
 
 type SearchUI struct {
@@ -48,6 +52,35 @@ type SearchUI struct {
 
 	selecting    bool
 	selectedFood *bite.Food
+
+	// marked holds the foods marked for the current log session. They
+	// are logged together as one dated batch.
+	marked []bite.Food
+
+	// logTable displays today's logged foods.
+	logTable *tview.Table
+
+	// logTotals displays the running totals of today's logged foods.
+	logTotals *tview.TextView
+
+	// keys maps key sequences to navigation actions.
+	keys *keymap
+
+	// searchTerm is the search term of the foods shown in the results
+	// list, and searchOffset is the number of its results loaded so
+	// far. While moreResults is set, the next page of results is loaded
+	// when the end of the list is reached.
+	searchTerm   string
+	searchOffset int
+	moreResults  bool
+	loadingMore  bool
+
+	// portion is the portion of a meal logged, e.g. 0.5 for half of it.
+	portion float64
+
+	// restricted holds the foods the user's restrictions avoid, keyed by
+	// food id, with the flags they conflict on.
+	restricted map[int][]string
 }
 
 // NewSearchUI creates and initializes a new SearchUI.
@@ -60,6 +93,17 @@ func NewSearchUI(db *sqlx.DB, query, item string) *SearchUI {
 		item:        item,
 		screenWidth: 50,
 		messages:    []string{},
+		portion:     1,
+	}
+
+	var err error
+	sui.keys, err = loadKeymap(db)
+	if err != nil {
+		log.Printf("couldn't load keybindings, using defaults: %v\n", err)
+	}
+	sui.restricted, err = bite.RestrictedFoods(db)
+	if err != nil {
+		log.Printf("couldn't load restricted foods: %v\n", err)
 	}
 
 	sui.setupUI(query)
@@ -102,11 +146,15 @@ func (sui *SearchUI) setupUI(query string) {
 		}
 	})
 
-	flex := tview.NewFlex().
+	search := tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(sui.inputField, 1, 0, true).
 		AddItem(sui.list, 0, 1, false)
 
+	flex := tview.NewFlex().
+		AddItem(search, 0, 3, true).
+		AddItem(sui.setupLogPane(), 0, 2, false)
+
 	sui.pages = tview.NewPages().
 		AddPage("", flex, true, true)
 
@@ -157,16 +205,22 @@ func (sui *SearchUI) setupFoodUI(query string) {
 		sui.app.QueueUpdateDraw(func() {
 			text := sui.inputField.GetText()
 			if text == "" {
-				sui.updateFoodsList(foods)
+				sui.showFoodResults("", foods)
 			}
 		})
 	}()
 
 	sui.ipInputFood(&foods)
+	sui.list.SetSelectionChangedFunc(func(row, _ int) {
+		// Load more results before the end of the list is reached.
+		if row >= sui.list.GetRowCount()-3*lazyLoadFoods {
+			sui.loadMoreFoods()
+		}
+	})
 
 	switch query {
 	case "":
-		sui.updateFoodsList(foods)
+		sui.showFoodResults("", foods)
 	default:
 		sui.inputField.SetText(query)
 	}
@@ -247,13 +301,13 @@ func (sui *SearchUI) ipInputFood(foods *[]bite.Food) {
 				latestText := sui.inputField.GetText()
 				if latestText == "" {
 					sui.app.QueueUpdateDraw(func() {
-						sui.updateFoodsList(*foods)
+						sui.showFoodResults("", *foods)
 					})
 					return
 				}
 				results := sui.performFoodSearch(latestText)
 				sui.app.QueueUpdateDraw(func() {
-					sui.updateFoodsList(results)
+					sui.showFoodResults(latestText, results)
 				})
 			}()
 		})
@@ -375,6 +429,44 @@ func (sui *SearchUI) performMealSearch(query string) []bite.Meal {
 	return meals
 }
 
+// showFoodResults shows the foods found for the search term in the
+// results list. Results of a full-text search are paged, and the next
+// page is loaded once the end of the list is reached.
+func (sui *SearchUI) showFoodResults(term string, foods []bite.Food) {
+	sui.searchTerm = term
+	sui.searchOffset = bite.SearchLimit
+	sui.moreResults = term != "" && !strings.HasPrefix(term, `recent:`) && len(foods) > 0
+	sui.updateFoodsList(foods)
+}
+
+// loadMoreFoods loads the next page of the search results in the
+// background and adds it to the end of the results list.
+func (sui *SearchUI) loadMoreFoods() {
+	if !sui.moreResults || sui.loadingMore {
+		return
+	}
+	sui.loadingMore = true
+	term, offset := sui.searchTerm, sui.searchOffset
+	go func() {
+		foods, err := bite.SearchFoodsPage(sui.db, term, offset)
+		sui.app.QueueUpdateDraw(func() {
+			sui.loadingMore = false
+			// Drop the page if the search changed while it loaded.
+			if term != sui.searchTerm || offset != sui.searchOffset {
+				return
+			}
+			if err != nil {
+				log.Printf("couldn't load more foods: %v\n", err)
+				sui.moreResults = false
+				return
+			}
+			sui.searchOffset += bite.SearchLimit
+			sui.moreResults = len(foods) > 0
+			sui.addFoodRows(foods)
+		})
+	}()
+}
+
 // updateFoodsList updates the results list with a given slice of food.
 func (sui *SearchUI) updateFoodsList(foods []bite.Food) {
 	list := sui.list
@@ -383,22 +475,26 @@ func (sui *SearchUI) updateFoodsList(foods []bite.Food) {
 		list.SetCellSimple(0, 0, "No matches found.")
 		return
 	}
-	row := 0
+	sui.addFoodRows(foods)
+	sui.list.ScrollToBeginning()
+}
+
+// addFoodRows adds the foods to the end of the results list.
+func (sui *SearchUI) addFoodRows(foods []bite.Food) {
+	list := sui.list
+	row := list.GetRowCount()
 	for i := 0; i < len(foods); i++ {
 		f := foods[i]
-		var s string
-		switch f.BrandName == "" {
-		case true:
-			s = fmt.Sprintf("[powderblue]%s[white]", f.Name)
-		case false:
-			s = fmt.Sprintf("[powderblue]%s (%s)[white]", f.Name, f.BrandName)
+		// Show the batch servings for foods marked for logging.
+		if j := sui.markedIndex(f.ID); j != -1 {
+			f = sui.marked[j]
 		}
-		list.SetCell(row, 0, tview.NewTableCell(s).
+		list.SetCell(row, 0, tview.NewTableCell(sui.foodTitle(f)).
 			SetReference(&f))
 		row++
 		line := fmt.Sprintf(resultsFmt, f.ServingSize, f.ServingUnit,
-			f.NumberOfServings, f.Calories, f.FoodMacros.Protein,
-			f.FoodMacros.Carbs, f.FoodMacros.Fat)
+			f.NumberOfServings, f.Calories, bite.FormatMacro(f.FoodMacros.Protein),
+			bite.FormatMacro(f.FoodMacros.Carbs), bite.FormatMacro(f.FoodMacros.Fat))
 		list.SetCell(row, 0, tview.NewTableCell(line).
 			SetSelectable(false))
 		row++
@@ -406,7 +502,59 @@ func (sui *SearchUI) updateFoodsList(foods []bite.Food) {
 			SetSelectable(false))
 		row++
 	}
-	sui.list.ScrollToBeginning()
+}
+
+// foodTitle returns the text of a food's title cell. Foods marked for
+// logging are prefixed with a plus sign, and foods the user's
+// restrictions avoid list what they contain.
+func (sui *SearchUI) foodTitle(f bite.Food) string {
+	s := "[powderblue]" + f.Name
+	if f.BrandName != "" {
+		s += " (" + f.BrandName + ")"
+	}
+	if src := bite.SourceName(f.Source); src != "" {
+		s += " [gray]" + src
+	}
+	if flags, ok := sui.restricted[f.ID]; ok {
+		s += " [red]contains " + strings.Join(flags, ", ")
+	}
+	s += "[white]"
+	if sui.markedIndex(f.ID) != -1 {
+		s = "[green]+[white] " + s
+	}
+	return s
+}
+
+// markedIndex returns the index of the food with the given id in the
+// marked foods, or -1 if the food isn't marked.
+func (sui *SearchUI) markedIndex(foodID int) int {
+	for i, f := range sui.marked {
+		if f.ID == foodID {
+			return i
+		}
+	}
+	return -1
+}
+
+// toggleMark marks the given food for logging, or unmarks it if it is
+// already marked.
+func (sui *SearchUI) toggleMark(f *bite.Food) {
+	switch i := sui.markedIndex(f.ID); i {
+	case -1:
+		sui.marked = append(sui.marked, *f)
+	default:
+		sui.marked = append(sui.marked[:i], sui.marked[i+1:]...)
+	}
+	sui.updateMarkedTitle()
+}
+
+// updateMarkedTitle shows the number of marked foods in the list title.
+func (sui *SearchUI) updateMarkedTitle() {
+	if len(sui.marked) == 0 {
+		sui.list.SetTitle("")
+		return
+	}
+	sui.list.SetTitle(fmt.Sprintf(" %d marked (c to log) ", len(sui.marked)))
 }
 
 // updateMealsList updates the results list with a given slice of meals.
@@ -418,6 +566,7 @@ func (sui *SearchUI) updateMealsList(meals []bite.Meal) {
 		return
 	}
 	row := 0
+	slot := bite.SlotForTime(bite.Now())
 	for i := 0; i < len(meals); i++ {
 		m := meals[i]
 		s := "[powderblue]" + m.Name + "[white]"
@@ -431,17 +580,25 @@ func (sui *SearchUI) updateMealsList(meals []bite.Meal) {
 				SetReference(&mf))
 			row++
 			line := fmt.Sprintf(mfResultsFmt, mf.ServingSize, mf.Food.ServingUnit,
-				mf.NumberOfServings, mf.Food.Calories, mf.Food.FoodMacros.Protein,
-				mf.Food.FoodMacros.Carbs, mf.Food.FoodMacros.Fat)
+				mf.NumberOfServings, mf.Food.Calories, bite.FormatMacro(mf.Food.FoodMacros.Protein),
+				bite.FormatMacro(mf.Food.FoodMacros.Carbs), bite.FormatMacro(mf.Food.FoodMacros.Fat))
 			list.SetCell(row, 0, tview.NewTableCell(line).
 				SetSelectable(false))
 			row++
 		}
-		line := fmt.Sprintf("TOTAL: %24.1f cals %5.1fg protein %5.1fg carbs %5.1fg fat",
-			m.Cals, m.Protein, m.Carbs, m.Fats)
+		line := fmt.Sprintf("TOTAL: %24.1f cals %5sg protein %5sg carbs %5sg fat",
+			m.Cals, bite.FormatMacro(m.Protein), bite.FormatMacro(m.Carbs), bite.FormatMacro(m.Fats))
 		list.SetCell(row, 0, tview.NewTableCell(line).
 			SetSelectable(false))
 		row++
+		// Flag meals that fall short of the target of the current slot.
+		macros := bite.FoodMacros{Protein: m.Protein, Carbs: m.Carbs, Fat: m.Fats}
+		if short := bite.SlotShortfalls(slot, macros); len(short) > 0 {
+			line := fmt.Sprintf("[red]Below the %s target: %s[white]", slot, strings.Join(short, ", "))
+			list.SetCell(row, 0, tview.NewTableCell(line).
+				SetSelectable(false))
+			row++
+		}
 		list.SetCell(row, 0, tview.NewTableCell("").
 			SetSelectable(false))
 		row++
@@ -451,6 +608,16 @@ func (sui *SearchUI) updateMealsList(meals []bite.Meal) {
 
 // listInput handles input capture for the list.
 //
+// Navigation is handled by the configurable keymap. The default
+// bindings are:
+//
+//   - j, k: Move down or up. Pressing k on the first result sets focus
+//     on the input field.
+//   - gg, G: Move to the first or last result.
+//   - ctrl-d, ctrl-u: Move half a page down or up.
+//   - /: Set focus on the input field.
+//   - ?: Show keybindings.
+//
 // It interprets the following key bindings and triggers corresponding
 // actions:
 //
@@ -461,14 +628,25 @@ func (sui *SearchUI) updateMealsList(meals []bite.Meal) {
 //   - H: Move to the top of the visible window.
 //   - M: Move to the center of the visible window.
 //   - L: Move to bottom of the visible window.
-//   - space: Page down
+//   - space: Mark or unmark selected food for logging. Page down when
+//     searching meals.
+//   - s: Set servings of selected food and mark it for logging.
+//   - c: Log all marked foods.
+//   - Tab: Set focus on the daily log pane.
 //   - b: Page up
-//
-// If selection is on first result and 'k' is pressed, set focus on
-// input field.
 func (sui *SearchUI) listInput() {
 	sui.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if a, ok := sui.keys.match(event); ok {
+			sui.listAction(a)
+			return nil
+		}
+
 		switch event.Key() {
+		case tcell.KeyTab: // Focus daily log pane
+			if sui.logTable != nil {
+				sui.app.SetFocus(sui.logTable)
+			}
+			return nil
 		case tcell.KeyEnter: // Log item
 			row, col := sui.list.GetSelection()
 			cell := sui.list.GetCell(row, col)
@@ -480,7 +658,7 @@ func (sui *SearchUI) listInput() {
 				sui.showModal(form)
 				return nil
 			}
-			date := time.Now()
+			date := bite.FoodLogNow()
 
 			switch i := cell.GetReference().(type) {
 			case *bite.Food:
@@ -496,6 +674,8 @@ func (sui *SearchUI) listInput() {
 				}
 				tx.Commit()
 				sui.messages = append(sui.messages, "Logged food \""+i.Name+"\"")
+				sui.refreshLog()
+				sui.runHooks()
 			case *bite.Meal:
 				if len(i.Foods) == 0 {
 					return nil
@@ -509,7 +689,7 @@ func (sui *SearchUI) listInput() {
 				}
 
 				// Bulk insert the foods that make up the meal into the daily_foods table.
-				if err := bite.AddMealFoodEntries(tx, i.ID, i.Foods, date); err != nil {
+				if err := bite.AddMealFoodEntries(tx, i.ID, i.Foods, sui.portion, date); err != nil {
 					form := sui.errorForm("", err)
 					sui.showModal(form)
 					return nil
@@ -519,6 +699,8 @@ func (sui *SearchUI) listInput() {
 				for _, mf := range i.Foods {
 					sui.messages = append(sui.messages, "Logged food \""+mf.Name+"\"")
 				}
+				sui.refreshLog()
+				sui.runHooks()
 			case *bite.MealFood:
 				// TODO: log selected food
 			default:
@@ -555,8 +737,19 @@ func (sui *SearchUI) listInput() {
 				sui.list.Select(row+height-1, 0)
 			case 'b': // page up (Ctrl-B)
 				return tcell.NewEventKey(tcell.KeyCtrlB, 0, tcell.ModNone)
-			case ' ': // page down
-				row, _ := sui.list.GetOffset()
+			case ' ': // mark food or page down
+				row, col := sui.list.GetSelection()
+				cell := sui.list.GetCell(row, col)
+				if f, ok := cell.GetReference().(*bite.Food); ok && !sui.selecting {
+					sui.toggleMark(f)
+					cell.SetText(sui.foodTitle(*f))
+					// Move selection to the next food.
+					if row+3 < sui.list.GetRowCount() {
+						sui.list.Select(row+3, col)
+					}
+					return nil
+				}
+				row, _ = sui.list.GetOffset()
 				_, _, _, height := sui.list.GetInnerRect()
 				newRow := row + height
 				if newRow > sui.list.GetRowCount()-1 {
@@ -564,6 +757,21 @@ func (sui *SearchUI) listInput() {
 				}
 				sui.list.SetOffset(newRow, 0)
 				sui.list.Select(newRow, 0)
+			case 's': // set batch servings
+				row, col := sui.list.GetSelection()
+				cell := sui.list.GetCell(row, col)
+				if f, ok := cell.GetReference().(*bite.Food); ok && !sui.selecting {
+					form := sui.servingsForm(f)
+					sui.showModal(form)
+				}
+				return nil
+			case 'c': // log marked foods
+				if len(sui.marked) == 0 || sui.selecting {
+					return nil
+				}
+				form := sui.logMarkedForm()
+				sui.showModal(form)
+				return nil
 			case 'e': // Edit
 				row, col := sui.list.GetSelection()
 				cell := sui.list.GetCell(row, col)
@@ -597,6 +805,7 @@ func (sui *SearchUI) listInput() {
 						messages:     []string{},
 						selecting:    true,
 						selectedFood: &bite.Food{},
+						keys:         sui.keys,
 					}
 
 					ssui.list.SetSelectedFunc(func(row, col int) {
@@ -669,18 +878,51 @@ func (sui *SearchUI) listInput() {
 				for _, message := range sui.messages {
 					fmt.Println(message)
 				}
-			case 'k':
-				row, _ := sui.list.GetSelection()
-				if row == 0 {
-					sui.list.SetSelectable(false, false)
-					sui.app.SetFocus(sui.inputField)
-				}
 			}
 		}
 		return event
 	})
 }
 
+// listAction performs a keymap action on the results list.
+func (sui *SearchUI) listAction(a action) {
+	row, _ := sui.list.GetSelection()
+	switch a {
+	case "": // Sequence not finished yet.
+	case actionSearch:
+		sui.list.SetSelectable(false, false)
+		sui.app.SetFocus(sui.inputField)
+	case actionUp:
+		if row == 0 {
+			sui.list.SetSelectable(false, false)
+			sui.app.SetFocus(sui.inputField)
+			return
+		}
+		navigate(sui.list, a)
+	case actionHelp:
+		sui.showModal(sui.keysForm(sui.list))
+	default:
+		navigate(sui.list, a)
+	}
+}
+
+// keysForm creates and returns a tview form listing the keybindings.
+// Focus returns to the given table when the form is closed.
+func (sui *SearchUI) keysForm(table *tview.Table) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Keybindings")
+
+	form.AddFormItem(tview.NewTextView().SetText(sui.keys.String()))
+
+	form.AddButton("Ok", func() {
+		sui.closeModal()
+		sui.app.SetFocus(table)
+	})
+
+	return form
+}
+
 // promptLogFoodForm prompts user for date before logging the food.
 func (sui *SearchUI) promptLogFoodForm(f *bite.Food) *tview.Form {
 	form := tview.NewForm()
@@ -688,12 +930,16 @@ func (sui *SearchUI) promptLogFoodForm(f *bite.Food) *tview.Form {
 	form.SetTitle("Log Food")
 
 	showingErr := false
-	date := time.Now().Format("2006-01-02")
+	date := bite.FoodLogNow().Format("2006-01-02")
 	// Define the input fields for the forms and update field variables if
 	// user makes any changes to the default values.
 	form.AddInputField("Enter Date (YYYY-MM-DD):", date, 20, nil, func(text string) {
 		date = text
 	})
+	warning := bite.RestrictionWarning(sui.restricted, *f)
+	if warning != "" {
+		form.AddFormItem(tview.NewTextView().SetText(warning).SetTextColor(tcell.ColorRed))
+	}
 
 	form.AddButton("Save", func() {
 		d, err := bite.ValidateDateStr(date)
@@ -725,6 +971,165 @@ func (sui *SearchUI) promptLogFoodForm(f *bite.Food) *tview.Form {
 		}
 		tx.Commit()
 		sui.messages = append(sui.messages, "Logged food \""+f.Name+"\"")
+		if warning != "" {
+			sui.messages = append(sui.messages, warning)
+		}
+
+		sui.refreshLog()
+		sui.runHooks()
+		sui.closeModal()
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeModal()
+	})
+
+	return form
+}
+
+// servingsForm prompts for the serving size and number of servings of
+// a food for the current log session and marks the food for logging.
+// Food preferences are left unchanged.
+func (sui *SearchUI) servingsForm(f *bite.Food) *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle("Set Servings")
+
+	servingSize := f.ServingSize
+	numServings := f.NumberOfServings
+
+	// Define the input fields for the forms and update field variables if
+	// user makes any changes to the default values.
+	form.AddInputField("Serving Size", fmt.Sprintf("%.1f", servingSize), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		servingSize = num
+	})
+	form.AddInputField("Num Servings", fmt.Sprintf("%.1f", numServings), 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			num = 0
+		}
+		numServings = num
+	})
+
+	// Let the user pick one of the food's recently logged servings.
+	history, err := bite.ServingHistory(sui.db, f.ID)
+	if err != nil {
+		log.Println("couldn't get serving history: ", err)
+	}
+	recent := bite.RecentServings(history)
+	if len(recent) > 0 {
+		now := bite.LogNow()
+		options := make([]string, len(recent))
+		for i, r := range recent {
+			options[i] = bite.ServingLabel(r, f.ServingUnit, i == 0, now)
+		}
+		form.AddDropDown("Recent Servings", options, -1, func(_ string, i int) {
+			if i < 0 {
+				return
+			}
+			sizeField := form.GetFormItemByLabel("Serving Size").(*tview.InputField)
+			numField := form.GetFormItemByLabel("Num Servings").(*tview.InputField)
+			sizeField.SetText(fmt.Sprintf("%.1f", recent[i].ServingSize))
+			numField.SetText(fmt.Sprintf("%.1f", recent[i].NumberOfServings))
+		})
+	}
+
+	showingErr := false
+	form.AddButton("Save", func() {
+		if servingSize <= 0 || numServings <= 0 {
+			if !showingErr {
+				errorMsg := "Please enter non-zero values."
+				showingErr = true
+				form.AddFormItem(tview.NewTextView().SetText(errorMsg).SetTextAlign(tview.AlignCenter))
+			}
+			return
+		}
+
+		bite.ScaleServings(f, servingSize, numServings)
+		switch i := sui.markedIndex(f.ID); i {
+		case -1:
+			sui.marked = append(sui.marked, *f)
+		default:
+			sui.marked[i] = *f
+		}
+		sui.updateMarkedTitle()
+		sui.updateSelectedFood(*f)
+
+		sui.closeModal()
+	})
+
+	form.AddButton("Cancel", func() {
+		sui.closeModal()
+	})
+
+	return form
+}
+
+// logMarkedForm prompts user for date before logging all the marked
+// foods as one batch.
+func (sui *SearchUI) logMarkedForm() *tview.Form {
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetTitle(fmt.Sprintf("Log %d Foods", len(sui.marked)))
+
+	showingErr := false
+	date := bite.FoodLogNow().Format("2006-01-02")
+	// Define the input fields for the forms and update field variables if
+	// user makes any changes to the default values.
+	form.AddInputField("Enter Date (YYYY-MM-DD):", date, 20, nil, func(text string) {
+		date = text
+	})
+	for _, f := range sui.marked {
+		if w := bite.RestrictionWarning(sui.restricted, f); w != "" {
+			form.AddFormItem(tview.NewTextView().SetText(w).SetTextColor(tcell.ColorRed))
+		}
+	}
+
+	form.AddButton("Save", func() {
+		d, err := bite.ValidateDateStr(date)
+		if err != nil {
+			if !showingErr {
+				errorMsg := "Please enter valid date: YYYY-MM-DD"
+				showingErr = true
+				form.AddFormItem(tview.NewTextView().SetText(errorMsg).SetTextAlign(tview.AlignCenter))
+			}
+			return
+		}
+
+		tx, err := sui.db.Beginx()
+		if err != nil {
+			log.Println("couldn't create transaction: ", err)
+			return
+		}
+		defer tx.Rollback()
+
+		if err := bite.AddFoodEntries(tx, sui.marked, d); err != nil {
+			bite.DiscardEvents()
+			log.Printf("couldn't add food log: %v\n", err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			// The foods weren't logged, so neither are their events.
+			bite.DiscardEvents()
+			form.AddFormItem(tview.NewTextView().SetText(fmt.Sprintf("couldn't log foods: %v", err)).SetTextAlign(tview.AlignCenter))
+			return
+		}
+		for _, f := range sui.marked {
+			sui.messages = append(sui.messages, "Logged food \""+f.Name+"\"")
+			if w := bite.RestrictionWarning(sui.restricted, f); w != "" {
+				sui.messages = append(sui.messages, w)
+			}
+		}
+
+		sui.marked = nil
+		sui.updateMarkedTitle()
+		sui.refreshFoodsList()
+		sui.refreshLog()
+		sui.runHooks()
 
 		sui.closeModal()
 	})
@@ -743,12 +1148,24 @@ func (sui *SearchUI) promptLogMealForm(m *bite.Meal) *tview.Form {
 	form.SetTitle("Log Meal")
 
 	showingErr := false
-	date := time.Now().Format("2006-01-02")
+	date := bite.FoodLogNow().Format("2006-01-02")
 	// Define the input fields for the forms and update field variables if
 	// user makes any changes to the default values.
 	form.AddInputField("Enter Date (YYYY-MM-DD):", date, 20, nil, func(text string) {
 		date = text
 	})
+	portion := strconv.FormatFloat(sui.portion, 'f', -1, 64)
+	form.AddInputField("Portion (e.g. 0.5)", portion, 20, nil, func(text string) {
+		portion = text
+	})
+	var leftover float64
+	form.AddInputField("Leftover Servings", "0", 20, nil, func(text string) {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil || num < 0 {
+			num = 0
+		}
+		leftover = num
+	})
 
 	form.AddButton("Save", func() {
 		if len(m.Foods) == 0 {
@@ -771,6 +1188,16 @@ func (sui *SearchUI) promptLogMealForm(m *bite.Meal) *tview.Form {
 			return
 		}
 
+		p, err := bite.ParsePortion(portion)
+		if err != nil {
+			if !showingErr {
+				showingErr = true
+				errorMsg := "Please enter a portion greater than 0, e.g. 0.5"
+				form.AddFormItem(tview.NewTextView().SetText(errorMsg).SetTextAlign(tview.AlignCenter))
+			}
+			return
+		}
+
 		tx, err := sui.db.Beginx()
 		defer tx.Rollback()
 		if err != nil {
@@ -786,7 +1213,11 @@ func (sui *SearchUI) promptLogMealForm(m *bite.Meal) *tview.Form {
 		}
 
 		// Bulk insert the foods that make up the meal into the daily_foods table.
-		if err := bite.AddMealFoodEntries(tx, m.ID, m.Foods, d); err != nil {
+		if err := bite.AddMealFoodEntries(tx, m.ID, m.Foods, p, d); err != nil {
+			log.Println(err)
+			return
+		}
+		if err := bite.AddLeftover(tx, m.ID, d, leftover); err != nil {
 			log.Println(err)
 			return
 		}
@@ -796,6 +1227,8 @@ func (sui *SearchUI) promptLogMealForm(m *bite.Meal) *tview.Form {
 			sui.messages = append(sui.messages, "Logged food \""+mf.Name+"\"")
 		}
 
+		sui.refreshLog()
+		sui.runHooks()
 		sui.closeModal()
 	})
 
@@ -831,6 +1264,9 @@ func (sui *SearchUI) editFoodForm(f *bite.Food) *tview.Form {
 	form.AddInputField("Brand Name", brandName, 20, nil, func(text string) {
 		brandName = text
 	})
+	if src := bite.SourceName(f.Source); src != "" {
+		form.AddTextView("Source", src, 20, 1, false, false)
+	}
 	form.AddInputField("Serving Size", fmt.Sprintf("%.1f", servingSize), 20, nil, func(text string) {
 		num, err := strconv.ParseFloat(text, 64)
 		if err != nil {
@@ -1090,7 +1526,7 @@ func (sui *SearchUI) confirmFoodDeletion(f *bite.Food) *tview.Form {
 		case false:
 			foods = sui.performFoodSearch(text)
 		}
-		sui.updateFoodsList(foods)
+		sui.showFoodResults(text, foods)
 
 		sui.closeModal()
 	})
@@ -1329,7 +1765,7 @@ func (sui *SearchUI) addFoodForm(name string) *tview.Form {
 		case false:
 			foods = sui.performFoodSearch(text)
 		}
-		sui.updateFoodsList(foods)
+		sui.showFoodResults(text, foods)
 
 		sui.closeModal()
 	})
@@ -1361,15 +1797,32 @@ func updateFoodTable(tx *sqlx.Tx, food bite.Food) error {
 func (sui *SearchUI) updateSelectedFood(f bite.Food) {
 	row, col := sui.list.GetSelection()
 	cell := sui.list.GetCell(row, col)
-	s := "[powderblue]" + f.Name + "[white]"
-	cell.SetText(s)
+	cell.SetText(sui.foodTitle(f))
 	line := fmt.Sprintf(resultsFmt, f.ServingSize, f.ServingUnit,
-		f.NumberOfServings, f.Calories, f.FoodMacros.Protein,
-		f.FoodMacros.Carbs, f.FoodMacros.Fat)
+		f.NumberOfServings, f.Calories, bite.FormatMacro(f.FoodMacros.Protein),
+		bite.FormatMacro(f.FoodMacros.Carbs), bite.FormatMacro(f.FoodMacros.Fat))
 	descCell := sui.list.GetCell(row+1, col)
 	descCell.SetText(line)
 }
 
+// refreshFoodsList reloads the foods list for the current search query.
+func (sui *SearchUI) refreshFoodsList() {
+	var foods []bite.Food
+	text := sui.inputField.GetText()
+	switch text == "" {
+	case true:
+		var err error
+		foods, err = bite.RecentlyLoggedFoods(sui.db, bite.SearchLimit)
+		if err != nil {
+			log.Printf("couldn't get recently logged foods: %v\n", err)
+			return
+		}
+	case false:
+		foods = sui.performFoodSearch(text)
+	}
+	sui.showFoodResults(text, foods)
+}
+
 // updateSelectedMeal updates the selected meal in the results list.
 func (sui *SearchUI) updateSelectedMeal(m bite.Meal) {
 	row, col := sui.list.GetSelection()
@@ -1385,8 +1838,8 @@ func (sui *SearchUI) updateSelectedMealFood(mf bite.MealFood) {
 	cell := sui.list.GetCell(row, col)
 	cell.SetText("* " + mf.Food.Name)
 	line := fmt.Sprintf(mfResultsFmt, mf.ServingSize, mf.Food.ServingUnit,
-		mf.NumberOfServings, mf.Food.Calories, mf.Food.FoodMacros.Protein,
-		mf.Food.FoodMacros.Carbs, mf.Food.FoodMacros.Fat)
+		mf.NumberOfServings, mf.Food.Calories, bite.FormatMacro(mf.Food.FoodMacros.Protein),
+		bite.FormatMacro(mf.Food.FoodMacros.Carbs), bite.FormatMacro(mf.Food.FoodMacros.Fat))
 	descCell := sui.list.GetCell(row+1, col)
 	descCell.SetText(line)
 }
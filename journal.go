@@ -0,0 +1,279 @@
+package bite
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// JournalDay is a day of a journal week.
+type JournalDay struct {
+	Date   time.Time
+	Weight float64 // Logged weight, 0 without a weigh-in.
+	Logged bool    // Whether any foods were logged.
+	Nutrition
+	Goal float64
+	Met  bool // Whether the calorie goal was met.
+}
+
+// Journal is a week of the diet, Monday to Sunday, written as Markdown
+// for a diet journal.
+type Journal struct {
+	Year     int
+	Week     int
+	Days     []JournalDay
+	CheckIns []CheckIn
+	// Supplements is how many days of the week each supplement was
+	// taken.
+	Supplements []SupplementAdherence
+	// Sleep is the week's sleep, and SleepTrend compares the weeks of
+	// poor and good sleep of the 8 weeks up to it.
+	Sleep      SleepWeek
+	SleepTrend SleepComparison
+	// Fiber is the week's fiber and sugar.
+	Fiber FiberWeek
+	// Notes are the week's timeline notes.
+	Notes []Note
+}
+
+// journalSleepWeeks is the number of weeks compared in a journal's
+// sleep section.
+const journalSleepWeeks = 8
+
+// ParseISOWeek parses an ISO 8601 week such as "2024-W12" and returns
+// the Monday it starts on.
+func ParseISOWeek(s string) (time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(strings.ToUpper(strings.TrimSpace(s)), "%d-W%d", &year, &week); err != nil {
+		return time.Time{}, fmt.Errorf("week must be written as YYYY-Www, e.g. 2024-W12, got %q", s)
+	}
+	// January 4th is always in the first week.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+7*(week-1))
+	if y, w := monday.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("%d has no week %d", year, week)
+	}
+	return monday, nil
+}
+
+// WeekJournal reads the ISO week of day: each day's logged weight,
+// nutrition, and whether it met the calorie goal, and the week's
+// check-ins, supplements, and sleep.
+func WeekJournal(db *sqlx.DB, u *UserInfo, day time.Time) (*Journal, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	day = dateOf(day)
+	monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	sunday := monday.AddDate(0, 0, 6)
+	j := &Journal{}
+	j.Year, j.Week = monday.ISOWeek()
+
+	s, err := DietRange(tx, u, monday, sunday)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]DayTotals)
+	for _, d := range s.Days {
+		totals[d.Date.Format(dateFormat)] = d
+	}
+	splits, err := carbSplits(tx, monday, sunday)
+	if err != nil {
+		return nil, err
+	}
+	var fiber []CarbSplit
+
+	if err := addWeightColumns(tx); err != nil {
+		return nil, err
+	}
+	var weights []Entry
+	const weightsSQL = `
+		SELECT date, weight AS user_weight FROM daily_weights
+		WHERE estimated = 0 AND date BETWEEN $1 AND $2
+	`
+	if err := tx.Select(&weights, weightsSQL, monday.Format(dateFormat), sunday.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get weights: %v", err)
+	}
+	weighIns := make(map[string]float64)
+	for _, w := range weights {
+		weighIns[w.Date.Format(dateFormat)] = w.UserWeight
+	}
+
+	for d := monday; !d.After(sunday); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateFormat)
+		day := JournalDay{Date: d, Weight: weighIns[key]}
+		if t, ok := totals[key]; ok {
+			day.Logged = true
+			day.Nutrition = t.Nutrition
+			day.Goal = t.Goal
+			day.Met = metDayGoal(u, day.Calories, day.Goal)
+			if c, ok := splits[key]; ok {
+				c.FiberMin, c.SugarMax = fiberMin(day.Goal), sugarMax(day.Goal)
+				fiber = append(fiber, c)
+			}
+		} else if day.Goal, err = dayGoal(tx, u, d); err != nil {
+			return nil, err
+		}
+		j.Days = append(j.Days, day)
+	}
+	j.Fiber = fiberWeek(fiber)
+
+	if j.Notes, err = NotesBetween(tx, monday, sunday); err != nil {
+		return nil, err
+	}
+
+	if err := addCheckInColumns(tx); err != nil {
+		return nil, err
+	}
+	const checkInsSQL = `
+		SELECT date, hunger, energy, sleep, performance, notes
+		FROM checkins
+		WHERE date BETWEEN $1 AND $2
+		ORDER BY date
+	`
+	if err := tx.Select(&j.CheckIns, checkInsSQL, monday.Format(dateFormat), sunday.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get check-ins: %v", err)
+	}
+
+	if j.Supplements, err = SupplementsBetween(tx, monday, sunday.AddDate(0, 0, 1)); err != nil {
+		return nil, err
+	}
+
+	sleep, err := SleepWeeks(tx, u, monday.AddDate(0, 0, -7*(journalSleepWeeks-1)), journalSleepWeeks)
+	if err != nil {
+		return nil, err
+	}
+	j.Sleep = sleep[len(sleep)-1]
+	j.SleepTrend = CompareSleep(sleep)
+	return j, nil
+}
+
+// metDayGoal reports whether the calories met the day's goal: staying
+// under it on a cut, reaching it on a bulk, and staying within
+// DayCalTolerance of it otherwise.
+func metDayGoal(u *UserInfo, cals, goal float64) bool {
+	if u.Phase.Status == "active" {
+		switch u.Phase.Name {
+		case "cut":
+			return cals <= goal
+		case "bulk":
+			return cals >= goal
+		}
+	}
+	return math.Abs(cals-goal) <= DayCalTolerance*goal
+}
+
+// WriteJournal writes the week as Markdown: a table of the days with
+// ✓ or ✗ for whether each met its calorie goal, followed by the fiber
+// and sugar, the check-ins and their notes, the supplements taken, and
+// the sleep.
+func WriteJournal(w io.Writer, j *Journal) error {
+	bw := bufio.NewWriter(w)
+	first, last := j.Days[0].Date, j.Days[len(j.Days)-1].Date
+	fmt.Fprintf(bw, "# Week %d-W%02d\n\n", j.Year, j.Week)
+	fmt.Fprintf(bw, "%s to %s\n\n", first.Format(dateFormat), last.Format(dateFormat))
+
+	fmt.Fprintln(bw, "| Day | Weight | Calories | Goal | Protein | Carbs | Fat | Goal met |")
+	fmt.Fprintln(bw, "| --- | ---: | ---: | ---: | ---: | ---: | ---: | :---: |")
+	var weighIns, logged, met int
+	var weight float64
+	var total Nutrition
+	for _, d := range j.Days {
+		wt := ""
+		if d.Weight > 0 {
+			wt = FormatWeight(d.Weight)
+			weight += d.Weight
+			weighIns++
+		}
+		if !d.Logged {
+			fmt.Fprintf(bw, "| %s | %s | | %.0f | | | | |\n", d.Date.Format("Mon 01-02"), wt, d.Goal)
+			continue
+		}
+		mark := "✗"
+		if d.Met {
+			mark = "✓"
+			met++
+		}
+		logged++
+		total.Calories += d.Calories
+		total.Protein += d.Protein
+		total.Carbs += d.Carbs
+		total.Fat += d.Fat
+		fmt.Fprintf(bw, "| %s | %s | %.0f | %.0f | %s | %s | %s | %s |\n", d.Date.Format("Mon 01-02"), wt,
+			d.Calories, d.Goal, FormatMacro(d.Protein), FormatMacro(d.Carbs), FormatMacro(d.Fat), mark)
+	}
+	if logged > 0 {
+		avg := total.times(1 / float64(logged))
+		wt := ""
+		if weighIns > 0 {
+			wt = FormatWeight(weight / float64(weighIns))
+		}
+		fmt.Fprintf(bw, "| **Average** | %s | %.0f | | %s | %s | %s | %d/%d |\n", wt,
+			avg.Calories, FormatMacro(avg.Protein), FormatMacro(avg.Carbs), FormatMacro(avg.Fat), met, logged)
+		fmt.Fprintf(bw, "\nMacros: %s\n", FormatMacroSplit(total.Protein, total.Carbs, total.Fat))
+	}
+
+	if f := j.Fiber; f.Days > 0 {
+		fmt.Fprint(bw, "\n## Fiber and sugar\n\n")
+		fmt.Fprintf(bw, "%sg of fiber and %sg of sugar a day on average, with fiber short of its minimum on %d of %d days",
+			formatNumber(f.Fiber, 0), formatNumber(f.Sugar, 0), f.LowFiber, f.Days)
+		if f.HighSugar > 0 {
+			fmt.Fprintf(bw, " and sugar over its maximum on %d", f.HighSugar)
+		}
+		fmt.Fprintln(bw, ".")
+		if f.Chronic() {
+			fmt.Fprintf(bw, "\n**Warning:** fiber was short most days this week, %sg a day against a minimum of %sg. "+
+				"Add vegetables, fruit, legumes, or whole grains.\n", formatNumber(f.Fiber, 0), formatNumber(f.FiberMin, 0))
+		}
+	}
+
+	if len(j.Notes) > 0 {
+		fmt.Fprint(bw, "\n## Notes\n\n")
+		for _, n := range j.Notes {
+			fmt.Fprintf(bw, "- %s: %s\n", n.Date.Format("Mon 01-02"), n.Text)
+		}
+	}
+
+	if len(j.CheckIns) > 0 {
+		fmt.Fprintln(bw, "\n## Check-ins")
+		for _, c := range j.CheckIns {
+			fmt.Fprintf(bw, "\n### %s\n\n", c.Date.Format(dateFormat))
+			fmt.Fprintf(bw, "Hunger %d/5, energy %d/5, sleep %d/5, gym performance %d/5\n",
+				c.Hunger, c.Energy, c.Sleep, c.Performance)
+			if c.Notes != "" {
+				fmt.Fprintf(bw, "\n%s\n", c.Notes)
+			}
+		}
+	}
+
+	if len(j.Supplements) > 0 {
+		fmt.Fprint(bw, "\n## Supplements\n\n")
+		for _, s := range j.Supplements {
+			fmt.Fprintf(bw, "- %s: %d/%d days\n", s, s.Taken, s.Days)
+		}
+	}
+
+	if j.Sleep.Nights > 0 {
+		fmt.Fprint(bw, "\n## Sleep\n\n")
+		fmt.Fprintf(bw, "%s h a night on average, with %d of 7 nights logged", formatNumber(j.Sleep.Hours, 1), j.Sleep.Nights)
+		if j.Sleep.Poor() {
+			fmt.Fprint(bw, ", a week of poor sleep")
+		}
+		fmt.Fprintln(bw, ".")
+		if lines := j.SleepTrend.Lines(); lines != nil {
+			fmt.Fprintf(bw, "\nOver the last %d weeks:\n\n", journalSleepWeeks)
+			for _, l := range lines {
+				fmt.Fprintf(bw, "- %s\n", l)
+			}
+		}
+	}
+	return bw.Flush()
+}
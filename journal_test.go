@@ -0,0 +1,70 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func ExampleParseISOWeek() {
+	for _, s := range []string{"2024-W12", "2021-W01", "2024-W53"} {
+		d, err := ParseISOWeek(s)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(d.Format(dateFormat))
+	}
+
+	// Output:
+	// 2024-03-18
+	// 2021-01-04
+	// 2024 has no week 53
+}
+
+func ExampleWriteJournal() {
+	monday := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	j := &Journal{Year: 2024, Week: 12}
+	for i := 0; i < 7; i++ {
+		j.Days = append(j.Days, JournalDay{Date: monday.AddDate(0, 0, i), Goal: 2000})
+	}
+	j.Days[0].Weight = 180
+	j.Days[0].Logged, j.Days[0].Met = true, true
+	j.Days[0].Nutrition = Nutrition{Calories: 1950, Protein: 180, Carbs: 170, Fat: 62}
+	j.Days[1].Logged = true
+	j.Days[1].Nutrition = Nutrition{Calories: 2250, Protein: 150, Carbs: 240, Fat: 75}
+	j.CheckIns = []CheckIn{{
+		Date: monday.AddDate(0, 0, 6), Hunger: 3, Energy: 4, Sleep: 2, Performance: 4,
+		Notes: "Slept badly after the late workouts.",
+	}}
+
+	if err := WriteJournal(os.Stdout, j); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// # Week 2024-W12
+	//
+	// 2024-03-18 to 2024-03-24
+	//
+	// | Day | Weight | Calories | Goal | Protein | Carbs | Fat | Goal met |
+	// | --- | ---: | ---: | ---: | ---: | ---: | ---: | :---: |
+	// | Mon 03-18 | 180.0 | 1950 | 2000 | 180.0 | 170.0 | 62.0 | ✓ |
+	// | Tue 03-19 |  | 2250 | 2000 | 150.0 | 240.0 | 75.0 | ✗ |
+	// | Wed 03-20 |  | | 2000 | | | | |
+	// | Thu 03-21 |  | | 2000 | | | | |
+	// | Fri 03-22 |  | | 2000 | | | | |
+	// | Sat 03-23 |  | | 2000 | | | | |
+	// | Sun 03-24 |  | | 2000 | | | | |
+	// | **Average** | 180.0 | 2100 | | 165.0 | 205.0 | 68.5 | 1/2 |
+	//
+	// Macros: P 31% / C 39% / F 29% of calories
+	//
+	// ## Check-ins
+	//
+	// ### 2024-03-24
+	//
+	// Hunger 3/5, energy 4/5, sleep 2/5, gym performance 4/5
+	//
+	// Slept badly after the late workouts.
+}
@@ -0,0 +1,254 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Label is the nutrition facts read from a pasted nutrition label.
+// Amounts are per serving. Fields that couldn't be read are zero and
+// missing from Parsed.
+type Label struct {
+	ServingSize      float64
+	ServingUnit      string
+	HouseholdServing string
+	Calories         float64
+	Fat              float64
+	Carbs            float64
+	Protein          float64
+	// Parsed holds the fields read from the label: "serving",
+	// "calories", "fat", "carbs", and "protein".
+	Parsed map[string]bool
+}
+
+const labelNumber = `<?\s*(\d+(?:\.\d+)?)`
+
+var (
+	labelServing = regexp.MustCompile(`(?i)^serving\s+size[:\s]*(.*)$`)
+	// labelMetric matches an amount in a known unit, such as the "55g"
+	// in "2/3 cup (55g)".
+	labelMetric = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?(?:/\d+)?)\s*([a-z]+)\.?$`)
+	labelFields = []struct {
+		name string
+		re   *regexp.Regexp
+	}{
+		// "Calories from Fat 70" isn't matched since the amount has to
+		// follow the name.
+		{"calories", regexp.MustCompile(`(?i)\bcalories[:\s]*` + labelNumber)},
+		// Saturated and trans fat are skipped.
+		{"fat", regexp.MustCompile(`(?i)^(?:total\s+)?fat[:\s]*` + labelNumber)},
+		{"carbs", regexp.MustCompile(`(?i)^(?:total\s+)?carb(?:ohydrate)?s?\.?[:\s]*` + labelNumber)},
+		{"protein", regexp.MustCompile(`(?i)^protein[:\s]*` + labelNumber)},
+	}
+)
+
+// ParseLabel reads the serving size, calories, and macros from the lines
+// of a US style nutrition label, such as:
+//
+//	Serving size 2/3 cup (55g)
+//	Calories 230
+//	Total Fat 8g
+//	Total Carbohydrate 37g
+//	Protein 3g
+func ParseLabel(text string) Label {
+	l := Label{Parsed: map[string]bool{}}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if m := labelServing.FindStringSubmatch(line); m != nil {
+			parseLabelServing(&l, strings.TrimSpace(m[1]))
+			continue
+		}
+		for _, f := range labelFields {
+			if l.Parsed[f.name] {
+				continue
+			}
+			m := f.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			n, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				continue
+			}
+			switch f.name {
+			case "calories":
+				l.Calories = n
+			case "fat":
+				l.Fat = n
+			case "carbs":
+				l.Carbs = n
+			case "protein":
+				l.Protein = n
+			}
+			l.Parsed[f.name] = true
+		}
+	}
+	return l
+}
+
+// parseLabelServing reads a serving size such as "2/3 cup (55g)" or
+// "30 g". The serving size is kept in grams or milliliters, and the
+// rest is kept as the household serving.
+func parseLabelServing(l *Label, s string) {
+	household := s
+	amount := s
+	if i := strings.Index(s, "("); i >= 0 {
+		household = strings.TrimSpace(s[:i])
+		amount = strings.Trim(s[i:], "() ")
+	}
+
+	m := labelMetric.FindStringSubmatch(amount)
+	if m == nil {
+		l.HouseholdServing = household
+		return
+	}
+	n, err := parseQuantity(m[1])
+	if err != nil || n <= 0 {
+		l.HouseholdServing = household
+		return
+	}
+	u, ok := units[strings.ToLower(m[2])]
+	if !ok {
+		l.HouseholdServing = household
+		return
+	}
+
+	l.ServingSize = n * u.factor
+	l.ServingUnit = u.base
+	if household != amount {
+		l.HouseholdServing = household
+	}
+	l.Parsed["serving"] = true
+}
+
+// Food returns the food described by the label, with its nutrients per
+// 100 serving units. Missing calories are calculated from the macros.
+func (l Label) Food(name string) Food {
+	per := PortionSize / l.ServingSize
+	f := Food{
+		Name:             name,
+		ServingSize:      l.ServingSize,
+		ServingUnit:      l.ServingUnit,
+		HouseholdServing: l.HouseholdServing,
+		NumberOfServings: 1,
+		FoodMacros: &FoodMacros{
+			Protein: l.Protein * per,
+			Fat:     l.Fat * per,
+			Carbs:   l.Carbs * per,
+		},
+	}
+	if l.Parsed["calories"] {
+		f.Calories = l.Calories * per
+	} else {
+		f.Calories = CalcCals(f.FoodMacros.Protein, f.FoodMacros.Carbs, f.FoodMacros.Fat)
+	}
+	return f
+}
+
+// promptLabelAmount prompts through r for a non-negative amount until
+// one is given.
+func promptLabelAmount(r *bufio.Reader, prompt string) (float64, error) {
+	for {
+		fmt.Print(prompt)
+		s, err := r.ReadString('\n')
+		if err != nil && s == "" {
+			return 0, fmt.Errorf("couldn't read amount: %v", err)
+		}
+		n, perr := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if perr != nil || n < 0 {
+			fmt.Println("Value must be a number of at least 0. Please try again.")
+			if err != nil {
+				return 0, fmt.Errorf("couldn't read amount: %v", err)
+			}
+			continue
+		}
+		return n, nil
+	}
+}
+
+// fillLabel prompts through r for the fields of the label that couldn't
+// be parsed.
+func fillLabel(r *bufio.Reader, l *Label) error {
+	var err error
+	if !l.Parsed["serving"] {
+		for l.ServingSize <= 0 {
+			if l.ServingSize, err = promptLabelAmount(r, "Enter the serving size: "); err != nil {
+				return err
+			}
+		}
+		fmt.Print("Enter serving unit: ")
+		s, _ := r.ReadString('\n')
+		l.ServingUnit = strings.TrimSpace(s)
+	}
+
+	amounts := []struct {
+		name, label string
+		v           *float64
+	}{
+		{"fat", "Fat", &l.Fat},
+		{"carbs", "Carbs", &l.Carbs},
+		{"protein", "Protein", &l.Protein},
+	}
+	for _, a := range amounts {
+		if l.Parsed[a.name] {
+			continue
+		}
+		prompt := fmt.Sprintf("Enter the amount of %s per serving: ", a.label)
+		if *a.v, err = promptLabelAmount(r, prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateFoodFromLabel creates a new food from a nutrition label pasted
+// through r and adds it into the database. Only the food name and the
+// fields that couldn't be read from the label are asked for.
+func CreateFoodFromLabel(db *sqlx.DB, r io.Reader) error {
+	br := bufio.NewReader(r)
+	fmt.Print("Enter the food name: ")
+	name, _ := br.ReadString('\n')
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("food name can't be empty")
+	}
+
+	fmt.Println("Paste the nutrition label, followed by a blank line:")
+	lines, err := readPaste(br)
+	if err != nil {
+		return err
+	}
+	l := ParseLabel(strings.Join(lines, "\n"))
+	if err := fillLabel(br, &l); err != nil {
+		return err
+	}
+	food := l.Food(name)
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	food.ID, err = InsertFood(tx, food)
+	if err != nil {
+		return err
+	}
+	if err := InsertNutrients(db, tx, food); err != nil {
+		return fmt.Errorf("failed to insert food nutrients into database: %v", err)
+	}
+
+	fmt.Printf("Added %s: %.0f cal, %sp %sc %sf per %g %s.\n", food.Name,
+		food.Calories*food.ServingSize/PortionSize,
+		FormatMacro(l.Protein), FormatMacro(l.Carbs), FormatMacro(l.Fat),
+		food.ServingSize, food.ServingUnit)
+
+	return tx.Commit()
+}
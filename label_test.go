@@ -0,0 +1,35 @@
+package bite
+
+import "fmt"
+
+func ExampleParseLabel() {
+	l := ParseLabel(`Nutrition Facts
+8 servings per container
+Serving size 2/3 cup (55g)
+Amount per serving
+Calories 230
+% Daily Value*
+Total Fat 8g 10%
+Saturated Fat 1g 5%
+Trans Fat 0g
+Sodium 160mg 7%
+Total Carbohydrate 37g 13%
+Dietary Fiber 4g 14%
+Protein 3g`)
+	fmt.Println(l.ServingSize, l.ServingUnit, l.HouseholdServing)
+	fmt.Println(l.Calories, l.Fat, l.Carbs, l.Protein)
+
+	f := l.Food("Granola")
+	fmt.Printf("%.1f %.1f %.1f %.1f\n", f.Calories, f.FoodMacros.Fat, f.FoodMacros.Carbs, f.FoodMacros.Protein)
+
+	// A serving size without an amount in a known unit and missing
+	// protein are left to be asked for.
+	l = ParseLabel("Serving size 1 bar\nCalories: 190\nFat 7g\nCarbs 24g")
+	fmt.Println(l.HouseholdServing, l.Parsed["serving"], l.Parsed["protein"])
+
+	// Output:
+	// 55 g 2/3 cup
+	// 230 8 37 3
+	// 418.2 14.5 67.3 5.5
+	// 1 bar false false
+}
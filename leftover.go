@@ -0,0 +1,202 @@
+package bite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// leftoverDays is the number of days cooked food keeps in the fridge.
+const leftoverDays = 4
+
+// Leftover is the remaining servings of a meal cooked in bulk.
+type Leftover struct {
+	ID         int       `db:"id"`
+	MealID     int       `db:"meal_id"`
+	MealName   string    `db:"meal_name"`
+	CookedDate time.Time `db:"cooked_date"`
+	Servings   float64   `db:"servings"`
+}
+
+// Expires returns the last day the leftover is good to eat.
+func (l Leftover) Expires() time.Time {
+	return dateOf(l.CookedDate).AddDate(0, 0, leftoverDays)
+}
+
+// ExpiryHint describes when the leftover expires relative to now.
+func (l Leftover) ExpiryHint(now time.Time) string {
+	days := int(l.Expires().Sub(dateOf(now)).Hours() / 24)
+	switch {
+	case days < 0:
+		return "expired, throw out"
+	case days == 0:
+		return "eat today"
+	case days == 1:
+		return "eat by tomorrow"
+	default:
+		return fmt.Sprintf("eat within %d days", days)
+	}
+}
+
+// AddLeftover records the servings of a meal left over after it was
+// cooked on the given date.
+func AddLeftover(tx *sqlx.Tx, mealID int, cooked time.Time, servings float64) error {
+	if servings <= 0 {
+		return nil
+	}
+	const query = `
+		INSERT INTO leftovers (meal_id, cooked_date, servings)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(query, mealID, cooked.Format(dateFormat), servings); err != nil {
+		return fmt.Errorf("couldn't add leftover: %v", err)
+	}
+	return nil
+}
+
+// Leftovers returns the leftovers with servings remaining, oldest
+// first.
+func Leftovers(q sqlx.Queryer) ([]Leftover, error) {
+	const query = `
+		SELECT l.id, l.meal_id, m.meal_name, l.cooked_date, l.servings
+		FROM leftovers l
+		INNER JOIN meals m ON m.meal_id = l.meal_id
+		WHERE l.servings > 0
+		ORDER BY l.cooked_date, l.id
+	`
+	var leftovers []Leftover
+	if err := sqlx.Select(q, &leftovers, query); err != nil {
+		return nil, fmt.Errorf("couldn't get leftovers: %v", err)
+	}
+	return leftovers, nil
+}
+
+// takeLeftover removes one serving from a leftover. The leftover is
+// deleted once no servings remain.
+func takeLeftover(tx *sqlx.Tx, l *Leftover) error {
+	l.Servings--
+	if l.Servings <= 0 {
+		l.Servings = 0
+		if _, err := tx.Exec(`DELETE FROM leftovers WHERE id = $1`, l.ID); err != nil {
+			return fmt.Errorf("couldn't delete leftover: %v", err)
+		}
+		return nil
+	}
+
+	if _, err := tx.Exec(`UPDATE leftovers SET servings = $1 WHERE id = $2`, l.Servings, l.ID); err != nil {
+		return fmt.Errorf("couldn't update leftover: %v", err)
+	}
+	return nil
+}
+
+// EatLeftover logs a serving of the leftover meal at the given time
+// and removes the serving from the leftover.
+func EatLeftover(db *sqlx.DB, tx *sqlx.Tx, l *Leftover, date time.Time) error {
+	mealFoods, err := MealFoodsWithPref(db, l.MealID)
+	if err != nil {
+		return err
+	}
+	if len(mealFoods) == 0 {
+		return fmt.Errorf("meal %q does not contain any foods", l.MealName)
+	}
+
+	if err := AddMealEntry(tx, l.MealID, date); err != nil {
+		return err
+	}
+	if err := AddMealFoodEntries(tx, l.MealID, mealFoods, 1, date); err != nil {
+		return err
+	}
+	return takeLeftover(tx, l)
+}
+
+// ShowLeftovers lists the leftovers in the fridge and lets the user log
+// a serving of one by entering its index, or throw one out by entering
+// "d" and its index.
+func ShowLeftovers(db *sqlx.DB) error {
+	reader := Input
+	for {
+		leftovers, err := Leftovers(db)
+		if err != nil {
+			return err
+		}
+		if len(leftovers) == 0 {
+			fmt.Println("No leftovers.")
+			return nil
+		}
+
+		now := FoodLogNow()
+		for i, l := range leftovers {
+			fmt.Printf("[%d] %s: %g servings, cooked %s (%s)\n", i+1, l.MealName, l.Servings,
+				l.CookedDate.Format(dateFormat), l.ExpiryHint(now))
+		}
+
+		fmt.Printf("Enter index to eat a serving, d<index> to throw out [Press <Enter> to quit]: ")
+		s, err := reader.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil
+		}
+
+		discard := strings.HasPrefix(s, "d")
+		idx, err := strconv.Atoi(strings.TrimPrefix(s, "d"))
+		if err != nil || idx < 1 || idx > len(leftovers) {
+			fmt.Println("Invalid index. Please try again.")
+			continue
+		}
+		l := &leftovers[idx-1]
+
+		if err := updateLeftover(db, l, discard, now); err != nil {
+			return err
+		}
+	}
+}
+
+// updateLeftover logs a serving of the leftover, or throws it out.
+func updateLeftover(db *sqlx.DB, l *Leftover, discard bool, now time.Time) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if discard {
+		if _, err := tx.Exec(`DELETE FROM leftovers WHERE id = $1`, l.ID); err != nil {
+			return fmt.Errorf("couldn't delete leftover: %v", err)
+		}
+		fmt.Printf("Threw out %s.\n", l.MealName)
+		return tx.Commit()
+	}
+
+	if err := EatLeftover(db, tx, l, now); err != nil {
+		return err
+	}
+	fmt.Printf("Logged a serving of %s. %g servings left.\n", l.MealName, l.Servings)
+	return tx.Commit()
+}
+
+// promptLeftoverServings prompts the user for the number of servings
+// left over from a meal cooked in bulk, validates their response until
+// they've entered a valid response, and returns the valid response.
+func promptLeftoverServings() float64 {
+	for {
+		var r string
+		fmt.Printf("Enter number of leftover servings [Press <Enter> for none]: ")
+		fmt.Fscanln(Input, &r)
+		if r == "" {
+			return 0
+		}
+
+		n, err := strconv.ParseFloat(r, 64)
+		if err != nil || n < 0 {
+			fmt.Println("Invalid number of servings. Please try again.")
+			continue
+		}
+		return n
+	}
+}
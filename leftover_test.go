@@ -0,0 +1,61 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleLeftovers() {
+	db := dbtest.MustNew(`INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Chili'), (2, 'Curry')`)
+	defer db.Close()
+
+	cooked := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	tx := db.MustBegin()
+	if err := AddLeftover(tx, 1, cooked, 2); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := AddLeftover(tx, 2, cooked.AddDate(0, 0, 2), 3); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Eat both servings of chili.
+	leftovers, err := Leftovers(tx)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if err := takeLeftover(tx, &leftovers[0]); err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	leftovers, err = Leftovers(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, l := range leftovers {
+		fmt.Println(l.MealName, l.Servings, l.Expires().Format(dateFormat))
+		for _, days := range []int{2, 5, 6, 7} {
+			fmt.Println(l.ExpiryHint(cooked.AddDate(0, 0, days)))
+		}
+	}
+
+	// Output:
+	// Curry 3 2024-01-07
+	// eat within 4 days
+	// eat by tomorrow
+	// eat today
+	// expired, throw out
+}
@@ -0,0 +1,135 @@
+package bite
+
+// localeES is Spanish.
+var localeES = locale{
+	decimalComma: true,
+	dateLayout:   "02/01/2006",
+	messages: map[string]string{
+		// Prompts.
+		"Please provide required information:":                              "Por favor, introduce la información necesaria:",
+		"Step 1: Your details.":                                             "Paso 1: Tus datos.",
+		"Set measurement system to:":                                        "Elige el sistema de medida:",
+		"1. Metric (kg/cm)":                                                 "1. Métrico (kg/cm)",
+		"2. Imperial (lbs/inches)":                                          "2. Imperial (lbs/pulgadas)",
+		"Type number and <Enter>: ":                                         "Escribe el número y pulsa <Enter>: ",
+		"Invalid option. Please try again.":                                 "Opción no válida. Inténtalo de nuevo.",
+		"Invalid action. Please try again.":                                 "Acción no válida. Inténtalo de nuevo.",
+		"Enter sex (male/female): ":                                         "Introduce tu sexo (male/female): ",
+		"Must enter \"male\" or \"female\". Please try again.":              "Debes introducir \"male\" o \"female\". Inténtalo de nuevo.",
+		"Enter weight (kgs): ":                                              "Introduce tu peso (kg): ",
+		"Enter weight (lbs): ":                                              "Introduce tu peso (lbs): ",
+		"Error reading weight: %v. Please try again.\n":                     "Error al leer el peso: %v. Inténtalo de nuevo.\n",
+		"Enter height (cm): ":                                               "Introduce tu altura (cm): ",
+		"Error reading height: %v. Please try again.\n":                     "Error al leer la altura: %v. Inténtalo de nuevo.\n",
+		"What is your height (feet portion)? ":                              "¿Cuál es tu altura (pies)? ",
+		"Error reading feet: %v. Please try again.\n":                       "Error al leer los pies: %v. Inténtalo de nuevo.\n",
+		"What is your height (inches portion)? ":                            "¿Cuál es tu altura (pulgadas)? ",
+		"Error reading inches: %v. Please try again.\n":                     "Error al leer las pulgadas: %v. Inténtalo de nuevo.\n",
+		"Enter age: ":                                                       "Introduce tu edad: ",
+		"Invalid age. Please try again.":                                    "Edad no válida. Inténtalo de nuevo.",
+		"Enter activity level (sedentary, light, moderate, active, very): ": "Introduce tu nivel de actividad (sedentary, light, moderate, active, very): ",
+		"Invalid activity level. Please try again.":                         "Nivel de actividad no válido. Inténtalo de nuevo.",
+		"Estimate BMR with:":                                                "Estimar la TMB con:",
+		"3. Katch-McArdle (requires body fat %)":                            "3. Katch-McArdle (requiere el % de grasa corporal)",
+		"4. Cunningham (requires body fat %)":                               "4. Cunningham (requiere el % de grasa corporal)",
+		"Enter body fat (%): ":                                              "Introduce tu grasa corporal (%): ",
+		"Error reading body fat: %v. Please try again.\n":                   "Error al leer la grasa corporal: %v. Inténtalo de nuevo.\n",
+		"Body fat must be between 0 and 100. Please try again.":             "La grasa corporal debe estar entre 0 y 100. Inténtalo de nuevo.",
+		"Update your information.":                                          "Actualiza tu información.",
+		"Updated information:":                                              "Información actualizada:",
+		"Rate the past week from 1 (worst) to 5 (best).":                    "Valora la última semana de 1 (peor) a 5 (mejor).",
+		"Hunger (1 = very hungry, 5 = not hungry)":                          "Hambre (1 = mucha hambre, 5 = nada de hambre)",
+		"Energy":          "Energía",
+		"Sleep quality":   "Calidad del sueño",
+		"Gym performance": "Rendimiento en el gimnasio",
+		"%s (1-5): ":      "%s (1-5): ",
+		"Rating must be a number from 1 to 5. Please try again.": "La valoración debe ser un número del 1 al 5. Inténtalo de nuevo.",
+		"Notes (optional): ": "Notas (opcional): ",
+		"Taking a diet break, 1-2 weeks of eating at maintenance, is recommended before continuing the cut.":                                 "Se recomienda un descanso de la dieta, 1-2 semanas comiendo en mantenimiento, antes de continuar la definición.",
+		"Your hunger or energy has been low for %d weeks in a row (hunger %d/5, energy %d/5, sleep %d/5, gym performance %d/5 this week).\n": "Tu hambre o energía han estado bajas %d semanas seguidas (hambre %d/5, energía %d/5, sueño %d/5, rendimiento en el gimnasio %d/5 esta semana).\n",
+
+		// User information.
+		"User Information:":           "Información del usuario:",
+		"Measurement System: %s\n":    "Sistema de medida: %s\n",
+		"Sex: %s\n":                   "Sexo: %s\n",
+		"Weight: %s kg\n":             "Peso: %s kg\n",
+		"Weight: %s lbs\n":            "Peso: %s lbs\n",
+		"Height: %s cm\n":             "Altura: %s cm\n",
+		"Height: %d' %s\"\n":          "Altura: %d' %s\"\n",
+		"Invalid measurement system.": "Sistema de medida no válido.",
+		"Age: %d\n":                   "Edad: %d\n",
+		"Activity Level: %s\n":        "Nivel de actividad: %s\n",
+		"BMR Formula: %s\n":           "Fórmula de la TMB: %s\n",
+		"Body Fat: %s%%\n":            "Grasa corporal: %s%%\n",
+		"TDEE: %s\n":                  "GET: %s\n",
+		"Conditions: %s\n":            "Condiciones: %s\n",
+
+		// Summaries.
+		"No foods logged for today.":        "No hay alimentos registrados hoy.",
+		"Protein":                           "Proteína",
+		"Fat":                               "Grasa",
+		"Carbs":                             "Hidratos",
+		"Calories":                          "Calorías",
+		"Macros:":                           "Macros:",
+		"%s calories remaining.":            "Quedan %s calorías.",
+		"Eaten $%s worth of food today.":    "Hoy has comido $%s en comida.",
+		"Caffeine: %s mg.":                  "Cafeína: %s mg.",
+		"Fiber":                             "Fibra",
+		"Sugar":                             "Azúcar",
+		"Day Summary for %s":                "Resumen del día %s",
+		"Current Weight: %s\n":              "Peso actual: %s\n",
+		"Calories Consumed: ":               "Calorías consumidas: ",
+		"Calorie Goal: %s (training day)\n": "Objetivo de calorías: %s (día de entrenamiento)\n",
+		"Calorie Goal: %s (rest day)\n":     "Objetivo de calorías: %s (día de descanso)\n",
+		"Macros: %s\n":                      "Macros: %s\n",
+		"Week Summary":                      "Resumen de la semana",
+		"Month Summary":                     "Resumen del mes",
+		"P %.0f%% / C %.0f%% / F %.0f%% of calories": "P %.0f%% / H %.0f%% / G %.0f%% de las calorías",
+		"Macros (P/C/F): %s\n":                       "Macros (P/H/G): %s\n",
+		"Fats a day: %s\n":                           "Grasas al día: %s\n",
+		"Diet Phase Info:":                           "Información de la fase:",
+		"Diet phase: %s\n":                           "Fase de la dieta: %s\n",
+		"Start Date: %s\n":                           "Fecha de inicio: %s\n",
+		"End Date: %s\n":                             "Fecha de fin: %s\n",
+		"Duration: %s weeks\n":                       "Duración: %s semanas\n",
+		"Remaining time: %d days\n":                  "Tiempo restante: %d días\n",
+		"Goal Weight: %s\n":                          "Peso objetivo: %s\n",
+		"You've reached your goal weight.":           "Has alcanzado tu peso objetivo.",
+		"Start Weight: %s\n":                         "Peso inicial: %s\n",
+		"Target: %s lbs by %s (%s lbs per week)\n":   "Objetivo: %s lbs para el %s (%s lbs por semana)\n",
+		"On a diet break until %s\n":                 "En descanso de la dieta hasta el %s\n",
+		"Training Day Calories: %s\n":                "Calorías en día de entrenamiento: %s\n",
+		"Rest Day Calories: %s\n":                    "Calorías en día de descanso: %s\n",
+		"Fiber is %sg short of the %sg minimum.":     "Faltan %sg de fibra para el mínimo de %sg.",
+		"Sugar is %sg over the %sg maximum.":         "El azúcar supera en %sg el máximo de %sg.",
+		"Sodium: %s mg. Potassium: %s mg.":           "Sodio: %s mg. Potasio: %s mg.",
+		"low":                                        "baja",
+		"medium":                                     "media",
+		"high":                                       "alta",
+		"Missing entry for today. Please create today's entry prior to attempting to generate today's diet summary.":             "Falta el registro de hoy. Crea el registro de hoy antes de generar el resumen del día.",
+		"Missing entries for this week. Please create today's entry prior to attempting to generate this week's diet summary.":   "Faltan registros de esta semana. Crea el registro de hoy antes de generar el resumen de la semana.",
+		"Missing entries for this month. Please create today's entry prior to attempting to generate this month's diet summary.": "Faltan registros de este mes. Crea el registro de hoy antes de generar el resumen del mes.",
+		"There has yet to be a logged day for this diet phase. Skipping diet day summary.":                                       "Aún no hay ningún día registrado en esta fase. Se omite el resumen del día.",
+		"There has yet to be a logged week for this diet phase. Skipping diet week summary.":                                     "Aún no hay ninguna semana registrada en esta fase. Se omite el resumen de la semana.",
+		"There has yet to be a logged month for this diet phase. Skipping diet month summary.":                                   "Aún no hay ningún mes registrado en esta fase. Se omite el resumen del mes.",
+		"Warning: %s mg of caffeine after %s can disturb sleep and hide weight trends.":                                          "Aviso: %s mg de cafeína después de las %s pueden alterar el sueño y ocultar la tendencia del peso.",
+		"Saturated fat was over the %sg limit on %d of %d days.\n":                                                               "La grasa saturada superó el límite de %sg en %d de %d días.\n",
+		"Glycemic load: %s (%s), from %s%% of the day's carbs.":                                                                  "Carga glucémica: %s (%s), del %s%% de los hidratos del día.",
+		"Weight is up %s from the day before after %s mg of sodium, likely water.":                                               "El peso ha subido %s desde el día anterior tras %s mg de sodio, probablemente agua.",
+		"Spent $%s of the $%s weekly food budget, $%s left.":                                                                     "Gastado $%s del presupuesto semanal de comida de $%s, quedan $%s.",
+		"Spent $%s on food this week, $%s over the $%s weekly budget.":                                                           "Gastado $%s en comida esta semana, $%s por encima del presupuesto semanal de $%s.",
+		"The budget is tight, so cheaper foods are suggested first.":                                                             "El presupuesto va justo, así que se sugieren primero los alimentos más baratos.",
+		"Spent $%s of the $%s weekly food budget.\n":                                                                             "Gastado $%s del presupuesto semanal de comida de $%s.\n",
+		"Spent $%s on food, $%s over the $%s weekly budget.\n":                                                                   "Gastado $%s en comida, $%s por encima del presupuesto semanal de $%s.\n",
+		"Water retention from the menstrual cycle is expected, so this week is left out of progress checks.":                     "Se espera retención de líquidos por el ciclo menstrual, así que esta semana no cuenta en la revisión del progreso.",
+
+		// Weekdays.
+		"Monday":    "Lunes",
+		"Tuesday":   "Martes",
+		"Wednesday": "Miércoles",
+		"Thursday":  "Jueves",
+		"Friday":    "Viernes",
+		"Saturday":  "Sábado",
+		"Sunday":    "Domingo",
+	},
+}
@@ -0,0 +1,104 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// BusyTimeout is how long a command waits for another one to finish
+// with the database before giving up.
+var BusyTimeout = 5 * time.Second
+
+// ErrLocked is returned when the database stays locked by another
+// command for longer than BusyTimeout.
+var ErrLocked = errors.New("database is in use by another bite command")
+
+// lockPoll is how often a held lock file is tried again.
+const lockPoll = 50 * time.Millisecond
+
+// Open connects to the plaintext database at path so that several
+// commands can use it at once. The database is put in WAL mode, so
+// reads don't wait for writes. Transactions take the write lock when
+// they begin, rather than when they first write, and wait up to
+// BusyTimeout for it, so two commands writing at once take turns
+// instead of one failing part way through.
+//
+// Foreign keys are enforced, so deleting a food or meal carries out the
+// ON DELETE actions of the rows that refer to it, unless the schema or
+// the rows break them. Databases made from an older setup.sql refer to
+// tables and columns that don't exist, so enforcing them would make
+// writes fail; those rely on the triggers db vacuum adds instead.
+func Open(path string) (*sqlx.DB, error) {
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		path, BusyTimeout.Milliseconds())
+	db, err := sqlx.Connect("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := foreignKeysHold(db)
+	if err != nil || !ok {
+		return db, err
+	}
+	db.Close()
+	return sqlx.Connect("sqlite", dsn+"&_pragma=foreign_keys(1)")
+}
+
+// foreignKeysHold reports whether the foreign keys of the database can
+// be enforced: every one refers to a table that exists by its primary
+// key or a unique index, and no row breaks one.
+func foreignKeysHold(db *sqlx.DB) (bool, error) {
+	const query = `
+		SELECT COUNT(*)
+		FROM sqlite_master AS m, pragma_foreign_key_list(m.name) AS f
+		WHERE m.type = 'table'
+			AND f."table" NOT IN (SELECT name FROM sqlite_master WHERE type = 'table')
+	`
+	var missing int
+	if err := db.Get(&missing, query); err != nil {
+		return false, fmt.Errorf("couldn't read foreign keys: %v", err)
+	}
+	if missing > 0 {
+		return false, nil
+	}
+	// The check fails when a foreign key refers to columns that aren't
+	// a key of their table.
+	rows, err := db.Query(`PRAGMA foreign_key_check`)
+	if err != nil {
+		return false, nil
+	}
+	defer rows.Close()
+	return !rows.Next() && rows.Err() == nil, nil
+}
+
+// Lock takes the lock file of the database at path, waiting up to
+// BusyTimeout for it. Any number of commands can hold a shared lock,
+// but an exclusive one is only granted when no other command holds the
+// lock. Commands that replace or restructure the whole database, such
+// as encrypting it or migrating its schema, take it exclusively. The
+// returned function releases the lock, which is also released if the
+// command exits without calling it.
+func Lock(path string, exclusive bool) (func() error, error) {
+	return lockFile(path+".lock", exclusive)
+}
+
+// checkpoint copies the write-ahead log of the database at path into
+// the database file and takes it out of WAL mode, so that the file
+// holds the whole database.
+func checkpoint(path string) error {
+	db, err := sqlx.Connect("sqlite", fmt.Sprintf("%s?_pragma=busy_timeout(%d)", path, BusyTimeout.Milliseconds()))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("couldn't checkpoint database: %v", err)
+	}
+	if _, err := db.Exec(`PRAGMA journal_mode=DELETE`); err != nil {
+		return fmt.Errorf("couldn't leave WAL mode: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package bite
+
+// lockFile doesn't lock anything on systems without flock. Commands
+// still take turns writing through SQLite's own locking.
+func lockFile(path string, exclusive bool) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,159 @@
+//go:build unix
+
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleOpen() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bite.db")
+
+	defer func(d time.Duration) { BusyTimeout = d }(BusyTimeout)
+	BusyTimeout = 100 * time.Millisecond
+
+	if err := dbtest.Create(path); err != nil {
+		log.Println(err)
+		return
+	}
+	db, err := Open(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	var mode string
+	if err := db.Get(&mode, `PRAGMA journal_mode`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println("Journal mode:", mode)
+
+	// A second command can't start writing until the first is done.
+	other, err := Open(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	tx := db.MustBegin()
+	tx.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-01-01', '07:00:00', 180)`)
+	_, err = other.Beginx()
+	fmt.Println("Busy:", err != nil)
+	tx.Commit()
+
+	otx, err := other.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	otx.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-01-02', '07:00:00', 179.4)`)
+	otx.Commit()
+	other.Close()
+	db.Close()
+
+	// Shared locks don't keep each other out, but they keep out an
+	// exclusive one.
+	unlockA, err := Lock(path, false)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	unlockB, err := Lock(path, false)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	_, err = Lock(path, true)
+	fmt.Println("Locked:", errors.Is(err, ErrLocked))
+	unlockA()
+	unlockB()
+	unlock, err := Lock(path, true)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer unlock()
+
+	// The encrypted file holds everything written in WAL mode.
+	k := DeriveKey([]byte("hunter2"), make([]byte, saltSize))
+	if err := EncryptFile(path, k); err != nil {
+		log.Println(err)
+		return
+	}
+	db, err = OpenEncrypted(path, k)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer db.Close()
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM daily_weights`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println("Weights:", n)
+
+	// Output:
+	// Journal mode: wal
+	// Busy: true
+	// Locked: true
+	// Weights: 2
+}
+
+func ExampleOpen_foreignKeys() {
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "bite.db")
+	err = dbtest.Create(path, `
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Bowl');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1), (1, 2);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES (1, '2024-01-01', '08:00:00', 40, 1, 156, 5, 3, 27);
+	`)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer db.Close()
+	// Leave the ON DELETE actions to do the work alone.
+	db.MustExec(`DROP TRIGGER foods_delete`)
+	db.MustExec(`DELETE FROM foods WHERE food_id = 1`)
+	var n struct {
+		DailyFoods int `db:"daily_foods"`
+		MealFoods  int `db:"meal_foods"`
+	}
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM daily_foods WHERE food_id = 1) AS daily_foods,
+			(SELECT COUNT(*) FROM meal_foods WHERE food_id = 1) AS meal_foods
+	`
+	if err := db.Get(&n, query); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(n.DailyFoods, n.MealFoods)
+	// Output:
+	// 0 0
+}
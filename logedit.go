@@ -0,0 +1,375 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// EditLine is an entry of an edited food log: an existing entry, or a
+// new one when ID is zero.
+type EditLine struct {
+	Num         int // Line number in the buffer.
+	ID          int
+	Time        string // "15:04".
+	Servings    float64
+	ServingSize float64
+	Unit        string // Empty when the unit was left out.
+	Food        string
+}
+
+// EditResult is the number of food entries an edit changed.
+type EditResult struct {
+	Updated, Added, Deleted int
+}
+
+// String returns the counts as a sentence.
+func (r EditResult) String() string {
+	return fmt.Sprintf("Updated %d, added %d, and deleted %d food entries.", r.Updated, r.Added, r.Deleted)
+}
+
+// editChange is a change to apply to the food log.
+type editChange struct {
+	line  EditLine
+	entry *DailyFood // Nil for a new entry.
+	food  *Food      // Nil when the entry keeps its food.
+}
+
+// formatFoodLogEdit returns the buffer the food log of the date is
+// edited in: a comment explaining the format followed by a line for
+// each entry.
+func formatFoodLogEdit(date time.Time, entries []DailyFood) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Food log for %s.\n", date.Format(dateFormat))
+	b.WriteString("# Change the time, servings, or food of an entry, delete its line to\n")
+	b.WriteString("# delete it, or add a line without an id to log another food.\n")
+	b.WriteString("# Lines starting with # are ignored.\n")
+	b.WriteString("#\n")
+	b.WriteString("# id | time | servings x size unit | food\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%d | %s | %s | %s\n", e.ID, entryClock(e.Time), formatEditServings(e.NumberOfServings, e.ServingSize, e.ServingUnit), e.FoodName)
+	}
+	return b.String()
+}
+
+// formatEditServings formats the servings of an entry as they are
+// edited, such as "1.5 x 40 g".
+func formatEditServings(n, size float64, unit string) string {
+	return fmt.Sprintf("%g x %g %s", roundEdit(n), roundEdit(size), unit)
+}
+
+// roundEdit rounds a number to the 2 decimals it is edited with.
+func roundEdit(n float64) float64 {
+	return math.Round(n*100) / 100
+}
+
+// entryClock returns the hours and minutes of an entry's time of day,
+// or the time as it is if it can't be parsed.
+func entryClock(s string) string {
+	t, err := time.Parse(dateFormatTime, s)
+	if err != nil {
+		return s
+	}
+	return t.Format("15:04")
+}
+
+// ParseFoodLogEdit reads the lines of an edited food log, skipping
+// blank lines and comments.
+func ParseFoodLogEdit(r io.Reader) ([]EditLine, error) {
+	var lines []EditLine
+	sc := bufio.NewScanner(r)
+	for num := 1; sc.Scan(); num++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		l, err := parseEditLine(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", num, err)
+		}
+		l.Num = num
+		lines = append(lines, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read edited food log: %v", err)
+	}
+	return lines, nil
+}
+
+// parseEditLine parses a line in "id | time | servings x size unit |
+// food" form. The id is left out of new entries.
+func parseEditLine(s string) (EditLine, error) {
+	var l EditLine
+	fields := strings.SplitN(s, "|", 4)
+	if len(fields) == 3 {
+		fields = append([]string{""}, fields...)
+	}
+	if len(fields) != 4 {
+		return l, errors.New(`want "id | time | servings x size unit | food"`)
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	if fields[0] != "" {
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id < 1 {
+			return l, fmt.Errorf("invalid id %q", fields[0])
+		}
+		l.ID = id
+	}
+	t, err := time.Parse("15:04", fields[1])
+	if err != nil {
+		return l, fmt.Errorf("invalid time %q: want HH:MM", fields[1])
+	}
+	l.Time = t.Format("15:04")
+	if l.Servings, l.ServingSize, l.Unit, err = parseEditServings(fields[2]); err != nil {
+		return l, err
+	}
+	if fields[3] == "" {
+		return l, errors.New("missing food")
+	}
+	l.Food = fields[3]
+	return l, nil
+}
+
+// parseEditServings parses servings in "servings x size unit" form, or
+// "size unit" for a single serving. The unit may be left out.
+func parseEditServings(s string) (n, size float64, unit string, err error) {
+	fields := strings.Fields(s)
+	n = 1
+	if len(fields) >= 3 && fields[1] == "x" {
+		if n, err = strconv.ParseFloat(fields[0], 64); err != nil || n <= 0 {
+			return 0, 0, "", fmt.Errorf("invalid number of servings %q", fields[0])
+		}
+		fields = fields[2:]
+	}
+	if len(fields) == 0 {
+		return 0, 0, "", errors.New("missing servings")
+	}
+	if size, err = strconv.ParseFloat(fields[0], 64); err != nil || size <= 0 {
+		return 0, 0, "", fmt.Errorf("invalid serving size %q", fields[0])
+	}
+	return n, size, strings.Join(fields[1:], " "), nil
+}
+
+// servingSizeIn returns the serving size of the line in the food's
+// serving unit.
+func (l EditLine) servingSizeIn(unit string) (float64, error) {
+	if l.Unit == "" || strings.EqualFold(l.Unit, unit) {
+		return l.ServingSize, nil
+	}
+	size, err := convertUnit(l.ServingSize, l.Unit, unit)
+	if err != nil {
+		return 0, fmt.Errorf("line %d: couldn't measure in %s: %v", l.Num, unit, err)
+	}
+	return size, nil
+}
+
+// planFoodLogEdit compares the edited lines to the entries they were
+// made from and returns the changes to make, matching the foods of new
+// lines and of lines whose food was renamed the way pasted foods are
+// matched, and the entries whose lines were deleted.
+func planFoodLogEdit(db *sqlx.DB, entries []DailyFood, lines []EditLine) ([]editChange, []DailyFood, error) {
+	byID := make(map[int]*DailyFood, len(entries))
+	for i := range entries {
+		byID[entries[i].ID] = &entries[i]
+	}
+
+	seen := make(map[int]bool)
+	var changes []editChange
+	for _, l := range lines {
+		c := editChange{line: l}
+		if l.ID != 0 {
+			e, ok := byID[l.ID]
+			if !ok {
+				return nil, nil, fmt.Errorf("line %d: no entry %d on this day", l.Num, l.ID)
+			}
+			if seen[l.ID] {
+				return nil, nil, fmt.Errorf("line %d: entry %d is listed twice", l.Num, l.ID)
+			}
+			seen[l.ID] = true
+			c.entry = e
+			if strings.EqualFold(l.Food, e.FoodName) {
+				size, err := l.servingSizeIn(e.ServingUnit)
+				if err != nil {
+					return nil, nil, err
+				}
+				if l.Time == entryClock(e.Time) && l.Servings == roundEdit(e.NumberOfServings) && roundEdit(size) == roundEdit(e.ServingSize) {
+					continue
+				}
+				changes = append(changes, c)
+				continue
+			}
+		}
+
+		name := strings.ToLower(l.Food)
+		foods, err := searchQuickFood(db, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(foods) == 0 {
+			return nil, nil, fmt.Errorf("line %d: no foods match %q", l.Num, l.Food)
+		}
+		f, ok := matchFood(foods, name)
+		if !ok {
+			return nil, nil, fmt.Errorf("line %d: %q matches %s; name one of them", l.Num, l.Food, editCandidates(foods))
+		}
+		size, err := l.servingSizeIn(f.ServingUnit)
+		if err != nil {
+			return nil, nil, err
+		}
+		ScaleServings(&f, size, l.Servings)
+		c.food = &f
+		changes = append(changes, c)
+	}
+
+	var deleted []DailyFood
+	for _, e := range entries {
+		if !seen[e.ID] {
+			deleted = append(deleted, e)
+		}
+	}
+	return changes, deleted, nil
+}
+
+// maxEditCandidates is the most foods listed when the food of a line
+// matches several.
+const maxEditCandidates = 5
+
+// editCandidates lists the names of the foods a line matched, up to
+// maxEditCandidates of them.
+func editCandidates(foods []Food) string {
+	var names []string
+	for i, f := range foods {
+		if i == maxEditCandidates {
+			names = append(names, fmt.Sprintf("%d more", len(foods)-i))
+			break
+		}
+		names = append(names, fmt.Sprintf("%q", f.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// applyFoodLogEdit makes the changes to the food log of the date and
+// deletes the entries whose lines were deleted.
+func applyFoodLogEdit(tx *sqlx.Tx, date time.Time, changes []editChange, deleted []DailyFood) (EditResult, error) {
+	var r EditResult
+	for _, e := range deleted {
+		if err := DeleteOneFoodEntry(tx, e.ID); err != nil {
+			return r, fmt.Errorf("couldn't delete food entry: %v", err)
+		}
+		r.Deleted++
+	}
+
+	for _, c := range changes {
+		t, err := time.Parse("15:04", c.line.Time)
+		if err != nil {
+			return r, err
+		}
+		at := time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+
+		if c.entry == nil {
+			if err := AddFoodEntry(tx, c.food, at); err != nil {
+				return r, fmt.Errorf("couldn't add food entry for %q: %v", c.food.Name, err)
+			}
+			r.Added++
+			continue
+		}
+
+		if c.food != nil {
+			const query = `UPDATE daily_foods SET food_id = $1 WHERE id = $2`
+			if _, err := tx.Exec(query, c.food.ID, c.entry.ID); err != nil {
+				return r, fmt.Errorf("couldn't update food entry: %v", err)
+			}
+			if err := updateFoodEntry(tx, c.entry.ID, *c.food); err != nil {
+				return r, fmt.Errorf("couldn't update food entry: %v", err)
+			}
+		} else {
+			size, err := c.line.servingSizeIn(c.entry.ServingUnit)
+			if err != nil {
+				return r, err
+			}
+			if c.line.Servings != roundEdit(c.entry.NumberOfServings) || roundEdit(size) != roundEdit(c.entry.ServingSize) {
+				if err := UpdateFoodEntryServings(tx, c.entry, size, c.line.Servings); err != nil {
+					return r, err
+				}
+			}
+		}
+		if c.line.Time != entryClock(c.entry.Time) {
+			const query = `UPDATE daily_foods SET time = $1 WHERE id = $2`
+			if _, err := tx.Exec(query, at.Format(dateFormatTime), c.entry.ID); err != nil {
+				return r, fmt.Errorf("couldn't update food entry time: %v", err)
+			}
+		}
+		r.Updated++
+	}
+	return r, nil
+}
+
+// EditFoodLog lets the user edit the food log of the date all at once.
+// The day's entries are written to a buffer, one per line, and edit is
+// called to change it, such as in the user's $EDITOR. The edited
+// entries are then updated, added, and deleted in one transaction. If
+// the edited buffer can't be applied, the user can edit it again.
+func EditFoodLog(db *sqlx.DB, date time.Time, edit func(string) (string, error)) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	entries, err := FoodEntriesForDate(tx, date)
+	tx.Rollback()
+	if err != nil {
+		return err
+	}
+
+	buf := formatFoodLogEdit(date, entries)
+	var changes []editChange
+	var deleted []DailyFood
+	for {
+		if buf, err = edit(buf); err != nil {
+			return err
+		}
+		lines, err := ParseFoodLogEdit(strings.NewReader(buf))
+		if err == nil {
+			changes, deleted, err = planFoodLogEdit(db, entries, lines)
+		}
+		if err == nil {
+			break
+		}
+
+		fmt.Println(err)
+		fmt.Printf("Edit again? (y/n): ")
+		s, _ := Input.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(s)); a != "y" && a != "yes" {
+			fmt.Println("Nothing updated.")
+			return nil
+		}
+	}
+	if len(changes) == 0 && len(deleted) == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+
+	tx, err = db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	r, err := applyFoodLogEdit(tx, date, changes, deleted)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Println(r)
+	return nil
+}
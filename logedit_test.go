@@ -0,0 +1,109 @@
+package bite
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleEditFoodLog() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_foods (id, food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price)
+		VALUES
+			(1, 1, '2024-05-02', '08:00:00', 40, 1, 155.6, 5.2, 2.8, 27.2, 0.5),
+			(2, 2, '2024-05-02', '12:00:00', 100, 1, 165, 31, 3.6, 0, 0),
+			(3, 3, '2024-05-02', '15:00:00', 118, 1, 105, 1.3, 0.4, 27.1, 0);
+	`)
+	defer db.Close()
+
+	date := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	edit := func(buf string) (string, error) {
+		fmt.Print(buf)
+		// Have more oats, swap lunch for a banana at 12:30, drop the
+		// afternoon banana, and add chicken for dinner.
+		buf = strings.Replace(buf, "1 | 08:00 | 1 x 40 g", "1 | 08:00 | 1.5 x 40 g", 1)
+		buf = strings.Replace(buf, "2 | 12:00 | 1 x 100 g | Chicken breast", "2 | 12:30 | 1 x 118 g | banana", 1)
+		buf = strings.Replace(buf, "3 | 15:00 | 1 x 118 g | Banana\n", "", 1)
+		return buf + "| 19:00 | 150 g | chicken breast\n", nil
+	}
+	if err := EditFoodLog(db, date, edit); err != nil {
+		log.Fatal(err)
+	}
+
+	var entries []struct {
+		ID       int     `db:"id"`
+		FoodID   int     `db:"food_id"`
+		Time     string  `db:"time"`
+		Servings float64 `db:"number_of_servings"`
+		Size     float64 `db:"serving_size"`
+		Calories float64 `db:"calories"`
+	}
+	const query = `
+		SELECT id, food_id, time, number_of_servings, serving_size, calories
+		FROM daily_foods ORDER BY time
+	`
+	if err := db.Select(&entries, query); err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		fmt.Printf("%d %d %s %g x %g %.0f\n", e.ID, e.FoodID, e.Time, e.Servings, e.Size, e.Calories)
+	}
+	// Output:
+	// # Food log for 2024-05-02.
+	// # Change the time, servings, or food of an entry, delete its line to
+	// # delete it, or add a line without an id to log another food.
+	// # Lines starting with # are ignored.
+	// #
+	// # id | time | servings x size unit | food
+	// 1 | 08:00 | 1 x 40 g | Oats
+	// 2 | 12:00 | 1 x 100 g | Chicken breast
+	// 3 | 15:00 | 1 x 118 g | Banana
+	// Updated 2, added 1, and deleted 1 food entries.
+	// 1 1 08:00:00 1.5 x 40 233
+	// 2 3 12:30:00 1 x 118 105
+	// 3 2 19:00:00 1 x 150 248
+}
+
+func ExampleEditFoodLog_ambiguous() {
+	db := dbtest.MustNew(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(4, 'Rye bread', 32, 'g', '1 slice'),
+			(5, 'Banana bread', 60, 'g', '1 slice'),
+			(6, 'Rice | beans', 200, 'g', '1 cup');
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(4, 1008, 259, 71), (5, 1008, 326, 71), (6, 1008, 125, 71);
+		INSERT INTO foods_fts (food_id, food_name, brand_name)
+			SELECT food_id, food_name, brand_name FROM foods WHERE food_id > 3;
+		INSERT INTO daily_foods (id, food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES
+			(1, 1, '2024-05-02', '08:00:00', 40, 1, 155.6, 5.2, 2.8, 27.2),
+			(2, 6, '2024-05-02', '12:00:00', 200, 1, 250, 9, 1, 50);
+	`)
+	defer db.Close()
+	defer func(r *bufio.Reader) { Input = r }(Input)
+	Input = bufio.NewReader(strings.NewReader("y\n"))
+
+	// Swap the oats for bread, which matches two foods, then for rye
+	// bread once asked to name one.
+	edits := strings.NewReplacer("| Oats\n", "| bread\n", "| bread\n", "| rye bread\n")
+	edit := func(buf string) (string, error) {
+		return edits.Replace(buf), nil
+	}
+	if err := EditFoodLog(db, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), edit); err != nil {
+		log.Fatal(err)
+	}
+
+	var foods []int
+	if err := db.Select(&foods, `SELECT food_id FROM daily_foods ORDER BY id`); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(foods)
+	// Output:
+	// line 7: "bread" matches "Rye bread", "Banana bread"; name one of them
+	// Edit again? (y/n): Updated 1, added 0, and deleted 0 food entries.
+	// [4 6]
+}
@@ -0,0 +1,53 @@
+package bite
+
+import "math"
+
+// macroStep is a macro that a change in calories is applied to, up to
+// its bound.
+type macroStep struct {
+	grams       *float64
+	bound       float64 // Minimum when removing, maximum when adding.
+	calsPerGram float64
+}
+
+// AdjustMacros applies a change in daily calories to the macros, keeping
+// each within its minimum and maximum. Calories are removed from fats
+// first, then carbs, and finally protein, and are added to carbs first,
+// then fats, and finally protein. It returns the new macros and the
+// calories that couldn't be applied because every macro reached its
+// bound, which have the same sign as delta.
+func AdjustMacros(m Macros, delta float64) (Macros, float64) {
+	var steps []macroStep
+	switch {
+	case delta < 0:
+		steps = []macroStep{
+			{&m.Fats, m.MinFats, calsInFats},
+			{&m.Carbs, m.MinCarbs, calsInCarbs},
+			{&m.Protein, m.MinProtein, calsInProtein},
+		}
+	case delta > 0:
+		steps = []macroStep{
+			{&m.Carbs, m.MaxCarbs, calsInCarbs},
+			{&m.Fats, m.MaxFats, calsInFats},
+			{&m.Protein, m.MaxProtein, calsInProtein},
+		}
+	}
+
+	remaining := delta
+	for _, s := range steps {
+		// Calories the macro can take before reaching its bound.
+		room := (s.bound - *s.grams) * s.calsPerGram
+		var applied float64
+		if delta < 0 {
+			applied = math.Max(remaining, math.Min(room, 0))
+		} else {
+			applied = math.Min(remaining, math.Max(room, 0))
+		}
+		*s.grams += applied / s.calsPerGram
+		remaining -= applied
+		if remaining == 0 {
+			break
+		}
+	}
+	return m, remaining
+}
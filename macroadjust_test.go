@@ -0,0 +1,38 @@
+package bite
+
+import "fmt"
+
+func ExampleAdjustMacros() {
+	m := Macros{
+		Protein: 150, MinProtein: 140, MaxProtein: 200,
+		Carbs: 250, MinCarbs: 200, MaxCarbs: 300,
+		Fats: 70, MinFats: 60, MaxFats: 80,
+	}
+
+	// 90 calories are 10g of fat.
+	got, left := AdjustMacros(m, -90)
+	fmt.Println(got.Protein, got.Carbs, got.Fats, left)
+
+	// Past the fat minimum, the rest comes from carbs, 4 calories a gram.
+	got, left = AdjustMacros(m, -290)
+	fmt.Println(got.Protein, got.Carbs, got.Fats, left)
+
+	// Every minimum is reached with 320 calories left over.
+	got, left = AdjustMacros(m, -650)
+	fmt.Println(got.Protein, got.Carbs, got.Fats, left)
+
+	// Surpluses go to carbs first, then fats.
+	got, left = AdjustMacros(m, 290)
+	fmt.Println(got.Protein, got.Carbs, got.Fats, left)
+
+	// Every maximum is reached with 140 calories left over.
+	got, left = AdjustMacros(m, 630)
+	fmt.Println(got.Protein, got.Carbs, got.Fats, left)
+
+	// Output:
+	// 150 250 60 0
+	// 150 200 60 0
+	// 140 200 60 -320
+	// 150 300 80 0
+	// 200 300 80 140
+}
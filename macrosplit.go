@@ -0,0 +1,67 @@
+package bite
+
+import "fmt"
+
+// proteinShortfall is the fraction of a week's logged days that have to
+// fall short of the protein target for it to be flagged.
+const proteinShortfall = 0.5
+
+// MacroSplit returns the percentage of the calories from macros that
+// came from protein, carbs, and fat.
+func MacroSplit(protein, carbs, fat float64) (p, c, f float64) {
+	p = protein * calsInProtein
+	c = carbs * calsInCarbs
+	f = fat * calsInFats
+	total := p + c + f
+	if total == 0 {
+		return 0, 0, 0
+	}
+	return p * 100 / total, c * 100 / total, f * 100 / total
+}
+
+// FormatMacroSplit formats the split of calories between the macros,
+// e.g. "P 28% / C 45% / F 27% of calories".
+func FormatMacroSplit(protein, carbs, fat float64) string {
+	p, c, f := MacroSplit(protein, carbs, fat)
+	return fmt.Sprintf(tr("P %.0f%% / C %.0f%% / F %.0f%% of calories"), p, c, f)
+}
+
+// shortMacroSplit formats the split of calories between the macros to
+// fit a column, e.g. "28/45/27".
+func shortMacroSplit(protein, carbs, fat float64) string {
+	p, c, f := MacroSplit(protein, carbs, fat)
+	return fmt.Sprintf("%.0f/%.0f/%.0f", p, c, f)
+}
+
+// macroWarnings returns warnings about the macros eaten on the days:
+// days with less fat than the user's minimum, and protein falling short
+// of the target on most days of a week with enough logged days.
+func macroWarnings(u *UserInfo, days []Entry) []string {
+	var lowFat, lowProtein int
+	for _, e := range days {
+		if u.Macros.MinFats > 0 && e.Fat < u.Macros.MinFats {
+			lowFat++
+		}
+		if u.Macros.Protein > 0 && e.Protein < u.Macros.Protein {
+			lowProtein++
+		}
+	}
+
+	var ws []string
+	if lowFat > 0 {
+		ws = append(ws, fmt.Sprintf("fat was below your minimum of %sg on %d of %d days.",
+			FormatMacro(u.Macros.MinFats), lowFat, len(days)))
+	}
+	if len(days) >= minEntriesPerWeek && float64(lowProtein) > proteinShortfall*float64(len(days)) {
+		ws = append(ws, fmt.Sprintf("protein was under your %sg target on %d of %d days.",
+			FormatMacro(u.Macros.Protein), lowProtein, len(days)))
+	}
+	return ws
+}
+
+// printMacroWarnings prints the warnings about the macros eaten.
+func printMacroWarnings(ws []string) {
+	for _, w := range ws {
+		fmt.Printf("%s %s\n", paint(colorRed, "Warning:"), w)
+	}
+}
@@ -0,0 +1,32 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleMacroWarnings() {
+	u := &UserInfo{}
+	u.Macros.Protein = 150
+	u.Macros.MinFats = 50
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	week := []Entry{
+		{Date: start, Protein: 120, Carbs: 250, Fat: 60},
+		{Date: start.AddDate(0, 0, 1), Protein: 160, Carbs: 200, Fat: 45},
+		{Date: start.AddDate(0, 0, 2), Protein: 110, Carbs: 300, Fat: 70},
+		{Date: start.AddDate(0, 0, 3), Protein: 130, Carbs: 220, Fat: 65},
+	}
+
+	fmt.Println(FormatMacroSplit(week[0].Protein, week[0].Carbs, week[0].Fat))
+	fmt.Println(shortMacroSplit(week[1].Protein, week[1].Carbs, week[1].Fat))
+	for _, w := range macroWarnings(u, week) {
+		fmt.Println(w)
+	}
+
+	// Output:
+	// P 24% / C 50% / F 27% of calories
+	// 35/43/22
+	// fat was below your minimum of 50.0g on 1 of 4 days.
+	// protein was under your 150.0g target on 3 of 4 days.
+}
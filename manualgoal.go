@@ -0,0 +1,111 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ways to resolve an automatic calorie adjustment of a calorie goal the
+// user set by hand.
+const (
+	ManualAsk     = "ask"     // Ask each time.
+	ManualRespect = "respect" // Keep the goal set by hand.
+	ManualBlend   = "blend"   // Meet halfway between the two goals.
+	ManualReplace = "replace" // Take the adjusted goal.
+)
+
+// ManualGoalPolicy is how an automatic calorie adjustment is resolved
+// while the calorie goal is one the user set by hand.
+var ManualGoalPolicy = ManualAsk
+
+// SetGoalCalories sets the calorie goal of the active diet phase by
+// hand. The difference is taken from or added to the macros, and the
+// goal is marked as set by hand so that the progress checks resolve
+// their later adjustments by ManualGoalPolicy.
+func SetGoalCalories(db *sqlx.DB, u *UserInfo, cals float64) error {
+	if cals <= 0 {
+		return fmt.Errorf("calorie goal must be positive, got %v", cals)
+	}
+	if u.Phase.Status != "active" {
+		return errors.New("there is no active diet phase")
+	}
+	if !confirmSafety(u, (cals-u.TDEE)*7/calsPerPound, cals) {
+		fmt.Printf("Keeping calorie goal at %.0f.\n", u.Phase.GoalCalories)
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	m, left := AdjustMacros(u.Macros, cals-u.Phase.GoalCalories)
+	if left != 0 {
+		fmt.Printf("The macros reached their limits %.0f calories short of the new goal.\n", math.Abs(left))
+	}
+	u.Macros = m
+	u.Phase.GoalCalories = cals
+	u.Phase.ManualCalories = cals
+	if err := saveUserInfo(tx, u); err != nil {
+		return fmt.Errorf("couldn't save calorie goal: %v", err)
+	}
+	fmt.Printf("Calorie goal: %.0f. Macros: %sg protein, %sg carbs, %sg fats.\n", cals,
+		FormatMacro(m.Protein), FormatMacro(m.Carbs), FormatMacro(m.Fats))
+	return tx.Commit()
+}
+
+// resolveManualGoal resolves the automatic adjustment of the calorie
+// goal from old, with the old macros, to the user's current goal when
+// old was set by hand. It returns false if the goal set by hand is kept.
+//
+// Blending keeps the goal marked as set by hand, while replacing it
+// hands the goal back to the progress checks.
+func resolveManualGoal(u *UserInfo, old float64, macros Macros) bool {
+	if u.Phase.ManualCalories == 0 || math.Round(u.Phase.ManualCalories) != math.Round(old) {
+		return true
+	}
+	adjusted := u.Phase.GoalCalories
+
+	policy := ManualGoalPolicy
+	if policy == ManualAsk {
+		policy = promptManualGoal(old, adjusted)
+	}
+	switch policy {
+	case ManualReplace:
+		u.Phase.ManualCalories = 0
+		return true
+	case ManualBlend:
+		m, left := AdjustMacros(macros, (adjusted-old)/2)
+		u.Macros = m
+		u.Phase.GoalCalories = old + (adjusted-old)/2 - left
+		u.Phase.ManualCalories = u.Phase.GoalCalories
+		fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
+		return true
+	default:
+		u.Phase.GoalCalories = old
+		u.Macros = macros
+		fmt.Printf("Keeping calorie goal at %.2f.\n", old)
+		return false
+	}
+}
+
+// promptManualGoal asks the user how to resolve an adjustment of their
+// calorie goal set by hand. It defaults to respecting it.
+func promptManualGoal(manual, adjusted float64) string {
+	fmt.Printf("Your calorie goal of %.0f was set by hand, and the progress checks would change it to %.0f.\n", manual, adjusted)
+	fmt.Printf("Respect, blend, or replace it? (respect/blend/replace): ")
+	s, _ := Input.ReadString('\n')
+	switch a := strings.ToLower(strings.TrimSpace(s)); {
+	case a != "" && strings.HasPrefix(ManualBlend, a):
+		return ManualBlend
+	case a != "" && strings.HasPrefix(ManualReplace, a) && a != "re":
+		return ManualReplace
+	default:
+		return ManualRespect
+	}
+}
@@ -0,0 +1,58 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSetGoalCalories() {
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	u, err := Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetGoalCalories(db, u, 4000); err != nil {
+		log.Println(err)
+		return
+	}
+	u, err = Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Printf("%.0f %.0f\n", u.Phase.GoalCalories, u.Phase.ManualCalories)
+
+	// Output:
+	// The macros reached their limits 1430 calories short of the new goal.
+	// Calorie goal: 4000. Macros: 170.0g protein, 300.0g carbs, 80.0g fats.
+	// 4000 4000
+}
+
+func ExampleManualGoalPolicy() {
+	defer func(p string) { ManualGoalPolicy = p }(ManualGoalPolicy)
+
+	macros := Macros{Protein: 150, MinProtein: 130, MaxProtein: 170, Carbs: 250, MinCarbs: 200, MaxCarbs: 300, Fats: 70, MinFats: 60, MaxFats: 80}
+	for _, p := range []string{ManualRespect, ManualBlend, ManualReplace} {
+		ManualGoalPolicy = p
+		u := UserInfo{Macros: macros}
+		u.Phase.Name = "cut"
+		u.Phase.GoalCalories, u.Phase.ManualCalories = 2300, 2300
+		m, _ := AdjustMacros(macros, -200)
+		u.Macros, u.Phase.GoalCalories = m, 2100
+
+		kept := !resolveManualGoal(&u, 2300, macros)
+		fmt.Printf("%s: goal %.0f, set by hand %.0f, kept %t, carbs %.0fg\n",
+			p, u.Phase.GoalCalories, u.Phase.ManualCalories, kept, u.Macros.Carbs)
+	}
+
+	// Output:
+	// Keeping calorie goal at 2300.00.
+	// respect: goal 2300, set by hand 2300, kept true, carbs 250g
+	// New calorie goal: 2200.00.
+	// blend: goal 2200, set by hand 2200, kept false, carbs 248g
+	// replace: goal 2100, set by hand 0, kept false, carbs 222g
+}
@@ -1,11 +1,9 @@
 package bite
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
 	"strings"
 
@@ -41,6 +39,17 @@ type Food struct {
 	// the meal (in food_prefs).
 	BrandName string  `db:"brand_name"`
 	Price     float64 `db:"cost"`
+	// Source is where the food's data came from: "usda",
+	// "openfoodfacts", or "user".
+	Source string `db:"source"`
+	// Caffeine is the caffeine (mg) of a serving.
+	Caffeine float64 `db:"caffeine"`
+	// CookedYield is the weight of a gram of the food once cooked, set
+	// to override the standard yield for its kind. Zero means the
+	// standard yield.
+	CookedYield float64 `db:"cooked_yield"`
+	// GlycemicIndex is the glycemic index of the food, or 0 if unknown.
+	GlycemicIndex float64 `db:"glycemic_index"`
 }
 
 // MealFood extends Food with additional fields to represent a food
@@ -128,7 +137,7 @@ func CreateAddFood(db *sqlx.DB) error {
 func promptNewFood() (*Food, error) {
 	newFood := &Food{}
 
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	fmt.Print("Enter the food name: ")
 	newFood.Name, _ = reader.ReadString('\n')
 	// Remove newline character at the end
@@ -137,7 +146,7 @@ func promptNewFood() (*Food, error) {
 	newFood.ServingSize = servingSize()
 
 	fmt.Printf("Enter serving unit: ")
-	fmt.Scanln(&newFood.ServingUnit)
+	fmt.Fscanln(Input, &newFood.ServingUnit)
 
 	fmt.Printf("Enter the household serving: ")
 	newFood.HouseholdServing, _ = reader.ReadString('\n')
@@ -157,7 +166,7 @@ func promptNewFood() (*Food, error) {
 // promptFoodPrice prompts user for price of a given food, validates user
 // response, and returns the valid food price.
 func promptFoodPrice() float64 {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 
 	var floatValue float64
 	var err error
@@ -187,7 +196,7 @@ func promptFoodNutrients(db *sqlx.DB) (*FoodMacros, float64, error) {
 	for _, nutrientName := range nutrientNames {
 		fmt.Printf("Enter the amount of %s per 100 serving units: ", nutrientName)
 		var amount float64
-		_, err := fmt.Scan(&amount)
+		_, err := fmt.Fscanln(Input, &amount)
 		if err != nil || amount < 0 {
 			fmt.Println("Invalid input. Try again.")
 			continue
@@ -222,12 +231,19 @@ func CalcCals(protein, carbs, fats float64) float64 {
 }
 
 // InsertFood inserts a food into the database and returns the id of the newly inserted food.
+// Foods without a source are created by the user.
 func InsertFood(tx *sqlx.Tx, food Food) (int, error) {
 	const query = `
-	INSERT INTO foods (food_name, serving_size, serving_unit, household_serving)
-	VALUES ($1, $2, $3, $4)
+	INSERT INTO foods (food_name, serving_size, serving_unit, household_serving, source)
+	VALUES ($1, $2, $3, $4, $5)
 	`
-	res, err := tx.Exec(query, food.Name, food.ServingSize, food.ServingUnit, food.HouseholdServing)
+	if err := addFoodColumns(tx); err != nil {
+		return 0, err
+	}
+	if food.Source == "" {
+		food.Source = SourceUser
+	}
+	res, err := tx.Exec(query, food.Name, food.ServingSize, food.ServingUnit, food.HouseholdServing, food.Source)
 	if err != nil {
 		return 0, fmt.Errorf("InsertFood: %w", err)
 	}
@@ -345,7 +361,7 @@ func UpdateFood(db *sqlx.DB) error {
 
 // promptUpdateFood prompts the user to update information for an existing food.
 func promptUpdateFood(existingFood *Food) {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 
 	fmt.Printf("Current food name: %s\n", existingFood.Name)
 	fmt.Printf("Enter new food name [Press <Enter> to keep]: ")
@@ -391,7 +407,7 @@ func promptUpdateServingSize(existingServingSize float64) float64 {
 	fmt.Printf("Current serving size: %.2f\n", existingServingSize)
 	for {
 		fmt.Printf("Enter new serving size [Press <Enter> to keep]: ")
-		fmt.Scanln(&newServingSize)
+		fmt.Fscanln(Input, &newServingSize)
 
 		// User pressed <Enter>
 		if newServingSize == "" {
@@ -415,7 +431,7 @@ func promptUpdateFoodPrice(existingFoodPrice float64) float64 {
 	fmt.Printf("Current food price per 100 servings units: $%.2f\n", existingFoodPrice)
 	for {
 		fmt.Printf("Enter food price per 100 serving units [Press <Enter> to keep]: ")
-		fmt.Scanln(&newFoodPrice)
+		fmt.Fscanln(Input, &newFoodPrice)
 
 		// User pressed <Enter>
 		if newFoodPrice == "" {
@@ -476,7 +492,7 @@ OuterLoop:
 
 		for {
 			fmt.Printf("Enter new amount per 100 serving units [Press <Enter> to keep]: ")
-			fmt.Scanln(&newAmount)
+			fmt.Fscanln(Input, &newAmount)
 
 			// User pressed <Enter>
 			if newAmount == "" {
@@ -592,6 +608,22 @@ func DeleteFood(tx *sqlx.Tx, foodID int) error {
 		return fmt.Errorf("couldn't delete food pref entry: %v", err)
 	}
 
+	_, err = tx.Exec(`
+			DELETE FROM food_groups
+			WHERE food_id = $1
+			`, foodID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete food group: %v", err)
+	}
+
+	_, err = tx.Exec(`
+			DELETE FROM food_tags
+			WHERE food_id = $1
+			`, foodID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete food tags: %v", err)
+	}
+
 	_, err = tx.Exec(`
 			DELETE FROM meal_foods
 			WHERE food_id = $1
@@ -616,6 +648,22 @@ func DeleteFood(tx *sqlx.Tx, foodID int) error {
 		return fmt.Errorf("couldn't delete daily foods: %v", err)
 	}
 
+	_, err = tx.Exec(`
+			DELETE FROM pantry
+			WHERE food_id = $1
+			`, foodID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete pantry entry: %v", err)
+	}
+
+	_, err = tx.Exec(`
+			DELETE FROM price_history
+			WHERE food_id = $1
+			`, foodID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete price history: %v", err)
+	}
+
 	_, err = tx.Exec(`
 			DELETE FROM foods
 			WHERE food_id = $1
@@ -671,7 +719,7 @@ func CreateAddMeal(db *sqlx.DB) error {
 
 		var s string
 		fmt.Printf("Do you want to change these values? (y/n): ")
-		fmt.Scan(&s)
+		fmt.Fscanln(Input, &s)
 
 		// If the user decides to change existing food preferences,
 		if strings.ToLower(s) == "y" {
@@ -690,6 +738,83 @@ func CreateAddMeal(db *sqlx.DB) error {
 	return tx.Commit()
 }
 
+// DuplicateMeal creates a new meal with the foods and servings of the
+// meal with the given name. The user is prompted for the name of the
+// new meal if name is empty.
+func DuplicateMeal(db *sqlx.DB, from, name string) error {
+	m, err := MealByName(db, from)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = promptMealName()
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := CopyMeal(tx, m.ID, name); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created meal %q from %q.\n", name, m.Name)
+	return tx.Commit()
+}
+
+// MealByName returns the meal with the given name, ignoring case. It is
+// an error if more than one meal has the name.
+func MealByName(q sqlx.Queryer, name string) (Meal, error) {
+	const query = `
+		SELECT * FROM meals
+		WHERE meal_name = $1 COLLATE NOCASE
+		ORDER BY meal_id
+	`
+	var meals []Meal
+	if err := sqlx.Select(q, &meals, query, name); err != nil {
+		return Meal{}, fmt.Errorf("couldn't get meal %q: %v", name, err)
+	}
+	switch len(meals) {
+	case 0:
+		return Meal{}, fmt.Errorf("no meal named %q", name)
+	case 1:
+		return meals[0], nil
+	default:
+		return Meal{}, fmt.Errorf("%d meals are named %q", len(meals), name)
+	}
+}
+
+// CopyMeal inserts a new meal with the given name made up of the foods
+// of another meal and their servings in it, and returns its id.
+func CopyMeal(tx *sqlx.Tx, fromID int, name string) (int64, error) {
+	mealID, err := InsertMeal(tx, name)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't insert meal: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO meal_foods (meal_id, food_id)
+		SELECT $1, food_id FROM meal_foods
+		WHERE meal_id = $2
+	`, mealID, fromID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't copy meal foods: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings)
+		SELECT $1, food_id, serving_size, number_of_servings FROM meal_food_prefs
+		WHERE meal_id = $2
+	`, mealID, fromID)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't copy meal food preferences: %v", err)
+	}
+
+	return mealID, nil
+}
+
 // UpdateMeal updates an existing meal.
 func UpdateMeal(tx *sqlx.Tx, m Meal) error {
 	const updateSQL = `
@@ -746,6 +871,22 @@ func DeleteMeal(tx *sqlx.Tx, mealID int) error {
 		return fmt.Errorf("couldn't delete meal food entries: %v", err)
 	}
 
+	_, err = tx.Exec(`
+      DELETE FROM meal_tags
+      WHERE meal_id = $1
+      `, mealID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete meal tags: %v", err)
+	}
+
+	_, err = tx.Exec(`
+      DELETE FROM leftovers
+      WHERE meal_id = $1
+      `, mealID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete leftovers: %v", err)
+	}
+
 	// Set any `meal_id` in the daily_foods table for any entries that
 	// were apart of this meal to NULL.
 	_, err = tx.Exec(`UPDATE daily_foods SET meal_id = NULL WHERE meal_id = ?`, mealID)
@@ -812,6 +953,48 @@ func UpdateMealFoodPrefs(tx *sqlx.Tx, pref MealFoodPref) error {
 	return err
 }
 
+// SaveMeal inserts a new meal made up of the given foods and returns
+// its id. Each food's serving size and number of servings are saved as
+// its meal food preferences, so the meal is logged the way it was
+// built.
+func SaveMeal(tx *sqlx.Tx, name string, foods []Food) (int64, error) {
+	mealID, err := InsertMeal(tx, name)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't insert meal: %v", err)
+	}
+	for _, f := range foods {
+		if err := InsertMealFood(tx, int(mealID), f.ID); err != nil {
+			return 0, fmt.Errorf("couldn't insert meal food %q: %v", f.Name, err)
+		}
+		pref := MealFoodPref{
+			FoodID:           f.ID,
+			MealID:           mealID,
+			NumberOfServings: f.NumberOfServings,
+			ServingSize:      f.ServingSize,
+		}
+		if err := UpdateMealFoodPrefs(tx, pref); err != nil {
+			return 0, fmt.Errorf("couldn't save servings of %q: %v", f.Name, err)
+		}
+	}
+	return mealID, nil
+}
+
+// FoodsTotal returns the total calories, macros, and price of the
+// foods.
+func FoodsTotal(foods []Food) Nutrition {
+	var n Nutrition
+	for _, f := range foods {
+		n.Calories += f.Calories
+		n.Price += f.Price
+		if f.FoodMacros != nil {
+			n.Protein += f.FoodMacros.Protein
+			n.Fat += f.FoodMacros.Fat
+			n.Carbs += f.FoodMacros.Carbs
+		}
+	}
+	return n
+}
+
 // PromptAddMealFood prompts for existing meal and food to add to the
 // meal and then inserts the new meal food into the database.
 func PromptAddMealFood(db *sqlx.DB) error {
@@ -850,7 +1033,7 @@ func PromptAddMealFood(db *sqlx.DB) error {
 
 	var s string
 	fmt.Printf("Do you want to change these values? (y/n): ")
-	fmt.Scan(&s)
+	fmt.Fscanln(Input, &s)
 
 	// If the user decides to change existing food preferences,
 	if strings.ToLower(s) == "y" {
@@ -893,7 +1076,7 @@ func SelectDeleteFoodMealFood(db *sqlx.DB) error {
 	var idx int
 	for {
 		// Get user response.
-		reader := bufio.NewReader(os.Stdin)
+		reader := Input
 		fmt.Printf("Enter index of food to remove: ")
 		response, err := reader.ReadString('\n')
 		if err != nil {
@@ -952,7 +1135,7 @@ func DeleteMealFood(tx *sqlx.Tx, mealID, foodID int) error {
 
 // promptMealName prompts and returns name of meal.
 func promptMealName() (m string) {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	fmt.Printf("Enter the name of your new meal: ")
 	m, err := reader.ReadString('\n')
 	if err != nil {
@@ -1002,7 +1185,7 @@ func servingSize() float64 {
 
 // promptServingSize prompts and returns serving size.
 func promptServingSize() (s float64, err error) {
-	if _, err := fmt.Scan(&s); err != nil {
+	if _, err := fmt.Fscanln(Input, &s); err != nil {
 		return 0, err
 	}
 	return s, nil
@@ -1044,7 +1227,7 @@ func getNumServings() float64 {
 
 // promptNumServings prompts and returns number of servings.
 func promptNumServings() (s float64, err error) {
-	if _, err := fmt.Scan(&s); err != nil {
+	if _, err := fmt.Fscanln(Input, &s); err != nil {
 		return 0, err
 	}
 	return s, nil
@@ -5,15 +5,11 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/ericstrs/bite/internal/dbtest"
 )
 
 func ExampleInsertFood() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -23,15 +19,6 @@ func ExampleInsertFood() {
 		return
 	}
 
-	// Create the foods table
-	tx.MustExec(` CREATE TABLE IF NOT EXISTS foods (
-  food_id INTEGER PRIMARY KEY,
-  food_name TEXT NOT NULL,
-  serving_size REAL NOT NULL,
-  serving_unit TEXT NOT NULL,
-  household_serving TEXT NOT NULL
-  )`)
-
 	food := Food{
 		ID:               1,
 		Name:             "Chicken Breast",
@@ -76,11 +63,7 @@ func ExampleInsertFood() {
 }
 
 func ExampleUpdateFoodTable() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -91,19 +74,6 @@ func ExampleUpdateFoodTable() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the food_nutrients table
-	tx.MustExec(`
-			CREATE TABLE IF NOT EXISTS foods (
-				food_id INTEGER PRIMARY KEY,
-				food_name TEXT NOT NULL,
-				serving_size REAL NOT NULL,
-				serving_unit TEXT NOT NULL,
-				household_serving TEXT NOT NULL,
-				brand_name TEXT DEFAULT '',
-				cost REAL DEFAULT 0
-			);
-	`)
-
 	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
   (1, 'Chicken Breast', 100, 'g', '1/2 piece')
   `)
@@ -143,11 +113,7 @@ func ExampleUpdateFoodTable() {
 }
 
 func ExampleDeleteFood() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -158,112 +124,19 @@ func ExampleDeleteFood() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the food_nutrients table
 	tx.MustExec(`
-			-- foods contains static information about foods.
-			CREATE TABLE IF NOT EXISTS foods (
-				food_id INTEGER PRIMARY KEY,
-				food_name TEXT NOT NULL,
-				serving_size REAL NOT NULL,
-				serving_unit TEXT NOT NULL,
-				household_serving TEXT NOT NULL
-			);
-
-			-- user_foods contains the user's food consumption
-			-- logs.
-			CREATE TABLE IF NOT EXISTS daily_foods (
-				id INTEGER PRIMARY KEY,
-				food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-				meal_id INTEGER REFERENCES meals(meal_id),
-				date DATE NOT NULL,
-				serving_size REAL NOT NULL,
-				number_of_servings REAL DEFAULT 1 NOT NULL
-			);
-
-			-- meal_foods relates meals to the foods the contain.
-			CREATE TABLE IF NOT EXISTS meal_foods (
-				meal_id INTEGER REFERENCES meals(meal_id),
-				food_id INTEGER REFERENCES foods(food_id),
-				number_of_servings REAL DEFAULT 1 NOT NULL
-			);
-
-			-- nutrients stores the nurtients that a food can be comprised of.
-			CREATE TABLE IF NOT EXISTS nutrients (
-				nutrient_id INTEGER PRIMARY KEY,
-				nutrient_name TEXT NOT NULL,
-				unit_name TEXT NOT NULL
-			);
-
-			-- food_nutrient_derivation stores the procedure indicating how a food
-			-- nutrient value was obtained.
-			CREATE TABLE IF NOT EXISTS food_nutrient_derivation (
-				id INT PRIMARY KEY,
-				code VARCHAR(255) NOT NULL,
-				description TEXT NOT NULL
-			);
-
-			CREATE TABLE IF NOT EXISTS food_nutrients (
-				id INTEGER PRIMARY KEY,
-				food_id INTEGER NOT NULL,
-				nutrient_id INTEGER NOT NULL,
-				amount REAL NOT NULL,
-				derivation_id REAL NOT NULL,
-				FOREIGN KEY (food_id) REFERENCES foods(food_id),
-				FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-				FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-			);
-
-			CREATE TABLE IF NOT EXISTS food_prefs (
-				food_id INTEGER PRIMARY KEY,
-				serving_size REAL,
-				number_of_servings REAL DEFAULT 1 NOT NULL,
-				FOREIGN KEY(food_id) REFERENCES foods(food_id)
-			);
-
-			CREATE TABLE IF NOT EXISTS meal_food_prefs (
-				meal_id INTEGER,
-				food_id INTEGER,
-				serving_size REAL,
-				number_of_servings REAL DEFAULT 1 NOT NULL,
-				PRIMARY KEY(meal_id, food_id),
-				FOREIGN KEY(food_id) REFERENCES foods(food_id),
-				FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-			);
-  `)
-
-	// Insert food
-	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
-  (1, 'Chicken Breast', 100, 'g', '1/2 piece')
-	`)
-
-	// Then, insert a nutrient
-	tx.MustExec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-	(1003, 'Protein', 'g')
-	`)
-
-	// Insert into daily_foods
-	tx.MustExec(`INSERT INTO daily_foods (food_id, meal_id, date, serving_size, number_of_servings) VALUES
-	(1, 1, '2023-07-09', 100, 1)
-	`)
-
-	// Insert into meal_foods
-	tx.MustExec(`INSERT INTO meal_foods (meal_id, food_id, number_of_servings) VALUES
-	(1, 1, 1)
-	`)
-
-	// Insert into food_nutrients
-	tx.MustExec(`INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
-	(1, 1003, 50, 71)
-	`)
-
-	// Insert into food_prefs
-	tx.MustExec(`INSERT INTO food_prefs (food_id, serving_size, number_of_servings) VALUES
-	(1, 100, 1)
-	`)
-
-	// Insert into meal_food_prefs
-	tx.MustExec(`INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES
-	(1, 1, 100, 1)
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (1, 'Chicken Breast', 100, 'g', '1/2 piece');
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES (1003, 'Protein', 'g');
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Chicken dinner');
+
+		INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+			VALUES (1, 1, '2023-07-09', '00:00:00', 100, 1, 165, 31, 3.6, 0);
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1);
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES (1, 1003, 50, 71);
+		INSERT INTO food_prefs (food_id, serving_size, number_of_servings) VALUES (1, 100, 1);
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES (1, 1, 100, 1);
 	`)
 
 	if err := DeleteFood(tx, 1); err != nil {
@@ -298,12 +171,54 @@ func ExampleDeleteFood() {
 	// Food with ID 1 was successfully deleted from table meal_food_prefs.
 }
 
-func ExampleInsertMeal() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
+func ExampleDeleteFood_pantry() {
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO pantry (food_id, on_hand, par) VALUES (1, 400, 200), (2, 500, 0);
+		INSERT INTO price_history (food_id, date, item, price, quantity, cost) VALUES
+			(1, '2024-01-05', 'OATS 1KG', 3.5, 1000, 0.35),
+			(2, '2024-01-05', 'CHKN BRST', 9, 900, 1);
+	`)
+	defer db.Close()
+
+	tx, err := db.Beginx()
 	if err != nil {
-		panic(err)
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := DeleteFood(tx, 1); err != nil {
+		fmt.Println(err)
+		return
 	}
+	// Deleting a food directly removes its rows too.
+	tx.MustExec(`DELETE FROM foods WHERE food_id = 2`)
+	if err := tx.Commit(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	var n struct {
+		Pantry int `db:"pantry"`
+		Prices int `db:"prices"`
+	}
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM pantry) AS pantry,
+			(SELECT COUNT(*) FROM price_history) AS prices
+	`
+	if err := db.Get(&n, query); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(n.Pantry, n.Prices)
+
+	// Output:
+	// 0 0
+}
+
+func ExampleInsertMeal() {
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -314,14 +229,6 @@ func ExampleInsertMeal() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create meals table.
-	tx.MustExec(`
-		CREATE TABLE IF NOT EXISTS meals (
-				meal_id INTEGER PRIMARY KEY,
-				meal_name TEXT NOT NULL
-		);
-	`)
-
 	id, err := InsertMeal(tx, "Cereal")
 	if err != nil {
 		fmt.Println(err)
@@ -343,11 +250,7 @@ func ExampleInsertMeal() {
 }
 
 func ExampleInsertMealFood() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -358,28 +261,6 @@ func ExampleInsertMealFood() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create meal foods table.
-	tx.MustExec(`
-		CREATE TABLE IF NOT EXISTS meals (
-				meal_id INTEGER PRIMARY KEY,
-				meal_name TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS foods (
-			food_id INTEGER PRIMARY KEY,
-			food_name TEXT NOT NULL,
-			serving_size REAL NOT NULL,
-			serving_unit TEXT NOT NULL,
-			household_serving TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS meal_foods (
-			meal_id INTEGER REFERENCES meals(meal_id),
-			food_id INTEGER REFERENCES foods(food_id),
-			PRIMARY KEY (meal_id, food_id)
-		);
-  `)
-
 	_, err = tx.Exec(`INSERT INTO meals VALUES (1, 'Cereal')`)
 	if err != nil {
 		log.Printf("Failed to insert data into meal table: %v\n", err)
@@ -423,11 +304,7 @@ func ExampleInsertMealFood() {
 }
 
 func ExampleUpdateMealFoodPrefs() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -438,31 +315,6 @@ func ExampleUpdateMealFoodPrefs() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	tx.MustExec(`
-		CREATE TABLE IF NOT EXISTS foods (
-			food_id INTEGER PRIMARY KEY,
-			food_name TEXT NOT NULL,
-			serving_size REAL NOT NULL,
-			serving_unit TEXT NOT NULL,
-			household_serving TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS meals (
-				meal_id INTEGER PRIMARY KEY,
-				meal_name TEXT NOT NULL
-		);
-
-		CREATE TABLE IF NOT EXISTS meal_food_prefs (
-			meal_id INTEGER,
-			food_id INTEGER,
-			serving_size REAL,
-			number_of_servings REAL DEFAULT 1 NOT NULL,
-			PRIMARY KEY(meal_id, food_id),
-			FOREIGN KEY(food_id) REFERENCES foods(food_id),
-			FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-		);
-	`)
-
 	_, err = tx.Exec(`INSERT INTO meals VALUES (1, 'Cereal')`)
 	if err != nil {
 		log.Printf("Failed to insert data into meal table: %v\n", err)
@@ -477,6 +329,8 @@ func ExampleUpdateMealFoodPrefs() {
 		return
 	}
 
+	tx.MustExec(`INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1)`)
+
 	pref := &MealFoodPref{}
 	pref.FoodID = 1
 	pref.MealID = 1
@@ -504,137 +358,125 @@ func ExampleUpdateMealFoodPrefs() {
 	// <nil>
 }
 
-func ExampleDeleteMeal() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		fmt.Printf("ERROR: couldn't connect to test database: %v", err)
-	}
+func ExampleSaveMeal() {
+	db := dbtest.MustNew()
 	defer db.Close()
 
-	// Start a new transaction
 	tx, err := db.Beginx()
 	if err != nil {
+		log.Println(err)
 		return
 	}
-	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the food_nutrients table
-	tx.MustExec(`
-			-- foods contains static information about foods.
-			CREATE TABLE IF NOT EXISTS foods (
-				food_id INTEGER PRIMARY KEY,
-				food_name TEXT NOT NULL,
-				serving_size REAL NOT NULL,
-				serving_unit TEXT NOT NULL,
-				household_serving TEXT NOT NULL
-			);
-
-			CREATE TABLE IF NOT EXISTS meals (
- 		   meal_id INTEGER PRIMARY KEY,
-   		 meal_name TEXT NOT NULL
-			);
-
-			-- user_foods contains the user's food consumption
-			-- logs.
-			CREATE TABLE IF NOT EXISTS daily_foods (
-				id INTEGER PRIMARY KEY,
-				food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-				meal_id INTEGER REFERENCES meals(meal_id),
-				date DATE NOT NULL,
-				serving_size REAL NOT NULL,
-				number_of_servings REAL DEFAULT 1 NOT NULL
-			);
-
-			CREATE TABLE IF NOT EXISTS daily_meals (
-  			id INTEGER PRIMARY KEY,
-  			meal_id INTEGER REFERENCES meals(meal_id),
-  			date DATE NOT NULL
-			);
-
-			-- meal_foods relates meals to the foods the contain.
-			CREATE TABLE IF NOT EXISTS meal_foods (
-				meal_id INTEGER REFERENCES meals(meal_id),
-				food_id INTEGER REFERENCES foods(food_id),
-				number_of_servings REAL DEFAULT 1 NOT NULL
-			);
-
-			-- nutrients stores the nurtients that a food can be comprised of.
-			CREATE TABLE IF NOT EXISTS nutrients (
-				nutrient_id INTEGER PRIMARY KEY,
-				nutrient_name TEXT NOT NULL,
-				unit_name TEXT NOT NULL
-			);
-
-			-- food_nutrient_derivation stores the procedure indicating how a food
-			-- nutrient value was obtained.
-			CREATE TABLE IF NOT EXISTS food_nutrient_derivation (
-				id INT PRIMARY KEY,
-				code VARCHAR(255) NOT NULL,
-				description TEXT NOT NULL
-			);
-
-			CREATE TABLE IF NOT EXISTS food_nutrients (
-				id INTEGER PRIMARY KEY,
-				food_id INTEGER NOT NULL,
-				nutrient_id INTEGER NOT NULL,
-				amount REAL NOT NULL,
-				derivation_id REAL NOT NULL,
-				FOREIGN KEY (food_id) REFERENCES foods(food_id),
-				FOREIGN KEY (nutrient_id) REFERENCES nutrients(nutrients_id),
-				FOREIGN KEY (derivation_id) REFERENCES food_nutrient_derivation(id)
-			);
-
-			CREATE TABLE IF NOT EXISTS meal_food_prefs (
-				meal_id INTEGER,
-				food_id INTEGER,
-				serving_size REAL,
-				number_of_servings REAL DEFAULT 1 NOT NULL,
-				PRIMARY KEY(meal_id, food_id),
-				FOREIGN KEY(food_id) REFERENCES foods(food_id),
-				FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-			);
-  `)
-	// Insert food
-	tx.MustExec(`INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
-  (1, 'Chicken Breast', 100, 'g', '1/2 piece')
-  `)
+	oats := Food{ID: 1, Name: "Oats", ServingSize: 40, NumberOfServings: 1,
+		Calories: 150, Price: 0.2, FoodMacros: &FoodMacros{Protein: 5, Fat: 3, Carbs: 27}}
+	milk := Food{ID: 2, Name: "Milk", ServingSize: 240, NumberOfServings: 1,
+		Calories: 120, Price: 0.3, FoodMacros: &FoodMacros{Protein: 8, Fat: 5, Carbs: 12}}
+	// Override the serving of the milk before saving.
+	ScaleServings(&milk, 120, 1)
 
-	// Then, insert a nutrient
-	tx.MustExec(`INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
-  (1003, 'Protein', 'g')
-  `)
+	foods := []Food{oats, milk}
+	n := FoodsTotal(foods)
+	fmt.Printf("%.0f cals, %.1fg protein, $%.2f\n", n.Calories, n.Protein, n.Price)
 
-	// Insert into meals
-	tx.MustExec(`INSERT INTO meals (meal_name) VALUES
-	('Chicken burrito')
+	id, err := SaveMeal(tx, "Oatmeal", foods)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	var prefs []MealFoodPref
+	if err := tx.Select(&prefs, `SELECT * FROM meal_food_prefs WHERE meal_id = $1 ORDER BY food_id`, id); err != nil {
+		log.Println(err)
+		return
+	}
+	for _, p := range prefs {
+		fmt.Printf("food %d: %.0f x %.0f\n", p.FoodID, p.ServingSize, p.NumberOfServings)
+	}
+
+	// Output:
+	// 210 cals, 9.0g protein, $0.35
+	// food 1: 40 x 1
+	// food 2: 120 x 1
+}
+
+func ExampleCopyMeal() {
+	db := dbtest.MustNew(`
+		INSERT INTO meals VALUES (1, 'Chicken bowl'), (2, 'Oatmeal');
+		INSERT INTO meal_foods VALUES (1, 1), (1, 2), (2, 3);
+		INSERT INTO meal_food_prefs VALUES (1, 1, 150, 1), (2, 3, 40, 1);
 	`)
+	defer db.Close()
 
-	// Insert into daily_meals
-	tx.MustExec(`INSERT INTO daily_meals (meal_id, date) VALUES
-  (1, '2023-07-09')
-  `)
+	m, err := MealByName(db, "chicken bowl")
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	_, err = MealByName(db, "Burrito")
+	fmt.Println(err)
 
-	// Insert into daily_foods
-	tx.MustExec(`INSERT INTO daily_foods (food_id, meal_id, date, serving_size, number_of_servings) VALUES
-  (1, 1, '2023-07-09', 100, 1)
-  `)
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
 
-	// Insert into meal_foods
-	tx.MustExec(`INSERT INTO meal_foods (meal_id, food_id, number_of_servings) VALUES
-  (1, 1, 1)
-  `)
+	id, err := CopyMeal(tx, m.ID, "Rice bowl")
+	if err != nil {
+		log.Println(err)
+		return
+	}
 
-	// Insert into food_nutrients
-	tx.MustExec(`INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
-  (1, 1003, 50, 71)
-  `)
+	var foods []int
+	if err := tx.Select(&foods, `SELECT food_id FROM meal_foods WHERE meal_id = $1 ORDER BY food_id`, id); err != nil {
+		log.Println(err)
+		return
+	}
+	var prefs []MealFoodPref
+	if err := tx.Select(&prefs, `SELECT * FROM meal_food_prefs WHERE meal_id = $1`, id); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(id, foods)
+	for _, p := range prefs {
+		fmt.Printf("food %d: %.0f x %.0f\n", p.FoodID, p.ServingSize, p.NumberOfServings)
+	}
 
-	// Insert into meal_food_prefs
-	tx.MustExec(`INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES
-  (1, 1, 100, 1)
-  `)
+	// Output:
+	// no meal named "Burrito"
+	// 3 [1 2]
+	// food 1: 150 x 1
+}
+
+func ExampleDeleteMeal() {
+	db := dbtest.MustNewEmpty()
+	defer db.Close()
+
+	// Start a new transaction
+	tx, err := db.Beginx()
+	if err != nil {
+		return
+	}
+	// If anything goes wrong, rollback the transaction
+	defer tx.Rollback()
+
+	tx.MustExec(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (1, 'Chicken Breast', 100, 'g', '1/2 piece');
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES (1003, 'Protein', 'g');
+		INSERT INTO food_nutrient_derivation (id, code, description) VALUES (71, 'PORT', 'Portion size');
+		INSERT INTO meals (meal_name) VALUES ('Chicken burrito');
+
+		INSERT INTO daily_meals (meal_id, date, time) VALUES (1, '2023-07-09', '00:00:00');
+		INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+			VALUES (1, 1, '2023-07-09', '00:00:00', 100, 1, 165, 31, 3.6, 0);
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 1);
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES (1, 1003, 50, 71);
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size, number_of_servings) VALUES (1, 1, 100, 1);
+	`)
 
 	if err := DeleteMeal(tx, 1); err != nil {
 		fmt.Printf("Failed to delete meal: %v\n", err)
@@ -669,11 +511,7 @@ func ExampleDeleteMeal() {
 }
 
 func ExampleDeleteMealFood() {
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNewEmpty()
 	defer db.Close()
 
 	// Start a new transaction
@@ -684,48 +522,6 @@ func ExampleDeleteMealFood() {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	// Create the food_nutrients table
-	tx.MustExec(`
-      -- foods contains static information about foods.
-      CREATE TABLE IF NOT EXISTS foods (
-        food_id INTEGER PRIMARY KEY,
-        food_name TEXT NOT NULL,
-        serving_size REAL NOT NULL,
-        serving_unit TEXT NOT NULL,
-        household_serving TEXT NOT NULL
-      );
-
-      CREATE TABLE IF NOT EXISTS meals (
-       meal_id INTEGER PRIMARY KEY,
-       meal_name TEXT NOT NULL
-      );
-
-      -- user_foods contains the user's food consumption
-      -- logs.
-      CREATE TABLE IF NOT EXISTS daily_foods (
-        id INTEGER PRIMARY KEY,
-        food_id INTEGER REFERENCES foods(food_id) NOT NULL,
-        meal_id INTEGER REFERENCES meals(meal_id),
-        date DATE NOT NULL,
-        serving_size REAL NOT NULL,
-        number_of_servings REAL DEFAULT 1 NOT NULL
-      );
-
-			CREATE TABLE IF NOT EXISTS meal_foods (
-        meal_id INTEGER REFERENCES meals(meal_id),
-        food_id INTEGER REFERENCES foods(food_id)
-      );
-
-			CREATE TABLE IF NOT EXISTS meal_food_prefs (
-        meal_id INTEGER,
-        food_id INTEGER,
-        serving_size REAL,
-        number_of_servings REAL DEFAULT 1 NOT NULL,
-        PRIMARY KEY(meal_id, food_id),
-        FOREIGN KEY(food_id) REFERENCES foods(food_id),
-        FOREIGN KEY(meal_id) REFERENCES meals(meal_id)
-      );
-	`)
 	// Insert into meals
 	tx.MustExec(`INSERT INTO meals (meal_name) VALUES
 	('Chicken burrito')
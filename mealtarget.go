@@ -0,0 +1,178 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MacroTarget is the least protein, carbs, and fat a meal slot should
+// have, such as 30g of protein at breakfast. Zero means no target.
+type MacroTarget struct {
+	Protein float64
+	Carbs   float64
+	Fat     float64
+}
+
+// SlotTargets maps meal slots to their macro targets.
+var SlotTargets = map[MealSlot]MacroTarget{}
+
+// IsZero reports whether the target sets no macros.
+func (t MacroTarget) IsZero() bool {
+	return t == MacroTarget{}
+}
+
+// String returns the target's macros, such as "protein ≥30g, fat ≥10g".
+func (t MacroTarget) String() string {
+	var parts []string
+	for _, m := range t.macros() {
+		if m.target > 0 {
+			parts = append(parts, fmt.Sprintf("%s ≥%sg", m.name, FormatMacro(m.target)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Shortfalls returns the macros that fall short of the target, such as
+// "protein 22g of 30g".
+func (t MacroTarget) Shortfalls(m FoodMacros) []string {
+	var short []string
+	for _, mt := range t.macros() {
+		if v := mt.value(m); mt.target > 0 && v < mt.target {
+			short = append(short, fmt.Sprintf("%s %sg of %sg", mt.name,
+				FormatMacro(v), FormatMacro(mt.target)))
+		}
+	}
+	return short
+}
+
+// macroTarget is a target of a single macro.
+type macroTarget struct {
+	name   string
+	target float64
+	value  func(FoodMacros) float64
+}
+
+// macros returns the targets of each macro.
+func (t MacroTarget) macros() []macroTarget {
+	return []macroTarget{
+		{"protein", t.Protein, func(m FoodMacros) float64 { return m.Protein }},
+		{"carbs", t.Carbs, func(m FoodMacros) float64 { return m.Carbs }},
+		{"fat", t.Fat, func(m FoodMacros) float64 { return m.Fat }},
+	}
+}
+
+// SlotShortfalls returns the macros that fall short of the target of
+// the meal slot, or nil if the slot has no target.
+func SlotShortfalls(slot MealSlot, m FoodMacros) []string {
+	return SlotTargets[slot].Shortfalls(m)
+}
+
+// printSlotShortfalls prints a warning if the macros fall short of the
+// target of the meal slot.
+func printSlotShortfalls(slot MealSlot, m FoodMacros) {
+	if short := SlotShortfalls(slot, m); len(short) > 0 {
+		fmt.Println(paint(colorMissed, fmt.Sprintf("Below the %s target: %s.", slot, strings.Join(short, ", "))))
+	}
+}
+
+// SlotAdherence is how often a meal slot hit its macro target.
+type SlotAdherence struct {
+	Slot   MealSlot
+	Target MacroTarget
+	Days   int // Days with food logged in the slot.
+	Hit    int // Days the slot hit its target.
+}
+
+// SlotAdherenceBetween returns how often each meal slot with a target
+// hit it for the food entries from start up to, but not including, end.
+func SlotAdherenceBetween(q sqlx.Queryer, start, end time.Time) ([]SlotAdherence, error) {
+	const query = `
+		SELECT date, time, protein, fat, carbs
+		FROM daily_foods
+		WHERE date >= $1 AND date < $2
+	`
+	var entries []struct {
+		Date time.Time `db:"date"`
+		Time string    `db:"time"`
+		FoodMacros
+	}
+	if err := sqlx.Select(q, &entries, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get food entries: %v", err)
+	}
+
+	// Total the macros of each slot of each day.
+	type slotDay struct {
+		slot MealSlot
+		date string
+	}
+	totals := make(map[slotDay]FoodMacros)
+	for _, e := range entries {
+		k := slotDay{DailyFood{Time: e.Time}.Slot(), e.Date.Format(dateFormat)}
+		m := totals[k]
+		m.Protein += e.Protein
+		m.Fat += e.Fat
+		m.Carbs += e.Carbs
+		totals[k] = m
+	}
+
+	var adherence []SlotAdherence
+	for _, slot := range MealSlots {
+		t := SlotTargets[slot]
+		if t.IsZero() {
+			continue
+		}
+		a := SlotAdherence{Slot: slot, Target: t}
+		for k, m := range totals {
+			if k.slot != slot {
+				continue
+			}
+			a.Days++
+			if len(t.Shortfalls(m)) == 0 {
+				a.Hit++
+			}
+		}
+		adherence = append(adherence, a)
+	}
+	return adherence, nil
+}
+
+// SlotSummary prints how often each meal slot hit its macro target over
+// the last week.
+func SlotSummary(db *sqlx.DB, now time.Time) error {
+	if len(SlotTargets) == 0 {
+		fmt.Println("No meal targets set.")
+		return nil
+	}
+
+	end := dateOf(now).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -7)
+	adherence, err := SlotAdherenceBetween(db, start, end)
+	if err != nil {
+		return err
+	}
+	writeSlotAdherence(start, end.AddDate(0, 0, -1), adherence)
+	return nil
+}
+
+// writeSlotAdherence prints a table of meal slot adherence from start
+// to end, inclusive.
+func writeSlotAdherence(start, end time.Time, adherence []SlotAdherence) {
+	fmt.Println(paint(colorUnderline, fmt.Sprintf("Meal targets %s to %s", start.Format(dateFormat), end.Format(dateFormat))))
+	fmt.Printf("%-10s %-32s %s\n", "Slot", "Target", "Hit")
+	for _, a := range adherence {
+		hit := "not logged"
+		if a.Days > 0 {
+			hit = fmt.Sprintf("%d of %d days (%.0f%%)", a.Hit, a.Days, float64(a.Hit)/float64(a.Days)*100)
+			switch a.Hit == a.Days {
+			case true:
+				hit = paint(colorMet, hit)
+			case false:
+				hit = paint(colorMissed, hit)
+			}
+		}
+		fmt.Printf("%-10s %-32s %s\n", a.Slot, a.Target, hit)
+	}
+}
@@ -0,0 +1,57 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleMacroTarget_Shortfalls() {
+	t := MacroTarget{Protein: 30, Fat: 10}
+	fmt.Println(t)
+	fmt.Println(t.Shortfalls(FoodMacros{Protein: 22, Fat: 12, Carbs: 50}))
+	fmt.Println(len(t.Shortfalls(FoodMacros{Protein: 35, Fat: 10})))
+
+	// Output:
+	// protein ≥30.0g, fat ≥10.0g
+	// [protein 22.0g of 30.0g]
+	// 0
+}
+
+func ExampleSlotAdherenceBetween() {
+	// Breakfast hits 30g of protein on the first day only, once its two
+	// foods are added up. Lunch has no target.
+	db := dbtest.MustNew(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(1, '2024-01-01', '07:00:00', 40, 300, 20, 10, 30),
+			(2, '2024-01-01', '07:30:00', 100, 100, 12, 0, 5),
+			(1, '2024-01-02', '08:00:00', 40, 300, 20, 10, 30),
+			(3, '2024-01-02', '12:00:00', 118, 600, 40, 20, 60),
+			(1, '2024-01-03', '08:00:00', 40, 300, 20, 10, 30);
+	`)
+	defer db.Close()
+
+	defer func(t map[MealSlot]MacroTarget) { SlotTargets = t }(SlotTargets)
+	SlotTargets = map[MealSlot]MacroTarget{
+		Breakfast: {Protein: 30},
+		Dinner:    {Protein: 40},
+	}
+	NoColor = true
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	adherence, err := SlotAdherenceBetween(db, start, end)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	writeSlotAdherence(start, end.AddDate(0, 0, -1), adherence)
+
+	// Output:
+	// Meal targets 2024-01-01 to 2024-01-07
+	// Slot       Target                           Hit
+	// breakfast  protein ≥30.0g                   1 of 3 days (33%)
+	// dinner     protein ≥40.0g                   not logged
+}
@@ -0,0 +1,92 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Metrics are the gauges of the diet exported for monitoring.
+type Metrics struct {
+	CaloriesToday    float64
+	CalorieGoal      float64
+	ProteinRemaining float64 // Grams, negative once the goal is passed.
+	TrendWeight      float64 // Pounds, 0 without logged weights.
+	PhaseActive      bool
+	PhaseDay         int // Day of the active phase, starting at 1.
+}
+
+// ReadMetrics reads the diet's gauges as of now. It returns
+// ErrNoUserInfo if the user info hasn't been set up.
+func ReadMetrics(db *sqlx.DB, now time.Time) (*Metrics, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	u, err := loadUserInfo(tx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoUserInfo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := DietRange(tx, u, now, now)
+	if err != nil {
+		return nil, err
+	}
+	m := &Metrics{}
+	today := s.Total()
+	m.CaloriesToday = today.Calories
+	m.ProteinRemaining = u.Macros.Protein - today.Protein
+	if m.CalorieGoal, err = dayGoal(tx, u, now); err != nil {
+		return nil, err
+	}
+
+	var weights []Entry
+	const query = `SELECT date, weight AS user_weight FROM daily_weights WHERE date <= $1 ORDER BY date`
+	if err := tx.Select(&weights, query, now.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get weights: %v", err)
+	}
+	if trend := TrendWeights(weights); len(trend) > 0 {
+		m.TrendWeight = trend[len(trend)-1]
+	}
+
+	if u.Phase.Status == "active" {
+		m.PhaseActive = true
+		m.PhaseDay = int(dateOf(now).Sub(dateOf(u.Phase.StartDate)).Hours()/24) + 1
+	}
+	return m, nil
+}
+
+// WriteMetrics writes the gauges in the Prometheus text format.
+func WriteMetrics(w io.Writer, m *Metrics) error {
+	active := 0.0
+	if m.PhaseActive {
+		active = 1
+	}
+	gauges := []struct {
+		name, help string
+		value      float64
+	}{
+		{"bite_calories_today", "Calories eaten today.", m.CaloriesToday},
+		{"bite_calorie_goal", "Calorie goal of today.", m.CalorieGoal},
+		{"bite_protein_remaining_grams", "Protein left to eat today to reach the goal.", m.ProteinRemaining},
+		{"bite_weight_trend_pounds", "Smoothed trend of the logged weights.", m.TrendWeight},
+		{"bite_phase_active", "Whether a diet phase is active.", active},
+		{"bite_phase_day", "Day of the active diet phase.", float64(m.PhaseDay)},
+	}
+	for _, g := range gauges {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", g.name, g.help, g.name, g.name, g.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
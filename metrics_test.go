@@ -0,0 +1,50 @@
+package bite
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleWriteMetrics() {
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+
+	db.MustExec(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+			VALUES (1, '2024-01-08', '08:00:00', 80, 1, 311, 10.4, 5.6, 54.4),
+			       (1, '2024-01-08', '12:00:00', 40, 1, 155.5, 5.2, 2.8, 27.2);
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '07:00:00', 185), ('2024-01-08', '07:00:00', 183);
+	`)
+
+	m, err := ReadMetrics(db, time.Date(2024, 1, 8, 18, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := WriteMetrics(os.Stdout, m); err != nil {
+		log.Fatal(err)
+	}
+
+	// Output:
+	// # HELP bite_calories_today Calories eaten today.
+	// # TYPE bite_calories_today gauge
+	// bite_calories_today 466.5
+	// # HELP bite_calorie_goal Calorie goal of today.
+	// # TYPE bite_calorie_goal gauge
+	// bite_calorie_goal 2200
+	// # HELP bite_protein_remaining_grams Protein left to eat today to reach the goal.
+	// # TYPE bite_protein_remaining_grams gauge
+	// bite_protein_remaining_grams 134.4
+	// # HELP bite_weight_trend_pounds Smoothed trend of the logged weights.
+	// # TYPE bite_weight_trend_pounds gauge
+	// bite_weight_trend_pounds 184.8
+	// # HELP bite_phase_active Whether a diet phase is active.
+	// # TYPE bite_phase_active gauge
+	// bite_phase_active 1
+	// # HELP bite_phase_day Day of the active diet phase.
+	// # TYPE bite_phase_day gauge
+	// bite_phase_day 8
+}
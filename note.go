@@ -0,0 +1,163 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Note is an annotation of a life event on the timeline, such as
+// "started new job", that explains changes in weight or adherence when
+// looking back.
+type Note struct {
+	ID   int       `db:"id"`
+	Date time.Time `db:"date"`
+	Text string    `db:"note"`
+}
+
+// String returns the note as its date and text.
+func (n Note) String() string {
+	return n.Date.Format(dateFormat) + " " + n.Text
+}
+
+// notesSchema creates the timeline_notes table in databases made before
+// it existed.
+const notesSchema = `
+	CREATE TABLE IF NOT EXISTS timeline_notes (
+		id INTEGER PRIMARY KEY,
+		date DATE NOT NULL,
+		note TEXT NOT NULL
+	)
+`
+
+// addNotesTable creates the timeline_notes table if it doesn't exist,
+// along with its audit triggers.
+func addNotesTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(notesSchema); err != nil {
+		return fmt.Errorf("couldn't create timeline notes table: %v", err)
+	}
+	return auditTable(tx, "timeline_notes")
+}
+
+// AddNote adds a note of the text on the date to the timeline.
+func AddNote(tx *sqlx.Tx, date time.Time, text string) (Note, error) {
+	n := Note{Date: dateOf(date), Text: strings.TrimSpace(text)}
+	if n.Text == "" {
+		return n, errors.New("note is empty")
+	}
+	if err := addNotesTable(tx); err != nil {
+		return n, err
+	}
+	const query = `INSERT INTO timeline_notes (date, note) VALUES ($1, $2)`
+	res, err := tx.Exec(query, n.Date.Format(dateFormat), n.Text)
+	if err != nil {
+		return n, fmt.Errorf("couldn't add note: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return n, err
+	}
+	n.ID = int(id)
+	return n, nil
+}
+
+// DeleteNote removes the note with the id from the timeline.
+func DeleteNote(tx *sqlx.Tx, id int) error {
+	if err := addNotesTable(tx); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM timeline_notes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("couldn't delete note: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("no note with id %d", id)
+	}
+	return nil
+}
+
+// NotesBetween returns the notes from the first date through the last,
+// in date order. Databases without notes return none.
+func NotesBetween(q sqlx.Queryer, first, last time.Time) ([]Note, error) {
+	var exists bool
+	const existsSQL = `
+		SELECT COUNT(*) > 0 FROM sqlite_master
+		WHERE type = 'table' AND name = 'timeline_notes'
+	`
+	if err := sqlx.Get(q, &exists, existsSQL); err != nil {
+		return nil, fmt.Errorf("couldn't look up timeline notes: %v", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var notes []Note
+	const query = `
+		SELECT id, date, note FROM timeline_notes
+		WHERE date BETWEEN $1 AND $2
+		ORDER BY date, id
+	`
+	if err := sqlx.Select(q, &notes, query, first.Format(dateFormat), last.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get notes: %v", err)
+	}
+	return notes, nil
+}
+
+// MarkNotes sets Notes on the entries to the notes of their day and of
+// the days since the entry before them, so that notes of days without
+// an entry aren't lost.
+func MarkNotes(db *sqlx.DB, entries *[]Entry) error {
+	if len(*entries) == 0 {
+		return nil
+	}
+	first, last := (*entries)[0].Date, (*entries)[len(*entries)-1].Date
+	notes, err := NotesBetween(db, first, last)
+	if err != nil {
+		return err
+	}
+	i := 0
+	for j := range *entries {
+		e := &(*entries)[j]
+		e.Notes = nil
+		for ; i < len(notes) && !notes[i].Date.After(dateOf(e.Date)); i++ {
+			e.Notes = append(e.Notes, notes[i])
+		}
+	}
+	return nil
+}
+
+// PrintNotes prints the notes on the timeline with their ids, oldest
+// first.
+func PrintNotes(db *sqlx.DB) error {
+	notes, err := NotesBetween(db, time.Time{}, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return err
+	}
+	if len(notes) == 0 {
+		fmt.Println(`No notes. Add one with "bite note add".`)
+		return nil
+	}
+	for _, n := range notes {
+		fmt.Printf("[%d] %s\n", n.ID, n)
+	}
+	return nil
+}
+
+// weekNotes returns the notes of the entries that fall in the week
+// starting on the day, as lines such as "Friday: \"started new job\"".
+func weekNotes(week []Entry, start time.Time) []string {
+	var lines []string
+	end := start.AddDate(0, 0, 7)
+	for _, e := range week {
+		for _, n := range e.Notes {
+			if n.Date.Before(start) || !n.Date.Before(end) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s: %q", weekdayName(n.Date.Weekday()), n.Text))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,53 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleMarkNotes() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	tx := db.MustBegin()
+	for _, n := range []struct {
+		date time.Time
+		text string
+	}{
+		{day(1), "started new job"},
+		{day(3), "flew to Denver"},
+		{day(9), "back home"},
+	} {
+		if _, err := AddNote(tx, n.date, n.text); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Nothing was logged on the 3rd, so its note goes with the 4th.
+	entries := []Entry{{Date: day(1)}, {Date: day(2)}, {Date: day(4)}, {Date: day(5)}}
+	if err := MarkNotes(db, &entries); err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		fmt.Println(e.Date.Format(dateFormat), e.Notes)
+	}
+
+	// The week of Monday the 26th shows both.
+	for _, l := range weekNotes(entries, startOfWeek(day(1))) {
+		fmt.Println(l)
+	}
+	// Output:
+	// 2024-03-01 [2024-03-01 started new job]
+	// 2024-03-02 []
+	// 2024-03-04 [2024-03-03 flew to Denver]
+	// 2024-03-05 []
+	// Friday: "started new job"
+	// Sunday: "flew to Denver"
+}
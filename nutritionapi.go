@@ -0,0 +1,303 @@
+package bite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Nutrition API providers.
+const (
+	ProviderCalorieNinjas = "calorieninjas"
+	ProviderNutritionix   = "nutritionix"
+)
+
+// NutritionAPI is an online nutrition database that foods missing from
+// the local database are searched for.
+type NutritionAPI struct {
+	Provider string // ProviderCalorieNinjas or ProviderNutritionix.
+	Key      string
+	AppID    string // Nutritionix only.
+	URL      string // Overrides the provider's endpoint.
+	Client   *http.Client
+}
+
+// RemoteFoods is the nutrition API searched when no local food matches,
+// or nil to only search the local database.
+var RemoteFoods *NutritionAPI
+
+// RemoteFood is a food found in a nutrition API. Its calories and
+// macros are for a serving of ServingSize grams.
+type RemoteFood struct {
+	Name        string
+	Brand       string
+	ServingSize float64 // g
+	Calories    float64
+	Protein     float64
+	Fat         float64
+	Carbs       float64
+}
+
+// Name returns the display name of the API's provider.
+func (a *NutritionAPI) Name() string {
+	switch a.Provider {
+	case ProviderCalorieNinjas:
+		return "CalorieNinjas"
+	case ProviderNutritionix:
+		return "Nutritionix"
+	}
+	return a.Provider
+}
+
+// Search returns the foods the API finds for the term.
+func (a *NutritionAPI) Search(term string) ([]RemoteFood, error) {
+	switch a.Provider {
+	case ProviderCalorieNinjas:
+		return a.searchCalorieNinjas(term)
+	case ProviderNutritionix:
+		return a.searchNutritionix(term)
+	}
+	return nil, fmt.Errorf("unknown nutrition API %q", a.Provider)
+}
+
+// searchCalorieNinjas searches the CalorieNinjas nutrition endpoint.
+func (a *NutritionAPI) searchCalorieNinjas(term string) ([]RemoteFood, error) {
+	endpoint := a.URL
+	if endpoint == "" {
+		endpoint = "https://api.calorieninjas.com/v1/nutrition"
+	}
+	req, err := http.NewRequest(http.MethodGet, endpoint+"?query="+url.QueryEscape(term), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Api-Key", a.Key)
+
+	var resp struct {
+		Items []struct {
+			Name        string  `json:"name"`
+			ServingSize float64 `json:"serving_size_g"`
+			Calories    float64 `json:"calories"`
+			Protein     float64 `json:"protein_g"`
+			Fat         float64 `json:"fat_total_g"`
+			Carbs       float64 `json:"carbohydrates_total_g"`
+		} `json:"items"`
+	}
+	if err := a.do(req, &resp); err != nil {
+		return nil, err
+	}
+	foods := make([]RemoteFood, 0, len(resp.Items))
+	for _, it := range resp.Items {
+		foods = append(foods, RemoteFood{
+			Name:        it.Name,
+			ServingSize: it.ServingSize,
+			Calories:    it.Calories,
+			Protein:     it.Protein,
+			Fat:         it.Fat,
+			Carbs:       it.Carbs,
+		})
+	}
+	return foods, nil
+}
+
+// searchNutritionix searches the Nutritionix natural language nutrients
+// endpoint.
+func (a *NutritionAPI) searchNutritionix(term string) ([]RemoteFood, error) {
+	endpoint := a.URL
+	if endpoint == "" {
+		endpoint = "https://trackapi.nutritionix.com/v2/natural/nutrients"
+	}
+	body, err := json.Marshal(map[string]string{"query": term})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-app-id", a.AppID)
+	req.Header.Set("x-app-key", a.Key)
+
+	var resp struct {
+		Foods []struct {
+			Name        string  `json:"food_name"`
+			Brand       string  `json:"brand_name"`
+			ServingSize float64 `json:"serving_weight_grams"`
+			Calories    float64 `json:"nf_calories"`
+			Protein     float64 `json:"nf_protein"`
+			Fat         float64 `json:"nf_total_fat"`
+			Carbs       float64 `json:"nf_total_carbohydrate"`
+		} `json:"foods"`
+	}
+	if err := a.do(req, &resp); err != nil {
+		return nil, err
+	}
+	foods := make([]RemoteFood, 0, len(resp.Foods))
+	for _, f := range resp.Foods {
+		foods = append(foods, RemoteFood{
+			Name:        f.Name,
+			Brand:       f.Brand,
+			ServingSize: f.ServingSize,
+			Calories:    f.Calories,
+			Protein:     f.Protein,
+			Fat:         f.Fat,
+			Carbs:       f.Carbs,
+		})
+	}
+	return foods, nil
+}
+
+// do sends the request and decodes the JSON response into v.
+func (a *NutritionAPI) do(req *http.Request, v interface{}) error {
+	client := a.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("couldn't reach %s: %v", a.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", a.Name(), resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("couldn't read %s response: %v", a.Name(), err)
+	}
+	return nil
+}
+
+// ImportRemoteFood adds a food found in a nutrition API to the local
+// database, so that it is found by later searches without the API, and
+// returns its id. Its data isn't verified, so it is saved as a user
+// food.
+func ImportRemoteFood(tx *sqlx.Tx, rf RemoteFood) (int, error) {
+	if rf.ServingSize <= 0 {
+		return 0, fmt.Errorf("%s has no serving size", rf.Name)
+	}
+	id, err := insertIndexedFood(tx, Food{
+		Name:        rf.Name,
+		ServingSize: rf.ServingSize,
+		ServingUnit: "g",
+		BrandName:   rf.Brand,
+		Source:      SourceUser,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := insertServingNutrients(tx, id, rf); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// insertIndexedFood inserts a food with its brand and adds it to the
+// full-text search index, which InsertFood leaves to the caller. It
+// returns the id of the food.
+func insertIndexedFood(tx *sqlx.Tx, food Food) (int, error) {
+	id, err := InsertFood(tx, food)
+	if err != nil {
+		return 0, err
+	}
+	if food.BrandName != "" {
+		if _, err := tx.Exec(`UPDATE foods SET brand_name = $1 WHERE food_id = $2`, food.BrandName, id); err != nil {
+			return 0, fmt.Errorf("couldn't set brand of %s: %v", food.Name, err)
+		}
+	}
+
+	const query = `
+		INSERT INTO foods_fts (food_id, food_name, brand_name)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(query, id, food.Name, food.BrandName); err != nil {
+		return 0, fmt.Errorf("couldn't index %s: %v", food.Name, err)
+	}
+	return id, nil
+}
+
+// insertServingNutrients inserts the calories and macros of a serving
+// of rf.ServingSize units of the food, which are stored per PortionSize
+// units.
+func insertServingNutrients(tx *sqlx.Tx, foodID int, rf RemoteFood) error {
+	per := PortionSize / rf.ServingSize
+	const query = `
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, n := range []struct {
+		id     int
+		amount float64
+	}{
+		{1003, rf.Protein},
+		{1004, rf.Fat},
+		{1005, rf.Carbs},
+		{1008, rf.Calories},
+	} {
+		if _, err := tx.Exec(query, foodID, n.id, n.amount*per, derivationIdPortion); err != nil {
+			return fmt.Errorf("couldn't insert nutrients of %s: %v", rf.Name, err)
+		}
+	}
+	return nil
+}
+
+// searchRemoteFoods offers to search RemoteFoods for the term when no
+// local food matches it, and imports the food the user picks. It
+// returns the name of the imported food, or false if nothing was
+// imported.
+func searchRemoteFoods(db *sqlx.DB, term string) (string, bool, error) {
+	if RemoteFoods == nil || strings.TrimSpace(term) == "" {
+		return "", false, nil
+	}
+	fmt.Printf("Search %s for %q? (y/n): ", RemoteFoods.Name(), term)
+	s, _ := Input.ReadString('\n')
+	if r := strings.ToLower(strings.TrimSpace(s)); r != "y" && r != "yes" {
+		return "", false, nil
+	}
+
+	foods, err := RemoteFoods.Search(term)
+	if err != nil {
+		return "", false, err
+	}
+	if len(foods) == 0 {
+		fmt.Printf("%s found no foods.\n", RemoteFoods.Name())
+		return "", false, nil
+	}
+	for i, f := range foods {
+		brand := ""
+		if f.Brand != "" {
+			brand = " (Brand: " + f.Brand + ")"
+		}
+		fmt.Printf("[%d] %s%s: %.0f cal, %sp %sc %sf per %g g\n", i+1, f.Name, brand, f.Calories,
+			FormatMacro(f.Protein), FormatMacro(f.Carbs), FormatMacro(f.Fat), f.ServingSize)
+	}
+	fmt.Print("Enter the index of the food to import [Press <Enter> to skip]: ")
+	s, _ = Input.ReadString('\n')
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || i < 1 || i > len(foods) {
+		return "", false, nil
+	}
+	f := foods[i-1]
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return "", false, err
+	}
+	defer tx.Rollback()
+	if _, err := ImportRemoteFood(tx, f); err != nil {
+		return "", false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return "", false, err
+	}
+	fmt.Printf("Imported %s from %s.\n", f.Name, RemoteFoods.Name())
+	return f.Name, true, nil
+}
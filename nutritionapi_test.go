@@ -0,0 +1,91 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleNutritionAPI_Search() {
+	ninjas := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, "bad key", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [{"name": %q, "serving_size_g": 50, "calories": 180,
+			"protein_g": 4, "fat_total_g": 2.5, "carbohydrates_total_g": 36}]}`, r.URL.Query().Get("query"))
+	}))
+	defer ninjas.Close()
+
+	nutritionix := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("x-app-id") != "app" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"foods": [{"food_name": "greek yogurt", "brand_name": "Fage",
+			"serving_weight_grams": 170, "nf_calories": 100, "nf_protein": 18,
+			"nf_total_fat": 0, "nf_total_carbohydrate": 6}]}`)
+	}))
+	defer nutritionix.Close()
+
+	for _, a := range []*NutritionAPI{
+		{Provider: ProviderCalorieNinjas, Key: "key", URL: ninjas.URL},
+		{Provider: ProviderNutritionix, Key: "key", AppID: "app", URL: nutritionix.URL},
+		{Provider: ProviderCalorieNinjas, Key: "wrong", URL: ninjas.URL},
+	} {
+		foods, err := a.Search("bagel")
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		for _, f := range foods {
+			fmt.Printf("%s: %s %q %g g, %.0f cal\n", a.Name(), f.Name, f.Brand, f.ServingSize, f.Calories)
+		}
+	}
+	// Output:
+	// CalorieNinjas: bagel "" 50 g, 180 cal
+	// Nutritionix: greek yogurt "Fage" 170 g, 100 cal
+	// CalorieNinjas returned 401 Unauthorized: bad key
+}
+
+func ExampleImportRemoteFood() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx := db.MustBegin()
+	id, err := ImportRemoteFood(tx, RemoteFood{
+		Name:        "Greek yogurt",
+		Brand:       "Fage",
+		ServingSize: 170,
+		Calories:    100,
+		Protein:     18,
+		Carbs:       6,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+
+	// The food is now found without the API.
+	foods, err := SearchFoods(db, "yogurt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range foods {
+		fmt.Printf("%t %s (%s) [%s]: %g %s\n", f.ID == id, f.Name, f.BrandName, f.Source, f.ServingSize, f.ServingUnit)
+	}
+
+	food, err := foodNutrition(db, id)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Per 100 g: %.1f cal, %.1fg protein\n", food.Calories, food.Protein)
+	// Output:
+	// true Greek yogurt (Fage) [user]: 170 g
+	// Per 100 g: 58.8 cal, 10.6g protein
+}
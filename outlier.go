@@ -0,0 +1,182 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A weight is implausible when it is further from the weights around it
+// than outlierBase of body weight, plus outlierPerDay for each day
+// between them, such as a 20 lb jump in a day or a finger slip.
+const (
+	outlierBase   = 0.02
+	outlierPerDay = 0.005
+)
+
+// outlierNearest is the number of weights nearest a weight that it is
+// compared with.
+const outlierNearest = 6
+
+// WeightOutlier is a logged weight implausibly far from the weights
+// around it.
+type WeightOutlier struct {
+	WeightEntry
+	Expected float64 // Median of the weights around it.
+}
+
+// String returns the outlier as its date, its weight, and the weight
+// expected.
+func (o WeightOutlier) String() string {
+	return fmt.Sprintf("%s: %s, expected about %s", o.Date.Format(dateFormat),
+		FormatWeight(o.Weight), FormatWeight(o.Expected))
+}
+
+// loggedWeights returns the weights that weren't estimated, oldest
+// first.
+func loggedWeights(tx *sqlx.Tx) ([]WeightEntry, error) {
+	if err := addWeightColumns(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT id, date, weight FROM daily_weights
+		WHERE estimated = 0
+		ORDER BY date, id
+	`
+	var ws []WeightEntry
+	if err := tx.Select(&ws, query); err != nil {
+		return nil, fmt.Errorf("couldn't get weights: %v", err)
+	}
+	return ws, nil
+}
+
+// expectedWeight returns the median of the outlierNearest weights
+// nearest the date, leaving out skip, and the days from the date to the
+// nearest of them. It returns false if there are none.
+func expectedWeight(ws []WeightEntry, date time.Time, skip int) (float64, float64, bool) {
+	var near []WeightEntry
+	for _, w := range ws {
+		if w.ID != skip {
+			near = append(near, w)
+		}
+	}
+	if len(near) == 0 {
+		return 0, 0, false
+	}
+	sort.SliceStable(near, func(i, j int) bool {
+		return math.Abs(daysBetween(date, near[i].Date)) < math.Abs(daysBetween(date, near[j].Date))
+	})
+	if len(near) > outlierNearest {
+		near = near[:outlierNearest]
+	}
+
+	values := make([]float64, len(near))
+	for i, w := range near {
+		values[i] = w.Weight
+	}
+	sort.Float64s(values)
+	median := values[len(values)/2]
+	if len(values)%2 == 0 {
+		median = (values[len(values)/2-1] + median) / 2
+	}
+	return median, math.Abs(daysBetween(date, near[0].Date)), true
+}
+
+// outlying reports whether the weight is implausibly far from the
+// expected weight of weights the days away.
+func outlying(weight, expected, days float64) bool {
+	return math.Abs(weight-expected) > expected*(outlierBase+outlierPerDay*days)
+}
+
+// CheckWeight returns the outlier the weight would be if logged on the
+// date, or nil if it is plausible.
+func CheckWeight(tx *sqlx.Tx, date time.Time, weight float64) (*WeightOutlier, error) {
+	ws, err := loggedWeights(tx)
+	if err != nil {
+		return nil, err
+	}
+	date = dateOf(date)
+	expected, days, ok := expectedWeight(ws, date, 0)
+	if !ok || !outlying(weight, expected, days) {
+		return nil, nil
+	}
+	return &WeightOutlier{WeightEntry{Date: date, Weight: weight}, expected}, nil
+}
+
+// WeightOutliers returns the logged weights implausibly far from the
+// weights around them.
+func WeightOutliers(tx *sqlx.Tx) ([]WeightOutlier, error) {
+	ws, err := loggedWeights(tx)
+	if err != nil {
+		return nil, err
+	}
+	var outliers []WeightOutlier
+	for _, w := range ws {
+		expected, days, ok := expectedWeight(ws, w.Date, w.ID)
+		if ok && outlying(w.Weight, expected, days) {
+			outliers = append(outliers, WeightOutlier{w, expected})
+		}
+	}
+	return outliers, nil
+}
+
+// confirmOutlier warns that the weight about to be logged is an outlier
+// and asks whether to log it anyway.
+func confirmOutlier(o *WeightOutlier) bool {
+	fmt.Println(paint(colorMissed, fmt.Sprintf("%s looks like a typo, the weights around it are about %s.",
+		FormatWeight(o.Weight), FormatWeight(o.Expected))))
+	fmt.Printf("Log it anyway? (y/n): ")
+	s, _ := Input.ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(s))
+	return a == "y" || a == "yes"
+}
+
+// CorrectOutliers prints each logged weight implausibly far from the
+// weights around it and asks the user to edit, delete, or keep it.
+func CorrectOutliers(db *sqlx.DB, u *UserInfo) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	outliers, err := WeightOutliers(tx)
+	tx.Rollback()
+	if err != nil {
+		return err
+	}
+	if len(outliers) == 0 {
+		fmt.Println("No outliers found.")
+		return nil
+	}
+
+	var edited, deleted int
+	for i, o := range outliers {
+		fmt.Printf("[%d/%d] %s\n", i+1, len(outliers), o)
+		fmt.Printf("(e)dit, (d)elete, (k)eep, or (q)uit: ")
+		s, _ := Input.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "e":
+			w, err := getWeight(u.System)
+			if err != nil {
+				return err
+			}
+			if err := updateWeightEntry(db, o.ID, w); err != nil {
+				return err
+			}
+			edited++
+		case "d":
+			if err := deleteOneWeightEntry(db, o.ID); err != nil {
+				return err
+			}
+			deleted++
+		case "q":
+			fmt.Printf("Edited %d and deleted %d weights.\n", edited, deleted)
+			return nil
+		}
+	}
+	fmt.Printf("Edited %d and deleted %d weights.\n", edited, deleted)
+	return nil
+}
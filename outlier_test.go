@@ -0,0 +1,48 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleWeightOutliers() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-03-01', '07:00:00', 181.2),
+			('2024-03-02', '07:00:00', 180.8),
+			('2024-03-03', '07:00:00', 1808),
+			('2024-03-04', '07:00:00', 180.4),
+			('2024-03-05', '07:00:00', 201.0),
+			('2024-03-06', '07:00:00', 179.6),
+			('2024-03-07', '07:00:00', 182.1),
+			('2024-03-20', '07:00:00', 176.5);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	outliers, err := WeightOutliers(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, o := range outliers {
+		fmt.Println(o)
+	}
+
+	// A weight about to be logged is checked against the ones before it.
+	for _, w := range []float64{179.0, 17.9} {
+		o, err := CheckWeight(tx, time.Date(2024, 3, 21, 7, 0, 0, 0, time.UTC), w)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(o)
+	}
+	// Output:
+	// 2024-03-03: 1808.0, expected about 181.0
+	// 2024-03-05: 201.0, expected about 181.0
+	// <nil>
+	// 2024-03-21: 17.9, expected about 181.2
+}
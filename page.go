@@ -0,0 +1,43 @@
+package bite
+
+import "fmt"
+
+// LogPageSize is the number of entries on a page of a log.
+const LogPageSize = 50
+
+// Page selects a page of a log's entries. Page 1 holds the most recent
+// entries.
+type Page struct {
+	// Number is the page number, starting at 1. Zero selects every
+	// entry.
+	Number int
+	Size   int
+}
+
+// limit returns the LIMIT and OFFSET of the page's entries, most recent
+// first. Every entry is selected by a limit of -1.
+func (p Page) limit() (int, int) {
+	if p.Number <= 0 {
+		return -1, 0
+	}
+	size := p.Size
+	if size <= 0 {
+		size = LogPageSize
+	}
+	return size, (p.Number - 1) * size
+}
+
+// printPageFooter prints which page of the total entries is shown and
+// how to see older entries.
+func printPageFooter(p Page, total int) {
+	size, _ := p.limit()
+	if size < 0 || total == 0 {
+		return
+	}
+	pages := (total + size - 1) / size
+	fmt.Printf("Page %d of %d.", p.Number, pages)
+	if p.Number < pages {
+		fmt.Printf(" Use --page %d for older entries, or --all.", p.Number+1)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,34 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleShowWeightLog() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '08:00:00', 180),
+			('2024-01-02', '08:00:00', 179.5),
+			('2024-01-03', '08:00:00', 179);
+	`)
+	defer db.Close()
+
+	for _, p := range []Page{{Number: 1, Size: 2}, {Number: 2, Size: 2}, {}} {
+		if err := ShowWeightLog(db, p); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	// Output:
+	// [1] 2024-01-03 179.0
+	// [2] 2024-01-02 179.5
+	// Page 1 of 2. Use --page 2 for older entries, or --all.
+	// [1] 2024-01-01 180.0
+	// Page 2 of 2.
+	// [1] 2024-01-03 179.0
+	// [2] 2024-01-02 179.5
+	// [3] 2024-01-01 180.0
+}
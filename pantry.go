@@ -0,0 +1,295 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// pantryLow is the fraction of a staple's par level at or below which
+// it's running low.
+const pantryLow = 0.25
+
+// PantryItem is the quantity on hand of a food, in its serving unit.
+type PantryItem struct {
+	FoodID int     `db:"food_id"`
+	Name   string  `db:"food_name"`
+	Unit   string  `db:"serving_unit"`
+	OnHand float64 `db:"on_hand"`
+	// Par is the quantity of a staple kept stocked, or zero for foods
+	// that aren't staples.
+	Par float64 `db:"par"`
+}
+
+// Staple reports whether the food is kept stocked.
+func (p PantryItem) Staple() bool {
+	return p.Par > 0
+}
+
+// Low reports whether the food is a staple running out.
+func (p PantryItem) Low() bool {
+	return p.Staple() && p.OnHand <= p.Par*pantryLow
+}
+
+// ToBuy returns the quantity that restocks the staple to its par level.
+func (p PantryItem) ToBuy() float64 {
+	if p.OnHand >= p.Par {
+		return 0
+	}
+	return p.Par - p.OnHand
+}
+
+// String describes the quantity on hand, such as "Oats: 120 g (keep
+// 500 g, low)".
+func (p PantryItem) String() string {
+	s := fmt.Sprintf("%s: %s %s", p.Name, formatNumber(p.OnHand, 0), p.Unit)
+	if !p.Staple() {
+		return s
+	}
+	notes := []string{fmt.Sprintf("keep %s %s", formatNumber(p.Par, 0), p.Unit)}
+	if p.Low() {
+		notes = append(notes, "low")
+	}
+	return s + " (" + strings.Join(notes, ", ") + ")"
+}
+
+// pantrySchema creates the pantry table in databases made before it
+// existed.
+const pantrySchema = `
+	CREATE TABLE IF NOT EXISTS pantry (
+		food_id INTEGER PRIMARY KEY REFERENCES foods(food_id) ON DELETE CASCADE,
+		on_hand REAL DEFAULT 0 NOT NULL,
+		par REAL DEFAULT 0 NOT NULL
+	)
+`
+
+// addPantryTable creates the pantry table if it doesn't exist, along
+// with its audit triggers.
+func addPantryTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(pantrySchema); err != nil {
+		return fmt.Errorf("couldn't create pantry table: %v", err)
+	}
+	return auditTable(tx, "pantry")
+}
+
+// StockPantry adds the quantity of a food to the pantry, or sets the
+// quantity on hand to it if set is true.
+func StockPantry(tx *sqlx.Tx, foodID int, quantity float64, set bool) error {
+	if err := addPantryTable(tx); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO pantry (food_id, on_hand) VALUES ($1, MAX($2, 0))
+		ON CONFLICT (food_id) DO UPDATE SET on_hand = MAX(on_hand + excluded.on_hand, 0)
+	`
+	if set {
+		query = `
+			INSERT INTO pantry (food_id, on_hand) VALUES ($1, MAX($2, 0))
+			ON CONFLICT (food_id) DO UPDATE SET on_hand = excluded.on_hand
+		`
+	}
+	if _, err := tx.Exec(query, foodID, quantity); err != nil {
+		return fmt.Errorf("couldn't stock pantry: %v", err)
+	}
+	return nil
+}
+
+// SetPantryPar makes a food a staple kept stocked at the par quantity.
+// Zero makes it no longer a staple.
+func SetPantryPar(tx *sqlx.Tx, foodID int, par float64) error {
+	if par < 0 {
+		return fmt.Errorf("par quantity can't be negative, got %v", par)
+	}
+	if err := addPantryTable(tx); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO pantry (food_id, par) VALUES ($1, $2)
+		ON CONFLICT (food_id) DO UPDATE SET par = excluded.par
+	`
+	if _, err := tx.Exec(query, foodID, par); err != nil {
+		return fmt.Errorf("couldn't set pantry par: %v", err)
+	}
+	return nil
+}
+
+// usePantry takes the quantity of a logged food out of the pantry. Foods
+// not in the pantry, and databases without one, are left alone.
+func usePantry(tx *sqlx.Tx, foodID int, quantity float64) error {
+	var exists bool
+	const existsSQL = `
+		SELECT COUNT(*) > 0 FROM sqlite_master
+		WHERE type = 'table' AND name = 'pantry'
+	`
+	if err := tx.Get(&exists, existsSQL); err != nil {
+		return fmt.Errorf("couldn't look up pantry: %v", err)
+	}
+	if !exists {
+		return nil
+	}
+	const query = `UPDATE pantry SET on_hand = MAX(on_hand - $1, 0) WHERE food_id = $2`
+	if _, err := tx.Exec(query, quantity, foodID); err != nil {
+		return fmt.Errorf("couldn't update pantry: %v", err)
+	}
+	return nil
+}
+
+// Pantry returns the foods in the pantry by name.
+func Pantry(tx *sqlx.Tx) ([]PantryItem, error) {
+	if err := addPantryTable(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT p.food_id, f.food_name, f.serving_unit, p.on_hand, p.par
+		FROM pantry p
+		INNER JOIN foods f ON f.food_id = p.food_id
+		ORDER BY f.food_name
+	`
+	var items []PantryItem
+	if err := tx.Select(&items, query); err != nil {
+		return nil, fmt.Errorf("couldn't get pantry: %v", err)
+	}
+	return items, nil
+}
+
+// LowStaples returns the staples running out, by name.
+func LowStaples(tx *sqlx.Tx) ([]PantryItem, error) {
+	items, err := Pantry(tx)
+	if err != nil {
+		return nil, err
+	}
+	var low []PantryItem
+	for _, p := range items {
+		if p.Low() {
+			low = append(low, p)
+		}
+	}
+	return low, nil
+}
+
+// GroceryList returns the grocery list of the staples running out, one
+// line each with the quantity that restocks it.
+func GroceryList(tx *sqlx.Tx) ([]string, error) {
+	low, err := LowStaples(tx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]string, len(low))
+	for i, p := range low {
+		list[i] = fmt.Sprintf("%s, %s %s", p.Name, formatNumber(p.ToBuy(), 0), p.Unit)
+	}
+	return list, nil
+}
+
+// PrintPantry prints the foods in the pantry, or only the staples
+// running out if low is true.
+func PrintPantry(db *sqlx.DB, low bool) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var items []PantryItem
+	if low {
+		items, err = LowStaples(tx)
+	} else {
+		items, err = Pantry(tx)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		if low {
+			fmt.Println("No staples running low.")
+		} else {
+			fmt.Println("The pantry is empty.")
+		}
+		return nil
+	}
+	for _, p := range items {
+		fmt.Println(p)
+	}
+	return nil
+}
+
+// PrintGroceryList prints the grocery list of the staples running out.
+func PrintGroceryList(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	list, err := GroceryList(tx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Println("Nothing to buy.")
+		return nil
+	}
+	for _, item := range list {
+		fmt.Println("- [ ] " + item)
+	}
+	return nil
+}
+
+// StockFood lets the user select a food and adds the quantity of it to
+// the pantry, or sets the quantity on hand if set is true.
+func StockFood(db *sqlx.DB, quantity float64, set bool) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := StockPantry(tx, food.ID, quantity, set); err != nil {
+		return err
+	}
+	var onHand float64
+	if err := tx.Get(&onHand, `SELECT on_hand FROM pantry WHERE food_id = $1`, food.ID); err != nil {
+		return fmt.Errorf("couldn't get pantry: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("%s on hand: %s %s.\n", food.Name, formatNumber(onHand, 0), food.ServingUnit)
+	return nil
+}
+
+// FlagStaple lets the user select a food and keeps it stocked at the
+// par quantity. Zero makes it no longer a staple.
+func FlagStaple(db *sqlx.DB, par float64) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := SetPantryPar(tx, food.ID, par); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if par == 0 {
+		fmt.Printf("%s is no longer a staple.\n", food.Name)
+		return nil
+	}
+	fmt.Printf("%s is a staple kept at %s %s.\n", food.Name, formatNumber(par, 0), food.ServingUnit)
+	return nil
+}
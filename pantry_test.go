@@ -0,0 +1,57 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleGroceryList() {
+	db := dbtest.MustNew(``)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	for _, p := range []struct {
+		id          int
+		onHand, par float64
+	}{{1, 150, 500}, {2, 200, 0}, {3, 1000, 1180}} {
+		if err := StockPantry(tx, p.id, p.onHand, false); err != nil {
+			log.Fatal(err)
+		}
+		if err := SetPantryPar(tx, p.id, p.par); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Two servings of oats are eaten, and more than the chicken on hand.
+	date := time.Date(2024, 3, 18, 8, 0, 0, 0, time.UTC)
+	foods := []Food{
+		{ID: 1, Name: "Oats", ServingSize: 40, NumberOfServings: 2, FoodMacros: &FoodMacros{}},
+		{ID: 2, Name: "Chicken breast", ServingSize: 100, NumberOfServings: 3, FoodMacros: &FoodMacros{}},
+	}
+	if err := AddFoodEntries(tx, foods, date); err != nil {
+		log.Fatal(err)
+	}
+
+	items, err := Pantry(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range items {
+		fmt.Println(p)
+	}
+	list, err := GroceryList(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(list)
+
+	// Output:
+	// Banana: 1000 g (keep 1180 g)
+	// Chicken breast: 0 g
+	// Oats: 70 g (keep 500 g, low)
+	// [Oats, 430 g]
+}
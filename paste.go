@@ -0,0 +1,170 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PasteItem is a line of a pasted list of foods and the food it
+// matched.
+type PasteItem struct {
+	Line string
+	Food *Food // Nil when the line couldn't be matched.
+	Err  error // Why the line couldn't be matched.
+}
+
+// readPaste reads the lines of a pasted list of foods up to the first
+// blank line after the list, or the end of the input.
+func readPaste(r *bufio.Reader) ([]string, error) {
+	var lines []string
+	for {
+		s, err := r.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("couldn't read pasted foods: %v", err)
+		}
+		s = strings.TrimSpace(s)
+		// Skip list markers from notes apps.
+		s = strings.TrimSpace(strings.TrimLeft(s, "-*•"))
+		if s != "" {
+			lines = append(lines, s)
+		} else if len(lines) > 0 && err == nil {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, nil
+		}
+	}
+}
+
+// matchPaste parses each line in "qty unit name" form and matches it to
+// a food without asking the user, taking the best search result when
+// the name is ambiguous. Lines that can't be parsed or matched keep the
+// reason in Err.
+func matchPaste(db *sqlx.DB, lines []string) ([]PasteItem, error) {
+	items := make([]PasteItem, len(lines))
+	for i, line := range lines {
+		items[i].Line = line
+		it, err := ParseQuickItem(line)
+		if err != nil {
+			items[i].Err = err
+			continue
+		}
+		foods, err := searchQuickFood(db, it.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(foods) == 0 {
+			items[i].Err = fmt.Errorf("no foods match %q", it.Name)
+			continue
+		}
+		f, ok := matchFood(foods, it.Name)
+		if !ok {
+			f = foods[0]
+		}
+		if it, err = applyCooked(db, f, it); err != nil {
+			return nil, err
+		}
+		if err := applyQuickItem(&f, it); err != nil {
+			items[i].Err = err
+			continue
+		}
+		items[i].Food = &f
+	}
+	return items, nil
+}
+
+// printPastePreview prints the food each line matched, the lines that
+// didn't match, and the totals of the matched foods.
+func printPastePreview(items []PasteItem) {
+	var cals, protein, fat, carbs, price float64
+	fmt.Printf("%-3s %-30s %-30s %8s\n", "#", "Line", "Food", "Calories")
+	for i, it := range items {
+		if it.Food == nil {
+			fmt.Printf("%-3d %-30s %v\n", i+1, it.Line, it.Err)
+			continue
+		}
+		f := it.Food
+		fmt.Printf("%-3d %-30s %-30s %8.0f\n", i+1, it.Line, quickLabel(*f), f.Calories)
+		cals += f.Calories
+		protein += f.FoodMacros.Protein
+		fat += f.FoodMacros.Fat
+		carbs += f.FoodMacros.Carbs
+		price += f.Price
+	}
+	fmt.Printf("Total: %.0f cals, %sg protein, %sg fat, %sg carbs, $%.2f\n",
+		cals, FormatMacro(protein), FormatMacro(fat), FormatMacro(carbs), price)
+}
+
+// PasteLog reads a pasted list of foods, one per line in "qty unit
+// name" form, previews the matched foods and their totals, and logs
+// them at the given date once the user confirms. Lines that don't match
+// a food are skipped. With confirmed set, it logs without asking.
+func PasteLog(db *sqlx.DB, r io.Reader, date time.Time, confirmed bool) error {
+	br := bufio.NewReader(r)
+	fmt.Println("Paste foods, one per line, then enter a blank line:")
+	lines, err := readPaste(br)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		fmt.Println("No foods pasted.")
+		return nil
+	}
+
+	items, err := matchPaste(db, lines)
+	if err != nil {
+		return err
+	}
+	printPastePreview(items)
+
+	var foods []Food
+	for _, it := range items {
+		if it.Food != nil {
+			foods = append(foods, *it.Food)
+		}
+	}
+	if len(foods) == 0 {
+		fmt.Println("No foods matched.")
+		return nil
+	}
+	if err := warnRestricted(db, foods); err != nil {
+		return err
+	}
+
+	if !confirmed {
+		fmt.Printf("Log %d foods? (y/n): ", len(foods))
+		s, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("couldn't read confirmation: %v", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "y", "yes":
+		case "":
+			if errors.Is(err, io.EOF) {
+				fmt.Println("\nNothing logged. Pass --yes to log piped foods without confirming.")
+				return nil
+			}
+			fallthrough
+		default:
+			fmt.Println("Nothing logged.")
+			return nil
+		}
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := AddFoodEntries(tx, foods, date); err != nil {
+		return err
+	}
+	fmt.Printf("Logged %d foods.\n", len(foods))
+	return tx.Commit()
+}
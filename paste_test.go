@@ -0,0 +1,32 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func ExamplePasteLog() {
+	r := bufio.NewReader(strings.NewReader("\n- 2 eggs\n40g oats\n\ny\n"))
+	lines, err := readPaste(r)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%q\n", lines)
+
+	egg := &Food{Name: "Egg", ServingUnit: "g", ServingSize: 50, NumberOfServings: 2, Calories: 143,
+		FoodMacros: &FoodMacros{Protein: 12.6, Fat: 9.5, Carbs: 0.7}, Price: 0.5}
+	printPastePreview([]PasteItem{
+		{Line: lines[0], Food: egg},
+		{Line: lines[1], Err: errors.New(`no foods match "oats"`)},
+	})
+
+	// Output:
+	// ["2 eggs" "40g oats"]
+	// #   Line                           Food                           Calories
+	// 1   2 eggs                         2 x 50 g Egg                        143
+	// 2   40g oats                       no foods match "oats"
+	// Total: 143 cals, 12.6g protein, 9.5g fat, 0.7g carbs, $0.50
+}
@@ -2,12 +2,10 @@
 package bite
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"log"
 	"math"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -36,11 +34,20 @@ const (
 	defaultCutWeeklyChangePct                          = -0.005 // -0.5% of bodyweight per week.
 	defaultBulkWeeklyChangePct                         = 0.0025 // +0.25% of bodyweight per week.
 	dateFormat                                         = "2006-01-02"
-	colorReset                                         = "\033[0m"
-	colorItalic                                        = "\033[3m"
-	colorRed                                           = "\033[31m"
-	colorGreen                                         = "\033[32m"
-	colorUnderline                                     = "\033[4m"
+)
+
+var (
+	// DayCalTolerance is the fraction of the goal calories that a
+	// maintenance day may be off by and still meet the daily goal.
+	DayCalTolerance = 0.05
+
+	// WeekAdherence is the fraction of days in a week that must meet the
+	// daily calorie goal for the week to meet its goal.
+	WeekAdherence = 0.7
+
+	// CalorieFloor is the lowest daily calorie goal that missed weeks
+	// lower the goal to. Zero means the user's BMR.
+	CalorieFloor float64
 )
 
 type PhaseInfo struct {
@@ -50,6 +57,10 @@ type PhaseInfo struct {
 	GoalCalories float64 `db:"goal_calories"`
 	StartWeight  float64 `db:"start_weight"`
 	GoalWeight   float64 `db:"goal_weight"`
+	// GoalWeightEnd is the far end of a goal weight range, such as 172
+	// for a cut to 172-175 lbs, where GoalWeight is the end nearer the
+	// start weight (175). It is zero when the goal is a single weight.
+	GoalWeightEnd float64 `db:"goal_weight_end"`
 	// WeightChangeThreshold is used to ensure the user has not
 	// lost/gained too much weight for a given diet phase.
 	// If the user chooses to continue the current diet phase,
@@ -65,6 +76,27 @@ type PhaseInfo struct {
 	MaxDuration     float64   `db:"max_duration"`
 	MinDuration     float64   `db:"min_duration"`
 	Status          string    `db:"status"`
+	// TrainingCalories are the extra calories eaten on training days.
+	// They are taken from the rest days, given TrainingDays training
+	// days per week.
+	TrainingCalories float64 `db:"training_calories"`
+	TrainingDays     int     `db:"training_days"`
+	// TargetMode is set for phases planned to reach the goal weight by
+	// the end date. The weekly change is recalculated each week from the
+	// actual progress.
+	TargetMode bool `db:"target_mode"`
+	// TargetWeek is the last phase week the target plan was checked in.
+	TargetWeek int `db:"target_week"`
+	// ManualCalories is the calorie goal the user set by hand, or zero
+	// if the goal wasn't. Adjustments of it are resolved by
+	// ManualGoalPolicy.
+	ManualCalories float64 `db:"manual_calories"`
+	// Breaks are the phase's diet breaks. They are loaded when checking
+	// progress.
+	Breaks []DietBreak `db:"-"`
+	// Recommendations are the refeed days and diet breaks recommended
+	// during the phase. They are loaded when checking progress.
+	Recommendations []Recommendation `db:"-"`
 }
 
 // CheckProgress performs checks on the user's current diet phase.
@@ -81,6 +113,19 @@ func CheckProgress(db *sqlx.DB, u *UserInfo, entries *[]Entry) error {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
+	// End the diet break if it is over.
+	if err := checkDietBreaks(tx, u, LogNow()); err != nil {
+		return err
+	}
+	if err := loadRecommendations(tx, u); err != nil {
+		return err
+	}
+
+	// Recommend a diet break if the user's check-ins are poor.
+	if err := checkCheckIns(tx, u); err != nil {
+		return err
+	}
+
 	// Make a map to track the numbers of entries in each week.
 	entryCountPerWeek, err := countEntriesPerWeek(u, entries)
 	if err != nil {
@@ -99,83 +144,137 @@ func CheckProgress(db *sqlx.DB, u *UserInfo, entries *[]Entry) error {
 
 	switch u.Phase.Name {
 	case "cut":
-		var total float64
-		var status WeightLossStatus
-
 		err := checkCutThreshold(tx, u) // Ensure user hasn't lost too much weight.
 		if err != nil {
 			return err
 		}
 
-		status, total, err = checkCutLoss(tx, u, entries) // Ensure weekly weight loss.
-		if err != nil {
+		// Ensure weekly weight loss.
+		if _, err := checkWeeklyChange(tx, u, entries); err != nil {
 			return err
 		}
 
-		switch status {
-		case lostTooLittle:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			addCals(u, total)
-		case lostTooMuch:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			removeCals(u, total)
-		case withinLossRange: // Do nothing
+		// Recommend a refeed day or diet break after a long stretch of
+		// sticking to the cut.
+		if err := checkRefeed(tx, u, *entries, LogNow()); err != nil {
+			return err
 		}
 	case "maintain":
-		status, total, err := checkMaintenance(tx, u, entries) // Ensure maintenance.
-		if err != nil {
+		// Ensure maintenance.
+		if _, err := checkWeeklyChange(tx, u, entries); err != nil {
 			return err
 		}
-
-		switch status {
-		case lost:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			addCals(u, total)
-		case gained:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			removeCals(u, total)
-		case maintained: // Do nothing
-		}
 	case "bulk":
-		var total float64
-		var status WeightGainStatus
-
 		err := checkBulkThreshold(tx, u) // Ensure user hasn't gained too much weight.
 		if err != nil {
 			return err
 		}
 
-		status, total, err = checkBulkGain(tx, u, entries) // Ensure weekly weight gain.
-		if err != nil {
+		// Ensure weekly weight gain.
+		if _, err := checkWeeklyChange(tx, u, entries); err != nil {
 			return err
 		}
+	}
 
-		switch status {
-		case gainedTooLittle:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			addCals(u, total)
-		case gainedTooMuch:
-			fmt.Printf("The weekly weight gain goal of %f has not been met for two consecutive weeks.", u.Phase.WeeklyChange)
-			removeCals(u, total)
-		case withinGainRange: // Do nothing
-		}
+	// Flag a plateau in the trend weight despite sticking to the
+	// calorie goal.
+	if err := checkPlateau(tx, u, *entries, LogNow()); err != nil {
+		return err
+	}
+
+	// Keep a target date plan on track with the actual progress.
+	if err := checkTarget(tx, u, *entries, LogNow()); err != nil {
+		return err
+	}
+
+	if err := recordSafetyOverrides(tx, u); err != nil {
+		return err
 	}
 
 	return tx.Commit()
 }
 
+// checkWeeklyChange checks the weeks of the diet phase from the last
+// checked week for two weeks in a row off the weekly change goal. If it
+// finds them, it adjusts the calorie goal to get back on track, records
+// why, and returns true.
+func checkWeeklyChange(tx *sqlx.Tx, u *UserInfo, entries *[]Entry) (bool, error) {
+	var add, remove bool
+	var total float64
+	switch u.Phase.Name {
+	case "cut":
+		status, t, err := checkCutLoss(tx, u, entries)
+		if err != nil {
+			return false, err
+		}
+		add, remove, total = status == lostTooLittle, status == lostTooMuch, t
+	case "maintain":
+		status, t, err := checkMaintenance(tx, u, entries)
+		if err != nil {
+			return false, err
+		}
+		add, remove, total = status == lost, status == gained, t
+	case "bulk":
+		status, t, err := checkBulkGain(tx, u, entries)
+		if err != nil {
+			return false, err
+		}
+		add, remove, total = status == gainedTooLittle, status == gainedTooMuch, t
+	}
+	if !add && !remove {
+		return false, nil
+	}
+
+	// The weeks are checked until two in a row miss the goal.
+	a := Adjustment{
+		PhaseID:      u.Phase.PhaseID,
+		Date:         u.Phase.LastCheckedWeek,
+		Weeks:        2,
+		WeightChange: total / 2,
+		WeeklyChange: u.Phase.WeeklyChange,
+		OldCalories:  u.Phase.GoalCalories,
+	}
+	macros := u.Macros
+	if add {
+		addCals(u, total)
+	} else {
+		removeCals(u, total)
+	}
+	if !resolveManualGoal(u, a.OldCalories, macros) {
+		return false, nil
+	}
+	a.NewCalories = u.Phase.GoalCalories
+	if a.NewCalories == a.OldCalories {
+		return false, nil
+	}
+	a.Protein = u.Macros.Protein - macros.Protein
+	a.Carbs = u.Macros.Carbs - macros.Carbs
+	a.Fats = u.Macros.Fats - macros.Fats
+	if err := recordAdjustment(tx, &a); err != nil {
+		return false, err
+	}
+	fmt.Printf("Adjusted the calorie goal from %.0f to %.0f: %s. See \"bite summary adjustments\".\n",
+		a.OldCalories, a.NewCalories, a)
+
+	// Save the new calorie goal, which records it as this week's target.
+	if err := saveUserInfo(tx, u); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // countEntriesPerWeek returns a map to tracker the number of entires in
 // each weeks of a diet phase.
 func countEntriesPerWeek(u *UserInfo, entries *[]Entry) (*map[int]int, error) {
 	entryCountPerWeek := make(map[int]int)
 	weekNumber := 0
 
-	// Get the first day and the upcoming Sunday
+	// Get the first day and the last day of its week.
 	firstDay := u.Phase.StartDate
-	firstSunday := firstDay.AddDate(0, 0, (int)(7-firstDay.Weekday())%7)
+	firstWeekEnd := startOfWeek(firstDay).AddDate(0, 0, 6)
 
 	// Count entries in the first (partial) week
-	entryCount, err := countEntriesInWeek(entries, firstDay, firstSunday)
+	entryCount, err := countEntriesInWeek(entries, firstDay, firstWeekEnd)
 	if err != nil {
 		return nil, err
 	}
@@ -183,7 +282,7 @@ func countEntriesPerWeek(u *UserInfo, entries *[]Entry) (*map[int]int, error) {
 	weekNumber++
 
 	// For subsequent weeks,
-	for date := firstSunday.AddDate(0, 0, 1); date.Before(u.Phase.EndDate) || isSameDay(date, u.Phase.EndDate); date = date.AddDate(0, 0, 7) {
+	for date := firstWeekEnd.AddDate(0, 0, 1); date.Before(u.Phase.EndDate) || isSameDay(date, u.Phase.EndDate); date = date.AddDate(0, 0, 7) {
 		weekStart := date
 		weekEnd := date.AddDate(0, 0, 6)
 
@@ -304,7 +403,7 @@ func getCutAction() string {
 
 		err := validateAction(option)
 		if err != nil {
-			fmt.Println("Invalid action. Please try again.")
+			fmt.Println(tr("Invalid action. Please try again."))
 			continue
 		}
 
@@ -373,9 +472,18 @@ func checkCutLoss(tx *sqlx.Tx, u *UserInfo, entries *[]Entry) (WeightLossStatus,
 }
 
 // validWeek determines if a given week fits the definition of a
-// week, retrives total change in weight, and array of calories for
-// the given week.
-func validWeek(tx *sqlx.Tx, entries *[]Entry, weekStart, weekEnd time.Time, u *UserInfo) (bool, float64, []float64, error) {
+// week, retrives total change in weight, and the entries of the given
+// week.
+func validWeek(tx *sqlx.Tx, entries *[]Entry, weekStart, weekEnd time.Time, u *UserInfo) (bool, float64, []Entry, error) {
+	// Weeks with diet break days aren't evaluated.
+	if u.Phase.onBreak(weekStart, weekEnd) {
+		return false, 0, nil, nil
+	}
+	// Nor are weeks with water retention from the menstrual cycle.
+	if Cycle.overlaps(weekStart, weekEnd) {
+		return false, 0, nil, nil
+	}
+
 	// Does this week contain has at least `minEntriesPerWeek` entries?
 	entryCount, err := countEntriesInWeek(entries, weekStart, weekEnd)
 	if err != nil || entryCount < minEntriesPerWeek {
@@ -388,15 +496,15 @@ func validWeek(tx *sqlx.Tx, entries *[]Entry, weekStart, weekEnd time.Time, u *U
 		return false, 0, nil, err
 	}
 
-	// Get array of calories for given week.
-	dailyCalories, err := getCalsWeek(entries, weekStart, weekEnd)
+	// Get the entries of the given week.
+	weekEntries, err := getWeekEntries(entries, weekStart, weekEnd)
 	if err != nil {
 		log.Println(err)
 		return false, 0, nil, err
 	}
 
 	// Did the user adhere to the daily calorie goal for this week?
-	valid = metWeeklyCalGoal(u, dailyCalories)
+	valid = metWeeklyCalGoal(u, weekEntries)
 	if !valid {
 		return false, 0, nil, nil
 	}
@@ -413,17 +521,15 @@ func validWeek(tx *sqlx.Tx, entries *[]Entry, weekStart, weekEnd time.Time, u *U
 	}
 	log.Println("Updated last checked week to:", weekEnd)
 
-	return true, totalWeekWeightChange, dailyCalories, nil
+	return true, totalWeekWeightChange, weekEntries, nil
 }
 
-// getCalsWeek returns an float64 array containing calorie count for
-// each day in a given week.
+// getWeekEntries returns the entries for each logged day in a given
+// week.
 //
 // Assumptions:
 // * Given week has at least `minEntriesPerWeek` entries.
-func getCalsWeek(entries *[]Entry, weekStart, WeekEnd time.Time) ([]float64, error) {
-	var calsWeek []float64
-
+func getWeekEntries(entries *[]Entry, weekStart, WeekEnd time.Time) ([]Entry, error) {
 	// Get the dataframe index of the entry with the start date of the
 	// diet.
 	startIdx, err := findEntryIdx(entries, weekStart)
@@ -443,27 +549,20 @@ func getCalsWeek(entries *[]Entry, weekStart, WeekEnd time.Time) ([]float64, err
 		return nil, fmt.Errorf("ERROR: Given week has less than %d entries.\n", minEntriesPerWeek)
 	}
 
-	// Iterate over each day of the week starting from startIdx.
-	for i := startIdx; i < endIdx; i++ {
-		// Get entry calories.
-		cal := (*entries)[i].Calories
-		calsWeek = append(calsWeek, cal) // Append recorded daily calorie.
-	}
-
-	return calsWeek, nil
+	return (*entries)[startIdx:endIdx], nil
 }
 
 // metWeeklyCalGoal calculates whether the user met their daily calorie
-// goal on at least 70% of the days in the week.
-func metWeeklyCalGoal(u *UserInfo, dailyCalories []float64) bool {
+// goal on at least WeekAdherence (70% by default) of the days in the
+// week.
+func metWeeklyCalGoal(u *UserInfo, days []Entry) bool {
 	daysMetGoal := 0
-	for _, cal := range dailyCalories {
-		if metCalDayGoal(u, cal) {
+	for _, e := range days {
+		if metEntryGoal(u, e) {
 			daysMetGoal++
 		}
 	}
-	// Compare to 70% of the week.
-	return float64(daysMetGoal)/float64(len(dailyCalories)) >= 0.7
+	return float64(daysMetGoal)/float64(len(days)) >= WeekAdherence
 }
 
 // metWeeklyGoalCut checks to see if a given week has met the weekly
@@ -488,7 +587,9 @@ func metWeeklyGoalCut(u *UserInfo, totalWeekWeightChange float64) WeightLossStat
 // to apply that deficit though first cutting fats, then carbs, and
 // finally protein.
 //
-// The deficit will be applied up to the minimmum macro values.
+// The deficit will be applied up to the minimmum macro values. The
+// calorie goal isn't lowered past the calorie floor; other ways to
+// keep progressing are recommended instead.
 func removeCals(u *UserInfo, totalWeekWeightChange float64) {
 
 	diff := totalWeekWeightChange - u.Phase.WeeklyChange
@@ -501,73 +602,58 @@ func removeCals(u *UserInfo, totalWeekWeightChange float64) {
 	// Set deficit
 	deficit := avgDayWeightChangeCals
 
-	// Update calorie goal.
-	u.Phase.GoalCalories -= deficit
-	fmt.Printf("Reducing caloric deficit by %.2f calories.\n", deficit)
-	fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
-
-	// Convert caloric deficit to fats in grams.
-	fatDeficit := deficit * calsInFats
-
-	// If the fat deficit is greater than or equal to the available fats
-	// left (up to minimum fats limit), then apply the defict
-	// exclusively through removing fats.
-	if fatDeficit >= (u.Macros.Fats - u.Macros.MinFats) {
-		u.Macros.Fats -= u.Macros.MinFats
-		return
-	}
-	// Otherwise, there are not enough fats to competely apply the deficit, so remove the remaining fats.
-	// Remove fats up to the minimum fats limit.
-	remainingInFats := u.Macros.MinFats - deficit
-	u.Macros.Fats -= deficit - remainingInFats
-
-	// Convert the remaining fats in grams to calories.
-	remainingInCals := remainingInFats * calsInFats
-	// Convert the remaining calories to carbs in grams.
-	carbDeficit := remainingInCals / calsInCarbs
-
-	// If carb deficit is greater than or equal to the availiable carbs
-	// left (up to minimum carbs limit), then apply the remaining
-	// deficit by removing carbs.
-	if carbDeficit >= (u.Macros.Carbs - u.Macros.MinCarbs) {
-		u.Macros.Carbs -= u.Macros.MinCarbs
-		return
+	// Stop at the calorie floor.
+	goal := u.Phase.GoalCalories - deficit
+	if floor := calorieFloor(u); goal < floor {
+		printFloorAdvice(floor)
+		if u.Phase.GoalCalories <= floor {
+			return
+		}
+		goal = floor
+		deficit = u.Phase.GoalCalories - floor
 	}
-	// Otherwise, remove fats up to the minimum carbs limit.
-	remainingInCarbs := u.Macros.MinCarbs - carbDeficit
-	u.Macros.Carbs -= carbDeficit - remainingInCarbs
 
-	// Set protein deficit in grams to the carbs that could not be
-	// removed.
-	// Note: calsInCarbs = calsInProtein.
-	proteinDeficit := remainingInCals
-
-	// If protein deficit is greater than or equal to the availiable
-	// protein left (up to minimum protein limit), then apply the
-	// remaining deficit by removing protein.
-	if proteinDeficit >= (u.Macros.Protein - u.Macros.MinProtein) {
-		u.Macros.Protein -= u.Macros.MinProtein
+	// Ensure the new calorie goal is safe, or the user overrides it.
+	if !confirmSafety(u, (goal-u.TDEE)*7/calsPerPound, goal) {
+		fmt.Printf("Keeping calorie goal at %.2f.\n", u.Phase.GoalCalories)
 		return
 	}
-	// Otherwise, remove protein up to the minimum protein limit.
-	remainingInProtein := u.Macros.MinProtein - proteinDeficit
-	u.Macros.Protein -= proteinDeficit - remainingInProtein
 
-	// Convert the remaining protein in grams to calories.
-	remaining := remainingInProtein * calsInProtein
+	// Update calorie goal.
+	u.Phase.GoalCalories = goal
+	fmt.Printf("Reducing caloric deficit by %.2f calories.\n", deficit)
+	fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
+
+	// Remove the deficit from the macros.
+	m, left := AdjustMacros(u.Macros, -deficit)
+	u.Macros = m
 
-	// If the remaining calories are not zero, then stop removing macros
-	// and update the diet goal calories.
-	if remaining != 0 {
+	// If some of the deficit couldn't be removed, then raise the diet
+	// goal calories by it.
+	if left != 0 {
 		fmt.Printf("Could not reach a deficit of %.2f as the minimum fat, carb, and protein limits have been met.\n", deficit)
-		fmt.Printf("Updating caloric deficit to %.2f\n", deficit-remaining)
-		// Override initial cut calorie goal.
-		u.Phase.GoalCalories = u.TDEE - deficit + remaining
+		fmt.Printf("Updating caloric deficit to %.2f\n", deficit+left)
+		u.Phase.GoalCalories -= left
 		fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
-		return
 	}
 }
 
+// calorieFloor returns the lowest daily calorie goal removeCals lowers
+// the goal to: CalorieFloor if it is set, or else the user's BMR.
+func calorieFloor(u *UserInfo) float64 {
+	if CalorieFloor > 0 {
+		return CalorieFloor
+	}
+	return BMR(u)
+}
+
+// printFloorAdvice tells the user their calorie goal has reached the
+// calorie floor and recommends other ways to keep progressing.
+func printFloorAdvice(floor float64) {
+	fmt.Printf("Your calorie goal has reached the floor of %.0f calories, so it won't be lowered further.\n", floor)
+	fmt.Println("Instead, try walking more or adding cardio, double-checking that your food log is complete, or taking a diet break.")
+}
+
 // checkBulkThreshold checks if the user has gained too much weight, in
 // which the bulk is stopped and a maintenance phase begins.
 //
@@ -634,9 +720,10 @@ func transitionToMaintenance(tx *sqlx.Tx, u *UserInfo) error {
 	u.Phase.WeightChangeThreshold = 0
 	u.Phase.WeeklyChange = 0
 	u.Phase.GoalWeight = u.Phase.StartWeight
+	u.Phase.GoalWeightEnd = 0
 	u.Phase.LastCheckedWeek = u.Phase.StartDate
 	u.Phase.Status = "active"
-	u.Phase.StartDate = time.Now()
+	u.Phase.StartDate = LogNow()
 	u.Phase.EndDate = calculateEndDate(u.Phase.StartDate, u.Phase.Duration)
 	setMinMaxPhaseDuration(u)
 	promptConfirmation(u)
@@ -667,7 +754,7 @@ func getBulkAction() string {
 
 		err := validateAction(option)
 		if err != nil {
-			fmt.Println("Invalid action. Please try again.")
+			fmt.Println(tr("Invalid action. Please try again."))
 			continue
 		}
 
@@ -678,8 +765,8 @@ func getBulkAction() string {
 
 // promptAction prompts the user for the action.
 func promptAction() (o string) {
-	fmt.Printf("Type number and <Enter>: ")
-	fmt.Scanln(&o)
+	fmt.Print(tr("Type number and <Enter>: "))
+	fmt.Fscanln(Input, &o)
 	return o
 }
 
@@ -702,7 +789,7 @@ func CheckPhaseStatus(db *sqlx.DB, u *UserInfo) (string, error) {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	t := time.Now()
+	t := LogNow()
 	// If today comes before diet start date, then phase has not yet begun.
 	if t.Before(u.Phase.StartDate) {
 		log.Println("Diet phase has not yet started. Skipping check on diet phase.")
@@ -711,12 +798,28 @@ func CheckPhaseStatus(db *sqlx.DB, u *UserInfo) (string, error) {
 
 	// If today comes after diet end date, diet phase is over.
 	if t.After(u.Phase.EndDate) {
+		// Offer to make up for the weeks that went off track before
+		// ending the phase.
+		extended, err := checkExtension(tx, u, t)
+		if err != nil {
+			return "", err
+		}
+		if extended {
+			return u.Phase.Status, tx.Commit()
+		}
+
 		fmt.Println("Diet phase completed! Starting the diet phase transistion process.")
 		//  Update current diet phase status to: "completed".
 		u.Phase.Status = "completed"
 		if err := updatePhaseInfo(tx, u); err != nil {
 			return "", err
 		}
+		// Watch for drift from the weight a cut or bulk ended at.
+		if u.Phase.Name == "cut" || u.Phase.Name == "bulk" {
+			if err := watchWeight(tx, u); err != nil {
+				return "", err
+			}
+		}
 
 		// Process phase transition
 		if err := processPhaseTransition(tx, u); err != nil {
@@ -734,13 +837,16 @@ func CheckPhaseStatus(db *sqlx.DB, u *UserInfo) (string, error) {
 
 		// Check if goal weight is still valid.
 		_, err := validateGoalWeight(strconv.FormatFloat(u.Phase.GoalWeight, 'f', -1, 64), u)
+		if err == nil && u.Phase.GoalWeightEnd != 0 {
+			_, err = validateGoalWeight(strconv.FormatFloat(u.Phase.GoalWeightEnd, 'f', -1, 64), u)
+		}
 		// If weight is now invalid,
 		if err != nil {
 			option := getNextAction(u)
 
 			switch option {
 			case "1": // Get new goal weight.
-				u.Phase.GoalWeight = getGoalWeight(u)
+				u.Phase.GoalWeight, u.Phase.GoalWeightEnd = getGoalWeight(u)
 			case "2": // Change to different phase.
 				// Update current diet phase status to: "stopped".
 				u.Phase.Status = "stopped"
@@ -800,8 +906,8 @@ func printNextAction(phase string) {
 
 // promptNextAction prompts the user for the next action.
 func promptNextAction() (a string) {
-	fmt.Printf("Type number and <Enter>: ")
-	fmt.Scanln(&a)
+	fmt.Print(tr("Type number and <Enter>: "))
+	fmt.Fscanln(Input, &a)
 	return a
 }
 
@@ -968,7 +1074,9 @@ func metWeeklyGoalBulk(u *UserInfo, totalWeekWeightChange float64) WeightGainSta
 }
 
 // addCals calculates the caloric surplus and then attempts to
-// apply it by first adding carbs, then fats, and finally fats.
+// apply it by first adding carbs, then fats, and finally protein.
+//
+// The surplus will be applied up to the maximum macro values.
 func addCals(u *UserInfo, totalWeekWeightChange float64) {
 
 	diff := u.Phase.WeeklyChange - totalWeekWeightChange
@@ -986,74 +1094,17 @@ func addCals(u *UserInfo, totalWeekWeightChange float64) {
 	fmt.Printf("Adding to caloric surplus by %.2f calories.\n", surplus)
 	fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
 
-	// Convert surplus in calories to carbs in grams.
-	carbSurplus := surplus * calsInCarbs
+	// Add the surplus to the macros.
+	m, left := AdjustMacros(u.Macros, surplus)
+	u.Macros = m
 
-	// If carb surplus is less than or equal to the availiable carbs
-	// left (up to maximum carbs limit), then apply the surplus
-	// execlusively through adding carbs and return.
-	if u.Macros.MaxCarbs <= (u.Macros.Carbs + carbSurplus) {
-		u.Macros.Carbs += carbSurplus
-		return
-	}
-	// Otherwise, there are too many carbs to completely apply the surplus.
-
-	// Calculate how many grams of carbs are over the maximum carb limit.
-	remainingInCarbs := (u.Macros.Carbs + carbSurplus) - u.Macros.MaxCarbs
-	// Add carbs up the maximum carb limit.
-	u.Macros.Carbs += carbSurplus - remainingInCarbs
-
-	// Convert the carbs in grams that couldn't be included to calories.
-	remainingInCals := remainingInCarbs * calsInCarbs
-	// Convert the remaining surplus from calories to fats.
-	fatSurplus := remainingInCals / calsInFats
-
-	// If the fat deficit is less than or equal to the available fats
-	// left (up to maximum fats limit), then apply the surplus
-	// exclusively through adding fats and return.
-	if u.Macros.MaxFats <= (u.Macros.Fats + fatSurplus) {
-		u.Macros.Fats += fatSurplus
-		return
-	}
-	// Otherwise, there are too many fats to competely apply the surplus.
-
-	// Calculate how many grams of fats are over the maximum fat limit.
-	remainingInFats := (u.Macros.Fats + fatSurplus) - u.Macros.MaxFats
-	// Add fats up the maximum fat limit.
-	u.Macros.Fats += fatSurplus - remainingInFats
-
-	// Convert the fats in grams that couldn't be included to calories.
-	remainingInCals = remainingInFats * calsInFats
-	// Convert the remaining surplus from calories to protein.
-	proteinSurplus := remainingInCals / calsInProtein
-
-	// If protein surplus is less than or equal to the availiable
-	// protein left (up to maximum protein limit), then apply the
-	// surplus by adding protein and return.
-	if u.Macros.MaxProtein <= (u.Macros.Protein + proteinSurplus) {
-		u.Macros.Protein += proteinSurplus
-		return
-	}
-	// Otherwise, there is too much protein to completely apply the
-	// surplus.
-
-	// Calculate how many grams of protein are over the maximum protein limit.
-	remainingInProtein := u.Macros.Protein + proteinSurplus - u.Macros.MaxProtein
-	// Add protein up to the maximum protein limit.
-	u.Macros.Protein += proteinSurplus - remainingInProtein
-
-	// Convert the protein in grams that couldn't be included to calories.
-	remaining := remainingInProtein * calsInProtein
-
-	// If the remaining calories are not zero, then stop adding to macros
-	// and update the diet goal calories and return.
-	if remaining != 0 {
-		fmt.Printf("Could not reach a surplus of %f since the maximum fat, carb, and protein limits were met before the entire surplus could be applied.\n", surplus)
-		fmt.Printf("Updating caloric surplus to %f.\n", surplus-remaining)
-		// Override initial cut calorie goal.
-		u.Phase.GoalCalories = u.TDEE + surplus - remaining
+	// If some of the surplus couldn't be added, then lower the diet goal
+	// calories by it.
+	if left != 0 {
+		fmt.Printf("Could not reach a surplus of %.2f since the maximum fat, carb, and protein limits were met before the entire surplus could be applied.\n", surplus)
+		fmt.Printf("Updating caloric surplus to %.2f.\n", surplus-left)
+		u.Phase.GoalCalories -= left
 		fmt.Printf("New calorie goal: %.2f.\n", u.Phase.GoalCalories)
-		return
 	}
 }
 
@@ -1149,23 +1200,46 @@ func getPrecedingWeightToDay(u *UserInfo, entries *[]Entry, weight float64, star
 }
 
 // processPhaseTransition transitions the user to a new diet phase
-// and saves the next phase to config file.
+// and saves the next phase to config file. A completed phase of a
+// started template is followed by the template's next phase without
+// prompting.
 func processPhaseTransition(tx *sqlx.Tx, u *UserInfo) error {
 	fmt.Println("Step 1: Diet phase recap")
-	fmt.Printf("Goal weight: %f. Current weight: %f\n", u.Phase.GoalWeight, u.Weight)
-
-	printTransitionSuggestion(u.Phase.Name)
+	fmt.Printf("Goal weight: %s. Current weight: %s\n", FormatGoalWeight(u.Phase), FormatWeight(u.Weight))
+	if u.Phase.Name != "maintain" && u.Phase.ReachedGoal(u.Weight) {
+		fmt.Println(tr("You've reached your goal weight."))
+	}
 
-	processUserInfo(u)
+	from := u.Phase.Name
+	next, ok, err := nextTemplatePhase(tx, u.Phase.Status == "completed")
+	if err != nil {
+		return err
+	}
+	if ok {
+		// The template's next phase starts where this one ended.
+		fmt.Printf("Starting the next phase of the %s template.\n", next.Template)
+		startTemplatePhase(u, next.PhaseStep, u.Phase.EndDate)
+		promptConfirmation(u)
+		PrintConditionDisclaimers(u)
+	} else {
+		printTransitionSuggestion(u.Phase.Name)
+		processUserInfo(u)
+	}
 
 	// Save user info to config file.
-	err := saveUserInfo(tx, u)
-	if err != nil {
+	if err := saveUserInfo(tx, u); err != nil {
 		log.Println("Failed to save user info:", err)
 		return err
 	}
 	log.Println("User info saved successfully.")
 
+	emit(EventPhaseTransition, phaseTransition{
+		From:      from,
+		To:        u.Phase.Name,
+		StartDate: u.Phase.StartDate.Format(dateFormat),
+		EndDate:   u.Phase.EndDate.Format(dateFormat),
+	})
+
 	return nil
 }
 
@@ -1212,6 +1286,7 @@ func processUserInfo(u *UserInfo) {
 
 	// Print new phase information to user.
 	promptConfirmation(u)
+	PrintConditionDisclaimers(u)
 }
 
 // promptUserForPhaseInfo prompts user for information to initialize the Phase
@@ -1226,6 +1301,8 @@ func promptUserForPhaseInfo(u *UserInfo) {
 		handleRecommendedDiet(u)
 	case "custom":
 		handleCustomDiet(u)
+	case "target":
+		handleTargetDiet(u)
 	}
 }
 
@@ -1236,7 +1313,7 @@ func getDietChoice(u *UserInfo) string {
 	fmt.Println("Step 3: Choose diet goal.")
 
 	// Print to user recommended and custom diet goal options.
-	printDietChoices(u.Phase.Name)
+	printDietChoices(u)
 
 	var c string
 	for {
@@ -1249,6 +1326,11 @@ func getDietChoice(u *UserInfo) string {
 			fmt.Println("Invalid diet goal. Please try again.")
 			continue
 		}
+		c = strings.ToLower(c)
+		if c == "target" && u.Phase.Name == "maintain" {
+			fmt.Println("A target date goal is only available for a cut or bulk. Please try again.")
+			continue
+		}
 
 		break
 	}
@@ -1257,11 +1339,12 @@ func getDietChoice(u *UserInfo) string {
 }
 
 // printDietChoices prints recommended and custom diet options.
-func printDietChoices(phase string) {
+func printDietChoices(u *UserInfo) {
+	phase := u.Phase.Name
 	fmt.Printf("Recommended: ")
 	switch phase {
 	case "cut":
-		fmt.Printf("Lose 0.5%% of bodyweight per week for 8 weeks.\n")
+		fmt.Printf("Lose %s%% of bodyweight per week for 8 weeks.\n", localizeNumber(strconv.FormatFloat(-cutRate(u)*100, 'f', -1, 64)))
 	case "maintain":
 		fmt.Printf("Maintain same weight for 5 weeks.\n")
 	case "bulk":
@@ -1269,20 +1352,23 @@ func printDietChoices(phase string) {
 	}
 
 	fmt.Println("Custom: Choose diet duration and rate of weight change.")
+	if phase != "maintain" {
+		fmt.Println("Target: Choose a goal weight and the date to reach it by.")
+	}
 }
 
 // promptDietChoice prints diet goal options, prompts for diet goal,
 // and validates user response.
 func promptDietChoice() (c string) {
-	fmt.Printf("Enter diet choice (recommended or custom): ")
-	fmt.Scanln(&c)
+	fmt.Printf("Enter diet choice (recommended, custom, or target): ")
+	fmt.Fscanln(Input, &c)
 	return c
 }
 
 // validateDietChoice validates and returns user diet choice.
 func validateDietChoice(c string) error {
 	c = strings.ToLower(c)
-	if c == "recommended" || c == "custom" {
+	if c == "recommended" || c == "custom" || c == "target" {
 		return nil
 	}
 
@@ -1296,13 +1382,28 @@ func handleRecommendedDiet(u *UserInfo) {
 
 	switch u.Phase.Name {
 	case "cut":
-		goalWeight, dailyCaloricChange := calculateDietPlan(u.Phase.StartWeight, defaultCutDuration, defaultCutWeeklyChangePct)
-		setRecommendedValues(u, defaultCutWeeklyChangePct*u.Phase.StartWeight, defaultCutDuration, goalWeight, u.TDEE+dailyCaloricChange)
+		setRecommendedPhase(u, defaultCutDuration)
 	case "maintain":
-		setRecommendedValues(u, 0, 5, u.Phase.StartWeight, u.TDEE)
+		setRecommendedPhase(u, 5)
 	case "bulk":
-		goalWeight, dailyCaloricChange := calculateDietPlan(u.Phase.StartWeight, defaultBulkDuration, defaultBulkWeeklyChangePct)
-		setRecommendedValues(u, defaultBulkWeeklyChangePct*u.Phase.StartWeight, defaultBulkDuration, goalWeight, u.TDEE+dailyCaloricChange)
+		setRecommendedPhase(u, defaultBulkDuration)
+	}
+}
+
+// setRecommendedPhase sets UserInfo struct fields according to a
+// recommended diet pace lasting the given number of weeks from the
+// phase's start date.
+func setRecommendedPhase(u *UserInfo, duration float64) {
+	switch u.Phase.Name {
+	case "cut":
+		rate := cutRate(u)
+		goalWeight, dailyCaloricChange := calculateDietPlan(u.Phase.StartWeight, duration, rate)
+		setRecommendedValues(u, rate*u.Phase.StartWeight, duration, goalWeight, u.TDEE+dailyCaloricChange)
+	case "maintain":
+		setRecommendedValues(u, 0, duration, u.Phase.StartWeight, u.TDEE)
+	case "bulk":
+		goalWeight, dailyCaloricChange := calculateDietPlan(u.Phase.StartWeight, duration, defaultBulkWeeklyChangePct)
+		setRecommendedValues(u, defaultBulkWeeklyChangePct*u.Phase.StartWeight, duration, goalWeight, u.TDEE+dailyCaloricChange)
 	}
 
 	u.Phase.EndDate = calculateEndDate(u.Phase.StartDate, u.Phase.Duration)
@@ -1358,6 +1459,7 @@ func setRecommendedValues(u *UserInfo, w, d, g, c float64) {
 	u.Phase.WeeklyChange = w
 	u.Phase.Duration = d
 	u.Phase.GoalWeight = g
+	u.Phase.GoalWeightEnd = 0
 	u.Phase.GoalCalories = c
 	u.Phase.LastCheckedWeek = u.Phase.StartDate
 }
@@ -1371,6 +1473,9 @@ func calculateEndDate(d time.Time, duration float64) time.Time {
 
 // handleCustomDiet sets UserInfo struct fields according to custom diet
 // specified by the user.
+//
+// The end date and goal weight are prompted for again until they make
+// a plan that passes the safety checks, or the user overrides them.
 func handleCustomDiet(u *UserInfo) {
 	// Get diet start date.
 	u.Phase.StartDate = getStartDate(u)
@@ -1378,27 +1483,33 @@ func handleCustomDiet(u *UserInfo) {
 	// Initialize last checked week.
 	u.Phase.LastCheckedWeek = u.Phase.StartDate
 
-	// set diet end date.
-	setEndDate(u)
-
-	// Get diet goal weight.
-	u.Phase.GoalWeight = getGoalWeight(u)
-
-	// Calculate weekly weight change rate.
-	u.Phase.WeeklyChange = calculateWeeklyChange(u.Weight, u.Phase.GoalWeight, u.Phase.Duration)
-
-	// Get weekly average weight change in calories.
-	totalWeekWeightChangeCals := u.Phase.WeeklyChange * calsPerPound
-	// Calculate daily average weight change in caloric needed for cut or bulk.
-	avgDayWeightChangeCals := totalWeekWeightChangeCals / 7
+	for {
+		// set diet end date.
+		setEndDate(u)
+
+		// Get diet goal weight.
+		u.Phase.GoalWeight, u.Phase.GoalWeightEnd = getGoalWeight(u)
+
+		// Calculate weekly weight change rate.
+		u.Phase.WeeklyChange = calculateWeeklyChange(u.Weight, u.Phase.GoalWeight, u.Phase.Duration)
+
+		// Get weekly average weight change in calories.
+		totalWeekWeightChangeCals := u.Phase.WeeklyChange * calsPerPound
+		// Calculate daily average weight change in caloric needed for cut or bulk.
+		avgDayWeightChangeCals := totalWeekWeightChangeCals / 7
+
+		switch u.Phase.Name {
+		case "cut":
+			u.Phase.GoalCalories = u.TDEE - avgDayWeightChangeCals
+		case "maintain":
+			u.Phase.GoalCalories = u.TDEE
+		case "bulk":
+			u.Phase.GoalCalories = u.TDEE + avgDayWeightChangeCals
+		}
 
-	switch u.Phase.Name {
-	case "cut":
-		u.Phase.GoalCalories = u.TDEE - avgDayWeightChangeCals
-	case "maintain":
-		u.Phase.GoalCalories = u.TDEE
-	case "bulk":
-		u.Phase.GoalCalories = u.TDEE + avgDayWeightChangeCals
+		if confirmSafety(u, u.Phase.WeeklyChange, u.Phase.GoalCalories) {
+			return
+		}
 	}
 }
 
@@ -1413,7 +1524,7 @@ func getStartDate(u *UserInfo) (date time.Time) {
 		// If user entered default date,
 		if r == "" {
 			// set date to today's date.
-			r = time.Now().Format(dateFormat)
+			r = Today().Format(dateFormat)
 			// Set phase status to true.
 			u.Phase.Status = "active"
 		}
@@ -1442,7 +1553,7 @@ func getStartDate(u *UserInfo) (date time.Time) {
 // equal to or later than the current date (today) and `false`
 // otherwise.
 func validateDateIsNotPast(date time.Time) bool {
-	today := time.Now()
+	today := LogNow()
 	if date.After(today) || isSameDay(date, today) {
 		return true // Date is today or later
 	}
@@ -1472,7 +1583,7 @@ func setEndDate(u *UserInfo) {
 
 // promptDate prompts and returns diet date.
 func promptDate(promptStr string) string {
-	reader := bufio.NewReader(os.Stdin)
+	reader := Input
 	// Prompt user for diet date.
 	fmt.Printf("%s ", promptStr)
 	response, _ := reader.ReadString('\n')
@@ -1517,15 +1628,9 @@ func validateEndDate(r string, u *UserInfo) (time.Time, float64, error) {
 }
 
 // ValidateDateStr validates the given date string and returns date if
-// valid.
+// valid. See ParseDate for the accepted dates.
 func ValidateDateStr(dateStr string) (time.Time, error) {
-	// Validate user response.
-	date, err := time.Parse(dateFormat, dateStr)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	return date, nil
+	return ParseDate(dateStr, LogNow())
 }
 
 // calculateDuration calculates and returns diet duration as a
@@ -1537,10 +1642,12 @@ func calculateDuration(start, end time.Time) time.Duration {
 
 // getGoalWeight prompts user for goal weight, validates their response
 // until they enter a valid goal weight, and returns valid goal weight.
-func getGoalWeight(u *UserInfo) (g float64) {
+// For a goal weight range, it returns the end nearer the starting
+// weight and the far end, which is otherwise zero.
+func getGoalWeight(u *UserInfo) (g, end float64) {
 	// If phase is maintenance, return starting weight and skip prompting.
 	if u.Phase.Name == "maintain" {
-		return u.Phase.StartWeight
+		return u.Phase.StartWeight, 0
 	}
 
 	for {
@@ -1549,23 +1656,95 @@ func getGoalWeight(u *UserInfo) (g float64) {
 
 		// Validate user response.
 		var err error
-		g, err = validateGoalWeight(w, u)
+		g, end, err = validateGoalRange(w, u)
 		if err != nil {
-			fmt.Println("Invalid goal weight. Please try again.")
+			fmt.Println(err)
+			fmt.Println("Please try again.")
 			continue
 		}
 
 		break
 	}
 
-	return g
+	return g, end
 }
 
 // promptGoalWeight prompts and returns user goal weight.
-func promptGoalWeight() (w string) {
-	fmt.Printf("Enter your goal weight: ")
-	fmt.Scanln(&w)
-	return w
+func promptGoalWeight() string {
+	fmt.Printf("Enter your goal weight, or a range such as 172-175: ")
+	w, _ := Input.ReadString('\n')
+	return strings.TrimSpace(w)
+}
+
+// validateGoalRange validates a goal weight, or a goal weight range
+// such as "172-175". It returns the end of the range nearer the
+// starting weight and the far end. The far end is zero for a single
+// goal weight.
+func validateGoalRange(s string, u *UserInfo) (near, far float64, err error) {
+	from, to, ok := cutGoalRange(s)
+	if !ok {
+		g, err := validateGoalWeight(strings.TrimSpace(s), u)
+		return g, 0, err
+	}
+
+	if near, err = validateGoalWeight(from, u); err != nil {
+		return 0, 0, err
+	}
+	if far, err = validateGoalWeight(to, u); err != nil {
+		return 0, 0, err
+	}
+	if near == far {
+		return 0, 0, errors.New("Invalid goal weight range. The ends of the range must differ.")
+	}
+	if math.Abs(near-u.Phase.StartWeight) > math.Abs(far-u.Phase.StartWeight) {
+		near, far = far, near
+	}
+	return near, far, nil
+}
+
+// cutGoalRange splits a goal weight range written with a hyphen, an en
+// dash, or "to" into its ends.
+func cutGoalRange(s string) (from, to string, ok bool) {
+	for _, sep := range []string{"–", "-", " to "} {
+		if from, to, ok = strings.Cut(s, sep); ok {
+			return strings.TrimSpace(from), strings.TrimSpace(to), true
+		}
+	}
+	return "", "", false
+}
+
+// GoalRange returns the lowest and highest goal weights of the phase.
+// They are equal when the goal is a single weight.
+func (p PhaseInfo) GoalRange() (lo, hi float64) {
+	if p.GoalWeightEnd == 0 {
+		return p.GoalWeight, p.GoalWeight
+	}
+	return math.Min(p.GoalWeight, p.GoalWeightEnd), math.Max(p.GoalWeight, p.GoalWeightEnd)
+}
+
+// ReachedGoal reports whether the weight has reached the goal weight
+// of the phase: it is inside the goal range, or past it in the
+// direction of the phase, e.g. below the range during a cut.
+func (p PhaseInfo) ReachedGoal(w float64) bool {
+	lo, hi := p.GoalRange()
+	switch p.Name {
+	case "cut":
+		return w <= hi
+	case "bulk":
+		return w >= lo
+	default:
+		return w >= lo && w <= hi
+	}
+}
+
+// FormatGoalWeight formats the goal weight of the phase, or its goal
+// weight range such as "172.0–175.0".
+func FormatGoalWeight(p PhaseInfo) string {
+	lo, hi := p.GoalRange()
+	if lo == hi {
+		return FormatWeight(p.GoalWeight)
+	}
+	return FormatWeight(lo) + "–" + FormatWeight(hi)
 }
 
 // validateGoalWeight prompts validates diet goal weight.
@@ -1639,13 +1818,13 @@ func promptConfirmation(u *UserInfo) {
 
 	switch u.Phase.Name {
 	case "cut":
-		fmt.Printf("Target weight: %.2f (%.2f lbs)\n", u.Phase.GoalWeight, u.Phase.StartWeight-u.Phase.GoalWeight)
+		fmt.Printf("Target weight: %s (%s lbs)\n", FormatGoalWeight(u.Phase), FormatWeight(u.Phase.StartWeight-u.Phase.GoalWeight))
 		fmt.Println("During your cut, you should lean slightly on the side of doing more high-volume training.")
 	case "maintain":
-		fmt.Printf("Target weight: %.2f\n", u.Phase.GoalWeight)
+		fmt.Printf("Target weight: %s\n", FormatWeight(u.Phase.GoalWeight))
 		fmt.Println("During your maintenance, you should lean towards low-volume training (3-10 rep strength training). Get active rest (barely any training and just living life for two weeks is also an option). This phase is meant to give your body a break to recharge for future hard  training.")
 	case "bulk":
-		fmt.Printf("Target weight: %.2f (+%.2f lbs)\n", u.Phase.GoalWeight, u.Phase.GoalWeight-u.Phase.StartWeight)
+		fmt.Printf("Target weight: %s (+%s lbs)\n", FormatGoalWeight(u.Phase), FormatWeight(u.Phase.GoalWeight-u.Phase.StartWeight))
 		fmt.Println("During your bulk, you can just train as you normally would.")
 	}
 }
@@ -1683,7 +1862,7 @@ func printDietPhases() {
 // promptUserPhase prompts the user to enter desired diet phase.
 func promptDietPhase() (s string) {
 	fmt.Print("Enter phase (cut, maintain, or bulk): ")
-	fmt.Scanln(&s)
+	fmt.Fscanln(Input, &s)
 	return s
 }
 
@@ -1702,7 +1881,7 @@ func validateDietPhase(s string) error {
 // * Diet phase activity has been checked. That is, this function should
 // not be called for a diet phase that is not currently active.
 func Summary(u *UserInfo, entries *[]Entry) {
-	defer printDietPhaseInfo(u)
+	defer printDietPhaseInfo(u, *entries)
 
 	m, _ := countEntriesPerWeek(u, entries)
 	totalEntries := 0
@@ -1714,21 +1893,21 @@ func Summary(u *UserInfo, entries *[]Entry) {
 
 	// Check if there are any days logged for this diet.
 	if totalEntries == 0 {
-		log.Println("There has yet to be a logged day for this diet phase. Skipping diet day summary.")
+		log.Println(tr("There has yet to be a logged day for this diet phase. Skipping diet day summary."))
 		return
 	}
 
 	daySummary(u, entries)
 
 	if totalWeeks < 1 {
-		log.Println("There has yet to be a logged week for this diet phase. Skipping diet week summary.")
+		log.Println(tr("There has yet to be a logged week for this diet phase. Skipping diet week summary."))
 		return
 	}
 
 	weekSummary(u, entries)
 
 	if totalWeeks < 4 {
-		log.Println("There has yet to be a logged month for this diet phase. Skipping diet month summary.")
+		log.Println(tr("There has yet to be a logged month for this diet phase. Skipping diet month summary."))
 		return
 	}
 
@@ -1737,7 +1916,7 @@ func Summary(u *UserInfo, entries *[]Entry) {
 
 // daySummary prints a summary of the diet for the current day.
 func daySummary(u *UserInfo, entries *[]Entry) {
-	today := time.Now()
+	today := LogNow()
 	i := len(*entries) - 1
 
 	// Get most recent entry date.
@@ -1745,62 +1924,90 @@ func daySummary(u *UserInfo, entries *[]Entry) {
 
 	// Ensure most recent entry date is equal to today's date.
 	if !isSameDay(today, tailDate) {
-		fmt.Println("Missing entry for today. Please create today's entry prior to attempting to generate today's diet summary.")
+		fmt.Println(tr("Missing entry for today. Please create today's entry prior to attempting to generate today's diet summary."))
 		return
 	}
 
-	cals := (*entries)[i].Calories
+	e := (*entries)[i]
+	cals := e.Calories
+	training := e.Training
 
-	fmt.Printf("%sDay Summary for %s%s\n", colorUnderline, tailDate.Format(dateFormat), colorReset)
-	fmt.Printf("Current Weight: %.2f\n", u.Weight)
-	fmt.Printf("Calories Consumed: ")
-	c := getAdherenceColor(fmt.Sprintf("%.2f", cals), metCalDayGoal(u, cals))
+	fmt.Println(paint(colorUnderline, fmt.Sprintf(tr("Day Summary for %s"), FormatDate(tailDate))))
+	fmt.Printf(tr("Current Weight: %s\n"), FormatWeight(u.Weight))
+	if note := sodiumNote(*entries, i); note != "" {
+		fmt.Println(note)
+	}
+	if Cycle.InWindow(tailDate) {
+		fmt.Println(tr("Water retention from the menstrual cycle is expected, so this week is left out of progress checks."))
+	}
+	fmt.Print(tr("Calories Consumed: "))
+	c := getAdherenceColor(formatNumber(cals, 2), metCalDayGoal(u, cals, training))
 	fmt.Printf("%s\n", c)
+	if u.Phase.TrainingCalories != 0 {
+		goal := formatNumber(DayGoalCalories(u, training), 2)
+		if training {
+			fmt.Printf(tr("Calorie Goal: %s (training day)\n"), goal)
+		} else {
+			fmt.Printf(tr("Calorie Goal: %s (rest day)\n"), goal)
+		}
+	}
+	fmt.Printf(tr("Macros: %s\n"), FormatMacroSplit(e.Protein, e.Carbs, e.Fat))
 }
 
 // metCalDayGoal checks to see if the user met the daily calorie goal
-// given their current diet phase.
-func metCalDayGoal(u *UserInfo, cals float64) bool {
-	tolerance := 0.05 * u.Phase.GoalCalories
+// given their current diet phase and whether they trained that day.
+func metCalDayGoal(u *UserInfo, cals float64, training bool) bool {
+	return metCalGoal(u, cals, DayGoalCalories(u, training))
+}
+
+// metEntryGoal checks to see if the entry met the calorie goal in
+// effect on its day.
+func metEntryGoal(u *UserInfo, e Entry) bool {
+	if e.Goal == 0 {
+		return metCalDayGoal(u, e.Calories, e.Training)
+	}
+	return metCalGoal(u, e.Calories, e.Goal)
+}
+
+// metCalGoal checks to see if the calories met the given goal for the
+// user's diet phase.
+func metCalGoal(u *UserInfo, cals, goal float64) bool {
+	tolerance := DayCalTolerance * goal
 
 	switch u.Phase.Name {
 	case "cut":
-		return cals <= u.Phase.GoalCalories
+		return cals <= goal
 	case "bulk":
-		return cals >= u.Phase.GoalCalories
+		return cals >= goal
 	case "maintain":
-		return math.Abs(cals-u.Phase.GoalCalories) <= tolerance
+		return math.Abs(cals-goal) <= tolerance
 	default:
 		return false
 	}
 }
 
-// getAdherenceColor returns some text in either green or red
-// indicating whether or not user adhered to the diet caloire goal for a
-// particular day.
+// getAdherenceColor returns some text in the met or missed color,
+// green or red by default, indicating whether or not user adhered to
+// the diet caloire goal for a particular day.
 func getAdherenceColor(s string, b bool) string {
-	switch b {
-	case true:
-		return colorGreen + s + colorReset
-	case false:
-		return colorRed + s + colorReset
-	default:
-		return ""
+	if b {
+		return paint(colorMet, s)
 	}
+	return paint(colorMissed, s)
 }
 
 // weekSummary prints a summary of the diet for the most recent week.
 func weekSummary(u *UserInfo, entries *[]Entry) {
 	fmt.Println()
-	fmt.Println(colorUnderline, "Week Summary", colorReset)
+	fmt.Println(paint(colorUnderline, tr("Week Summary")))
 
 	var daysOfWeek []string
 	var calsOfWeek []string
+	var macrosOfWeek []string
+	var week []Entry
+	var notes []string
 	//var calsStr string
-	today := time.Now()
-
-	// Find the current ISO week.
-	_, currentWeek := today.ISOWeek()
+	today := LogNow()
 
 	//tailDate, _ := time.Parse(dateFormat, logs.Series[dateCol].Value(logs.NRows()-1).(string))
 
@@ -1808,27 +2015,23 @@ func weekSummary(u *UserInfo, entries *[]Entry) {
 	// Find the most recent entry's date.
 	tailDate := (*entries)[i].Date
 
-	// Find the last Monday that comes before tailDate
-	diff := (int(tailDate.Weekday()-time.Monday+6)%7 + 1) % 7
-	lastMonday := tailDate.AddDate(0, 0, -diff)
-
-	// Find the tail ISO week.
-	_, tailWeek := lastMonday.ISOWeek()
+	// Find the start of the week of tailDate.
+	tailWeek := startOfWeek(tailDate)
 
 	// Ensure tail week is equal to this week.
-	if tailWeek != currentWeek {
-		fmt.Println("Missing entries for this week. Please create today's entry prior to attempting to generate this week's diet summary.")
+	if !tailWeek.Equal(startOfWeek(today)) {
+		fmt.Println(tr("Missing entries for this week. Please create today's entry prior to attempting to generate this week's diet summary."))
 		return
 	}
 
 	// Iterate over the entries starting from EndDate - 7 days.
 	for i := 0; i < 7; i++ {
-		date := lastMonday.AddDate(0, 0, i)
-		d := date.Weekday().String() + " "
+		date := tailWeek.AddDate(0, 0, i)
+		d := weekdayName(date.Weekday()) + " "
 
 		// Bold the value if it's the current day.
 		if date.Equal(tailDate) {
-			d = colorItalic + date.Weekday().String() + colorReset + " "
+			d = paint(colorItalic, weekdayName(date.Weekday())) + " "
 		}
 
 		// Append date in day of the week to array.
@@ -1837,24 +2040,50 @@ func weekSummary(u *UserInfo, entries *[]Entry) {
 		idx, _ := findEntryIdx(entries, date)
 		// If date matches a logged entry date,
 		if idx != -1 {
-			cals := (*entries)[idx].Calories
-			s := getAdherenceColor(fmt.Sprintf("%-10.2f", cals), metCalDayGoal(u, cals))
+			e := (*entries)[idx]
+			s := getAdherenceColor(fmt.Sprintf("%-10.2f", e.Calories), metEntryGoal(u, e))
 
 			calsOfWeek = append(calsOfWeek, s)
+			macrosOfWeek = append(macrosOfWeek, shortMacroSplit(e.Protein, e.Carbs, e.Fat))
+			week = append(week, e)
+			if note := sodiumNote(*entries, idx); note != "" {
+				notes = append(notes, weekdayName(date.Weekday())+": "+note)
+			}
 
 			continue
 		}
 		calsOfWeek = append(calsOfWeek, "")
+		macrosOfWeek = append(macrosOfWeek, "")
 	}
 
 	printWeekSummary(daysOfWeek, calsOfWeek)
+	for _, m := range macrosOfWeek {
+		fmt.Printf("%-10s", m)
+	}
+	fmt.Println()
+
+	// Print the split of the week's calories between the macros.
+	var protein, carbs, fat float64
+	for _, e := range week {
+		protein += e.Protein
+		carbs += e.Carbs
+		fat += e.Fat
+	}
+	fmt.Printf(tr("Macros (P/C/F): %s\n"), FormatMacroSplit(protein, carbs, fat))
+	printMacroWarnings(macroWarnings(u, week))
+	printWeekFats(week)
+	printWeekBudget(week)
+	notes = append(notes, weekNotes(week, tailWeek)...)
+	for _, n := range notes {
+		fmt.Println(n)
+	}
 }
 
 // monthSummary prints a summary of the diet for the most recent 4 weeks.
 func monthSummary(u *UserInfo, entries *[]Entry) {
 	fmt.Println()
-	fmt.Println(colorUnderline, "Month Summary", colorReset)
-	today := time.Now()
+	fmt.Println(paint(colorUnderline, tr("Month Summary")))
+	today := LogNow()
 
 	currentYear, currentMonth, _ := today.Date()
 
@@ -1862,23 +2091,22 @@ func monthSummary(u *UserInfo, entries *[]Entry) {
 	// Find the most recent entry's date.
 	tailDate := (*entries)[i].Date
 
-	// Find the last Monday that comes before tailDate
-	diff := (int(tailDate.Weekday()-time.Monday+6)%7 + 1) % 7
-	lastMonday := tailDate.AddDate(0, 0, -diff)
+	// Find the start of the week of tailDate.
+	tailWeek := startOfWeek(tailDate)
 
-	tailYear, tailMonth, _ := lastMonday.Date()
+	tailYear, tailMonth, _ := tailWeek.Date()
 
 	// Ensure tail month is equal to this month.
 	// If tailMonth is not equal to the current month or tailYear is not
 	// the current year, then don't print the summary
 	if tailMonth != currentMonth || tailYear != currentYear {
-		fmt.Println("Missing entries for this month. Please create today's entry prior to attempting to generate this month's diet summary.")
+		fmt.Println(tr("Missing entries for this month. Please create today's entry prior to attempting to generate this month's diet summary."))
 		return
 	}
 
 	// Iterate over the weeks starting from EndDate - 28 days.
 	for week := 0; week < 4; week++ {
-		weekStart := lastMonday.AddDate(0, 0, -21+week*7)
+		weekStart := tailWeek.AddDate(0, 0, -21+week*7)
 
 		var daysOfWeek []string
 		var calsOfWeek []string
@@ -1886,11 +2114,11 @@ func monthSummary(u *UserInfo, entries *[]Entry) {
 		// Iterate over the days of the week.
 		for i := 0; i < 7; i++ {
 			date := weekStart.AddDate(0, 0, i)
-			d := date.Weekday().String()
+			d := weekdayName(date.Weekday())
 
 			// Bold the value if it's the current day.
 			if date.Equal(tailDate) {
-				d = colorItalic + date.Weekday().String() + colorReset + " "
+				d = paint(colorItalic, weekdayName(date.Weekday())) + " "
 			}
 			// Append date in day of the week to array.
 			daysOfWeek = append(daysOfWeek, d)
@@ -1898,8 +2126,8 @@ func monthSummary(u *UserInfo, entries *[]Entry) {
 			idx, _ := findEntryIdx(entries, date)
 			// If date matches a logged entry date,
 			if idx != -1 {
-				cals := (*entries)[idx].Calories
-				s := getAdherenceColor(fmt.Sprintf("%-10.2f", cals), metCalDayGoal(u, cals))
+				e := (*entries)[idx]
+				s := getAdherenceColor(fmt.Sprintf("%-10.2f", e.Calories), metEntryGoal(u, e))
 
 				calsOfWeek = append(calsOfWeek, s)
 
@@ -1933,22 +2161,44 @@ func isSameDay(date1, date2 time.Time) bool {
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
-// printDietPhaseInfo prints out the information about the diet phase.
-func printDietPhaseInfo(u *UserInfo) {
+// printDietPhaseInfo prints out the information about the diet phase,
+// the projected date to reach the goal weight from the entries, the
+// weight forecast, and the change in TDEE.
+func printDietPhaseInfo(u *UserInfo, entries []Entry) {
 	// Print the diet phase information.
 	fmt.Println()
-	fmt.Println(colorUnderline, "Diet Phase Info:", colorReset)
-	fmt.Println("Diet phase:", u.Phase.Name)
-	fmt.Println("Start Date:", u.Phase.StartDate.Format(dateFormat))
-	fmt.Println("End Date:", u.Phase.EndDate.Format(dateFormat))
-	fmt.Printf("Duration: %.1f weeks\n", math.Round(u.Phase.Duration*100)/100)
+	fmt.Println(paint(colorUnderline, tr("Diet Phase Info:")))
+	fmt.Printf(tr("Diet phase: %s\n"), u.Phase.Name)
+	fmt.Printf(tr("Start Date: %s\n"), FormatDate(u.Phase.StartDate))
+	fmt.Printf(tr("End Date: %s\n"), FormatDate(u.Phase.EndDate))
+	fmt.Printf(tr("Duration: %s weeks\n"), formatNumber(math.Round(u.Phase.Duration*100)/100, 1))
 
-	remainingTime := calculateDuration(time.Now(), u.Phase.EndDate)
+	remainingTime := calculateDuration(LogNow(), u.Phase.EndDate)
 	remainingDays := int(remainingTime.Hours() / 24)
-	fmt.Printf("Remaining time: %d days\n", remainingDays)
+	fmt.Printf(tr("Remaining time: %d days\n"), remainingDays)
 
-	fmt.Println("Goal Weight:", u.Phase.GoalWeight)
-	fmt.Println("Start Weight:", u.Phase.StartWeight)
+	fmt.Printf(tr("Goal Weight: %s\n"), FormatGoalWeight(u.Phase))
+	fmt.Printf(tr("Start Weight: %s\n"), FormatWeight(u.Phase.StartWeight))
+	if u.Phase.Name != "maintain" && u.Phase.ReachedGoal(u.Weight) {
+		fmt.Println(tr("You've reached your goal weight."))
+	}
+	if u.Phase.TargetMode {
+		fmt.Printf(tr("Target: %s lbs by %s (%s lbs per week)\n"), FormatGoalWeight(u.Phase),
+			FormatDate(u.Phase.EndDate), localizeNumber(fmt.Sprintf("%+.2f", u.Phase.WeeklyChange)))
+	}
+	printProjection(u, entries)
+	printForecast(u, entries)
+	printTDEEChange(u, entries)
+
+	if b := u.Phase.ActiveBreak(); b != nil {
+		fmt.Printf(tr("On a diet break until %s\n"), FormatDate(b.EndDate.AddDate(0, 0, -1)))
+	}
+	printRecommendations(u)
+
+	if u.Phase.TrainingCalories != 0 {
+		fmt.Printf(tr("Training Day Calories: %s\n"), formatNumber(DayGoalCalories(u, true), 2))
+		fmt.Printf(tr("Rest Day Calories: %s\n"), formatNumber(DayGoalCalories(u, false), 2))
+	}
 }
 
 // StopPhase stops the ongoing diet and prompts the user for
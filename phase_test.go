@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/ericstrs/bite/internal/dbtest"
 )
 
 func ExampleCountEntriesPerWeek() {
@@ -156,6 +156,35 @@ func ExampleRemoveCals() {
 	// New calorie goal: 2701.23.
 }
 
+func ExampleRemoveCals_floor() {
+	defer func(f float64) { CalorieFloor = f }(CalorieFloor)
+	CalorieFloor = 2500
+
+	u := UserInfo{Weight: 180, Height: 70, Age: 30}
+	u.Phase.WeeklyChange = 0
+	u.Macros = Macros{Fats: 80, MinFats: 50, Carbs: 250, MinCarbs: 100, Protein: 150, MinProtein: 130}
+
+	// Gaining 0.5 lbs a week asks for 250 fewer calories a day.
+	for _, goal := range []float64{2750, 2700, 2500} {
+		u.Phase.GoalCalories = goal
+		removeCals(&u, 0.5)
+		fmt.Printf("%.0f -> %.0f\n", goal, u.Phase.GoalCalories)
+	}
+
+	// Output:
+	// Reducing caloric deficit by 250.00 calories.
+	// New calorie goal: 2500.00.
+	// 2750 -> 2500
+	// Your calorie goal has reached the floor of 2500 calories, so it won't be lowered further.
+	// Instead, try walking more or adding cardio, double-checking that your food log is complete, or taking a diet break.
+	// Reducing caloric deficit by 200.00 calories.
+	// New calorie goal: 2500.00.
+	// 2700 -> 2500
+	// Your calorie goal has reached the floor of 2500 calories, so it won't be lowered further.
+	// Instead, try walking more or adding cardio, double-checking that your food log is complete, or taking a diet break.
+	// 2500 -> 2500
+}
+
 func ExampleValidateAction() {
 	err := validateAction("1")
 	fmt.Println(err)
@@ -207,11 +236,7 @@ func ExampleCheckCutLoss_withinRange() {
 	u.Phase.Status = "active"
 	u.Phase.Name = "cut"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -221,11 +246,6 @@ func ExampleCheckCutLoss_withinRange() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, avgTotal, err := checkCutLoss(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -272,11 +292,7 @@ func ExampleCheckCutLoss_tooLittle() {
 	u.Phase.Name = "cut"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -286,11 +302,6 @@ func ExampleCheckCutLoss_tooLittle() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, avgTotal, err := checkCutLoss(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -338,11 +349,7 @@ func ExampleCheckCutLoss_tooMuch() {
 	u.Phase.Name = "cut"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -352,11 +359,6 @@ func ExampleCheckCutLoss_tooMuch() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, total, err := checkCutLoss(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -414,11 +416,7 @@ func ExampleCheckMaintenance_within() {
 	u.Phase.GoalCalories = 2400
 	u.Phase.Name = "maintenance"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -476,11 +474,7 @@ func ExampleCheckMaintenance_gained() {
 	u.Phase.Name = "maintain"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -490,11 +484,6 @@ func ExampleCheckMaintenance_gained() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, total, err := checkMaintenance(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -543,11 +532,7 @@ func ExampleCheckMaintenance_lost() {
 	u.Phase.Name = "maintain"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -557,11 +542,6 @@ func ExampleCheckMaintenance_lost() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, total, err := checkMaintenance(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -619,11 +599,7 @@ func ExampleCheckBulkGain_withinRange() {
 	u.Phase.Name = "bulk"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -633,11 +609,6 @@ func ExampleCheckBulkGain_withinRange() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, avgTotal, err := checkBulkGain(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -685,11 +656,7 @@ func ExampleCheckBulkGain_tooLittle() {
 	u.Phase.Name = "bulk"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -699,11 +666,6 @@ func ExampleCheckBulkGain_tooLittle() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, avgTotal, err := checkBulkGain(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -751,11 +713,7 @@ func ExampleCheckBulkGain_tooMuch() {
 	u.Phase.Name = "bulk"
 	u.Phase.Status = "active"
 
-	// Connect to the test database
-	db, err := sqlx.Connect("sqlite", ":memory:")
-	if err != nil {
-		panic(err)
-	}
+	db := dbtest.MustNew()
 	defer db.Close()
 
 	// Start a new transaction.
@@ -765,11 +723,6 @@ func ExampleCheckBulkGain_tooMuch() {
 		return
 	}
 
-	err = setupTestConfigTables(tx)
-	if err != nil {
-		return
-	}
-
 	status, total, err := checkBulkGain(tx, &u, &entries)
 
 	fmt.Println(status)
@@ -1100,6 +1053,38 @@ func ExampleValidateGoalWeight_invalidBulk() {
 	// Invalid goal weight. For a bulk, goal weight cannot exceed 10% of starting body weight.
 }
 
+func ExampleValidateGoalRange() {
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.StartWeight = 190
+	for _, s := range []string{"180", "172-175", "175 – 172", "175-175", "172-foo"} {
+		near, far, err := validateGoalRange(s, &u)
+		fmt.Println(near, far, err)
+	}
+
+	// Output:
+	// 180 0 <nil>
+	// 175 172 <nil>
+	// 175 172 <nil>
+	// 0 0 Invalid goal weight range. The ends of the range must differ.
+	// 0 0 Invalid goal weight. Goal weight must be a number.
+}
+
+func ExamplePhaseInfo_ReachedGoal() {
+	p := PhaseInfo{Name: "cut", StartWeight: 190, GoalWeight: 175, GoalWeightEnd: 172}
+	fmt.Println(FormatGoalWeight(p))
+	for _, w := range []float64{176, 175, 173.5, 170} {
+		fmt.Println(w, p.ReachedGoal(w))
+	}
+
+	// Output:
+	// 172.0–175.0
+	// 176 false
+	// 175 true
+	// 173.5 true
+	// 170 true
+}
+
 func ExampleCalculateWeeklyChange_cut() {
 	curWeight := 180.0 // Current weight
 	goalWeight := 170.0
@@ -1206,60 +1191,3 @@ func ExampleSummary() {
 	/// Output:
 	// 0
 }
-
-func setupTestConfigTables(tx *sqlx.Tx) error {
-	_, err := tx.Exec(`
-    CREATE TABLE IF NOT EXISTS config (
-      user_id INTEGER PRIMARY KEY,
-      sex TEXT NOT NULL,
-      weight REAL NOT NULL,
-      height REAL NOT NULL,
-      age INTEGER NOT NULL,
-      activity_level TEXT NOT NULL,
-      tdee REAL NOT NULL,
-      system TEXT NOT NULL,
-      macros_id INTEGER,
-      phase_id INTEGER,
-      FOREIGN KEY (macros_id) REFERENCES macros(macros_id),
-      FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
-    );
-
-    CREATE TABLE IF NOT EXISTS macros (
-        macros_id INTEGER PRIMARY KEY,
-        protein REAL NOT NULL,
-        min_protein REAL NOT NULL,
-        max_protein REAL NOT NULL,
-        carbs REAL NOT NULL,
-        min_carbs REAL NOT NULL,
-        max_carbs REAL NOT NULL,
-        fats REAL NOT NULL,
-        min_fats REAL NOT NULL,
-        max_fats REAL NOT NULL
-    );
-
-    CREATE TABLE IF NOT EXISTS phase_info (
-        phase_id INTEGER PRIMARY KEY,
-        user_id INTEGER NOT NULL,
-        name TEXT NOT NULL,
-        goal_calories REAL NOT NULL,
-        start_weight REAL NOT NULL,
-        goal_weight REAL NOT NULL,
-        weight_change_threshold REAL NOT NULL,
-        weekly_change REAL NOT NULL,
-        start_date DATE NOT NULL,
-        end_date DATE NOT NULL,
-        last_checked_week DATE NOT NULL,
-        duration REAL NOT NULL,
-        max_duration REAL NOT NULL,
-        min_duration REAL NOT NULL,
-				status TEXT NOT NULL CHECK(status IN ('active', 'completed', 'paused', 'stopped', 'scheduled')),
-        FOREIGN KEY (user_id) REFERENCES config(user_id)
-    );
-  `)
-
-	if err != nil {
-		log.Println("Failed to setup tables:", err)
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,163 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// phaseTargetsSchema creates the phase_targets table in databases made
+// before it existed.
+const phaseTargetsSchema = `
+	CREATE TABLE IF NOT EXISTS phase_targets (
+		phase_id INTEGER NOT NULL,
+		week DATE NOT NULL,
+		calories REAL NOT NULL,
+		protein REAL NOT NULL,
+		carbs REAL NOT NULL,
+		fats REAL NOT NULL,
+		training_calories REAL DEFAULT 0 NOT NULL,
+		training_days INTEGER DEFAULT 0 NOT NULL,
+		PRIMARY KEY (phase_id, week)
+	)
+`
+
+// PhaseTarget is the calorie and macro targets of a diet phase in
+// effect from the start of a week until the next recorded week.
+type PhaseTarget struct {
+	PhaseID          int       `db:"phase_id"`
+	Week             time.Time `db:"week"`
+	Calories         float64   `db:"calories"`
+	Protein          float64   `db:"protein"`
+	Carbs            float64   `db:"carbs"`
+	Fats             float64   `db:"fats"`
+	TrainingCalories float64   `db:"training_calories"`
+	TrainingDays     int       `db:"training_days"`
+}
+
+// addPhaseTargetsTable creates the phase_targets table if it doesn't
+// exist, along with its audit triggers.
+func addPhaseTargetsTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(phaseTargetsSchema); err != nil {
+		return fmt.Errorf("couldn't create phase targets table: %v", err)
+	}
+	return auditTable(tx, "phase_targets")
+}
+
+// recordPhaseTarget records the user's current targets as the targets
+// of the phase with the given id for this week, or for the first week
+// of a phase that hasn't started yet.
+func recordPhaseTarget(tx *sqlx.Tx, phaseID int, u *UserInfo) error {
+	if phaseID == 0 {
+		return nil
+	}
+	if err := addPhaseTargetsTable(tx); err != nil {
+		return err
+	}
+	week := startOfWeek(LogNow())
+	if start := startOfWeek(u.Phase.StartDate); week.Before(start) {
+		week = start
+	}
+	const query = `
+		INSERT INTO phase_targets (phase_id, week, calories, protein, carbs, fats, training_calories, training_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT(phase_id, week) DO UPDATE SET
+			calories = $3, protein = $4, carbs = $5, fats = $6, training_calories = $7, training_days = $8
+	`
+	_, err := tx.Exec(query, phaseID, week.Format(dateFormat), u.Phase.GoalCalories, u.Macros.Protein,
+		u.Macros.Carbs, u.Macros.Fats, u.Phase.TrainingCalories, u.Phase.TrainingDays)
+	if err != nil {
+		return fmt.Errorf("couldn't record phase targets: %v", err)
+	}
+	return nil
+}
+
+// phaseTargets returns the targets recorded for the phase with the
+// given id, ordered by week.
+func phaseTargets(tx *sqlx.Tx, phaseID int) ([]PhaseTarget, error) {
+	if err := addPhaseTargetsTable(tx); err != nil {
+		return nil, err
+	}
+	var ts []PhaseTarget
+	const query = `SELECT * FROM phase_targets WHERE phase_id = $1 ORDER BY week`
+	if err := tx.Select(&ts, query, phaseID); err != nil {
+		return nil, fmt.Errorf("couldn't get phase targets: %v", err)
+	}
+	return ts, nil
+}
+
+// targetOn returns the targets in effect on the date: those of the
+// latest week up to it, or the earliest for dates before them. It
+// returns nil without targets. The targets must be ordered by week.
+func targetOn(ts []PhaseTarget, date time.Time) *PhaseTarget {
+	if len(ts) == 0 {
+		return nil
+	}
+	t := &ts[0]
+	for i := range ts {
+		if ts[i].Week.After(date) {
+			break
+		}
+		t = &ts[i]
+	}
+	return t
+}
+
+// withTarget returns the user with the given targets, or u itself for
+// nil targets.
+func withTarget(u *UserInfo, t *PhaseTarget) *UserInfo {
+	if t == nil {
+		return u
+	}
+	w := *u
+	w.Phase.GoalCalories = t.Calories
+	w.Macros.Protein, w.Macros.Carbs, w.Macros.Fats = t.Protein, t.Carbs, t.Fats
+	w.Phase.TrainingCalories, w.Phase.TrainingDays = t.TrainingCalories, t.TrainingDays
+	return &w
+}
+
+// userOn returns the user with the targets in effect on the date, or u
+// itself if none were recorded.
+func userOn(tx *sqlx.Tx, u *UserInfo, date time.Time) (*UserInfo, error) {
+	ts, err := phaseTargets(tx, u.Phase.PhaseID)
+	if err != nil {
+		return nil, err
+	}
+	return withTarget(u, targetOn(ts, dateOf(date))), nil
+}
+
+// MarkGoals sets Goal on the entries of the user's active phase to the
+// calorie goal in effect on their day, so adherence is checked against
+// the goal at the time rather than the current one.
+func MarkGoals(db *sqlx.DB, u *UserInfo, entries *[]Entry) error {
+	if u.Phase.Status != "active" {
+		return nil
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := markGoals(tx, u, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// markGoals sets Goal on the entries of the user's phase to the calorie
+// goal in effect on their day.
+func markGoals(tx *sqlx.Tx, u *UserInfo, entries *[]Entry) error {
+	ts, err := phaseTargets(tx, u.Phase.PhaseID)
+	if err != nil {
+		return err
+	}
+	for i := range *entries {
+		e := &(*entries)[i]
+		if e.Date.Before(u.Phase.StartDate) {
+			continue
+		}
+		e.Goal = DayGoalCalories(withTarget(u, targetOn(ts, dateOf(e.Date))), e.Training)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleMarkGoals() {
+	restore := SetClock(FixedClock(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)))
+	defer restore()
+
+	// The phase of dbtest.User starts on 2024-01-01 at 2200 calories.
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	u, err := Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	save := func() {
+		tx, err := db.Beginx()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		defer tx.Rollback()
+		if err := updatePhaseInfo(tx, u); err != nil {
+			log.Println(err)
+			return
+		}
+		tx.Commit()
+	}
+	save()
+
+	// Two weeks later, the calorie goal is lowered.
+	SetClock(FixedClock(time.Date(2024, 1, 24, 12, 0, 0, 0, time.UTC)))
+	u.Phase.GoalCalories = 2000
+	save()
+
+	entries := []Entry{
+		{Date: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Calories: 2100},
+		{Date: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), Calories: 2100},
+		{Date: time.Date(2024, 1, 23, 0, 0, 0, 0, time.UTC), Calories: 2100},
+	}
+	if err := MarkGoals(db, u, &entries); err != nil {
+		log.Println(err)
+		return
+	}
+	for _, e := range entries {
+		fmt.Printf("%s goal %.0f, met: %t\n", e.Date.Format(dateFormat), e.Goal, metEntryGoal(u, e))
+	}
+
+	// Output:
+	// 2024-01-03 goal 2200, met: true
+	// 2024-01-17 goal 2200, met: true
+	// 2024-01-23 goal 2000, met: false
+}
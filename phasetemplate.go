@@ -0,0 +1,239 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PhaseStep is a diet phase of a phase template and how many weeks it
+// lasts.
+type PhaseStep struct {
+	Name  string  `db:"name"`
+	Weeks float64 `db:"weeks"`
+}
+
+// PhaseTemplates maps the names of phase templates to the phases each
+// runs back-to-back. The "recomp" template is a 12-week recomp block of
+// 4 weeks of maintenance followed by an 8-week cut.
+var PhaseTemplates = map[string][]PhaseStep{
+	"recomp": {{"maintain", 4}, {"cut", 8}},
+}
+
+// queuedPhase is a phase of a started template that is yet to start.
+type queuedPhase struct {
+	ID       int    `db:"id"`
+	Template string `db:"template"`
+	PhaseStep
+}
+
+// phaseQueueSchema creates the phase_queue table in databases made
+// before it existed.
+const phaseQueueSchema = `
+	CREATE TABLE IF NOT EXISTS phase_queue (
+		id INTEGER PRIMARY KEY,
+		template TEXT NOT NULL,
+		name TEXT NOT NULL,
+		weeks REAL NOT NULL
+	)
+`
+
+// ParsePhaseTemplate parses the phases of a phase template, such as
+// "4w maintain, 8w cut", each a number of weeks and a diet phase.
+func ParsePhaseTemplate(spec string) ([]PhaseStep, error) {
+	var steps []PhaseStep
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		fields := strings.Fields(strings.ToLower(s))
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid phase %q: must be weeks and a phase, such as \"8w cut\"", s)
+		}
+		weeks, err := strconv.ParseFloat(strings.TrimSuffix(fields[0], "w"), 64)
+		if err != nil || weeks <= 0 {
+			return nil, fmt.Errorf("invalid phase %q: weeks must be a number greater than 0", s)
+		}
+		step := PhaseStep{Name: fields[1], Weeks: weeks}
+		if err := validatePhaseStep(step); err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// validatePhaseStep validates the step's diet phase and that it lasts
+// between the phase's minimum and maximum duration.
+func validatePhaseStep(s PhaseStep) error {
+	if err := validateDietPhase(s.Name); err != nil {
+		return fmt.Errorf("invalid phase %q: must be cut, maintain, or bulk", s.Name)
+	}
+	u := UserInfo{Phase: PhaseInfo{Name: s.Name}}
+	setMinMaxPhaseDuration(&u)
+	if s.Weeks < u.Phase.MinDuration || s.Weeks > u.Phase.MaxDuration {
+		return fmt.Errorf("a %s must last %g to %g weeks, got %g", s.Name, u.Phase.MinDuration, u.Phase.MaxDuration, s.Weeks)
+	}
+	return nil
+}
+
+// addPhaseQueueTable creates the phase_queue table if it doesn't exist,
+// along with its audit triggers.
+func addPhaseQueueTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(phaseQueueSchema); err != nil {
+		return fmt.Errorf("couldn't create phase queue table: %v", err)
+	}
+	return auditTable(tx, "phase_queue")
+}
+
+// StartTemplate stops the current diet phase and runs the phases of the
+// named template back-to-back from the start date. The first phase
+// starts on the date and the others are queued, each starting when the
+// one before it ends.
+func StartTemplate(db *sqlx.DB, u *UserInfo, name string, start time.Time) error {
+	name = strings.ToLower(name)
+	steps, ok := PhaseTemplates[name]
+	if !ok {
+		return fmt.Errorf("unknown phase template %q: must be one of %s", name, strings.Join(templateNames(), ", "))
+	}
+
+	if !validateDateIsNotPast(start) {
+		return errors.New("the template must start today or on a future date")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	from := u.Phase.Name
+	if u.Phase.Status == "active" || u.Phase.Status == "scheduled" {
+		u.Phase.Status = "stopped"
+		if err := updatePhaseInfo(tx, u); err != nil {
+			return err
+		}
+	}
+
+	startTemplatePhase(u, steps[0], start)
+	if err := saveUserInfo(tx, u); err != nil {
+		return err
+	}
+	if err := queueTemplate(tx, name, steps[1:]); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	emit(EventPhaseTransition, phaseTransition{
+		From:      from,
+		To:        u.Phase.Name,
+		StartDate: u.Phase.StartDate.Format(dateFormat),
+		EndDate:   u.Phase.EndDate.Format(dateFormat),
+	})
+
+	fmt.Printf("Started the %s template:\n", name)
+	start = u.Phase.StartDate
+	for _, s := range steps {
+		end := calculateEndDate(start, s.Weeks)
+		fmt.Printf("%-8s %s to %s (%g weeks)\n", s.Name, start.Format(dateFormat), end.Format(dateFormat), s.Weeks)
+		start = end
+	}
+	return nil
+}
+
+// startTemplatePhase sets the user's diet phase to the step starting on
+// the start date, at the recommended pace from the user's current
+// weight, along with its macros.
+func startTemplatePhase(u *UserInfo, s PhaseStep, start time.Time) {
+	u.Phase = PhaseInfo{
+		UserID:                u.Phase.UserID,
+		Name:                  s.Name,
+		StartWeight:           u.Weight,
+		WeightChangeThreshold: u.Weight * 0.10,
+		StartDate:             dateOf(start),
+		Status:                "active",
+	}
+	if u.Phase.StartDate.After(LogNow()) {
+		u.Phase.Status = "scheduled"
+	}
+	setMinMaxPhaseDuration(u)
+	setRecommendedPhase(u, s.Weeks)
+
+	setMinMaxMacros(u)
+	u.Macros.Protein, u.Macros.Carbs, u.Macros.Fats = calculateMacros(u)
+}
+
+// queueTemplate replaces the queued phases with the steps of the
+// template.
+func queueTemplate(tx *sqlx.Tx, name string, steps []PhaseStep) error {
+	if err := addPhaseQueueTable(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM phase_queue`); err != nil {
+		return fmt.Errorf("couldn't clear phase queue: %v", err)
+	}
+	const query = `INSERT INTO phase_queue (template, name, weeks) VALUES ($1, $2, $3)`
+	for _, s := range steps {
+		if _, err := tx.Exec(query, name, s.Name, s.Weeks); err != nil {
+			return fmt.Errorf("couldn't queue phase: %v", err)
+		}
+	}
+	return nil
+}
+
+// nextTemplatePhase removes the next queued phase from the queue and
+// returns it, or ok false if there is none. A phase that is stopped
+// before it's completed ends its template, so the queue is cleared
+// instead unless completed is set.
+func nextTemplatePhase(tx *sqlx.Tx, completed bool) (p queuedPhase, ok bool, err error) {
+	if err := addPhaseQueueTable(tx); err != nil {
+		return p, false, err
+	}
+	if !completed {
+		if _, err := tx.Exec(`DELETE FROM phase_queue`); err != nil {
+			return p, false, fmt.Errorf("couldn't clear phase queue: %v", err)
+		}
+		return p, false, nil
+	}
+
+	err = tx.Get(&p, `SELECT * FROM phase_queue ORDER BY id LIMIT 1`)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, false, nil
+	}
+	if err != nil {
+		return p, false, fmt.Errorf("couldn't get queued phase: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM phase_queue WHERE id = $1`, p.ID); err != nil {
+		return p, false, fmt.Errorf("couldn't remove queued phase: %v", err)
+	}
+	return p, true, nil
+}
+
+// templateNames returns the names of the phase templates in order.
+func templateNames() []string {
+	names := make([]string, 0, len(PhaseTemplates))
+	for name := range PhaseTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PrintPhaseTemplates prints the phase templates and their phases.
+func PrintPhaseTemplates() {
+	for _, name := range templateNames() {
+		var phases []string
+		var weeks float64
+		for _, s := range PhaseTemplates[name] {
+			phases = append(phases, fmt.Sprintf("%gw %s", s.Weeks, s.Name))
+			weeks += s.Weeks
+		}
+		fmt.Printf("%s: %s (%g weeks)\n", name, strings.Join(phases, ", "), weeks)
+	}
+}
@@ -0,0 +1,79 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleParsePhaseTemplate() {
+	steps, err := ParsePhaseTemplate("4w maintain, 8W Cut")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(steps)
+
+	_, err = ParsePhaseTemplate("4w maintain, 20w cut")
+	fmt.Println(err)
+	// Output:
+	// [{maintain 4} {cut 8}]
+	// a cut must last 6 to 12 weeks, got 20
+}
+
+func ExampleStartTemplate() {
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	defer SetClock(FixedClock(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)))()
+
+	u, err := Config(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := StartTemplate(db, u, "recomp", Now()); err != nil {
+		log.Fatal(err)
+	}
+
+	// The cut starts on its own once the maintenance phase is over.
+	SetClock(FixedClock(time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)))
+	if u, err = Config(db); err != nil {
+		log.Fatal(err)
+	}
+	status, err := CheckPhaseStatus(db, u)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(status)
+	if u, err = Config(db); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(u.Phase.Name, u.Phase.Status, u.Phase.StartDate.Format(dateFormat), u.Phase.EndDate.Format(dateFormat))
+
+	var phases []struct {
+		Name   string `db:"name"`
+		Status string `db:"status"`
+	}
+	if err := db.Select(&phases, `SELECT name, status FROM phase_info ORDER BY phase_id`); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(phases)
+	// Output:
+	// Started the recomp template:
+	// maintain 2024-03-04 to 2024-04-01 (4 weeks)
+	// cut      2024-04-01 to 2024-05-27 (8 weeks)
+	// Diet phase completed! Starting the diet phase transistion process.
+	// Step 1: Diet phase recap
+	// Goal weight: 185.0. Current weight: 185.0
+	// Starting the next phase of the recomp template.
+	// Fats are below minimum limit. Taking calories from carbs and moving them to fats.
+	// Summary:
+	// Diet Start Date: 2024-04-01
+	// Diet End Date: 2024-05-27
+	// Diet Duration: 8.0 weeks
+	// Target weight: 177.7 (7.3 lbs)
+	// During your cut, you should lean slightly on the side of doing more high-volume training.
+	// active
+	// cut active 2024-04-01 2024-05-27
+	// [{cut stopped} {maintain completed} {cut active}]
+}
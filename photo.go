@@ -0,0 +1,305 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// progressPhotosSchema creates the progress_photos table in databases
+// made before it existed.
+const progressPhotosSchema = `
+	CREATE TABLE IF NOT EXISTS progress_photos (
+		id INTEGER PRIMARY KEY,
+		date DATE NOT NULL,
+		phase_id INTEGER REFERENCES phase_info(phase_id),
+		path TEXT NOT NULL,
+		original TEXT NOT NULL
+	)
+`
+
+// photoExts are the file extensions of the images that can be added as
+// progress photos.
+var photoExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".heic": true,
+}
+
+// PhotosDir is the directory progress photos are kept in. Empty means
+// the "photos" directory next to the database.
+var PhotosDir = ""
+
+// Photo is a progress photo kept in the photos directory.
+type Photo struct {
+	ID       int       `db:"id"`
+	Date     time.Time `db:"date"`
+	Phase    string    `db:"phase"` // Name of the diet phase the photo was taken in, if any.
+	Path     string    `db:"path"`  // Relative to the photos directory.
+	Original string    `db:"original"`
+	Weight   float64   `db:"weight"` // Weight logged on the day, 0 without a weigh-in.
+}
+
+// addPhotoTable creates the progress_photos table if it doesn't exist,
+// along with its audit triggers.
+func addPhotoTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(progressPhotosSchema); err != nil {
+		return fmt.Errorf("couldn't create progress photos table: %v", err)
+	}
+	return auditTable(tx, "progress_photos")
+}
+
+// AddPhoto copies the image at src into the photos directory dir, or
+// links to it when link is set, and records it as a progress photo of
+// the given date. Photos are kept in a directory of the diet phase the
+// date falls in, such as "3-cut", or "no-phase", and named after the
+// date, such as "2024-03-18.jpg" and "2024-03-18-2.jpg". During a dry
+// run the photo is recorded but its file isn't added.
+func AddPhoto(tx *sqlx.Tx, dir, src string, date time.Time, link bool) (*Photo, error) {
+	ext := strings.ToLower(filepath.Ext(src))
+	if !photoExts[ext] {
+		return nil, fmt.Errorf("%s isn't a photo, expected a .jpg, .png, .gif, .webp, or .heic file", src)
+	}
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get absolute path of %s: %v", src, err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, fmt.Errorf("couldn't add photo: %v", err)
+	}
+	if err := addPhotoTable(tx); err != nil {
+		return nil, err
+	}
+
+	p := &Photo{Date: dateOf(date), Original: abs}
+	var phaseID *int
+	var phase struct {
+		ID   int    `db:"phase_id"`
+		Name string `db:"name"`
+	}
+	const phaseSQL = `
+		SELECT phase_id, name FROM phase_info
+		WHERE date(start_date) <= $1 AND date(end_date) >= $1 AND status != 'scheduled'
+		ORDER BY phase_id DESC LIMIT 1
+	`
+	phaseDir := "no-phase"
+	switch err := tx.Get(&phase, phaseSQL, p.Date.Format(dateFormat)); {
+	case err == nil:
+		phaseID, p.Phase = &phase.ID, phase.Name
+		phaseDir = fmt.Sprintf("%d-%s", phase.ID, phase.Name)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("couldn't get diet phase: %v", err)
+	}
+
+	name := p.Date.Format(dateFormat)
+	for n := 2; ; n++ {
+		p.Path = filepath.Join(phaseDir, name+ext)
+		if _, err := os.Lstat(filepath.Join(dir, p.Path)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		name = fmt.Sprintf("%s-%d", p.Date.Format(dateFormat), n)
+	}
+	dst := filepath.Join(dir, p.Path)
+	if !DryRunning {
+		if err := os.MkdirAll(filepath.Join(dir, phaseDir), 0o755); err != nil {
+			return nil, fmt.Errorf("couldn't create photos directory: %v", err)
+		}
+		if link {
+			err = os.Symlink(abs, dst)
+		} else {
+			err = copyFile(abs, dst)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("couldn't add photo: %v", err)
+		}
+	}
+
+	const query = `
+		INSERT INTO progress_photos (date, phase_id, path, original)
+		VALUES ($1, $2, $3, $4)
+	`
+	res, err := tx.Exec(query, p.Date.Format(dateFormat), phaseID, filepath.ToSlash(p.Path), abs)
+	if err != nil {
+		os.Remove(dst)
+		return nil, fmt.Errorf("couldn't save photo: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't save photo: %v", err)
+	}
+	p.ID = int(id)
+	return p, nil
+}
+
+// copyFile copies the file at src to a new file at dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// Photos returns the progress photos from start to end, inclusive, with
+// the weight logged on each photo's day, ordered by date.
+func Photos(tx *sqlx.Tx, start, end time.Time) ([]Photo, error) {
+	if err := addPhotoTable(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT p.id, p.date, COALESCE(ph.name, '') AS phase, p.path, p.original,
+			COALESCE((SELECT w.weight FROM daily_weights w
+				WHERE w.date = p.date ORDER BY w.time LIMIT 1), 0) AS weight
+		FROM progress_photos p
+		LEFT JOIN phase_info ph ON ph.phase_id = p.phase_id
+		WHERE p.date BETWEEN $1 AND $2
+		ORDER BY p.date, p.id
+	`
+	var photos []Photo
+	if err := tx.Select(&photos, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get photos: %v", err)
+	}
+	return photos, nil
+}
+
+// PhotoPair is a photo from the start of a comparison and the photo
+// taken in its place at the end. Either may be nil when one day has
+// more photos than the other.
+type PhotoPair struct {
+	Before, After *Photo
+}
+
+// ComparePhotos pairs the photos of the first day with photos on or
+// after from with those of the last day with photos on or before to, in
+// the order they were added, so photos of the same pose line up.
+func ComparePhotos(tx *sqlx.Tx, from, to time.Time) ([]PhotoPair, error) {
+	if to.Before(from) {
+		return nil, errors.New("--to can't be before --from")
+	}
+	photos, err := Photos(tx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(photos) == 0 {
+		return nil, fmt.Errorf("no photos from %s to %s", from.Format(dateFormat), to.Format(dateFormat))
+	}
+
+	first, last := photos[0].Date, photos[len(photos)-1].Date
+	if first.Equal(last) {
+		return nil, fmt.Errorf("only %s has photos from %s to %s", first.Format(dateFormat),
+			from.Format(dateFormat), to.Format(dateFormat))
+	}
+	var before, after []Photo
+	for _, p := range photos {
+		if p.Date.Equal(first) {
+			before = append(before, p)
+		}
+		if p.Date.Equal(last) {
+			after = append(after, p)
+		}
+	}
+
+	n := len(before)
+	if len(after) > n {
+		n = len(after)
+	}
+	pairs := make([]PhotoPair, n)
+	for i := range pairs {
+		if i < len(before) {
+			pairs[i].Before = &before[i]
+		}
+		if i < len(after) {
+			pairs[i].After = &after[i]
+		}
+	}
+	return pairs, nil
+}
+
+// PrintPhotoPairs prints the paths of the paired photos in dir with
+// the weights of their days.
+func PrintPhotoPairs(dir string, pairs []PhotoPair) {
+	desc := func(p *Photo) string {
+		if p == nil {
+			return "-"
+		}
+		s := p.Date.Format(dateFormat)
+		if p.Weight > 0 {
+			s += " (" + FormatWeight(p.Weight) + ")"
+		}
+		return s + " " + filepath.Join(dir, filepath.FromSlash(p.Path))
+	}
+	for i, pair := range pairs {
+		fmt.Printf("[%d] %s\n    %s\n", i+1, desc(pair.Before), desc(pair.After))
+	}
+}
+
+// contactSheet is the layout of the photo contact sheet.
+var contactSheet = template.Must(template.New("contact").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Progress photos</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+.sheet { display: flex; flex-wrap: wrap; gap: 1em; }
+figure { margin: 0; width: 200px; }
+img { width: 200px; height: 267px; object-fit: cover; }
+figcaption { font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Progress photos</h1>
+{{range .}}<h2>{{.Name}}</h2>
+<div class="sheet">
+{{range .Photos}}<figure><a href="{{.Path}}"><img src="{{.Path}}" alt="{{.Caption}}" loading="lazy"></a><figcaption>{{.Caption}}</figcaption></figure>
+{{end}}</div>
+{{end}}</body>
+</html>
+`))
+
+// WriteContactSheet writes an HTML contact sheet of the photos, grouped
+// by diet phase. The photos are linked relative to the photos
+// directory, so the sheet belongs in it.
+func WriteContactSheet(w io.Writer, photos []Photo) error {
+	type entry struct{ Path, Caption string }
+	type group struct {
+		Name   string
+		Photos []entry
+	}
+	var groups []group
+	for _, p := range photos {
+		name := "No phase"
+		if p.Phase != "" {
+			name = strings.ToUpper(p.Phase[:1]) + p.Phase[1:]
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != name {
+			groups = append(groups, group{Name: name})
+		}
+		caption := p.Date.Format(dateFormat)
+		if p.Weight > 0 {
+			caption += ", " + FormatWeight(p.Weight)
+		}
+		g := &groups[len(groups)-1]
+		g.Photos = append(g.Photos, entry{Path: p.Path, Caption: caption})
+	}
+	if err := contactSheet.Execute(w, groups); err != nil {
+		return fmt.Errorf("couldn't write contact sheet: %v", err)
+	}
+	return nil
+}
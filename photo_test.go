@@ -0,0 +1,130 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExampleAddPhoto() {
+	src, err := os.MkdirTemp("", "photos")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	for _, name := range []string{"front.jpg", "side.jpg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	dir := filepath.Join(src, "managed")
+
+	// The cut of dbtest.User starts on 2024-01-01.
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO daily_weights (date, time, weight) VALUES ('2024-02-01', '07:00:00', 180.4);
+	`)
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	for _, d := range []time.Time{
+		time.Date(2023, 12, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		for _, name := range []string{"front.jpg", "side.jpg"} {
+			p, err := AddPhoto(tx, dir, filepath.Join(src, name), d, false)
+			if err != nil {
+				log.Fatal(err)
+			}
+			b, err := os.ReadFile(filepath.Join(dir, p.Path))
+			fmt.Println(filepath.ToSlash(p.Path), string(b), err)
+		}
+	}
+	_, err = AddPhoto(tx, dir, filepath.Join(src, "notes.txt"), time.Now(), false)
+	fmt.Println(strings.TrimPrefix(err.Error(), src+string(filepath.Separator)))
+
+	pairs, err := ComparePhotos(tx, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, pair := range pairs {
+		fmt.Printf("%s -> %s (%s)\n", pair.Before.Path, pair.After.Path, FormatWeight(pair.After.Weight))
+	}
+
+	photos, err := Photos(tx, time.Time{}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var sheet strings.Builder
+	if err := WriteContactSheet(&sheet, photos); err != nil {
+		log.Fatal(err)
+	}
+	for _, line := range strings.Split(sheet.String(), "\n") {
+		if strings.HasPrefix(line, "<h2>") || strings.HasPrefix(line, "<figure>") {
+			fmt.Println(line)
+		}
+	}
+	// Output:
+	// no-phase/2023-12-28.jpg front.jpg <nil>
+	// no-phase/2023-12-28-2.jpg side.jpg <nil>
+	// 1-cut/2024-02-01.jpg front.jpg <nil>
+	// 1-cut/2024-02-01-2.jpg side.jpg <nil>
+	// notes.txt isn't a photo, expected a .jpg, .png, .gif, .webp, or .heic file
+	// no-phase/2023-12-28.jpg -> 1-cut/2024-02-01.jpg (180.4)
+	// no-phase/2023-12-28-2.jpg -> 1-cut/2024-02-01-2.jpg (180.4)
+	// <h2>No phase</h2>
+	// <figure><a href="no-phase/2023-12-28.jpg"><img src="no-phase/2023-12-28.jpg" alt="2023-12-28" loading="lazy"></a><figcaption>2023-12-28</figcaption></figure>
+	// <figure><a href="no-phase/2023-12-28-2.jpg"><img src="no-phase/2023-12-28-2.jpg" alt="2023-12-28" loading="lazy"></a><figcaption>2023-12-28</figcaption></figure>
+	// <h2>Cut</h2>
+	// <figure><a href="1-cut/2024-02-01.jpg"><img src="1-cut/2024-02-01.jpg" alt="2024-02-01, 180.4" loading="lazy"></a><figcaption>2024-02-01, 180.4</figcaption></figure>
+	// <figure><a href="1-cut/2024-02-01-2.jpg"><img src="1-cut/2024-02-01-2.jpg" alt="2024-02-01, 180.4" loading="lazy"></a><figcaption>2024-02-01, 180.4</figcaption></figure>
+}
+
+func ExampleAddPhoto_dryRun() {
+	src, err := os.MkdirTemp("", "photos")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	if err := os.WriteFile(filepath.Join(src, "front.jpg"), []byte("front.jpg"), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	dir := filepath.Join(src, "managed")
+
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	// The photo is recorded in the dry run's copy of the database, but
+	// its file isn't added to the photos directory.
+	err = DryRun(db, io.Discard, func(db *sqlx.DB) error {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		p, err := AddPhoto(tx, dir, filepath.Join(src, "front.jpg"), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), false)
+		if err != nil {
+			return err
+		}
+		fmt.Println(filepath.ToSlash(p.Path))
+		return tx.Commit()
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = os.Stat(dir)
+	fmt.Println(errors.Is(err, fs.ErrNotExist))
+
+	// Output:
+	// no-phase/2024-02-01.jpg
+	// true
+}
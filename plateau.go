@@ -0,0 +1,280 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// trendSmoothing is the weight given to each new weigh-in in the
+	// trend weight.
+	trendSmoothing = 0.1
+	// plateauWeeks is the number of valid weeks the trend weight is
+	// checked over.
+	plateauWeeks = 3
+	// plateauMinChange is the change in trend weight (lbs) below which
+	// the weight is considered to have plateaued.
+	plateauMinChange = 0.5
+	// plateauAdherence is the fraction of days in each week that must
+	// meet the calorie goal for a plateau to be flagged.
+	plateauAdherence = 0.8
+	// plateauCalories is how much the calorie goal changes when the user
+	// chooses to adjust calories to break a plateau.
+	plateauCalories = 150
+	// plateauSteps is the number of extra daily steps suggested to break
+	// a plateau.
+	plateauSteps = 2000
+)
+
+// Plateau is a flagged plateau and the action the user chose.
+type Plateau struct {
+	ID      int       `db:"id"`
+	PhaseID int       `db:"phase_id"`
+	Date    time.Time `db:"date"`
+	Change  float64   `db:"change"` // Trend weight change (lbs).
+	Action  string    `db:"action"`
+}
+
+// TrendWeights returns the trend weight for each entry: an
+// exponentially smoothed moving average of the logged weights, which
+// evens out day to day swings from water and food.
+func TrendWeights(entries []Entry) []float64 {
+	trend := make([]float64, len(entries))
+	for i, e := range entries {
+		if i == 0 {
+			trend[i] = e.UserWeight
+			continue
+		}
+		trend[i] = trend[i-1] + trendSmoothing*(e.UserWeight-trend[i-1])
+	}
+	return trend
+}
+
+// trendChange returns the change in trend weight over the days from
+// start to end, inclusive.
+func trendChange(entries []Entry, trend []float64, start, end time.Time) float64 {
+	before, last := -1, -1
+	for i, e := range entries {
+		if e.Date.Before(start) {
+			before = i
+		}
+		if !e.Date.After(end) {
+			last = i
+		}
+	}
+	if last == -1 {
+		return 0
+	}
+	if before == -1 {
+		// Nothing logged before the window, so measure from its first
+		// entry.
+		before = last
+		for i, e := range entries {
+			if !e.Date.Before(start) {
+				before = i
+				break
+			}
+		}
+	}
+	return trend[last] - trend[before]
+}
+
+// detectPlateau checks the plateauWeeks full weeks before now. It
+// returns the change in trend weight and whether the weight has
+// plateaued: each week has at least minEntriesPerWeek entries, met the
+// calorie goal on plateauAdherence of the days, and isn't a diet break,
+// yet the trend weight moved less than plateauMinChange. Weeks are
+// counted in 7 day steps from the phase start date, and weeks before
+// from are ignored.
+func detectPlateau(u *UserInfo, entries []Entry, from, now time.Time) (float64, bool) {
+	// Find the start of the last full week.
+	last := time.Time{}
+	for w := u.Phase.StartDate; !w.AddDate(0, 0, 7).After(now); w = w.AddDate(0, 0, 7) {
+		last = w
+	}
+	if last.IsZero() {
+		return 0, false
+	}
+
+	start := last.AddDate(0, 0, -7*(plateauWeeks-1))
+	if start.Before(u.Phase.StartDate) || start.Before(from) {
+		return 0, false
+	}
+
+	for w := start; !w.After(last); w = w.AddDate(0, 0, 7) {
+		weekEnd := w.AddDate(0, 0, 6)
+		if u.Phase.onBreak(w, weekEnd) {
+			return 0, false
+		}
+
+		var days []Entry
+		for _, e := range entries {
+			if !e.Date.Before(w) && !e.Date.After(weekEnd) {
+				days = append(days, e)
+			}
+		}
+		if len(days) < minEntriesPerWeek {
+			return 0, false
+		}
+		met := 0
+		for _, e := range days {
+			if metEntryGoal(u, e) {
+				met++
+			}
+		}
+		if float64(met)/float64(len(days)) < plateauAdherence {
+			return 0, false
+		}
+	}
+
+	change := trendChange(entries, TrendWeights(entries), start, last.AddDate(0, 0, 6))
+	return change, math.Abs(change) < plateauMinChange
+}
+
+// lastPlateau returns the most recent plateau of the phase, or nil.
+func lastPlateau(tx *sqlx.Tx, phaseID int) (*Plateau, error) {
+	const query = `
+		SELECT * FROM plateaus
+		WHERE phase_id = $1
+		ORDER BY date DESC, id DESC
+		LIMIT 1
+	`
+	p := &Plateau{}
+	if err := tx.Get(p, query, phaseID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("couldn't get last plateau: %v", err)
+	}
+	return p, nil
+}
+
+// addPlateau records a plateau and the action the user chose.
+func addPlateau(tx *sqlx.Tx, p Plateau) error {
+	const query = `
+		INSERT INTO plateaus (phase_id, date, change, action)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := tx.Exec(query, p.PhaseID, p.Date.Format(dateFormat), p.Change, p.Action)
+	if err != nil {
+		return fmt.Errorf("couldn't save plateau: %v", err)
+	}
+	return nil
+}
+
+// checkPlateau flags a plateau in the trend weight during a cut or
+// bulk, lets the user choose how to respond, and records the choice. A
+// plateau isn't flagged again until plateauWeeks more weeks have been
+// checked.
+//
+// Unlike the weekly goal checks, which compare the day to day weight
+// changes to the weekly goal, it looks at the smoothed trend weight, so
+// it isn't thrown off by water weight.
+//
+// Assumptions:
+// * The phase's diet breaks have been loaded.
+func checkPlateau(tx *sqlx.Tx, u *UserInfo, entries []Entry, now time.Time) error {
+	if u.Phase.Name != "cut" && u.Phase.Name != "bulk" {
+		return nil
+	}
+	if u.Phase.ActiveBreak() != nil {
+		return nil
+	}
+
+	from := u.Phase.StartDate
+	prev, err := lastPlateau(tx, u.Phase.PhaseID)
+	if err != nil {
+		return err
+	}
+	if prev != nil {
+		from = prev.Date
+	}
+
+	change, ok := detectPlateau(u, entries, from, now)
+	if !ok {
+		return nil
+	}
+
+	p := Plateau{PhaseID: u.Phase.PhaseID, Date: dateOf(now), Change: change}
+	switch getPlateauAction(u, change) {
+	case "1":
+		p.Action = "adjust_calories"
+		if u.Phase.Name == "cut" {
+			u.Phase.GoalCalories -= plateauCalories
+		} else {
+			u.Phase.GoalCalories += plateauCalories
+		}
+		if err := updatePhaseInfo(tx, u); err != nil {
+			return err
+		}
+		fmt.Printf("Your calorie goal is now %.0f calories.\n", u.Phase.GoalCalories)
+	case "2":
+		p.Action = "add_steps"
+		fmt.Printf("Aim for %d more steps a day, which burns about %.0f calories.\n",
+			plateauSteps, plateauSteps*calsPerStepKg*lbsToKg(u.Weight))
+	case "3":
+		p.Action = "diet_break"
+		if err := startDietBreak(tx, u, now, 7); err != nil {
+			return err
+		}
+		fmt.Printf("Diet break started. Eat %.0f calories a day for the next week.\n", u.Phase.GoalCalories)
+	default:
+		p.Action = "continue"
+	}
+
+	return addPlateau(tx, p)
+}
+
+// getPlateauAction presents the ways to respond to a plateau, prompts
+// the user for one, validates their response, and returns the valid
+// option.
+func getPlateauAction(u *UserInfo, change float64) (o string) {
+	fmt.Printf("Your trend weight changed %s lbs over the last %d weeks even though you stuck to your calorie goal. You may have hit a plateau. Please choose one of the following actions:\n",
+		FormatWeight(change), plateauWeeks)
+
+	var options int
+	if u.Phase.Name == "cut" {
+		fmt.Printf("1. Lower calories by %d.\n", plateauCalories)
+		fmt.Printf("2. Add %d steps a day.\n", plateauSteps)
+		fmt.Println("3. Take a 1 week diet break.")
+		fmt.Println("4. Keep going.")
+		options = 4
+	} else {
+		fmt.Printf("1. Raise calories by %d.\n", plateauCalories)
+		fmt.Println("2. Keep going.")
+		options = 2
+	}
+
+	for {
+		o = promptAction()
+		n, err := validatePlateauAction(o, options)
+		if err != nil {
+			fmt.Println(tr("Invalid action. Please try again."))
+			continue
+		}
+
+		// Map "keep going" to the same option for cuts and bulks.
+		if n == options {
+			o = "4"
+		}
+		break
+	}
+	return o
+}
+
+// validatePlateauAction validates the user's chosen plateau action and
+// returns it as a number.
+func validatePlateauAction(o string, options int) (int, error) {
+	for n := 1; n <= options; n++ {
+		if o == fmt.Sprint(n) {
+			return n, nil
+		}
+	}
+
+	return 0, errors.New("Invalid action.")
+}
@@ -0,0 +1,65 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleTrendWeights() {
+	entries := []Entry{
+		{UserWeight: 180}, {UserWeight: 182}, {UserWeight: 179}, {UserWeight: 181},
+	}
+	for _, t := range TrendWeights(entries) {
+		fmt.Printf("%.2f\n", t)
+	}
+
+	// Output:
+	// 180.00
+	// 180.20
+	// 180.08
+	// 180.17
+}
+
+func ExampleDetectPlateau() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.GoalCalories = 2000
+	u.Phase.StartDate = start
+
+	// Four weeks at the calorie goal, bouncing between 180 and 181 lbs.
+	var entries []Entry
+	for i := 0; i < 28; i++ {
+		w := 180.0 + float64(i%2)
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: w, Calories: 1950})
+	}
+	now := start.AddDate(0, 0, 28)
+
+	change, ok := detectPlateau(&u, entries, start, now)
+	fmt.Printf("%.2f %t\n", change, ok)
+
+	// Not enough weeks since the last plateau.
+	_, ok = detectPlateau(&u, entries, start.AddDate(0, 0, 14), now)
+	fmt.Println(ok)
+
+	// Going over the goal more than once a week isn't a plateau.
+	for i := 14; i < 28; i += 3 {
+		entries[i].Calories = 2400
+	}
+	_, ok = detectPlateau(&u, entries, start, now)
+	fmt.Println(ok)
+
+	// Neither is losing a quarter pound a day.
+	for i := range entries {
+		entries[i].UserWeight = 185 - float64(i)*0.25
+		entries[i].Calories = 1950
+	}
+	change, ok = detectPlateau(&u, entries, start, now)
+	fmt.Printf("%.2f %t\n", change, ok)
+
+	// Output:
+	// 0.28 true
+	// false
+	// false
+	// -4.19 false
+}
@@ -0,0 +1,25 @@
+package bite
+
+// Weights and macros are stored at full precision and rounded only
+// when displayed.
+var (
+	// WeightDecimals is the number of decimals weights are displayed
+	// with.
+	WeightDecimals = 1
+
+	// MacroDecimals is the number of decimals macros, in grams, are
+	// displayed with.
+	MacroDecimals = 1
+)
+
+// FormatWeight formats a weight, or a change in weight, with
+// WeightDecimals decimals and Locale's decimal separator.
+func FormatWeight(w float64) string {
+	return formatNumber(w, WeightDecimals)
+}
+
+// FormatMacro formats an amount of a macro in grams with MacroDecimals
+// decimals and Locale's decimal separator.
+func FormatMacro(g float64) string {
+	return formatNumber(g, MacroDecimals)
+}
@@ -0,0 +1,20 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleFormatWeight() {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	printWeightEntries([]WeightEntry{{Date: date, Weight: 180}, {Date: date.AddDate(0, 0, 1), Weight: 179.46}})
+
+	WeightDecimals, MacroDecimals = 2, 0
+	defer func() { WeightDecimals, MacroDecimals = 1, 1 }()
+	fmt.Println(FormatWeight(179.456), FormatMacro(31.6))
+
+	// Output:
+	// [1] 2024-01-01 180.0
+	// [2] 2024-01-02 179.5
+	// 179.46 32
+}
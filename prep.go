@@ -0,0 +1,268 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PrepTag is the tag of the meals cooked ahead for the week.
+const PrepTag = "prep-friendly"
+
+const (
+	// prepShare is the share of the day's calories and macros each of
+	// lunch and dinner is planned to hold.
+	prepShare = 0.3
+	// prepRecipes is the most meals cooked for a week of prep.
+	prepRecipes = 4
+	// Portions of a meal are planned in quarters between the least and
+	// most portion.
+	prepMinPortion = 0.5
+	prepMaxPortion = 2
+)
+
+// PrepMeal is a lunch or dinner of a meal prep plan.
+type PrepMeal struct {
+	Day     time.Time
+	Slot    MealSlot
+	Meal    Meal
+	Portion float64 // Portion of the meal, e.g. 1.5 for one and a half.
+}
+
+// Nutrition returns the calories and macros of the portion of the meal.
+func (p PrepMeal) Nutrition() Nutrition {
+	return Nutrition{
+		Calories: p.Meal.Cals * p.Portion,
+		Protein:  p.Meal.Protein * p.Portion,
+		Carbs:    p.Meal.Carbs * p.Portion,
+		Fat:      p.Meal.Fats * p.Portion,
+	}
+}
+
+// PrepPlan is a week of lunches and dinners cooked ahead.
+type PrepPlan struct {
+	Start  time.Time // First day of the week.
+	Target Nutrition // Calories and macros each meal aims for.
+	Meals  []PrepMeal
+}
+
+// PrepCook is a meal to cook for a plan and how many servings of it.
+type PrepCook struct {
+	Meal     Meal
+	Servings float64
+}
+
+// Grocery is a quantity of a food to buy, in its serving unit.
+type Grocery struct {
+	FoodID   int
+	Name     string
+	Unit     string
+	Quantity float64
+}
+
+// PlanPrep plans a week of lunches and dinners from the start day out
+// of the meals, each portioned to the target's calories. The meals
+// whose macros come closest to the target once portioned are cooked,
+// up to prepRecipes of them, and take turns across the week.
+func PlanPrep(meals []Meal, target Nutrition, start time.Time) (PrepPlan, error) {
+	plan := PrepPlan{Start: dateOf(start), Target: target}
+	type option struct {
+		meal    Meal
+		portion float64
+		score   float64
+	}
+	var options []option
+	for _, m := range meals {
+		if m.Cals <= 0 {
+			continue
+		}
+		p := prepPortion(m.Cals, target.Calories)
+		pm := PrepMeal{Meal: m, Portion: p}
+		options = append(options, option{m, p, prepScore(pm.Nutrition(), target)})
+	}
+	if len(options) == 0 {
+		return plan, fmt.Errorf("no meals tagged %q with calories to plan from", PrepTag)
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].score < options[j].score
+	})
+	if len(options) > prepRecipes {
+		options = options[:prepRecipes]
+	}
+
+	for i := 0; i < 14; i++ {
+		o := options[i%len(options)]
+		slot := Lunch
+		if i%2 == 1 {
+			slot = Dinner
+		}
+		plan.Meals = append(plan.Meals, PrepMeal{
+			Day:     plan.Start.AddDate(0, 0, i/2),
+			Slot:    slot,
+			Meal:    o.meal,
+			Portion: o.portion,
+		})
+	}
+	return plan, nil
+}
+
+// prepPortion returns the portion of a meal with the calories that
+// comes closest to the target calories, in quarters.
+func prepPortion(cals, target float64) float64 {
+	if target <= 0 {
+		return 1
+	}
+	p := math.Round(target/cals*4) / 4
+	return math.Max(prepMinPortion, math.Min(prepMaxPortion, p))
+}
+
+// prepScore returns how far the nutrition is from the target, as the
+// sum of the relative misses of the calories and protein and half of
+// those of carbs and fat. Lower is closer.
+func prepScore(n, target Nutrition) float64 {
+	miss := func(v, t float64) float64 {
+		if t <= 0 {
+			return 0
+		}
+		return math.Abs(v-t) / t
+	}
+	return miss(n.Calories, target.Calories) + miss(n.Protein, target.Protein) +
+		(miss(n.Carbs, target.Carbs)+miss(n.Fat, target.Fat))/2
+}
+
+// CookList returns the meals to cook for the plan and their servings,
+// in the order they first appear.
+func (p PrepPlan) CookList() []PrepCook {
+	var cook []PrepCook
+	idx := make(map[int]int)
+	for _, m := range p.Meals {
+		i, ok := idx[m.Meal.ID]
+		if !ok {
+			i = len(cook)
+			idx[m.Meal.ID] = i
+			cook = append(cook, PrepCook{Meal: m.Meal})
+		}
+		cook[i].Servings += m.Portion
+	}
+	return cook
+}
+
+// Groceries returns the foods the plan's meals are made of and the
+// quantities of them for the week, by name.
+func (p PrepPlan) Groceries() []Grocery {
+	var list []Grocery
+	idx := make(map[int]int)
+	for _, m := range p.Meals {
+		for _, mf := range m.Meal.Foods {
+			i, ok := idx[mf.Food.ID]
+			if !ok {
+				i = len(list)
+				idx[mf.Food.ID] = i
+				list = append(list, Grocery{FoodID: mf.Food.ID, Name: mf.Food.Name, Unit: mf.Food.ServingUnit})
+			}
+			list[i].Quantity += mf.ServingSize * mf.NumberOfServings * m.Portion
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// PrintPrepPlan plans next week's lunches and dinners from the meals
+// tagged PrepTag, aiming each at prepShare of the day's calorie and
+// macro goals, and prints the plan, the meals to cook, and a grocery
+// list of what isn't in the pantry.
+func PrintPrepPlan(db *sqlx.DB, u *UserInfo) error {
+	meals, err := SearchMeals(db, tagPrefix+PrepTag)
+	if err != nil {
+		return err
+	}
+	if len(meals) == 0 {
+		return fmt.Errorf("no meals tagged %q: tag meals to cook ahead with \"bite tag meal %s\"", PrepTag, PrepTag)
+	}
+	target := Nutrition{
+		Calories: u.Phase.GoalCalories * prepShare,
+		Protein:  u.Macros.Protein * prepShare,
+		Carbs:    u.Macros.Carbs * prepShare,
+		Fat:      u.Macros.Fats * prepShare,
+	}
+	plan, err := PlanPrep(meals, target, startOfWeek(LogNow()).AddDate(0, 0, 7))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	pantry, err := Pantry(tx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	onHand := make(map[int]float64, len(pantry))
+	for _, p := range pantry {
+		onHand[p.FoodID] = p.OnHand
+	}
+
+	printPrepPlan(plan, onHand)
+	return nil
+}
+
+// printPrepPlan prints the plan, its cook list, and the grocery list of
+// its foods less the quantities on hand, keyed by food id.
+func printPrepPlan(plan PrepPlan, onHand map[int]float64) {
+	fmt.Printf("Meal prep for the week of %s, about %.0f cal and %sg protein a meal:\n",
+		plan.Start.Format(dateFormat), plan.Target.Calories, FormatMacro(plan.Target.Protein))
+	var total Nutrition
+	for i := 0; i+1 < len(plan.Meals); i += 2 {
+		lunch, dinner := plan.Meals[i], plan.Meals[i+1]
+		fmt.Printf("%-10s %s: %s, %s: %s\n", weekdayName(lunch.Day.Weekday()),
+			lunch.Slot, prepMealName(lunch), dinner.Slot, prepMealName(dinner))
+		for _, m := range []PrepMeal{lunch, dinner} {
+			n := m.Nutrition()
+			total.Calories += n.Calories
+			total.Protein += n.Protein
+			total.Carbs += n.Carbs
+			total.Fat += n.Fat
+		}
+	}
+	days := float64(len(plan.Meals)) / 2
+	fmt.Printf("A day of lunch and dinner: %.0f cal, %sg protein, %sg carbs, %sg fat.\n", total.Calories/days,
+		FormatMacro(total.Protein/days), FormatMacro(total.Carbs/days), FormatMacro(total.Fat/days))
+
+	fmt.Println("\nCook:")
+	for _, c := range plan.CookList() {
+		fmt.Printf("- %s: %g servings\n", c.Meal.Name, c.Servings)
+	}
+
+	fmt.Println("\nGrocery list:")
+	var n int
+	for _, g := range plan.Groceries() {
+		need := g.Quantity - onHand[g.FoodID]
+		if need <= 0 {
+			continue
+		}
+		fmt.Printf("- [ ] %s, %s %s\n", g.Name, formatNumber(need, 0), g.Unit)
+		n++
+	}
+	if n == 0 {
+		fmt.Println("Everything is in the pantry.")
+	}
+}
+
+// prepMealName returns the name of the planned meal with its portion,
+// such as "Chili (1.5x)", when it isn't a single portion.
+func prepMealName(m PrepMeal) string {
+	if m.Portion == 1 {
+		return m.Meal.Name
+	}
+	return fmt.Sprintf("%s (%gx)", m.Meal.Name, m.Portion)
+}
@@ -0,0 +1,51 @@
+package bite
+
+import (
+	"log"
+	"time"
+)
+
+func ExamplePlanPrep() {
+	food := func(id int, name string, grams float64) MealFood {
+		return MealFood{Food: Food{ID: id, Name: name, ServingUnit: "g"}, ServingSize: grams, NumberOfServings: 1}
+	}
+	meals := []Meal{
+		{ID: 1, Name: "Chicken rice", Cals: 600, Protein: 50, Carbs: 70, Fats: 12,
+			Foods: []MealFood{food(2, "Chicken breast", 150), food(9, "Rice", 200)}},
+		{ID: 2, Name: "Chili", Cals: 500, Protein: 35, Carbs: 45, Fats: 18,
+			Foods: []MealFood{food(10, "Beef", 150), food(11, "Beans", 100)}},
+		{ID: 3, Name: "Pasta bake", Cals: 900, Protein: 20, Carbs: 150, Fats: 25,
+			Foods: []MealFood{food(12, "Pasta", 150)}},
+	}
+	// 30% of a 2200 calorie day with 150g protein, 250g carbs, and 70g
+	// fat.
+	target := Nutrition{Calories: 660, Protein: 45, Carbs: 75, Fat: 21}
+	plan, err := PlanPrep(meals, target, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+	printPrepPlan(plan, map[int]float64{9: 400})
+
+	// Output:
+	// Meal prep for the week of 2024-03-18, about 660 cal and 45.0g protein a meal:
+	// Monday     lunch: Chili (1.25x), dinner: Chicken rice
+	// Tuesday    lunch: Pasta bake (0.75x), dinner: Chili (1.25x)
+	// Wednesday  lunch: Chicken rice, dinner: Pasta bake (0.75x)
+	// Thursday   lunch: Chili (1.25x), dinner: Chicken rice
+	// Friday     lunch: Pasta bake (0.75x), dinner: Chili (1.25x)
+	// Saturday   lunch: Chicken rice, dinner: Pasta bake (0.75x)
+	// Sunday     lunch: Chili (1.25x), dinner: Chicken rice
+	// A day of lunch and dinner: 1261 cal, 75.5g protein, 154.5g carbs, 35.4g fat.
+	//
+	// Cook:
+	// - Chili: 6.25 servings
+	// - Chicken rice: 5 servings
+	// - Pasta bake: 3 servings
+	//
+	// Grocery list:
+	// - [ ] Beans, 625 g
+	// - [ ] Beef, 938 g
+	// - [ ] Chicken breast, 750 g
+	// - [ ] Pasta, 450 g
+	// - [ ] Rice, 600 g
+}
@@ -0,0 +1,87 @@
+package bite
+
+import (
+	"math"
+	"time"
+)
+
+// PhaseProgress summarizes how the user is tracking against the active
+// diet phase.
+type PhaseProgress struct {
+	Weeks         []WeekProgress
+	DaysElapsed   int
+	DaysRemaining int
+	LoggedDays    int // Days with an entry since the phase started.
+	AdherentDays  int // Logged days that met the daily calorie goal.
+}
+
+// WeekProgress holds the progress for one week of a diet phase. Weeks
+// are counted in 7 day steps from the phase start date.
+type WeekProgress struct {
+	Start      time.Time
+	AvgWeight  float64 // Average logged weight. Zero if nothing logged.
+	GoalWeight float64 // Weight the user should be at by the week start.
+	LoggedDays int
+	Notes      []Note // Timeline notes of the week.
+}
+
+// Adherence returns the percentage of logged days that met the daily
+// calorie goal.
+func (p PhaseProgress) Adherence() float64 {
+	if p.LoggedDays == 0 {
+		return 0
+	}
+	return float64(p.AdherentDays) * 100 / float64(p.LoggedDays)
+}
+
+// Progress calculates the progress of the user's diet phase as of the
+// given date. Entries are expected to be the valid log for the phase.
+func Progress(u *UserInfo, entries []Entry, now time.Time) PhaseProgress {
+	p := PhaseProgress{}
+	start := u.Phase.StartDate
+	end := u.Phase.EndDate
+
+	p.DaysElapsed = int(calculateDuration(start, now).Hours() / 24)
+	if p.DaysElapsed < 0 {
+		p.DaysElapsed = 0
+	}
+	p.DaysRemaining = int(math.Ceil(calculateDuration(now, end).Hours() / 24))
+	if p.DaysRemaining < 0 {
+		p.DaysRemaining = 0
+	}
+
+	total := end.Sub(start).Hours()
+	for weekStart := start; !weekStart.After(now) && !weekStart.After(end); weekStart = weekStart.AddDate(0, 0, 7) {
+		weekEnd := weekStart.AddDate(0, 0, 7)
+		w := WeekProgress{Start: weekStart, GoalWeight: u.Phase.StartWeight}
+		if total > 0 {
+			frac := weekStart.Sub(start).Hours() / total
+			w.GoalWeight += (u.Phase.GoalWeight - u.Phase.StartWeight) * frac
+		}
+
+		var sum float64
+		for _, e := range entries {
+			for _, n := range e.Notes {
+				if !n.Date.Before(weekStart) && n.Date.Before(weekEnd) {
+					w.Notes = append(w.Notes, n)
+				}
+			}
+			if e.Date.Before(weekStart) || !e.Date.Before(weekEnd) {
+				continue
+			}
+			sum += e.UserWeight
+			w.LoggedDays++
+			if metEntryGoal(u, e) {
+				p.AdherentDays++
+			}
+		}
+		if w.LoggedDays > 0 {
+			w.AvgWeight = sum / float64(w.LoggedDays)
+		}
+		p.LoggedDays += w.LoggedDays
+
+		p.Weeks = append(p.Weeks, w)
+	}
+
+	return p
+}
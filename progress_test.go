@@ -0,0 +1,40 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleProgress() {
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.GoalCalories = 2000
+	u.Phase.StartWeight = 180
+	u.Phase.GoalWeight = 176
+	u.Phase.StartDate = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	u.Phase.EndDate = time.Date(2023, time.January, 29, 0, 0, 0, 0, time.UTC)
+
+	entries := []Entry{
+		{UserWeight: 180.0, Calories: 1900, Date: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{UserWeight: 179.6, Calories: 2100, Date: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{UserWeight: 179.4, Calories: 1950, Date: time.Date(2023, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{UserWeight: 178.8, Calories: 2000, Date: time.Date(2023, 1, 12, 0, 0, 0, 0, time.UTC)},
+	}
+
+	now := time.Date(2023, time.January, 16, 0, 0, 0, 0, time.UTC)
+	p := Progress(&u, entries, now)
+
+	for _, w := range p.Weeks {
+		fmt.Printf("%s: avg %.1f, goal %.1f, %d logged\n", w.Start.Format(dateFormat),
+			w.AvgWeight, w.GoalWeight, w.LoggedDays)
+	}
+	fmt.Printf("%d days elapsed, %d days remaining\n", p.DaysElapsed, p.DaysRemaining)
+	fmt.Printf("%d/%d adherent days (%.0f%%)\n", p.AdherentDays, p.LoggedDays, p.Adherence())
+
+	// Output:
+	// 2023-01-01: avg 179.8, goal 180.0, 2 logged
+	// 2023-01-08: avg 179.1, goal 179.0, 2 logged
+	// 2023-01-15: avg 0.0, goal 178.0, 0 logged
+	// 15 days elapsed, 13 days remaining
+	// 3/4 adherent days (75%)
+}
@@ -0,0 +1,131 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// projectionWeeks is the number of recent weeks of trend weight used to
+// project the goal date.
+const projectionWeeks = 4
+
+// Projection is the projected date the trend weight reaches the goal
+// weight, or the nearer end of a goal weight range, at the recent weekly
+// rate of change.
+type Projection struct {
+	Rate  float64   // Mean weekly change in trend weight (lbs).
+	Date  time.Time // Projected date at the mean rate.
+	Early time.Time // Projected date at the faster end of the range.
+	Late  time.Time // Projected date at the slower end, or zero if never.
+}
+
+// trendAt returns the trend weight of the last entry on or before date.
+func trendAt(entries []Entry, trend []float64, date time.Time) (float64, bool) {
+	i := -1
+	for j, e := range entries {
+		if e.Date.After(date) {
+			break
+		}
+		i = j
+	}
+	if i == -1 {
+		return 0, false
+	}
+	return trend[i], true
+}
+
+// weeklyTrendRates returns the change in trend weight over each of the
+// last projectionWeeks weeks of the phase, counting back in 7 day steps
+// from the last entry.
+func weeklyTrendRates(u *UserInfo, entries []Entry) []float64 {
+	if len(entries) == 0 {
+		return nil
+	}
+	trend := TrendWeights(entries)
+	last := entries[len(entries)-1].Date
+
+	var rates []float64
+	for k := 0; k < projectionWeeks; k++ {
+		end := last.AddDate(0, 0, -7*k)
+		start := end.AddDate(0, 0, -7)
+		if start.Before(u.Phase.StartDate) {
+			break
+		}
+		from, ok := trendAt(entries, trend, start)
+		if !ok {
+			break
+		}
+		to, _ := trendAt(entries, trend, end)
+		rates = append(rates, to-from)
+	}
+	return rates
+}
+
+// ProjectGoal projects the date the trend weight reaches the goal
+// weight from the mean weekly rate of change over the recent weeks. The
+// range spans one standard deviation of the weekly rates either side of
+// the mean. It returns false if there are fewer than two weeks of
+// entries or the trend isn't moving toward the goal weight.
+func ProjectGoal(u *UserInfo, entries []Entry) (Projection, bool) {
+	p := Projection{}
+	rates := weeklyTrendRates(u, entries)
+	if len(rates) < 2 {
+		return p, false
+	}
+
+	for _, r := range rates {
+		p.Rate += r
+	}
+	p.Rate /= float64(len(rates))
+	variance := 0.0
+	for _, r := range rates {
+		variance += (r - p.Rate) * (r - p.Rate)
+	}
+	sd := math.Sqrt(variance / float64(len(rates)-1))
+
+	trend := TrendWeights(entries)
+	last := entries[len(entries)-1].Date
+	remaining := u.Phase.GoalWeight - trend[len(trend)-1]
+	if remaining == 0 || p.Rate == 0 || math.Signbit(remaining) != math.Signbit(p.Rate) {
+		return p, false
+	}
+
+	// dateAt returns the date the goal is reached at the given weekly
+	// rate.
+	dateAt := func(rate float64) time.Time {
+		days := math.Ceil(remaining / rate * 7)
+		return last.AddDate(0, 0, int(days))
+	}
+
+	// Faster and slower rates in the direction of the goal.
+	faster, slower := p.Rate-sd, p.Rate+sd
+	if p.Rate > 0 {
+		faster, slower = p.Rate+sd, p.Rate-sd
+	}
+	p.Date = dateAt(p.Rate)
+	p.Early = dateAt(faster)
+	if slower != 0 && math.Signbit(slower) == math.Signbit(p.Rate) {
+		p.Late = dateAt(slower)
+	}
+	return p, true
+}
+
+// printProjection prints the projected date to reach the goal weight.
+func printProjection(u *UserInfo, entries []Entry) {
+	if u.Phase.Name != "cut" && u.Phase.Name != "bulk" {
+		return
+	}
+	p, ok := ProjectGoal(u, entries)
+	if !ok {
+		return
+	}
+
+	if p.Late.IsZero() {
+		fmt.Printf("Projected goal date: %s (%s or later) at %+.2f lbs per week\n",
+			p.Date.Format(dateFormat), p.Early.Format(dateFormat), p.Rate)
+		return
+	}
+	fmt.Printf("Projected goal date: %s (%s to %s) at %+.2f lbs per week\n",
+		p.Date.Format(dateFormat), p.Early.Format(dateFormat), p.Late.Format(dateFormat), p.Rate)
+}
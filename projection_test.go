@@ -0,0 +1,41 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleProjectGoal() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.StartDate = start
+	u.Phase.GoalWeight = 170
+
+	// Five weeks of logging at 180 lbs, then losing a pound a week.
+	var entries []Entry
+	for i := 0; i < 35; i++ {
+		w := 180.0
+		if i >= 7 {
+			w -= float64(i-7) / 7
+		}
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: w})
+	}
+
+	p, ok := ProjectGoal(&u, entries)
+	fmt.Println(ok, p.Rate < 0, !p.Early.After(p.Date), !p.Late.Before(p.Date))
+
+	// Too few weeks to project.
+	_, ok = ProjectGoal(&u, entries[:10])
+	fmt.Println(ok)
+
+	// Moving away from the goal weight.
+	u.Phase.GoalWeight = 190
+	_, ok = ProjectGoal(&u, entries)
+	fmt.Println(ok)
+
+	// Output:
+	// true true true true
+	// false
+	// false
+}
@@ -0,0 +1,378 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// purgeLogs are the logs purged of entries before a date, with the
+// column holding each entry's date.
+var purgeLogs = []struct{ table, date string }{
+	{"daily_foods", "date"},
+	{"daily_meals", "date"},
+	{"daily_weights", "date"},
+	{"daily_training", "date"},
+	{"daily_sleep", "date"},
+	{"daily_steps", "date"},
+	{"daily_supps", "date"},
+	{"strength_log", "date"},
+	{"checkins", "date"},
+	{"timeline_notes", "date"},
+	{"progress_photos", "date"},
+	{"price_history", "date"},
+	{"calorie_adjustments", "date"},
+	{"diet_breaks", "end_date"},
+	{"recommendations", "date"},
+	{"plateaus", "date"},
+	{"phase_extensions", "date"},
+	{"safety_overrides", "date"},
+}
+
+// purgeKeep are the tables of food reference data that purging all
+// user data keeps.
+var purgeKeep = map[string]bool{
+	"foods":                    true,
+	"foods_fts":                true,
+	"food_nutrients":           true,
+	"food_flags":               true,
+	"nutrients":                true,
+	"food_nutrient_derivation": true,
+}
+
+// Purged is the number of rows purged from a table.
+type Purged struct {
+	Table string
+	Rows  int
+}
+
+// PurgeBefore deletes the log entries dated before the given date, the
+// attachments of the entries deleted, and the audit log's record of
+// changes made before it. The deletes themselves aren't audited, so
+// the audit log doesn't keep a copy of the rows. It returns the rows
+// deleted from each table that had any.
+func PurgeBefore(tx *sqlx.Tx, before time.Time) ([]Purged, error) {
+	tables, err := tableNames(tx)
+	if err != nil {
+		return nil, err
+	}
+	mark, err := auditMark(tx, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	d := before.Format(dateFormat)
+	var purged []Purged
+	for _, l := range purgeLogs {
+		if !contains(tables, l.table) {
+			continue
+		}
+		query := fmt.Sprintf(`DELETE FROM %s WHERE %s < $1`, l.table, l.date)
+		if purged, err = purgeRows(tx, purged, l.table, query, d); err != nil {
+			return nil, err
+		}
+	}
+	if contains(tables, "attachments") {
+		const query = `
+			DELETE FROM attachments
+			WHERE (entry_table = 'daily_foods' AND entry_id NOT IN (SELECT id FROM daily_foods))
+				OR (entry_table = 'daily_weights' AND entry_id NOT IN (SELECT id FROM daily_weights))
+		`
+		if purged, err = purgeRows(tx, purged, "attachments", query); err != nil {
+			return nil, err
+		}
+	}
+	if contains(tables, "audit_log") {
+		const query = `DELETE FROM audit_log WHERE time < $1 OR id > $2`
+		if purged, err = purgeRows(tx, purged, "audit_log", query, d, mark); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
+
+// PurgeUserData deletes every row of every table except the food
+// reference data in purgeKeep and the tables storing the virtual
+// tables, such as "foods_fts_data". Tables are emptied before the
+// tables their foreign keys refer to, and the audit log last, once the
+// others' deletes are recorded in it. It returns the rows deleted from
+// each table that had any.
+func PurgeUserData(tx *sqlx.Tx) ([]Purged, error) {
+	tables, err := tableNames(tx)
+	if err != nil {
+		return nil, err
+	}
+	var purge []string
+	for _, t := range tables {
+		if purgeKeep[t] || t == "audit_log" || strings.HasPrefix(t, "foods_fts_") || strings.HasPrefix(t, "meals_fts_") {
+			continue
+		}
+		purge = append(purge, t)
+	}
+	if purge, err = childrenFirst(tx, purge); err != nil {
+		return nil, err
+	}
+	if contains(tables, "audit_log") {
+		purge = append(purge, "audit_log")
+	}
+
+	var purged []Purged
+	for _, t := range purge {
+		if purged, err = purgeRows(tx, purged, t, fmt.Sprintf(`DELETE FROM "%s"`, t)); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
+
+// tableNames returns the names of the tables of the database, leaving
+// out SQLite's own.
+func tableNames(tx *sqlx.Tx) ([]string, error) {
+	var tables []string
+	const query = `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name
+	`
+	if err := tx.Select(&tables, query); err != nil {
+		return nil, fmt.Errorf("couldn't get tables: %v", err)
+	}
+	return tables, nil
+}
+
+// childrenFirst orders the tables so that each comes before the tables
+// its foreign keys refer to, keeping their order otherwise. Tables that
+// refer to each other in a cycle are left in their order at the end.
+func childrenFirst(tx *sqlx.Tx, tables []string) ([]string, error) {
+	var keys []struct {
+		Child  string `db:"child"`
+		Parent string `db:"parent"`
+	}
+	const query = `
+		SELECT DISTINCT m.name AS child, f."table" AS parent
+		FROM sqlite_master AS m, pragma_foreign_key_list(m.name) AS f
+		WHERE m.type = 'table' AND m.name != f."table"
+	`
+	if err := tx.Select(&keys, query); err != nil {
+		return nil, fmt.Errorf("couldn't read foreign keys: %v", err)
+	}
+	// children counts the tables left to order that refer to each table.
+	children := make(map[string]int)
+	left := make(map[string]bool)
+	for _, t := range tables {
+		left[t] = true
+	}
+	for _, k := range keys {
+		if left[k.Child] {
+			children[k.Parent]++
+		}
+	}
+
+	var ordered []string
+	for len(ordered) < len(tables) {
+		next := ""
+		for _, t := range tables {
+			if left[t] && children[t] == 0 {
+				next = t
+				break
+			}
+		}
+		if next == "" {
+			for _, t := range tables {
+				if left[t] {
+					ordered = append(ordered, t)
+				}
+			}
+			break
+		}
+		ordered = append(ordered, next)
+		left[next] = false
+		for _, k := range keys {
+			if k.Child == next {
+				children[k.Parent]--
+			}
+		}
+	}
+	return ordered, nil
+}
+
+// auditMark returns the id of the last entry of the audit log, or 0 if
+// the database doesn't have one.
+func auditMark(tx *sqlx.Tx, tables []string) (int64, error) {
+	if !contains(tables, "audit_log") {
+		return 0, nil
+	}
+	var mark int64
+	if err := tx.Get(&mark, `SELECT COALESCE(MAX(id), 0) FROM audit_log`); err != nil {
+		return 0, fmt.Errorf("couldn't read audit log: %v", err)
+	}
+	return mark, nil
+}
+
+// purgeRows runs the delete query and appends the rows it deleted from
+// table to purged, if there were any.
+func purgeRows(tx *sqlx.Tx, purged []Purged, table, query string, args ...any) ([]Purged, error) {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return purged, fmt.Errorf("couldn't purge %s: %v", table, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return purged, err
+	}
+	if n > 0 {
+		purged = append(purged, Purged{table, int(n)})
+	}
+	return purged, nil
+}
+
+// BackupDB writes a copy of db, opened from the file at path, to dst. An
+// encrypted database is copied as it is on disk, so the backup stays
+// encrypted with the same passphrase.
+func BackupDB(db *sqlx.DB, path, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("couldn't back up database: %s already exists", dst)
+	}
+	enc, err := IsEncrypted(path)
+	if err != nil {
+		return fmt.Errorf("couldn't read database: %v", err)
+	}
+	if enc {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("couldn't read database: %v", err)
+		}
+		if err := writeFileAtomic(dst, data); err != nil {
+			return fmt.Errorf("couldn't back up database: %v", err)
+		}
+		return nil
+	}
+	if _, err := db.Exec(`VACUUM INTO $1`, dst); err != nil {
+		return fmt.Errorf("couldn't back up database: %v", err)
+	}
+	return nil
+}
+
+// purgedPhotos returns the paths of the progress photos dated before
+// the given date, or of all of them if it is zero.
+func purgedPhotos(tx *sqlx.Tx, before time.Time) ([]string, error) {
+	tables, err := tableNames(tx)
+	if err != nil || !contains(tables, "progress_photos") {
+		return nil, err
+	}
+	query, args := `SELECT path FROM progress_photos`, []any{}
+	if !before.IsZero() {
+		query, args = query+` WHERE date < $1`, append(args, before.Format(dateFormat))
+	}
+	var paths []string
+	if err := tx.Select(&paths, query, args...); err != nil {
+		return nil, fmt.Errorf("couldn't get progress photos: %v", err)
+	}
+	return paths, nil
+}
+
+// removePhotos deletes the progress photos at the paths, relative to
+// the photos directory dir, and returns how many it deleted. Photos
+// that were already deleted are skipped, and a linked photo's link is
+// deleted but not the file it links to.
+func removePhotos(dir string, paths []string) (int, error) {
+	n := 0
+	var first error
+	for _, p := range paths {
+		err := os.Remove(filepath.Join(dir, filepath.FromSlash(p)))
+		switch {
+		case err == nil:
+			n++
+		case !errors.Is(err, fs.ErrNotExist) && first == nil:
+			first = fmt.Errorf("couldn't delete progress photo: %v", err)
+		}
+	}
+	return n, first
+}
+
+// Purge offers to back up the database at path, asks the user to type
+// a confirmation phrase, and then irreversibly deletes the log entries
+// dated before the given date, or, if it is zero, all user data but
+// the food reference data. The database is vacuumed afterwards so the
+// deleted rows don't linger in the file's free pages. The files of the
+// deleted progress photos are deleted from the photos directory dir
+// once the deletes are committed, but not during a dry run, whose
+// deletes are never saved.
+func Purge(db *sqlx.DB, r io.Reader, path, dir string, before time.Time) error {
+	br := bufio.NewReader(r)
+	fmt.Printf("Back up the database first? Enter a file to write the backup to, or press <Enter> to skip: ")
+	s, _ := br.ReadString('\n')
+	if dst := strings.TrimSpace(s); dst != "" {
+		if err := BackupDB(db, path, dst); err != nil {
+			return err
+		}
+		fmt.Printf("Backed up the database to %s.\n", dst)
+	}
+
+	phrase := "purge all my data"
+	if !before.IsZero() {
+		phrase = "purge before " + before.Format(dateFormat)
+	}
+	fmt.Println(paint(colorMissed, "This can't be undone."))
+	fmt.Printf("Type %q to confirm: ", phrase)
+	s, _ = br.ReadString('\n')
+	if strings.TrimSpace(s) != phrase {
+		fmt.Println("Nothing purged.")
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	photos, err := purgedPhotos(tx, before)
+	if err != nil {
+		return err
+	}
+	var purged []Purged
+	if before.IsZero() {
+		purged, err = PurgeUserData(tx)
+	} else {
+		purged, err = PurgeBefore(tx, before)
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	var removed int
+	var rmErr error
+	if !DryRunning {
+		removed, rmErr = removePhotos(dir, photos)
+	}
+	if _, err := db.Exec(`VACUUM`); err != nil {
+		return fmt.Errorf("couldn't vacuum database: %v", err)
+	}
+	if _, err := db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("couldn't checkpoint database: %v", err)
+	}
+
+	if len(purged) == 0 {
+		fmt.Println("Nothing to purge.")
+		return nil
+	}
+	sort.SliceStable(purged, func(i, j int) bool { return purged[i].Rows > purged[j].Rows })
+	for _, p := range purged {
+		fmt.Printf("Deleted %d rows from %s.\n", p.Rows, p.Table)
+	}
+	if removed > 0 {
+		fmt.Printf("Deleted %d progress photos.\n", removed)
+	}
+	return rmErr
+}
@@ -0,0 +1,138 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+func ExamplePurgeBefore() {
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2021-12-30', '07:00:00', 190.2), ('2022-01-02', '07:00:00', 189.4);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES
+			(1, '2021-12-31', '08:00:00', 40, 1, 156, 5, 3, 27),
+			(3, '2021-12-31', '10:00:00', 118, 1, 105, 1, 0, 27),
+			(1, '2022-01-01', '08:00:00', 40, 1, 156, 5, 3, 27);
+		INSERT INTO phase_targets (phase_id, week, calories, protein, carbs, fats) VALUES
+			(1, '2024-01-01', 2200, 150, 250, 70), (1, '2024-01-08', 2200, 150, 250, 70);
+		INSERT INTO progress_photos (date, phase_id, path, original)
+			VALUES ('2024-01-07', 1, 'cut/2024-01-07.jpg', '/home/sam/front.jpg');
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	purged, err := PurgeBefore(tx, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range purged {
+		fmt.Println(p.Table, p.Rows)
+	}
+
+	// Purging all user data keeps the foods. The phase's targets and
+	// photos are deleted before the phase they refer to.
+	if _, err := PurgeUserData(tx); err != nil {
+		log.Fatal(err)
+	}
+	var foods, entries, phases int
+	tx.Get(&foods, `SELECT COUNT(*) FROM foods`)
+	tx.Get(&entries, `SELECT COUNT(*) FROM daily_foods`)
+	tx.Get(&phases, `SELECT COUNT(*) FROM phase_info`)
+	fmt.Println(foods, entries, phases)
+	// Output:
+	// daily_foods 2
+	// daily_weights 1
+	// 3 0 0
+}
+
+func ExamplePurge() {
+	dir, err := os.MkdirTemp("", "bite-photos")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, p := range []string{"no-phase/2021-12-31.jpg", "no-phase/2022-01-02.jpg"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p), []byte("jpg"), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2021-12-30', '07:00:00', 190.2), ('2022-01-02', '07:00:00', 189.4);
+		INSERT INTO progress_photos (date, path, original) VALUES
+			('2021-12-31', 'no-phase/2021-12-31.jpg', '/home/sam/front.jpg'),
+			('2022-01-02', 'no-phase/2022-01-02.jpg', '/home/sam/front.jpg');
+	`)
+	defer db.Close()
+	defer func(c bool) { NoColor = c }(NoColor)
+	NoColor = true
+
+	input := strings.NewReader("\npurge before 2022-01-01\n")
+	if err := Purge(db, input, "", dir, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range []string{"no-phase/2021-12-31.jpg", "no-phase/2022-01-02.jpg"} {
+		_, err := os.Stat(filepath.Join(dir, p))
+		fmt.Println(p, !errors.Is(err, fs.ErrNotExist))
+	}
+	// Output:
+	// Back up the database first? Enter a file to write the backup to, or press <Enter> to skip: This can't be undone.
+	// Type "purge before 2022-01-01" to confirm: Deleted 1 rows from daily_weights.
+	// Deleted 1 rows from progress_photos.
+	// Deleted 1 progress photos.
+	// no-phase/2021-12-31.jpg false
+	// no-phase/2022-01-02.jpg true
+}
+
+func ExamplePurge_dryRun() {
+	dir, err := os.MkdirTemp("", "bite-photos")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := "no-phase/2021-12-31.jpg"
+	if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0o755); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, p), []byte("jpg"), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	db := dbtest.MustNew(`
+		INSERT INTO progress_photos (date, path, original)
+			VALUES ('2021-12-31', 'no-phase/2021-12-31.jpg', '/home/sam/front.jpg');
+	`)
+	defer db.Close()
+	defer func(c bool) { NoColor = c }(NoColor)
+	NoColor = true
+
+	// The photo's row is only deleted from the dry run's copy of the
+	// database, so its file is kept.
+	input := strings.NewReader("\npurge all my data\n")
+	err = DryRun(db, io.Discard, func(db *sqlx.DB) error {
+		return Purge(db, input, "", dir, time.Time{})
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = os.Stat(filepath.Join(dir, p))
+	fmt.Println(p, !errors.Is(err, fs.ErrNotExist))
+	// Output:
+	// Back up the database first? Enter a file to write the backup to, or press <Enter> to skip: This can't be undone.
+	// Type "purge all my data" to confirm: Deleted 1 rows from progress_photos.
+	// no-phase/2021-12-31.jpg true
+}
@@ -0,0 +1,342 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QuickItem is a food typed in a quick log, such as "40g oats".
+type QuickItem struct {
+	Quantity float64
+	Unit     string // Empty when the quantity is a number of servings.
+	Name     string
+	// Cooked is set for cooked weights, such as "150g cooked chicken",
+	// of foods whose data is for the raw food.
+	Cooked bool
+}
+
+// unit is a unit of measure and its size in the base unit of its
+// dimension, grams or milliliters.
+type unit struct {
+	base   string
+	factor float64
+}
+
+// units maps the units a quantity can be given in to their size.
+var units = map[string]unit{
+	"g":     {"g", 1},
+	"grm":   {"g", 1},
+	"gram":  {"g", 1},
+	"grams": {"g", 1},
+	"kg":    {"g", 1000},
+	"oz":    {"g", 28.3495},
+	"lb":    {"g", 453.592},
+	"lbs":   {"g", 453.592},
+	"ml":    {"ml", 1},
+	"mlt":   {"ml", 1},
+	"l":     {"ml", 1000},
+	"cup":   {"ml", 236.588},
+	"cups":  {"ml", 236.588},
+	"tbsp":  {"ml", 14.787},
+	"tsp":   {"ml", 4.929},
+}
+
+// quickSeparators splits a quick log into its foods.
+var quickSeparators = regexp.MustCompile(`\s*(?:,|\+|\band\b)\s*`)
+
+// quickQuantity matches a quantity and any unit attached to it, such as
+// "2", "1/2", "1.5", or "40g".
+var quickQuantity = regexp.MustCompile(`^(\d+(?:\.\d+)?|\d+/\d+)([a-z]*)$`)
+
+// ParseQuickLog parses foods separated by commas, "+", or "and", such
+// as "2 eggs and 40g oats".
+func ParseQuickLog(s string) ([]QuickItem, error) {
+	var items []QuickItem
+	for _, part := range quickSeparators.Split(strings.TrimSpace(s), -1) {
+		if part == "" {
+			continue
+		}
+		it, err := ParseQuickItem(part)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no foods in %q", s)
+	}
+	return items, nil
+}
+
+// ParseQuickItem parses a food in "qty unit name" form. The unit is
+// optional, in which case the quantity is a number of servings, and
+// so is the quantity, which defaults to a single serving.
+func ParseQuickItem(s string) (QuickItem, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	it := QuickItem{Quantity: 1}
+	if len(fields) == 0 {
+		return it, fmt.Errorf("missing food")
+	}
+
+	m := quickQuantity.FindStringSubmatch(fields[0])
+	switch {
+	case m != nil:
+		q, err := parseQuantity(m[1])
+		if err != nil {
+			return it, fmt.Errorf("invalid quantity in %q: %v", s, err)
+		}
+		it.Quantity = q
+		fields = fields[1:]
+		if m[2] != "" {
+			it.Unit = m[2]
+		} else if len(fields) > 1 {
+			if _, ok := units[fields[0]]; ok {
+				it.Unit = fields[0]
+				fields = fields[1:]
+			}
+		}
+	case fields[0] == "a" || fields[0] == "an":
+		fields = fields[1:]
+	}
+
+	if it.Unit != "" {
+		if _, ok := units[it.Unit]; !ok {
+			return it, fmt.Errorf("unknown unit %q in %q", it.Unit, s)
+		}
+	}
+	if len(fields) > 0 && fields[0] == "of" {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && (fields[0] == "serving" || fields[0] == "servings") {
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "of" {
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 1 && fields[0] == "cooked" {
+		it.Cooked = true
+		fields = fields[1:]
+	}
+	it.Name = strings.Join(fields, " ")
+	if it.Name == "" {
+		return it, fmt.Errorf("missing food in %q", s)
+	}
+	if it.Quantity <= 0 {
+		return it, fmt.Errorf("quantity must be positive in %q", s)
+	}
+	return it, nil
+}
+
+// parseQuantity parses a decimal or a fraction such as "1/2".
+func parseQuantity(s string) (float64, error) {
+	if n, d, ok := strings.Cut(s, "/"); ok {
+		num, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, err
+		}
+		den, err := strconv.ParseFloat(d, 64)
+		if err != nil || den == 0 {
+			return 0, fmt.Errorf("invalid fraction %q", s)
+		}
+		return num / den, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// convertUnit converts a quantity from one unit to another of the same
+// dimension.
+func convertUnit(qty float64, from, to string) (float64, error) {
+	f, ok := units[strings.ToLower(from)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", from)
+	}
+	t, ok := units[strings.ToLower(to)]
+	if !ok || f.base != t.base {
+		return 0, fmt.Errorf("can't convert %s to %s", from, to)
+	}
+	return qty * f.factor / t.factor, nil
+}
+
+// applyQuickItem sets the serving of the food to the amount of the
+// item. Without a unit, the item's quantity is the number of servings
+// of the food's default serving size.
+func applyQuickItem(f *Food, it QuickItem) error {
+	if it.Unit == "" {
+		ScaleServings(f, f.ServingSize, it.Quantity)
+		return nil
+	}
+	size, err := convertUnit(it.Quantity, it.Unit, f.ServingUnit)
+	if err != nil {
+		return fmt.Errorf("couldn't measure %q: %v", f.Name, err)
+	}
+	ScaleServings(f, size, 1)
+	return nil
+}
+
+// singular returns the word without a plural ending.
+func singular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies"):
+		return strings.TrimSuffix(s, "ies") + "y"
+	case strings.HasSuffix(s, "oes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return strings.TrimSuffix(s, "es")
+	case strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss"):
+		return strings.TrimSuffix(s, "s")
+	}
+	return s
+}
+
+// matchFood returns the food the name refers to when there's no doubt:
+// the only result, or the only one whose name is the given name.
+func matchFood(foods []Food, name string) (Food, bool) {
+	if len(foods) == 1 {
+		return foods[0], true
+	}
+	var match []Food
+	for _, f := range foods {
+		n := strings.ToLower(f.Name)
+		if n == name || n == singular(name) {
+			match = append(match, f)
+		}
+	}
+	if len(match) == 1 {
+		return match[0], true
+	}
+	return Food{}, false
+}
+
+// searchQuickFood searches for the foods matching the name, falling
+// back to its singular form.
+func searchQuickFood(db *sqlx.DB, name string) ([]Food, error) {
+	foods, err := SearchFoods(db, name)
+	if err != nil {
+		return nil, err
+	}
+	if s := singular(name); len(foods) == 0 && s != name {
+		return SearchFoods(db, s)
+	}
+	return foods, nil
+}
+
+// resolveQuickFood finds the food the name refers to. When the name
+// matches no food or more than one, it asks the user to pick one or to
+// search again.
+func resolveQuickFood(db *sqlx.DB, name string) (Food, error) {
+	foods, err := searchQuickFood(db, name)
+	if err != nil {
+		return Food{}, err
+	}
+	if f, ok := matchFood(foods, name); ok {
+		return f, nil
+	}
+
+	for {
+		if len(foods) == 0 {
+			fmt.Printf("No foods match %q.\n", name)
+			imported, ok, err := searchRemoteFoods(db, name)
+			if err != nil {
+				fmt.Println(err)
+			}
+			if ok {
+				name = imported
+			} else {
+				name = promptSelectEntry("Enter a search term")
+			}
+			if foods, err = searchQuickFood(db, name); err != nil {
+				return Food{}, err
+			}
+			if f, ok := matchFood(foods, name); ok {
+				return f, nil
+			}
+			continue
+		}
+
+		fmt.Printf("Which food is %q?\n", name)
+		for i, f := range foods {
+			brandDetail := ""
+			if f.BrandName != "" {
+				brandDetail = " (Brand: " + f.BrandName + ")"
+			}
+			fmt.Printf("[%d] %s%s\n", i+1, f.Name, brandDetail)
+		}
+		response := promptSelectResponse("food")
+		idx, err := strconv.Atoi(response)
+		if err != nil {
+			name = response
+			if foods, err = searchQuickFood(db, name); err != nil {
+				return Food{}, err
+			}
+			continue
+		}
+		if idx < 1 || idx > len(foods) {
+			fmt.Println("Number must be between 0 and number of foods. Please try again.")
+			continue
+		}
+		return foods[idx-1], nil
+	}
+}
+
+// resolveQuickItems finds the food of each item and sets its serving to
+// the item's amount.
+func resolveQuickItems(db *sqlx.DB, items []QuickItem) ([]Food, error) {
+	foods := make([]Food, len(items))
+	for i, it := range items {
+		f, err := resolveQuickFood(db, it.Name)
+		if err != nil {
+			return nil, err
+		}
+		if it, err = applyCooked(db, f, it); err != nil {
+			return nil, err
+		}
+		if err := applyQuickItem(&f, it); err != nil {
+			return nil, err
+		}
+		foods[i] = f
+	}
+	return foods, nil
+}
+
+// QuickLog parses the foods in the text, such as "2 eggs and 40g
+// oats", finds each in the food database, and logs them all at the
+// given date.
+func QuickLog(db *sqlx.DB, text string, date time.Time) error {
+	items, err := ParseQuickLog(text)
+	if err != nil {
+		return err
+	}
+	foods, err := resolveQuickItems(db, items)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := AddFoodEntries(tx, foods, date); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, f := range foods {
+		fmt.Printf("Logged %s (%.0f cals).\n", quickLabel(f), f.Calories)
+	}
+	return warnRestricted(db, foods)
+}
+
+// quickLabel describes the serving of a food, such as "2 x 50 g Egg".
+func quickLabel(f Food) string {
+	size := math.Round(f.ServingSize*100) / 100
+	if f.NumberOfServings == 1 {
+		return fmt.Sprintf("%g %s %s", size, f.ServingUnit, f.Name)
+	}
+	return fmt.Sprintf("%g x %g %s %s", f.NumberOfServings, size, f.ServingUnit, f.Name)
+}
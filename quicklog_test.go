@@ -0,0 +1,50 @@
+package bite
+
+import (
+	"fmt"
+)
+
+func ExampleParseQuickLog() {
+	items, err := ParseQuickLog("2 eggs and 40g oats, 1/2 cup of milk + an apple")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, it := range items {
+		fmt.Printf("%g %q %q\n", it.Quantity, it.Unit, it.Name)
+	}
+
+	_, err = ParseQuickLog(", and")
+	fmt.Println(err)
+	_, err = ParseQuickLog("40xyz oats")
+	fmt.Println(err)
+
+	// Output:
+	// 2 "" "eggs"
+	// 40 "g" "oats"
+	// 0.5 "cup" "milk"
+	// 1 "" "apple"
+	// no foods in ", and"
+	// unknown unit "xyz" in "40xyz oats"
+}
+
+func ExampleApplyQuickItem() {
+	egg := Food{Name: "Egg", ServingUnit: "g", ServingSize: 50, NumberOfServings: 1, Calories: 72, FoodMacros: &FoodMacros{}}
+	oats := Food{Name: "Oats", ServingUnit: "g", ServingSize: 100, NumberOfServings: 1, Calories: 380, FoodMacros: &FoodMacros{}}
+
+	applyQuickItem(&egg, QuickItem{Quantity: 2, Name: "eggs"})
+	applyQuickItem(&oats, QuickItem{Quantity: 1, Unit: "oz", Name: "oats"})
+	fmt.Printf("%s (%.0f cals)\n", quickLabel(egg), egg.Calories)
+	fmt.Printf("%s (%.0f cals)\n", quickLabel(oats), oats.Calories)
+
+	f, ok := matchFood([]Food{{Name: "Egg"}, {Name: "Egg white"}}, "eggs")
+	fmt.Println(f.Name, ok)
+
+	fmt.Println(applyQuickItem(&oats, QuickItem{Quantity: 1, Unit: "cup", Name: "oats"}))
+
+	// Output:
+	// 2 x 50 g Egg (144 cals)
+	// 28.35 g Oats (108 cals)
+	// Egg true
+	// couldn't measure "Oats": can't convert cup to g
+}
@@ -0,0 +1,194 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DayTotals is the nutrition eaten on a day and the day's calorie goal.
+type DayTotals struct {
+	Date time.Time `db:"date"`
+	Nutrition
+	Goal float64
+	// GlycemicLoad is the day's glycemic load, set by
+	// MarkGlycemicLoads. Zero means none of the day's foods has a
+	// glycemic index.
+	GlycemicLoad float64 `db:"-"`
+}
+
+// RangeSummary summarizes the food log from From to To, inclusive.
+type RangeSummary struct {
+	From time.Time
+	To   time.Time
+	Days []DayTotals // Days with logged foods, oldest first.
+}
+
+// Total returns the nutrition eaten over the range.
+func (s *RangeSummary) Total() Nutrition {
+	var t Nutrition
+	for _, d := range s.Days {
+		t.Calories += d.Calories
+		t.Protein += d.Protein
+		t.Fat += d.Fat
+		t.Carbs += d.Carbs
+		t.Price += d.Price
+	}
+	return t
+}
+
+// Average returns the nutrition eaten on an average logged day.
+func (s *RangeSummary) Average() Nutrition {
+	t := s.Total()
+	n := float64(len(s.Days))
+	if n == 0 {
+		return t
+	}
+	return Nutrition{
+		Calories: t.Calories / n,
+		Protein:  t.Protein / n,
+		Fat:      t.Fat / n,
+		Carbs:    t.Carbs / n,
+		Price:    t.Price / n,
+	}
+}
+
+// GlycemicLoad returns the average glycemic load of the days with one,
+// and how many days had one.
+func (s *RangeSummary) GlycemicLoad() (float64, int) {
+	var total float64
+	var days int
+	for _, d := range s.Days {
+		if d.GlycemicLoad > 0 {
+			total += d.GlycemicLoad
+			days++
+		}
+	}
+	if days == 0 {
+		return 0, 0
+	}
+	return total / float64(days), days
+}
+
+// BestDay returns the day closest to its calorie goal, or nil without
+// logged days.
+func (s *RangeSummary) BestDay() *DayTotals {
+	return s.dayBy(func(off, best float64) bool { return off < best })
+}
+
+// WorstDay returns the day furthest from its calorie goal.
+func (s *RangeSummary) WorstDay() *DayTotals {
+	return s.dayBy(func(off, worst float64) bool { return off > worst })
+}
+
+// dayBy returns the first day whose distance from its calorie goal is
+// preferred over every other day's.
+func (s *RangeSummary) dayBy(prefer func(off, cur float64) bool) *DayTotals {
+	var day *DayTotals
+	for i := range s.Days {
+		d := &s.Days[i]
+		if day == nil || prefer(math.Abs(d.Calories-d.Goal), math.Abs(day.Calories-day.Goal)) {
+			day = d
+		}
+	}
+	return day
+}
+
+// DietRange returns the summary of the food log from from to to,
+// inclusive. Each day's calorie goal is the phase's goal for a training
+// or rest day, or maintenance calories without an active phase.
+func DietRange(tx *sqlx.Tx, u *UserInfo, from, to time.Time) (*RangeSummary, error) {
+	totals, err := dailyTotals(tx)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT date, calories, protein, fat, carbs, price
+		FROM %s
+		WHERE date >= $1 AND date <= $2
+		ORDER BY date
+	`, totals)
+	s := &RangeSummary{From: dateOf(from), To: dateOf(to)}
+	err = tx.Select(&s.Days, query, s.From.Format(dateFormat), s.To.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get daily totals: %v", err)
+	}
+
+	for i, d := range s.Days {
+		if s.Days[i].Goal, err = dayGoal(tx, u, d.Date); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// MarkGlycemicLoads sets GlycemicLoad on the days of the summary.
+func MarkGlycemicLoads(tx *sqlx.Tx, s *RangeSummary) error {
+	loads, err := glycemicLoads(tx, s.From, s.To)
+	if err != nil {
+		return err
+	}
+	for i, d := range s.Days {
+		s.Days[i].GlycemicLoad = loads[d.Date.Format(dateFormat)].Load
+	}
+	return nil
+}
+
+// dayGoal returns the calorie goal of the day: the phase's goal in
+// effect on the day while a phase is active, and maintenance calories
+// otherwise.
+func dayGoal(tx *sqlx.Tx, u *UserInfo, date time.Time) (float64, error) {
+	if u.Phase.Status != "active" {
+		return u.TDEE, nil
+	}
+	training, err := trainedOn(tx, date)
+	if err != nil {
+		return 0, err
+	}
+	w, err := userOn(tx, u, date)
+	if err != nil {
+		return 0, err
+	}
+	return DayGoalCalories(w, training), nil
+}
+
+// PrintRangeSummary prints the averages, totals, best and worst days,
+// and macro distribution of the summary.
+func PrintRangeSummary(s *RangeSummary) {
+	fmt.Printf("Diet summary from %s to %s\n", s.From.Format(dateFormat), s.To.Format(dateFormat))
+	days := int(s.To.Sub(s.From).Hours()/24) + 1
+	fmt.Printf("Days logged: %d of %d\n", len(s.Days), days)
+	if len(s.Days) == 0 {
+		return
+	}
+
+	avg, total := s.Average(), s.Total()
+	fmt.Printf("\n%-10s %10s %10s %10s %10s %10s\n", "", "Calories", "Protein", "Carbs", "Fat", "Price")
+	fmt.Printf("%-10s %10.0f %10s %10s %10s %10.2f\n", "Average", avg.Calories,
+		FormatMacro(avg.Protein), FormatMacro(avg.Carbs), FormatMacro(avg.Fat), avg.Price)
+	fmt.Printf("%-10s %10.0f %10s %10s %10s %10.2f\n", "Total", total.Calories,
+		FormatMacro(total.Protein), FormatMacro(total.Carbs), FormatMacro(total.Fat), total.Price)
+
+	best, worst := s.BestDay(), s.WorstDay()
+	fmt.Printf("\nBest day:  %s, %.0f calories (goal %.0f)\n", best.Date.Format(dateFormat), best.Calories, best.Goal)
+	fmt.Printf("Worst day: %s, %.0f calories (goal %.0f)\n", worst.Date.Format(dateFormat), worst.Calories, worst.Goal)
+
+	fmt.Printf("\nMacros: %s\n", FormatMacroSplit(total.Protein, total.Carbs, total.Fat))
+	if gl, days := s.GlycemicLoad(); days > 0 {
+		fmt.Printf("Glycemic load: %.0f a day (%s), over the %d days with glycemic indexes\n",
+			gl, GlycemicLoad{Load: gl}.Level(), days)
+	}
+}
+
+// MonthRange returns the first day of t's month and t's date.
+func MonthRange(t time.Time) (time.Time, time.Time) {
+	t = dateOf(t)
+	return t.AddDate(0, 0, 1-t.Day()), t
+}
+
+// WeekRange returns the first day of t's week and t's date.
+func WeekRange(t time.Time) (time.Time, time.Time) {
+	return startOfWeek(t), dateOf(t)
+}
@@ -0,0 +1,50 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleDietRange() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs, price) VALUES
+			(1, '2023-12-31', '12:00:00', 40, 3000, 150, 100, 375, 10),
+			(1, '2024-01-01', '08:00:00', 40, 1200, 100, 40, 110, 4),
+			(2, '2024-01-01', '12:00:00', 100, 800, 50, 20, 105, 3),
+			(1, '2024-01-02', '08:00:00', 40, 2500, 150, 80, 290, 8),
+			(1, '2024-01-04', '08:00:00', 40, 1500, 100, 50, 160, 5);
+		INSERT INTO daily_training (date, time, type, duration) VALUES ('2024-01-02', '18:00:00', 'weights', 60);
+	`)
+	defer db.Close()
+
+	u := &UserInfo{TDEE: 2500}
+	u.Phase.Status = "active"
+	u.Phase.GoalCalories = 2000
+	u.Phase.TrainingCalories = 500
+	u.Phase.TrainingDays = 1
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, err := DietRange(tx, u, from, from.AddDate(0, 0, 6))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	PrintRangeSummary(s)
+
+	// Output:
+	// Diet summary from 2024-01-01 to 2024-01-07
+	// Days logged: 3 of 7
+	//
+	//              Calories    Protein      Carbs        Fat      Price
+	// Average          2000      133.3      221.7       63.3       6.67
+	// Total            6000      400.0      665.0      190.0      20.00
+	//
+	// Best day:  2024-01-02, 2500 calories (goal 2500)
+	// Worst day: 2024-01-04, 1500 calories (goal 1917)
+	//
+	// Macros: P 27% / C 45% / F 29% of calories
+}
@@ -0,0 +1,186 @@
+package bite
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Nutrition is the calories, macros, and price of a food entry.
+type Nutrition struct {
+	Calories float64 `db:"calories"`
+	Protein  float64 `db:"protein"`
+	Fat      float64 `db:"fat"`
+	Carbs    float64 `db:"carbs"`
+	Price    float64 `db:"price"`
+}
+
+// differs reports whether any value of the nutrition differs from
+// another's by more than rounding.
+func (n Nutrition) differs(o Nutrition) bool {
+	const eps = 0.005
+	return math.Abs(n.Calories-o.Calories) > eps || math.Abs(n.Protein-o.Protein) > eps ||
+		math.Abs(n.Fat-o.Fat) > eps || math.Abs(n.Carbs-o.Carbs) > eps ||
+		math.Abs(n.Price-o.Price) > eps
+}
+
+// times returns the nutrition scaled by r.
+func (n Nutrition) times(r float64) Nutrition {
+	return Nutrition{
+		Calories: n.Calories * r,
+		Protein:  n.Protein * r,
+		Fat:      n.Fat * r,
+		Carbs:    n.Carbs * r,
+		Price:    n.Price * r,
+	}
+}
+
+// foodNutrition returns the current nutrition of a PortionSize portion
+// of the food.
+func foodNutrition(db *sqlx.DB, foodID int) (Nutrition, error) {
+	const query = `
+		SELECT
+			COALESCE((SELECT amount FROM food_nutrients
+				WHERE food_id = f.food_id AND nutrient_id = 1008 LIMIT 1), 0) AS calories,
+			COALESCE(f.cost, 0) AS price
+		FROM foods f
+		WHERE f.food_id = $1
+	`
+	var n Nutrition
+	if err := db.Get(&n, query, foodID); err != nil {
+		return Nutrition{}, fmt.Errorf("couldn't get food %d: %v", foodID, err)
+	}
+	m, err := foodMacros(db, foodID)
+	if err != nil {
+		return Nutrition{}, err
+	}
+	n.Protein, n.Fat, n.Carbs = m.Protein, m.Fat, m.Carbs
+	return n, nil
+}
+
+// EntryRecalc is a food entry whose stored nutrition differs from the
+// current data of its food.
+type EntryRecalc struct {
+	ID               int       `db:"id"`
+	Date             time.Time `db:"date"`
+	ServingSize      float64   `db:"serving_size"`
+	NumberOfServings float64   `db:"number_of_servings"`
+	Old              Nutrition
+	New              Nutrition
+}
+
+// RecalcEntries recomputes the nutrition of the food's entries logged
+// on or after from using the food's current nutrients and cost, and
+// returns the entries whose stored nutrition is out of date. The logged
+// serving of each entry is kept.
+func RecalcEntries(db *sqlx.DB, foodID int, from time.Time) ([]EntryRecalc, error) {
+	per, err := foodNutrition(db, foodID)
+	if err != nil {
+		return nil, err
+	}
+
+	const entriesSQL = `
+		SELECT id, date, serving_size, number_of_servings,
+			calories, protein, fat, carbs, COALESCE(price, 0) AS price
+		FROM daily_foods
+		WHERE food_id = $1 AND date >= $2
+		ORDER BY date, time, id
+	`
+	var rows []struct {
+		ID               int       `db:"id"`
+		Date             time.Time `db:"date"`
+		ServingSize      float64   `db:"serving_size"`
+		NumberOfServings float64   `db:"number_of_servings"`
+		Nutrition
+	}
+	if err := db.Select(&rows, entriesSQL, foodID, from.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get food entries: %v", err)
+	}
+
+	var rs []EntryRecalc
+	for _, row := range rows {
+		n := per.times(row.ServingSize / PortionSize * row.NumberOfServings)
+		if !n.differs(row.Nutrition) {
+			continue
+		}
+		rs = append(rs, EntryRecalc{
+			ID:               row.ID,
+			Date:             row.Date,
+			ServingSize:      row.ServingSize,
+			NumberOfServings: row.NumberOfServings,
+			Old:              row.Nutrition,
+			New:              n,
+		})
+	}
+	return rs, nil
+}
+
+// ApplyRecalc saves the recomputed nutrition of the entries.
+func ApplyRecalc(tx *sqlx.Tx, rs []EntryRecalc) error {
+	const query = `
+		UPDATE daily_foods
+		SET calories = $1, protein = $2, fat = $3, carbs = $4, price = $5
+		WHERE id = $6
+	`
+	for _, r := range rs {
+		n := r.New
+		if _, err := tx.Exec(query, n.Calories, n.Protein, n.Fat, n.Carbs, n.Price, r.ID); err != nil {
+			return fmt.Errorf("couldn't update food entry %d: %v", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// printRecalc prints the old and new nutrition of each entry.
+func printRecalc(rs []EntryRecalc) {
+	fmt.Printf("%-10s %-14s %-16s %-16s %-16s %s\n", "Date", "Serving", "Calories", "Protein", "Carbs", "Fat")
+	for _, r := range rs {
+		fmt.Printf("%-10s %-14s %-16s %-16s %-16s %s\n",
+			r.Date.Format(dateFormat),
+			fmt.Sprintf("%g x %g", r.NumberOfServings, r.ServingSize),
+			fmt.Sprintf("%.0f -> %.0f", r.Old.Calories, r.New.Calories),
+			FormatMacro(r.Old.Protein)+" -> "+FormatMacro(r.New.Protein),
+			FormatMacro(r.Old.Carbs)+" -> "+FormatMacro(r.New.Carbs),
+			FormatMacro(r.Old.Fat)+" -> "+FormatMacro(r.New.Fat))
+	}
+}
+
+// RecalcFood previews the entries of the food, logged on or after from,
+// whose nutrition is out of date and, once the user confirms through r,
+// updates them. With confirmed set, it updates them without asking.
+func RecalcFood(db *sqlx.DB, r io.Reader, foodID int, from time.Time, confirmed bool) error {
+	rs, err := RecalcEntries(db, foodID, from)
+	if err != nil {
+		return err
+	}
+	if len(rs) == 0 {
+		fmt.Println("All entries are up to date.")
+		return nil
+	}
+	printRecalc(rs)
+
+	if !confirmed {
+		fmt.Printf("Update %d entries? (y/n): ", len(rs))
+		s, _ := bufio.NewReader(r).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(s)); a != "y" && a != "yes" {
+			fmt.Println("Nothing updated.")
+			return nil
+		}
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := ApplyRecalc(tx, rs); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %d entries.\n", len(rs))
+	return tx.Commit()
+}
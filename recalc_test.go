@@ -0,0 +1,47 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleRecalcEntries() {
+	db := dbtest.MustNew(`
+		-- The corrected nutrients per 100g.
+		DELETE FROM food_nutrients WHERE food_id = 1;
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(1, 1008, 380, 71), (1, 1003, 13, 71), (1, 1004, 7, 71), (1, 1005, 67, 71);
+
+		-- Logged with the old nutrients, except for the last entry.
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs, price) VALUES
+			(1, '2024-01-01', '08:00:00', 40, 1, 140, 5, 3, 25, 0.2),
+			(1, '2024-01-05', '08:00:00', 40, 2, 280, 10, 6, 50, 0.4),
+			(1, '2024-01-06', '08:00:00', 50, 1, 190, 6.5, 3.5, 33.5, 0.25);
+	`)
+	defer db.Close()
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	rs, err := RecalcEntries(db, 1, from)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	printRecalc(rs)
+
+	tx := db.MustBegin()
+	if err := ApplyRecalc(tx, rs); err != nil {
+		fmt.Println(err)
+		return
+	}
+	tx.Commit()
+
+	rs, _ = RecalcEntries(db, 1, from)
+	fmt.Println(len(rs))
+
+	// Output:
+	// Date       Serving        Calories         Protein          Carbs            Fat
+	// 2024-01-05 2 x 40         280 -> 304       10.0 -> 10.4     50.0 -> 53.6     6.0 -> 5.6
+	// 0
+}
@@ -0,0 +1,352 @@
+package bite
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ReceiptItem is a line item of a store receipt.
+type ReceiptItem struct {
+	Name  string
+	Price float64
+	// Quantity and Unit are the size of the item, such as 1 kg, when the
+	// receipt gives it. Unit is empty when it doesn't.
+	Quantity float64
+	Unit     string
+}
+
+var (
+	// receiptPrice matches the price of a line item, such as "3.49" or
+	// "$3.49". Receipts print cents, which tells prices apart from
+	// quantities.
+	receiptPrice = regexp.MustCompile(`^\$?(\d+\.\d{2})$`)
+	// receiptSize matches the size of a line item, such as "1kg" or
+	// "500".
+	receiptSize = regexp.MustCompile(`^(\d+(?:\.\d+)?)([a-z]*)$`)
+)
+
+// receiptSkip are the first words of receipt lines that aren't items.
+var receiptSkip = []string{"subtotal", "total", "tax", "change", "cash", "card", "balance", "item"}
+
+// ParseReceipt parses the line items of a store receipt, one a line,
+// either as CSV or as text such as "OATS 1KG 3.49". Each item has a
+// name and a price, and optionally a size. Lines without a price, such
+// as the store's name, and totals are skipped.
+func ParseReceipt(r io.Reader) ([]ReceiptItem, error) {
+	var items []ReceiptItem
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		it, ok, err := parseReceiptLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if ok {
+			items = append(items, it)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read receipt: %v", err)
+	}
+	return items, nil
+}
+
+// parseReceiptLine parses a line of a receipt. It returns ok false when
+// the line isn't a line item.
+func parseReceiptLine(line string) (ReceiptItem, bool, error) {
+	var it ReceiptItem
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return it, false, nil
+	}
+
+	var tokens []string
+	if strings.Contains(line, ",") {
+		fields, err := csv.NewReader(strings.NewReader(line)).Read()
+		if err != nil {
+			return it, false, fmt.Errorf("invalid CSV: %v", err)
+		}
+		for _, f := range fields {
+			tokens = append(tokens, strings.Fields(f)...)
+		}
+	} else {
+		tokens = strings.Fields(line)
+	}
+	for i := range tokens {
+		tokens[i] = strings.ToLower(tokens[i])
+	}
+
+	// The price is the last amount with cents. Anything after it, such
+	// as a tax code, is dropped.
+	price := -1
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if receiptPrice.MatchString(tokens[i]) {
+			price = i
+			break
+		}
+	}
+	if price < 1 {
+		return it, false, nil
+	}
+	it.Price, _ = strconv.ParseFloat(receiptPrice.FindStringSubmatch(tokens[price])[1], 64)
+	tokens = tokens[:price]
+	for _, w := range receiptSkip {
+		if tokens[0] == w {
+			return it, false, nil
+		}
+	}
+
+	var name []string
+	for i := 0; i < len(tokens); i++ {
+		m := receiptSize.FindStringSubmatch(tokens[i])
+		if it.Unit != "" || m == nil {
+			name = append(name, tokens[i])
+			continue
+		}
+		unit := m[2]
+		if unit == "" && i+1 < len(tokens) {
+			unit = tokens[i+1]
+			if _, ok := units[unit]; ok {
+				i++
+			}
+		}
+		if _, ok := units[unit]; !ok {
+			name = append(name, tokens[i])
+			continue
+		}
+		it.Quantity, _ = strconv.ParseFloat(m[1], 64)
+		it.Unit = unit
+	}
+	if len(name) == 0 {
+		return it, false, fmt.Errorf("item has no name")
+	}
+	it.Name = strings.Join(name, " ")
+	return it, true, nil
+}
+
+// priceHistorySchema creates the price_history table in databases made
+// before it existed.
+const priceHistorySchema = `
+	CREATE TABLE IF NOT EXISTS price_history (
+		id INTEGER PRIMARY KEY,
+		food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE NOT NULL,
+		date DATE NOT NULL,
+		item TEXT NOT NULL,
+		price REAL NOT NULL,
+		quantity REAL NOT NULL,
+		cost REAL NOT NULL
+	)
+`
+
+// addPriceHistoryTable creates the price_history table if it doesn't
+// exist, along with its audit triggers.
+func addPriceHistoryTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(priceHistorySchema); err != nil {
+		return fmt.Errorf("couldn't create price history table: %v", err)
+	}
+	return auditTable(tx, "price_history")
+}
+
+// RecordPrice records the price paid for a quantity of a food, in its
+// serving unit, bought on the date as the receipt item, and updates the
+// food's price to match. It returns the food's new price per
+// PortionSize serving units.
+func RecordPrice(tx *sqlx.Tx, foodID int, date time.Time, item string, price, quantity float64) (float64, error) {
+	if quantity <= 0 {
+		return 0, fmt.Errorf("quantity must be greater than 0, got %v", quantity)
+	}
+	if err := addPriceHistoryTable(tx); err != nil {
+		return 0, err
+	}
+	cost := price / quantity * PortionSize
+	const query = `
+		INSERT INTO price_history (food_id, date, item, price, quantity, cost)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.Exec(query, foodID, date.Format(dateFormat), item, price, quantity, cost); err != nil {
+		return 0, fmt.Errorf("couldn't record price: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE foods SET cost = $1 WHERE food_id = $2`, cost, foodID); err != nil {
+		return 0, fmt.Errorf("couldn't update food price: %v", err)
+	}
+	return cost, nil
+}
+
+// receiptFood returns the food a receipt item was last matched to, and
+// ok false if it was never matched.
+func receiptFood(tx *sqlx.Tx, item string) (food Food, ok bool, err error) {
+	if err := addPriceHistoryTable(tx); err != nil {
+		return food, false, err
+	}
+	const query = `
+		SELECT f.* FROM foods f
+		WHERE f.food_id = (
+			SELECT food_id FROM price_history
+			WHERE item = $1
+			ORDER BY date DESC, id DESC
+			LIMIT 1
+		)
+	`
+	err = tx.Get(&food, query, item)
+	if errors.Is(err, sql.ErrNoRows) {
+		return food, false, nil
+	}
+	if err != nil {
+		return food, false, fmt.Errorf("couldn't get matched food: %v", err)
+	}
+	return food, true, nil
+}
+
+// receiptSearchTerm returns the search term of a receipt item's name:
+// its words without digits or punctuation.
+func receiptSearchTerm(name string) string {
+	var words []string
+	for _, w := range strings.Fields(nonAlnum.ReplaceAllString(name, " ")) {
+		if strings.Trim(w, "0123456789") == w {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ImportReceipt reads a store receipt and updates the prices of the
+// foods its items are matched to as of the date. Items matched before
+// are matched to the same food, and the user is asked to match the
+// others by searching the foods, or to skip them.
+func ImportReceipt(db *sqlx.DB, r io.Reader, date time.Time) error {
+	items, err := ParseReceipt(r)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println("No items found on the receipt.")
+		return nil
+	}
+
+	var updated int
+	var skipped []string
+	for _, it := range items {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		food, ok, err := receiptFood(tx, it.Name)
+		tx.Rollback()
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Printf("%q is %s.\n", it.Name, food.Name)
+		} else if food, ok, err = promptReceiptFood(db, it); err != nil {
+			return err
+		}
+		if !ok {
+			skipped = append(skipped, it.Name)
+			continue
+		}
+
+		qty, err := convertUnit(it.Quantity, it.Unit, food.ServingUnit)
+		if it.Unit == "" || err != nil {
+			qty = promptReceiptQuantity(food, it)
+		}
+		if qty == 0 {
+			skipped = append(skipped, it.Name)
+			continue
+		}
+
+		tx, err = db.Beginx()
+		if err != nil {
+			return err
+		}
+		cost, err := RecordPrice(tx, food.ID, date, it.Name, it.Price, qty)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		fmt.Printf("%s now costs $%s per %d %s.\n", food.Name, formatNumber(cost, 2), PortionSize, food.ServingUnit)
+		updated++
+	}
+
+	fmt.Printf("Updated the prices of %d foods.\n", updated)
+	if len(skipped) > 0 {
+		fmt.Printf("Skipped: %s\n", strings.Join(skipped, ", "))
+	}
+	return nil
+}
+
+// promptReceiptFood asks the user to match a receipt item to one of the
+// foods found by searching for it. It returns ok false if the user
+// skips the item.
+func promptReceiptFood(db *sqlx.DB, it ReceiptItem) (Food, bool, error) {
+	term := receiptSearchTerm(it.Name)
+	for {
+		var foods []Food
+		if term != "" {
+			var err error
+			if foods, err = SearchFoods(db, term); err != nil {
+				return Food{}, false, err
+			}
+			// Receipts shorten names, so fall back to foods matching
+			// any of the words.
+			if words := strings.Fields(term); len(foods) == 0 && len(words) > 1 {
+				if foods, err = SearchFoods(db, strings.Join(words, " OR ")); err != nil {
+					return Food{}, false, err
+				}
+			}
+		}
+		if len(foods) == 0 {
+			fmt.Printf("No foods match %q ($%s).\n", it.Name, formatNumber(it.Price, 2))
+		} else {
+			fmt.Printf("Foods matching %q ($%s):\n", it.Name, formatNumber(it.Price, 2))
+			for i, f := range foods {
+				fmt.Printf("[%d] %s\n", i+1, f.Name)
+			}
+		}
+
+		response := promptSelectEntry("Enter food index to match, a search term, or <Enter> to skip")
+		if response == "" {
+			return Food{}, false, nil
+		}
+		idx, err := strconv.Atoi(response)
+		if err != nil {
+			term = response
+			continue
+		}
+		if idx < 1 || idx > len(foods) {
+			fmt.Println("Number must be between 1 and number of foods. Please try again.")
+			continue
+		}
+		return foods[idx-1], true, nil
+	}
+}
+
+// promptReceiptQuantity asks the user for the quantity of the food
+// bought as the receipt item, in the food's serving unit. It returns 0
+// if the user skips the item.
+func promptReceiptQuantity(food Food, it ReceiptItem) float64 {
+	for {
+		response := promptSelectEntry(fmt.Sprintf("Enter the %s of %s bought as %q [Press <Enter> to skip]",
+			food.ServingUnit, food.Name, it.Name))
+		if response == "" {
+			return 0
+		}
+		qty, err := strconv.ParseFloat(response, 64)
+		if err != nil || qty <= 0 {
+			fmt.Println("Invalid quantity. Please try again.")
+			continue
+		}
+		return qty
+	}
+}
@@ -0,0 +1,63 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleParseReceipt() {
+	receipt := `FRESH MART #42
+OATS 1KG          3.49 F
+BANANAS 2 lb      1.18
+CHKN BREAST 900 G $7.99
+COUPON           -1.00
+SUBTOTAL         12.66
+"Greek yogurt, plain",500g,4.25
+`
+	items, err := ParseReceipt(strings.NewReader(receipt))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, it := range items {
+		fmt.Printf("%s: $%.2f (%g %s)\n", it.Name, it.Price, it.Quantity, it.Unit)
+	}
+	// Output:
+	// oats: $3.49 (1 kg)
+	// bananas: $1.18 (2 lb)
+	// chkn breast: $7.99 (900 g)
+	// greek yogurt, plain: $4.25 (500 g)
+}
+
+func ExampleRecordPrice() {
+	db := dbtest.MustNew(``)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	date := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	cost, err := RecordPrice(tx, 1, date, "oats", 3.49, 1000)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("$%.3f per 100 g\n", cost)
+
+	// The item is matched to the same food on the next receipt.
+	food, ok, err := receiptFood(tx, "oats")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(food.Name, ok, food.Price)
+	_, ok, err = receiptFood(tx, "bananas")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(ok)
+	// Output:
+	// $0.349 per 100 g
+	// Oats true 0.349
+	// false
+}
@@ -0,0 +1,108 @@
+package bite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// recheckPhase re-evaluates every week of the user's diet phase from
+// its start and returns the user with the calorie goal and macros the
+// phase would have, along with the number of calorie adjustments made.
+//
+// The weeks are replayed from the phase's starting calorie goal, the
+// calories for its weekly change, and the macros for it, so a recheck
+// only depends on the logged entries and rechecking twice gives the
+// same targets. Changes are
+// made in tx.
+func recheckPhase(tx *sqlx.Tx, u *UserInfo, entries *[]Entry) (*UserInfo, int, error) {
+	r := *u
+	r.Phase.GoalCalories = targetCalories(&r)
+	r.Macros.Protein, r.Macros.Carbs, r.Macros.Fats = calculateMacros(&r)
+	r.Phase.LastCheckedWeek = r.Phase.StartDate
+
+	// The adjustments are all replayed.
+	if err := deleteAdjustments(tx, r.Phase.PhaseID); err != nil {
+		return nil, 0, err
+	}
+
+	adjustments := 0
+	for {
+		last := r.Phase.LastCheckedWeek
+		adjusted, err := checkWeeklyChange(tx, &r, entries)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !adjusted {
+			break
+		}
+		adjustments++
+		// Every adjustment takes two more weeks, so the checks move past
+		// them before looking for the next one.
+		if !r.Phase.LastCheckedWeek.After(last) {
+			break
+		}
+	}
+	return &r, adjustments, nil
+}
+
+// RecheckPhase re-evaluates every week of the user's diet phase, for
+// entries edited or backfilled after their weeks were checked, and
+// prints the calorie goal changes it would make. Once the user confirms
+// through r, the changes are saved. With confirmed set, they are saved
+// without asking.
+func RecheckPhase(db *sqlx.DB, r io.Reader, u *UserInfo, entries *[]Entry, confirmed bool) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := loadDietBreaks(tx, u); err != nil {
+		return err
+	}
+	if u.Phase.ActiveBreak() != nil {
+		return errors.New("can't recheck the diet phase during a diet break")
+	}
+
+	fmt.Printf("Rechecking the %s phase from %s.\n", u.Phase.Name, u.Phase.StartDate.Format(dateFormat))
+	rechecked, adjustments, err := recheckPhase(tx, u, entries)
+	if err != nil {
+		return err
+	}
+
+	old, goal := u.Phase.GoalCalories, rechecked.Phase.GoalCalories
+	fmt.Printf("Calorie adjustments: %d.\n", adjustments)
+	m, n := u.Macros, rechecked.Macros
+	same := math.Round(old) == math.Round(goal) && math.Round(m.Protein) == math.Round(n.Protein) &&
+		math.Round(m.Carbs) == math.Round(n.Carbs) && math.Round(m.Fats) == math.Round(n.Fats)
+	if same {
+		fmt.Printf("The calorie goal stays at %.0f calories.\n", goal)
+	} else {
+		fmt.Printf("Calorie goal: %.0f -> %.0f calories (%+.0f).\n", old, goal, goal-old)
+		fmt.Printf("Macros: %sg protein, %sg carbs, %sg fats -> %sg protein, %sg carbs, %sg fats.\n",
+			FormatMacro(m.Protein), FormatMacro(m.Carbs), FormatMacro(m.Fats),
+			FormatMacro(n.Protein), FormatMacro(n.Carbs), FormatMacro(n.Fats))
+
+		if !confirmed {
+			fmt.Printf("Update the calorie goal? (y/n): ")
+			s, _ := bufio.NewReader(r).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(s)); a != "y" && a != "yes" {
+				fmt.Println("Nothing updated.")
+				return nil
+			}
+		}
+	}
+
+	// Save the last checked week along with any new goal.
+	if err := saveUserInfo(tx, rechecked); err != nil {
+		return err
+	}
+	*u = *rechecked
+	return tx.Commit()
+}
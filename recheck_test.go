@@ -0,0 +1,77 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleRecheckPhase() {
+	restore := SetClock(FixedClock(time.Date(2024, 2, 5, 12, 0, 0, 0, time.UTC)))
+	defer restore()
+
+	db := dbtest.MustNew(dbtest.User)
+	defer db.Close()
+	u, err := Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Backfilled entries of a cut that stalled at 185 lbs.
+	var entries []Entry
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for d := start; d.Before(Now()); d = d.AddDate(0, 0, 1) {
+		entries = append(entries, Entry{Date: d, UserWeight: 185, Calories: 2200})
+	}
+
+	// Rechecking is idempotent.
+	for i := 0; i < 2; i++ {
+		if err := RecheckPhase(db, strings.NewReader(""), u, &entries, true); err != nil {
+			log.Println(err)
+			return
+		}
+		fmt.Println()
+	}
+	u, err = Config(db)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Printf("%.0f %s\n", u.Phase.GoalCalories, u.Phase.LastCheckedWeek.Format(dateFormat))
+
+	if err := AdjustmentSummary(db, u); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Output:
+	// Rechecking the cut phase from 2024-01-01.
+	// Fats are below minimum limit. Taking calories from carbs and moving them to fats.
+	// Adding to caloric surplus by -500.00 calories.
+	// New calorie goal: 1700.00.
+	// Could not reach a surplus of -500.00 since the maximum fat, carb, and protein limits were met before the entire surplus could be applied.
+	// Updating caloric surplus to -340.00.
+	// New calorie goal: 1860.00.
+	// Adjusted the calorie goal from 2200 to 1860: 2 weeks at +0.0 lbs vs target -1.0 lbs ⇒ -340 calories a day, taken from carbs 30g, protein 55g. See "bite summary adjustments".
+	// Calorie adjustments: 1.
+	// Calorie goal: 2200 -> 1860 calories (-340).
+	// Macros: 150.0g protein, 250.0g carbs, 70.0g fats -> 130.0g protein, 200.0g carbs, 60.0g fats.
+	//
+	// Rechecking the cut phase from 2024-01-01.
+	// Fats are below minimum limit. Taking calories from carbs and moving them to fats.
+	// Adding to caloric surplus by -500.00 calories.
+	// New calorie goal: 1700.00.
+	// Could not reach a surplus of -500.00 since the maximum fat, carb, and protein limits were met before the entire surplus could be applied.
+	// Updating caloric surplus to -340.00.
+	// New calorie goal: 1860.00.
+	// Adjusted the calorie goal from 2200 to 1860: 2 weeks at +0.0 lbs vs target -1.0 lbs ⇒ -340 calories a day, taken from carbs 30g, protein 55g. See "bite summary adjustments".
+	// Calorie adjustments: 1.
+	// The calorie goal stays at 1860 calories.
+	//
+	// 1860 2024-01-14
+	// 2024-01-14  2200 -> 1860  2 weeks at +0.0 lbs vs target -1.0 lbs ⇒ -340 calories a day, taken from carbs 30g, protein 55g
+}
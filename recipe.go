@@ -0,0 +1,128 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Ingredient is a raw ingredient of a cooked batch.
+type Ingredient struct {
+	FoodID int
+	Grams  float64 // Raw weight.
+}
+
+// ParseIngredient parses an ingredient written as the food id and its
+// raw weight in grams, such as "12=450".
+func ParseIngredient(s string) (Ingredient, error) {
+	id, grams, ok := strings.Cut(s, "=")
+	if !ok {
+		return Ingredient{}, fmt.Errorf("ingredient must be <food-id>=<grams>, got %q", s)
+	}
+	var in Ingredient
+	var err error
+	if in.FoodID, err = strconv.Atoi(strings.TrimSpace(id)); err != nil || in.FoodID <= 0 {
+		return Ingredient{}, fmt.Errorf("invalid food id %q", id)
+	}
+	if in.Grams, err = strconv.ParseFloat(strings.TrimSpace(grams), 64); err != nil || in.Grams <= 0 {
+		return Ingredient{}, fmt.Errorf("invalid weight %q: must be positive grams", grams)
+	}
+	return in, nil
+}
+
+// Batch is a recipe cooked in bulk, such as a pot of chili, whose
+// weight changes as it cooks.
+type Batch struct {
+	Name        string
+	Ingredients []Ingredient
+	Cooked      float64 // Weight (g) of the whole batch once cooked.
+	// Servings is the number of servings the batch is split into. Zero
+	// means servings of PortionSize grams.
+	Servings int
+}
+
+// ServingSize returns the weight (g) of a serving of the cooked batch.
+func (b Batch) ServingSize() float64 {
+	if b.Servings <= 0 {
+		return PortionSize
+	}
+	return b.Cooked / float64(b.Servings)
+}
+
+// BatchNutrition returns the nutrition of the whole batch, from the raw
+// weights of its ingredients.
+func BatchNutrition(db *sqlx.DB, ingredients []Ingredient) (Nutrition, error) {
+	var total Nutrition
+	for _, in := range ingredients {
+		n, err := foodNutrition(db, in.FoodID)
+		if err != nil {
+			return Nutrition{}, err
+		}
+		n = n.times(in.Grams / PortionSize)
+		total.Calories += n.Calories
+		total.Protein += n.Protein
+		total.Fat += n.Fat
+		total.Carbs += n.Carbs
+	}
+	return total, nil
+}
+
+// PortionBatch creates a food of the cooked batch, with the nutrition of
+// its ingredients spread over its cooked weight, so that servings of it
+// can be logged by weight. It returns the id of the food.
+func PortionBatch(db *sqlx.DB, b Batch) (int, error) {
+	b.Name = strings.TrimSpace(b.Name)
+	switch {
+	case b.Name == "":
+		return 0, errors.New("batch name can't be empty")
+	case len(b.Ingredients) == 0:
+		return 0, errors.New("batch needs at least one ingredient")
+	case b.Cooked <= 0:
+		return 0, fmt.Errorf("cooked weight must be positive, got %v", b.Cooked)
+	case b.Servings < 0:
+		return 0, fmt.Errorf("servings can't be negative, got %d", b.Servings)
+	}
+
+	total, err := BatchNutrition(db, b.Ingredients)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	food := Food{Name: b.Name, ServingSize: b.ServingSize(), ServingUnit: "g"}
+	if b.Servings > 0 {
+		food.HouseholdServing = fmt.Sprintf("1/%d batch", b.Servings)
+	}
+	id, err := insertIndexedFood(tx, food)
+	if err != nil {
+		return 0, err
+	}
+	// The batch totals are stored per PortionSize grams of the cooked
+	// weight.
+	batch := RemoteFood{Name: b.Name, ServingSize: b.Cooked, Calories: total.Calories,
+		Protein: total.Protein, Fat: total.Fat, Carbs: total.Carbs}
+	if err := insertServingNutrients(tx, id, batch); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	per := total.times(PortionSize / b.Cooked)
+	fmt.Printf("Added %s (food %d): %.0f cal, %sp %sc %sf per %d g cooked.\n", b.Name, id,
+		per.Calories, FormatMacro(per.Protein), FormatMacro(per.Carbs), FormatMacro(per.Fat), PortionSize)
+	if b.Servings > 0 {
+		s := total.times(1 / float64(b.Servings))
+		fmt.Printf("A serving of %s g (1/%d of the batch): %.0f cal, %sp %sc %sf.\n", formatNumber(b.ServingSize(), 0),
+			b.Servings, s.Calories, FormatMacro(s.Protein), FormatMacro(s.Carbs), FormatMacro(s.Fat))
+	}
+	return id, nil
+}
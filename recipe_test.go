@@ -0,0 +1,53 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleParseIngredient() {
+	fmt.Println(ParseIngredient("12=450"))
+	fmt.Println(ParseIngredient("12"))
+	fmt.Println(ParseIngredient("12=-5"))
+
+	// Output:
+	// {12 450} <nil>
+	// {0 0} ingredient must be <food-id>=<grams>, got "12"
+	// {0 0} invalid weight "-5": must be positive grams
+}
+
+func ExamplePortionBatch() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	// 600 g of raw ingredients cook down to 500 g.
+	b := Batch{
+		Name:        "Oat bake",
+		Ingredients: []Ingredient{{FoodID: 1, Grams: 300}, {FoodID: 2, Grams: 300}},
+		Cooked:      500,
+		Servings:    4,
+	}
+	id, err := PortionBatch(db, b)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	total, err := BatchNutrition(db, b.Ingredients)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	per, err := foodNutrition(db, id)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Printf("batch %.0f cal, per 100 g cooked %.0f cal\n", total.Calories, per.Calories)
+
+	// Output:
+	// Added Oat bake (food 4): 332 cal, 26.4p 40.8c 6.4f per 100 g cooked.
+	// A serving of 125 g (1/4 of the batch): 416 cal, 33.0p 51.0c 8.0f.
+	// batch 1662 cal, per 100 g cooked 332 cal
+}
@@ -0,0 +1,193 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// refeedAfterWeeks is the number of consecutive compliant cut weeks
+	// after which a refeed day is recommended.
+	refeedAfterWeeks = 6
+	// dietBreakAfterWeeks is the number of consecutive compliant cut
+	// weeks after which a diet break is recommended instead.
+	dietBreakAfterWeeks = 12
+)
+
+// Recommendation is a refeed day or diet break recommended during a cut
+// and what the user chose to do about it.
+type Recommendation struct {
+	ID      int       `db:"id"`
+	PhaseID int       `db:"phase_id"`
+	Date    time.Time `db:"date"`
+	Kind    string    `db:"kind"`   // "refeed" or "break".
+	Weeks   int       `db:"weeks"`  // Consecutive compliant weeks.
+	Action  string    `db:"action"` // "scheduled" or "skipped".
+}
+
+// loadRecommendations reads the refeed and diet break recommendations
+// of the user's phase.
+func loadRecommendations(tx *sqlx.Tx, u *UserInfo) error {
+	const query = `
+		SELECT * FROM recommendations
+		WHERE phase_id = $1
+		ORDER BY date, id
+	`
+	u.Phase.Recommendations = nil
+	if err := tx.Select(&u.Phase.Recommendations, query, u.Phase.PhaseID); err != nil {
+		return fmt.Errorf("couldn't get recommendations: %v", err)
+	}
+	return nil
+}
+
+// addRecommendation records a recommendation.
+func addRecommendation(tx *sqlx.Tx, u *UserInfo, r Recommendation) error {
+	const query = `
+		INSERT INTO recommendations (phase_id, date, kind, weeks, action)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	res, err := tx.Exec(query, u.Phase.PhaseID, r.Date.Format(dateFormat), r.Kind, r.Weeks, r.Action)
+	if err != nil {
+		return fmt.Errorf("couldn't save recommendation: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	r.ID = int(id)
+	r.PhaseID = u.Phase.PhaseID
+	u.Phase.Recommendations = append(u.Phase.Recommendations, r)
+	return nil
+}
+
+// compliantWeeks counts the consecutive weeks, ending with the last
+// full week before now, in which the user logged at least
+// minEntriesPerWeek days and met the daily calorie goal. Weeks are
+// counted in 7 day steps from the phase start date, and counting starts
+// over after a diet break or a recommendation.
+func compliantWeeks(u *UserInfo, entries []Entry, now time.Time) int {
+	from := u.Phase.StartDate
+	for _, b := range u.Phase.Breaks {
+		if b.EndDate.After(from) {
+			from = b.EndDate
+		}
+	}
+	for _, r := range u.Phase.Recommendations {
+		if r.Date.After(from) {
+			from = r.Date
+		}
+	}
+
+	n := 0
+	for weekStart := u.Phase.StartDate; !weekStart.AddDate(0, 0, 7).After(now); weekStart = weekStart.AddDate(0, 0, 7) {
+		weekEnd := weekStart.AddDate(0, 0, 6)
+		if weekStart.Before(from) {
+			continue
+		}
+
+		var days []Entry
+		for _, e := range entries {
+			if !e.Date.Before(weekStart) && !e.Date.After(weekEnd) {
+				days = append(days, e)
+			}
+		}
+		if len(days) < minEntriesPerWeek || !metWeeklyCalGoal(u, days) {
+			n = 0
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// checkRefeed recommends a refeed day after refeedAfterWeeks, or a diet
+// break after dietBreakAfterWeeks, consecutive compliant weeks of a cut.
+// The user can schedule it or skip it, and the choice is recorded.
+//
+// Assumptions:
+// * The phase's diet breaks and recommendations have been loaded.
+func checkRefeed(tx *sqlx.Tx, u *UserInfo, entries []Entry, now time.Time) error {
+	if u.Phase.Name != "cut" || u.Phase.ActiveBreak() != nil {
+		return nil
+	}
+
+	n := compliantWeeks(u, entries, now)
+	if n < refeedAfterWeeks {
+		return nil
+	}
+	r := Recommendation{Date: dateOf(now), Kind: "refeed", Weeks: n, Action: "skipped"}
+	if n >= dietBreakAfterWeeks {
+		r.Kind = "break"
+	}
+
+	if getRefeedAction(r) == "1" {
+		days := 1
+		if r.Kind == "break" {
+			days = 7
+		}
+		if err := startDietBreak(tx, u, now, days); err != nil {
+			return err
+		}
+		r.Action = "scheduled"
+		fmt.Printf("Eat %.0f calories a day until %s.\n", u.Phase.GoalCalories,
+			u.Phase.ActiveBreak().EndDate.AddDate(0, 0, -1).Format(dateFormat))
+	}
+
+	return addRecommendation(tx, u, r)
+}
+
+// getRefeedAction presents the recommendation, prompts the user for
+// whether to schedule it, validates their response, and returns the
+// valid option.
+func getRefeedAction(r Recommendation) (o string) {
+	switch r.Kind {
+	case "break":
+		fmt.Printf("You've stuck to your cut for %d weeks in a row. A 1 week diet break at maintenance calories is recommended to restore energy and training performance.\n", r.Weeks)
+		fmt.Println("1. Start a diet break today.")
+	default:
+		fmt.Printf("You've stuck to your cut for %d weeks in a row. A refeed day at maintenance calories is recommended to restore energy and training performance.\n", r.Weeks)
+		fmt.Println("1. Make today a refeed day.")
+	}
+	fmt.Println("2. Skip.")
+
+	for {
+		o = promptAction()
+		if err := validateRefeedAction(o); err != nil {
+			fmt.Println(tr("Invalid action. Please try again."))
+			continue
+		}
+
+		break
+	}
+	return o
+}
+
+// validateRefeedAction validates the user's response to a
+// recommendation.
+func validateRefeedAction(o string) error {
+	if o == "1" || o == "2" {
+		return nil
+	}
+
+	return errors.New("Invalid action.")
+}
+
+// printRecommendations prints the refeed and diet break
+// recommendations of the phase.
+func printRecommendations(u *UserInfo) {
+	if len(u.Phase.Recommendations) == 0 {
+		return
+	}
+
+	fmt.Println("Recommendations:")
+	for _, r := range u.Phase.Recommendations {
+		kind := "refeed day"
+		if r.Kind == "break" {
+			kind = "diet break"
+		}
+		fmt.Printf("  %s: %s after %d compliant weeks (%s)\n", r.Date.Format(dateFormat), kind, r.Weeks, r.Action)
+	}
+}
@@ -0,0 +1,42 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleCompliantWeeks() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.GoalCalories = 2000
+	u.Phase.StartDate = start
+
+	// Eight weeks of logging, going over the goal every day of the
+	// second week.
+	var entries []Entry
+	for i := 0; i < 56; i++ {
+		cals := 1900.0
+		if i >= 7 && i < 14 {
+			cals = 2300
+		}
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), Calories: cals})
+	}
+
+	now := start.AddDate(0, 0, 56)
+	fmt.Println(compliantWeeks(&u, entries, now))
+
+	// Counting starts over after a recommendation.
+	u.Phase.Recommendations = []Recommendation{
+		{Date: start.AddDate(0, 0, 42), Kind: "refeed", Weeks: 6, Action: "skipped"},
+	}
+	fmt.Println(compliantWeeks(&u, entries, now))
+
+	printRecommendations(&u)
+
+	// Output:
+	// 6
+	// 2
+	// Recommendations:
+	//   2024-02-12: refeed day after 6 compliant weeks (skipped)
+}
@@ -0,0 +1,238 @@
+package bite
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MenuImport is the result of importing a restaurant's menu.
+type MenuImport struct {
+	Added   int // Menu items new to the database.
+	Updated int // Menu items already imported, whose nutrition was replaced.
+}
+
+// menuColumns is the index of each column of a menu file, or -1 when
+// the file doesn't have the column.
+type menuColumns struct {
+	name, serving, calories, protein, fat, carbs int
+}
+
+// nonAlnum matches the characters ignored when matching menu column
+// names.
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// parseMenuHeader finds the columns of a menu file from its header row.
+// Chains name their columns differently, e.g. "Total Fat (g)" or "Fat",
+// so only the start of each name is matched.
+func parseMenuHeader(header []string) (menuColumns, error) {
+	c := menuColumns{-1, -1, -1, -1, -1, -1}
+	for i, h := range header {
+		h = strings.TrimSpace(nonAlnum.ReplaceAllString(strings.ToLower(h), " "))
+		switch {
+		case c.name < 0 && (h == "item" || h == "name" || h == "food" ||
+			strings.HasPrefix(h, "menu item") || strings.HasPrefix(h, "item name") || strings.HasPrefix(h, "food name")):
+			c.name = i
+		case c.serving < 0 && (strings.HasPrefix(h, "serving") || strings.HasPrefix(h, "weight")):
+			c.serving = i
+		case c.calories < 0 && (strings.HasPrefix(h, "calories") && !strings.Contains(h, "fat") || strings.HasPrefix(h, "energy")):
+			c.calories = i
+		case c.protein < 0 && strings.HasPrefix(h, "protein"):
+			c.protein = i
+		case c.fat < 0 && (h == "fat" || strings.HasPrefix(h, "fat g") || strings.HasPrefix(h, "total fat")):
+			c.fat = i
+		case c.carbs < 0 && (strings.HasPrefix(h, "carb") || strings.HasPrefix(h, "total carb")):
+			c.carbs = i
+		}
+	}
+
+	var missing []string
+	for _, col := range []struct {
+		name string
+		i    int
+	}{
+		{"item name", c.name},
+		{"calories", c.calories},
+		{"protein", c.protein},
+		{"fat", c.fat},
+		{"carbs", c.carbs},
+	} {
+		if col.i < 0 {
+			missing = append(missing, col.name)
+		}
+	}
+	if len(missing) > 0 {
+		return c, fmt.Errorf("menu file has no column for %s", strings.Join(missing, ", "))
+	}
+	return c, nil
+}
+
+// parseMenuAmount parses an amount of a menu file, such as "12", "<1",
+// or "170 g". Published menus round small amounts to "<1", which is
+// taken as 1. An empty amount is 0.
+func parseMenuAmount(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "<"))
+	if s == "" || s == "-" {
+		return 0, nil
+	}
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == ',') {
+		end++
+	}
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s[:end], ",", ""), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+// ImportMenu adds the items of a restaurant's published nutrition CSV
+// file to the foods, with the restaurant as their brand and food group,
+// so that eating out can be logged like any other food. The file must
+// have a header row naming the item name, calories, protein, fat, and
+// carbs columns, and may have a serving size column in grams. Items
+// without a serving size are logged by the item. Items imported before
+// have their nutrition replaced, so the file can be imported again when
+// the menu changes.
+func ImportMenu(tx *sqlx.Tx, r io.Reader, restaurant string) (MenuImport, error) {
+	var res MenuImport
+	restaurant = strings.TrimSpace(restaurant)
+	if restaurant == "" {
+		return res, errors.New("restaurant name can't be empty")
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return res, fmt.Errorf("couldn't read menu file: %v", err)
+	}
+	cols, err := parseMenuHeader(header)
+	if err != nil {
+		return res, err
+	}
+
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return res, fmt.Errorf("couldn't read menu file: %v", err)
+		}
+		item, err := parseMenuItem(rec, cols)
+		if err != nil {
+			return res, fmt.Errorf("line %d: %v", line, err)
+		}
+		if item.Name == "" {
+			continue // Blank or section rows.
+		}
+		item.Brand = restaurant
+
+		added, err := upsertMenuItem(tx, item)
+		if err != nil {
+			return res, fmt.Errorf("line %d: %v", line, err)
+		}
+		if added {
+			res.Added++
+		} else {
+			res.Updated++
+		}
+	}
+	return res, nil
+}
+
+// parseMenuItem parses a row of a menu file. The ServingSize of an item
+// without a serving size is 0.
+func parseMenuItem(rec []string, cols menuColumns) (RemoteFood, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return rec[i]
+	}
+
+	item := RemoteFood{Name: strings.TrimSpace(field(cols.name))}
+	if item.Name == "" {
+		return item, nil
+	}
+	for _, a := range []struct {
+		name string
+		i    int
+		v    *float64
+	}{
+		{"serving size", cols.serving, &item.ServingSize},
+		{"calories", cols.calories, &item.Calories},
+		{"protein", cols.protein, &item.Protein},
+		{"fat", cols.fat, &item.Fat},
+		{"carbs", cols.carbs, &item.Carbs},
+	} {
+		v, err := parseMenuAmount(field(a.i))
+		if err != nil {
+			return item, fmt.Errorf("%s: %v", a.name, err)
+		}
+		*a.v = v
+	}
+	return item, nil
+}
+
+// upsertMenuItem adds the menu item to the foods and its restaurant's
+// food group, or replaces the serving and nutrition of the restaurant's
+// food of the same name. It reports whether the food was added.
+func upsertMenuItem(tx *sqlx.Tx, item RemoteFood) (bool, error) {
+	food := Food{
+		Name:             item.Name,
+		ServingSize:      item.ServingSize,
+		ServingUnit:      "g",
+		HouseholdServing: "1 item",
+		BrandName:        item.Brand,
+		Source:           SourceUser,
+	}
+	if item.ServingSize == 0 {
+		food.ServingSize, food.ServingUnit, food.HouseholdServing = 1, "item", ""
+		item.ServingSize = 1
+	}
+
+	var id int
+	const findSQL = `
+		SELECT food_id FROM foods
+		WHERE food_name = $1 AND brand_name = $2 AND source = $3
+		LIMIT 1
+	`
+	err := tx.Get(&id, findSQL, food.Name, food.BrandName, SourceUser)
+	added := errors.Is(err, sql.ErrNoRows)
+	switch {
+	case added:
+		if id, err = insertIndexedFood(tx, food); err != nil {
+			return false, err
+		}
+	case err != nil:
+		return false, fmt.Errorf("couldn't find %s: %v", food.Name, err)
+	default:
+		const updateSQL = `
+			UPDATE foods SET serving_size = $1, serving_unit = $2, household_serving = $3
+			WHERE food_id = $4
+		`
+		if _, err := tx.Exec(updateSQL, food.ServingSize, food.ServingUnit, food.HouseholdServing, id); err != nil {
+			return false, fmt.Errorf("couldn't update %s: %v", food.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM food_nutrients WHERE food_id = $1`, id); err != nil {
+			return false, fmt.Errorf("couldn't update nutrients of %s: %v", food.Name, err)
+		}
+	}
+
+	if err := insertServingNutrients(tx, id, item); err != nil {
+		return false, err
+	}
+	if err := SetFoodGroup(tx, id, item.Brand); err != nil {
+		return false, err
+	}
+	return added, nil
+}
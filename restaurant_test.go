@@ -0,0 +1,69 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleImportMenu() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	menu := `Category,Item,Serving Size (g),Calories,Calories from Fat,Total Fat (g),Saturated Fat (g),Total Carbohydrates (g),Protein (g)
+Burgers,Cheeseburger,119,300,110,13,6,32,15
+Sides,Small Fries,71,230,100,11,1.5,29,3
+,,,,,,,,
+Drinks,Coffee,,5,0,0,0,1,<1
+`
+	import_ := func(menu string) {
+		tx := db.MustBegin()
+		res, err := ImportMenu(tx, strings.NewReader(menu), "Burger Barn")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Added %d, updated %d\n", res.Added, res.Updated)
+	}
+	import_(menu)
+
+	// A new menu replaces the nutrition of the items.
+	import_(strings.Replace(menu, "Cheeseburger,119,300", "Cheeseburger,119,310", 1))
+
+	foods, err := SearchFoods(db, "burger barn")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range foods {
+		n, err := foodNutrition(db, f.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ratio := f.ServingSize / PortionSize
+		fmt.Printf("%s (%s): %g %s, %.0f cal, %.0fg protein\n", f.Name, f.BrandName, f.ServingSize, f.ServingUnit,
+			n.Calories*ratio, n.Protein*ratio)
+	}
+
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM food_groups WHERE group_name = 'Burger Barn'`); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Foods in the group:", n)
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	_, err = ImportMenu(tx, strings.NewReader("Item,Calories\nFries,230\n"), "Burger Barn")
+	fmt.Println(err)
+	// Output:
+	// Added 3, updated 0
+	// Added 0, updated 3
+	// Cheeseburger (Burger Barn): 119 g, 310 cal, 15g protein
+	// Coffee (Burger Barn): 1 item, 5 cal, 1g protein
+	// Small Fries (Burger Barn): 71 g, 230 cal, 3g protein
+	// Foods in the group: 3
+	// menu file has no column for protein, fat, carbs
+}
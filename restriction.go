@@ -0,0 +1,240 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// FoodFlags are the allergens and animal products a food can be flagged
+// as containing.
+var FoodFlags = []string{"gluten", "dairy", "egg", "nuts", "peanuts", "soy", "fish", "shellfish", "meat"}
+
+// diets are the flags of the foods each diet avoids.
+var diets = map[string][]string{
+	"vegan":       {"dairy", "egg", "fish", "shellfish", "meat"},
+	"vegetarian":  {"fish", "shellfish", "meat"},
+	"pescatarian": {"meat"},
+}
+
+// Restrictions are the flags of the foods the user avoids. Foods with
+// any of them are left out of suggestions and warned about when logged.
+var Restrictions []string
+
+// foodFlagsSchema creates the food_flags table in databases made before
+// it existed.
+const foodFlagsSchema = `
+	CREATE TABLE IF NOT EXISTS food_flags (
+		food_id INTEGER NOT NULL,
+		flag TEXT NOT NULL,
+		PRIMARY KEY (food_id, flag)
+	)
+`
+
+// ParseRestrictions returns the flags of the foods avoided by the
+// restrictions, each a flag such as "gluten" or a diet such as "vegan".
+func ParseRestrictions(names []string) ([]string, error) {
+	avoid := make(map[string]bool)
+	for _, name := range names {
+		name = NormalizeTag(name)
+		if flags, ok := diets[name]; ok {
+			for _, f := range flags {
+				avoid[f] = true
+			}
+			continue
+		}
+		if !isFoodFlag(name) {
+			return nil, fmt.Errorf("unknown restriction %q: must be a diet (vegan, vegetarian, pescatarian) or one of %s",
+				name, strings.Join(FoodFlags, ", "))
+		}
+		avoid[name] = true
+	}
+	flags := make([]string, 0, len(avoid))
+	for f := range avoid {
+		flags = append(flags, f)
+	}
+	sort.Strings(flags)
+	return flags, nil
+}
+
+// isFoodFlag reports whether the flag is one of FoodFlags.
+func isFoodFlag(flag string) bool {
+	for _, f := range FoodFlags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// addFoodFlagsTable creates the food_flags table if it doesn't exist,
+// along with its audit triggers.
+func addFoodFlagsTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(foodFlagsSchema); err != nil {
+		return fmt.Errorf("couldn't create food flags table: %v", err)
+	}
+	return auditTable(tx, "food_flags")
+}
+
+// SetFoodFlags flags the food as containing the flags, or removes the
+// flags from it.
+func SetFoodFlags(tx *sqlx.Tx, foodID int, flags []string, remove bool) error {
+	if err := addFoodFlagsTable(tx); err != nil {
+		return err
+	}
+	for _, flag := range flags {
+		flag = NormalizeTag(flag)
+		if !isFoodFlag(flag) {
+			return fmt.Errorf("unknown food flag %q: must be one of %s", flag, strings.Join(FoodFlags, ", "))
+		}
+		query := `INSERT OR IGNORE INTO food_flags (food_id, flag) VALUES ($1, $2)`
+		if remove {
+			query = `DELETE FROM food_flags WHERE food_id = $1 AND flag = $2`
+		}
+		if _, err := tx.Exec(query, foodID, flag); err != nil {
+			return fmt.Errorf("couldn't set food flag %q: %v", flag, err)
+		}
+	}
+	return nil
+}
+
+// foodFlagsOf returns the flags of a food in alphabetical order.
+func foodFlagsOf(tx *sqlx.Tx, foodID int) ([]string, error) {
+	if err := addFoodFlagsTable(tx); err != nil {
+		return nil, err
+	}
+	var flags []string
+	const query = `SELECT flag FROM food_flags WHERE food_id = $1 ORDER BY flag`
+	if err := tx.Select(&flags, query, foodID); err != nil {
+		return nil, fmt.Errorf("couldn't get food flags: %v", err)
+	}
+	return flags, nil
+}
+
+// RestrictedFoods returns the foods with flags the user's restrictions
+// avoid, keyed by food id, with the flags they conflict on.
+func RestrictedFoods(db *sqlx.DB) (map[int][]string, error) {
+	restricted := make(map[int][]string)
+	if len(Restrictions) == 0 {
+		return restricted, nil
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if err := addFoodFlagsTable(tx); err != nil {
+		return nil, err
+	}
+	query, args, err := sqlx.In(`SELECT food_id, flag FROM food_flags WHERE flag IN (?) ORDER BY flag`, Restrictions)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get restricted foods: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var flag string
+		if err := rows.Scan(&id, &flag); err != nil {
+			return nil, fmt.Errorf("couldn't get restricted foods: %v", err)
+		}
+		restricted[id] = append(restricted[id], flag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't get restricted foods: %v", err)
+	}
+	rows.Close()
+	return restricted, tx.Commit()
+}
+
+// withoutRestricted returns the foods without those the user's
+// restrictions avoid.
+func withoutRestricted(db *sqlx.DB, foods []Food) ([]Food, error) {
+	restricted, err := RestrictedFoods(db)
+	if err != nil || len(restricted) == 0 {
+		return foods, err
+	}
+	kept := foods[:0]
+	for _, f := range foods {
+		if _, ok := restricted[f.ID]; !ok {
+			kept = append(kept, f)
+		}
+	}
+	return kept, nil
+}
+
+// RestrictionWarning returns a warning that the food is one of the
+// restricted foods, or "" if it isn't.
+func RestrictionWarning(restricted map[int][]string, f Food) string {
+	flags, ok := restricted[f.ID]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %s contains %s, which your restrictions avoid.", f.Name, strings.Join(flags, " and "))
+}
+
+// warnRestricted prints a warning for each of the foods that conflicts
+// with the user's restrictions.
+func warnRestricted(db *sqlx.DB, foods []Food) error {
+	restricted, err := RestrictedFoods(db)
+	if err != nil {
+		return err
+	}
+	for _, f := range foods {
+		if w := RestrictionWarning(restricted, f); w != "" {
+			fmt.Println(w)
+		}
+	}
+	return nil
+}
+
+// FlagFoods lets the user select foods and flags them as containing the
+// flags, or removes the flags from them.
+func FlagFoods(db *sqlx.DB, flags []string, remove bool) error {
+	if len(flags) == 0 {
+		return errors.New("no food flags given")
+	}
+	n := 0
+	for {
+		food, err := selectFood(db)
+		if err != nil {
+			if errors.Is(err, ErrDone) {
+				break
+			}
+			return err
+		}
+
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if err := SetFoodFlags(tx, food.ID, flags, remove); err != nil {
+			tx.Rollback()
+			return err
+		}
+		current, err := foodFlagsOf(tx, food.ID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		if len(current) == 0 {
+			fmt.Printf("%q has no flags.\n", food.Name)
+		} else {
+			fmt.Printf("%q contains %s.\n", food.Name, strings.Join(current, ", "))
+		}
+		n++
+	}
+	if n == 0 {
+		fmt.Println("No food selected.")
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleParseRestrictions() {
+	flags, err := ParseRestrictions([]string{"Vegan", "gluten", "meat"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(flags)
+
+	_, err = ParseRestrictions([]string{"keto"})
+	fmt.Println(err)
+
+	// Output:
+	// [dairy egg fish gluten meat shellfish]
+	// unknown restriction "keto": must be a diet (vegan, vegetarian, pescatarian) or one of gluten, dairy, egg, nuts, peanuts, soy, fish, shellfish, meat
+}
+
+func ExampleRestrictionWarning() {
+	db := dbtest.MustNew(``)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	if err := SetFoodFlags(tx, 1, []string{"gluten"}, false); err != nil {
+		log.Fatal(err)
+	}
+	if err := SetFoodFlags(tx, 2, []string{"meat", "soy"}, false); err != nil {
+		log.Fatal(err)
+	}
+	if err := SetFoodFlags(tx, 2, []string{"soy"}, true); err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+
+	defer func(r []string) { Restrictions = r }(Restrictions)
+	Restrictions, _ = ParseRestrictions([]string{"vegetarian", "gluten"})
+	restricted, err := RestrictedFoods(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	foods := []Food{{ID: 1, Name: "Oats"}, {ID: 2, Name: "Chicken breast"}, {ID: 3, Name: "Banana"}}
+	for _, f := range foods {
+		fmt.Printf("%q\n", RestrictionWarning(restricted, f))
+	}
+
+	kept, err := withoutRestricted(db, foods)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, f := range kept {
+		fmt.Println(f.Name)
+	}
+
+	// Output:
+	// "Warning: Oats contains gluten, which your restrictions avoid."
+	// "Warning: Chicken breast contains meat, which your restrictions avoid."
+	// ""
+	// Banana
+}
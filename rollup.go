@@ -0,0 +1,109 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// rollupsSchema creates the daily_rollups table, which holds the daily
+// totals of the food log, and the triggers that keep it up to date as
+// food entries are written. Databases made before it existed get it
+// from RebuildRollups.
+const rollupsSchema = `
+	CREATE TABLE IF NOT EXISTS daily_rollups (
+		date DATE PRIMARY KEY,
+		calories REAL NOT NULL,
+		protein REAL NOT NULL,
+		fat REAL NOT NULL,
+		carbs REAL NOT NULL,
+		price REAL DEFAULT 0 NOT NULL,
+		entries INTEGER NOT NULL
+	);
+
+	CREATE TRIGGER IF NOT EXISTS daily_rollups_insert AFTER INSERT ON daily_foods
+	BEGIN
+		DELETE FROM daily_rollups WHERE date = new.date;
+		INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+		SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+		FROM daily_foods WHERE date = new.date GROUP BY date;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS daily_rollups_update
+	AFTER UPDATE OF date, calories, protein, fat, carbs, price ON daily_foods
+	BEGIN
+		DELETE FROM daily_rollups WHERE date IN (old.date, new.date);
+		INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+		SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+		FROM daily_foods WHERE date IN (old.date, new.date) GROUP BY date;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS daily_rollups_delete AFTER DELETE ON daily_foods
+	BEGIN
+		DELETE FROM daily_rollups WHERE date = old.date;
+		INSERT INTO daily_rollups (date, calories, protein, fat, carbs, price, entries)
+		SELECT date, SUM(calories), SUM(protein), SUM(fat), SUM(carbs), COALESCE(SUM(price), 0), COUNT(*)
+		FROM daily_foods WHERE date = old.date GROUP BY date;
+	END;
+`
+
+// dailyTotalsSQL adds up the food log by day. It is the fallback when
+// the rollups can't be used.
+const dailyTotalsSQL = `(
+		SELECT date, SUM(calories) AS calories, SUM(protein) AS protein,
+			SUM(fat) AS fat, SUM(carbs) AS carbs, COALESCE(SUM(price), 0) AS price,
+			COUNT(*) AS entries
+		FROM daily_foods
+		GROUP BY date
+	)`
+
+// RebuildRollups creates the daily rollups if they don't exist and
+// recomputes them from the food log. It returns the number of days
+// rolled up.
+func RebuildRollups(tx *sqlx.Tx) (int, error) {
+	if _, err := tx.Exec(rollupsSchema); err != nil {
+		return 0, fmt.Errorf("couldn't create daily rollups: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM daily_rollups`); err != nil {
+		return 0, fmt.Errorf("couldn't clear daily rollups: %v", err)
+	}
+	res, err := tx.Exec(`INSERT INTO daily_rollups SELECT * FROM ` + dailyTotalsSQL)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't roll up food log: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// dailyTotals returns the table expression of the food log's daily
+// totals: the rollups when they exist and account for every food
+// entry, or else the totals added up from the food log.
+func dailyTotals(q sqlx.Queryer) (string, error) {
+	var exists bool
+	const existsSQL = `
+		SELECT COUNT(*) > 0 FROM sqlite_master
+		WHERE type = 'table' AND name = 'daily_rollups'
+	`
+	if err := sqlx.Get(q, &exists, existsSQL); err != nil {
+		return "", fmt.Errorf("couldn't look up daily rollups: %v", err)
+	}
+	if !exists {
+		return dailyTotalsSQL, nil
+	}
+
+	var fresh bool
+	const freshSQL = `
+		SELECT (SELECT COALESCE(SUM(entries), 0) FROM daily_rollups) =
+			(SELECT COUNT(*) FROM daily_foods)
+	`
+	if err := sqlx.Get(q, &fresh, freshSQL); err != nil {
+		return "", fmt.Errorf("couldn't check daily rollups: %v", err)
+	}
+	if !fresh {
+		return dailyTotalsSQL, nil
+	}
+	return "daily_rollups", nil
+}
@@ -0,0 +1,80 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleRebuildRollups() {
+	// A database made before the food log was rolled up.
+	db := dbtest.MustNew(`
+		DROP TRIGGER daily_rollups_insert;
+		DROP TRIGGER daily_rollups_update;
+		DROP TRIGGER daily_rollups_delete;
+		DROP TABLE daily_rollups;
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(1, '2024-01-01', '08:00:00', 40, 500, 30, 20, 50),
+			(2, '2024-01-01', '12:00:00', 100, 300, 20, 10, 30),
+			(1, '2024-01-02', '08:00:00', 40, 600, 40, 20, 60);
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-01-01', '07:00:00', 180), ('2024-01-02', '07:00:00', 179.6);
+	`)
+	defer db.Close()
+
+	show := func() {
+		totals, err := dailyTotals(db)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		entries, err := AllEntries(db)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Print(totals == "daily_rollups", ":")
+		for _, e := range *entries {
+			fmt.Printf(" %s %.0f", e.Date.Format(dateFormat), e.Calories)
+		}
+		fmt.Println()
+	}
+
+	// Without rollups, the totals are added up from the food log.
+	show()
+
+	tx := db.MustBegin()
+	n, err := RebuildRollups(tx)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	tx.Commit()
+	fmt.Println("days:", n)
+	show()
+
+	// Writes to the food log keep the rollups up to date.
+	db.MustExec(`
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(3, '2024-01-02', '15:00:00', 118, 200, 10, 5, 25);
+		UPDATE daily_foods SET calories = 400 WHERE id = 1;
+		DELETE FROM daily_foods WHERE id = 2;
+	`)
+	show()
+
+	// Rollups that miss entries, such as ones written while the
+	// triggers were dropped, aren't used.
+	db.MustExec(`
+		DROP TRIGGER daily_rollups_insert;
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(3, '2024-01-01', '15:00:00', 118, 100, 5, 2, 10);
+	`)
+	show()
+
+	// Output:
+	// false: 2024-01-01 800 2024-01-02 600
+	// days: 2
+	// true: 2024-01-01 800 2024-01-02 600
+	// true: 2024-01-01 400 2024-01-02 800
+	// false: 2024-01-01 500 2024-01-02 800
+}
@@ -0,0 +1,107 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxCutRate is the fastest safe rate of weight loss, as a fraction of
+// body weight per week.
+const maxCutRate = 0.015
+
+// safetyOverridesSchema creates the safety_overrides table, which
+// records each safety check the user chose to override.
+const safetyOverridesSchema = `
+	CREATE TABLE IF NOT EXISTS safety_overrides (
+		id INTEGER PRIMARY KEY,
+		date DATE NOT NULL,
+		phase TEXT NOT NULL,
+		rule TEXT NOT NULL,
+		detail TEXT NOT NULL
+	);
+`
+
+// SafetyOverride is a safety check the user chose to override.
+type SafetyOverride struct {
+	Rule   string // "cut-rate" or "bmr-floor".
+	Detail string
+}
+
+// safetyChecks returns the safety checks that a weekly weight change and
+// a daily calorie goal fail: losing more than 1.5% of body weight a
+// week, or less for some conditions, or eating less than the BMR.
+func safetyChecks(u *UserInfo, weeklyChange, goalCalories float64) []SafetyOverride {
+	var failed []SafetyOverride
+	rate := safeCutRate(u)
+	if max := rate * u.Weight; weeklyChange < -max {
+		failed = append(failed, SafetyOverride{
+			Rule: "cut-rate",
+			Detail: fmt.Sprintf("losing %s lbs a week is faster than the safe rate of %s lbs (%s%% of body weight)",
+				FormatWeight(-weeklyChange), FormatWeight(max), formatNumber(rate*100, 1)),
+		})
+	}
+	if bmr := BMR(u); goalCalories < bmr {
+		failed = append(failed, SafetyOverride{
+			Rule:   "bmr-floor",
+			Detail: fmt.Sprintf("%.0f calories a day is below your BMR of %.0f calories", goalCalories, bmr),
+		})
+	}
+	return failed
+}
+
+// confirmSafety reports whether a weekly weight change and a daily
+// calorie goal pass the safety checks, or the user explicitly confirms
+// overriding the checks they fail. Overrides are kept in u until they
+// are recorded by recordSafetyOverrides.
+func confirmSafety(u *UserInfo, weeklyChange, goalCalories float64) bool {
+	failed := safetyChecks(u, weeklyChange, goalCalories)
+	if len(failed) == 0 {
+		return true
+	}
+
+	fmt.Println(paint(colorMissed, "This plan fails a safety check:"))
+	for _, o := range failed {
+		fmt.Printf("  - %s\n", o.Detail)
+	}
+	if !promptOverride() {
+		return false
+	}
+	u.overrides = append(u.overrides, failed...)
+	return true
+}
+
+// promptOverride asks the user to type "override" to go ahead anyway.
+func promptOverride() bool {
+	fmt.Printf("Type \"override\" to continue anyway, or press Enter to go back: ")
+	r, _ := Input.ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(r), "override")
+}
+
+// recordSafetyOverrides saves the safety checks the user overrode,
+// which puts them in the audit log.
+func recordSafetyOverrides(tx *sqlx.Tx, u *UserInfo) error {
+	if len(u.overrides) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(safetyOverridesSchema); err != nil {
+		return fmt.Errorf("couldn't create safety overrides table: %v", err)
+	}
+	if err := auditTable(tx, "safety_overrides"); err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO safety_overrides (date, phase, rule, detail)
+		VALUES ($1, $2, $3, $4)
+	`
+	date := Today().Format(dateFormat)
+	for _, o := range u.overrides {
+		if _, err := tx.Exec(query, date, u.Phase.Name, o.Rule, o.Detail); err != nil {
+			return fmt.Errorf("couldn't record safety override: %v", err)
+		}
+	}
+	u.overrides = nil
+	return nil
+}
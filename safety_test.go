@@ -0,0 +1,61 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSafetyChecks() {
+	u := UserInfo{Sex: "male", Weight: 200, Height: 70, Age: 30}
+
+	// A cut of 2 lbs a week is within 1.5% of 200 lbs, but 1200
+	// calories is below the BMR.
+	for _, o := range safetyChecks(&u, -2, 1200) {
+		fmt.Println(o.Rule+":", o.Detail)
+	}
+	fmt.Println(len(safetyChecks(&u, -3.5, 2000)), len(safetyChecks(&u, -1, 2000)))
+
+	// Output:
+	// bmr-floor: 1200 calories a day is below your BMR of 1873 calories
+	// 1 0
+}
+
+func ExampleRecordSafetyOverrides() {
+	db := dbtest.MustNew()
+	defer db.Close()
+	if err := EnableAudit(db); err != nil {
+		log.Println(err)
+		return
+	}
+
+	u := UserInfo{Weight: 200}
+	u.Phase.Name = "cut"
+	u.overrides = safetyChecks(&u, -4, 3000)
+
+	tx := db.MustBegin()
+	if err := recordSafetyOverrides(tx, &u); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	entries, err := AuditLog(db, time.Now().Add(-time.Hour))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, e := range entries {
+		fmt.Println(e.Action, e.Table, e.RowID)
+	}
+	fmt.Println(len(u.overrides))
+
+	// Output:
+	// insert safety_overrides 1
+	// 0
+}
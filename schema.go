@@ -0,0 +1,144 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// tablesSchema creates the tables of setup.sql that commands expect to
+// exist, in databases made before they were added to it.
+const tablesSchema = `
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS daily_training (
+		id INTEGER PRIMARY KEY,
+		date DATE NOT NULL,
+		time TIME NOT NULL,
+		type TEXT NOT NULL,
+		duration REAL NOT NULL,
+		calories REAL DEFAULT 0 NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS daily_steps (
+		date DATE PRIMARY KEY,
+		steps INTEGER NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS checkins (
+		date DATE PRIMARY KEY,
+		hunger INTEGER NOT NULL CHECK(hunger BETWEEN 1 AND 5),
+		energy INTEGER NOT NULL CHECK(energy BETWEEN 1 AND 5),
+		sleep INTEGER NOT NULL CHECK(sleep BETWEEN 1 AND 5),
+		performance INTEGER NOT NULL CHECK(performance BETWEEN 1 AND 5),
+		notes TEXT DEFAULT '' NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS diet_breaks (
+		id INTEGER PRIMARY KEY,
+		phase_id INTEGER NOT NULL,
+		start_date DATE NOT NULL,
+		end_date DATE NOT NULL,
+		goal_calories REAL NOT NULL,
+		status TEXT NOT NULL CHECK(status IN ('active', 'completed')),
+		FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS recommendations (
+		id INTEGER PRIMARY KEY,
+		phase_id INTEGER NOT NULL,
+		date DATE NOT NULL,
+		kind TEXT NOT NULL CHECK(kind IN ('refeed', 'break')),
+		weeks INTEGER NOT NULL,
+		action TEXT NOT NULL CHECK(action IN ('scheduled', 'skipped')),
+		FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS plateaus (
+		id INTEGER PRIMARY KEY,
+		phase_id INTEGER NOT NULL,
+		date DATE NOT NULL,
+		change REAL NOT NULL,
+		action TEXT NOT NULL CHECK(action IN ('adjust_calories', 'add_steps', 'diet_break', 'continue')),
+		FOREIGN KEY (phase_id) REFERENCES phase_info(phase_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS food_groups (
+		food_id INTEGER PRIMARY KEY REFERENCES foods(food_id) ON DELETE CASCADE,
+		group_name TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS tags (
+		tag_id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE IF NOT EXISTS food_tags (
+		food_id INTEGER REFERENCES foods(food_id) ON DELETE CASCADE,
+		tag_id INTEGER REFERENCES tags(tag_id),
+		PRIMARY KEY (food_id, tag_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS meal_tags (
+		meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE,
+		tag_id INTEGER REFERENCES tags(tag_id),
+		PRIMARY KEY (meal_id, tag_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS leftovers (
+		id INTEGER PRIMARY KEY,
+		meal_id INTEGER REFERENCES meals(meal_id) ON DELETE CASCADE NOT NULL,
+		cooked_date DATE NOT NULL,
+		servings REAL NOT NULL
+	);
+` + pantrySchema + ";" + priceHistorySchema
+
+// schemaTables are the tables tablesSchema creates.
+var schemaTables = []string{
+	"settings", "daily_training", "daily_steps", "checkins", "diet_breaks",
+	"recommendations", "plateaus", "food_groups", "tags", "food_tags",
+	"meal_tags", "leftovers", "pantry", "price_history",
+}
+
+// TablesCurrent reports whether the database has all the tables of
+// setup.sql that AddTables creates, so that opening it doesn't change
+// its schema.
+func TablesCurrent(db *sqlx.DB) (bool, error) {
+	query, args, err := sqlx.In(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN (?)`, schemaTables)
+	if err != nil {
+		return false, err
+	}
+	var n int
+	if err := db.Get(&n, query, args...); err != nil {
+		return false, fmt.Errorf("couldn't get tables: %v", err)
+	}
+	return n == len(schemaTables), nil
+}
+
+// AddTables creates the tables of setup.sql that the database is
+// missing, along with their audit triggers. Databases made before a
+// table was added to setup.sql don't have it, and the commands using it
+// would fail. It does nothing if the database has them all.
+func AddTables(db *sqlx.DB) error {
+	current, err := TablesCurrent(db)
+	if err != nil || current {
+		return err
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(tablesSchema); err != nil {
+		return fmt.Errorf("couldn't create tables: %v", err)
+	}
+	for _, t := range schemaTables {
+		if err := auditTable(tx, t); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,47 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleAddTables() {
+	// A database made before the settings and leftovers tables existed.
+	db := dbtest.MustNew(`DROP TABLE settings; DROP TABLE leftovers;`)
+	defer db.Close()
+	if err := EnableAudit(db); err != nil {
+		log.Fatal(err)
+	}
+
+	current, err := TablesCurrent(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(current)
+	if err := AddTables(db); err != nil {
+		log.Fatal(err)
+	}
+	if current, err = TablesCurrent(db); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(current)
+
+	tx := db.MustBegin()
+	if err := SetSetting(tx, "keys.quit", "q"); err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+	var n int
+	if err := db.Get(&n, `SELECT COUNT(*) FROM audit_log WHERE table_name = 'settings'`); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+	// Output:
+	// false
+	// true
+	// 1
+}
@@ -0,0 +1,47 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// popularUses is the number of times a food has to be logged to get
+	// half of the frequency boost.
+	popularUses = 3
+	// popularDays is the number of days since a food was last logged at
+	// which it gets half of the recency boost.
+	popularDays = 30
+)
+
+var (
+	// SearchFrequencyWeight is how much how often a food was logged
+	// raises it in search results, relative to how well its name
+	// matches. Zero ranks by the match alone.
+	SearchFrequencyWeight = 4.0
+	// SearchRecencyWeight is how much how recently a food was logged
+	// raises it in search results.
+	SearchRecencyWeight = 2.0
+)
+
+// popularityClauses returns the join of the number of times and the
+// last date each row of logTable's rows was logged, and the expression
+// of the rows' boost in rank. The boost blends how often and how
+// recently the row was logged as of now, each between 0 and its weight.
+// The arguments of the clauses are appended to args.
+func popularityClauses(logTable, idCol string, now time.Time, args []interface{}) (string, string, []interface{}) {
+	join := fmt.Sprintf(`
+			LEFT JOIN (
+				SELECT %s, COUNT(*) AS uses, MAX(date) AS last_logged
+				FROM %s
+				GROUP BY %s
+			) p ON p.%s = r.%s`, idCol, logTable, idCol, idCol, idCol)
+
+	args = append(args, SearchFrequencyWeight, SearchRecencyWeight, now.Format(dateFormat))
+	n := len(args)
+	boost := fmt.Sprintf(`(
+				$%d * COALESCE(p.uses * 1.0 / (p.uses + %d), 0) +
+				$%d * COALESCE(1.0 / (1 + MAX(julianday($%d) - julianday(substr(p.last_logged, 1, 10)), 0) / %d), 0)
+			)`, n-2, popularUses, n-1, n, popularDays)
+	return join, boost, args
+}
@@ -0,0 +1,55 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleRankedSearchQuery() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Egg, whole, raw', 50, 'g', '1 large'),
+			(2, 'Egg, whole, cooked, scrambled', 61, 'g', '1 large'),
+			(3, 'Egg, whole, dried, stabilized, glucose reduced', 100, 'g', '');
+		INSERT INTO foods_fts (food_id, food_name, brand_name)
+			SELECT food_id, food_name, brand_name FROM foods;
+
+		-- Scrambled eggs are a staple.
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(2, '2024-03-01', '08:00:00', 61, 91, 6, 7, 1), (2, '2024-03-05', '08:00:00', 61, 91, 6, 7, 1),
+			(2, '2024-03-09', '08:00:00', 61, 91, 6, 7, 1), (2, '2024-03-12', '08:00:00', 61, 91, 6, 7, 1);
+		INSERT INTO tags (tag_id, name) VALUES (1, 'breakfast');
+		INSERT INTO food_tags (food_id, tag_id) VALUES (1, 1), (2, 1), (3, 1);
+	`)
+	defer db.Close()
+	now := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	search := func(term string) {
+		query, args := rankedSearchQuery("foods", "foods_fts", "food_id", "food_name", "food_tags", term,
+			searchOptions{logTable: "daily_foods", now: now})
+		var ids []int
+		if err := db.Select(&ids, `SELECT food_id FROM (`+query+`)`, args...); err != nil {
+			log.Println(err)
+			return
+		}
+		fmt.Println(term, ids)
+	}
+
+	search("egg")
+	search("tag:breakfast")
+
+	// Without the weights, foods rank by how well they match.
+	defer func(f, r float64) {
+		SearchFrequencyWeight, SearchRecencyWeight = f, r
+	}(SearchFrequencyWeight, SearchRecencyWeight)
+	SearchFrequencyWeight, SearchRecencyWeight = 0, 0
+	search("egg")
+
+	// Output:
+	// egg [2 1 3]
+	// tag:breakfast [2 3 1]
+	// egg [1 2 3]
+}
@@ -0,0 +1,139 @@
+package bite
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// servingHistorySize is the number of recently logged servings kept for
+// each food.
+const servingHistorySize = 5
+
+// Serving is the serving size and number of servings a food was logged
+// with.
+type Serving struct {
+	Date             time.Time `db:"date"`
+	ServingSize      float64   `db:"serving_size"`
+	NumberOfServings float64   `db:"number_of_servings"`
+}
+
+// amount returns the total amount of the food in the serving.
+func (s Serving) amount() float64 {
+	return s.ServingSize * s.NumberOfServings
+}
+
+// ServingHistory returns the last servingHistorySize servings the food
+// was logged with, most recent first. Foods logged as part of a meal
+// are left out since they use the meal's preferences.
+func ServingHistory(q sqlx.Queryer, foodID int) ([]Serving, error) {
+	const query = `
+		SELECT date, serving_size, number_of_servings
+		FROM daily_foods
+		WHERE food_id = $1 AND meal_id IS NULL
+		ORDER BY date DESC, time DESC, id DESC
+		LIMIT $2
+	`
+	var servings []Serving
+	if err := sqlx.Select(q, &servings, query, foodID, servingHistorySize); err != nil {
+		return nil, fmt.Errorf("couldn't get serving history: %v", err)
+	}
+	return servings, nil
+}
+
+// MedianServing returns the median of the servings by total amount. For
+// an even number of servings the smaller of the two middle servings is
+// used so the default is always one the user has actually logged.
+func MedianServing(servings []Serving) (Serving, bool) {
+	if len(servings) == 0 {
+		return Serving{}, false
+	}
+	sorted := make([]Serving, len(servings))
+	copy(sorted, servings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].amount() < sorted[j].amount()
+	})
+	return sorted[(len(sorted)-1)/2], true
+}
+
+// RecentServings returns the distinct servings in the history, most
+// recent first.
+func RecentServings(servings []Serving) []Serving {
+	var recent []Serving
+OuterLoop:
+	for _, s := range servings {
+		for _, r := range recent {
+			if r.ServingSize == s.ServingSize && r.NumberOfServings == s.NumberOfServings {
+				continue OuterLoop
+			}
+		}
+		recent = append(recent, s)
+	}
+	return recent
+}
+
+// ServingLabel describes a logged serving and how long ago it was
+// logged, such as "150 g x2 (1 week ago)". The most recent serving is
+// labeled "last".
+func ServingLabel(s Serving, unit string, last bool, now time.Time) string {
+	label := fmt.Sprintf("%.0f %s", s.ServingSize, unit)
+	if s.NumberOfServings != 1 {
+		label += fmt.Sprintf(" x%g", s.NumberOfServings)
+	}
+	if last {
+		return label + " (last)"
+	}
+	return label + " (" + servingAge(s.Date, now) + ")"
+}
+
+// servingAge describes how long ago the given date was.
+func servingAge(date, now time.Time) string {
+	days := int(dateOf(now).Sub(dateOf(date)).Hours() / 24)
+	switch {
+	case days <= 0:
+		return "today"
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 14:
+		return "1 week ago"
+	default:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	}
+}
+
+// DefaultServing sets the food's serving size and number of servings to
+// the median of its recently logged servings and rescales its calories,
+// macros, and price. Foods that haven't been logged are left unchanged.
+func DefaultServing(q sqlx.Queryer, f *Food) error {
+	servings, err := ServingHistory(q, f.ID)
+	if err != nil {
+		return err
+	}
+	if s, ok := MedianServing(servings); ok {
+		ScaleServings(f, s.ServingSize, s.NumberOfServings)
+	}
+	return nil
+}
+
+// ScaleServings sets the serving size and number of servings of a food
+// and rescales its calories, macros, and price to match.
+func ScaleServings(f *Food, servingSize, numServings float64) {
+	// "Undo" any scaling that took place during food retrieval.
+	ratio := f.ServingSize / PortionSize * f.NumberOfServings
+	newRatio := servingSize / PortionSize * numServings
+	if ratio != 0 {
+		f.Calories = f.Calories / ratio * newRatio
+		f.Price = f.Price / ratio * newRatio
+		if f.FoodMacros != nil {
+			f.FoodMacros.Protein = f.FoodMacros.Protein / ratio * newRatio
+			f.FoodMacros.Fat = f.FoodMacros.Fat / ratio * newRatio
+			f.FoodMacros.Carbs = f.FoodMacros.Carbs / ratio * newRatio
+		}
+	}
+	f.ServingSize = servingSize
+	f.NumberOfServings = numServings
+}
@@ -0,0 +1,52 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleServingHistory() {
+	db := dbtest.MustNew(`
+		INSERT INTO meals (meal_id, meal_name) VALUES (3, 'Oats and banana');
+		INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(1, NULL, '2024-01-01', '12:00:00', 200, 1, 0, 0, 0, 0),
+			(1, NULL, '2024-01-05', '12:00:00', 150, 2, 0, 0, 0, 0),
+			(1, NULL, '2024-01-08', '12:00:00', 100, 1, 0, 0, 0, 0),
+			(1, NULL, '2024-01-12', '12:00:00', 150, 1, 0, 0, 0, 0),
+			(1, 3, '2024-01-13', '12:00:00', 500, 1, 0, 0, 0, 0),
+			(1, NULL, '2024-01-14', '12:00:00', 100, 1, 0, 0, 0, 0),
+			(2, NULL, '2024-01-14', '12:00:00', 30, 1, 0, 0, 0, 0);
+	`)
+	defer db.Close()
+
+	servings, err := ServingHistory(db, 1)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// The median of 100, 150, 100, 300, and 200 g.
+	s, _ := MedianServing(servings)
+	fmt.Println(s.ServingSize, s.NumberOfServings)
+
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i, r := range RecentServings(servings) {
+		fmt.Println(ServingLabel(r, "g", i == 0, now))
+	}
+
+	// Scale a food to the median serving.
+	f := Food{ServingSize: 100, NumberOfServings: 1, Calories: 52, FoodMacros: &FoodMacros{Carbs: 12}}
+	ScaleServings(&f, s.ServingSize, s.NumberOfServings)
+	fmt.Println(f.Calories, f.FoodMacros.Carbs)
+
+	// Output:
+	// 150 1
+	// 100 g (last)
+	// 150 g (3 days ago)
+	// 150 g x2 (1 week ago)
+	// 200 g (2 weeks ago)
+	// 78 18
+}
@@ -0,0 +1,66 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Setting returns the value of the setting with the given key. The
+// boolean reports whether the setting exists.
+func Setting(db *sqlx.DB, key string) (string, bool, error) {
+	const query = `SELECT value FROM settings WHERE key = $1`
+	var value string
+	if err := db.Get(&value, query, key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("couldn't get setting %q: %v", key, err)
+	}
+	return value, true, nil
+}
+
+// SettingsWithPrefix returns all the settings whose key starts with the
+// given prefix.
+func SettingsWithPrefix(db *sqlx.DB, prefix string) (map[string]string, error) {
+	const query = `
+		SELECT key, value FROM settings
+		WHERE substr(key, 1, length($1)) = $1
+	`
+	var rows []struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}
+	if err := db.Select(&rows, query, prefix); err != nil {
+		return nil, fmt.Errorf("couldn't get settings: %v", err)
+	}
+
+	settings := make(map[string]string, len(rows))
+	for _, r := range rows {
+		settings[r.Key] = r.Value
+	}
+	return settings, nil
+}
+
+// SetSetting inserts or updates the setting with the given key.
+func SetSetting(tx *sqlx.Tx, key, value string) error {
+	const query = `
+		INSERT INTO settings (key, value) VALUES ($1, $2)
+		ON CONFLICT(key) DO UPDATE SET value = $2
+	`
+	if _, err := tx.Exec(query, key, value); err != nil {
+		return fmt.Errorf("couldn't set setting %q: %v", key, err)
+	}
+	return nil
+}
+
+// DeleteSetting removes the setting with the given key.
+func DeleteSetting(tx *sqlx.Tx, key string) error {
+	const query = `DELETE FROM settings WHERE key = $1`
+	if _, err := tx.Exec(query, key); err != nil {
+		return fmt.Errorf("couldn't delete setting %q: %v", key, err)
+	}
+	return nil
+}
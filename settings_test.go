@@ -0,0 +1,51 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSetSetting() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	if err := SetSetting(tx, "key.down", "n"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetSetting(tx, "key.up", "e"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetSetting(tx, "key.down", "j"); err != nil {
+		log.Println(err)
+		return
+	}
+	if err := SetSetting(tx, "units", "metric"); err != nil {
+		log.Println(err)
+		return
+	}
+	tx.Commit()
+
+	v, ok, err := Setting(db, "key.down")
+	fmt.Println(v, ok, err)
+
+	_, ok, err = Setting(db, "key.top")
+	fmt.Println(ok, err)
+
+	keys, err := SettingsWithPrefix(db, "key.")
+	fmt.Println(keys, err)
+
+	// Output:
+	// j true <nil>
+	// false <nil>
+	// map[key.down:j key.up:e] <nil>
+}
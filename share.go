@@ -0,0 +1,134 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// shareCreatedSetting is the setting that holds when a share was
+// exported. Only shares have it.
+const shareCreatedSetting = "share.created"
+
+// sharePrivate are the tables emptied in shares: the paths of attached
+// files and progress photos, sync bookkeeping, and the audit log, which
+// holds the command line and user name of every change. The audit log
+// is emptied last, once the others' deletes are recorded in it.
+var sharePrivate = []string{"attachments", "progress_photos", "sync_deletions", "audit_log"}
+
+// ExportShare writes a read-only snapshot of db to path that a coach
+// can view the logs, trends, and adherence of. The snapshot leaves out
+// the tables in sharePrivate and the sync settings. It isn't
+// encrypted, even when db is. A file already at path is replaced.
+func ExportShare(db *sqlx.DB, path string) error {
+	dir, err := os.MkdirTemp("", "bite-share")
+	if err != nil {
+		return fmt.Errorf("couldn't create share directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "share.db")
+	if _, err := db.Exec(`VACUUM INTO $1`, tmp); err != nil {
+		return fmt.Errorf("couldn't copy database for share: %v", err)
+	}
+	cp, err := sqlx.Connect("sqlite", tmp)
+	if err != nil {
+		return err
+	}
+	defer cp.Close()
+	if err := sanitizeShare(cp); err != nil {
+		return err
+	}
+
+	// Deleted rows stay in the file's free pages until it is vacuumed,
+	// so the share is written by vacuuming the copy.
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("couldn't replace share: %v", err)
+	}
+	if _, err := cp.Exec(`VACUUM INTO $1`, path); err != nil {
+		return fmt.Errorf("couldn't write share: %v", err)
+	}
+	return nil
+}
+
+// sanitizeShare empties the private tables and sync settings of the
+// copy of the database and marks it as a share.
+func sanitizeShare(db *sqlx.DB) error {
+	if err := AddTables(db); err != nil {
+		return err
+	}
+	query, args, err := sqlx.In(`SELECT name FROM sqlite_master WHERE type = 'table' AND name IN (?)`, sharePrivate)
+	if err != nil {
+		return err
+	}
+	var tables []string
+	if err := db.Select(&tables, query, args...); err != nil {
+		return fmt.Errorf("couldn't get tables: %v", err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM settings WHERE key LIKE 'sync.%'`); err != nil {
+		return fmt.Errorf("couldn't remove sync settings: %v", err)
+	}
+	if err := SetSetting(tx, shareCreatedSetting, Now().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	for _, t := range sharePrivate {
+		if !contains(tables, t) {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s`, t)); err != nil {
+			return fmt.Errorf("couldn't empty %s: %v", t, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CopyShare copies the share at path to dst, which must not exist, and
+// returns when the share was exported. The share itself is opened
+// read-only. It returns an error if the file isn't a share.
+func CopyShare(path, dst string) (time.Time, error) {
+	if _, err := os.Stat(path); err != nil {
+		return time.Time{}, fmt.Errorf("couldn't open share: %v", err)
+	}
+	db, err := sqlx.Connect("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't open share: %v", err)
+	}
+	defer db.Close()
+
+	var created string
+	err = db.Get(&created, `SELECT value FROM settings WHERE key = $1`, shareCreatedSetting)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("%s isn't a share, export one with \"bite share export\"", path)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't read share: %v", err)
+	}
+	t, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s setting %q: %v", shareCreatedSetting, created, err)
+	}
+
+	if _, err := db.Exec(`VACUUM INTO $1`, dst); err != nil {
+		return time.Time{}, fmt.Errorf("couldn't copy share: %v", err)
+	}
+	return t, nil
+}
+
+// ShareChanges returns how many rows of db, a copy of the share at
+// path, were added, changed, or deleted. Tables and columns db gained
+// are left out, so that creating them on first use doesn't count.
+func ShareChanges(db *sqlx.DB, path string) (int, error) {
+	return diffRows(io.Discard, db, path)
+}
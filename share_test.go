@@ -0,0 +1,76 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleExportShare() {
+	db := dbtest.MustNew(dbtest.User, `
+		INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-18', '07:00:00', 180);
+		INSERT INTO attachments (entry_table, entry_id, path) VALUES ('daily_weights', 1, '/home/me/scale.jpg');
+		INSERT INTO settings (key, value) VALUES ('sync.last_export', '2024-03-17T10:00:00.000Z'),
+			('weights.exclude_estimates', 'true');
+	`)
+	defer db.Close()
+	defer SetClock(FixedClock(time.Date(2024, 3, 18, 20, 0, 0, 0, time.UTC)))()
+
+	dir, err := os.MkdirTemp("", "bite")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	share := filepath.Join(dir, "share.db")
+	if err := ExportShare(db, share); err != nil {
+		log.Fatal(err)
+	}
+
+	// A coach views a copy of the share.
+	view := filepath.Join(dir, "view.db")
+	created, err := CopyShare(share, view)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(created.Format(time.RFC3339))
+	cp, err := Open(view)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer cp.Close()
+	var weights, attachments int
+	cp.Get(&weights, `SELECT COUNT(*) FROM daily_weights`)
+	cp.Get(&attachments, `SELECT COUNT(*) FROM attachments`)
+	var settings []string
+	cp.Select(&settings, `SELECT key FROM settings ORDER BY key`)
+	fmt.Println(weights, attachments, settings)
+
+	// Creating a table isn't a change, but logging a weight is.
+	cp.MustExec(`CREATE TABLE notes (id INTEGER PRIMARY KEY, note TEXT); INSERT INTO notes (note) VALUES ('hi')`)
+	n, err := ShareChanges(cp, share)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+	cp.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-03-19', '07:00:00', 179.5)`)
+	if n, err = ShareChanges(cp, share); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(n)
+
+	// The database itself isn't a share.
+	plain := filepath.Join(dir, "bite.db")
+	db.MustExec(`VACUUM INTO $1`, plain)
+	_, err = CopyShare(plain, filepath.Join(dir, "again.db"))
+	fmt.Println(err != nil)
+	// Output:
+	// 2024-03-18T20:00:00Z
+	// 1 0 [share.created weights.exclude_estimates]
+	// 0
+	// 1
+	// true
+}
@@ -0,0 +1,291 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sleepSchema creates the daily_sleep table in databases made before it
+// existed.
+const sleepSchema = `
+	CREATE TABLE IF NOT EXISTS daily_sleep (
+		date DATE PRIMARY KEY,
+		hours REAL NOT NULL
+	)
+`
+
+// PoorSleepHours is the average hours of sleep a night under which a
+// week counts as a week of poor sleep.
+const PoorSleepHours = 7.0
+
+// SleepWeek is the sleep, weight change, and adherence of a week.
+type SleepWeek struct {
+	Start  time.Time
+	Nights int     // Nights with sleep logged.
+	Hours  float64 // Average hours slept a night.
+	// WeightChange is the change in average weight from the week
+	// before, valid only if HasWeight is set.
+	WeightChange float64
+	HasWeight    bool
+	// Deviation is how far WeightChange was from the weekly change goal.
+	Deviation float64
+	Logged    int // Days with foods logged.
+	Met       int // Logged days that met the calorie goal.
+}
+
+// Poor reports whether the week had poor sleep.
+func (w SleepWeek) Poor() bool {
+	return w.Nights > 0 && w.Hours < PoorSleepHours
+}
+
+// Adherence returns the share (%) of logged days that met the calorie
+// goal.
+func (w SleepWeek) Adherence() float64 {
+	if w.Logged == 0 {
+		return 0
+	}
+	return float64(w.Met) * 100 / float64(w.Logged)
+}
+
+// SleepGroup is the average weight change deviation and adherence of
+// weeks of poor or good sleep.
+type SleepGroup struct {
+	Weeks     int
+	Deviation float64 // Average absolute deviation from the weekly change goal.
+	Adherence float64 // Share (%) of logged days that met the calorie goal.
+
+	deviationWeeks int
+	logged, met    int
+}
+
+// SleepComparison compares weeks of poor sleep with weeks of good
+// sleep.
+type SleepComparison struct {
+	Poor, Rested SleepGroup
+}
+
+// addSleepTable creates the daily_sleep table if it doesn't exist,
+// along with its audit triggers.
+func addSleepTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(sleepSchema); err != nil {
+		return fmt.Errorf("couldn't create sleep table: %v", err)
+	}
+	return auditTable(tx, "daily_sleep")
+}
+
+// ParseSleep parses an amount of sleep, such as "7.5h", "7h30m", "450m",
+// or "7.5" hours, and returns it in hours.
+func ParseSleep(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	hours, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		d, derr := time.ParseDuration(s)
+		if derr != nil {
+			return 0, fmt.Errorf("sleep must be hours such as 7.5 or 7h30m, got %q", s)
+		}
+		hours = d.Hours()
+	}
+	if hours <= 0 || hours > 24 {
+		return 0, fmt.Errorf("sleep must be more than 0 and at most 24 hours, got %q", s)
+	}
+	return hours, nil
+}
+
+// LogSleep stores the hours slept the night before the date. It
+// replaces any sleep already logged for that day.
+func LogSleep(tx *sqlx.Tx, date time.Time, hours float64) error {
+	if hours <= 0 || hours > 24 {
+		return fmt.Errorf("sleep must be more than 0 and at most 24 hours, got %v", hours)
+	}
+	if err := addSleepTable(tx); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO daily_sleep (date, hours) VALUES ($1, $2)
+		ON CONFLICT(date) DO UPDATE SET hours = $2
+	`
+	if _, err := tx.Exec(query, date.Format(dateFormat), hours); err != nil {
+		return fmt.Errorf("couldn't log sleep: %v", err)
+	}
+	return nil
+}
+
+// SleepWeeks returns the given number of weeks from start with each
+// week's sleep, the change in average weight from the week before, and
+// how many logged days met the calorie goal.
+func SleepWeeks(tx *sqlx.Tx, u *UserInfo, start time.Time, weeks int) ([]SleepWeek, error) {
+	if err := addSleepTable(tx); err != nil {
+		return nil, err
+	}
+	if err := addWeightColumns(tx); err != nil {
+		return nil, err
+	}
+	start = dateOf(start)
+	end := start.AddDate(0, 0, 7*weeks)
+
+	var nights []struct {
+		Date  time.Time `db:"date"`
+		Hours float64   `db:"hours"`
+	}
+	const sleepSQL = `SELECT date, hours FROM daily_sleep WHERE date >= $1 AND date < $2`
+	if err := tx.Select(&nights, sleepSQL, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get sleep: %v", err)
+	}
+	var weights []Entry
+	const weightsSQL = `
+		SELECT date, weight AS user_weight FROM daily_weights
+		WHERE estimated = 0 AND date >= $1 AND date < $2
+	`
+	err := tx.Select(&weights, weightsSQL, start.AddDate(0, 0, -7).Format(dateFormat), end.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get weights: %v", err)
+	}
+	s, err := DietRange(tx, u, start, end.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// week returns the index of the week the date falls in, where -1 is
+	// the week before start.
+	week := func(d time.Time) int {
+		return int(math.Floor(dateOf(d).Sub(start).Hours() / (24 * 7)))
+	}
+	out := make([]SleepWeek, weeks)
+	for i := range out {
+		out[i].Start = start.AddDate(0, 0, 7*i)
+	}
+	for _, n := range nights {
+		w := &out[week(n.Date)]
+		w.Nights++
+		w.Hours += n.Hours
+	}
+	for _, d := range s.Days {
+		w := &out[week(d.Date)]
+		w.Logged++
+		if metDayGoal(u, d.Calories, d.Goal) {
+			w.Met++
+		}
+	}
+	sums, counts := make([]float64, weeks+1), make([]int, weeks+1)
+	for _, e := range weights {
+		i := week(e.Date) + 1
+		sums[i] += e.UserWeight
+		counts[i]++
+	}
+	for i := range out {
+		w := &out[i]
+		if w.Nights > 0 {
+			w.Hours /= float64(w.Nights)
+		}
+		if counts[i] > 0 && counts[i+1] > 0 {
+			w.WeightChange = sums[i+1]/float64(counts[i+1]) - sums[i]/float64(counts[i])
+			w.Deviation = w.WeightChange - u.Phase.WeeklyChange
+			w.HasWeight = true
+		}
+	}
+	return out, nil
+}
+
+// CompareSleep averages the weight change deviation and adherence of the
+// weeks of poor sleep and of good sleep. Weeks without sleep logged are
+// left out.
+func CompareSleep(weeks []SleepWeek) SleepComparison {
+	var c SleepComparison
+	for _, w := range weeks {
+		if w.Nights == 0 {
+			continue
+		}
+		g := &c.Rested
+		if w.Poor() {
+			g = &c.Poor
+		}
+		g.Weeks++
+		g.logged += w.Logged
+		g.met += w.Met
+		if w.HasWeight {
+			g.Deviation += math.Abs(w.Deviation)
+			g.deviationWeeks++
+		}
+	}
+	for _, g := range []*SleepGroup{&c.Poor, &c.Rested} {
+		if g.deviationWeeks > 0 {
+			g.Deviation /= float64(g.deviationWeeks)
+		}
+		if g.logged > 0 {
+			g.Adherence = float64(g.met) * 100 / float64(g.logged)
+		}
+	}
+	return c
+}
+
+// Lines describes how the weeks of poor sleep compared with the weeks of
+// good sleep, or returns nil without weeks of both.
+func (c SleepComparison) Lines() []string {
+	if c.Poor.Weeks == 0 || c.Rested.Weeks == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Weeks of poor sleep (under %s h a night): %d, of good sleep: %d.",
+		formatNumber(PoorSleepHours, 0), c.Poor.Weeks, c.Rested.Weeks)}
+	if c.Poor.deviationWeeks > 0 && c.Rested.deviationWeeks > 0 {
+		lines = append(lines, fmt.Sprintf("Weight change was off the weekly goal by %s on average in weeks of poor sleep and %s in weeks of good sleep.",
+			FormatWeight(c.Poor.Deviation), FormatWeight(c.Rested.Deviation)))
+	}
+	if c.Poor.logged > 0 && c.Rested.logged > 0 {
+		lines = append(lines, fmt.Sprintf("Calorie goals were met on %.0f%% of days in weeks of poor sleep and %.0f%% in weeks of good sleep.",
+			c.Poor.Adherence, c.Rested.Adherence))
+	}
+	return lines
+}
+
+// SleepReport prints the sleep, weight change, and adherence of each of
+// the last given number of weeks, starting on WeekStart, and how weeks
+// of poor sleep compared with weeks of good sleep.
+func SleepReport(db *sqlx.DB, u *UserInfo, weeks int, now time.Time) error {
+	if weeks < 1 {
+		return fmt.Errorf("weeks must be at least 1, got %d", weeks)
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	start := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	sw, err := SleepWeeks(tx, u, start, weeks)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-12s %-8s %-8s %-14s %-10s %s\n", "Week of", "Sleep", "Nights", "Weight change", "Deviation", "Goal met")
+	for _, w := range sw {
+		sleep, change, dev, met := "-", "-", "-", "-"
+		if w.Nights > 0 {
+			sleep = formatNumber(w.Hours, 1) + " h"
+			if w.Poor() {
+				sleep = paint(colorMissed, fmt.Sprintf("%-8s", sleep))
+			}
+		}
+		if w.HasWeight {
+			change = localizeNumber(fmt.Sprintf("%+.*f", WeightDecimals, w.WeightChange))
+			dev = localizeNumber(fmt.Sprintf("%+.*f", WeightDecimals, w.Deviation))
+		}
+		if w.Logged > 0 {
+			met = fmt.Sprintf("%d/%d", w.Met, w.Logged)
+		}
+		fmt.Printf("%-12s %-8s %-8d %-14s %-10s %s\n", w.Start.Format(dateFormat), sleep, w.Nights, change, dev, met)
+	}
+
+	fmt.Println()
+	lines := CompareSleep(sw).Lines()
+	if lines == nil {
+		fmt.Println("Log sleep in weeks of both poor and good sleep to compare them.")
+	}
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleParseSleep() {
+	for _, s := range []string{"7.5h", "7h30m", "450m", "6", "25h", "long"} {
+		h, err := ParseSleep(s)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(h)
+	}
+
+	// Output:
+	// 7.5
+	// 7.5
+	// 7.5
+	// 6
+	// sleep must be more than 0 and at most 24 hours, got "25h"
+	// sleep must be hours such as 7.5 or 7h30m, got "long"
+}
+
+func ExampleSleepWeeks() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight)
+		VALUES ('2024-02-28', '07:00:00', 180),
+		       ('2024-03-06', '07:00:00', 180.2),
+		       ('2024-03-13', '07:00:00', 179),
+		       ('2024-03-20', '07:00:00', 178);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (1, '2024-03-04', '08:00:00', 100, 2500, 150, 80, 250),
+		       (1, '2024-03-05', '08:00:00', 100, 1900, 150, 60, 200),
+		       (1, '2024-03-11', '08:00:00', 100, 1900, 150, 60, 200),
+		       (1, '2024-03-12', '08:00:00', 100, 1800, 150, 60, 180);
+	`)
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	for _, n := range []struct {
+		day   int
+		hours float64
+	}{{0, 6}, {1, 5.5}, {2, 6.5}, {7, 8}, {8, 7.5}} {
+		if err := LogSleep(tx, monday.AddDate(0, 0, n.day), n.hours); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.Status = "active"
+	u.Phase.GoalCalories = 2000
+	u.Phase.WeeklyChange = -1
+	weeks, err := SleepWeeks(tx, &u, monday, 3)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, w := range weeks {
+		fmt.Printf("%s: %d nights of %.2f h, poor %t, change %+.1f, deviation %+.1f, met %d/%d\n",
+			w.Start.Format(dateFormat), w.Nights, w.Hours, w.Poor(), w.WeightChange, w.Deviation, w.Met, w.Logged)
+	}
+	for _, l := range CompareSleep(weeks).Lines() {
+		fmt.Println(l)
+	}
+
+	// Output:
+	// 2024-03-04: 3 nights of 6.00 h, poor true, change +0.2, deviation +1.2, met 1/2
+	// 2024-03-11: 2 nights of 7.75 h, poor false, change -1.2, deviation -0.2, met 2/2
+	// 2024-03-18: 0 nights of 0.00 h, poor false, change -1.0, deviation +0.0, met 0/0
+	// Weeks of poor sleep (under 7 h a night): 1, of good sleep: 1.
+	// Weight change was off the weekly goal by 1.2 on average in weeks of poor sleep and 0.2 in weeks of good sleep.
+	// Calorie goals were met on 50% of days in weeks of poor sleep and 100% in weeks of good sleep.
+}
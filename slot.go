@@ -0,0 +1,76 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MealSlot is a part of the day that food entries are grouped into. A
+// food entry's slot is derived from the time it was logged at.
+type MealSlot string
+
+const (
+	Breakfast MealSlot = "breakfast"
+	Lunch     MealSlot = "lunch"
+	Snack     MealSlot = "snack"
+	Dinner    MealSlot = "dinner"
+)
+
+// MealSlots lists the meal slots in the order they occur in a day.
+var MealSlots = []MealSlot{Breakfast, Lunch, Snack, Dinner}
+
+// SlotTimes maps each meal slot to the time of day it starts. Entries
+// logged before the first slot starts belong to the last slot of the
+// previous day.
+var SlotTimes = map[MealSlot]string{
+	Breakfast: "05:00:00",
+	Lunch:     "11:00:00",
+	Snack:     "15:00:00",
+	Dinner:    "17:00:00",
+}
+
+// SlotForTime returns the meal slot that the given time of day falls in.
+// That is the slot with the latest start time that isn't after the
+// time.
+func SlotForTime(t time.Time) MealSlot {
+	clock := t.Format(dateFormatTime)
+	slot, latest := MealSlot(""), ""
+	for _, s := range MealSlots {
+		if start := SlotTimes[s]; start <= clock && start >= latest {
+			slot, latest = s, start
+		}
+	}
+	if slot != "" {
+		return slot
+	}
+
+	// Time is before every slot starts, so it belongs to the slot that
+	// starts last.
+	for _, s := range MealSlots {
+		if start := SlotTimes[s]; start >= latest {
+			slot, latest = s, start
+		}
+	}
+	return slot
+}
+
+// ParseMealSlot returns the meal slot with the given name.
+func ParseMealSlot(s string) (MealSlot, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, slot := range MealSlots {
+		if string(slot) == s {
+			return slot, nil
+		}
+	}
+	return "", fmt.Errorf("unknown meal slot %q", s)
+}
+
+// Slot returns the meal slot the food entry was logged in.
+func (e DailyFood) Slot() MealSlot {
+	t, err := time.Parse(dateFormatTime, e.Time)
+	if err != nil {
+		return MealSlots[0]
+	}
+	return SlotForTime(t)
+}
@@ -0,0 +1,32 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleSlotForTime() {
+	for _, clock := range []string{"02:30", "07:15", "11:00", "15:45", "20:10"} {
+		t, _ := time.Parse("15:04", clock)
+		fmt.Println(clock, SlotForTime(t))
+	}
+
+	// Output:
+	// 02:30 dinner
+	// 07:15 breakfast
+	// 11:00 lunch
+	// 15:45 snack
+	// 20:10 dinner
+}
+
+func ExampleParseMealSlot() {
+	slot, err := ParseMealSlot(" Lunch ")
+	fmt.Println(slot, err)
+
+	_, err = ParseMealSlot("brunch")
+	fmt.Println(err)
+
+	// Output:
+	// lunch <nil>
+	// unknown meal slot "brunch"
+}
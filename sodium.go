@@ -0,0 +1,106 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// USDA nutrient ids of the electrolytes that move water weight.
+const (
+	potassiumNutrientID = 1092 // Potassium, K.
+	sodiumNutrientID    = 1093 // Sodium, Na.
+)
+
+// SodiumHigh is the sodium (mg) of a day high enough to hold on to
+// water and raise the next weigh-in.
+var SodiumHigh = 3000.0
+
+// weightSpike is the fraction of body weight a day-over-day weight gain
+// must reach to be a spike.
+const weightSpike = 0.005
+
+// Electrolytes is the sodium and potassium (mg) of a day's food log.
+type Electrolytes struct {
+	Sodium    float64
+	Potassium float64
+}
+
+// electrolytes returns the sodium and potassium of each day from from
+// to to, inclusive, keyed by date. Days whose foods have neither are
+// left out.
+func electrolytes(q sqlx.Queryer, from, to time.Time) (map[string]Electrolytes, error) {
+	days, err := dailyNutrients(q, from, to, sodiumNutrientID, potassiumNutrientID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get sodium and potassium: %v", err)
+	}
+	es := make(map[string]Electrolytes, len(days))
+	for date, n := range days {
+		es[date] = Electrolytes{Sodium: n[sodiumNutrientID], Potassium: n[potassiumNutrientID]}
+	}
+	return es, nil
+}
+
+// electrolytesOn returns the sodium and potassium of the day, or nil if
+// the day's foods have neither.
+func electrolytesOn(tx *sqlx.Tx, date time.Time) (*Electrolytes, error) {
+	es, err := electrolytes(tx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := es[dateOf(date).Format(dateFormat)]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// MarkSodium sets Sodium and Potassium on the entries from their day's
+// food log.
+func MarkSodium(db *sqlx.DB, entries *[]Entry) error {
+	if len(*entries) == 0 {
+		return nil
+	}
+	first, last := (*entries)[0].Date, (*entries)[len(*entries)-1].Date
+	es, err := electrolytes(db, first, last)
+	if err != nil {
+		return err
+	}
+	for i := range *entries {
+		e := &(*entries)[i]
+		el := es[e.Date.Format(dateFormat)]
+		e.Sodium, e.Potassium = el.Sodium, el.Potassium
+	}
+	return nil
+}
+
+// sodiumSpike returns the weight gained since the day before the entry
+// at i and the day before's sodium when the gain is a spike that
+// follows a high-sodium day, and ok false otherwise. Water held on to
+// from a salty day shows on the next morning's weigh-in.
+func sodiumSpike(entries []Entry, i int) (gain, sodium float64, ok bool) {
+	if i <= 0 || i >= len(entries) {
+		return 0, 0, false
+	}
+	prev, e := entries[i-1], entries[i]
+	if !dateOf(prev.Date).AddDate(0, 0, 1).Equal(dateOf(e.Date)) {
+		return 0, 0, false
+	}
+	gain = e.UserWeight - prev.UserWeight
+	if gain < weightSpike*prev.UserWeight || prev.Sodium < SodiumHigh {
+		return 0, 0, false
+	}
+	return gain, prev.Sodium, true
+}
+
+// sodiumNote returns the note of a weight spike at the entry at i after
+// a high-sodium day, or "" if it has none.
+func sodiumNote(entries []Entry, i int) string {
+	gain, sodium, ok := sodiumSpike(entries, i)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(tr("Weight is up %s from the day before after %s mg of sodium, likely water."),
+		FormatWeight(gain), formatNumber(sodium, 0))
+}
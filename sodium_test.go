@@ -0,0 +1,48 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleMarkSodium() {
+	db := dbtest.MustNew(`
+		INSERT INTO nutrients (nutrient_id, nutrient_name, unit_name) VALUES
+			(1092, 'Potassium, K', 'MG'), (1093, 'Sodium, Na', 'MG');
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+			VALUES (4, 'Ramen', 100, 'g', '1 pack');
+		INSERT INTO food_nutrients (food_id, nutrient_id, amount, derivation_id) VALUES
+			(3, 1092, 358, 71), (3, 1093, 1, 71), (4, 1092, 120, 71), (4, 1093, 1800, 71);
+		INSERT INTO daily_foods (food_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs) VALUES
+			(3, '2024-03-18', '10:00:00', 118, 1, 105, 1.3, 0.4, 27),
+			(4, '2024-03-19', '19:00:00', 100, 2, 880, 20, 34, 124),
+			(3, '2024-03-20', '10:00:00', 118, 2, 210, 2.6, 0.8, 54);
+		INSERT INTO daily_weights (date, time, weight) VALUES
+			('2024-03-18', '07:00:00', 180), ('2024-03-19', '07:00:00', 180.2),
+			('2024-03-20', '07:00:00', 182.1), ('2024-03-21', '07:00:00', 181.2);
+	`)
+	defer db.Close()
+
+	entries, err := AllEntries(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := MarkSodium(db, entries); err != nil {
+		log.Fatal(err)
+	}
+	for i, e := range *entries {
+		fmt.Printf("%s %s mg sodium, %s mg potassium\n", e.Date.Format(dateFormat),
+			formatNumber(e.Sodium, 0), formatNumber(e.Potassium, 0))
+		if note := sodiumNote(*entries, i); note != "" {
+			fmt.Println(note)
+		}
+	}
+
+	// Output:
+	// 2024-03-18 1 mg sodium, 422 mg potassium
+	// 2024-03-19 3600 mg sodium, 240 mg potassium
+	// 2024-03-20 2 mg sodium, 845 mg potassium
+	// Weight is up 1.9 from the day before after 3600 mg of sodium, likely water.
+}
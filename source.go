@@ -0,0 +1,69 @@
+package bite
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Food sources record where a food's data came from.
+const (
+	SourceUSDA          = "usda"
+	SourceOpenFoodFacts = "openfoodfacts"
+	SourceUser          = "user"
+)
+
+// PreferVerified hides user created foods from search results when a
+// food from a verified source has the same name.
+var PreferVerified = false
+
+// addFoodColumns adds the source column to foods tables created before
+// it existed. Foods already in the table were imported from the USDA
+// database unless created by the user.
+func addFoodColumns(tx *sqlx.Tx) error {
+	return addColumns(tx, "foods",
+		`source TEXT DEFAULT 'usda' NOT NULL CHECK (source IN ('usda', 'openfoodfacts', 'user'))`)
+}
+
+// Verified reports whether the food's data came from a food database
+// rather than the user.
+func (f Food) Verified() bool {
+	return f.Source == SourceUSDA || f.Source == SourceOpenFoodFacts
+}
+
+// SourceName returns the display name of a food source.
+func SourceName(source string) string {
+	switch source {
+	case SourceUSDA:
+		return "USDA"
+	case SourceOpenFoodFacts:
+		return "Open Food Facts"
+	case SourceUser:
+		return "User"
+	}
+	return ""
+}
+
+// preferVerified removes the user created foods that have the same name
+// and brand as a verified food, keeping the order of the rest.
+func preferVerified(foods []Food) []Food {
+	key := func(f Food) string {
+		return strings.ToLower(strings.TrimSpace(f.Name)) + "\x00" +
+			strings.ToLower(strings.TrimSpace(f.BrandName))
+	}
+	verified := map[string]bool{}
+	for _, f := range foods {
+		if f.Verified() {
+			verified[key(f)] = true
+		}
+	}
+
+	kept := foods[:0]
+	for _, f := range foods {
+		if !f.Verified() && verified[key(f)] {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
@@ -0,0 +1,22 @@
+package bite
+
+import "fmt"
+
+func ExamplePreferVerified() {
+	foods := []Food{
+		{ID: 1, Name: "Banana", Source: SourceUser},
+		{ID: 2, Name: "banana", Source: SourceUSDA},
+		{ID: 3, Name: "Protein Bar", BrandName: "Acme", Source: SourceUser},
+		{ID: 4, Name: "Protein Bar", BrandName: "Acme", Source: SourceOpenFoodFacts},
+		{ID: 5, Name: "Grandma's Stew", Source: SourceUser},
+	}
+
+	for _, f := range preferVerified(foods) {
+		fmt.Println(f.ID, f.Name, SourceName(f.Source))
+	}
+
+	// Output:
+	// 2 banana USDA
+	// 4 Protein Bar Open Food Facts
+	// 5 Grandma's Stew User
+}
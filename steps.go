@@ -0,0 +1,189 @@
+package bite
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// stepsWindow is the number of days in the rolling steps average.
+	stepsWindow = 7
+	// stepsBaselineDays is the number of days at the start of a diet
+	// phase that make up the steps baseline.
+	stepsBaselineDays = 14
+	// stepsDropThreshold is the fraction the rolling steps average may
+	// drop below the baseline during a cut before the user is warned.
+	stepsDropThreshold = 0.2
+	// calsPerStepKg is the estimated calories burned per step per
+	// kilogram of bodyweight.
+	calsPerStepKg = 0.0005
+)
+
+// StepsEntry is the step count for a day.
+type StepsEntry struct {
+	Date  time.Time `db:"date"`
+	Steps int       `db:"steps"`
+}
+
+// StepsDrop describes a drop in daily steps during a diet phase.
+type StepsDrop struct {
+	Baseline float64 // Average daily steps at the start of the phase.
+	Current  float64 // Rolling average of daily steps.
+	Calories float64 // Estimated drop in daily calories burned.
+}
+
+// Percent returns the size of the drop as a percentage of the baseline.
+func (d StepsDrop) Percent() float64 {
+	return (d.Baseline - d.Current) * 100 / d.Baseline
+}
+
+// LogSteps stores the step count for a day. It replaces any count
+// already logged for that day.
+func LogSteps(tx *sqlx.Tx, date time.Time, steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("steps must not be negative, got %d", steps)
+	}
+	const query = `
+		INSERT INTO daily_steps (date, steps) VALUES ($1, $2)
+		ON CONFLICT(date) DO UPDATE SET steps = $2
+	`
+	if _, err := tx.Exec(query, date.Format(dateFormat), steps); err != nil {
+		return fmt.Errorf("couldn't log steps: %v", err)
+	}
+	return nil
+}
+
+// ImportSteps logs the step counts of a CSV file with date
+// (YYYY-MM-DD) and steps columns, e.g. an export from a phone or
+// fitness tracker. A header row is skipped. It returns the number of
+// days logged.
+func ImportSteps(tx *sqlx.Tx, r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	n := 0
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("couldn't read steps file: %v", err)
+		}
+		if len(rec) < 2 {
+			return n, fmt.Errorf("line %d: expected date and steps", line)
+		}
+
+		date, err := time.Parse(dateFormat, strings.TrimSpace(rec[0]))
+		if err != nil {
+			if line == 1 {
+				continue // Header.
+			}
+			return n, fmt.Errorf("line %d: invalid date %q", line, rec[0])
+		}
+		steps, err := strconv.Atoi(strings.TrimSpace(rec[1]))
+		if err != nil {
+			return n, fmt.Errorf("line %d: invalid steps %q", line, rec[1])
+		}
+
+		if err := LogSteps(tx, date, steps); err != nil {
+			return n, fmt.Errorf("line %d: %v", line, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// StepsBetween returns the step counts logged from start up to, but
+// not including, end. Ordered by date.
+func StepsBetween(db *sqlx.DB, start, end time.Time) ([]StepsEntry, error) {
+	const query = `
+		SELECT date, steps FROM daily_steps
+		WHERE date >= $1 AND date < $2
+		ORDER BY date
+	`
+	var entries []StepsEntry
+	if err := db.Select(&entries, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get steps: %v", err)
+	}
+	return entries, nil
+}
+
+// averageSteps returns the average daily steps of the entries. Days
+// without a logged count are left out.
+func averageSteps(entries []StepsEntry) float64 {
+	if len(entries) == 0 {
+		return 0
+	}
+	var total int
+	for _, e := range entries {
+		total += e.Steps
+	}
+	return float64(total) / float64(len(entries))
+}
+
+// RollingSteps returns the average daily steps over the stepsWindow
+// days that end with the given date.
+func RollingSteps(db *sqlx.DB, date time.Time) (float64, error) {
+	end := date.AddDate(0, 0, 1)
+	entries, err := StepsBetween(db, end.AddDate(0, 0, -stepsWindow), end)
+	if err != nil {
+		return 0, err
+	}
+	return averageSteps(entries), nil
+}
+
+// CheckSteps compares the rolling steps average as of the given date
+// to the average over the first days of the user's cut. It returns the
+// drop when the average fell by more than stepsDropThreshold, or nil.
+//
+// Fewer steps means less non-exercise activity thermogenesis (NEAT),
+// which lowers the TDEE and is a common cause of stalled weight loss.
+func CheckSteps(db *sqlx.DB, u *UserInfo, date time.Time) (*StepsDrop, error) {
+	if u.Phase.Name != "cut" || u.Phase.Status != "active" {
+		return nil, nil
+	}
+
+	// Wait until the baseline and the rolling window don't overlap.
+	baselineEnd := u.Phase.StartDate.AddDate(0, 0, stepsBaselineDays)
+	if date.Before(baselineEnd.AddDate(0, 0, stepsWindow-1)) {
+		return nil, nil
+	}
+
+	baseline, err := StepsBetween(db, u.Phase.StartDate, baselineEnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(baseline) < stepsWindow/2 {
+		return nil, nil
+	}
+	current, err := RollingSteps(db, date)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, nil
+	}
+
+	d := &StepsDrop{Baseline: averageSteps(baseline), Current: current}
+	if d.Current >= d.Baseline*(1-stepsDropThreshold) {
+		return nil, nil
+	}
+	d.Calories = (d.Baseline - d.Current) * calsPerStepKg * lbsToKg(u.Weight)
+	return d, nil
+}
+
+// PrintStepsDrop warns the user about a drop in daily steps.
+func PrintStepsDrop(d *StepsDrop) {
+	fmt.Println()
+	fmt.Printf("%s your average daily steps dropped from %.0f to %.0f (%.0f%%) since the start of your cut.\n",
+		paint(colorRed, "Warning:"), d.Baseline, d.Current, d.Percent())
+	fmt.Printf("This lowers your TDEE by about %.0f calories. Walk more to get back to your usual steps, or lower your calorie goal by this amount to keep losing weight.\n", d.Calories)
+}
@@ -0,0 +1,79 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleCheckSteps() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	// Two weeks at 10000 steps, then three weeks at 6000 steps.
+	var csv strings.Builder
+	csv.WriteString("date,steps\n")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 35; i++ {
+		steps := 10000
+		if i >= 14 {
+			steps = 6000
+		}
+		fmt.Fprintf(&csv, "%s,%d\n", start.AddDate(0, 0, i).Format(dateFormat), steps)
+	}
+	n, err := ImportSteps(tx, strings.NewReader(csv.String()))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(n)
+
+	u := UserInfo{Weight: 180}
+	u.Phase.Name = "cut"
+	u.Phase.Status = "active"
+	u.Phase.StartDate = start
+
+	end := start.AddDate(0, 0, 34)
+	avg, err := RollingSteps(db, end)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(avg)
+
+	d, err := CheckSteps(db, &u, end)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Printf("%.0f %.0f %.0f%% %.0f\n", d.Baseline, d.Current, d.Percent(), d.Calories)
+
+	// Maintenance phases aren't checked.
+	u.Phase.Name = "maintain"
+	d, err = CheckSteps(db, &u, end)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(d == nil)
+
+	// Output:
+	// 35
+	// 6000
+	// 10000 6000 40% 163
+	// true
+}
@@ -0,0 +1,227 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// strengthSchema creates the strength_log table in databases made before
+// it existed.
+const strengthSchema = `
+	CREATE TABLE IF NOT EXISTS strength_log (
+		id INTEGER PRIMARY KEY,
+		date DATE NOT NULL,
+		lift TEXT NOT NULL COLLATE NOCASE,
+		weight REAL NOT NULL,
+		reps INTEGER NOT NULL
+	)
+`
+
+// strengthMargin is the change (%) in a lift's estimated one-rep max
+// within which strength counts as maintained. Day to day performance
+// varies by about this much.
+const strengthMargin = 2.5
+
+// LiftSet is a set from the strength log.
+type LiftSet struct {
+	ID     int       `db:"id"`
+	Date   time.Time `db:"date"`
+	Lift   string    `db:"lift"`
+	Weight float64   `db:"weight"`
+	Reps   int       `db:"reps"`
+}
+
+// E1RM returns the estimated one-rep max of the set.
+func (s LiftSet) E1RM() float64 {
+	return E1RM(s.Weight, s.Reps)
+}
+
+// E1RM estimates the one-rep max from a set of the given weight and
+// reps with the Epley formula. Estimates from sets of more than about 10
+// reps are rough.
+func E1RM(weight float64, reps int) float64 {
+	if reps <= 1 {
+		return weight
+	}
+	return weight * (1 + float64(reps)/30)
+}
+
+// StrengthTrend is how a lift's estimated one-rep max changed over a
+// diet phase, from the best set of its first session to the best set of
+// its latest one.
+type StrengthTrend struct {
+	Lift     string
+	Sessions int
+	Start    float64 // Estimated one-rep max of the first session.
+	Latest   float64 // Estimated one-rep max of the latest session.
+}
+
+// Change returns the change (%) of the estimated one-rep max.
+func (t StrengthTrend) Change() float64 {
+	if t.Start == 0 {
+		return 0
+	}
+	return (t.Latest - t.Start) * 100 / t.Start
+}
+
+// Status returns whether the lift is "climbing", "maintained", or
+// "dropping", or an empty string with a single session.
+func (t StrengthTrend) Status() string {
+	switch c := t.Change(); {
+	case t.Sessions < 2:
+		return ""
+	case c > strengthMargin:
+		return "climbing"
+	case c < -strengthMargin:
+		return "dropping"
+	default:
+		return "maintained"
+	}
+}
+
+// addStrengthTable creates the strength_log table if it doesn't exist,
+// along with its audit triggers.
+func addStrengthTable(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(strengthSchema); err != nil {
+		return fmt.Errorf("couldn't create strength log table: %v", err)
+	}
+	return auditTable(tx, "strength_log")
+}
+
+// AddLiftSet inserts a set into the strength log.
+func AddLiftSet(tx *sqlx.Tx, s *LiftSet) error {
+	s.Lift = strings.TrimSpace(s.Lift)
+	switch {
+	case s.Lift == "":
+		return errors.New("lift name can't be empty")
+	case s.Weight <= 0:
+		return fmt.Errorf("weight must be positive, got %v", s.Weight)
+	case s.Reps < 1:
+		return fmt.Errorf("reps must be at least 1, got %d", s.Reps)
+	}
+	if err := addStrengthTable(tx); err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO strength_log (date, lift, weight, reps)
+		VALUES ($1, $2, $3, $4)
+	`
+	res, err := tx.Exec(query, s.Date.Format(dateFormat), s.Lift, s.Weight, s.Reps)
+	if err != nil {
+		return fmt.Errorf("couldn't insert set: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("couldn't get set id: %v", err)
+	}
+	s.ID = int(id)
+	return nil
+}
+
+// LiftSets returns the sets logged from start up to, but not including,
+// end. Ordered by date.
+func LiftSets(tx *sqlx.Tx, start, end time.Time) ([]LiftSet, error) {
+	if err := addStrengthTable(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT id, date, lift, weight, reps
+		FROM strength_log
+		WHERE date >= $1 AND date < $2
+		ORDER BY date, id
+	`
+	var sets []LiftSet
+	if err := tx.Select(&sets, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get sets: %v", err)
+	}
+	return sets, nil
+}
+
+// StrengthTrends returns the trend of each lift in the sets, ordered by
+// when the lift was first logged. The sets must be ordered by date.
+func StrengthTrends(sets []LiftSet) []StrengthTrend {
+	var trends []StrengthTrend
+	index := make(map[string]int)
+	last := make(map[string]time.Time)
+	for _, s := range sets {
+		key := strings.ToLower(s.Lift)
+		i, ok := index[key]
+		if !ok {
+			i = len(trends)
+			index[key] = i
+			trends = append(trends, StrengthTrend{Lift: s.Lift, Sessions: 1, Start: s.E1RM()})
+			last[key] = s.Date
+		}
+		t := &trends[i]
+		e := s.E1RM()
+		switch {
+		case !s.Date.Equal(last[key]):
+			t.Sessions++
+			t.Latest = e
+			last[key] = s.Date
+		case e > t.Latest:
+			t.Latest = e
+		}
+		if t.Sessions == 1 && e > t.Start {
+			t.Start = e
+		}
+	}
+	return trends
+}
+
+// StrengthSummary prints the trend of each lift logged in the active
+// diet phase.
+func StrengthSummary(db *sqlx.DB, u *UserInfo) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	sets, err := LiftSets(tx, u.Phase.StartDate, LogNow().AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println(paint(colorUnderline, "Strength"))
+	if len(sets) == 0 {
+		fmt.Println("No lifts logged for this diet phase.")
+		return nil
+	}
+	fmt.Printf("%-20s %-10s %-10s %-10s %-8s %s\n", "Lift", "Sessions", "Start", "Latest", "Change", "Trend")
+	for _, t := range StrengthTrends(sets) {
+		status, color := t.Status(), colorMet
+		switch status {
+		case "":
+			status = "-"
+		case "dropping":
+			color = colorMissed
+		}
+		fmt.Printf("%-20s %-10d %-10s %-10s %-8s %s\n", t.Lift, t.Sessions, FormatWeight(t.Start),
+			FormatWeight(t.Latest), formatNumber(t.Change(), 1)+"%", paint(color, status))
+	}
+	return nil
+}
+
+// ShowLiftLog prints the strength log.
+func ShowLiftLog(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	sets, err := LiftSets(tx, time.Time{}, LogNow().AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	for _, s := range sets {
+		fmt.Printf("%s %-20s %8s x %-3d e1RM %s\n", s.Date.Format(dateFormat), s.Lift,
+			FormatWeight(s.Weight), s.Reps, FormatWeight(s.E1RM()))
+	}
+	return nil
+}
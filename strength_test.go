@@ -0,0 +1,45 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleStrengthTrends() {
+	db := dbtest.MustNew()
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, s := range []LiftSet{
+		{Date: start, Lift: "Squat", Weight: 140, Reps: 5},
+		{Date: start, Lift: "Squat", Weight: 150, Reps: 3},
+		{Date: start, Lift: "Bench press", Weight: 100, Reps: 5},
+		{Date: start.AddDate(0, 0, 7), Lift: "squat", Weight: 145, Reps: 5},
+		{Date: start.AddDate(0, 0, 7), Lift: "Bench press", Weight: 90, Reps: 5},
+		{Date: start.AddDate(0, 0, 14), Lift: "Squat", Weight: 150, Reps: 5},
+		{Date: start.AddDate(0, 0, 14), Lift: "Deadlift", Weight: 180, Reps: 1},
+	} {
+		s := s
+		if err := AddLiftSet(tx, &s); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	sets, err := LiftSets(tx, start, start.AddDate(0, 0, 15))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, t := range StrengthTrends(sets) {
+		fmt.Printf("%s: %d sessions, %.1f -> %.1f (%q)\n", t.Lift, t.Sessions, t.Start, t.Latest, t.Status())
+	}
+
+	// Output:
+	// Squat: 3 sessions, 165.0 -> 175.0 ("climbing")
+	// Bench press: 2 sessions, 116.7 -> 105.0 ("dropping")
+	// Deadlift: 1 sessions, 180.0 -> 180.0 ("")
+}
@@ -0,0 +1,286 @@
+package bite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// supplementsSchema creates the supplements tables in databases made
+// before they existed.
+const supplementsSchema = `
+	CREATE TABLE IF NOT EXISTS supplements (
+		supp_id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+		dose TEXT DEFAULT '' NOT NULL,
+		time TEXT DEFAULT '' NOT NULL,
+		start_date DATE NOT NULL,
+		active INTEGER DEFAULT 1 NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS daily_supps (
+		date DATE NOT NULL,
+		supp_id INTEGER REFERENCES supplements(supp_id) NOT NULL,
+		time TIME NOT NULL,
+		PRIMARY KEY (date, supp_id)
+	);
+`
+
+// Supplement is a supplement taken every day, such as creatine or
+// vitamin D. Supplements have no calories, so they are tracked apart
+// from foods.
+type Supplement struct {
+	ID        int       `db:"supp_id"`
+	Name      string    `db:"name"`
+	Dose      string    `db:"dose"` // Such as "5 g" or "1000 IU".
+	Time      string    `db:"time"` // Time of day ("15:04") it is due, or empty for any time.
+	StartDate time.Time `db:"start_date"`
+}
+
+// String returns the name and dose of the supplement.
+func (s Supplement) String() string {
+	if s.Dose == "" {
+		return s.Name
+	}
+	return fmt.Sprintf("%s (%s)", s.Name, s.Dose)
+}
+
+// SupplementAdherence is how many days a supplement was taken over some
+// period.
+type SupplementAdherence struct {
+	Supplement
+	Taken int // Days taken.
+	Days  int // Days it was scheduled.
+}
+
+// Percent returns the share of scheduled days the supplement was taken.
+func (a SupplementAdherence) Percent() float64 {
+	if a.Days == 0 {
+		return 0
+	}
+	return float64(a.Taken) * 100 / float64(a.Days)
+}
+
+// addSupplementTables creates the supplements tables if they don't
+// exist, along with their audit triggers.
+func addSupplementTables(tx *sqlx.Tx) error {
+	if _, err := tx.Exec(supplementsSchema); err != nil {
+		return fmt.Errorf("couldn't create supplements tables: %v", err)
+	}
+	for _, t := range []string{"supplements", "daily_supps"} {
+		if err := auditTable(tx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddSupplement schedules a supplement to be taken every day from
+// today. Scheduling a supplement again updates its dose and time.
+func AddSupplement(tx *sqlx.Tx, s Supplement) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return errors.New("supplement name can't be empty")
+	}
+	if s.Time != "" {
+		t, err := time.Parse("15:04", s.Time)
+		if err != nil {
+			return fmt.Errorf("time must be HH:MM, got %q", s.Time)
+		}
+		s.Time = t.Format("15:04")
+	}
+	if err := addSupplementTables(tx); err != nil {
+		return err
+	}
+
+	const query = `
+		INSERT INTO supplements (name, dose, time, start_date)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT(name) DO UPDATE SET dose = $2, time = $3,
+			start_date = CASE WHEN active THEN start_date ELSE $4 END,
+			active = 1
+	`
+	if _, err := tx.Exec(query, s.Name, s.Dose, s.Time, Today().Format(dateFormat)); err != nil {
+		return fmt.Errorf("couldn't add supplement: %v", err)
+	}
+	return nil
+}
+
+// RemoveSupplement stops scheduling the supplement. The days it was
+// taken are kept.
+func RemoveSupplement(tx *sqlx.Tx, name string) error {
+	if err := addSupplementTables(tx); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE supplements SET active = 0 WHERE name = $1 AND active`, strings.TrimSpace(name))
+	if err != nil {
+		return fmt.Errorf("couldn't remove supplement: %v", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("no supplement named %q is scheduled", name)
+	}
+	return nil
+}
+
+// Supplements returns the scheduled supplements, ordered by time of day
+// and name.
+func Supplements(tx *sqlx.Tx) ([]Supplement, error) {
+	if err := addSupplementTables(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT supp_id, name, dose, time, start_date FROM supplements
+		WHERE active
+		ORDER BY time, name
+	`
+	var supps []Supplement
+	if err := tx.Select(&supps, query); err != nil {
+		return nil, fmt.Errorf("couldn't get supplements: %v", err)
+	}
+	return supps, nil
+}
+
+// LogSupplement records that the scheduled supplement of the given name
+// was taken at the given time. Logging it again the same day replaces
+// the time.
+func LogSupplement(tx *sqlx.Tx, name string, at time.Time) error {
+	if err := addSupplementTables(tx); err != nil {
+		return err
+	}
+	var id int
+	err := tx.Get(&id, `SELECT supp_id FROM supplements WHERE name = $1 AND active`, strings.TrimSpace(name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no supplement named %q is scheduled, add it with \"bite supp add\"", name)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't find supplement: %v", err)
+	}
+
+	const query = `
+		INSERT INTO daily_supps (date, supp_id, time) VALUES ($1, $2, $3)
+		ON CONFLICT(date, supp_id) DO UPDATE SET time = $3
+	`
+	if _, err := tx.Exec(query, at.Format(dateFormat), id, at.Format("15:04:05")); err != nil {
+		return fmt.Errorf("couldn't log supplement: %v", err)
+	}
+	return nil
+}
+
+// SupplementsBetween returns the adherence of each scheduled supplement
+// from start up to, but not including, end. Only days from the day it
+// was scheduled up to today count.
+func SupplementsBetween(tx *sqlx.Tx, start, end time.Time) ([]SupplementAdherence, error) {
+	supps, err := Supplements(tx)
+	if err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT supp_id, COUNT(*) FROM daily_supps
+		WHERE date >= $1 AND date < $2
+		GROUP BY supp_id
+	`
+	rows, err := tx.Query(query, start.Format(dateFormat), end.Format(dateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get supplement adherence: %v", err)
+	}
+	defer rows.Close()
+	taken := make(map[int]int)
+	for rows.Next() {
+		var id, n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("couldn't get supplement adherence: %v", err)
+		}
+		taken[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't get supplement adherence: %v", err)
+	}
+
+	start, end = dateOf(start), dateOf(end)
+	if tomorrow := Today().AddDate(0, 0, 1); end.After(tomorrow) {
+		end = tomorrow
+	}
+	var adherence []SupplementAdherence
+	for _, s := range supps {
+		from := start
+		if d := dateOf(s.StartDate); d.After(from) {
+			from = d
+		}
+		if !end.After(from) {
+			continue
+		}
+		a := SupplementAdherence{Supplement: s, Taken: taken[s.ID], Days: int(end.Sub(from).Hours() / 24)}
+		if a.Taken > a.Days {
+			a.Taken = a.Days // Taken before it was scheduled again.
+		}
+		adherence = append(adherence, a)
+	}
+	return adherence, nil
+}
+
+// DueSupplements returns the scheduled supplements not yet taken on the
+// day of now whose time of day has come. Supplements without a time are
+// due all day.
+func DueSupplements(tx *sqlx.Tx, now time.Time) ([]Supplement, error) {
+	if err := addSupplementTables(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT s.supp_id, s.name, s.dose, s.time, s.start_date FROM supplements s
+		WHERE s.active AND s.time <= $1
+			AND NOT EXISTS (SELECT 1 FROM daily_supps d WHERE d.supp_id = s.supp_id AND d.date = $2)
+		ORDER BY s.time, s.name
+	`
+	var due []Supplement
+	if err := tx.Select(&due, query, now.Format("15:04"), now.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get due supplements: %v", err)
+	}
+	return due, nil
+}
+
+// SupplementSummary prints how many days each supplement was taken in
+// each of the last given number of weeks, starting on WeekStart.
+func SupplementSummary(db *sqlx.DB, weeks int, now time.Time) error {
+	if weeks < 1 {
+		return fmt.Errorf("weeks must be at least 1, got %d", weeks)
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	start := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	for w := start; !w.After(now); w = w.AddDate(0, 0, 7) {
+		adherence, err := SupplementsBetween(tx, w, w.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+		fmt.Println(paint(colorUnderline, "Week of "+w.Format(dateFormat)))
+		if len(adherence) == 0 {
+			fmt.Println("No supplements scheduled.")
+		}
+		printSupplementAdherence(adherence)
+		fmt.Println()
+	}
+	return nil
+}
+
+// printSupplementAdherence prints a table of supplement adherence.
+func printSupplementAdherence(adherence []SupplementAdherence) {
+	if len(adherence) == 0 {
+		return
+	}
+	fmt.Printf("%-30s %-8s %s\n", "Supplement", "Taken", "Share")
+	for _, a := range adherence {
+		color := colorMet
+		if a.Taken < a.Days {
+			color = colorMissed
+		}
+		share := fmt.Sprintf("%.0f%%", a.Percent())
+		fmt.Printf("%-30s %-8s %s\n", a.String(), fmt.Sprintf("%d/%d", a.Taken, a.Days), paint(color, share))
+	}
+}
@@ -0,0 +1,75 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleSupplementsBetween() {
+	monday := time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC)
+	restore := SetClock(FixedClock(monday))
+	defer restore()
+
+	db := dbtest.MustNew()
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	for _, s := range []Supplement{
+		{Name: "Creatine", Dose: "5 g"},
+		{Name: "Vitamin D", Dose: "1000 IU", Time: "8:00"},
+		{Name: "Fish oil", Time: "20:00"},
+	} {
+		if err := AddSupplement(tx, s); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Creatine is taken every day and vitamin D on weekdays.
+	for i := 0; i < 7; i++ {
+		day := monday.AddDate(0, 0, i).Add(9 * time.Hour)
+		if err := LogSupplement(tx, "creatine", day); err != nil {
+			log.Fatal(err)
+		}
+		if i < 5 {
+			if err := LogSupplement(tx, "Vitamin D", day); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	fmt.Println(LogSupplement(tx, "Magnesium", monday))
+
+	// Fish oil is stopped, then scheduled again on Thursday.
+	if err := RemoveSupplement(tx, "fish oil"); err != nil {
+		log.Fatal(err)
+	}
+	SetClock(FixedClock(monday.AddDate(0, 0, 3)))
+	if err := AddSupplement(tx, Supplement{Name: "Fish oil", Time: "20:00"}); err != nil {
+		log.Fatal(err)
+	}
+
+	// It is now Sunday at noon.
+	SetClock(FixedClock(monday.AddDate(0, 0, 6).Add(12 * time.Hour)))
+	adherence, err := SupplementsBetween(tx, monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, a := range adherence {
+		fmt.Printf("%s: %d/%d days (%.0f%%)\n", a, a.Taken, a.Days, a.Percent())
+	}
+
+	due, err := DueSupplements(tx, Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Due:", due)
+	// Output:
+	// no supplement named "Magnesium" is scheduled, add it with "bite supp add"
+	// Creatine (5 g): 7/7 days (100%)
+	// Vitamin D (1000 IU): 5/7 days (71%)
+	// Fish oil: 0/4 days (0%)
+	// Due: [Vitamin D (1000 IU)]
+}
@@ -0,0 +1,706 @@
+package bite
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// changesetVersion is the version of the changeset format.
+	changesetVersion = 1
+
+	// SyncTimeFormat is the format of the row version timestamps. They
+	// are always in UTC so that they can be compared as strings.
+	SyncTimeFormat = "2006-01-02T15:04:05.000Z"
+
+	// sqlNow is the SQL expression for the current row version
+	// timestamp.
+	sqlNow = `strftime('%Y-%m-%dT%H:%M:%fZ', 'now')`
+)
+
+// syncTables are the log tables kept in sync between devices.
+var syncTables = []string{"daily_foods", "daily_weights"}
+
+// contentColumns are the columns of each sync table that make up the
+// content hash of a row.
+var contentColumns = map[string]string{
+	"daily_foods":   "food_id, date, time, serving_size, number_of_servings",
+	"daily_weights": "date, time, weight",
+}
+
+// Changeset holds the log rows that were added, changed, or deleted
+// since a point in time.
+type Changeset struct {
+	Version int `json:"version"`
+
+	// Since is the row version the changes were collected from. It is
+	// empty if the changeset holds every row.
+	Since string `json:"since"`
+
+	// Created is the time the changeset was exported.
+	Created string `json:"created"`
+
+	Foods       []SyncFood       `json:"daily_foods,omitempty"`
+	Weights     []SyncWeight     `json:"daily_weights,omitempty"`
+	Deleted     []SyncDeletion   `json:"deleted,omitempty"`
+	Attachments []SyncAttachment `json:"attachments,omitempty"`
+}
+
+// SyncFood is a food entry in a changeset.
+type SyncFood struct {
+	UID              string  `db:"uid" json:"uid"`
+	UpdatedAt        string  `db:"updated_at" json:"updated_at"`
+	FoodID           int     `db:"food_id" json:"food_id"`
+	MealID           *int    `db:"meal_id" json:"meal_id,omitempty"`
+	Date             string  `db:"date" json:"date"`
+	Time             string  `db:"time" json:"time"`
+	ServingSize      float64 `db:"serving_size" json:"serving_size"`
+	NumberOfServings float64 `db:"number_of_servings" json:"number_of_servings"`
+	Calories         float64 `db:"calories" json:"calories"`
+	Protein          float64 `db:"protein" json:"protein"`
+	Fat              float64 `db:"fat" json:"fat"`
+	Carbs            float64 `db:"carbs" json:"carbs"`
+	Price            float64 `db:"price" json:"price"`
+
+	// The food and meal of the entry are found on the importing device
+	// by these, since foods and meals made on a device have ids of their
+	// own there. Foods from the USDA database have the same id on every
+	// device, so they are found by their id.
+	FoodName        string  `db:"food_name" json:"food_name,omitempty"`
+	BrandName       string  `db:"brand_name" json:"brand_name,omitempty"`
+	FoodServingSize float64 `db:"food_serving_size" json:"food_serving_size,omitempty"`
+	FoodServingUnit string  `db:"food_serving_unit" json:"food_serving_unit,omitempty"`
+	FoodSource      string  `db:"food_source" json:"food_source,omitempty"`
+	MealName        *string `db:"meal_name" json:"meal_name,omitempty"`
+
+	// ContentHash isn't exported. The importing device computes it.
+	ContentHash string `db:"content_hash" json:"-"`
+}
+
+// SyncWeight is a weight entry in a changeset.
+type SyncWeight struct {
+	UID       string  `db:"uid" json:"uid"`
+	UpdatedAt string  `db:"updated_at" json:"updated_at"`
+	Date      string  `db:"date" json:"date"`
+	Time      string  `db:"time" json:"time"`
+	Weight    float64 `db:"weight" json:"weight"`
+
+	ContentHash string `db:"content_hash" json:"-"`
+}
+
+// SyncAttachment is a reference from a log row to a local file in a
+// changeset. Only the path is exported, not the file.
+type SyncAttachment struct {
+	EntryUID  string `db:"entry_uid" json:"entry_uid"`
+	Table     string `db:"entry_table" json:"table"`
+	Path      string `db:"path" json:"path"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+}
+
+// SyncDeletion records a deleted log row.
+type SyncDeletion struct {
+	UID       string `db:"uid" json:"uid"`
+	Table     string `db:"tbl" json:"table"`
+	DeletedAt string `db:"deleted_at" json:"deleted_at"`
+}
+
+// SyncResult counts what happened to the rows of an imported changeset.
+type SyncResult struct {
+	Inserted int
+	Updated  int
+	Deleted  int
+
+	// Skipped rows were already up to date or can't be imported, e.g.
+	// food entries of foods that don't exist on this device.
+	Skipped int
+
+	// Duplicates are new rows with the same content as an existing row.
+	// They aren't imported.
+	Duplicates int
+
+	// Conflicts counts rows that were changed on both devices. They are
+	// also counted as updated or skipped, depending on which change won.
+	Conflicts int
+}
+
+func (r SyncResult) String() string {
+	return fmt.Sprintf("%d inserted, %d updated, %d deleted, %d skipped, %d duplicates, %d conflicts",
+		r.Inserted, r.Updated, r.Deleted, r.Skipped, r.Duplicates, r.Conflicts)
+}
+
+// PrepareSync adds the row version and content hash columns, triggers,
+// and the deletion log used by sync to the database. It is safe to call
+// more than once. Rows that existed before are given a version of the
+// current time.
+func PrepareSync(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("couldn't create transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	const deletions = `
+		CREATE TABLE IF NOT EXISTS sync_deletions (
+			uid TEXT PRIMARY KEY NOT NULL,
+			tbl TEXT NOT NULL,
+			deleted_at TEXT NOT NULL
+		)
+	`
+	if _, err := tx.Exec(deletions); err != nil {
+		return fmt.Errorf("couldn't create deletion log: %v", err)
+	}
+	if _, err := tx.Exec(attachmentsTable); err != nil {
+		return fmt.Errorf("couldn't create attachments: %v", err)
+	}
+	// Food entries are exported with the source of their food.
+	if err := addFoodColumns(tx); err != nil {
+		return err
+	}
+
+	for _, t := range syncTables {
+		var cols []string
+		if err := tx.Select(&cols, `SELECT name FROM pragma_table_info($1)`, t); err != nil {
+			return fmt.Errorf("couldn't get columns of %s: %v", t, err)
+		}
+		for _, c := range []string{"uid", "updated_at", "content_hash"} {
+			if contains(cols, c) {
+				continue
+			}
+			if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s TEXT`, t, c)); err != nil {
+				return fmt.Errorf("couldn't add %s column to %s: %v", c, t, err)
+			}
+		}
+
+		stmts := []string{
+			fmt.Sprintf(`UPDATE %s SET uid = lower(hex(randomblob(16))) WHERE uid IS NULL`, t),
+			fmt.Sprintf(`UPDATE %s SET updated_at = %s WHERE updated_at IS NULL`, t, sqlNow),
+			fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %[1]s_uid ON %[1]s (uid)`, t),
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %[1]s_content_hash ON %[1]s (content_hash)`, t),
+
+			// Rows inserted by sync keep their uid and version.
+			fmt.Sprintf(`
+				CREATE TRIGGER IF NOT EXISTS %[1]s_sync_insert AFTER INSERT ON %[1]s
+				WHEN new.uid IS NULL OR new.updated_at IS NULL
+				BEGIN
+					UPDATE %[1]s SET
+						uid = COALESCE(new.uid, lower(hex(randomblob(16)))),
+						updated_at = COALESCE(new.updated_at, %[2]s)
+					WHERE id = new.id;
+				END
+			`, t, sqlNow),
+
+			// Updates that don't set the version or content hash
+			// themselves bump the version. The trigger is recreated as
+			// older versions didn't check the content hash.
+			fmt.Sprintf(`DROP TRIGGER IF EXISTS %s_sync_update`, t),
+			fmt.Sprintf(`
+				CREATE TRIGGER %[1]s_sync_update AFTER UPDATE ON %[1]s
+				WHEN new.updated_at IS old.updated_at
+					AND new.content_hash IS old.content_hash
+				BEGIN
+					UPDATE %[1]s SET updated_at = %[2]s WHERE id = new.id;
+				END
+			`, t, sqlNow),
+
+			// The hash is computed in Go, so a changed row loses its hash
+			// until BackfillHashes runs.
+			fmt.Sprintf(`
+				CREATE TRIGGER IF NOT EXISTS %[1]s_content_update AFTER UPDATE OF %[2]s ON %[1]s
+				WHEN new.content_hash IS old.content_hash
+					AND (%[3]s) IS NOT (%[4]s)
+				BEGIN
+					UPDATE %[1]s SET content_hash = NULL WHERE id = new.id;
+				END
+			`, t, contentColumns[t], qualify("new", contentColumns[t]), qualify("old", contentColumns[t])),
+
+			fmt.Sprintf(`
+				CREATE TRIGGER IF NOT EXISTS %[1]s_sync_delete AFTER DELETE ON %[1]s
+				WHEN old.uid IS NOT NULL
+				BEGIN
+					INSERT OR REPLACE INTO sync_deletions (uid, tbl, deleted_at)
+					VALUES (old.uid, '%[1]s', %[2]s);
+				END
+			`, t, sqlNow),
+		}
+		for _, s := range stmts {
+			if _, err := tx.Exec(s); err != nil {
+				return fmt.Errorf("couldn't prepare %s for sync: %v", t, err)
+			}
+		}
+	}
+
+	if err := BackfillHashes(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// qualify prefixes each column of the comma-separated list with the
+// table name.
+func qualify(table, columns string) string {
+	cols := strings.Split(columns, ", ")
+	for i, c := range cols {
+		cols[i] = table + "." + c
+	}
+	return strings.Join(cols, ", ")
+}
+
+// contains reports whether the string is in the slice.
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportChanges returns the log rows added, changed, or deleted after
+// since. A zero since exports every row.
+func ExportChanges(db *sqlx.DB, since time.Time) (*Changeset, error) {
+	cs := &Changeset{
+		Version: changesetVersion,
+		Created: Now().UTC().Format(SyncTimeFormat),
+	}
+	if !since.IsZero() {
+		cs.Since = since.UTC().Format(SyncTimeFormat)
+	}
+
+	// Dates are cast to text so that the driver doesn't parse them.
+	const foodsQuery = `
+		SELECT df.uid, df.updated_at, df.food_id, df.meal_id,
+			CAST(df.date AS TEXT) AS date, df.time, df.serving_size,
+			df.number_of_servings, df.calories, df.protein, df.fat, df.carbs,
+			COALESCE(df.price, 0) AS price,
+			COALESCE(f.food_name, '') AS food_name,
+			COALESCE(f.brand_name, '') AS brand_name,
+			COALESCE(f.serving_size, 0) AS food_serving_size,
+			COALESCE(f.serving_unit, '') AS food_serving_unit,
+			COALESCE(f.source, '') AS food_source,
+			m.meal_name
+		FROM daily_foods df
+		LEFT JOIN foods f ON f.food_id = df.food_id
+		LEFT JOIN meals m ON m.meal_id = df.meal_id
+		WHERE df.updated_at > $1
+		ORDER BY df.updated_at
+	`
+	if err := db.Select(&cs.Foods, foodsQuery, cs.Since); err != nil {
+		return nil, fmt.Errorf("couldn't get changed food entries: %v", err)
+	}
+
+	const weightsQuery = `
+		SELECT uid, updated_at, CAST(date AS TEXT) AS date, time, weight
+		FROM daily_weights
+		WHERE updated_at > $1
+		ORDER BY updated_at
+	`
+	if err := db.Select(&cs.Weights, weightsQuery, cs.Since); err != nil {
+		return nil, fmt.Errorf("couldn't get changed weight entries: %v", err)
+	}
+
+	const deletedQuery = `
+		SELECT uid, tbl, deleted_at FROM sync_deletions
+		WHERE deleted_at > $1
+		ORDER BY deleted_at
+	`
+	if err := db.Select(&cs.Deleted, deletedQuery, cs.Since); err != nil {
+		return nil, fmt.Errorf("couldn't get deleted entries: %v", err)
+	}
+
+	const attachmentsQuery = `
+		SELECT COALESCE(df.uid, dw.uid) AS entry_uid, a.entry_table, a.path,
+			a.created_at
+		FROM attachments a
+		LEFT JOIN daily_foods df ON a.entry_table = 'daily_foods' AND df.id = a.entry_id
+		LEFT JOIN daily_weights dw ON a.entry_table = 'daily_weights' AND dw.id = a.entry_id
+		WHERE a.created_at > $1 AND COALESCE(df.uid, dw.uid) IS NOT NULL
+		ORDER BY a.created_at
+	`
+	if err := db.Select(&cs.Attachments, attachmentsQuery, cs.Since); err != nil {
+		return nil, fmt.Errorf("couldn't get attachments: %v", err)
+	}
+
+	return cs, nil
+}
+
+// ImportChanges applies the changeset to the database. When a row was
+// changed on both devices since the changeset's base, the most recent
+// change wins.
+func ImportChanges(db *sqlx.DB, cs *Changeset) (SyncResult, error) {
+	var r SyncResult
+	if cs.Version != changesetVersion {
+		return r, fmt.Errorf("unsupported changeset version %d", cs.Version)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return r, fmt.Errorf("couldn't create transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := BackfillHashes(tx); err != nil {
+		return r, err
+	}
+
+	for _, f := range cs.Foods {
+		if err := importFood(tx, cs.Since, f, &r); err != nil {
+			return r, err
+		}
+	}
+	for _, w := range cs.Weights {
+		if err := importWeight(tx, cs.Since, w, &r); err != nil {
+			return r, err
+		}
+	}
+	for _, d := range cs.Deleted {
+		if err := importDeletion(tx, d, &r); err != nil {
+			return r, err
+		}
+	}
+	for _, a := range cs.Attachments {
+		if err := importAttachment(tx, a, &r); err != nil {
+			return r, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return r, fmt.Errorf("couldn't commit changeset: %v", err)
+	}
+	return r, nil
+}
+
+// localVersion returns the id and version of the row with the given
+// uid. A nil id means the row doesn't exist.
+func localVersion(tx *sqlx.Tx, table, uid string) (*int, string, error) {
+	var row struct {
+		ID        int    `db:"id"`
+		UpdatedAt string `db:"updated_at"`
+	}
+	query := fmt.Sprintf(`SELECT id, updated_at FROM %s WHERE uid = $1`, table)
+	if err := tx.Get(&row, query, uid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("couldn't get %s row %s: %v", table, uid, err)
+	}
+	return &row.ID, row.UpdatedAt, nil
+}
+
+// deletedAfter reports whether the row with the given uid was deleted
+// on this device at or after the given version.
+func deletedAfter(tx *sqlx.Tx, uid, version string) (bool, error) {
+	const query = `SELECT COUNT(*) FROM sync_deletions WHERE uid = $1 AND deleted_at >= $2`
+	var n int
+	if err := tx.Get(&n, query, uid, version); err != nil {
+		return false, fmt.Errorf("couldn't check deletion log: %v", err)
+	}
+	return n > 0, nil
+}
+
+// resolve decides whether an incoming row should replace the local one
+// and counts the outcome. It reports whether the incoming row wins.
+func resolve(since, local, incoming string, r *SyncResult) bool {
+	if local == incoming {
+		r.Skipped++
+		return false
+	}
+	if since != "" && local > since {
+		r.Conflicts++
+	}
+	if incoming < local {
+		r.Skipped++
+		return false
+	}
+	return true
+}
+
+// localFood sets the food and meal ids of the entry to those of its
+// food and meal on this device. It reports whether the food exists
+// here. An entry whose meal doesn't exist here is imported without one.
+// Entries of changesets exported before foods and meals were found by
+// name keep their ids.
+func localFood(tx *sqlx.Tx, f *SyncFood) (bool, error) {
+	var ids []int
+	var err error
+	switch f.FoodSource {
+	case "":
+		err = tx.Select(&ids, `SELECT food_id FROM foods WHERE food_id = $1`, f.FoodID)
+	case SourceUSDA:
+		const query = `SELECT food_id FROM foods WHERE food_id = $1 AND source = $2`
+		err = tx.Select(&ids, query, f.FoodID, SourceUSDA)
+	default:
+		const query = `
+			SELECT food_id FROM foods
+			WHERE food_name = $1 AND COALESCE(brand_name, '') = $2
+				AND serving_size = $3 AND serving_unit = $4
+			ORDER BY food_id
+			LIMIT 1
+		`
+		err = tx.Select(&ids, query, f.FoodName, f.BrandName, f.FoodServingSize, f.FoodServingUnit)
+	}
+	if err != nil {
+		return false, fmt.Errorf("couldn't find food %q: %v", f.FoodName, err)
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	f.FoodID = ids[0]
+
+	if f.MealID == nil || f.MealName == nil {
+		return true, nil
+	}
+	var meals []int
+	const query = `SELECT meal_id FROM meals WHERE meal_name = $1 ORDER BY meal_id LIMIT 1`
+	if err := tx.Select(&meals, query, *f.MealName); err != nil {
+		return false, fmt.Errorf("couldn't find meal %q: %v", *f.MealName, err)
+	}
+	f.MealID = nil
+	if len(meals) > 0 {
+		f.MealID = &meals[0]
+	}
+	return true, nil
+}
+
+func importFood(tx *sqlx.Tx, since string, f SyncFood, r *SyncResult) error {
+	found, err := localFood(tx, &f)
+	if err != nil {
+		return err
+	}
+	if !found {
+		r.Skipped++
+		return nil
+	}
+	f.ContentHash = f.Hash()
+	id, version, err := localVersion(tx, "daily_foods", f.UID)
+	if err != nil {
+		return err
+	}
+
+	if id == nil {
+		deleted, err := deletedAfter(tx, f.UID, f.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			r.Skipped++
+			return nil
+		}
+		dup, err := hashExists(tx, "daily_foods", f.ContentHash)
+		if err != nil {
+			return err
+		}
+		if dup {
+			r.Duplicates++
+			return nil
+		}
+
+		const query = `
+			INSERT INTO daily_foods (uid, updated_at, content_hash, food_id, meal_id,
+				date, time, serving_size, number_of_servings, calories, protein, fat,
+				carbs, price)
+			VALUES (:uid, :updated_at, :content_hash, :food_id, :meal_id,
+				:date, :time, :serving_size, :number_of_servings, :calories, :protein, :fat,
+				:carbs, :price)
+		`
+		if _, err := tx.NamedExec(query, f); err != nil {
+			return fmt.Errorf("couldn't insert food entry %s: %v", f.UID, err)
+		}
+		r.Inserted++
+		return nil
+	}
+
+	if !resolve(since, version, f.UpdatedAt, r) {
+		return nil
+	}
+	const query = `
+		UPDATE daily_foods SET updated_at = :updated_at,
+			content_hash = :content_hash, food_id = :food_id,
+			meal_id = :meal_id, date = :date, time = :time,
+			serving_size = :serving_size, number_of_servings = :number_of_servings,
+			calories = :calories, protein = :protein, fat = :fat, carbs = :carbs,
+			price = :price
+		WHERE uid = :uid
+	`
+	if _, err := tx.NamedExec(query, f); err != nil {
+		return fmt.Errorf("couldn't update food entry %s: %v", f.UID, err)
+	}
+	r.Updated++
+	return nil
+}
+
+func importWeight(tx *sqlx.Tx, since string, w SyncWeight, r *SyncResult) error {
+	w.ContentHash = w.Hash()
+	id, version, err := localVersion(tx, "daily_weights", w.UID)
+	if err != nil {
+		return err
+	}
+
+	if id == nil {
+		deleted, err := deletedAfter(tx, w.UID, w.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			r.Skipped++
+			return nil
+		}
+		dup, err := hashExists(tx, "daily_weights", w.ContentHash)
+		if err != nil {
+			return err
+		}
+		if dup {
+			r.Duplicates++
+			return nil
+		}
+		// Only one weight can be logged per day. A different entry for
+		// the same day is a conflict that is left for the user to fix.
+		var n int
+		if err := tx.Get(&n, `SELECT COUNT(*) FROM daily_weights WHERE date = $1`, w.Date); err != nil {
+			return fmt.Errorf("couldn't check weight entries: %v", err)
+		}
+		if n > 0 {
+			r.Conflicts++
+			r.Skipped++
+			return nil
+		}
+
+		const query = `
+			INSERT INTO daily_weights (uid, updated_at, content_hash, date, time, weight)
+			VALUES (:uid, :updated_at, :content_hash, :date, :time, :weight)
+		`
+		if _, err := tx.NamedExec(query, w); err != nil {
+			return fmt.Errorf("couldn't insert weight entry %s: %v", w.UID, err)
+		}
+		r.Inserted++
+		return nil
+	}
+
+	if !resolve(since, version, w.UpdatedAt, r) {
+		return nil
+	}
+	const query = `
+		UPDATE daily_weights SET updated_at = :updated_at,
+			content_hash = :content_hash, date = :date, time = :time,
+			weight = :weight
+		WHERE uid = :uid
+	`
+	if _, err := tx.NamedExec(query, w); err != nil {
+		return fmt.Errorf("couldn't update weight entry %s: %v", w.UID, err)
+	}
+	r.Updated++
+	return nil
+}
+
+func importDeletion(tx *sqlx.Tx, d SyncDeletion, r *SyncResult) error {
+	if !contains(syncTables, d.Table) {
+		return fmt.Errorf("unknown table %q in changeset", d.Table)
+	}
+
+	id, version, err := localVersion(tx, d.Table, d.UID)
+	if err != nil {
+		return err
+	}
+	if id != nil {
+		// A row changed after it was deleted elsewhere is kept.
+		if version > d.DeletedAt {
+			r.Conflicts++
+			r.Skipped++
+			return nil
+		}
+		query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, d.Table)
+		if _, err := tx.Exec(query, *id); err != nil {
+			return fmt.Errorf("couldn't delete %s row %s: %v", d.Table, d.UID, err)
+		}
+		if err := deleteAttachments(tx, d.Table, *id); err != nil {
+			return err
+		}
+		r.Deleted++
+	} else {
+		r.Skipped++
+	}
+
+	// Remember the deletion so that the row isn't brought back by an
+	// older changeset.
+	const query = `
+		INSERT INTO sync_deletions (uid, tbl, deleted_at) VALUES (:uid, :tbl, :deleted_at)
+		ON CONFLICT(uid) DO UPDATE SET deleted_at = :deleted_at
+	`
+	if _, err := tx.NamedExec(query, d); err != nil {
+		return fmt.Errorf("couldn't log deletion of %s: %v", d.UID, err)
+	}
+	return nil
+}
+
+// WriteChangeset writes the changeset to w as gzipped JSON.
+func WriteChangeset(w io.Writer, cs *Changeset) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(cs); err != nil {
+		return fmt.Errorf("couldn't encode changeset: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("couldn't compress changeset: %v", err)
+	}
+	return nil
+}
+
+// ReadChangeset reads a changeset written by WriteChangeset.
+func ReadChangeset(r io.Reader) (*Changeset, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decompress changeset: %v", err)
+	}
+	defer zr.Close()
+
+	var cs Changeset
+	if err := json.NewDecoder(zr).Decode(&cs); err != nil {
+		return nil, fmt.Errorf("couldn't decode changeset: %v", err)
+	}
+	return &cs, nil
+}
+
+// importAttachment adds an attachment to the local row it refers to.
+// Attachments of rows that don't exist on this device are skipped.
+func importAttachment(tx *sqlx.Tx, a SyncAttachment, r *SyncResult) error {
+	if !contains(syncTables, a.Table) {
+		return fmt.Errorf("unknown table %q in changeset", a.Table)
+	}
+	id, _, err := localVersion(tx, a.Table, a.EntryUID)
+	if err != nil {
+		return err
+	}
+	if id == nil {
+		r.Skipped++
+		return nil
+	}
+
+	var n int
+	const exists = `
+		SELECT COUNT(*) FROM attachments
+		WHERE entry_table = $1 AND entry_id = $2 AND path = $3
+	`
+	if err := tx.Get(&n, exists, a.Table, *id, a.Path); err != nil {
+		return fmt.Errorf("couldn't check attachments: %v", err)
+	}
+	if n > 0 {
+		r.Duplicates++
+		return nil
+	}
+
+	const query = `
+		INSERT INTO attachments (entry_table, entry_id, path, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(query, a.Table, *id, a.Path, a.CreatedAt); err != nil {
+		return fmt.Errorf("couldn't insert attachment for %s: %v", a.EntryUID, err)
+	}
+	r.Inserted++
+	return nil
+}
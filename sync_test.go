@@ -0,0 +1,215 @@
+package bite
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+	"github.com/jmoiron/sqlx"
+)
+
+// syncTestDB creates an in-memory database with an apple and bread
+// for foods, prepared for sync.
+func syncTestDB() *sqlx.DB {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Apple', 182, 'g', '1 medium'), (2, 'Bread', 50, 'g', '1 slice');
+	`)
+
+	if err := PrepareSync(db); err != nil {
+		panic(err)
+	}
+	return db
+}
+
+// transfer exports the changes after since from one database and
+// imports them into another.
+func transfer(from, to *sqlx.DB, since time.Time) (*Changeset, SyncResult, error) {
+	cs, err := ExportChanges(from, since)
+	if err != nil {
+		return nil, SyncResult{}, err
+	}
+	var buf bytes.Buffer
+	if err := WriteChangeset(&buf, cs); err != nil {
+		return nil, SyncResult{}, err
+	}
+	cs, err = ReadChangeset(&buf)
+	if err != nil {
+		return nil, SyncResult{}, err
+	}
+	r, err := ImportChanges(to, cs)
+	return cs, r, err
+}
+
+func ExampleImportChanges() {
+	laptop := syncTestDB()
+	defer laptop.Close()
+	home := syncTestDB()
+	defer home.Close()
+
+	laptop.MustExec(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving)
+		VALUES (3, 'Honey', 21, 'g', '1 tbsp');
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (1, '2024-01-01', '08:00:00', 100, 52, 0.3, 0.2, 14),
+		       (2, '2024-01-01', '12:00:00', 50, 130, 4, 1, 25),
+		       (3, '2024-01-01', '13:00:00', 10, 10, 0, 0, 0);
+		INSERT INTO daily_weights (date, time, weight) VALUES ('2024-01-01', '07:00:00', 180);
+	`)
+
+	// The food with ID 3 doesn't exist on the home device.
+	cs, r, err := transfer(laptop, home, time.Time{})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	// Importing the same changeset again changes nothing.
+	r, err = ImportChanges(home, cs)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	// Make sure the changes get a later row version.
+	time.Sleep(5 * time.Millisecond)
+	since, err := time.Parse(SyncTimeFormat, cs.Created)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	laptop.MustExec(`UPDATE daily_foods SET number_of_servings = 2 WHERE food_id = 1`)
+	laptop.MustExec(`DELETE FROM daily_foods WHERE food_id = 2`)
+
+	_, r, err = transfer(laptop, home, since)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	var entries []struct {
+		FoodID     int     `db:"food_id"`
+		NumServing float64 `db:"number_of_servings"`
+	}
+	if err := home.Select(&entries, `SELECT food_id, number_of_servings FROM daily_foods`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(entries)
+
+	// Output:
+	// 3 inserted, 0 updated, 0 deleted, 1 skipped, 0 duplicates, 0 conflicts
+	// 0 inserted, 0 updated, 0 deleted, 4 skipped, 0 duplicates, 0 conflicts
+	// 0 inserted, 1 updated, 1 deleted, 0 skipped, 0 duplicates, 0 conflicts
+	// [{1 2}]
+}
+
+func ExampleImportChanges_conflict() {
+	laptop := syncTestDB()
+	defer laptop.Close()
+	home := syncTestDB()
+	defer home.Close()
+
+	laptop.MustExec(`INSERT INTO daily_weights (date, time, weight) VALUES ('2024-01-01', '07:00:00', 180)`)
+	cs, _, err := transfer(laptop, home, time.Time{})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	since, err := time.Parse(SyncTimeFormat, cs.Created)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Both devices change the same entry. The home change is the most
+	// recent one.
+	time.Sleep(5 * time.Millisecond)
+	laptop.MustExec(`UPDATE daily_weights SET weight = 181`)
+	time.Sleep(5 * time.Millisecond)
+	home.MustExec(`UPDATE daily_weights SET weight = 179`)
+
+	_, r, err := transfer(laptop, home, since)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	_, r, err = transfer(home, laptop, since)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	var weight float64
+	if err := laptop.Get(&weight, `SELECT weight FROM daily_weights`); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(weight)
+
+	// Output:
+	// 0 inserted, 0 updated, 0 deleted, 1 skipped, 0 duplicates, 1 conflicts
+	// 0 inserted, 1 updated, 0 deleted, 0 skipped, 0 duplicates, 1 conflicts
+	// 179
+}
+
+func ExampleImportChanges_customFoods() {
+	laptop := syncTestDB()
+	defer laptop.Close()
+	home := syncTestDB()
+	defer home.Close()
+
+	// Foods and meals made on each device have ids of their own, so the
+	// same id is a different food on the other device.
+	laptop.MustExec(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving, source) VALUES
+			(3, 'Protein bar', 60, 'g', '1 bar', 'user'), (4, 'Shake', 300, 'ml', '1 bottle', 'user');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Breakfast');
+		INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, calories, protein, fat, carbs)
+		VALUES (3, 1, '2024-01-01', '08:00:00', 60, 200, 20, 7, 22),
+		       (4, NULL, '2024-01-01', '10:00:00', 300, 150, 25, 3, 6),
+		       (1, 1, '2024-01-01', '08:00:00', 182, 95, 0.5, 0.3, 25);
+	`)
+	home.MustExec(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving, source) VALUES
+			(3, 'Granola', 50, 'g', '1/2 cup', 'user'), (4, 'Protein bar', 60, 'g', '1 bar', 'user');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Lunch'), (2, 'Breakfast');
+	`)
+
+	// The shake doesn't exist at home.
+	_, r, err := transfer(laptop, home, time.Time{})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(r)
+
+	var entries []struct {
+		Food string `db:"food_name"`
+		Meal string `db:"meal_name"`
+	}
+	const query = `
+		SELECT f.food_name, COALESCE(m.meal_name, '') AS meal_name
+		FROM daily_foods df
+		JOIN foods f ON f.food_id = df.food_id
+		LEFT JOIN meals m ON m.meal_id = df.meal_id
+		ORDER BY df.id
+	`
+	if err := home.Select(&entries, query); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(entries)
+
+	// Output:
+	// 2 inserted, 0 updated, 0 deleted, 1 skipped, 0 duplicates, 0 conflicts
+	// [{Protein bar Breakfast} {Apple Breakfast}]
+}
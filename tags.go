@@ -0,0 +1,358 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// tagPrefix marks a tag filter in a search term, e.g. "tag:vegan".
+const tagPrefix = "tag:"
+
+// TagCount is a tag and the number of logged foods and meals with it.
+type TagCount struct {
+	Name  string `db:"name"`
+	Count int    `db:"count"`
+}
+
+// NormalizeTag returns the tag in the form it is stored in.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// validateTag validates a normalized tag. Tags are single words so they
+// can be used as search filters.
+func validateTag(tag string) error {
+	if tag == "" {
+		return errors.New("tag can't be empty")
+	}
+	if strings.ContainsAny(tag, " \t:") {
+		return fmt.Errorf("tag %q can't contain spaces or colons", tag)
+	}
+	return nil
+}
+
+// tagID returns the ID of the tag, adding the tag if it doesn't exist.
+func tagID(tx *sqlx.Tx, tag string) (int, error) {
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES ($1)`, tag); err != nil {
+		return 0, fmt.Errorf("couldn't add tag: %v", err)
+	}
+	var id int
+	if err := tx.Get(&id, `SELECT tag_id FROM tags WHERE name = $1`, tag); err != nil {
+		return 0, fmt.Errorf("couldn't get tag: %v", err)
+	}
+	return id, nil
+}
+
+// setTags adds the tags to, or removes them from, the food or meal with
+// the given ID. table is the table relating tags to foods or meals and
+// idCol its food or meal ID column.
+func setTags(tx *sqlx.Tx, table, idCol string, id int, tags []string, remove bool) error {
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if err := validateTag(tag); err != nil {
+			return err
+		}
+
+		if remove {
+			query := fmt.Sprintf(`
+				DELETE FROM %s
+				WHERE %s = $1 AND tag_id IN (SELECT tag_id FROM tags WHERE name = $2)
+			`, table, idCol)
+			if _, err := tx.Exec(query, id, tag); err != nil {
+				return fmt.Errorf("couldn't remove tag %q: %v", tag, err)
+			}
+			continue
+		}
+
+		tid, err := tagID(tx, tag)
+		if err != nil {
+			return err
+		}
+		query := fmt.Sprintf(`INSERT OR IGNORE INTO %s (%s, tag_id) VALUES ($1, $2)`, table, idCol)
+		if _, err := tx.Exec(query, id, tid); err != nil {
+			return fmt.Errorf("couldn't add tag %q: %v", tag, err)
+		}
+	}
+	return nil
+}
+
+// TagFood adds the tags to a food.
+func TagFood(tx *sqlx.Tx, foodID int, tags ...string) error {
+	return setTags(tx, "food_tags", "food_id", foodID, tags, false)
+}
+
+// UntagFood removes the tags from a food.
+func UntagFood(tx *sqlx.Tx, foodID int, tags ...string) error {
+	return setTags(tx, "food_tags", "food_id", foodID, tags, true)
+}
+
+// TagMeal adds the tags to a meal.
+func TagMeal(tx *sqlx.Tx, mealID int, tags ...string) error {
+	return setTags(tx, "meal_tags", "meal_id", mealID, tags, false)
+}
+
+// UntagMeal removes the tags from a meal.
+func UntagMeal(tx *sqlx.Tx, mealID int, tags ...string) error {
+	return setTags(tx, "meal_tags", "meal_id", mealID, tags, true)
+}
+
+// FoodTags returns the tags of a food in alphabetical order.
+func FoodTags(q sqlx.Queryer, foodID int) ([]string, error) {
+	const query = `
+		SELECT t.name FROM tags t
+		INNER JOIN food_tags ft ON ft.tag_id = t.tag_id
+		WHERE ft.food_id = $1
+		ORDER BY t.name
+	`
+	var tags []string
+	if err := sqlx.Select(q, &tags, query, foodID); err != nil {
+		return nil, fmt.Errorf("couldn't get food tags: %v", err)
+	}
+	return tags, nil
+}
+
+// MealTags returns the tags of a meal in alphabetical order.
+func MealTags(q sqlx.Queryer, mealID int) ([]string, error) {
+	const query = `
+		SELECT t.name FROM tags t
+		INNER JOIN meal_tags mt ON mt.tag_id = t.tag_id
+		WHERE mt.meal_id = $1
+		ORDER BY t.name
+	`
+	var tags []string
+	if err := sqlx.Select(q, &tags, query, mealID); err != nil {
+		return nil, fmt.Errorf("couldn't get meal tags: %v", err)
+	}
+	return tags, nil
+}
+
+// EditFoodTags lets the user select foods and adds the tags to them, or
+// removes the tags from them.
+func EditFoodTags(db *sqlx.DB, tags []string, remove bool) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n := 0
+	for {
+		food, err := selectFood(db)
+		if err != nil {
+			if errors.Is(err, ErrDone) {
+				break
+			}
+			return err
+		}
+		if err := setTags(tx, "food_tags", "food_id", food.ID, tags, remove); err != nil {
+			return err
+		}
+		current, err := FoodTags(tx, food.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Tags of %q: %s\n", food.Name, strings.Join(current, ", "))
+		n++
+	}
+
+	if n == 0 {
+		fmt.Println("No food selected.")
+		return nil
+	}
+	return tx.Commit()
+}
+
+// EditMealTags lets the user select a meal and adds the tags to it, or
+// removes the tags from it.
+func EditMealTags(db *sqlx.DB, tags []string, remove bool) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	meal, err := selectMeal(db)
+	if err != nil {
+		return err
+	}
+	if err := setTags(tx, "meal_tags", "meal_id", meal.ID, tags, remove); err != nil {
+		return err
+	}
+	current, err := MealTags(tx, meal.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Tags of %q: %s\n", meal.Name, strings.Join(current, ", "))
+	return tx.Commit()
+}
+
+// parseTagFilters splits the "tag:" filters from a search term. It
+// returns the rest of the term and the normalized tags.
+func parseTagFilters(term string) (string, []string) {
+	var words, tags []string
+	for _, w := range strings.Fields(term) {
+		if strings.HasPrefix(strings.ToLower(w), tagPrefix) {
+			if tag := NormalizeTag(w[len(tagPrefix):]); tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), tags
+}
+
+// searchQuery returns the query, and its arguments, that full-text
+// searches table through its fts table for the search term. Any "tag:"
+// filters in the term keep only the rows with every one of the tags,
+// which are related to the rows by tagTable. A term of only tag filters
+// lists the rows with the tags by name.
+func searchQuery(table, fts, idCol, nameCol, tagTable, term string) (string, []interface{}) {
+	return rankedSearchQuery(table, fts, idCol, nameCol, tagTable, term, searchOptions{})
+}
+
+// searchOptions changes how rankedSearchQuery ranks and pages results.
+type searchOptions struct {
+	// logTable is the table rows are logged in. When set, rows logged
+	// often and recently in it, as of now, rank higher.
+	logTable string
+	now      time.Time
+	// offset is the number of results to skip.
+	offset int
+	// cheaper ranks foods that cost less per calorie higher. It only
+	// applies to the foods table.
+	cheaper bool
+}
+
+// rankedSearchQuery is like searchQuery, but ranks and pages the results
+// by the options. With a log table, a term of only tag filters lists
+// the most popular rows first.
+func rankedSearchQuery(table, fts, idCol, nameCol, tagTable, term string, o searchOptions) (string, []interface{}) {
+	term, tags := parseTagFilters(term)
+
+	var b strings.Builder
+	var args []interface{}
+	if term == "" && len(tags) > 0 {
+		fmt.Fprintf(&b, `
+			SELECT r.* FROM %s r`, table)
+		args = append(args, SearchLimit)
+	} else {
+		fmt.Fprintf(&b, `
+			SELECT r.* FROM %s r
+			INNER JOIN %s s ON s.%s = r.%s`, table, fts, idCol, idCol)
+		args = append(args, term, SearchLimit)
+	}
+
+	var boost string
+	if o.logTable != "" {
+		var join string
+		join, boost, args = popularityClauses(o.logTable, idCol, o.now, args)
+		b.WriteString(join)
+	}
+	if o.cheaper {
+		join, penalty := cheapClauses(idCol)
+		b.WriteString(join)
+		if boost == "" {
+			boost = "0"
+		}
+		boost = fmt.Sprintf("(%s - %s)", boost, penalty)
+	}
+
+	if term == "" && len(tags) > 0 {
+		b.WriteString(`
+			WHERE 1 = 1`)
+	} else {
+		fmt.Fprintf(&b, `
+			WHERE %s MATCH $1`, fts)
+	}
+
+	for _, tag := range tags {
+		args = append(args, tag)
+		fmt.Fprintf(&b, `
+			AND r.%s IN (
+				SELECT tt.%s FROM %s tt
+				INNER JOIN tags t ON t.tag_id = tt.tag_id
+				WHERE t.name = $%d
+			)`, idCol, idCol, tagTable, len(args))
+	}
+
+	switch {
+	case term == "" && len(tags) > 0 && boost != "":
+		fmt.Fprintf(&b, `
+			ORDER BY %s DESC, r.%s
+			LIMIT $1 OFFSET %d`, boost, nameCol, o.offset)
+	case term == "" && len(tags) > 0:
+		fmt.Fprintf(&b, `
+			ORDER BY r.%s
+			LIMIT $1 OFFSET %d`, nameCol, o.offset)
+	case boost != "":
+		fmt.Fprintf(&b, `
+			ORDER BY bm25(%s) - %s
+			LIMIT $2 OFFSET %d`, fts, boost, o.offset)
+	default:
+		fmt.Fprintf(&b, `
+			ORDER BY bm25(%s)
+			LIMIT $2 OFFSET %d`, fts, o.offset)
+	}
+	return b.String(), args
+}
+
+// TagCloud returns every tag and the number of foods and meals with it
+// logged from start up to, but not including, end, most used first.
+func TagCloud(q sqlx.Queryer, start, end time.Time) ([]TagCount, error) {
+	const query = `
+		SELECT t.name, COUNT(x.tag_id) AS count
+		FROM tags t
+		LEFT JOIN (
+			SELECT ft.tag_id FROM daily_foods df
+			INNER JOIN food_tags ft ON ft.food_id = df.food_id
+			WHERE df.date >= $1 AND df.date < $2
+			UNION ALL
+			SELECT mt.tag_id FROM daily_meals dm
+			INNER JOIN meal_tags mt ON mt.meal_id = dm.meal_id
+			WHERE dm.date >= $1 AND dm.date < $2
+		) x ON x.tag_id = t.tag_id
+		GROUP BY t.tag_id
+		ORDER BY count DESC, t.name
+	`
+	var tags []TagCount
+	if err := sqlx.Select(q, &tags, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get tag cloud: %v", err)
+	}
+	return tags, nil
+}
+
+// ListTags prints every tag and how many times foods and meals with the
+// tag have been logged.
+func ListTags(db *sqlx.DB) error {
+	tags, err := TagCloud(db, time.Time{}, LogNow().AddDate(1, 0, 0))
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		fmt.Println("No tags.")
+		return nil
+	}
+	for _, t := range tags {
+		fmt.Printf("%-20s %d\n", t.Name, t.Count)
+	}
+	return nil
+}
+
+// printTagCloud prints the tags that were logged and how often.
+func printTagCloud(tags []TagCount) {
+	var s []string
+	for _, t := range tags {
+		if t.Count > 0 {
+			s = append(s, fmt.Sprintf("%s (%d)", t.Name, t.Count))
+		}
+	}
+	if len(s) == 0 {
+		return
+	}
+	fmt.Println("Tags: " + strings.Join(s, "  "))
+}
@@ -0,0 +1,73 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleTagFood() {
+	db := dbtest.MustNewEmpty(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(1, 'Tofu', 100, 'g', ''), (2, 'Chicken Breast', 100, 'g', ''), (3, 'Rice', 158, 'g', '1 cup');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Stir fry');
+		INSERT INTO daily_foods (food_id, date, time, serving_size, calories, protein, fat, carbs) VALUES
+			(1, '2024-01-01', '12:00:00', 100, 76, 8, 5, 2),
+			(2, '2024-01-01', '18:00:00', 100, 165, 31, 4, 0),
+			(2, '2024-01-02', '12:00:00', 100, 165, 31, 4, 0),
+			(3, '2024-01-02', '12:00:00', 158, 205, 4, 0, 45);
+		INSERT INTO daily_meals (meal_id, date, time) VALUES (1, '2024-01-02', '18:00:00');
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	for _, err := range []error{
+		TagFood(tx, 1, "Vegan", "high-protein"),
+		TagFood(tx, 2, "high-protein", "prep-friendly"),
+		TagFood(tx, 3, "vegan"),
+		UntagFood(tx, 2, "prep-friendly"),
+		TagMeal(tx, 1, "vegan"),
+	} {
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
+	fmt.Println(TagFood(tx, 3, "meal prep"))
+	if err := tx.Commit(); err != nil {
+		log.Println(err)
+		return
+	}
+
+	tags, err := FoodTags(db, 1)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(tags)
+
+	// Filter foods by tag.
+	query, args := searchQuery("foods", "foods_fts", "food_id", "food_name", "food_tags", "tag:vegan tag:high-protein")
+	var names []string
+	if err := db.Select(&names, `SELECT food_name FROM (`+query+`)`, args...); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(names)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cloud, err := TagCloud(db, start, start.AddDate(0, 0, 7))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	printTagCloud(cloud)
+
+	// Output:
+	// tag "meal prep" can't contain spaces or colons
+	// [high-protein vegan]
+	// [Tofu]
+	// Tags: high-protein (3)  vegan (3)
+}
@@ -0,0 +1,135 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// maxCutWeeklyChangePct is the fastest safe rate of weight loss, as a
+	// fraction of bodyweight per week.
+	maxCutWeeklyChangePct = 0.01
+	// maxBulkWeeklyChangePct is the fastest safe rate of weight gain, as
+	// a fraction of bodyweight per week.
+	maxBulkWeeklyChangePct = 0.005
+)
+
+// maxWeeklyChange returns the largest safe weekly weight change in lbs
+// for the phase at the given weight.
+func maxWeeklyChange(phase string, weight float64) float64 {
+	if phase == "bulk" {
+		return weight * maxBulkWeeklyChangePct
+	}
+	return weight * maxCutWeeklyChangePct
+}
+
+// validateTargetRate validates that the weekly weight change needed to
+// reach the goal weight is within the safe rate for the phase.
+func validateTargetRate(phase string, weight, weeklyChange float64) error {
+	limit := maxWeeklyChange(phase, weight)
+	if math.Abs(weeklyChange) <= limit {
+		return nil
+	}
+
+	verb := "Losing"
+	if phase == "bulk" {
+		verb = "Gaining"
+	}
+	return fmt.Errorf("%s %.2f lbs per week is faster than the safe limit of %.2f lbs per week", verb, math.Abs(weeklyChange), limit)
+}
+
+// earliestTargetDate returns the earliest date the goal weight can be
+// reached from the given weight at the safe rate for the phase.
+func earliestTargetDate(phase string, weight, goal float64, from time.Time) time.Time {
+	weeks := math.Abs(goal-weight) / maxWeeklyChange(phase, weight)
+	return dateOf(from).AddDate(0, 0, int(math.Ceil(weeks*7)))
+}
+
+// targetCalories returns the daily calories for the phase's weekly
+// weight change.
+func targetCalories(u *UserInfo) float64 {
+	return u.TDEE + u.Phase.WeeklyChange*calsPerPound/7
+}
+
+// handleTargetDiet sets UserInfo struct fields for a diet that reaches
+// a goal weight by a target date. The weekly change is calculated from
+// the two, and the user is asked for another date until the change is
+// within the safe rate.
+func handleTargetDiet(u *UserInfo) {
+	// Get diet start date.
+	u.Phase.StartDate = getStartDate(u)
+
+	// Initialize last checked week.
+	u.Phase.LastCheckedWeek = u.Phase.StartDate
+
+	// Get diet goal weight.
+	u.Phase.GoalWeight, u.Phase.GoalWeightEnd = getGoalWeight(u)
+
+	for {
+		// Get the target date as the diet end date.
+		setEndDate(u)
+
+		u.Phase.WeeklyChange = calculateWeeklyChange(u.Weight, u.Phase.GoalWeight, u.Phase.Duration)
+		if err := validateTargetRate(u.Phase.Name, u.Weight, u.Phase.WeeklyChange); err != nil {
+			fmt.Printf("%v. The earliest safe date is %s. Please try again.\n", err,
+				earliestTargetDate(u.Phase.Name, u.Weight, u.Phase.GoalWeight, u.Phase.StartDate).Format(dateFormat))
+			continue
+		}
+
+		break
+	}
+
+	u.Phase.GoalCalories = targetCalories(u)
+	u.Phase.TargetMode = true
+}
+
+// checkTarget recalculates the plan of a target date phase once a week
+// from the user's trend weight. If the goal can still be reached safely
+// by the end date, the weekly change and calorie goal are updated to
+// get there. Otherwise, the user is warned and told the earliest date
+// the goal can be reached.
+//
+// Assumptions:
+// * The phase's diet breaks have been loaded.
+// * Entries are in date order.
+func checkTarget(tx *sqlx.Tx, u *UserInfo, entries []Entry, now time.Time) error {
+	if !u.Phase.TargetMode || u.Phase.ActiveBreak() != nil || len(entries) == 0 {
+		return nil
+	}
+
+	today := dateOf(now)
+	week := int(today.Sub(u.Phase.StartDate).Hours() / 24 / 7)
+	if week <= u.Phase.TargetWeek {
+		return nil
+	}
+	u.Phase.TargetWeek = week
+
+	trend := TrendWeights(entries)
+	current := trend[len(trend)-1]
+	weeksLeft := u.Phase.EndDate.Sub(today).Hours() / 24 / 7
+
+	switch {
+	case u.Phase.ReachedGoal(current):
+		fmt.Printf("Your trend weight of %s lbs has reached your goal weight of %s lbs.\n", FormatWeight(current), FormatGoalWeight(u.Phase))
+	case weeksLeft < 1:
+		// Too close to the end date to change the plan.
+	default:
+		change := calculateWeeklyChange(current, u.Phase.GoalWeight, weeksLeft)
+		if err := validateTargetRate(u.Phase.Name, current, change); err != nil {
+			fmt.Printf("Your plan to reach %.1f lbs by %s is off track. %v. The earliest safe date is %s.\n",
+				u.Phase.GoalWeight, u.Phase.EndDate.Format(dateFormat), err,
+				earliestTargetDate(u.Phase.Name, current, u.Phase.GoalWeight, today).Format(dateFormat))
+			break
+		}
+
+		u.Phase.WeeklyChange = change
+		u.Phase.GoalCalories = targetCalories(u)
+		fmt.Printf("To reach %.1f lbs by %s, aim for %+.2f lbs per week on %.0f calories a day.\n",
+			u.Phase.GoalWeight, u.Phase.EndDate.Format(dateFormat), change, u.Phase.GoalCalories)
+	}
+
+	return updatePhaseInfo(tx, u)
+}
@@ -0,0 +1,89 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleValidateTargetRate() {
+	// 180 lbs to 170 lbs in 12 weeks is safe, in 4 weeks it isn't.
+	fmt.Println(validateTargetRate("cut", 180, calculateWeeklyChange(180, 170, 12)))
+	fmt.Println(validateTargetRate("cut", 180, calculateWeeklyChange(180, 170, 4)))
+	fmt.Println(validateTargetRate("bulk", 180, calculateWeeklyChange(180, 190, 8)))
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fmt.Println(earliestTargetDate("cut", 180, 170, start).Format(dateFormat))
+
+	// Output:
+	// <nil>
+	// Losing 2.50 lbs per week is faster than the safe limit of 1.80 lbs per week
+	// Gaining 1.25 lbs per week is faster than the safe limit of 0.90 lbs per week
+	// 2024-02-09
+}
+
+func ExampleCheckTarget() {
+	db := dbtest.MustNew()
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	defer tx.Rollback()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{UserID: 1, Weight: 180, TDEE: 2500}
+	u.Phase = PhaseInfo{
+		UserID:          1,
+		Name:            "cut",
+		StartWeight:     180,
+		GoalWeight:      170,
+		WeeklyChange:    -1,
+		StartDate:       start,
+		EndDate:         start.AddDate(0, 0, 70),
+		LastCheckedWeek: start,
+		Duration:        10,
+		Status:          "active",
+		TargetMode:      true,
+	}
+	u.Phase.GoalCalories = targetCalories(&u)
+	if err := insertOrUpdatePhaseInfo(tx, &u); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(u.Phase.GoalCalories)
+
+	// Two weeks without losing any weight.
+	var entries []Entry
+	for i := 0; i < 14; i++ {
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: 180})
+	}
+	if err := checkTarget(tx, &u, entries, start.AddDate(0, 0, 14)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Checked once a week.
+	if err := checkTarget(tx, &u, entries, start.AddDate(0, 0, 15)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Six weeks without losing weight puts the goal out of reach.
+	for i := 14; i < 42; i++ {
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: 180})
+	}
+	if err := checkTarget(tx, &u, entries, start.AddDate(0, 0, 42)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	// Output:
+	// 2000
+	// To reach 170.0 lbs by 2024-03-11, aim for -1.25 lbs per week on 1875 calories a day.
+	// Your plan to reach 170.0 lbs by 2024-03-11 is off track. Losing 2.50 lbs per week is faster than the safe limit of 1.80 lbs per week. The earliest safe date is 2024-03-22.
+}
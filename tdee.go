@@ -0,0 +1,132 @@
+package bite
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// tdeeMinDays is the fewest days of a week with calories logged for its
+// TDEE to be back-calculated.
+const tdeeMinDays = 4
+
+// tdeeBarLength is the length of the bar of the highest TDEE in the
+// TDEE chart.
+const tdeeBarLength = 20
+
+// TDEEWeek is the TDEE back-calculated from a week of the diet phase.
+type TDEEWeek struct {
+	Start    time.Time
+	Days     int     // Days with calories logged.
+	Calories float64 // Average calories eaten a day.
+	Change   float64 // Weekly rate of weight change (lbs).
+	TDEE     float64 // Calories eaten less the calories of the change.
+}
+
+// AdaptiveTDEE back-calculates the TDEE of each week of the diet phase,
+// counted in 7 day steps from the start date, from the average calories
+// eaten and the rate of weight change. The rate is fit by least squares
+// to the weights of the week and the week before it, which evens out
+// day to day swings without the lag of the trend weight. A TDEE falling
+// over a long cut is metabolic adaptation. Weeks that aren't over or
+// have fewer than tdeeMinDays days logged are left out.
+func AdaptiveTDEE(u *UserInfo, entries []Entry) []TDEEWeek {
+	if len(entries) == 0 {
+		return nil
+	}
+	last := dateOf(entries[len(entries)-1].Date)
+
+	var weeks []TDEEWeek
+	for start := u.Phase.StartDate; !start.AddDate(0, 0, 6).After(last); start = start.AddDate(0, 0, 7) {
+		end := start.AddDate(0, 0, 7)
+		w := TDEEWeek{Start: start}
+
+		var xs, ys []float64
+		for _, e := range entries {
+			if e.Date.Before(start.AddDate(0, 0, -7)) {
+				continue
+			}
+			if !e.Date.Before(end) {
+				break
+			}
+			if e.UserWeight != 0 {
+				xs = append(xs, daysBetween(start, e.Date))
+				ys = append(ys, e.UserWeight)
+			}
+			if e.Calories > 0 && !e.Date.Before(start) {
+				w.Calories += e.Calories
+				w.Days++
+			}
+		}
+		if w.Days < tdeeMinDays || len(xs) < 2 || xs[len(xs)-1] == xs[0] {
+			continue
+		}
+		slope, _, _, _ := leastSquares(xs, ys)
+		w.Calories /= float64(w.Days)
+		w.Change = slope * 7
+		w.TDEE = w.Calories - slope*calsPerPound
+		weeks = append(weeks, w)
+	}
+	return weeks
+}
+
+// TDEEChange returns the change from the TDEE of the first week to the
+// last. It returns false if there are fewer than two weeks.
+func TDEEChange(weeks []TDEEWeek) (float64, bool) {
+	if len(weeks) < 2 {
+		return 0, false
+	}
+	return weeks[len(weeks)-1].TDEE - weeks[0].TDEE, true
+}
+
+// PrintTDEE prints the back-calculated TDEE of each week of the diet
+// phase as a bar chart, followed by the change since the first week.
+func PrintTDEE(u *UserInfo, entries []Entry) {
+	weeks := AdaptiveTDEE(u, entries)
+	if len(weeks) == 0 {
+		fmt.Printf("Log calories and weight on at least %d days of a week to estimate its TDEE.\n", tdeeMinDays)
+		return
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, w := range weeks {
+		lo = math.Min(lo, w.TDEE)
+		hi = math.Max(hi, w.TDEE)
+	}
+	fmt.Printf("%-12s %-10s %-8s %-8s\n", "Week of", "Calories", "Change", "TDEE")
+	for _, w := range weeks {
+		// Bars are scaled between the lowest and highest TDEE, so that
+		// small changes show.
+		n := tdeeBarLength
+		if hi > lo {
+			n = 1 + int((w.TDEE-lo)/(hi-lo)*(tdeeBarLength-1))
+		}
+		change := localizeNumber(fmt.Sprintf("%+.*f", WeightDecimals, w.Change))
+		fmt.Printf("%-12s %-10s %-8s %-8s %s\n", w.Start.Format(dateFormat), formatNumber(w.Calories, 0),
+			change, formatNumber(w.TDEE, 0), strings.Repeat(fullBlock, n))
+	}
+	if line := tdeeChangeLine(weeks); line != "" {
+		fmt.Println()
+		fmt.Println(line)
+	}
+}
+
+// tdeeChangeLine returns the current TDEE against the initial one, or
+// an empty string if there are fewer than two weeks.
+func tdeeChangeLine(weeks []TDEEWeek) string {
+	d, ok := TDEEChange(weeks)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("TDEE: %s now, %s in the first week (%s)", formatNumber(weeks[len(weeks)-1].TDEE, 0),
+		formatNumber(weeks[0].TDEE, 0), localizeNumber(fmt.Sprintf("%+.0f", d)))
+}
+
+// printTDEEChange prints the current TDEE against the initial one of the
+// phase.
+func printTDEEChange(u *UserInfo, entries []Entry) {
+	if line := tdeeChangeLine(AdaptiveTDEE(u, entries)); line != "" {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,37 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+)
+
+func ExampleAdaptiveTDEE() {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := UserInfo{}
+	u.Phase.StartDate = start
+
+	// Eating 2200 calories a day, the loss slows from a pound a week to
+	// half a pound.
+	var entries []Entry
+	w := 190.0
+	for i := 0; i < 38; i++ {
+		entries = append(entries, Entry{Date: start.AddDate(0, 0, i), UserWeight: w, Calories: 2200})
+		if i < 14 {
+			w -= 1.0 / 7
+		} else {
+			w -= 0.5 / 7
+		}
+	}
+
+	for _, wk := range AdaptiveTDEE(&u, entries) {
+		fmt.Println(wk.Start.Format(dateFormat), wk.Days, formatNumber(wk.Change, 2), formatNumber(wk.TDEE, 0))
+	}
+	fmt.Println(tdeeChangeLine(AdaptiveTDEE(&u, entries)))
+	// Output:
+	// 2024-01-01 7 -1.00 2700
+	// 2024-01-08 7 -1.00 2700
+	// 2024-01-15 7 -0.78 2588
+	// 2024-01-22 7 -0.50 2450
+	// 2024-01-29 7 -0.50 2450
+	// TDEE: 2450 now, 2700 in the first week (-250)
+}
@@ -0,0 +1,121 @@
+package bite
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// TemplatesDir is the directory of the user's templates. A template
+// named after a report, such as "day.tmpl", replaces how the report is
+// printed. Reports use their default layout when it is empty.
+var TemplatesDir = ""
+
+// dayTemplate is the default layout of the day summary.
+const dayTemplate = `{{progress (tr "Protein") .Protein .Goal.Protein "g"}}
+{{progress (tr "Fat") .Fat .Goal.Fat "g"}}
+{{progress (tr "Carbs") .Carbs .Goal.Carbs "g"}}
+{{progress (tr "Calories") .Calories .Goal.Calories ""}}
+{{printf "%-9s" (tr "Macros:")}} {{split .Protein .Carbs .Fat}}
+
+{{printf (tr "%s calories remaining.") (num .Remaining 2)}}
+{{printf (tr "Eaten $%s worth of food today.") (num .Price 2)}}
+{{with .CarbSplit}}{{progress (tr "Fiber") .Fiber .FiberMin "g"}}
+{{progress (tr "Sugar") .Sugar .SugarMax "g"}}
+{{if .LowFiber}}{{printf (tr "Fiber is %sg short of the %sg minimum.") (num (sub .FiberMin .Fiber) 0) (num .FiberMin 0)}}
+{{end}}{{if .HighSugar}}{{printf (tr "Sugar is %sg over the %sg maximum.") (num (sub .Sugar .SugarMax) 0) (num .SugarMax 0)}}
+{{end}}{{end}}{{with .Electrolytes}}{{printf (tr "Sodium: %s mg. Potassium: %s mg.") (num .Sodium 0) (num .Potassium 0)}}
+{{end}}{{with .GlycemicLoad}}{{printf (tr "Glycemic load: %s (%s), from %s%% of the day's carbs.") (num .Load 0) (tr .Level) (num .Coverage 0)}}
+{{end}}{{with .Caffeine}}{{printf (tr "Caffeine: %s mg.") (num .Total 0)}}
+{{if .Late}}{{printf (tr "Warning: %s mg of caffeine after %s can disturb sleep and hide weight trends.") (num .Late 0) .Cutoff}}
+{{end}}{{end}}{{with .Budget}}{{if .Over}}{{printf (tr "Spent $%s on food this week, $%s over the $%s weekly budget.") (num .Spent 2) (num (sub .Spent .Limit) 2) (num .Limit 2)}}
+{{else}}{{printf (tr "Spent $%s of the $%s weekly food budget, $%s left.") (num .Spent 2) (num .Limit 2) (num .Left 2)}}
+{{end}}{{if .Tight}}{{tr "The budget is tight, so cheaper foods are suggested first."}}
+{{end}}{{end}}`
+
+// templateFuncs are the functions templates can call besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	// bar renders a progress bar of current towards goal.
+	"bar": renderProgressBar,
+	// percent returns current as a percentage of goal.
+	"percent": func(current, goal float64) float64 {
+		return current * 100 / goal
+	},
+	// progress formats a line of progress towards a goal, e.g.
+	// "Protein:  [█████░░░░░]  50% (75g / 150g)".
+	"progress": func(name string, current, goal float64, unit string) string {
+		return fmt.Sprintf("%-9s %s %3.0f%% (%.0f%s / %.0f%s)", name+":",
+			renderProgressBar(current, goal), current*100/goal, current, unit, goal, unit)
+	},
+	// sub returns a minus b.
+	"sub": func(a, b float64) float64 {
+		return a - b
+	},
+	"macro":  FormatMacro,
+	"weight": FormatWeight,
+	"split":  FormatMacroSplit,
+	"date":   FormatDate,
+	// num formats a number with the given decimals.
+	"num": formatNumber,
+	// tr translates a message to Locale.
+	"tr": tr,
+}
+
+// DayReport is the data of the day summary given to its template.
+type DayReport struct {
+	Date  time.Time
+	Foods []DailyFood // The day's food log, in the order it was eaten.
+	Nutrition
+	Goal Nutrition // The day's calorie and macro goals.
+	// CarbSplit is the day's fiber and sugar, or nil if the day's foods
+	// have no fiber or sugar data.
+	CarbSplit *CarbSplit
+	// Electrolytes is the day's sodium and potassium, or nil if the
+	// day's foods have no sodium or potassium data.
+	Electrolytes *Electrolytes
+	// GlycemicLoad is the day's glycemic load, or nil if none of the
+	// day's foods has a glycemic index.
+	GlycemicLoad *GlycemicLoad
+	// Caffeine is the day's caffeine, or nil if the day's foods had
+	// none.
+	Caffeine *DayCaffeine
+	// Budget is the week's spend on food, or nil if there is no weekly
+	// budget.
+	Budget *Budget
+}
+
+// Remaining returns the calories left to eat to reach the day's goal.
+func (r *DayReport) Remaining() float64 {
+	return r.Goal.Calories - r.Calories
+}
+
+// render writes the report with the user's template named after it,
+// or with the default layout def when the user has none.
+func render(w io.Writer, name, def string, data interface{}) error {
+	text, src := def, name
+	if TemplatesDir != "" {
+		path := filepath.Join(TemplatesDir, name+".tmpl")
+		b, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			text, src = string(b), path
+		case !errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("couldn't read template: %v", err)
+		}
+	}
+
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("couldn't parse template %s: %v", src, err)
+	}
+	if err := t.Execute(w, data); err != nil {
+		return fmt.Errorf("couldn't render template %s: %v", src, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package bite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func ExampleDayReport() {
+	r := &DayReport{
+		Date:      time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		Nutrition: Nutrition{Calories: 1500, Protein: 75, Fat: 50, Carbs: 180, Price: 6.5},
+		Goal:      Nutrition{Calories: 2000, Protein: 150, Fat: 70, Carbs: 200},
+	}
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		fmt.Println(err)
+	}
+
+	// A template in TemplatesDir replaces the default layout.
+	dir, err := os.MkdirTemp("", "templates")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	const day = `{{date .Date}}: {{printf "%.0f" .Remaining}} calories left, {{macro .Protein}}g protein`
+	if err := os.WriteFile(filepath.Join(dir, "day.tmpl"), []byte(day+"\n"), 0644); err != nil {
+		fmt.Println(err)
+		return
+	}
+	TemplatesDir = dir
+	defer func() { TemplatesDir = "" }()
+	fmt.Println()
+	if err := render(os.Stdout, "day", dayTemplate, r); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// Protein:  [█████▒▒▒▒▒]  50% (75g / 150g)
+	// Fat:      [███████▒▒▒]  71% (50g / 70g)
+	// Carbs:    [█████████▒]  90% (180g / 200g)
+	// Calories: [███████▒▒▒]  75% (1500 / 2000)
+	// Macros:   P 20% / C 49% / F 31% of calories
+	//
+	// 500.00 calories remaining.
+	// Eaten $6.50 worth of food today.
+	//
+	// 2024-03-18: 500 calories left, 75.0g protein
+}
@@ -0,0 +1,195 @@
+package bite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TrainingEntry is a session from the training log.
+type TrainingEntry struct {
+	ID       int       `db:"id"`
+	Date     time.Time `db:"date"`
+	Type     string    `db:"type"`
+	Duration float64   `db:"duration"` // Minutes.
+	Calories float64   `db:"calories"` // Estimated calories burned.
+}
+
+// TrainingVolume is the training done in one week of a diet phase.
+type TrainingVolume struct {
+	Start    time.Time
+	Sessions int
+	Minutes  float64
+	Calories float64
+}
+
+// AddTrainingEntry inserts a session into the training log.
+func AddTrainingEntry(tx *sqlx.Tx, e *TrainingEntry) error {
+	const query = `
+		INSERT INTO daily_training (date, time, type, duration, calories)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	now := LogNow()
+	res, err := tx.Exec(query, e.Date.Format(dateFormat), now.Format("15:04:05"),
+		e.Type, e.Duration, e.Calories)
+	if err != nil {
+		return fmt.Errorf("couldn't insert training entry: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("couldn't get training entry id: %v", err)
+	}
+	e.ID = int(id)
+	return nil
+}
+
+// TrainingEntries returns the sessions logged from start up to, but not
+// including, end. Ordered by date.
+func TrainingEntries(db *sqlx.DB, start, end time.Time) ([]TrainingEntry, error) {
+	const query = `
+		SELECT id, date, type, duration, calories
+		FROM daily_training
+		WHERE date >= $1 AND date < $2
+		ORDER BY date, time, id
+	`
+	var entries []TrainingEntry
+	if err := db.Select(&entries, query, start.Format(dateFormat), end.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get training entries: %v", err)
+	}
+	return entries, nil
+}
+
+// ShowTrainingLog prints every session in the training log.
+func ShowTrainingLog(db *sqlx.DB) error {
+	const query = `
+		SELECT id, date, type, duration, calories
+		FROM daily_training
+		ORDER BY date, time, id
+	`
+	var entries []TrainingEntry
+	if err := db.Select(&entries, query); err != nil {
+		return fmt.Errorf("couldn't get training entries: %v", err)
+	}
+	for _, e := range entries {
+		fmt.Printf("%s %-12s %6.0f min %7.0f cal\n", e.Date.Format(dateFormat), e.Type, e.Duration, e.Calories)
+	}
+	return nil
+}
+
+// trainedOn reports whether a session was logged on the date.
+func trainedOn(tx *sqlx.Tx, date time.Time) (bool, error) {
+	const query = `SELECT COUNT(*) FROM daily_training WHERE date = $1`
+	var n int
+	if err := tx.Get(&n, query, date.Format(dateFormat)); err != nil {
+		return false, fmt.Errorf("couldn't look up training entries: %v", err)
+	}
+	return n > 0, nil
+}
+
+// MarkTrainingDays sets Training on the entries of days with a logged
+// session.
+func MarkTrainingDays(q sqlx.Queryer, entries *[]Entry) error {
+	var dates []string
+	const query = `SELECT DISTINCT CAST(date AS TEXT) FROM daily_training`
+	if err := sqlx.Select(q, &dates, query); err != nil {
+		return fmt.Errorf("couldn't get training days: %v", err)
+	}
+
+	days := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		days[d] = true
+	}
+	for i := range *entries {
+		e := &(*entries)[i]
+		e.Training = days[e.Date.Format(dateFormat)]
+	}
+	return nil
+}
+
+// DayGoalCalories returns the calorie goal for a training or rest day.
+// Training days get the phase's extra training calories, which are
+// taken from the rest days so the weekly intake stays the same.
+func DayGoalCalories(u *UserInfo, training bool) float64 {
+	extra := u.Phase.TrainingCalories
+	days := u.Phase.TrainingDays
+	if extra == 0 || days <= 0 || days >= 7 {
+		return u.Phase.GoalCalories
+	}
+
+	if training {
+		return u.Phase.GoalCalories + extra
+	}
+	return u.Phase.GoalCalories - extra*float64(days)/float64(7-days)
+}
+
+// SetTrainingCalories sets the extra calories eaten on training days of
+// the active diet phase, given the number of training days per week.
+// Zero extra calories gives every day the same goal.
+func SetTrainingCalories(db *sqlx.DB, u *UserInfo, extra float64, days int) error {
+	if extra != 0 && (days < 1 || days > 6) {
+		return fmt.Errorf("training days must be between 1 and 6, got %d", days)
+	}
+	if u.Phase.Status != "active" {
+		return fmt.Errorf("there is no active diet phase")
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	u.Phase.TrainingCalories = extra
+	u.Phase.TrainingDays = days
+	if err := updatePhaseInfo(tx, u); err != nil {
+		return fmt.Errorf("couldn't save training calories: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// WeeklyTrainingVolume totals the sessions for each week of the diet
+// phase up to the given date. Weeks are counted in 7 day steps from the
+// phase start date.
+func WeeklyTrainingVolume(u *UserInfo, entries []TrainingEntry, now time.Time) []TrainingVolume {
+	var weeks []TrainingVolume
+	start := u.Phase.StartDate
+	end := u.Phase.EndDate
+	for weekStart := start; !weekStart.After(now) && !weekStart.After(end); weekStart = weekStart.AddDate(0, 0, 7) {
+		weekEnd := weekStart.AddDate(0, 0, 7)
+		v := TrainingVolume{Start: weekStart}
+		for _, e := range entries {
+			if e.Date.Before(weekStart) || !e.Date.Before(weekEnd) {
+				continue
+			}
+			v.Sessions++
+			v.Minutes += e.Duration
+			v.Calories += e.Calories
+		}
+		weeks = append(weeks, v)
+	}
+	return weeks
+}
+
+// TrainingSummary prints the weekly training volume of the active diet
+// phase.
+func TrainingSummary(db *sqlx.DB, u *UserInfo) error {
+	now := LogNow()
+	entries, err := TrainingEntries(db, u.Phase.StartDate, now.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println(paint(colorUnderline, "Training Volume"))
+	if len(entries) == 0 {
+		fmt.Println("No training logged for this diet phase.")
+		return nil
+	}
+	fmt.Printf("%-12s %-10s %-10s %-10s\n", "Week of", "Sessions", "Minutes", "Calories")
+	for _, w := range WeeklyTrainingVolume(u, entries, now) {
+		fmt.Printf("%-12s %-10d %-10.0f %-10.0f\n", w.Start.Format(dateFormat), w.Sessions, w.Minutes, w.Calories)
+	}
+	return nil
+}
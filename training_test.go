@@ -0,0 +1,67 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleDayGoalCalories() {
+	u := UserInfo{}
+	u.Phase.Name = "cut"
+	u.Phase.GoalCalories = 2000
+	fmt.Println(DayGoalCalories(&u, true), DayGoalCalories(&u, false))
+
+	// Training 3 days a week with 400 extra calories keeps the weekly
+	// intake at 14000 calories.
+	u.Phase.TrainingCalories = 400
+	u.Phase.TrainingDays = 3
+	fmt.Println(DayGoalCalories(&u, true), DayGoalCalories(&u, false))
+
+	fmt.Println(metCalDayGoal(&u, 2200, true), metCalDayGoal(&u, 2200, false))
+
+	// Output:
+	// 2000 2000
+	// 2400 1700
+	// true false
+}
+
+func ExampleWeeklyTrainingVolume() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_training (date, time, type, duration, calories)
+		VALUES ('2024-01-01', '18:00:00', 'lifting', 60, 300),
+		       ('2024-01-03', '18:00:00', 'lifting', 75, 350),
+		       ('2024-01-04', '07:00:00', 'running', 30, 320),
+		       ('2024-01-09', '18:00:00', 'lifting', 60, 300);
+	`)
+	defer db.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 10)
+	u := UserInfo{}
+	u.Phase.StartDate = start
+	u.Phase.EndDate = start.AddDate(0, 0, 56)
+
+	sessions, err := TrainingEntries(db, start, now)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, w := range WeeklyTrainingVolume(&u, sessions, now) {
+		fmt.Println(w.Start.Format(dateFormat), w.Sessions, w.Minutes, w.Calories)
+	}
+
+	entries := []Entry{{Date: start}, {Date: start.AddDate(0, 0, 1)}}
+	if err := MarkTrainingDays(db, &entries); err != nil {
+		log.Println(err)
+		return
+	}
+	fmt.Println(entries[0].Training, entries[1].Training)
+
+	// Output:
+	// 2024-01-01 3 165 970
+	// 2024-01-08 1 60 300
+	// true false
+}
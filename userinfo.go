@@ -21,6 +21,10 @@ const (
 	calsInFats    = 9 // Calories per gram of fat.
 )
 
+// DefaultSystem is the measurement system, "metric" or "imperial", used
+// when prompting for user details. When empty the user is asked.
+var DefaultSystem = ""
+
 type UserInfo struct {
 	UserID        int       `db:"user_id"`
 	Sex           string    `db:"sex"`
@@ -34,6 +38,15 @@ type UserInfo struct {
 	System        string    `db:"system"`
 	Phase         PhaseInfo `db:"phase"`
 	PhaseID       int       `db:"phase_id"`
+	BMRFormula    string    `db:"bmr_formula"`
+	BodyFat       float64   `db:"body_fat"` // percent
+	// Conditions are the comma-separated names of the conditions the
+	// user flagged, such as "glp1".
+	Conditions string `db:"conditions"`
+
+	// overrides holds the safety checks the user overrode that aren't
+	// recorded yet.
+	overrides []SafetyOverride
 }
 
 type Macros struct {
@@ -57,13 +70,10 @@ func Config(db *sqlx.DB) (*UserInfo, error) {
 	}
 	defer tx.Rollback()
 
-	u := &UserInfo{}
-
-	// Query the database for the configuration (assuming only one
-	// config for now).
-	if err := tx.Get(u, `SELECT * FROM config LIMIT 1`); err != nil {
+	u, err := loadUserInfo(tx)
+	if err != nil {
 		// If no config data in the database, generate a new config.
-		if err == sql.ErrNoRows {
+		if errors.Is(err, sql.ErrNoRows) {
 			u, err = generateAndSaveConfig(tx)
 			if err != nil {
 				return nil, err
@@ -71,6 +81,23 @@ func Config(db *sqlx.DB) (*UserInfo, error) {
 
 			return u, tx.Commit()
 		}
+		return nil, err
+	}
+
+	return u, tx.Commit()
+}
+
+// loadUserInfo reads the user info, macros, and phase info. It returns
+// sql.ErrNoRows if the user info hasn't been set up.
+func loadUserInfo(tx *sqlx.Tx) (*UserInfo, error) {
+	u := &UserInfo{}
+
+	// Query the database for the configuration (assuming only one
+	// config for now).
+	if err := tx.Get(u, `SELECT * FROM config LIMIT 1`); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("couldn't get config: %v", err)
 	}
 
@@ -87,12 +114,12 @@ func Config(db *sqlx.DB) (*UserInfo, error) {
 	}
 	u.Phase = *phase
 
-	return u, tx.Commit()
+	return u, nil
 }
 
 // generateAndSaveConfig generates a new user configuration file.
 func generateAndSaveConfig(tx *sqlx.Tx) (*UserInfo, error) {
-	fmt.Println("Please provide required information:")
+	fmt.Println(tr("Please provide required information:"))
 	u := UserInfo{}
 	getUserInfo(&u)
 	processUserInfo(&u)
@@ -138,7 +165,7 @@ func saveUserInfo(tx *sqlx.Tx, u *UserInfo) error {
 		return err
 	}
 
-	return nil
+	return recordSafetyOverrides(tx, u)
 }
 
 // insertOrUpdateUserInfo attempts to insert a new user information
@@ -149,9 +176,19 @@ func saveUserInfo(tx *sqlx.Tx, u *UserInfo) error {
 // creating a user table. In such case, the user id would come from matching
 // record to hashed password.
 func insertOrUpdateUserInfo(tx *sqlx.Tx, u *UserInfo) error {
+	err := addColumns(tx, "config",
+		"bmr_formula TEXT DEFAULT 'mifflin' NOT NULL",
+		"body_fat REAL DEFAULT 0 NOT NULL")
+	if err != nil {
+		return err
+	}
+	if u.BMRFormula == "" {
+		u.BMRFormula = "mifflin"
+	}
+
 	// Check if the record already exists
 	var count int
-	err := tx.Get(&count, "SELECT COUNT(*) FROM config WHERE user_id = 1")
+	err = tx.Get(&count, "SELECT COUNT(*) FROM config WHERE user_id = 1")
 	if err != nil {
 		return err
 	}
@@ -159,9 +196,9 @@ func insertOrUpdateUserInfo(tx *sqlx.Tx, u *UserInfo) error {
 	if count == 0 {
 		// Insert if no record found
 		_, err = tx.Exec(`
-        INSERT INTO config(user_id, sex, weight, height, age, activity_level, tdee, system, macros_id, phase_id)
-        VALUES (1, $1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-			u.Sex, u.Weight, u.Height, u.Age, u.ActivityLevel, u.TDEE, u.System, u.Macros.MacrosID, u.Phase.PhaseID)
+        INSERT INTO config(user_id, sex, weight, height, age, activity_level, tdee, system, macros_id, phase_id, bmr_formula, body_fat)
+        VALUES (1, $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			u.Sex, u.Weight, u.Height, u.Age, u.ActivityLevel, u.TDEE, u.System, u.Macros.MacrosID, u.Phase.PhaseID, u.BMRFormula, u.BodyFat)
 
 		if err != nil {
 			log.Printf("Failed to insert into config table: %v\n", err)
@@ -173,9 +210,10 @@ func insertOrUpdateUserInfo(tx *sqlx.Tx, u *UserInfo) error {
 	_, err = tx.Exec(`
 			UPDATE config SET
 					sex = $1, weight = $2, height = $3, age = $4,
-					activity_level = $5, tdee = $6, system = $7, macros_id = $8, phase_id = $9
+					activity_level = $5, tdee = $6, system = $7, macros_id = $8, phase_id = $9,
+					bmr_formula = $10, body_fat = $11
 			WHERE user_id = 1`,
-		u.Sex, u.Weight, u.Height, u.Age, u.ActivityLevel, u.TDEE, u.System, u.Macros.MacrosID, u.Phase.PhaseID)
+		u.Sex, u.Weight, u.Height, u.Age, u.ActivityLevel, u.TDEE, u.System, u.Macros.MacrosID, u.Phase.PhaseID, u.BMRFormula, u.BodyFat)
 
 	if err != nil {
 		log.Printf("Failed to update into config table: %v\n", err)
@@ -183,6 +221,26 @@ func insertOrUpdateUserInfo(tx *sqlx.Tx, u *UserInfo) error {
 	return err
 }
 
+// addColumns adds the columns to tables created before they existed.
+// Each definition starts with the column name.
+func addColumns(tx *sqlx.Tx, table string, defs ...string) error {
+	var cols []string
+	if err := tx.Select(&cols, `SELECT name FROM pragma_table_info($1)`, table); err != nil {
+		return fmt.Errorf("couldn't get %s columns: %v", table, err)
+	}
+
+	for _, def := range defs {
+		c := strings.Fields(def)[0]
+		if contains(cols, c) {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s`, table, def)); err != nil {
+			return fmt.Errorf("couldn't add %s column to %s: %v", c, table, err)
+		}
+	}
+	return nil
+}
+
 // insertOrUpdateMacros attempts to insert new macro nutritional data
 // for the user. If a record for the user's macros already exists, it
 // updates the existing record.
@@ -222,6 +280,10 @@ func insertOrUpdateMacros(tx *sqlx.Tx, u *UserInfo) error {
 // creating a user table. In such case, the user id would come from matching
 // record to hashed password.
 func insertOrUpdatePhaseInfo(tx *sqlx.Tx, u *UserInfo) error {
+	if err := addPhaseColumns(tx); err != nil {
+		return err
+	}
+
 	// Check if there's an existing active phase for this user
 	var existingPhaseID int
 	err := tx.Get(&existingPhaseID, "SELECT phase_id FROM phase_info WHERE user_id = $1 AND status = 'active'", u.UserID)
@@ -237,29 +299,36 @@ func insertOrUpdatePhaseInfo(tx *sqlx.Tx, u *UserInfo) error {
         name = $2, goal_calories = $3, start_weight = $4, goal_weight = $5,
         weight_change_threshold = $6, weekly_change = $7, start_date = $8,
         end_date = $9, last_checked_week = $10, duration = $11,
-        max_duration = $12, min_duration = $13, status = $14
+        max_duration = $12, min_duration = $13, status = $14,
+        training_calories = $15, training_days = $16, target_mode = $17,
+        target_week = $18, goal_weight_end = $19, manual_calories = $20
         WHERE phase_id = $1`,
 			existingPhaseID, u.Phase.Name, u.Phase.GoalCalories, u.Phase.StartWeight, u.Phase.GoalWeight,
 			u.Phase.WeightChangeThreshold, u.Phase.WeeklyChange, u.Phase.StartDate.Format(dateFormat),
 			u.Phase.EndDate.Format(dateFormat), u.Phase.LastCheckedWeek.Format(dateFormat), u.Phase.Duration,
-			u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status)
+			u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status,
+			u.Phase.TrainingCalories, u.Phase.TrainingDays, u.Phase.TargetMode, u.Phase.TargetWeek,
+			u.Phase.GoalWeightEnd, u.Phase.ManualCalories)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return recordPhaseTarget(tx, existingPhaseID, u)
 	}
 	// Otherwise, Insert a new phase
 	res, err := tx.Exec(`
       INSERT INTO phase_info(user_id, name, status, goal_calories, start_weight, goal_weight,
         weight_change_threshold, weekly_change, start_date,
         end_date, last_checked_week, duration, max_duration,
-        min_duration, status)
-      VALUES ($1, $2, 'active', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+        min_duration, status, training_calories, training_days, target_mode,
+        target_week, goal_weight_end, manual_calories)
+      VALUES ($1, $2, 'active', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`,
 		u.UserID, u.Phase.Name, u.Phase.GoalCalories, u.Phase.StartWeight, u.Phase.GoalWeight,
 		u.Phase.WeightChangeThreshold, u.Phase.WeeklyChange, u.Phase.StartDate.Format(dateFormat),
 		u.Phase.EndDate.Format(dateFormat), u.Phase.LastCheckedWeek.Format(dateFormat), u.Phase.Duration,
-		u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status)
+		u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status,
+		u.Phase.TrainingCalories, u.Phase.TrainingDays, u.Phase.TargetMode, u.Phase.TargetWeek,
+		u.Phase.GoalWeightEnd, u.Phase.ManualCalories)
 	if err != nil {
 		return err
 	}
@@ -272,11 +341,15 @@ func insertOrUpdatePhaseInfo(tx *sqlx.Tx, u *UserInfo) error {
 	// update the UserInfo struct
 	u.Phase.PhaseID = int(phaseID)
 
-	return nil
+	return recordPhaseTarget(tx, u.Phase.PhaseID, u)
 }
 
 // updatePhaseInfo updates the user's ongoing phase details.
 func updatePhaseInfo(tx *sqlx.Tx, u *UserInfo) error {
+	if err := addPhaseColumns(tx); err != nil {
+		return err
+	}
+
 	// Check if there's an existing active phase for this user
 	var activePhaseID int
 	err := tx.Get(&activePhaseID, "SELECT phase_id FROM phase_info WHERE user_id = $1 AND status = 'active' LIMIT 1", u.UserID)
@@ -295,18 +368,35 @@ func updatePhaseInfo(tx *sqlx.Tx, u *UserInfo) error {
         name = $2, goal_calories = $3, start_weight = $4, goal_weight = $5,
         weight_change_threshold = $6, weekly_change = $7, start_date = $8,
         end_date = $9, last_checked_week = $10, duration = $11,
-        max_duration = $12, min_duration = $13, status = $14
+        max_duration = $12, min_duration = $13, status = $14,
+        training_calories = $15, training_days = $16, target_mode = $17,
+        target_week = $18, goal_weight_end = $19, manual_calories = $20
         WHERE phase_id = $1`,
 		activePhaseID, u.Phase.Name, u.Phase.GoalCalories, u.Phase.StartWeight, u.Phase.GoalWeight,
 		u.Phase.WeightChangeThreshold, u.Phase.WeeklyChange, u.Phase.StartDate.Format(dateFormat),
 		u.Phase.EndDate.Format(dateFormat), u.Phase.LastCheckedWeek.Format(dateFormat), u.Phase.Duration,
-		u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status)
+		u.Phase.MaxDuration, u.Phase.MinDuration, u.Phase.Status,
+		u.Phase.TrainingCalories, u.Phase.TrainingDays, u.Phase.TargetMode, u.Phase.TargetWeek,
+		u.Phase.GoalWeightEnd, u.Phase.ManualCalories)
 	if err != nil {
 		log.Println("Error updating diet phase information.")
 		return err
 	}
 
-	return nil
+	return recordPhaseTarget(tx, activePhaseID, u)
+}
+
+// addPhaseColumns adds the training calorie, target mode, and goal
+// weight range columns to phase_info tables created before they
+// existed.
+func addPhaseColumns(tx *sqlx.Tx) error {
+	return addColumns(tx, "phase_info",
+		"training_calories REAL DEFAULT 0 NOT NULL",
+		"training_days INTEGER DEFAULT 0 NOT NULL",
+		"target_mode INTEGER DEFAULT 0 NOT NULL",
+		"target_week INTEGER DEFAULT 0 NOT NULL",
+		"goal_weight_end REAL DEFAULT 0 NOT NULL",
+		"manual_calories REAL DEFAULT 0 NOT NULL")
 }
 
 // activity returns the scale based on the user's activity level.
@@ -391,6 +481,80 @@ func Mifflin(u *UserInfo) float64 {
 	return bmr
 }
 
+// HarrisBenedict calculates and returns the BMR using the revised
+// Harris-Benedict equation, which is based on weight (kg), height (cm),
+// age (years), and sex.
+func HarrisBenedict(u *UserInfo) float64 {
+	weight := lbsToKg(u.Weight)
+	height := inchesToCm(u.Height)
+	age := float64(u.Age)
+
+	if u.Sex == "female" {
+		return 447.593 + (9.247 * weight) + (3.098 * height) - (4.330 * age)
+	}
+	return 88.362 + (13.397 * weight) + (4.799 * height) - (5.677 * age)
+}
+
+// KatchMcArdle calculates and returns the BMR using the Katch-McArdle
+// equation, which is based on lean body mass (kg).
+func KatchMcArdle(u *UserInfo) float64 {
+	return 370 + (21.6 * leanMass(u))
+}
+
+// Cunningham calculates and returns the BMR using the Cunningham
+// equation, which is based on lean body mass (kg). It suits lean,
+// active people better than the other equations.
+func Cunningham(u *UserInfo) float64 {
+	return 500 + (22 * leanMass(u))
+}
+
+// leanMass returns the user's lean body mass in kilograms.
+func leanMass(u *UserInfo) float64 {
+	return lbsToKg(u.Weight) * (1 - u.BodyFat/100)
+}
+
+// BMR calculates the BMR using the user's chosen equation. Mifflin-St
+// Jeor is used when no equation is set, or when an equation based on
+// lean body mass is chosen without a body fat percentage.
+func BMR(u *UserInfo) float64 {
+	switch u.BMRFormula {
+	case "harris-benedict":
+		return HarrisBenedict(u)
+	case "katch-mcardle":
+		if u.BodyFat > 0 {
+			return KatchMcArdle(u)
+		}
+	case "cunningham":
+		if u.BodyFat > 0 {
+			return Cunningham(u)
+		}
+	}
+	return Mifflin(u)
+}
+
+// bmrFormulas holds the supported BMR equations in the order they are
+// listed to the user.
+var bmrFormulas = []string{"mifflin", "harris-benedict", "katch-mcardle", "cunningham"}
+
+// bmrFormulaName returns the display name of the BMR equation.
+func bmrFormulaName(f string) string {
+	switch f {
+	case "harris-benedict":
+		return "Harris-Benedict"
+	case "katch-mcardle":
+		return "Katch-McArdle"
+	case "cunningham":
+		return "Cunningham"
+	}
+	return "Mifflin-St Jeor"
+}
+
+// needsBodyFat reports whether the BMR equation is based on lean body
+// mass.
+func needsBodyFat(f string) bool {
+	return f == "katch-mcardle" || f == "cunningham"
+}
+
 // TDEE calcuates the Total Daily Energy Expenditure (TDEE) based on the
 // BMR and user's activity level.
 func TDEE(bmr float64, a string) float64 {
@@ -653,13 +817,15 @@ func setMinMaxMacros(u *UserInfo) {
 	// Maximum daily fat intake is keeping calorie contribtions from fat
 	// to be under 40% of total daily calories .
 	u.Macros.MaxFats = 0.4 * u.Phase.GoalCalories / calsInFats
+
+	applyConditionMinimums(u)
 }
 
 // PrintMetrics prints user TDEE, suggested macro split, and generates
 // plots using logs data frame.
 func PrintMetrics(u *UserInfo) {
 	// Get BMR.
-	bmr := Mifflin(u)
+	bmr := BMR(u)
 	fmt.Printf("BMR: %.2f\n", bmr)
 
 	// Get TDEE.
@@ -668,19 +834,22 @@ func PrintMetrics(u *UserInfo) {
 
 	// Get suggested macro split.
 	protein, carbs, fats := calculateMacros(u)
-	fmt.Printf("Protein: %.2fg Carbs: %.2fg Fats: %.2fg\n", protein, carbs, fats)
+	fmt.Printf("Protein: %sg Carbs: %sg Fats: %sg\n", FormatMacro(protein), FormatMacro(carbs), FormatMacro(fats))
 
 	// Create plots
 }
 
 // getUserInfo prompts for user details.
 func getUserInfo(u *UserInfo) {
-	fmt.Println("Step 1: Your details.")
-
-	u.System = "imperial"
-	s := getSystem()
-	if s == "1" {
-		u.System = "metric"
+	fmt.Println(tr("Step 1: Your details."))
+
+	u.System = DefaultSystem
+	if u.System == "" {
+		u.System = "imperial"
+		s := getSystem()
+		if s == "1" {
+			u.System = "metric"
+		}
 	}
 
 	u.Sex = getSex()
@@ -693,8 +862,14 @@ func getUserInfo(u *UserInfo) {
 	u.Age = getAge()
 	u.ActivityLevel = getActivity()
 
+	u.BMRFormula = getBMRFormula()
+	u.BodyFat = 0
+	if needsBodyFat(u.BMRFormula) {
+		u.BodyFat = getBodyFat()
+	}
+
 	// Get BMR
-	bmr := Mifflin(u)
+	bmr := BMR(u)
 
 	// Set TDEE
 	u.TDEE = TDEE(bmr, u.ActivityLevel)
@@ -709,7 +884,7 @@ func getSystem() (s string) {
 
 		err := validateSystem(s)
 		if err != nil {
-			fmt.Println("Invalid option. Please try again.")
+			fmt.Println(tr("Invalid option. Please try again."))
 			continue
 		}
 
@@ -720,11 +895,11 @@ func getSystem() (s string) {
 
 // promptSystem prompts and returns user's preferred measurement system.
 func promptSystem() (s string) {
-	fmt.Println("Set measurement system to:")
-	fmt.Println("1. Metric (kg/cm)")
-	fmt.Println("2. Imperial (lbs/inches)")
-	fmt.Printf("Type number and <Enter>: ")
-	fmt.Scanln(&s)
+	fmt.Println(tr("Set measurement system to:"))
+	fmt.Println(tr("1. Metric (kg/cm)"))
+	fmt.Println(tr("2. Imperial (lbs/inches)"))
+	fmt.Print(tr("Type number and <Enter>: "))
+	fmt.Fscanln(Input, &s)
 	return s
 }
 
@@ -748,7 +923,7 @@ func getSex() (s string) {
 		// Validate user response.
 		err := validateSex(s)
 		if err != nil {
-			fmt.Println("Must enter \"male\" or \"female\". Please try again.")
+			fmt.Println(tr("Must enter \"male\" or \"female\". Please try again."))
 			continue
 		}
 
@@ -760,8 +935,8 @@ func getSex() (s string) {
 
 // promptSex prompts and returns user sex.
 func promptSex() (s string) {
-	fmt.Print("Enter sex (male/female): ")
-	fmt.Scanln(&s)
+	fmt.Print(tr("Enter sex (male/female): "))
+	fmt.Fscanln(Input, &s)
 	return s
 }
 
@@ -783,19 +958,19 @@ func getWeight(system string) (float64, error) {
 	for {
 		switch system {
 		case "metric":
-			fmt.Print("Enter weight (kgs): ")
-			_, err = fmt.Scan(&weight)
+			fmt.Print(tr("Enter weight (kgs): "))
+			_, err = fmt.Fscanln(Input, &weight)
 			if err != nil {
-				fmt.Printf("Error reading weight: %v. Please try again.\n", err)
+				fmt.Printf(tr("Error reading weight: %v. Please try again.\n"), err)
 				continue
 			}
 
 			weight = kgToLbs(weight)
 		case "imperial":
-			fmt.Print("Enter weight (lbs): ")
-			_, err = fmt.Scan(&weight)
+			fmt.Print(tr("Enter weight (lbs): "))
+			_, err = fmt.Fscanln(Input, &weight)
 			if err != nil {
-				fmt.Printf("Error reading weight: %v. Please try again.\n", err)
+				fmt.Printf(tr("Error reading weight: %v. Please try again.\n"), err)
 				continue
 			}
 		default:
@@ -816,31 +991,31 @@ func getHeight(system string) (float64, error) {
 	for {
 		switch system {
 		case "metric":
-			fmt.Print("Enter height (cm): ")
-			_, err = fmt.Scan(&height)
+			fmt.Print(tr("Enter height (cm): "))
+			_, err = fmt.Fscanln(Input, &height)
 
 			if err != nil {
-				fmt.Printf("Error reading height: %v. Please try again.\n", err)
+				fmt.Printf(tr("Error reading height: %v. Please try again.\n"), err)
 				continue
 			}
 
 			height = cmToInches(height)
 		case "imperial":
 			// Prompt for feet portion.
-			fmt.Print("What is your height (feet portion)? ")
+			fmt.Print(tr("What is your height (feet portion)? "))
 			var feet int
-			_, err := fmt.Scan(&feet)
+			_, err := fmt.Fscanln(Input, &feet)
 			if err != nil {
-				fmt.Printf("Error reading feet: %v. Please try again.", err)
+				fmt.Printf(tr("Error reading feet: %v. Please try again.\n"), err)
 				continue
 			}
 
 			// Prompt for inches portion
-			fmt.Print("What is your height (inches portion)? ")
+			fmt.Print(tr("What is your height (inches portion)? "))
 			var inches float64
-			_, err = fmt.Scan(&inches)
+			_, err = fmt.Fscanln(Input, &inches)
 			if err != nil {
-				fmt.Printf("Error reading inches: %v. Please try again.", err)
+				fmt.Printf(tr("Error reading inches: %v. Please try again.\n"), err)
 				continue
 			}
 
@@ -867,7 +1042,7 @@ func getAge() (age int) {
 		// Validate user response.
 		age, err = validateAge(ageStr)
 		if err != nil {
-			fmt.Println("Invalid age. Please try again.")
+			fmt.Println(tr("Invalid age. Please try again."))
 			continue
 		}
 
@@ -878,8 +1053,8 @@ func getAge() (age int) {
 
 // promptAge prompts user for their age and returns age as a string.
 func promptAge() (a string) {
-	fmt.Print("Enter age: ")
-	fmt.Scanln(&a)
+	fmt.Print(tr("Enter age: "))
+	fmt.Fscanln(Input, &a)
 	return a
 }
 
@@ -905,7 +1080,7 @@ func getActivity() (a string) {
 		// Validate user response.
 		err := validateActivity(a)
 		if err != nil {
-			fmt.Println("Invalid activity level. Please try again.")
+			fmt.Println(tr("Invalid activity level. Please try again."))
 			continue
 		}
 
@@ -916,8 +1091,8 @@ func getActivity() (a string) {
 
 // promptActivity prompts and returns user activity level.
 func promptActivity() (a string) {
-	fmt.Print("Enter activity level (sedentary, light, moderate, active, very): ")
-	fmt.Scanln(&a)
+	fmt.Print(tr("Enter activity level (sedentary, light, moderate, active, very): "))
+	fmt.Fscanln(Input, &a)
 	return a
 }
 
@@ -932,27 +1107,105 @@ func validateActivity(a string) error {
 	return nil
 }
 
+// getBMRFormula prompts user for the equation used to estimate their
+// BMR, validates their response, and returns the equation name.
+func getBMRFormula() string {
+	for {
+		// Prompt user for the BMR equation.
+		s := promptBMRFormula()
+
+		// Validate user response.
+		f, err := validateBMRFormula(s)
+		if err != nil {
+			fmt.Println(tr("Invalid option. Please try again."))
+			continue
+		}
+
+		return f
+	}
+}
+
+// promptBMRFormula prompts and returns user's preferred BMR equation.
+func promptBMRFormula() (s string) {
+	fmt.Println(tr("Estimate BMR with:"))
+	fmt.Println("1. Mifflin-St Jeor")
+	fmt.Println("2. Harris-Benedict")
+	fmt.Println(tr("3. Katch-McArdle (requires body fat %)"))
+	fmt.Println(tr("4. Cunningham (requires body fat %)"))
+	fmt.Print(tr("Type number and <Enter>: "))
+	fmt.Fscanln(Input, &s)
+	return s
+}
+
+// validateBMRFormula validates user response and returns the name of
+// the chosen BMR equation.
+func validateBMRFormula(s string) (string, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > len(bmrFormulas) {
+		return "", errors.New("Invalid option.")
+	}
+
+	return bmrFormulas[n-1], nil
+}
+
+// getBodyFat prompts user for body fat percentage, validates their
+// response, and returns valid body fat percentage.
+func getBodyFat() (bf float64) {
+	for {
+		fmt.Print(tr("Enter body fat (%): "))
+		_, err := fmt.Fscanln(Input, &bf)
+		if err != nil {
+			fmt.Printf(tr("Error reading body fat: %v. Please try again.\n"), err)
+			continue
+		}
+
+		if err := validateBodyFat(bf); err != nil {
+			fmt.Println(tr("Body fat must be between 0 and 100. Please try again."))
+			continue
+		}
+
+		break
+	}
+	return bf
+}
+
+// validateBodyFat validates user body fat percentage.
+func validateBodyFat(bf float64) error {
+	if bf <= 0 || bf >= 100 {
+		return errors.New("Invalid body fat.")
+	}
+
+	return nil
+}
+
 // PrintUserInfo prints the users info.
 func PrintUserInfo(u *UserInfo) {
-	fmt.Println(colorUnderline, "User Information:", colorReset)
-	fmt.Printf("Measurement System: %s\n", u.System)
-	fmt.Printf("Sex: %s\n", u.Sex)
+	fmt.Println(paint(colorUnderline, tr("User Information:")))
+	fmt.Printf(tr("Measurement System: %s\n"), u.System)
+	fmt.Printf(tr("Sex: %s\n"), u.Sex)
 
 	switch u.System {
 	case "metric":
-		fmt.Printf("Weight: %.2f kg\n", lbsToKg(u.Weight))
-		fmt.Printf("Height: %.2f cm\n", inchesToCm(u.Height))
+		fmt.Printf(tr("Weight: %s kg\n"), FormatWeight(lbsToKg(u.Weight)))
+		fmt.Printf(tr("Height: %s cm\n"), formatNumber(inchesToCm(u.Height), 2))
 	case "imperial":
 		feet, inches := inchesToFeetInches(u.Height)
-		fmt.Printf("Weight: %.2f lbs\n", u.Weight)
-		fmt.Printf("Height: %d' %.2f\"\n", feet, inches)
+		fmt.Printf(tr("Weight: %s lbs\n"), FormatWeight(u.Weight))
+		fmt.Printf(tr("Height: %d' %s\"\n"), feet, formatNumber(inches, 2))
 	default:
-		fmt.Println("Invalid measurement system.")
+		fmt.Println(tr("Invalid measurement system."))
 	}
 
-	fmt.Printf("Age: %d\n", u.Age)
-	fmt.Printf("Activity Level: %s\n", u.ActivityLevel)
-	fmt.Printf("TDEE: %.2f\n", u.TDEE)
+	fmt.Printf(tr("Age: %d\n"), u.Age)
+	fmt.Printf(tr("Activity Level: %s\n"), u.ActivityLevel)
+	fmt.Printf(tr("BMR Formula: %s\n"), bmrFormulaName(u.BMRFormula))
+	if needsBodyFat(u.BMRFormula) {
+		fmt.Printf(tr("Body Fat: %s%%\n"), formatNumber(u.BodyFat, 1))
+	}
+	fmt.Printf(tr("TDEE: %s\n"), formatNumber(u.TDEE, 2))
+	if u.Conditions != "" {
+		fmt.Printf(tr("Conditions: %s\n"), strings.ReplaceAll(u.Conditions, ",", ", "))
+	}
 }
 
 // UpdateUserInfo lets the user update their information.
@@ -965,7 +1218,7 @@ func UpdateUserInfo(db *sqlx.DB, u *UserInfo) error {
 	// If anything goes wrong, rollback the transaction
 	defer tx.Rollback()
 
-	fmt.Println("Update your information.")
+	fmt.Println(tr("Update your information."))
 	getUserInfo(u)
 
 	// Update min and max values for macros.
@@ -984,7 +1237,7 @@ func UpdateUserInfo(db *sqlx.DB, u *UserInfo) error {
 		return err
 	}
 
-	fmt.Println("Updated information:")
+	fmt.Println(tr("Updated information:"))
 	PrintUserInfo(u)
 
 	return tx.Commit()
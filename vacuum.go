@@ -0,0 +1,106 @@
+package bite
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// deleteTriggersSchema creates the triggers that carry out the ON
+// DELETE actions of the foreign keys of foods and meals. The actions are
+// only declared in databases made from the current setup.sql, and only
+// enforced when their foreign keys hold (see Open). Databases made
+// before then refer to columns and tables that don't exist, so deleting
+// a food or meal removes the rows that refer to it through these
+// instead. Vacuum adds the triggers to such databases, or replaces
+// those made by an older version, but it can't add the actions, since
+// SQLite can't change the foreign keys of a table.
+const deleteTriggersSchema = `
+	DROP TRIGGER IF EXISTS foods_delete;
+	CREATE TRIGGER foods_delete AFTER DELETE ON foods
+	BEGIN
+		DELETE FROM food_prefs WHERE food_id = old.food_id;
+		DELETE FROM meal_food_prefs WHERE food_id = old.food_id;
+		DELETE FROM meal_foods WHERE food_id = old.food_id;
+		DELETE FROM food_groups WHERE food_id = old.food_id;
+		DELETE FROM food_tags WHERE food_id = old.food_id;
+		DELETE FROM food_nutrients WHERE food_id = old.food_id;
+		DELETE FROM daily_foods WHERE food_id = old.food_id;
+		DELETE FROM pantry WHERE food_id = old.food_id;
+		DELETE FROM price_history WHERE food_id = old.food_id;
+		DELETE FROM foods_fts WHERE food_id = old.food_id;
+	END;
+
+	DROP TRIGGER IF EXISTS meals_delete;
+	CREATE TRIGGER meals_delete AFTER DELETE ON meals
+	BEGIN
+		DELETE FROM meal_food_prefs WHERE meal_id = old.meal_id;
+		DELETE FROM meal_foods WHERE meal_id = old.meal_id;
+		DELETE FROM meal_tags WHERE meal_id = old.meal_id;
+		DELETE FROM leftovers WHERE meal_id = old.meal_id;
+		DELETE FROM daily_meals WHERE meal_id = old.meal_id;
+		UPDATE daily_foods SET meal_id = NULL WHERE meal_id = old.meal_id;
+	END;
+
+	DROP TRIGGER IF EXISTS meal_foods_delete;
+	CREATE TRIGGER meal_foods_delete AFTER DELETE ON meal_foods
+	BEGIN
+		DELETE FROM meal_food_prefs
+		WHERE meal_id = old.meal_id AND food_id = old.food_id;
+	END;
+`
+
+// VacuumResult is the number of orphaned rows of each kind Vacuum
+// removed.
+type VacuumResult struct {
+	FoodPrefs     int // Serving preferences of deleted foods.
+	MealFoodPrefs int // Serving preferences of foods no longer in their meal.
+	DailyMeals    int // Logged meals without any logged foods.
+	SearchRows    int // Search index rows of deleted foods.
+}
+
+// String returns the counts, one per line.
+func (r VacuumResult) String() string {
+	return fmt.Sprintf(`Food serving preferences of deleted foods: %d
+Meal serving preferences of foods no longer in the meal: %d
+Logged meals without foods: %d
+Search index rows of deleted foods: %d`, r.FoodPrefs, r.MealFoodPrefs, r.DailyMeals, r.SearchRows)
+}
+
+// Vacuum removes the rows left behind by deleted foods and meals and
+// adds the triggers that remove them on delete from then on.
+func Vacuum(tx *sqlx.Tx) (VacuumResult, error) {
+	var r VacuumResult
+	steps := []struct {
+		n     *int
+		query string
+	}{
+		{&r.FoodPrefs, `DELETE FROM food_prefs WHERE food_id NOT IN (SELECT food_id FROM foods)`},
+		{&r.MealFoodPrefs, `DELETE FROM meal_food_prefs WHERE (meal_id, food_id) NOT IN (SELECT meal_id, food_id FROM meal_foods)`},
+		{&r.DailyMeals, `
+			DELETE FROM daily_meals
+			WHERE meal_id NOT IN (SELECT meal_id FROM meals)
+				OR NOT EXISTS (
+					SELECT 1 FROM daily_foods df
+					WHERE df.meal_id = daily_meals.meal_id AND df.date = daily_meals.date
+				)
+		`},
+		{&r.SearchRows, `DELETE FROM foods_fts WHERE food_id NOT IN (SELECT food_id FROM foods)`},
+	}
+	for _, s := range steps {
+		res, err := tx.Exec(s.query)
+		if err != nil {
+			return r, fmt.Errorf("couldn't remove orphaned rows: %v", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return r, err
+		}
+		*s.n = int(n)
+	}
+
+	if _, err := tx.Exec(deleteTriggersSchema); err != nil {
+		return r, fmt.Errorf("couldn't create delete triggers: %v", err)
+	}
+	return r, nil
+}
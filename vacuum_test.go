@@ -0,0 +1,60 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleVacuum() {
+	db := dbtest.MustNew(`
+		-- Rows left behind before foreign keys were enforced.
+		PRAGMA foreign_keys = OFF;
+		INSERT INTO foods_fts (food_id, food_name, brand_name) VALUES (4, 'Rice', '');
+		INSERT INTO meals (meal_id, meal_name) VALUES (1, 'Bowl'), (2, 'Oatmeal');
+		INSERT INTO meal_foods (meal_id, food_id) VALUES (1, 2), (2, 1), (2, 3);
+		INSERT INTO food_prefs (food_id, serving_size) VALUES (1, 40), (4, 100);
+		INSERT INTO meal_food_prefs (meal_id, food_id, serving_size) VALUES (1, 2, 150), (1, 3, 118);
+		INSERT INTO daily_meals (meal_id, date, time) VALUES
+			(1, '2024-01-01', '12:00:00'), (1, '2024-01-02', '12:00:00'), (3, '2024-01-02', '18:00:00');
+		INSERT INTO daily_foods (food_id, meal_id, date, time, serving_size, number_of_servings, calories, protein, fat, carbs)
+		VALUES (2, 1, '2024-01-01', '12:00:00', 150, 1, 248, 46, 5, 0);
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	defer tx.Rollback()
+	r, err := Vacuum(tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(r)
+
+	// Deleting a meal or a food now removes the rows that refer to it.
+	tx.MustExec(`DELETE FROM meals WHERE meal_id = 1`)
+	tx.MustExec(`DELETE FROM foods WHERE food_id = 1`)
+	var rows struct {
+		MealFoods  int `db:"meal_foods"`
+		Prefs      int `db:"prefs"`
+		DailyMeals int `db:"daily_meals"`
+		Search     int `db:"search"`
+	}
+	const query = `
+		SELECT
+			(SELECT COUNT(*) FROM meal_foods) AS meal_foods,
+			(SELECT COUNT(*) FROM food_prefs) + (SELECT COUNT(*) FROM meal_food_prefs) AS prefs,
+			(SELECT COUNT(*) FROM daily_meals) AS daily_meals,
+			(SELECT COUNT(*) FROM foods_fts WHERE food_id = 1) AS search
+	`
+	if err := tx.Get(&rows, query); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(rows.MealFoods, rows.Prefs, rows.DailyMeals, rows.Search)
+	// Output:
+	// Food serving preferences of deleted foods: 1
+	// Meal serving preferences of foods no longer in the meal: 1
+	// Logged meals without foods: 2
+	// Search index rows of deleted foods: 1
+	// 1 0 0 0
+}
@@ -0,0 +1,182 @@
+package bite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// usualWeighIns is the number of recent weigh-ins whose median time of
+// day is the usual weigh-in time when no WeighInTime is set.
+const usualWeighIns = 14
+
+// minUsualWeighIns is the least number of weigh-ins with a time of day
+// needed to find the usual weigh-in time.
+const minUsualWeighIns = 5
+
+var (
+	// WeighInTime is the preferred time of day ("15:04") to weigh in.
+	// Empty means the usual time of the recent weigh-ins.
+	WeighInTime = ""
+	// WeighInWindow is how far from the weigh-in time a weight may be
+	// logged before it is flagged.
+	WeighInWindow = time.Hour
+	// WeighInConditions describes how to weigh in, such as "after the
+	// bathroom, before eating or drinking".
+	WeighInConditions = ""
+)
+
+// WeighInProtocol is when the user weighs in.
+type WeighInProtocol struct {
+	Time   time.Duration // Time of day, from midnight.
+	Window time.Duration
+	Usual  bool // Whether Time is the usual time rather than WeighInTime.
+}
+
+// Offset returns how far the time of day of t is outside the weigh-in
+// window, or 0 inside it.
+func (p WeighInProtocol) Offset(t time.Time) time.Duration {
+	d := clockOf(t) - p.Time
+	if d < 0 {
+		d = -d
+	}
+	if d <= p.Window {
+		return 0
+	}
+	return d
+}
+
+// clockOf returns the time of day of t, from midnight.
+func clockOf(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// formatClock formats a time of day as "15:04".
+func formatClock(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04")
+}
+
+// formatOffset formats a duration in hours and minutes, such as "2h40m"
+// or "3h".
+func formatOffset(d time.Duration) string {
+	s := strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// weighInProtocol returns the user's weigh-in protocol on the day of
+// now: WeighInTime, or else the median time of day of the weigh-ins
+// before the day. It returns nil without WeighInTime and enough
+// weigh-ins. Weights logged without a time of day, at midnight, are
+// left out.
+func weighInProtocol(tx *sqlx.Tx, now time.Time) (*WeighInProtocol, error) {
+	p := &WeighInProtocol{Window: WeighInWindow}
+	if WeighInTime != "" {
+		t, err := time.Parse("15:04", WeighInTime)
+		if err != nil {
+			return nil, fmt.Errorf("weigh-in time must be HH:MM, got %q", WeighInTime)
+		}
+		p.Time = clockOf(t)
+		return p, nil
+	}
+
+	if err := addWeightColumns(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT CAST(time AS TEXT) FROM daily_weights
+		WHERE estimated = 0 AND time NOT IN ('00:00', '00:00:00') AND date < $1
+		ORDER BY date DESC
+		LIMIT $2
+	`
+	var times []string
+	if err := tx.Select(&times, query, now.Format(dateFormat), usualWeighIns); err != nil {
+		return nil, fmt.Errorf("couldn't get weigh-in times: %v", err)
+	}
+	var clocks []time.Duration
+	for _, s := range times {
+		if t, ok := parseClock(s); ok {
+			clocks = append(clocks, clockOf(t))
+		}
+	}
+	if len(clocks) < minUsualWeighIns {
+		return nil, nil
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i] < clocks[j] })
+	p.Time, p.Usual = clocks[len(clocks)/2], true
+	return p, nil
+}
+
+// parseClock parses a time of day written as "15:04:05" or "15:04".
+func parseClock(s string) (time.Time, bool) {
+	for _, layout := range []string{dateFormatTime, "15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// weighInWarning returns a warning when a weight logged at t was logged
+// outside the weigh-in window, or an empty string.
+func weighInWarning(p *WeighInProtocol, t time.Time) string {
+	if p == nil {
+		return ""
+	}
+	off := p.Offset(t)
+	if off == 0 {
+		return ""
+	}
+	usual := "preferred"
+	if p.Usual {
+		usual = "usual"
+	}
+	return fmt.Sprintf("Weight logged at %s, %s from your %s %s weigh-in. Weighing in at different times adds noise to the weight trend.",
+		t.Format("15:04"), formatOffset(off), usual, formatClock(p.Time))
+}
+
+// WeighInReminders returns the weigh-in reminders of the day of now: to
+// weigh in once the window has opened and no weight is logged, or that
+// the day's weight was logged outside the window.
+func WeighInReminders(tx *sqlx.Tx, now time.Time) ([]string, error) {
+	p, err := weighInProtocol(tx, now)
+	if err != nil || p == nil {
+		return nil, err
+	}
+	if err := addWeightColumns(tx); err != nil {
+		return nil, err
+	}
+	const query = `
+		SELECT CAST(time AS TEXT) FROM daily_weights
+		WHERE estimated = 0 AND date = $1
+		ORDER BY time LIMIT 1
+	`
+	var times []string
+	if err := tx.Select(&times, query, now.Format(dateFormat)); err != nil {
+		return nil, fmt.Errorf("couldn't get today's weight: %v", err)
+	}
+
+	if len(times) == 0 {
+		if clockOf(now) < p.Time-p.Window {
+			return nil, nil
+		}
+		s := "Weigh in"
+		if WeighInConditions != "" {
+			s += " (" + WeighInConditions + ")"
+		}
+		return []string{fmt.Sprintf("%s between %s and %s.", s, formatClock(p.Time-p.Window), formatClock(p.Time+p.Window))}, nil
+	}
+	t, ok := parseClock(times[0])
+	if !ok || clockOf(t) == 0 {
+		return nil, nil
+	}
+	if w := weighInWarning(p, t); w != "" {
+		return []string{w}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,50 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleWeighInReminders() {
+	db := dbtest.MustNew(`
+		INSERT INTO daily_weights (date, time, weight)
+		VALUES ('2024-03-11', '07:05:00', 181),
+		       ('2024-03-12', '06:50:00', 180.8),
+		       ('2024-03-13', '07:00:00', 180.6),
+		       ('2024-03-14', '07:20:00', 180.9),
+		       ('2024-03-15', '00:00:00', 180.2),
+		       ('2024-03-16', '07:10:00', 180.4),
+		       ('2024-03-18', '10:15:00', 180.1);
+	`)
+	defer db.Close()
+	tx := db.MustBegin()
+	defer tx.Rollback()
+
+	remind := func(now time.Time) {
+		reminders, err := WeighInReminders(tx, now)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s %q\n", now.Format("01-02 15:04"), reminders)
+	}
+
+	// The usual time is the median of the weigh-ins with a time of day.
+	remind(time.Date(2024, 3, 17, 5, 30, 0, 0, time.UTC))
+	remind(time.Date(2024, 3, 17, 6, 30, 0, 0, time.UTC))
+	remind(time.Date(2024, 3, 18, 12, 0, 0, 0, time.UTC))
+
+	WeighInTime, WeighInWindow, WeighInConditions = "09:30", 45*time.Minute, "after the bathroom"
+	defer func() { WeighInTime, WeighInWindow, WeighInConditions = "", time.Hour, "" }()
+	remind(time.Date(2024, 3, 17, 9, 0, 0, 0, time.UTC))
+	remind(time.Date(2024, 3, 18, 12, 0, 0, 0, time.UTC))
+
+	// Output:
+	// 03-17 05:30 []
+	// 03-17 06:30 ["Weigh in between 06:05 and 08:05."]
+	// 03-18 12:00 ["Weight logged at 10:15, 3h10m from your usual 07:05 weigh-in. Weighing in at different times adds noise to the weight trend."]
+	// 03-17 09:00 ["Weigh in (after the bathroom) between 08:45 and 10:15."]
+	// 03-18 12:00 []
+}
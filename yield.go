@@ -0,0 +1,160 @@
+package bite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// cookedYields are the standard weights (g) of a gram of raw or dry
+// food once cooked, by a word in the food's name. Meats lose water as
+// they cook, while grains and legumes take it up.
+var cookedYields = []struct {
+	word  string
+	yield float64
+}{
+	{"rice", 2.5},
+	{"pasta", 2.25},
+	{"spaghetti", 2.25},
+	{"noodles", 2.25},
+	{"macaroni", 2.25},
+	{"quinoa", 2.7},
+	{"couscous", 2.5},
+	{"lentils", 2.5},
+	{"beans", 2.4},
+	{"chickpeas", 2.4},
+	{"chicken", 0.75},
+	{"turkey", 0.75},
+	{"beef", 0.72},
+	{"pork", 0.75},
+	{"lamb", 0.72},
+	{"salmon", 0.8},
+	{"fish", 0.8},
+	{"cod", 0.8},
+	{"tuna", 0.8},
+	{"shrimp", 0.85},
+}
+
+// cookedWords are the words in the name of a food whose data is already
+// for the cooked food.
+var cookedWords = []string{"cooked", "boiled", "roasted", "grilled", "baked", "fried", "steamed", "braised", "broiled", "stewed", "canned"}
+
+// addYieldColumn adds the cooked_yield column, the weight of a gram of
+// a food once cooked, to food tables created before it existed.
+func addYieldColumn(tx *sqlx.Tx) error {
+	return addColumns(tx, "foods", "cooked_yield REAL DEFAULT 0 NOT NULL")
+}
+
+// SetFoodYield sets the weight (g) of a gram of the food once cooked,
+// overriding the standard yield for its kind. Zero removes the
+// override, and 1 logs cooked weights of the food as they are.
+func SetFoodYield(tx *sqlx.Tx, foodID int, yield float64) error {
+	if yield < 0 {
+		return fmt.Errorf("cooked yield can't be negative, got %v", yield)
+	}
+	if err := addYieldColumn(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE foods SET cooked_yield = $1 WHERE food_id = $2`, yield, foodID); err != nil {
+		return fmt.Errorf("couldn't set cooked yield: %v", err)
+	}
+	return nil
+}
+
+// CookedYield returns the weight (g) of a gram of the food once cooked:
+// the yield set for the food, or else the standard yield for its kind.
+// It is 1 for foods whose data is already for the cooked food, and for
+// foods without a yield.
+func CookedYield(db *sqlx.DB, f Food) (float64, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if err := addYieldColumn(tx); err != nil {
+		return 0, err
+	}
+	var yield float64
+	if err := tx.Get(&yield, `SELECT cooked_yield FROM foods WHERE food_id = $1`, f.ID); err != nil {
+		return 0, fmt.Errorf("couldn't get cooked yield of %s: %v", f.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	if yield > 0 {
+		return yield, nil
+	}
+	return standardYield(f.Name), nil
+}
+
+// standardYield returns the standard yield of the food with the given
+// name, or 1 if it's cooked already or has none.
+func standardYield(name string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')' || r == '-'
+	})
+	for _, w := range words {
+		for _, c := range cookedWords {
+			if w == c {
+				return 1
+			}
+		}
+	}
+	for _, y := range cookedYields {
+		for _, w := range words {
+			if w == y.word || singular(w) == singular(y.word) {
+				return y.yield
+			}
+		}
+	}
+	return 1
+}
+
+// applyCooked converts the cooked weight of an item, such as "150g
+// cooked chicken", to the raw weight of the food, whose data is for the
+// raw or dry food. Items without a unit are servings and are left as
+// they are.
+func applyCooked(db *sqlx.DB, f Food, it QuickItem) (QuickItem, error) {
+	if !it.Cooked || it.Unit == "" {
+		return it, nil
+	}
+	yield, err := CookedYield(db, f)
+	if err != nil {
+		return it, err
+	}
+	if yield != 1 {
+		raw := it.Quantity / yield
+		fmt.Printf("%s %s cooked %s is %s %s raw.\n", formatNumber(it.Quantity, 0), it.Unit, f.Name, formatNumber(raw, 0), it.Unit)
+		it.Quantity = raw
+	}
+	return it, nil
+}
+
+// FlagCookedYield lets the user select a food and sets the weight of a
+// gram of it once cooked.
+func FlagCookedYield(db *sqlx.DB, yield float64) error {
+	food, err := selectFood(db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := SetFoodYield(tx, food.ID, yield); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if yield == 0 {
+		yield = standardYield(food.Name)
+		fmt.Printf("%s uses the standard cooked yield of %s.\n", food.Name, formatNumber(yield, 2))
+		return nil
+	}
+	fmt.Printf("100 g of %s weighs %s g once cooked.\n", food.Name, formatNumber(yield*PortionSize, 0))
+	return nil
+}
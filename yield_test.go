@@ -0,0 +1,56 @@
+package bite
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ericstrs/bite/internal/dbtest"
+)
+
+func ExampleCookedYield() {
+	db := dbtest.MustNew(`
+		INSERT INTO foods (food_id, food_name, serving_size, serving_unit, household_serving) VALUES
+			(4, 'Chicken breast, raw', 100, 'g', ''),
+			(5, 'Rice, white, cooked', 100, 'g', ''),
+			(6, 'Jasmine rice', 100, 'g', '');
+	`)
+	defer db.Close()
+
+	tx := db.MustBegin()
+	if err := SetFoodYield(tx, 6, 3); err != nil {
+		log.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatal(err)
+	}
+
+	items, err := ParseQuickLog("150g cooked chicken, 200g cooked rice, 2 cooked eggs")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, it := range items {
+		fmt.Printf("%g %q %q %v\n", it.Quantity, it.Unit, it.Name, it.Cooked)
+	}
+
+	for _, f := range []Food{
+		{ID: 4, Name: "Chicken breast, raw"},
+		{ID: 5, Name: "Rice, white, cooked"},
+		{ID: 6, Name: "Jasmine rice"},
+	} {
+		it, err := applyCooked(db, f, QuickItem{Quantity: 150, Unit: "g", Cooked: true})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %g g\n", f.Name, it.Quantity)
+	}
+
+	// Output:
+	// 150 "g" "chicken" true
+	// 200 "g" "rice" true
+	// 2 "" "eggs" true
+	// 150 g cooked Chicken breast, raw is 200 g raw.
+	// Chicken breast, raw: 200 g
+	// Rice, white, cooked: 150 g
+	// 150 g cooked Jasmine rice is 50 g raw.
+	// Jasmine rice: 50 g
+}